@@ -0,0 +1,63 @@
+package ast
+
+import (
+	"fmt"
+	"testing"
+)
+
+// benchInsertNode builds an insert of an entity with n attributes, mirroring
+// what gotype generates for a model with n fields.
+func benchInsertNode(n int) QueryNode {
+	statements := []Statement{IsaStmt("$e", "person")}
+	for i := range n {
+		name := fmt.Sprintf("attr-%02d", i)
+		var val Value
+		switch i % 3 {
+		case 0:
+			val = Str("value " + name)
+		case 1:
+			val = Long(int64(i))
+		default:
+			val = Double(float64(i) + 0.5)
+		}
+		statements = append(statements, HasStmt("$e", name, val))
+	}
+	return Insert(statements...)
+}
+
+// benchFetchNode builds a fetch of n attributes plus the IID.
+func benchFetchNode(n int) QueryNode {
+	items := []FetchItem{FetchFunc("_iid", "iid", "$e")}
+	for i := range n {
+		name := fmt.Sprintf("attr-%02d", i)
+		items = append(items, FetchAttr(name, "$e", name))
+	}
+	return Fetch(items...)
+}
+
+func benchmarkCompile(b *testing.B, node QueryNode) {
+	compiler := &Compiler{}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := compiler.Compile(node); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiler_Compile_InsertSmall(b *testing.B) {
+	benchmarkCompile(b, benchInsertNode(5))
+}
+
+func BenchmarkCompiler_Compile_Insert50(b *testing.B) {
+	benchmarkCompile(b, benchInsertNode(50))
+}
+
+func BenchmarkCompiler_Compile_FetchSmall(b *testing.B) {
+	benchmarkCompile(b, benchFetchNode(5))
+}
+
+func BenchmarkCompiler_Compile_Fetch50(b *testing.B) {
+	benchmarkCompile(b, benchFetchNode(50))
+}
@@ -21,6 +21,12 @@ func Put(statements ...Statement) PutClause {
 	return PutClause{Statements: statements}
 }
 
+// MatchInsert creates a MatchInsertClause that inserts statements for each
+// match of patterns unless guard already matches.
+func MatchInsert(patterns, guard []Pattern, statements ...Statement) MatchInsertClause {
+	return MatchInsertClause{Patterns: patterns, Guard: guard, Statements: statements}
+}
+
 // Delete creates a DeleteClause with the given statements.
 func Delete(statements ...Statement) DeleteClause {
 	return DeleteClause{Statements: statements}
@@ -60,6 +66,11 @@ func Limit(count int) LimitClause {
 	return LimitClause{Count: count}
 }
 
+// Define creates a DefineClause with the given definitions.
+func Define(definitions ...Definition) DefineClause {
+	return DefineClause{Definitions: definitions}
+}
+
 // Entity creates an EntityPattern with the given variable, type, and constraints.
 func Entity(varName, typeName string, constraints ...Constraint) EntityPattern {
 	return EntityPattern{
@@ -130,11 +141,90 @@ func Bool(b bool) LiteralValue {
 	return LiteralValue{Val: b, ValueType: "boolean"}
 }
 
+// Datetime creates a datetime LiteralValue (no zone information).
+func Datetime(t time.Time) LiteralValue {
+	return LiteralValue{Val: t, ValueType: "datetime"}
+}
+
+// DatetimeTZ creates a zone-aware datetime-tz LiteralValue.
+func DatetimeTZ(t time.Time) LiteralValue {
+	return LiteralValue{Val: t, ValueType: "datetime-tz"}
+}
+
 // FuncCall creates a FunctionCallValue with the given function name and arguments.
 func FuncCall(funcName string, args ...any) FunctionCallValue {
 	return FunctionCallValue{Function: funcName, Args: args}
 }
 
+// LetEq creates a scalar LetAssignment binding the result of expr to vars
+// (let $a, $b = expr). The expression can be a Value or a raw string.
+func LetEq(expr any, vars ...string) LetAssignment {
+	return LetAssignment{Variables: vars, Expression: expr}
+}
+
+// LetIn creates a stream LetAssignment binding each row of expr to vars
+// (let $a, $b in expr). The expression can be a Value or a raw string.
+func LetIn(expr any, vars ...string) LetAssignment {
+	return LetAssignment{Variables: vars, Expression: expr, IsStream: true}
+}
+
+// Add creates an ArithmeticValue for left + right.
+// Operands can be Value nodes, variable strings (e.g. "$x"), or Go values
+// converted via ValueFromGo; a string without a leading "$" becomes a quoted
+// string literal.
+func Add(left, right any) ArithmeticValue {
+	return arith(left, "+", right)
+}
+
+// Sub creates an ArithmeticValue for left - right.
+func Sub(left, right any) ArithmeticValue {
+	return arith(left, "-", right)
+}
+
+// Mul creates an ArithmeticValue for left * right.
+func Mul(left, right any) ArithmeticValue {
+	return arith(left, "*", right)
+}
+
+// Div creates an ArithmeticValue for left / right.
+func Div(left, right any) ArithmeticValue {
+	return arith(left, "/", right)
+}
+
+// Mod creates an ArithmeticValue for left % right.
+func Mod(left, right any) ArithmeticValue {
+	return arith(left, "%", right)
+}
+
+// Pow creates an ArithmeticValue for left ^ right.
+func Pow(left, right any) ArithmeticValue {
+	return arith(left, "^", right)
+}
+
+// Paren creates a ParenValue that forces explicit grouping of v.
+func Paren(v any) ParenValue {
+	return ParenValue{Inner: operand(v)}
+}
+
+func arith(left any, op string, right any) ArithmeticValue {
+	return ArithmeticValue{Left: operand(left), Operator: op, Right: operand(right)}
+}
+
+// operand passes through Value nodes and variable strings (those starting
+// with "$") and converts any other Go value, including plain strings, to a
+// literal.
+func operand(v any) any {
+	switch x := v.(type) {
+	case Value:
+		return v
+	case string:
+		if strings.HasPrefix(x, "$") {
+			return v
+		}
+	}
+	return ValueFromGo(v)
+}
+
 // HasStmt creates a HasStatement for the given subject variable, attribute name, and value.
 // The value must be a Value type (use Str(), Long(), etc. to create literal values).
 func HasStmt(subjectVar, attrName string, value Value) HasStatement {
@@ -180,6 +270,12 @@ func FetchFunc(key, funcName, varName string) FetchFunction {
 	return FetchFunction{Key: key, FuncName: funcName, Var: varName}
 }
 
+// FetchSub creates a FetchSubquery that fetches the results of a nested match-fetch
+// pipeline under key.
+func FetchSub(key string, match MatchClause, fetch FetchClause) FetchSubquery {
+	return FetchSubquery{Key: key, Match: match, Fetch: fetch}
+}
+
 // DeleteHas creates a DeleteHasStatement for deleting an attribute from its owner.
 // Compiles to: $attrVar of $ownerVar
 func DeleteHas(attrVar, ownerVar string) DeleteHasStatement {
@@ -191,12 +287,81 @@ func Cmp(variable, operator string, value any) ValueComparisonPattern {
 	return ValueComparisonPattern{Var: variable, Operator: operator, Value: value}
 }
 
+// Is creates an IsPattern asserting that two variables refer to the same concept.
+func Is(leftVar, rightVar string) IsPattern {
+	return IsPattern{LeftVar: leftVar, RightVar: rightVar}
+}
+
+// Not creates a NotPattern negating the given patterns.
+func Not(patterns ...Pattern) NotPattern {
+	return NotPattern{Patterns: patterns}
+}
+
 // Or creates an OrPattern from multiple pattern alternatives.
 // Each alternative is a slice of patterns that must all match.
 func Or(alternatives ...[]Pattern) OrPattern {
 	return OrPattern{Alternatives: alternatives}
 }
 
+// EntityType creates a TypeDefinition for an entity type.
+func EntityType(typeName string, capabilities ...Capability) TypeDefinition {
+	return TypeDefinition{Kind: "entity", TypeName: typeName, Capabilities: capabilities}
+}
+
+// RelationType creates a TypeDefinition for a relation type.
+func RelationType(typeName string, capabilities ...Capability) TypeDefinition {
+	return TypeDefinition{Kind: "relation", TypeName: typeName, Capabilities: capabilities}
+}
+
+// AttributeType creates a TypeDefinition for an attribute type with the given value type.
+// Annotations constrain the value, e.g. Regex or Values.
+func AttributeType(typeName, valueType string, valueAnnotations ...Annotation) TypeDefinition {
+	return TypeDefinition{
+		Kind:         "attribute",
+		TypeName:     typeName,
+		Capabilities: []Capability{ValueTypeCapability{ValueType: valueType, Annotations: valueAnnotations}},
+	}
+}
+
+// Owns creates an OwnsCapability for the given attribute with optional annotations.
+func Owns(attrName string, annotations ...Annotation) OwnsCapability {
+	return OwnsCapability{AttrName: attrName, Annotations: annotations}
+}
+
+// Relates creates a RelatesCapability for the given role with optional annotations.
+func Relates(roleName string, annotations ...Annotation) RelatesCapability {
+	return RelatesCapability{RoleName: roleName, Annotations: annotations}
+}
+
+// Plays creates a PlaysCapability for the given scoped role (e.g. "friendship:friend").
+func Plays(role string, annotations ...Annotation) PlaysCapability {
+	return PlaysCapability{Role: role, Annotations: annotations}
+}
+
+// Card creates a bounded CardAnnotation (@card(min..max)).
+func Card(minCount, maxCount int) CardAnnotation {
+	return CardAnnotation{Min: minCount, Max: &maxCount}
+}
+
+// CardAtLeast creates an unbounded CardAnnotation (@card(min..)).
+func CardAtLeast(minCount int) CardAnnotation {
+	return CardAnnotation{Min: minCount}
+}
+
+// Values creates a ValuesAnnotation from Go values converted via ValueFromGo.
+func Values(values ...any) ValuesAnnotation {
+	vals := make([]Value, 0, len(values))
+	for _, v := range values {
+		vals = append(vals, ValueFromGo(v))
+	}
+	return ValuesAnnotation{Values: vals}
+}
+
+// Regex creates a RegexAnnotation for the given pattern.
+func Regex(pattern string) RegexAnnotation {
+	return RegexAnnotation{Pattern: pattern}
+}
+
 // ValueFromGo converts a Go value to an AST Value node.
 // Handles common types: string, int, int64, float64, bool, time.Time.
 // Falls back to string representation for unknown types.
@@ -11,7 +11,11 @@ import (
 
 // Compiler compiles AST nodes into TypeQL query strings.
 // It traverses the AST and generates the corresponding TypeQL syntax.
-type Compiler struct{}
+// The zero value is ready to use.
+type Compiler struct {
+	// Literals controls how temporal literal values are formatted.
+	Literals LiteralOptions
+}
 
 // Compile compiles a single AST node into its TypeQL string representation.
 // It returns an error if the node type is unknown or if compilation fails.
@@ -27,6 +31,12 @@ func (c *Compiler) Compile(node QueryNode) (string, error) {
 		return c.compileConstraint(n)
 	case Value:
 		return c.compileValue(n)
+	case Definition:
+		return c.compileDefinition(n)
+	case Capability:
+		return c.compileCapability(n)
+	case Annotation:
+		return c.compileAnnotation(n)
 	default:
 		return "", fmt.Errorf("unknown node type: %T", node)
 	}
@@ -68,6 +78,8 @@ func (c *Compiler) compileClause(clause Clause) (string, error) {
 		return c.compileStmtBlock("update", cl.Statements)
 	case PutClause:
 		return c.compileStmtBlock("put", cl.Statements)
+	case MatchInsertClause:
+		return c.compileMatchInsert(cl)
 	case FetchClause:
 		return c.compileFetchClause(cl)
 	case ReduceClause:
@@ -80,6 +92,8 @@ func (c *Compiler) compileClause(clause Clause) (string, error) {
 		return fmt.Sprintf("offset %d;", cl.Count), nil
 	case LimitClause:
 		return fmt.Sprintf("limit %d;", cl.Count), nil
+	case DefineClause:
+		return joinCompiled("define\n", "\n", "", cl.Definitions, c.compileDefinition)
 	default:
 		return "", fmt.Errorf("unknown clause type: %T", clause)
 	}
@@ -89,6 +103,30 @@ func (c *Compiler) compileMatchClause(cl MatchClause) (string, error) {
 	return joinCompiled("match\n", ";\n", ";", cl.Patterns, c.compilePattern)
 }
 
+// compileMatchInsert compiles an insert guarded by a negated pattern. An empty
+// guard or insert is rejected: without the guard the query would insert
+// unconditionally, which is what the clause exists to prevent.
+func (c *Compiler) compileMatchInsert(cl MatchInsertClause) (string, error) {
+	if len(cl.Guard) == 0 {
+		return "", fmt.Errorf("match-insert requires at least one guard pattern")
+	}
+	if len(cl.Statements) == 0 {
+		return "", fmt.Errorf("match-insert requires at least one insert statement")
+	}
+	patterns := make([]Pattern, 0, len(cl.Patterns)+1)
+	patterns = append(patterns, cl.Patterns...)
+	patterns = append(patterns, NotPattern{Patterns: cl.Guard})
+	match, err := c.compileMatchClause(MatchClause{Patterns: patterns})
+	if err != nil {
+		return "", err
+	}
+	insert, err := c.compileStmtBlock("insert", cl.Statements)
+	if err != nil {
+		return "", err
+	}
+	return match + "\n" + insert, nil
+}
+
 func (c *Compiler) compileStmtBlock(keyword string, statements []Statement) (string, error) {
 	stmts := make([]string, 0, len(statements))
 	for _, s := range statements {
@@ -162,6 +200,9 @@ func (c *Compiler) compileMatchLet(clause MatchLetClause) (string, error) {
 }
 
 func (c *Compiler) compileLetAssignment(a LetAssignment) (string, error) {
+	if len(a.Variables) == 0 {
+		return "", fmt.Errorf("let assignment requires at least one variable")
+	}
 	varsStr := strings.Join(a.Variables, ", ")
 	op := "="
 	if a.IsStream {
@@ -198,6 +239,8 @@ func (c *Compiler) compilePattern(pattern Pattern) (string, error) {
 		return c.compileOrPattern(p)
 	case IidPattern:
 		return p.Variable + " iid " + p.IID, nil
+	case IsPattern:
+		return p.LeftVar + " is " + p.RightVar, nil
 	case AttributePattern:
 		return c.compileAttributePattern(p)
 	case RawPattern:
@@ -356,15 +399,14 @@ func (c *Compiler) compileConstraint(constraint Constraint) (string, error) {
 func (c *Compiler) compileValue(v Value) (string, error) {
 	switch val := v.(type) {
 	case ArithmeticValue:
-		leftStr, err := c.compileValueOrString(val.Left)
-		if err != nil {
-			return "", err
-		}
-		rightStr, err := c.compileValueOrString(val.Right)
+		return c.compileArithmetic(val)
+
+	case ParenValue:
+		inner, err := c.compileValueOrString(val.Inner)
 		if err != nil {
 			return "", err
 		}
-		return fmt.Sprintf("(%s %s %s)", leftStr, val.Operator, rightStr), nil
+		return "(" + inner + ")", nil
 
 	case FunctionCallValue:
 		args := make([]string, 0, len(val.Args))
@@ -378,13 +420,59 @@ func (c *Compiler) compileValue(v Value) (string, error) {
 		return fmt.Sprintf("%s(%s)", val.Function, strings.Join(args, ", ")), nil
 
 	case LiteralValue:
-		return FormatLiteral(val.Val, val.ValueType), nil
+		return FormatLiteralWithOptions(val.Val, val.ValueType, c.Literals), nil
 
 	default:
 		return "", fmt.Errorf("unknown value type: %T", v)
 	}
 }
 
+// compileArithmetic compiles a binary operation, parenthesizing nested
+// operands only where operator precedence or associativity requires it.
+func (c *Compiler) compileArithmetic(val ArithmeticValue) (string, error) {
+	prec := operatorPrecedence(val.Operator)
+	rightAssoc := val.Operator == "^"
+
+	leftStr, err := c.compileValueOrString(val.Left)
+	if err != nil {
+		return "", err
+	}
+	if l, ok := val.Left.(ArithmeticValue); ok {
+		lp := operatorPrecedence(l.Operator)
+		if lp < prec || (lp == prec && rightAssoc) {
+			leftStr = "(" + leftStr + ")"
+		}
+	}
+
+	rightStr, err := c.compileValueOrString(val.Right)
+	if err != nil {
+		return "", err
+	}
+	if r, ok := val.Right.(ArithmeticValue); ok {
+		rp := operatorPrecedence(r.Operator)
+		if rp < prec || (rp == prec && !rightAssoc) {
+			rightStr = "(" + rightStr + ")"
+		}
+	}
+
+	return leftStr + " " + val.Operator + " " + rightStr, nil
+}
+
+// operatorPrecedence ranks TypeQL infix operators; unknown operators rank
+// lowest so their operands are always parenthesized.
+func operatorPrecedence(op string) int {
+	switch op {
+	case "^":
+		return 3
+	case "*", "/", "%":
+		return 2
+	case "+", "-":
+		return 1
+	default:
+		return 0
+	}
+}
+
 func (c *Compiler) compileValueOrString(v any) (string, error) {
 	switch val := v.(type) {
 	case Value:
@@ -509,11 +597,26 @@ func (c *Compiler) compileFetchItem(item any) (string, error) {
 	case FetchNestedWildcard:
 		return `"` + fi.Key + `": { ` + fi.Var + ".* }", nil
 
+	case FetchSubquery:
+		return c.compileFetchSubquery(fi)
+
 	default:
 		return "", fmt.Errorf("unknown fetch item type: %T", item)
 	}
 }
 
+func (c *Compiler) compileFetchSubquery(fi FetchSubquery) (string, error) {
+	matchStr, err := c.compileMatchClause(fi.Match)
+	if err != nil {
+		return "", err
+	}
+	fetchStr, err := c.compileFetchClause(fi.Fetch)
+	if err != nil {
+		return "", err
+	}
+	return `"` + fi.Key + `": [` + "\n" + matchStr + "\n" + fetchStr + "\n]", nil
+}
+
 // --- Reduce ---
 
 func (c *Compiler) compileReduceAssignment(a ReduceAssignment) (string, error) {
@@ -524,8 +627,153 @@ func (c *Compiler) compileReduceAssignment(a ReduceAssignment) (string, error) {
 	return a.Variable + " = " + exprStr, nil
 }
 
-// FormatLiteral formats a Go value as a TypeQL literal string.
+// --- Schema Definitions ---
+
+func (c *Compiler) compileDefinition(def Definition) (string, error) {
+	switch d := def.(type) {
+	case TypeDefinition:
+		var b strings.Builder
+		if d.Kind != "" {
+			b.WriteString(d.Kind)
+			b.WriteByte(' ')
+		}
+		b.WriteString(d.TypeName)
+		if err := c.appendAnnotations(&b, d.Annotations); err != nil {
+			return "", err
+		}
+		if d.Supertype != "" {
+			b.WriteString(", sub ")
+			b.WriteString(d.Supertype)
+		}
+		for _, capability := range d.Capabilities {
+			s, err := c.compileCapability(capability)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(",\n    ")
+			b.WriteString(s)
+		}
+		b.WriteByte(';')
+		return b.String(), nil
+	default:
+		return "", fmt.Errorf("unknown definition type: %T", def)
+	}
+}
+
+func (c *Compiler) compileCapability(capability Capability) (string, error) {
+	var b strings.Builder
+	var anns []Annotation
+	switch cp := capability.(type) {
+	case OwnsCapability:
+		b.WriteString("owns " + cp.AttrName)
+		anns = cp.Annotations
+	case RelatesCapability:
+		b.WriteString("relates " + cp.RoleName)
+		anns = cp.Annotations
+	case PlaysCapability:
+		b.WriteString("plays " + cp.Role)
+		anns = cp.Annotations
+	case ValueTypeCapability:
+		b.WriteString("value " + cp.ValueType)
+		anns = cp.Annotations
+	default:
+		return "", fmt.Errorf("unknown capability type: %T", capability)
+	}
+	if err := c.appendAnnotations(&b, anns); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (c *Compiler) appendAnnotations(b *strings.Builder, anns []Annotation) error {
+	for _, ann := range anns {
+		s, err := c.compileAnnotation(ann)
+		if err != nil {
+			return err
+		}
+		b.WriteByte(' ')
+		b.WriteString(s)
+	}
+	return nil
+}
+
+func (c *Compiler) compileAnnotation(ann Annotation) (string, error) {
+	switch a := ann.(type) {
+	case KeyAnnotation:
+		return "@key", nil
+	case UniqueAnnotation:
+		return "@unique", nil
+	case IndependentAnnotation:
+		return "@independent", nil
+	case AbstractAnnotation:
+		return "@abstract", nil
+	case CardAnnotation:
+		if a.Max == nil {
+			return "@card(" + strconv.Itoa(a.Min) + "..)", nil
+		}
+		if *a.Max < a.Min {
+			return "", fmt.Errorf("invalid @card range %d..%d", a.Min, *a.Max)
+		}
+		return "@card(" + strconv.Itoa(a.Min) + ".." + strconv.Itoa(*a.Max) + ")", nil
+	case ValuesAnnotation:
+		if len(a.Values) == 0 {
+			return "", fmt.Errorf("@values requires at least one value")
+		}
+		return joinCompiled("@values(", ", ", ")", a.Values, c.compileValue)
+	case RegexAnnotation:
+		return `@regex("` + EscapeString(a.Pattern) + `")`, nil
+	default:
+		return "", fmt.Errorf("unknown annotation type: %T", ann)
+	}
+}
+
+// TimePrecision controls how many fractional-second digits datetime literals carry.
+type TimePrecision int
+
+const (
+	// PrecisionAuto emits only the fractional digits needed to represent the
+	// value exactly (up to nanoseconds), and none for whole seconds.
+	PrecisionAuto TimePrecision = iota
+	// PrecisionSeconds truncates datetimes to whole seconds.
+	PrecisionSeconds
+	// PrecisionMillis always emits three fractional digits.
+	PrecisionMillis
+	// PrecisionMicros always emits six fractional digits.
+	PrecisionMicros
+	// PrecisionNanos always emits nine fractional digits.
+	PrecisionNanos
+)
+
+// ZoneStyle controls how the zone of a datetime-tz literal is rendered.
+type ZoneStyle int
+
+const (
+	// ZoneOffset renders an ISO 8601 offset, using "Z" for UTC.
+	ZoneOffset ZoneStyle = iota
+	// ZoneExplicitOffset always renders a numeric offset, including "+00:00" for UTC.
+	ZoneExplicitOffset
+	// ZoneName renders the IANA zone name (e.g. "2024-01-15T10:30:00 Europe/London").
+	// Locations without an IANA name fall back to an explicit offset.
+	ZoneName
+)
+
+// LiteralOptions configures how temporal literals are formatted.
+// The zero value uses PrecisionAuto and ZoneOffset.
+type LiteralOptions struct {
+	// Precision is the fractional-second precision for datetime and datetime-tz literals.
+	Precision TimePrecision
+	// Zone is the zone rendering style for datetime-tz literals.
+	Zone ZoneStyle
+}
+
+// FormatLiteral formats a Go value as a TypeQL literal string using the default LiteralOptions.
 func FormatLiteral(val any, valueType string) string {
+	return FormatLiteralWithOptions(val, valueType, LiteralOptions{})
+}
+
+// FormatLiteralWithOptions formats a Go value as a TypeQL literal string.
+// The options only affect time.Time values of type "datetime" and "datetime-tz".
+func FormatLiteralWithOptions(val any, valueType string, opts LiteralOptions) string {
 	switch valueType {
 	case "string":
 		s, _ := val.(string)
@@ -542,12 +790,12 @@ func FormatLiteral(val any, valueType string) string {
 		return formatFloat(val)
 	case "datetime":
 		if t, ok := val.(time.Time); ok {
-			return t.Format("2006-01-02T15:04:05")
+			return t.Format(datetimeLayout(opts.Precision))
 		}
 		return fmt.Sprint(val)
 	case "datetime-tz":
 		if t, ok := val.(time.Time); ok {
-			return t.Format(time.RFC3339)
+			return formatDatetimeTZ(t, opts)
 		}
 		return fmt.Sprint(val)
 	case "date":
@@ -560,15 +808,76 @@ func FormatLiteral(val any, valueType string) string {
 	}
 }
 
+// datetimeLayout returns the time layout for a zone-less datetime at the given precision.
+func datetimeLayout(p TimePrecision) string {
+	switch p {
+	case PrecisionSeconds:
+		return "2006-01-02T15:04:05"
+	case PrecisionMillis:
+		return "2006-01-02T15:04:05.000"
+	case PrecisionMicros:
+		return "2006-01-02T15:04:05.000000"
+	case PrecisionNanos:
+		return "2006-01-02T15:04:05.000000000"
+	default:
+		return "2006-01-02T15:04:05.999999999"
+	}
+}
+
+func formatDatetimeTZ(t time.Time, opts LiteralOptions) string {
+	layout := datetimeLayout(opts.Precision)
+	switch opts.Zone {
+	case ZoneExplicitOffset:
+		return t.Format(layout + "-07:00")
+	case ZoneName:
+		if name := t.Location().String(); name == "UTC" || strings.Contains(name, "/") {
+			return t.Format(layout) + " " + name
+		}
+		return t.Format(layout + "-07:00")
+	default:
+		return t.Format(layout + "Z07:00")
+	}
+}
+
 // EscapeString escapes special characters in a string for use in TypeQL string literals.
 // It handles backslashes, quotes, newlines, carriage returns, and tabs.
+// Strings without special characters are returned unchanged without allocating.
 func EscapeString(s string) string {
-	s = strings.ReplaceAll(s, `\`, `\\`)
-	s = strings.ReplaceAll(s, `"`, `\"`)
-	s = strings.ReplaceAll(s, "\n", `\n`)
-	s = strings.ReplaceAll(s, "\r", `\r`)
-	s = strings.ReplaceAll(s, "\t", `\t`)
-	return s
+	if !needsEscape(s) {
+		return s
+	}
+	return string(appendEscaped(make([]byte, 0, len(s)+8), s))
+}
+
+// needsEscape reports whether s contains a character EscapeString rewrites.
+func needsEscape(s string) bool {
+	return strings.ContainsAny(s, "\\\"\n\r\t")
+}
+
+// appendEscaped appends the escaped form of s to dst in a single pass.
+func appendEscaped(dst []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		var esc string
+		switch s[i] {
+		case '\\':
+			esc = `\\`
+		case '"':
+			esc = `\"`
+		case '\n':
+			esc = `\n`
+		case '\r':
+			esc = `\r`
+		case '\t':
+			esc = `\t`
+		default:
+			continue
+		}
+		dst = append(dst, s[start:i]...)
+		dst = append(dst, esc...)
+		start = i + 1
+	}
+	return append(dst, s[start:]...)
 }
 
 // FormatGoValue converts a Go value into its TypeQL literal string representation.
@@ -576,51 +885,97 @@ func EscapeString(s string) string {
 // This is the canonical formatting function for Go values; other packages should use this
 // instead of implementing their own formatting logic.
 func FormatGoValue(value any) string {
-	if value == nil {
+	// Fast paths for the common scalar types avoid reflection and produce
+	// at most the result allocation.
+	switch v := value.(type) {
+	case nil:
 		return "null"
-	}
-
-	v := reflect.ValueOf(value)
-
-	// Dereference pointers
-	for v.Kind() == reflect.Pointer {
-		if v.IsNil() {
-			return "null"
-		}
-		v = v.Elem()
-		value = v.Interface()
-	}
-
-	switch val := value.(type) {
 	case string:
-		return `"` + EscapeString(val) + `"`
+		if !needsEscape(v) {
+			return `"` + v + `"`
+		}
 	case bool:
-		if val {
+		if v {
 			return "true"
 		}
 		return "false"
-	case int, int8, int16, int32, int64:
-		return formatInteger(val)
-	case uint, uint8, uint16, uint32, uint64:
-		return formatInteger(val)
-	case float32, float64:
-		return formatFloat(val)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	var buf [64]byte
+	return string(AppendGoValue(buf[:0], value))
+}
+
+// AppendGoValue appends the TypeQL literal for value to dst and returns the
+// extended buffer. The output is identical to FormatGoValue; callers that
+// assemble queries in a reusable buffer use it to avoid intermediate strings.
+func AppendGoValue(dst []byte, value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(dst, "null"...)
+	case string:
+		dst = append(dst, '"')
+		dst = appendEscaped(dst, v)
+		return append(dst, '"')
+	case bool:
+		return strconv.AppendBool(dst, v)
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(dst, v, 10)
+	case uint:
+		return strconv.AppendUint(dst, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(dst, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(dst, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(dst, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(dst, v, 10)
+	case float32:
+		return strconv.AppendFloat(dst, float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.AppendFloat(dst, v, 'f', -1, 64)
 	case time.Time:
-		// Date-only format (midnight UTC)
-		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
-			return val.Format("2006-01-02")
-		}
-		// DateTime without timezone (UTC)
-		if val.Location() == time.UTC {
-			return val.Format("2006-01-02T15:04:05")
+		return v.AppendFormat(dst, goTimeLayout(v))
+	}
+
+	// Dereference pointers
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return append(dst, "null"...)
 		}
-		// DateTime with timezone
-		return val.Format(time.RFC3339)
-	default:
-		// Fallback: convert to string and escape
-		s := fmt.Sprint(val)
-		return `"` + EscapeString(s) + `"`
+		return AppendGoValue(dst, rv.Elem().Interface())
+	}
+
+	// Fallback: convert to string and escape
+	dst = append(dst, '"')
+	dst = appendEscaped(dst, fmt.Sprint(value))
+	return append(dst, '"')
+}
+
+// goTimeLayout picks the literal layout for t: date-only for midnight UTC,
+// a zone-less datetime for other UTC times, and RFC 3339 otherwise.
+func goTimeLayout(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return "2006-01-02"
+	}
+	if t.Location() == time.UTC {
+		return "2006-01-02T15:04:05"
 	}
+	return time.RFC3339
 }
 
 func formatInteger(val any) string {
@@ -0,0 +1,119 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// hostileStrings seed the fuzz targets with inputs that break naive quoting.
+var hostileStrings = []string{
+	"",
+	"plain",
+	`say "hi"`,
+	`trailing backslash\`,
+	`\"`,
+	`\\"; delete $p; #`,
+	"line1\nline2\r\n\tend",
+	`"; match $x isa thing; insert $y isa evil; "`,
+	"\x00\x1b[31m\x7f",
+	"\xff\xfe invalid utf-8 \xc3",
+	"emoji 🚀 and ünïcödé",
+	`\n is not a newline`,
+}
+
+// unquoteTypeQL parses a TypeQL string literal, rejecting raw quotes, line
+// breaks, tabs and backslashes that do not start a known escape.
+func unquoteTypeQL(lit string) (string, error) {
+	if len(lit) < 2 || lit[0] != '"' || lit[len(lit)-1] != '"' {
+		return "", fmt.Errorf("not a quoted literal: %q", lit)
+	}
+	body := lit[1 : len(lit)-1]
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; c {
+		case '"', '\n', '\r', '\t':
+			return "", fmt.Errorf("unescaped %q at offset %d", c, i+1)
+		case '\\':
+			i++
+			if i == len(body) {
+				return "", fmt.Errorf("dangling backslash in %q", lit)
+			}
+			switch body[i] {
+			case '\\', '"':
+				b.WriteByte(body[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				return "", fmt.Errorf("unknown escape \\%c in %q", body[i], lit)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+func FuzzEscapeString(f *testing.F) {
+	for _, s := range hostileStrings {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		lit := `"` + EscapeString(s) + `"`
+		got, err := unquoteTypeQL(lit)
+		if err != nil {
+			t.Fatalf("EscapeString(%q): %v", s, err)
+		}
+		if got != s {
+			t.Fatalf("EscapeString(%q) round-trips to %q", s, got)
+		}
+		if !needsEscape(s) && EscapeString(s) != s {
+			t.Fatalf("EscapeString(%q) changed a string without special characters", s)
+		}
+
+		for name, other := range map[string]string{
+			"FormatLiteral":           FormatLiteral(s, "string"),
+			"FormatLiteral (unknown)": FormatLiteral(s, "unknown"),
+			"FormatGoValue":           FormatGoValue(s),
+			"AppendGoValue":           string(AppendGoValue(nil, s)),
+		} {
+			if other != lit {
+				t.Fatalf("%s(%q) = %s, want %s", name, s, other, lit)
+			}
+		}
+	})
+}
+
+func FuzzCompileStringLiteral(f *testing.F) {
+	for _, s := range hostileStrings {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		c := &Compiler{}
+		query, err := c.Compile(MatchClause{Patterns: []Pattern{
+			EntityPattern{Variable: "$p", TypeName: "person", Constraints: []Constraint{
+				HasConstraint{AttrName: "name", Value: Str(s)},
+			}},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The value must stay inside its literal: the query is the fixed
+		// text around exactly one well-formed string.
+		const prefix, suffix = "match\n$p isa person, has name ", ";"
+		if !strings.HasPrefix(query, prefix) || !strings.HasSuffix(query, suffix) {
+			t.Fatalf("unexpected query shape for %q:\n%s", s, query)
+		}
+		got, err := unquoteTypeQL(query[len(prefix) : len(query)-len(suffix)])
+		if err != nil {
+			t.Fatalf("value %q: %v\n%s", s, err, query)
+		}
+		if got != s {
+			t.Fatalf("value %q compiled to a literal of %q", s, got)
+		}
+	})
+}
@@ -136,6 +136,24 @@ $p isa person, has name "Alice";`,
 			},
 			want: "match\n$p isa person;\n{ $p has name $n; } or { $p has email $e; };",
 		},
+		{
+			name: "is pattern negated to exclude self-match",
+			node: Match(
+				Relation("", "friendship", []RolePlayer{Role("friend", "$p"), Role("friend", "$f")}),
+				Relation("", "friendship", []RolePlayer{Role("friend", "$f"), Role("friend", "$fof")}),
+				Not(Is("$p", "$fof")),
+			),
+			want: "match\n(friend: $p, friend: $f) isa friendship;\n(friend: $f, friend: $fof) isa friendship;\nnot { $p is $fof; };",
+		},
+		{
+			name: "is pattern",
+			node: MatchClause{
+				Patterns: []Pattern{
+					IsPattern{LeftVar: "$x", RightVar: "$y"},
+				},
+			},
+			want: "match\n$x is $y;",
+		},
 		{
 			name: "iid pattern",
 			node: MatchClause{
@@ -291,6 +309,60 @@ $e has age 30;`,
 	}
 }
 
+func TestCompiler_MatchInsertClause(t *testing.T) {
+	c := &Compiler{}
+	node := MatchInsert(
+		[]Pattern{
+			Entity("$p", "person", Has("name", Str("Alice"))),
+			Entity("$c", "company", Has("name", Str("Acme"))),
+		},
+		[]Pattern{
+			Relation("$r", "employment", []RolePlayer{Role("employee", "$p"), Role("employer", "$c")}),
+		},
+		RelationStmt("employment", Role("employee", "$p"), Role("employer", "$c")),
+	)
+	got, err := c.Compile(node)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	want := `match
+$p isa person, has name "Alice";
+$c isa company, has name "Acme";
+not { $r isa employment (employee: $p, employer: $c); };
+insert
+(employee: $p, employer: $c) isa employment;`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A guard alone is enough when the insert binds no matched variables.
+	got, err = c.Compile(MatchInsert(nil,
+		[]Pattern{Entity("$t", "tag", Has("label", Str("go")))},
+		IsaStmt("$n", "tag"), HasStmt("$n", "label", Str("go")),
+	))
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	want = `match
+not { $t isa tag, has label "go"; };
+insert
+$n isa tag;
+$n has label "go";`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	for name, bad := range map[string]MatchInsertClause{
+		"no guard":      MatchInsert(nil, nil, IsaStmt("$n", "tag")),
+		"no statements": MatchInsert(nil, []Pattern{Entity("$t", "tag")}),
+		"bad guard":     MatchInsert(nil, []Pattern{nil}, IsaStmt("$n", "tag")),
+	} {
+		if _, err := c.Compile(bad); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
 func TestCompiler_DeleteClause(t *testing.T) {
 	c := &Compiler{}
 	got, err := c.Compile(DeleteClause{
@@ -396,6 +468,31 @@ func TestCompiler_FetchClause(t *testing.T) {
 			},
 			want: `fetch {
   "nested": { $p.* }
+};`,
+		},
+		{
+			name: "fetch subquery",
+			node: FetchClause{
+				Items: []any{
+					FetchAttribute{Key: "name", Var: "$p", AttrName: "name"},
+					FetchSubquery{
+						Key: "friends",
+						Match: Match(
+							Relation("", "friendship", []RolePlayer{Role("friend", "$p"), Role("friend", "$f")}),
+						),
+						Fetch: Fetch(FetchAttr("name", "$f", "name")),
+					},
+				},
+			},
+			want: `fetch {
+  "name": $p.name,
+  "friends": [
+match
+(friend: $p, friend: $f) isa friendship;
+fetch {
+  "name": $f.name
+};
+]
 };`,
 		},
 		{
@@ -499,6 +596,24 @@ func TestCompiler_MatchLetClause(t *testing.T) {
 			},
 			want: "match\nlet $x in iid($p);",
 		},
+		{
+			name: "let destructuring stream",
+			node: MatchLetClause{
+				Assignments: []LetAssignment{
+					LetIn(FuncCall("tag_counts", "$u"), "$tag", "$count"),
+				},
+			},
+			want: "match\nlet $tag, $count in tag_counts($u);",
+		},
+		{
+			name: "let destructuring scalar tuple",
+			node: MatchLetClause{
+				Assignments: []LetAssignment{
+					LetEq(FuncCall("min_max", "$u"), "$lo", "$hi"),
+				},
+			},
+			want: "match\nlet $lo, $hi = min_max($u);",
+		},
 		{
 			name: "let with function call value",
 			node: MatchLetClause{
@@ -588,7 +703,47 @@ func TestCompiler_Values(t *testing.T) {
 		{
 			name: "arithmetic value",
 			node: ArithmeticValue{Left: "$x", Operator: "+", Right: LiteralValue{Val: int64(1), ValueType: "long"}},
-			want: "($x + 1)",
+			want: "$x + 1",
+		},
+		{
+			name: "arithmetic lower precedence operand is parenthesized",
+			node: Mul(Add("$a", "$b"), "$c"),
+			want: "($a + $b) * $c",
+		},
+		{
+			name: "arithmetic higher precedence operand is not parenthesized",
+			node: Add("$a", Mul("$b", 2)),
+			want: "$a + $b * 2",
+		},
+		{
+			name: "arithmetic left-associative right operand",
+			node: Sub("$a", Sub("$b", "$c")),
+			want: "$a - ($b - $c)",
+		},
+		{
+			name: "arithmetic left-associative left operand",
+			node: Sub(Sub("$a", "$b"), "$c"),
+			want: "$a - $b - $c",
+		},
+		{
+			name: "arithmetic power is right-associative",
+			node: Pow(Pow("$a", "$b"), Pow("$c", 2)),
+			want: "($a ^ $b) ^ $c ^ 2",
+		},
+		{
+			name: "explicit paren",
+			node: Paren(Add("$a", 1.5)),
+			want: "($a + 1.5)",
+		},
+		{
+			name: "arithmetic plain string operand is a literal",
+			node: Add("$x", "abc"),
+			want: `$x + "abc"`,
+		},
+		{
+			name: "arithmetic string operand with quotes is escaped",
+			node: Paren(`a"b`),
+			want: `("a\"b")`,
 		},
 		{
 			name: "function call value",
@@ -615,6 +770,191 @@ func TestCompiler_Values(t *testing.T) {
 	}
 }
 
+func TestCompiler_LetAssignmentRequiresVariables(t *testing.T) {
+	c := &Compiler{}
+	_, err := c.Compile(MatchLetClause{Assignments: []LetAssignment{LetIn("f()")}})
+	if err == nil {
+		t.Fatal("expected error for let assignment without variables")
+	}
+}
+
+func TestCompiler_TemporalLiterals(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	frac := time.Date(2024, 1, 15, 10, 30, 0, 123456789, time.UTC)
+	whole := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		opts LiteralOptions
+		node Value
+		want string
+	}{
+		{
+			name: "auto keeps sub-second precision",
+			node: Datetime(frac),
+			want: "2024-01-15T10:30:00.123456789",
+		},
+		{
+			name: "auto omits zero fraction",
+			node: Datetime(whole),
+			want: "2024-01-15T10:30:00",
+		},
+		{
+			name: "seconds precision",
+			opts: LiteralOptions{Precision: PrecisionSeconds},
+			node: Datetime(frac),
+			want: "2024-01-15T10:30:00",
+		},
+		{
+			name: "millis precision",
+			opts: LiteralOptions{Precision: PrecisionMillis},
+			node: Datetime(frac),
+			want: "2024-01-15T10:30:00.123",
+		},
+		{
+			name: "micros precision pads",
+			opts: LiteralOptions{Precision: PrecisionMicros},
+			node: Datetime(whole),
+			want: "2024-01-15T10:30:00.000000",
+		},
+		{
+			name: "datetime-tz utc uses Z",
+			node: DatetimeTZ(whole),
+			want: "2024-01-15T10:30:00Z",
+		},
+		{
+			name: "datetime-tz explicit offset",
+			opts: LiteralOptions{Zone: ZoneExplicitOffset},
+			node: DatetimeTZ(whole),
+			want: "2024-01-15T10:30:00+00:00",
+		},
+		{
+			name: "datetime-tz offset from location",
+			opts: LiteralOptions{Precision: PrecisionMillis},
+			node: DatetimeTZ(frac.In(berlin)),
+			want: "2024-01-15T11:30:00.123+01:00",
+		},
+		{
+			name: "datetime-tz iana name",
+			opts: LiteralOptions{Zone: ZoneName},
+			node: DatetimeTZ(whole.In(berlin)),
+			want: "2024-01-15T11:30:00 Europe/Berlin",
+		},
+		{
+			name: "datetime-tz fixed zone falls back to offset",
+			opts: LiteralOptions{Zone: ZoneName},
+			node: DatetimeTZ(whole.In(time.FixedZone("", -5*3600))),
+			want: "2024-01-15T05:30:00-05:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Compiler{Literals: tt.opts}
+			got, err := c.Compile(tt.node)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompiler_DefineClause(t *testing.T) {
+	c := &Compiler{}
+	tests := []struct {
+		name string
+		node QueryNode
+		want string
+	}{
+		{
+			name: "attribute with regex",
+			node: AttributeType("email", "string", Regex(`^[^@]+@[^@]+\.\w+$`)),
+			want: `attribute email,
+    value string @regex("^[^@]+@[^@]+\\.\\w+$");`,
+		},
+		{
+			name: "independent attribute with values",
+			node: TypeDefinition{
+				Kind:         "attribute",
+				TypeName:     "status",
+				Annotations:  []Annotation{IndependentAnnotation{}},
+				Capabilities: []Capability{ValueTypeCapability{ValueType: "string", Annotations: []Annotation{Values("open", "closed")}}},
+			},
+			want: `attribute status @independent,
+    value string @values("open", "closed");`,
+		},
+		{
+			name: "abstract entity with ownership annotations",
+			node: TypeDefinition{
+				Kind:        "entity",
+				TypeName:    "user",
+				Supertype:   "party",
+				Annotations: []Annotation{AbstractAnnotation{}},
+				Capabilities: []Capability{
+					Owns("user-id", KeyAnnotation{}),
+					Owns("email", UniqueAnnotation{}, Card(0, 1)),
+					Owns("tag", CardAtLeast(0)),
+					Plays("friendship:friend"),
+				},
+			},
+			want: `entity user @abstract, sub party,
+    owns user-id @key,
+    owns email @unique @card(0..1),
+    owns tag @card(0..),
+    plays friendship:friend;`,
+		},
+		{
+			name: "relation with role cardinality",
+			node: RelationType("friendship", Relates("friend", Card(2, 2))),
+			want: `relation friendship,
+    relates friend @card(2..2);`,
+		},
+		{
+			name: "define clause",
+			node: Define(
+				AttributeType("name", "string"),
+				EntityType("person", Owns("name", KeyAnnotation{})),
+			),
+			want: `define
+attribute name,
+    value string;
+entity person,
+    owns name @key;`,
+		},
+		{
+			name: "annotation fragment",
+			node: Card(1, 3),
+			want: "@card(1..3)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Compile(tt.node)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompiler_InvalidAnnotations(t *testing.T) {
+	c := &Compiler{}
+	for _, node := range []QueryNode{Card(3, 1), ValuesAnnotation{}} {
+		if _, err := c.Compile(node); err == nil {
+			t.Errorf("expected error compiling %#v", node)
+		}
+	}
+}
+
 func TestCompiler_CompileBatch(t *testing.T) {
 	c := &Compiler{}
 	nodes := []QueryNode{
@@ -885,3 +1225,55 @@ func TestCompiler_TypelessRelation(t *testing.T) {
 		})
 	}
 }
+
+type testNamedString string
+
+func TestFormatGoValue_AppendParity(t *testing.T) {
+	name := "alice"
+	var nilPtr *int
+	values := []any{
+		nil, "plain", "say \"hi\"\n\tpath\\to\r", "", true, false,
+		int(-7), int8(8), int16(16), int32(32), int64(1 << 40),
+		uint(1), uint8(8), uint16(16), uint32(32), uint64(1 << 63),
+		float32(2.5), 3.1415926535, 1e21,
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 10, 30, 0, 0, time.FixedZone("PDT", -7*60*60)),
+		&name, nilPtr, testNamedString(`a"b`),
+	}
+	want := []string{
+		"null", `"plain"`, `"say \"hi\"\n\tpath\\to\r"`, `""`, "true", "false",
+		"-7", "8", "16", "32", "1099511627776",
+		"1", "8", "16", "32", "9223372036854775808",
+		"2.5", "3.1415926535", "1000000000000000000000",
+		"2024-01-15", "2024-01-15T10:30:00", "2024-01-15T10:30:00-07:00",
+		`"alice"`, "null", `"a\"b"`,
+	}
+	for i, v := range values {
+		if got := FormatGoValue(v); got != want[i] {
+			t.Errorf("FormatGoValue(%#v) = %s, want %s", v, got, want[i])
+		}
+		if got := string(AppendGoValue([]byte("x="), v)); got != "x="+want[i] {
+			t.Errorf("AppendGoValue(%#v) = %s, want x=%s", v, got, want[i])
+		}
+	}
+}
+
+func TestEscapeString_NoAllocWithoutSpecials(t *testing.T) {
+	s := "nothing to escape here"
+	if got := EscapeString(s); got != s {
+		t.Fatalf("EscapeString = %q", got)
+	}
+	if n := testing.AllocsPerRun(100, func() { _ = EscapeString(s) }); n != 0 {
+		t.Errorf("EscapeString allocated %.0f times, want 0", n)
+	}
+}
+
+func TestFormatGoValue_ScalarAllocs(t *testing.T) {
+	values := []any{"Alice Example", true, int64(123456), 3.25}
+	for _, v := range values {
+		if n := testing.AllocsPerRun(100, func() { _ = FormatGoValue(v) }); n > 1 {
+			t.Errorf("FormatGoValue(%#v) allocated %.0f times, want at most 1", v, n)
+		}
+	}
+}
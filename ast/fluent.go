@@ -98,9 +98,6 @@ func (s fluentState) nodes() []QueryNode {
 	if len(s.insertStatements) > 0 {
 		nodes = append(nodes, Insert(s.insertStatements...))
 	}
-	if len(s.fetchItems) > 0 {
-		nodes = append(nodes, Fetch(s.fetchItems...))
-	}
 	if len(s.selectVars) > 0 {
 		nodes = append(nodes, Select(s.selectVars...))
 	}
@@ -113,6 +110,11 @@ func (s fluentState) nodes() []QueryNode {
 	if s.limitClause != nil {
 		nodes = append(nodes, *s.limitClause)
 	}
+	// Fetch ends a TypeQL 3 pipeline, so it comes after the stream stages
+	// whatever the order of the builder calls.
+	if len(s.fetchItems) > 0 {
+		nodes = append(nodes, Fetch(s.fetchItems...))
+	}
 	return nodes
 }
 
@@ -156,11 +158,13 @@ type MatchResultStage interface {
 // FunctionStage is the pre-output stage for function-based match-let queries.
 type FunctionStage interface {
 	Select(vars ...string) FunctionResultStage
+	Fetch(vars ...string) FunctionResultStage
 }
 
 // FunctionResultStage is the output stage for function queries.
 type FunctionResultStage interface {
 	Select(vars ...string) FunctionResultStage
+	Fetch(vars ...string) FunctionResultStage
 	Sort(variable, direction string) FunctionResultStage
 	Limit(count int) FunctionResultStage
 	Offset(count int) FunctionResultStage
@@ -208,6 +212,35 @@ func FluentPatterns(patterns ...Pattern) MatchStage {
 
 // MatchFunction starts a fluent function query compiled as match-let.
 func MatchFunction(funcName string, args ...any) FunctionStage {
+	return FunctionBuilder{state: fluentState{
+		mainVar: "$result",
+		matchLet: []LetAssignment{
+			LetEq(formatFunctionCall(funcName, args), "$result"),
+		},
+	}}
+}
+
+// MatchFunctionStream starts a fluent query that destructures each row of a
+// stream function into vars (let $a, $b in fun(...)). Use it to consume
+// functions returning tuples such as { string, integer }.
+func MatchFunctionStream(vars []string, funcName string, args ...any) FunctionStage {
+	bound := make([]string, 0, len(vars))
+	for _, v := range vars {
+		bound = append(bound, ensureVar(v))
+	}
+	mainVar := "$result"
+	if len(bound) > 0 {
+		mainVar = bound[0]
+	}
+	return FunctionBuilder{state: fluentState{
+		mainVar: mainVar,
+		matchLet: []LetAssignment{
+			LetIn(formatFunctionCall(funcName, args), bound...),
+		},
+	}}
+}
+
+func formatFunctionCall(funcName string, args []any) string {
 	compiledArgs := make([]string, 0, len(args))
 	for _, arg := range args {
 		switch v := arg.(type) {
@@ -221,15 +254,7 @@ func MatchFunction(funcName string, args ...any) FunctionStage {
 			compiledArgs = append(compiledArgs, FormatGoValue(v))
 		}
 	}
-
-	call := fmt.Sprintf("%s(%s)", funcName, strings.Join(compiledArgs, ", "))
-	return FunctionBuilder{state: fluentState{
-		mainVar: "$result",
-		matchLet: []LetAssignment{{
-			Variables:  []string{"$result"},
-			Expression: call,
-		}},
-	}}
+	return fmt.Sprintf("%s(%s)", funcName, strings.Join(compiledArgs, ", "))
 }
 
 // Has adds a has constraint to the primary matched variable.
@@ -422,6 +447,14 @@ func (b FunctionBuilder) Select(vars ...string) FunctionResultStage {
 	return FunctionOutputBuilder{state: next}
 }
 
+// Fetch transitions function query to output stage, fetching each variable
+// under its name without the leading "$".
+func (b FunctionBuilder) Fetch(vars ...string) FunctionResultStage {
+	next := b.state.clone()
+	next.addFetchVars(vars)
+	return FunctionOutputBuilder{state: next}
+}
+
 // Select adds additional selected variables in output stage.
 func (b FunctionOutputBuilder) Select(vars ...string) FunctionResultStage {
 	next := b.state.clone()
@@ -431,6 +464,13 @@ func (b FunctionOutputBuilder) Select(vars ...string) FunctionResultStage {
 	return FunctionOutputBuilder{state: next}
 }
 
+// Fetch adds fetched variables in output stage.
+func (b FunctionOutputBuilder) Fetch(vars ...string) FunctionResultStage {
+	next := b.state.clone()
+	next.addFetchVars(vars)
+	return FunctionOutputBuilder{state: next}
+}
+
 // Sort configures a sort clause.
 func (b FunctionOutputBuilder) Sort(variable, direction string) FunctionResultStage {
 	next := b.state.clone()
@@ -570,6 +610,13 @@ func (s *fluentState) addPatternConstraint(constraint Constraint) {
 	s.matchPatterns[0] = first
 }
 
+func (s *fluentState) addFetchVars(vars []string) {
+	for _, v := range vars {
+		v = ensureVar(v)
+		s.fetchItems = append(s.fetchItems, FetchVar(strings.TrimPrefix(v, "$"), v))
+	}
+}
+
 func inferMainVar(patterns []Pattern) string {
 	for _, pattern := range patterns {
 		switch p := pattern.(type) {
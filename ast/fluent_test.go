@@ -50,6 +50,37 @@ func TestMatchFunction_SelectBuild(t *testing.T) {
 	}
 }
 
+func TestMatchFunctionStream_DestructureFetch(t *testing.T) {
+	query, err := MatchFunctionStream([]string{"name", "$count"}, "tag_counts", "$user", 10).
+		Fetch("name", "count").
+		Sort("$count", "desc").
+		Build()
+	if err != nil {
+		t.Fatalf("build error: %v", err)
+	}
+
+	want := "match\nlet $name, $count in tag_counts($user, 10);\nsort $count desc;\n" +
+		"fetch {\n  \"name\": $name,\n  \"count\": $count\n};"
+	if query != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", query, want)
+	}
+}
+
+func TestFluentMatch_FetchIsLastStage(t *testing.T) {
+	query, err := FluentMatch("p", "person").
+		Fetch("p", "name").
+		Sort("$p", "asc").
+		Offset(5).
+		Limit(10).
+		Build()
+	if err != nil {
+		t.Fatalf("build error: %v", err)
+	}
+	if !strings.HasSuffix(query, "sort $p asc;\noffset 5;\nlimit 10;\nfetch {\n  \"name\": $p.name\n};") {
+		t.Fatalf("fetch is not the last stage:\n%s", query)
+	}
+}
+
 func TestUpdateAttributeTemplate(t *testing.T) {
 	query, err := UpdateAttribute("n", "user_story", "status", "done")
 	if err != nil {
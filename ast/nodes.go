@@ -53,6 +53,17 @@ type ArithmeticValue struct {
 func (ArithmeticValue) queryNode() {}
 func (ArithmeticValue) value()     {}
 
+// ParenValue wraps a value in explicit parentheses.
+// The compiler already parenthesizes nested ArithmeticValue operands where
+// precedence requires it; use ParenValue to force grouping regardless.
+type ParenValue struct {
+	// Inner is the wrapped value (Value or variable string).
+	Inner any
+}
+
+func (ParenValue) queryNode() {}
+func (ParenValue) value()     {}
+
 // --- Role Players ---
 
 // RolePlayer represents a role player in a relation, mapping a role name to a player variable.
@@ -223,6 +234,18 @@ type IidPattern struct {
 func (IidPattern) queryNode() {}
 func (IidPattern) pattern()   {}
 
+// IsPattern asserts that two variables refer to the same concept ($x is $y).
+// Wrap it in a NotPattern to exclude self-matches, e.g. not { $p is $f; }.
+type IsPattern struct {
+	// LeftVar is the left-hand variable.
+	LeftVar string
+	// RightVar is the right-hand variable.
+	RightVar string
+}
+
+func (IsPattern) queryNode() {}
+func (IsPattern) pattern()   {}
+
 // RawPattern represents a raw TypeQL string pattern, typically for legacy support.
 type RawPattern struct {
 	// Content is the raw TypeQL string.
@@ -330,8 +353,9 @@ func (MatchClause) queryNode() {}
 func (MatchClause) clause()    {}
 
 // LetAssignment represents an assignment in a 'match let' clause.
+// Multiple variables destructure a tuple-returning function (let $a, $b in fun(...)).
 type LetAssignment struct {
-	// Variables are the variables being assigned values.
+	// Variables are the variables being assigned values, in tuple order.
 	Variables []string
 	// Expression is the value or expression being assigned (Value or variable string).
 	Expression any
@@ -389,6 +413,24 @@ type PutClause struct {
 func (PutClause) queryNode() {}
 func (PutClause) clause()    {}
 
+// MatchInsertClause represents an insert-if-not-exists pipeline: a match, a
+// not-exists guard and an insert, compiled as one query. The insert runs once
+// per match of Patterns for which Guard finds nothing, which gives put-like
+// semantics to relations that have no key for 'put' to check.
+// Compiles to: match <patterns>; not { <guard>; }; insert <statements>;
+type MatchInsertClause struct {
+	// Patterns bind the variables the insert refers to, such as role players.
+	// They may be empty when the guard and insert need no bound variables.
+	Patterns []Pattern
+	// Guard describes the existing data that must be absent; it is negated.
+	Guard []Pattern
+	// Statements are the statements to insert.
+	Statements []Statement
+}
+
+func (MatchInsertClause) queryNode() {}
+func (MatchInsertClause) clause()    {}
+
 // SelectClause represents a 'select' clause for variable projection.
 // Compiles to: select $var1, $var2, ...;
 type SelectClause struct {
@@ -528,6 +570,24 @@ func (FetchNestedWildcard) fetchItem() {}
 // FetchKey returns the output key for the nested wildcard.
 func (f FetchNestedWildcard) FetchKey() string { return f.Key }
 
+// FetchSubquery fetches the results of a nested match-fetch pipeline as a list.
+// It enables eager loading of related data in a single query, e.g.
+// "friends": [ match ...; fetch { ... }; ].
+type FetchSubquery struct {
+	// Key is the output key in the result JSON.
+	Key string
+	// Match is the nested match clause; it may reference variables of the outer query.
+	Match MatchClause
+	// Fetch shapes each result of the nested match.
+	Fetch FetchClause
+}
+
+func (FetchSubquery) queryNode() {}
+func (FetchSubquery) fetchItem() {}
+
+// FetchKey returns the output key for the subquery.
+func (f FetchSubquery) FetchKey() string { return f.Key }
+
 // FetchClause defines the output structure of a query.
 type FetchClause struct {
 	// Items are the items to fetch, which can be FetchItem nodes or raw strings.
@@ -571,3 +631,146 @@ type ReduceClause struct {
 
 func (ReduceClause) queryNode() {}
 func (ReduceClause) clause()    {}
+
+// --- Schema Definitions ---
+
+// Annotation is the marker interface for schema annotations such as @key or @card(1..3).
+type Annotation interface {
+	QueryNode
+	annotation()
+}
+
+// KeyAnnotation marks an ownership as the owner's key (@key).
+type KeyAnnotation struct{}
+
+func (KeyAnnotation) queryNode()  {}
+func (KeyAnnotation) annotation() {}
+
+// UniqueAnnotation marks an ownership as unique across owners (@unique).
+type UniqueAnnotation struct{}
+
+func (UniqueAnnotation) queryNode()  {}
+func (UniqueAnnotation) annotation() {}
+
+// CardAnnotation constrains the cardinality of an ownership, role, or plays (@card(min..max)).
+type CardAnnotation struct {
+	// Min is the lower bound.
+	Min int
+	// Max is the upper bound; nil means unbounded (@card(min..)).
+	Max *int
+}
+
+func (CardAnnotation) queryNode()  {}
+func (CardAnnotation) annotation() {}
+
+// ValuesAnnotation restricts an attribute to an enumerated set of values (@values(...)).
+type ValuesAnnotation struct {
+	// Values are the permitted values.
+	Values []Value
+}
+
+func (ValuesAnnotation) queryNode()  {}
+func (ValuesAnnotation) annotation() {}
+
+// RegexAnnotation restricts a string attribute to values matching a pattern (@regex("...")).
+type RegexAnnotation struct {
+	// Pattern is the regular expression, unescaped.
+	Pattern string
+}
+
+func (RegexAnnotation) queryNode()  {}
+func (RegexAnnotation) annotation() {}
+
+// IndependentAnnotation keeps attribute instances alive without an owner (@independent).
+type IndependentAnnotation struct{}
+
+func (IndependentAnnotation) queryNode()  {}
+func (IndependentAnnotation) annotation() {}
+
+// AbstractAnnotation marks a type or role as abstract (@abstract).
+type AbstractAnnotation struct{}
+
+func (AbstractAnnotation) queryNode()  {}
+func (AbstractAnnotation) annotation() {}
+
+// Capability is the marker interface for type capabilities inside a TypeDefinition.
+type Capability interface {
+	QueryNode
+	capability()
+}
+
+// OwnsCapability declares that a type owns an attribute (owns name @key).
+type OwnsCapability struct {
+	// AttrName is the name of the owned attribute type.
+	AttrName string
+	// Annotations are applied to the ownership.
+	Annotations []Annotation
+}
+
+func (OwnsCapability) queryNode()  {}
+func (OwnsCapability) capability() {}
+
+// RelatesCapability declares a role of a relation type (relates friend @card(0..2)).
+type RelatesCapability struct {
+	// RoleName is the name of the role.
+	RoleName string
+	// Annotations are applied to the role.
+	Annotations []Annotation
+}
+
+func (RelatesCapability) queryNode()  {}
+func (RelatesCapability) capability() {}
+
+// PlaysCapability declares that a type plays a scoped role (plays friendship:friend).
+type PlaysCapability struct {
+	// Role is the scoped role label, e.g. "friendship:friend".
+	Role string
+	// Annotations are applied to the plays declaration.
+	Annotations []Annotation
+}
+
+func (PlaysCapability) queryNode()  {}
+func (PlaysCapability) capability() {}
+
+// ValueTypeCapability declares the value type of an attribute type (value string @regex("...")).
+type ValueTypeCapability struct {
+	// ValueType is the TypeQL value type (string, integer, double, ...).
+	ValueType string
+	// Annotations constrain the values, typically @values or @regex.
+	Annotations []Annotation
+}
+
+func (ValueTypeCapability) queryNode()  {}
+func (ValueTypeCapability) capability() {}
+
+// Definition is the marker interface for definitions inside a define clause.
+type Definition interface {
+	QueryNode
+	definition()
+}
+
+// TypeDefinition declares an entity, relation, or attribute type with its capabilities.
+type TypeDefinition struct {
+	// Kind is "entity", "relation", or "attribute".
+	Kind string
+	// TypeName is the name of the type being defined.
+	TypeName string
+	// Supertype is the optional parent type (sub parent).
+	Supertype string
+	// Annotations are type-level annotations such as @abstract or @independent.
+	Annotations []Annotation
+	// Capabilities are the owns/relates/plays/value declarations.
+	Capabilities []Capability
+}
+
+func (TypeDefinition) queryNode()  {}
+func (TypeDefinition) definition() {}
+
+// DefineClause represents a 'define' clause containing schema definitions.
+type DefineClause struct {
+	// Definitions are the type definitions to declare.
+	Definitions []Definition
+}
+
+func (DefineClause) queryNode() {}
+func (DefineClause) clause()    {}
@@ -66,6 +66,10 @@ run_gate "staticcheck" "$HOME/go/bin/staticcheck" "${UNIT_PKGS[@]}"
 
 run_gate "go test (-race)" go test -race "${UNIT_PKGS[@]}" -timeout 180s
 
+# gotype/export is its own module (it pulls in Arrow), so ./gotype/... above
+# does not reach it.
+run_gate "gotype/export module" bash -c 'cd gotype/export && go vet ./... && go test -race ./... -timeout 180s'
+
 # Non-test Go sources for the gocyclo / dupl blocking gates — production code
 # only. Test files routinely have high parallel-symmetry (deliberate) and
 # long table-driven functions; gating them fights readability.
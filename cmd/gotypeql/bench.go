@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// benchItem is the entity every bench workload reads and writes.
+type benchItem struct {
+	gotype.BaseEntity
+	ID    string `typedb:"bench-id,key"`
+	Group string `typedb:"bench-group"`
+	Score int64  `typedb:"bench-score"`
+}
+
+// benchGroups is the number of distinct bench-group values; queries and
+// aggregates filter on one group, so each covers 1/benchGroups of the items.
+const benchGroups = 8
+
+// benchProfiles maps a profile to the operations its workers pick from,
+// with equal probability.
+var benchProfiles = map[string][]string{
+	"crud":      {"insert", "get", "query", "aggregate"},
+	"read":      {"get", "query", "aggregate"},
+	"write":     {"insert"},
+	"insert":    {"insert"},
+	"get":       {"get"},
+	"query":     {"query"},
+	"aggregate": {"aggregate"},
+}
+
+// benchOpStats summarizes the latencies of one operation.
+type benchOpStats struct {
+	Op        string  `json:"op"`
+	Count     int     `json:"count"`
+	Errors    int     `json:"errors"`
+	OpsPerSec float64 `json:"ops_per_sec"`
+	P50       float64 `json:"p50_ms"`
+	P90       float64 `json:"p90_ms"`
+	P99       float64 `json:"p99_ms"`
+	Max       float64 `json:"max_ms"`
+	FirstErr  string  `json:"first_error,omitempty"`
+}
+
+// benchReport is the result of a bench run, as printed by -json.
+type benchReport struct {
+	Profile     string         `json:"profile"`
+	Concurrency int            `json:"concurrency"`
+	Seconds     float64        `json:"seconds"`
+	Preload     int            `json:"preload"`
+	Ops         []benchOpStats `json:"ops"`
+}
+
+// benchSamples is what one worker measured.
+type benchSamples struct {
+	latencies map[string][]time.Duration
+	errors    map[string]int
+	firstErr  map[string]error
+}
+
+// runBench implements "bench": it creates a scratch database, preloads it,
+// then runs -concurrency workers issuing the operations of -profile through
+// a gotype.Manager for -duration, and reports latency percentiles per
+// operation.
+func (c *cli) runBench(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	dbName := fs.String("db", "gotypeql_bench", "scratch database to create; it must not exist")
+	profile := fs.String("profile", "crud", "crud, read, write, insert, get, query or aggregate")
+	concurrency := fs.Int("concurrency", 16, "concurrent workers")
+	duration := fs.Duration("duration", 60*time.Second, "how long the workers run")
+	preload := fs.Int("preload", 1000, "items inserted before the run, read by get, query and aggregate")
+	keep := fs.Bool("keep", false, "keep the database after the run instead of deleting it")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("bench: unexpected arguments")
+	}
+	ops, ok := benchProfiles[*profile]
+	if !ok {
+		return fmt.Errorf("bench: unknown profile %q (want crud, read, write, insert, get, query or aggregate)", *profile)
+	}
+	if *concurrency < 1 || *duration <= 0 || *preload < 0 {
+		return errors.New("bench: -concurrency and -duration must be positive and -preload not negative")
+	}
+	if *preload == 0 && slices.Contains(ops, "get") {
+		return fmt.Errorf("bench: the %s profile reads preloaded items; -preload must be positive", *profile)
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	exists, err := conn.DatabaseContains(*dbName)
+	if err != nil {
+		return fmt.Errorf("bench: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("bench: database %s exists; bench only runs against a database it creates", *dbName)
+	}
+	if err := conn.DatabaseCreate(*dbName); err != nil {
+		return fmt.Errorf("bench: create %s: %w", *dbName, err)
+	}
+	if *keep {
+		defer fmt.Fprintf(c.stdout, "kept database %s\n", *dbName)
+	} else {
+		defer conn.DatabaseDelete(*dbName)
+	}
+
+	reg := gotype.NewRegistry()
+	if err := gotype.RegisterIn[benchItem](reg, gotype.WithTypeName("bench-item")); err != nil {
+		return fmt.Errorf("bench: %w", err)
+	}
+	db := gotype.NewDatabase(conn, *dbName).WithRegistry(reg)
+	if err := db.ExecuteSchema(ctx, reg.GenerateSchema()); err != nil {
+		return fmt.Errorf("bench: define schema: %w", err)
+	}
+	mgr, err := gotype.NewManager[benchItem](db)
+	if err != nil {
+		return fmt.Errorf("bench: %w", err)
+	}
+	for start := 0; start < *preload; start += 100 {
+		items := make([]*benchItem, 0, 100)
+		for i := start; i < min(start+100, *preload); i++ {
+			items = append(items, newBenchItem(fmt.Sprintf("item-%d", i), i))
+		}
+		if err := mgr.InsertMany(ctx, items); err != nil {
+			return fmt.Errorf("bench: preload: %w", err)
+		}
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+	samples := make([]benchSamples, *concurrency)
+	started := time.Now()
+	var wg sync.WaitGroup
+	for w := range *concurrency {
+		wg.Go(func() {
+			samples[w] = benchWorker(runCtx, mgr, ops, w, *preload)
+		})
+	}
+	wg.Wait()
+	elapsed := time.Since(started)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	report := benchReport{Profile: *profile, Concurrency: *concurrency, Seconds: elapsed.Seconds(), Preload: *preload}
+	failed, total := 0, 0
+	for _, op := range ops {
+		stats := summarizeBench(op, samples, elapsed)
+		failed += stats.Errors
+		total += stats.Count + stats.Errors
+		report.Ops = append(report.Ops, stats)
+	}
+	if len(ops) > 1 {
+		report.Ops = append(report.Ops, summarizeBench("all", samples, elapsed))
+	}
+
+	if *asJSON {
+		if err := c.writeJSON(report); err != nil {
+			return err
+		}
+	} else {
+		c.printBench(report, *dbName)
+	}
+	if failed > 0 {
+		return fmt.Errorf("bench: %d of %d operations failed", failed, total)
+	}
+	return nil
+}
+
+func newBenchItem(id string, n int) *benchItem {
+	return &benchItem{ID: id, Group: fmt.Sprintf("group-%d", n%benchGroups), Score: int64(n * 37 % 1000)}
+}
+
+// benchWorker issues operations until ctx ends. An operation cut short by
+// the end of the run is not counted.
+func benchWorker(ctx context.Context, mgr *gotype.Manager[benchItem], ops []string, worker, preload int) benchSamples {
+	s := benchSamples{
+		latencies: make(map[string][]time.Duration),
+		errors:    make(map[string]int),
+		firstErr:  make(map[string]error),
+	}
+	rng := rand.New(rand.NewPCG(uint64(worker), uint64(time.Now().UnixNano())))
+	for n := 0; ctx.Err() == nil; n++ {
+		op := ops[rng.IntN(len(ops))]
+		group := fmt.Sprintf("group-%d", rng.IntN(benchGroups))
+		start := time.Now()
+		var err error
+		switch op {
+		case "insert":
+			err = mgr.Insert(ctx, newBenchItem(fmt.Sprintf("w%d-%d", worker, n), rng.IntN(1000)))
+		case "get":
+			_, err = mgr.Get(ctx, map[string]any{"bench-id": fmt.Sprintf("item-%d", rng.IntN(preload))})
+		case "query":
+			_, err = mgr.Query().Filter(gotype.Eq("bench-group", group)).OrderDesc("bench-score").Limit(20).Execute(ctx)
+		case "aggregate":
+			_, err = mgr.Query().Filter(gotype.Eq("bench-group", group)).Avg("bench-score").Execute(ctx)
+		}
+		took := time.Since(start)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return s
+		case err != nil:
+			s.errors[op]++
+			if s.firstErr[op] == nil {
+				s.firstErr[op] = err
+			}
+		default:
+			s.latencies[op] = append(s.latencies[op], took)
+		}
+	}
+	return s
+}
+
+// summarizeBench merges the samples of every worker for op, or for every
+// operation when op is "all".
+func summarizeBench(op string, samples []benchSamples, elapsed time.Duration) benchOpStats {
+	stats := benchOpStats{Op: op}
+	var all []time.Duration
+	for _, s := range samples {
+		for name, lat := range s.latencies {
+			if op == "all" || name == op {
+				all = append(all, lat...)
+			}
+		}
+		for name, n := range s.errors {
+			if op == "all" || name == op {
+				stats.Errors += n
+				if stats.FirstErr == "" {
+					stats.FirstErr = s.firstErr[name].Error()
+				}
+			}
+		}
+	}
+	slices.Sort(all)
+	stats.Count = len(all)
+	stats.OpsPerSec = float64(len(all)) / elapsed.Seconds()
+	stats.P50 = millis(percentile(all, 50))
+	stats.P90 = millis(percentile(all, 90))
+	stats.P99 = millis(percentile(all, 99))
+	stats.Max = millis(percentile(all, 100))
+	return stats
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func (c *cli) printBench(r benchReport, dbName string) {
+	fmt.Fprintf(c.stdout, "profile %s, %d workers, %.1fs against %s (%d preloaded items)\n",
+		r.Profile, r.Concurrency, r.Seconds, dbName, r.Preload)
+	fmt.Fprintf(c.stdout, "%-10s %8s %7s %9s %9s %9s %9s %9s\n", "op", "count", "errors", "ops/s", "p50 ms", "p90 ms", "p99 ms", "max ms")
+	for _, s := range r.Ops {
+		fmt.Fprintf(c.stdout, "%-10s %8d %7d %9.1f %9.2f %9.2f %9.2f %9.2f\n",
+			s.Op, s.Count, s.Errors, s.OpsPerSec, s.P50, s.P90, s.P99, s.Max)
+	}
+	for _, s := range r.Ops {
+		if s.FirstErr != "" && s.Op != "all" {
+			fmt.Fprintf(c.stdout, "%s: first error: %s\n", s.Op, s.FirstErr)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func benchConn() *fakeConn {
+	return &fakeConn{
+		dbs:     map[string]string{},
+		rows:    []map[string]any{{"_iid": "0x1", "bench-id": "item-1", "bench-group": "group-1", "bench-score": int64(37)}},
+		answers: map[string][]map[string]any{"reduce": {{"result": 12.5}}},
+	}
+}
+
+func TestBench(t *testing.T) {
+	conn := benchConn()
+	out, _, err := runCLI(t, conn, "bench", "-duration", "50ms", "-concurrency", "4", "-preload", "150")
+	if err != nil {
+		t.Fatalf("bench: %v\n%s", err, out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 7 || !strings.HasPrefix(lines[0], "profile crud, 4 workers, ") || !strings.HasPrefix(lines[1], "op ") {
+		t.Fatalf("output = %q", out)
+	}
+	for i, op := range []string{"insert", "get", "query", "aggregate", "all"} {
+		if fields := strings.Fields(lines[i+2]); fields[0] != op || fields[1] == "0" || fields[2] != "0" {
+			t.Errorf("row %q, want %s with samples and no errors", lines[i+2], op)
+		}
+	}
+	if _, ok := conn.dbs["gotypeql_bench"]; ok {
+		t.Error("scratch database was not deleted")
+	}
+	if !strings.Contains(conn.queries[0], "entity bench-item") {
+		t.Errorf("first query = %q, want the bench schema", conn.queries[0])
+	}
+	preload := 0
+	for _, q := range conn.queries {
+		if strings.HasPrefix(q, "insert") {
+			preload += strings.Count(q, `has bench-id "item-`)
+		}
+	}
+	if preload != 150 {
+		t.Errorf("preload inserted %d items, want 150", preload)
+	}
+}
+
+func TestBench_JSONAndErrors(t *testing.T) {
+	conn := benchConn()
+	conn.failOn = "reduce"
+	out, _, err := runCLI(t, conn, "bench", "-profile", "aggregate", "-duration", "20ms", "-concurrency", "2", "-keep", "-json")
+	if err == nil || !strings.Contains(err.Error(), "operations failed") {
+		t.Fatalf("err = %v", err)
+	}
+	end := strings.LastIndex(out, "}\n") + 2
+	report, kept := out[:end], out[end:]
+	var r benchReport
+	if err := json.Unmarshal([]byte(report), &r); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if r.Profile != "aggregate" || len(r.Ops) != 1 || r.Ops[0].Errors == 0 || !strings.Contains(r.Ops[0].FirstErr, "rejected") {
+		t.Errorf("report = %+v", r)
+	}
+	if kept != "kept database gotypeql_bench\n" {
+		t.Errorf("trailer = %q", kept)
+	}
+
+	if _, _, err := runCLI(t, conn, "bench", "-duration", "1ms"); err == nil || !strings.Contains(err.Error(), "database gotypeql_bench exists") {
+		t.Errorf("existing database: err = %v", err)
+	}
+	if _, _, err := runCLI(t, nil, "bench", "-profile", "mixed"); err == nil || !strings.Contains(err.Error(), `unknown profile "mixed"`) {
+		t.Errorf("unknown profile: err = %v", err)
+	}
+	if _, _, err := runCLI(t, nil, "bench", "-profile", "read", "-preload", "0"); err == nil || !strings.Contains(err.Error(), "-preload must be positive") {
+		t.Errorf("read without preload: err = %v", err)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var lat []time.Duration
+	for i := 1; i <= 100; i++ {
+		lat = append(lat, time.Duration(i)*time.Millisecond)
+	}
+	for p, want := range map[float64]time.Duration{50: 50 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond} {
+		if got := percentile(lat, p); got != want {
+			t.Errorf("p%v = %v, want %v", p, got, want)
+		}
+	}
+	if percentile(nil, 50) != 0 {
+		t.Error("percentile of no samples should be 0")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !cgo || !typedb
+
+package main
+
+import (
+	"errors"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// dialTypeDB reports that the binary was built without the TypeDB driver.
+func dialTypeDB(connConfig) (gotype.Conn, error) {
+	return nil, errors.New(`built without TypeDB support; rebuild with -tags "cgo,typedb"`)
+}
@@ -0,0 +1,74 @@
+//go:build cgo && typedb
+
+package main
+
+import (
+	"context"
+
+	"github.com/CaliLuke/go-typeql/driver"
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// dialTypeDB opens a driver connection and adapts it to gotype.Conn.
+func dialTypeDB(cfg connConfig) (gotype.Conn, error) {
+	drv, err := driver.OpenWithTLS(cfg.addr, cfg.user, cfg.password, cfg.tls, cfg.tlsRootCA)
+	if err != nil {
+		return nil, err
+	}
+	return &driverConn{drv: drv}, nil
+}
+
+// driverConn wraps a *driver.Driver to satisfy gotype.Conn.
+type driverConn struct {
+	drv *driver.Driver
+}
+
+func (c *driverConn) Transaction(dbName string, txType int) (gotype.Tx, error) {
+	tx, err := c.drv.Transaction(dbName, driver.TransactionType(txType))
+	if err != nil {
+		return nil, err
+	}
+	return driverTx{tx}, nil
+}
+
+// driverTx adds gotype.ResultTx to a driver transaction, which returns the
+// answer rows of write queries but no counts.
+type driverTx struct {
+	*driver.Transaction
+}
+
+func (t driverTx) QueryResult(ctx context.Context, query string) (gotype.Result, error) {
+	rows, err := t.QueryWithContext(ctx, query)
+	if err != nil {
+		return gotype.Result{}, err
+	}
+	return gotype.Result{Rows: rows, Summary: gotype.RowSummary(query, rows)}, nil
+}
+
+func (c *driverConn) Schema(dbName string) (string, error) {
+	return c.drv.Databases().Schema(dbName)
+}
+
+func (c *driverConn) DatabaseCreate(name string) error {
+	return c.drv.Databases().Create(name)
+}
+
+func (c *driverConn) DatabaseDelete(name string) error {
+	return c.drv.Databases().Delete(name)
+}
+
+func (c *driverConn) DatabaseContains(name string) (bool, error) {
+	return c.drv.Databases().Contains(name)
+}
+
+func (c *driverConn) DatabaseAll() ([]string, error) {
+	return c.drv.Databases().All()
+}
+
+func (c *driverConn) Close() {
+	c.drv.Close()
+}
+
+func (c *driverConn) IsOpen() bool {
+	return c.drv.IsOpen()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// runDB implements "db list|create|delete|exists".
+func (c *cli) runDB(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("db: missing subcommand (want list, create, delete or exists)")
+	}
+	sub, args := args[0], args[1:]
+	switch sub {
+	case "list":
+		if len(args) != 0 {
+			return errors.New("db list: takes no arguments")
+		}
+	case "create", "delete", "exists":
+		if len(args) != 1 || args[0] == "" {
+			return fmt.Errorf("db %s: want exactly one database name", sub)
+		}
+	default:
+		return fmt.Errorf("db: unknown subcommand %q (want list, create, delete or exists)", sub)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch sub {
+	case "list":
+		names, err := conn.DatabaseAll()
+		if err != nil {
+			return fmt.Errorf("db list: %w", err)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			fmt.Fprintln(c.stdout, name)
+		}
+	case "create":
+		if err := conn.DatabaseCreate(args[0]); err != nil {
+			return fmt.Errorf("db create %s: %w", args[0], err)
+		}
+		fmt.Fprintf(c.stdout, "created %s\n", args[0])
+	case "delete":
+		exists, err := conn.DatabaseContains(args[0])
+		if err != nil {
+			return fmt.Errorf("db delete %s: %w", args[0], err)
+		}
+		if !exists {
+			return fmt.Errorf("db delete %s: database does not exist", args[0])
+		}
+		if err := conn.DatabaseDelete(args[0]); err != nil {
+			return fmt.Errorf("db delete %s: %w", args[0], err)
+		}
+		fmt.Fprintf(c.stdout, "deleted %s\n", args[0])
+	case "exists":
+		exists, err := conn.DatabaseContains(args[0])
+		if err != nil {
+			return fmt.Errorf("db exists %s: %w", args[0], err)
+		}
+		fmt.Fprintln(c.stdout, exists)
+		if !exists {
+			return errNotExist
+		}
+	}
+	return nil
+}
+
+// runSchema implements "schema show|dump|diff".
+func (c *cli) runSchema(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("schema: missing subcommand (want show, dump or diff)")
+	}
+	sub, args := args[0], args[1:]
+	if sub == "diff" {
+		return c.runSchemaDiff(ctx, args)
+	}
+	if sub != "show" && sub != "dump" {
+		return fmt.Errorf("schema: unknown subcommand %q (want show, dump or diff)", sub)
+	}
+
+	fs := flag.NewFlagSet("schema "+sub, flag.ContinueOnError)
+	dbName := fs.String("db", "", "database name (required)")
+	var outFile string
+	if sub == "dump" {
+		fs.StringVar(&outFile, "o", "", "write the schema to this file instead of stdout")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dbName == "" || fs.NArg() != 0 {
+		return fmt.Errorf("schema %s: want -db NAME", sub)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	schema, err := conn.Schema(*dbName)
+	if err != nil {
+		return fmt.Errorf("schema %s %s: %w", sub, *dbName, err)
+	}
+	if sub == "show" {
+		fmt.Fprintln(c.stdout, strings.TrimRight(schema, "\n"))
+		return nil
+	}
+
+	out := gotype.NormalizeSchema(schema) + "\n"
+	if outFile == "" {
+		_, err = io.WriteString(c.stdout, out)
+		return err
+	}
+	if err := os.WriteFile(outFile, []byte(out), 0o644); err != nil {
+		return fmt.Errorf("schema dump %s: %w", *dbName, err)
+	}
+	fmt.Fprintf(c.stdout, "wrote %s\n", outFile)
+	return nil
+}
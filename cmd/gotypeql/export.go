@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// multiFlag collects the values of a repeatable flag.
+type multiFlag []string
+
+func (f *multiFlag) String() string     { return strings.Join(*f, ", ") }
+func (f *multiFlag) Set(v string) error { *f = append(*f, v); return nil }
+
+// runExport implements "export": it writes the instances of an entity type,
+// optionally filtered by attribute values, as NDJSON, JSON or CSV.
+func (c *cli) runExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	dbName := fs.String("db", "", "database name (required)")
+	typeName := fs.String("type", "", "entity type to export (required)")
+	var where multiFlag
+	fs.Var(&where, "where", "attribute=value filter, repeatable")
+	outFile := fs.String("o", "", "output file (default: stdout)")
+	format := fs.String("format", "", "ndjson, json or csv (default: from the output extension, else ndjson)")
+	relations := fs.Bool("relations", false, `add the relations each instance plays a role in as "_relations"`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dbName == "" || *typeName == "" || fs.NArg() != 0 {
+		return errors.New("export: want -db NAME -type TYPE")
+	}
+	if *format == "" {
+		*format = "ndjson"
+		if *outFile != "" {
+			*format = formatFromExt(*outFile)
+		}
+	}
+	if *format != "csv" && *format != "json" && *format != "ndjson" {
+		return fmt.Errorf("export: unknown format %q (want ndjson, json or csv)", *format)
+	}
+	if *relations && *format == "csv" {
+		return errors.New("export: -relations needs json or ndjson output")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	attrs, err := entityAttributes(conn, *dbName, *typeName)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	filters := make(map[string]any, len(where))
+	for _, w := range where {
+		attr, text, ok := strings.Cut(w, "=")
+		if !ok {
+			return fmt.Errorf("export: invalid -where %q (want attribute=value)", w)
+		}
+		valueType, ok := attrs[attr]
+		if !ok {
+			return fmt.Errorf("export: %s does not own attribute %s", *typeName, attr)
+		}
+		if filters[attr], err = gotype.ParseAttributeValue(valueType, text); err != nil {
+			return fmt.Errorf("export: -where %s: %w", attr, err)
+		}
+	}
+
+	mgr, err := gotype.NewDynamicManager(gotype.NewDatabase(conn, *dbName), *typeName)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	rows, err := mgr.Get(ctx, filters)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	// Sorting by IID keeps repeated exports of the same data identical.
+	slices.SortFunc(rows, func(a, b map[string]any) int {
+		return strings.Compare(fmt.Sprint(a["_iid"]), fmt.Sprint(b["_iid"]))
+	})
+	if *relations {
+		links, err := mgr.Links(ctx, filters)
+		if err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		byIID := make(map[string][]gotype.DynamicLink)
+		for _, l := range links {
+			byIID[l.IID] = append(byIID[l.IID], l)
+		}
+		for _, row := range rows {
+			iid, _ := row["_iid"].(string)
+			row["_relations"] = nonNilLinks(byIID[iid])
+		}
+	}
+
+	w := c.stdout
+	if *outFile != "" {
+		f, err := os.Create(*outFile)
+		if err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	switch *format {
+	case "ndjson":
+		err = writeNDJSON(bw, rows)
+	case "json":
+		err = writeJSONArray(bw, rows)
+	case "csv":
+		err = writeCSV(bw, rows, slices.Sorted(maps.Keys(attrs)))
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	if *outFile != "" {
+		fmt.Fprintf(c.stdout, "exported %d %s rows to %s\n", len(rows), *typeName, *outFile)
+	}
+	return nil
+}
+
+func nonNilLinks(links []gotype.DynamicLink) []gotype.DynamicLink {
+	if links == nil {
+		return []gotype.DynamicLink{}
+	}
+	return links
+}
+
+func writeNDJSON(w io.Writer, rows []map[string]any) error {
+	enc := json.NewEncoder(w)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSONArray writes rows as an indented JSON array, one row at a time.
+func writeJSONArray(w io.Writer, rows []map[string]any) error {
+	if len(rows) == 0 {
+		_, err := io.WriteString(w, "[]\n")
+		return err
+	}
+	if _, err := io.WriteString(w, "[\n"); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for i, row := range rows {
+		data, err := json.MarshalIndent(row, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Reset()
+		buf.WriteString("  ")
+		buf.Write(data)
+		if i < len(rows)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+// writeCSV writes an "_iid" column followed by one column per attribute.
+// Attributes with several values are joined with ";".
+func writeCSV(w io.Writer, rows []map[string]any, attrs []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"_iid"}, attrs...)); err != nil {
+		return err
+	}
+	record := make([]string, len(attrs)+1)
+	for _, row := range rows {
+		record[0] = csvCell(row["_iid"])
+		for i, attr := range attrs {
+			record[i+1] = csvCell(row[attr])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []any:
+		cells := make([]string, len(v))
+		for i, item := range v {
+			cells[i] = csvCell(item)
+		}
+		return strings.Join(cells, ";")
+	default:
+		return fmt.Sprint(v)
+	}
+}
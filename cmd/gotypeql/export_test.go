@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func exportConn() *fakeConn {
+	return &fakeConn{
+		dbs: map[string]string{"app": importSchema},
+		answers: map[string][]map[string]any{
+			"links (": {
+				{"_iid": "0x1", "relation": "0x9", "type": "friendship", "role": "friendship:friend"},
+			},
+			"$e.*": {
+				{"_iid": "0x2", "_attributes": map[string]any{"name": "Bob", "email": []any{"b@x", "bob@x"}}},
+				{"_iid": "0x1", "_attributes": map[string]any{"name": "Alice", "age": 30}},
+			},
+		},
+	}
+}
+
+func TestExport_NDJSON(t *testing.T) {
+	conn := exportConn()
+	out, _, err := runCLI(t, conn, "export", "-db", "app", "-type", "person", "-where", "age=30", "-where", "name=Alice")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := `{"_iid":"0x1","age":30,"name":"Alice"}` + "\n" +
+		`{"_iid":"0x2","email":["b@x","bob@x"],"name":"Bob"}` + "\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if !strings.Contains(conn.queries[0], "$e isa person,\nhas age 30,\nhas name \"Alice\";") {
+		t.Errorf("query = %q", conn.queries[0])
+	}
+}
+
+func TestExport_JSONWithRelations(t *testing.T) {
+	conn := exportConn()
+	file := filepath.Join(t.TempDir(), "people.json")
+	out, _, err := runCLI(t, conn, "export", "-db", "app", "-type", "person", "-relations", "-o", file)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if out != "exported 2 person rows to "+file+"\n" {
+		t.Errorf("output = %q", out)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"[\n  {\n    \"_iid\": \"0x1\",\n    \"_relations\": [\n      {\n        \"iid\": \"0x1\",\n        \"relation_iid\": \"0x9\",",
+		"\"role\": \"friendship:friend\"",
+		"\"_relations\": [],",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("people.json missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestExport_CSV(t *testing.T) {
+	out, _, err := runCLI(t, exportConn(), "export", "-db", "app", "-type", "person", "-format", "csv")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := "_iid,age,email,joined,name\n0x1,30,,,Alice\n0x2,,b@x;bob@x,,Bob\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestExport_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-type", "person"}, "want -db NAME"},
+		{[]string{"-db", "app", "-type", "person", "-where", "age"}, "invalid -where"},
+		{[]string{"-db", "app", "-type", "person", "-where", "nick=x"}, "does not own attribute nick"},
+		{[]string{"-db", "app", "-type", "person", "-where", "age=old"}, `invalid integer "old"`},
+		{[]string{"-db", "app", "-type", "person", "-format", "csv", "-relations"}, "-relations needs json"},
+		{[]string{"-db", "app", "-type", "friendship"}, "friendship is a relation"},
+	} {
+		_, _, err := runCLI(t, exportConn(), append([]string{"export"}, tt.args...)...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: err = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+// shortWriter accepts n bytes and fails every write after that.
+type shortWriter struct{ n int }
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		return 0, errors.New("disk full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestExport_WriteErrors(t *testing.T) {
+	rows := []map[string]any{{"_iid": "0x1", "name": "Alice"}, {"_iid": "0x2", "name": "Bob"}}
+	for _, n := range []int{0, 2, 40} {
+		if err := writeJSONArray(&shortWriter{n}, rows); err == nil {
+			t.Errorf("writeJSONArray with %d bytes: expected error", n)
+		}
+	}
+	if err := writeCSV(&shortWriter{0}, rows, []string{"name"}); err == nil {
+		t.Error("writeCSV: expected error")
+	}
+}
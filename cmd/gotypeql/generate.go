@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/CaliLuke/go-typeql/tqlgen"
+)
+
+// runGenerate implements "generate": it runs every target of a tqlgen.yaml
+// (models, registry, DTOs, docs) against one parse of the schema. It needs
+// no connection.
+func (c *cli) runGenerate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	configFile := fs.String("config", "tqlgen.yaml", "generation config file (YAML, or JSON with a .json name)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("generate: want [-config FILE]")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	cfg, err := tqlgen.LoadGenerateConfig(*configFile)
+	if err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+	written, err := tqlgen.Generate(cfg)
+	for _, path := range written {
+		fmt.Fprintf(c.stdout, "wrote %s\n", path)
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"schema.tql":  "define\nattribute name, value string;\nentity person, owns name @key;\n",
+		"tqlgen.yaml": "schema: schema.tql\ntargets:\n  - kind: models\n    out: models_gen.go\n  - kind: docs\n    out: schema.md\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// generate never connects: a nil fakeConn would panic if it did.
+	out, _, err := runCLI(t, nil, "generate", "-config", filepath.Join(dir, "tqlgen.yaml"))
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	want := "wrote " + filepath.Join(dir, "models_gen.go") + "\nwrote " + filepath.Join(dir, "schema.md") + "\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+
+	if _, _, err := runCLI(t, nil, "generate", "-config", filepath.Join(dir, "missing.yaml")); err == nil || !strings.Contains(err.Error(), "generate: ") {
+		t.Errorf("missing config: err = %v", err)
+	}
+	if _, _, err := runCLI(t, nil, "generate", "extra"); err == nil || !strings.Contains(err.Error(), "want [-config FILE]") {
+		t.Errorf("extra argument: err = %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/tqlgen"
+)
+
+// columnMapping maps one input column to an attribute type.
+type columnMapping struct {
+	attr, column string
+}
+
+// importRecord is one input row: its cells by column name, or the error that
+// made it unreadable.
+type importRecord struct {
+	cells map[string]string
+	err   error
+}
+
+// runImport implements "import": it inserts one entity per row of a CSV,
+// JSON or NDJSON file, in batches of -batch rows per transaction.
+func (c *cli) runImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dbName := fs.String("db", "", "database name (required)")
+	typeName := fs.String("type", "", "entity type to insert (required)")
+	file := fs.String("file", "", `input file, "-" for stdin (required)`)
+	format := fs.String("format", "", "csv, json or ndjson (default: from the file extension)")
+	mapping := fs.String("map", "", "attribute=column pairs, comma separated (default: columns named like attributes)")
+	batch := fs.Int("batch", 100, "rows inserted per transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dbName == "" || *typeName == "" || *file == "" || fs.NArg() != 0 {
+		return errors.New("import: want -db NAME -type TYPE -file FILE")
+	}
+	if *batch < 1 {
+		return errors.New("import: -batch must be at least 1")
+	}
+	if *format == "" {
+		*format = formatFromExt(*file)
+	}
+	if *format != "csv" && *format != "json" && *format != "ndjson" {
+		return fmt.Errorf("import: unknown format %q (want csv, json or ndjson)", *format)
+	}
+	columns, err := parseColumnMap(*mapping)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	var in io.Reader = c.stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	header, records, err := readRecords(in, *format)
+	if err != nil {
+		return fmt.Errorf("import %s: %w", *file, err)
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	attrs, err := entityAttributes(conn, *dbName, *typeName)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	if columns, err = resolveColumns(columns, attrs, header, *typeName); err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	db := gotype.NewDatabase(conn, *dbName)
+	mgr, err := gotype.NewDynamicManager(db, *typeName)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	// Rows are numbered from 1 in input order; rows that cannot be converted
+	// are reported without stopping the import.
+	var rows []map[string]any
+	var rowNums []int
+	failed := 0
+	for i, rec := range records {
+		row, err := convertRecord(rec, columns, attrs)
+		if err != nil {
+			fmt.Fprintf(c.stdout, "row %d: %v\n", i+1, err)
+			failed++
+			continue
+		}
+		rows = append(rows, row)
+		rowNums = append(rowNums, i+1)
+	}
+
+	for start := 0; start < len(rows); start += *batch {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+*batch, len(rows))
+		if err := mgr.InsertMany(ctx, rows[start:end]); err == nil {
+			continue
+		}
+		// The batch was rolled back: insert its rows one by one to find
+		// the failing ones.
+		for i := start; i < end; i++ {
+			if _, err := mgr.Insert(ctx, rows[i]); err != nil {
+				fmt.Fprintf(c.stdout, "row %d: %v\n", rowNums[i], err)
+				failed++
+			}
+		}
+	}
+
+	fmt.Fprintf(c.stdout, "imported %d of %d rows into %s\n", len(records)-failed, len(records), *typeName)
+	if failed > 0 {
+		return fmt.Errorf("import: %d of %d rows failed", failed, len(records))
+	}
+	return nil
+}
+
+func formatFromExt(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return "json"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	default:
+		return "csv"
+	}
+}
+
+// parseColumnMap parses "attr=Column,attr2=Column2".
+func parseColumnMap(s string) ([]columnMapping, error) {
+	var columns []columnMapping
+	for pair := range strings.SplitSeq(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		attr, column, ok := strings.Cut(pair, "=")
+		attr, column = strings.TrimSpace(attr), strings.TrimSpace(column)
+		if !ok || attr == "" || column == "" {
+			return nil, fmt.Errorf("invalid -map entry %q (want attribute=column)", pair)
+		}
+		columns = append(columns, columnMapping{attr: attr, column: column})
+	}
+	return columns, nil
+}
+
+// entityAttributes returns the value type of every attribute typeName owns,
+// inherited ones included, as declared by the database schema.
+func entityAttributes(conn gotype.Conn, dbName, typeName string) (map[string]string, error) {
+	schemaStr, err := conn.Schema(dbName)
+	if err != nil {
+		return nil, fmt.Errorf("read schema of %s: %w", dbName, err)
+	}
+	schema, err := gotype.IntrospectSchemaFromString(schemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("read schema of %s: %w", dbName, err)
+	}
+	schema.AccumulateInheritance()
+
+	if slices.ContainsFunc(schema.Relations, func(r tqlgen.RelationSpec) bool { return r.Name == typeName }) {
+		return nil, fmt.Errorf("%s is a relation; only entity types are supported", typeName)
+	}
+	i := slices.IndexFunc(schema.Entities, func(e tqlgen.EntitySpec) bool { return e.Name == typeName })
+	if i < 0 {
+		return nil, fmt.Errorf("no entity type %s in database %s", typeName, dbName)
+	}
+	valueTypes := make(map[string]string, len(schema.Attributes))
+	for _, a := range schema.Attributes {
+		valueTypes[a.Name] = a.ValueType
+	}
+	attrs := make(map[string]string)
+	for _, o := range schema.Entities[i].Owns {
+		attrs[o.Attribute] = valueTypes[o.Attribute]
+	}
+	return attrs, nil
+}
+
+// resolveColumns checks the explicit mapping against the schema and the CSV
+// header, or, without one, maps every column named like an owned attribute.
+func resolveColumns(columns []columnMapping, attrs map[string]string, header []string, typeName string) ([]columnMapping, error) {
+	if len(columns) == 0 {
+		for _, attr := range slices.Sorted(maps.Keys(attrs)) {
+			if header == nil || slices.Contains(header, attr) {
+				columns = append(columns, columnMapping{attr: attr, column: attr})
+			}
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("no column is named like an attribute of %s (use -map)", typeName)
+		}
+		return columns, nil
+	}
+	for _, m := range columns {
+		if _, ok := attrs[m.attr]; !ok {
+			return nil, fmt.Errorf("%s does not own attribute %s", typeName, m.attr)
+		}
+		if header != nil && !slices.Contains(header, m.column) {
+			return nil, fmt.Errorf("no column %s in the input", m.column)
+		}
+	}
+	return columns, nil
+}
+
+// convertRecord turns the mapped cells of rec into attribute values. Empty
+// and missing cells are left out.
+func convertRecord(rec importRecord, columns []columnMapping, attrs map[string]string) (map[string]any, error) {
+	if rec.err != nil {
+		return nil, rec.err
+	}
+	row := make(map[string]any)
+	for _, m := range columns {
+		cell, ok := rec.cells[m.column]
+		if !ok || cell == "" {
+			continue
+		}
+		v, err := gotype.ParseAttributeValue(attrs[m.attr], cell)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.attr, err)
+		}
+		row[m.attr] = v
+	}
+	return row, nil
+}
+
+// readRecords reads every row of in. The header is returned for CSV input
+// only, whose columns are known upfront.
+func readRecords(in io.Reader, format string) ([]string, []importRecord, error) {
+	switch format {
+	case "csv":
+		r := csv.NewReader(in)
+		header, err := r.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read header: %w", err)
+		}
+		r.FieldsPerRecord = len(header)
+		var records []importRecord
+		for {
+			fields, err := r.Read()
+			if err == io.EOF {
+				return header, records, nil
+			}
+			if err != nil {
+				var parseErr *csv.ParseError
+				if !errors.As(err, &parseErr) {
+					return nil, nil, err
+				}
+				records = append(records, importRecord{err: err})
+				continue
+			}
+			cells := make(map[string]string, len(header))
+			for i, name := range header {
+				cells[name] = fields[i]
+			}
+			records = append(records, importRecord{cells: cells})
+		}
+	case "json":
+		dec := json.NewDecoder(in)
+		dec.UseNumber()
+		var objects []map[string]any
+		if err := dec.Decode(&objects); err != nil {
+			return nil, nil, fmt.Errorf("want an array of objects: %w", err)
+		}
+		records := make([]importRecord, len(objects))
+		for i, obj := range objects {
+			records[i] = jsonRecord(obj)
+		}
+		return nil, records, nil
+	default:
+		var records []importRecord
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			dec := json.NewDecoder(bytes.NewReader(line))
+			dec.UseNumber()
+			var obj map[string]any
+			if err := dec.Decode(&obj); err != nil {
+				records = append(records, importRecord{err: err})
+				continue
+			}
+			records = append(records, jsonRecord(obj))
+		}
+		return nil, records, scanner.Err()
+	}
+}
+
+// jsonRecord converts the scalar values of obj to cells. Nulls are dropped.
+func jsonRecord(obj map[string]any) importRecord {
+	cells := make(map[string]string, len(obj))
+	for name, v := range obj {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			cells[name] = v
+		case json.Number:
+			cells[name] = v.String()
+		case bool:
+			cells[name] = strconv.FormatBool(v)
+		default:
+			return importRecord{err: fmt.Errorf("%s: nested values are not supported", name)}
+		}
+	}
+	return importRecord{cells: cells}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const importSchema = `define
+attribute name, value string;
+attribute email, value string;
+attribute age, value integer;
+attribute joined, value datetime;
+entity person, owns name @key, owns email, owns age, owns joined;
+entity employee sub person;
+relation friendship, relates friend;
+`
+
+func writeInput(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestImport_CSVWithMapping(t *testing.T) {
+	conn := &fakeConn{dbs: map[string]string{"app": importSchema}}
+	file := writeInput(t, "people.csv", "Name,Email,Age,Joined\nAlice,alice@example.com,30,2024-01-02\nBob,,x,\nCarol,carol@example.com,,\n")
+
+	out, _, err := runCLI(t, conn, "import", "-db", "app", "-type", "employee", "-file", file,
+		"-map", "name=Name,email=Email,age=Age,joined=Joined", "-batch", "1")
+	if err == nil || err.Error() != "import: 1 of 3 rows failed" {
+		t.Fatalf("err = %v", err)
+	}
+	if !strings.Contains(out, `row 2: age: invalid integer "x"`) || !strings.Contains(out, "imported 2 of 3 rows into employee") {
+		t.Errorf("output:\n%s", out)
+	}
+	want := []string{
+		"insert\n$e isa employee, has age 30, has email \"alice@example.com\", has joined 2024-01-02, has name \"Alice\";",
+		"insert\n$e isa employee, has email \"carol@example.com\", has name \"Carol\";",
+	}
+	if len(conn.queries) != 2 {
+		t.Fatalf("queries = %q", conn.queries)
+	}
+	for i, q := range conn.queries {
+		if !strings.HasPrefix(q, want[i]) {
+			t.Errorf("query %d = %q, want prefix %q", i, q, want[i])
+		}
+	}
+}
+
+func TestImport_NDJSONBatchFallsBackToRows(t *testing.T) {
+	conn := &fakeConn{dbs: map[string]string{"app": importSchema}, failOn: `"dup"`}
+	file := writeInput(t, "people.ndjson", `{"name": "a", "age": 1}
+{"name": "dup", "age": 2}
+
+{"name": "c", "age": null, "extra": true}
+`)
+
+	out, _, err := runCLI(t, conn, "import", "-db", "app", "-type", "person", "-file", file)
+	if err == nil {
+		t.Fatal("expected an error for the rejected row")
+	}
+	if !strings.Contains(out, "row 2: insert person: rejected") || !strings.Contains(out, "imported 2 of 3 rows") {
+		t.Errorf("output:\n%s", out)
+	}
+	// The batch of three was rolled back, then "a" and "c" inserted alone.
+	var inserted []string
+	for _, q := range conn.queries {
+		inserted = append(inserted, strings.SplitN(q, ";", 2)[0])
+	}
+	got := strings.Join(inserted, "\n")
+	if !strings.HasSuffix(got, "insert\n$e isa person, has age 1, has name \"a\"\ninsert\n$e isa person, has name \"c\"") {
+		t.Errorf("inserts:\n%s", got)
+	}
+}
+
+func TestImport_Errors(t *testing.T) {
+	conn := &fakeConn{dbs: map[string]string{"app": importSchema}}
+	csvFile := writeInput(t, "people.csv", "Name\nAlice\n")
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-type", "person", "-file", csvFile}, "want -db NAME"},
+		{[]string{"-db", "app", "-type", "friendship", "-file", csvFile}, "friendship is a relation"},
+		{[]string{"-db", "app", "-type", "robot", "-file", csvFile}, "no entity type robot"},
+		{[]string{"-db", "app", "-type", "person", "-file", csvFile}, "no column is named like an attribute"},
+		{[]string{"-db", "app", "-type", "person", "-file", csvFile, "-map", "nick=Name"}, "does not own attribute nick"},
+		{[]string{"-db", "app", "-type", "person", "-file", csvFile, "-map", "name=Nom"}, "no column Nom"},
+		{[]string{"-db", "app", "-type", "person", "-file", csvFile, "-map", "name"}, "invalid -map entry"},
+		{[]string{"-db", "app", "-type", "person", "-file", csvFile, "-format", "xml"}, "unknown format"},
+	} {
+		_, _, err := runCLI(t, conn, append([]string{"import"}, tt.args...)...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: err = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
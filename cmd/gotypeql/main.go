@@ -0,0 +1,149 @@
+// gotypeql administers TypeDB databases from the command line.
+//
+// Usage:
+//
+//	gotypeql [connection flags] db list
+//	gotypeql [connection flags] db create|delete|exists NAME
+//	gotypeql [connection flags] schema show -db NAME
+//	gotypeql [connection flags] schema dump -db NAME [-o FILE]
+//	gotypeql [connection flags] schema diff FILE -db NAME
+//	gotypeql [connection flags] migrate up|down|status|stamp -db NAME [-dir DIR]
+//	gotypeql [connection flags] repl -db NAME [-mode read|write|schema]
+//	gotypeql [connection flags] import -db NAME -type TYPE -file FILE [-map attr=Column,...]
+//	gotypeql [connection flags] export -db NAME -type TYPE [-where attr=value] [-o FILE]
+//	gotypeql [connection flags] seed -db NAME DIR
+//	gotypeql generate [-config tqlgen.yaml]
+//	gotypeql [connection flags] bench [-profile crud] [-concurrency 16] [-duration 60s]
+//
+// Connection flags default to the TYPEDB_ADDRESS, TYPEDB_USERNAME and
+// TYPEDB_PASSWORD environment variables. The binary talks to TypeDB through
+// the driver package, so it must be built with -tags "cgo,typedb".
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// errNotExist makes "db exists" exit with status 1 without an error message.
+var errNotExist = errors.New("database does not exist")
+
+// connConfig holds the connection flags shared by every command.
+type connConfig struct {
+	addr      string
+	user      string
+	password  string
+	tls       bool
+	tlsRootCA string
+}
+
+// cli runs one invocation. dial opens the connection lazily, so usage errors
+// never reach the server.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	dial   func(connConfig) (gotype.Conn, error)
+	cfg    connConfig
+}
+
+func main() {
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, dial: dialTypeDB}
+	if err := c.run(context.Background(), os.Args[1:]); err != nil {
+		if errors.Is(err, errNotExist) {
+			os.Exit(1)
+		}
+		if errors.Is(err, errSchemaDrift) {
+			os.Exit(3)
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "gotypeql: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (c *cli) run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("gotypeql", flag.ContinueOnError)
+	fs.StringVar(&c.cfg.addr, "addr", envOr("TYPEDB_ADDRESS", "localhost:1729"), "TypeDB server address")
+	fs.StringVar(&c.cfg.user, "user", envOr("TYPEDB_USERNAME", "admin"), "TypeDB username")
+	fs.StringVar(&c.cfg.password, "password", envOr("TYPEDB_PASSWORD", "password"), "TypeDB password")
+	fs.BoolVar(&c.cfg.tls, "tls", false, "connect with TLS")
+	fs.StringVar(&c.cfg.tlsRootCA, "tls-root-ca", "", "path to a custom TLS root CA")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	switch rest[0] {
+	case "db":
+		return c.runDB(ctx, rest[1:])
+	case "schema":
+		return c.runSchema(ctx, rest[1:])
+	case "migrate":
+		return c.runMigrate(ctx, rest[1:])
+	case "repl":
+		return c.runREPL(ctx, rest[1:])
+	case "import":
+		return c.runImport(ctx, rest[1:])
+	case "export":
+		return c.runExport(ctx, rest[1:])
+	case "seed":
+		return c.runSeed(ctx, rest[1:])
+	case "generate":
+		return c.runGenerate(ctx, rest[1:])
+	case "bench":
+		return c.runBench(ctx, rest[1:])
+	default:
+		return fmt.Errorf("unknown command %q (want db, schema, migrate, repl, import, export, seed, generate or bench)", rest[0])
+	}
+}
+
+const usage = `Usage:
+  gotypeql [flags] db list
+  gotypeql [flags] db create|delete|exists NAME
+  gotypeql [flags] schema show -db NAME
+  gotypeql [flags] schema dump -db NAME [-o FILE]
+  gotypeql [flags] schema diff FILE -db NAME [-breaking] [-json]
+  gotypeql [flags] migrate up|stamp -db NAME [-dir DIR] [-target NAME] [-dry-run] [-json]
+  gotypeql [flags] migrate down -db NAME [-dir DIR] [-steps N | -target NAME] [-dry-run] [-json]
+  gotypeql [flags] migrate status -db NAME [-dir DIR] [-json]
+  gotypeql [flags] repl -db NAME [-mode read|write|schema] [-history FILE]
+  gotypeql [flags] import -db NAME -type TYPE -file FILE [-format csv|json|ndjson] [-map attr=Column,...] [-batch N]
+  gotypeql [flags] export -db NAME -type TYPE [-where attr=value]... [-o FILE] [-format ndjson|json|csv] [-relations]
+  gotypeql [flags] seed -db NAME [-refs] DIR
+  gotypeql generate [-config FILE]
+  gotypeql [flags] bench [-db NAME] [-profile crud|read|write|insert|get|query|aggregate] [-concurrency N] [-duration D] [-preload N] [-keep] [-json]
+
+Flags:
+`
+
+// connect opens a connection with the parsed connection flags.
+func (c *cli) connect() (gotype.Conn, error) {
+	conn, err := c.dial(c.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", c.cfg.addr, err)
+	}
+	return conn, nil
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
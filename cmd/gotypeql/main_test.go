@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// fakeConn is an in-memory gotype.Conn holding database names and schemas.
+// Its transactions keep sequential migration records and log every other
+// query. It is safe for concurrent use.
+type fakeConn struct {
+	mu      sync.Mutex
+	dbs     map[string]string           // name -> schema
+	records []string                    // applied migration names
+	queries []string                    // queries other than migration bookkeeping
+	rows    []map[string]any            // result of every other query
+	txTypes []int                       // type of every transaction opened
+	failOn  string                      // queries containing it fail
+	answers map[string][]map[string]any // results of queries containing the key
+	closed  bool
+}
+
+func (f *fakeConn) Transaction(dbName string, txType int) (gotype.Tx, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.txTypes = append(f.txTypes, txType)
+	return &fakeTx{conn: f}, nil
+}
+
+func (f *fakeConn) Schema(dbName string) (string, error) {
+	schema, ok := f.dbs[dbName]
+	if !ok {
+		return "", errors.New("no such database")
+	}
+	return schema, nil
+}
+
+func (f *fakeConn) DatabaseCreate(name string) error {
+	if _, ok := f.dbs[name]; ok {
+		return errors.New("already exists")
+	}
+	f.dbs[name] = ""
+	return nil
+}
+
+func (f *fakeConn) DatabaseDelete(name string) error {
+	delete(f.dbs, name)
+	return nil
+}
+
+func (f *fakeConn) DatabaseContains(name string) (bool, error) {
+	_, ok := f.dbs[name]
+	return ok, nil
+}
+
+func (f *fakeConn) DatabaseAll() ([]string, error) {
+	var names []string
+	for name := range f.dbs {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f *fakeConn) Close()       { f.closed = true }
+func (f *fakeConn) IsOpen() bool { return !f.closed }
+
+var recordNameRe = regexp.MustCompile(`has seq-migration-name "([^"]*)"`)
+
+type fakeTx struct{ conn *fakeConn }
+
+func (t *fakeTx) Query(query string) ([]map[string]any, error) {
+	f := t.conn
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.Contains(query, "entity seq-migration-record"):
+	case strings.HasPrefix(query, "match\n$m isa seq-migration-record;\nfetch"):
+		rows := make([]map[string]any, len(f.records))
+		for i, name := range f.records {
+			rows[i] = map[string]any{"name": name, "applied-at": "2024-01-02T03:04:05Z"}
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "insert\n$m isa seq-migration-record"):
+		f.records = append(f.records, recordNameRe.FindStringSubmatch(query)[1])
+	case strings.Contains(query, "$m isa seq-migration-record, has"):
+		name := recordNameRe.FindStringSubmatch(query)[1]
+		f.records = slices.DeleteFunc(f.records, func(r string) bool { return r == name })
+	case f.failOn != "" && strings.Contains(query, f.failOn):
+		return nil, errors.New("rejected")
+	default:
+		f.queries = append(f.queries, query)
+		for key, rows := range f.answers {
+			if strings.Contains(query, key) {
+				return rows, nil
+			}
+		}
+		return f.rows, nil
+	}
+	return nil, nil
+}
+
+func (t *fakeTx) QueryWithContext(ctx context.Context, query string) ([]map[string]any, error) {
+	return t.Query(query)
+}
+func (t *fakeTx) Commit() error   { return nil }
+func (t *fakeTx) Rollback() error { return nil }
+func (t *fakeTx) Close()          {}
+func (t *fakeTx) IsOpen() bool    { return true }
+
+func runCLI(t *testing.T, conn *fakeConn, args ...string) (string, connConfig, error) {
+	t.Helper()
+	var out bytes.Buffer
+	var got connConfig
+	c := &cli{stdout: &out, dial: func(cfg connConfig) (gotype.Conn, error) {
+		got = cfg
+		return conn, nil
+	}}
+	err := c.run(context.Background(), args)
+	return out.String(), got, err
+}
+
+func TestDBCommands(t *testing.T) {
+	conn := &fakeConn{dbs: map[string]string{"b": "", "a": ""}}
+
+	out, _, err := runCLI(t, conn, "db", "list")
+	if err != nil || out != "a\nb\n" {
+		t.Fatalf("db list = %q, %v", out, err)
+	}
+	if !conn.closed {
+		t.Error("connection was not closed")
+	}
+
+	if out, _, err := runCLI(t, conn, "db", "create", "c"); err != nil || out != "created c\n" {
+		t.Fatalf("db create = %q, %v", out, err)
+	}
+	if out, _, err := runCLI(t, conn, "db", "exists", "c"); err != nil || out != "true\n" {
+		t.Fatalf("db exists = %q, %v", out, err)
+	}
+	if out, _, err := runCLI(t, conn, "db", "delete", "c"); err != nil || out != "deleted c\n" {
+		t.Fatalf("db delete = %q, %v", out, err)
+	}
+	out, _, err = runCLI(t, conn, "db", "exists", "c")
+	if !errors.Is(err, errNotExist) || out != "false\n" {
+		t.Fatalf("db exists after delete = %q, %v", out, err)
+	}
+	if _, _, err := runCLI(t, conn, "db", "delete", "c"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("db delete of missing database: %v", err)
+	}
+}
+
+func TestSchemaShow(t *testing.T) {
+	conn := &fakeConn{dbs: map[string]string{"app": "define\nentity person;\n"}}
+
+	out, cfg, err := runCLI(t, conn, "-addr", "db.example:1729", "-user", "ops", "schema", "show", "-db", "app")
+	if err != nil {
+		t.Fatalf("schema show: %v", err)
+	}
+	if out != "define\nentity person;\n" {
+		t.Errorf("schema show = %q", out)
+	}
+	if cfg.addr != "db.example:1729" || cfg.user != "ops" {
+		t.Errorf("connection config = %+v", cfg)
+	}
+}
+
+func TestSchemaDump(t *testing.T) {
+	conn := &fakeConn{dbs: map[string]string{"app": "define\nentity person, owns name;\nattribute name, value string;\n"}}
+	want := "define\n\n  attribute name,\n    value string;\n\n  entity person,\n    owns name;\n"
+
+	out, _, err := runCLI(t, conn, "schema", "dump", "-db", "app")
+	if err != nil {
+		t.Fatalf("schema dump: %v", err)
+	}
+	if out != want {
+		t.Errorf("schema dump = %q, want %q", out, want)
+	}
+
+	file := filepath.Join(t.TempDir(), "schema.tql")
+	out, _, err = runCLI(t, conn, "schema", "dump", "-db", "app", "-o", file)
+	if err != nil {
+		t.Fatalf("schema dump -o: %v", err)
+	}
+	if out != "wrote "+file+"\n" {
+		t.Errorf("schema dump -o printed %q", out)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("schema.tql = %q, want %q", data, want)
+	}
+}
+
+func TestUsageErrorsDoNotConnect(t *testing.T) {
+	for _, args := range [][]string{
+		{"db"},
+		{"db", "drop", "x"},
+		{"db", "create"},
+		{"db", "list", "extra"},
+		{"schema", "show"},
+		{"schema", "export", "-db", "x"},
+		{"schema", "dump", "-o", "x.tql"},
+		{"schema", "show", "-db", "x", "-o", "x.tql"},
+		{"table"},
+	} {
+		c := &cli{stdout: &bytes.Buffer{}, dial: func(connConfig) (gotype.Conn, error) {
+			t.Fatalf("%v: dialed on a usage error", args)
+			return nil, nil
+		}}
+		if err := c.run(context.Background(), args); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
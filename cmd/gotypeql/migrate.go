@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// migrateResult is the JSON output of migrate up, down and stamp.
+type migrateResult struct {
+	Command    string   `json:"command"`
+	DryRun     bool     `json:"dry_run"`
+	Migrations []string `json:"migrations"`
+}
+
+// migrationStatus is one entry of the JSON output of migrate status.
+type migrationStatus struct {
+	Name      string `json:"name"`
+	Applied   bool   `json:"applied"`
+	AppliedAt string `json:"applied_at,omitempty"`
+}
+
+// runMigrate implements "migrate up|down|status|stamp" over a directory of
+// <name>.up.tql / <name>.down.tql files.
+func (c *cli) runMigrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("migrate: missing subcommand (want up, down, status or stamp)")
+	}
+	sub, args := args[0], args[1:]
+	switch sub {
+	case "up", "down", "status", "stamp":
+	default:
+		return fmt.Errorf("migrate: unknown subcommand %q (want up, down, status or stamp)", sub)
+	}
+
+	fs := flag.NewFlagSet("migrate "+sub, flag.ContinueOnError)
+	dbName := fs.String("db", "", "database name (required)")
+	dir := fs.String("dir", "migrations", "directory of <name>.up.tql and <name>.down.tql files")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	var dryRun bool
+	var target string
+	steps := 1
+	if sub != "status" {
+		fs.BoolVar(&dryRun, "dry-run", false, "report what would change without touching the database")
+		fs.StringVar(&target, "target", "", "migration to stop at (down: roll back everything after it)")
+	}
+	if sub == "down" {
+		fs.IntVar(&steps, "steps", 1, "number of migrations to roll back, unless -target is set")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dbName == "" || fs.NArg() != 0 {
+		return fmt.Errorf("migrate %s: want -db NAME", sub)
+	}
+
+	migrations, err := gotype.LoadTQLMigrations(os.DirFS(*dir), ".")
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", sub, err)
+	}
+	if len(migrations) == 0 {
+		return fmt.Errorf("migrate %s: no migrations in %s", sub, *dir)
+	}
+	if target != "" && !slices.ContainsFunc(migrations, func(m gotype.SequentialMigration) bool { return m.Name == target }) {
+		return fmt.Errorf("migrate %s: unknown target %q", sub, target)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	db := gotype.NewDatabase(conn, *dbName)
+
+	if sub == "status" {
+		return c.migrateStatus(ctx, db, migrations, *asJSON)
+	}
+
+	var opts []gotype.SeqMigrationOption
+	if dryRun {
+		opts = append(opts, gotype.WithSeqDryRun())
+	}
+	if target != "" {
+		opts = append(opts, gotype.WithSeqTarget(target))
+	}
+	if !*asJSON {
+		opts = append(opts, gotype.WithSeqLogger(func(msg string) { fmt.Fprintln(c.stdout, msg) }))
+	}
+
+	var names []string
+	switch sub {
+	case "up":
+		names, err = gotype.RunSequentialMigrations(ctx, db, migrations, opts...)
+	case "stamp":
+		names, err = gotype.StampSequentialMigrations(ctx, db, migrations, opts...)
+	case "down":
+		names, err = c.migrateDown(ctx, db, migrations, steps, target, dryRun, *asJSON)
+	}
+	if *asJSON {
+		if encErr := c.writeJSON(migrateResult{Command: sub, DryRun: dryRun, Migrations: nonNil(names)}); encErr != nil && err == nil {
+			err = encErr
+		}
+	} else if err == nil && len(names) == 0 {
+		fmt.Fprintln(c.stdout, "nothing to do")
+	}
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", sub, err)
+	}
+	return nil
+}
+
+func (c *cli) migrateStatus(ctx context.Context, db *gotype.Database, migrations []gotype.SequentialMigration, asJSON bool) error {
+	infos, err := gotype.SeqMigrationStatus(ctx, db, migrations)
+	if err != nil {
+		return fmt.Errorf("migrate status: %w", err)
+	}
+	if asJSON {
+		out := make([]migrationStatus, len(infos))
+		for i, info := range infos {
+			out[i] = migrationStatus{Name: info.Name, Applied: info.Applied, AppliedAt: info.AppliedAt}
+		}
+		return c.writeJSON(out)
+	}
+	for _, info := range infos {
+		state := "pending"
+		if info.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(c.stdout, "%-8s %-20s %s\n", state, info.AppliedAt, info.Name)
+	}
+	return nil
+}
+
+// migrateDown rolls back steps migrations, or every applied migration sorted
+// after target when it is set.
+func (c *cli) migrateDown(ctx context.Context, db *gotype.Database, migrations []gotype.SequentialMigration, steps int, target string, dryRun, quiet bool) ([]string, error) {
+	infos, err := gotype.SeqMigrationStatus(ctx, db, migrations)
+	if err != nil {
+		return nil, err
+	}
+	var applied []string
+	for _, info := range slices.Backward(infos) {
+		if info.Applied && (target == "" || info.Name > target) {
+			applied = append(applied, info.Name)
+		}
+	}
+	if target != "" {
+		steps = len(applied)
+	}
+	steps = min(max(steps, 0), len(applied))
+
+	if dryRun {
+		for _, name := range applied[:steps] {
+			if !quiet {
+				fmt.Fprintf(c.stdout, "[dry-run] roll back: %s\n", name)
+			}
+		}
+		return applied[:steps], nil
+	}
+	names, err := gotype.RollbackSequentialMigration(ctx, db, migrations, steps)
+	if !quiet {
+		for _, name := range names {
+			fmt.Fprintf(c.stdout, "rolled back: %s\n", name)
+		}
+	}
+	return names, err
+}
+
+func (c *cli) writeJSON(v any) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
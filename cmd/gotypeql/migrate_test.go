@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeMigrations(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"001_people.up.tql":   "define\nentity person;",
+		"001_people.down.tql": "undefine person;",
+		"002_seed.up.tql":     "insert $p isa person;",
+		"002_seed.down.tql":   "match $p isa person; delete $p;",
+		"003_tags.up.tql":     "define\nentity tag;",
+		"003_tags.down.tql":   "undefine tag;",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMigrateUpStatusDown(t *testing.T) {
+	dir := writeMigrations(t)
+	conn := &fakeConn{dbs: map[string]string{}}
+
+	out, _, err := runCLI(t, conn, "migrate", "up", "-db", "app", "-dir", dir, "-target", "002_seed")
+	if err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	if want := "applying: 001_people\napplied: 001_people\napplying: 002_seed\napplied: 002_seed\n"; out != want {
+		t.Errorf("migrate up output = %q", out)
+	}
+	if !reflect.DeepEqual(conn.records, []string{"001_people", "002_seed"}) {
+		t.Errorf("records = %v", conn.records)
+	}
+
+	out, _, err = runCLI(t, conn, "migrate", "status", "-db", "app", "-dir", dir, "-json")
+	if err != nil {
+		t.Fatalf("migrate status: %v", err)
+	}
+	var status []migrationStatus
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		t.Fatalf("decode status %q: %v", out, err)
+	}
+	want := []migrationStatus{
+		{Name: "001_people", Applied: true, AppliedAt: "2024-01-02T03:04:05Z"},
+		{Name: "002_seed", Applied: true, AppliedAt: "2024-01-02T03:04:05Z"},
+		{Name: "003_tags"},
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("status = %+v", status)
+	}
+
+	out, _, err = runCLI(t, conn, "migrate", "down", "-db", "app", "-dir", dir, "-target", "001_people", "-dry-run", "-json")
+	if err != nil {
+		t.Fatalf("migrate down dry-run: %v", err)
+	}
+	var res migrateResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if !res.DryRun || !reflect.DeepEqual(res.Migrations, []string{"002_seed"}) {
+		t.Errorf("dry-run result = %+v", res)
+	}
+	if len(conn.records) != 2 {
+		t.Fatalf("dry-run changed records: %v", conn.records)
+	}
+
+	out, _, err = runCLI(t, conn, "migrate", "down", "-db", "app", "-dir", dir, "-steps", "5")
+	if err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+	if out != "rolled back: 002_seed\nrolled back: 001_people\n" || len(conn.records) != 0 {
+		t.Errorf("migrate down output = %q, records = %v", out, conn.records)
+	}
+	if last := conn.queries[len(conn.queries)-1]; last != "undefine person;" {
+		t.Errorf("last query = %q", last)
+	}
+}
+
+func TestMigrateStamp(t *testing.T) {
+	dir := writeMigrations(t)
+	conn := &fakeConn{dbs: map[string]string{}}
+
+	out, _, err := runCLI(t, conn, "migrate", "stamp", "-db", "app", "-dir", dir, "-json")
+	if err != nil {
+		t.Fatalf("migrate stamp: %v", err)
+	}
+	var res migrateResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if res.Command != "stamp" || len(res.Migrations) != 3 || len(conn.queries) != 0 {
+		t.Errorf("stamp result = %+v, queries = %v", res, conn.queries)
+	}
+
+	out, _, err = runCLI(t, conn, "migrate", "up", "-db", "app", "-dir", dir)
+	if err != nil || out != "nothing to do\n" {
+		t.Errorf("migrate up after stamp = %q, %v", out, err)
+	}
+}
+
+func TestMigrateUsageErrors(t *testing.T) {
+	dir := writeMigrations(t)
+	for _, args := range [][]string{
+		{"migrate"},
+		{"migrate", "sideways", "-db", "app"},
+		{"migrate", "up", "-dir", dir},
+		{"migrate", "up", "-db", "app", "-dir", filepath.Join(dir, "missing")},
+		{"migrate", "up", "-db", "app", "-dir", dir, "-target", "999_nope"},
+		{"migrate", "status", "-db", "app", "-dir", dir, "-dry-run"},
+	} {
+		if _, _, err := runCLI(t, &fakeConn{}, args...); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
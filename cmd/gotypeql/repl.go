@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// maxHistory is the number of entries kept in the history file.
+const maxHistory = 1000
+
+var replModes = map[string]gotype.TransactionType{
+	"read":   gotype.ReadTransaction,
+	"write":  gotype.WriteTransaction,
+	"schema": gotype.SchemaTransaction,
+}
+
+const replHelp = `Enter a TypeQL query over one or more lines and finish it with an empty line.
+Each query runs in its own transaction, committed unless the mode is read.
+
+  :read, :write, :schema   switch the transaction mode
+  :history                 list previous queries
+  !N                       run query N of the history again
+  :help                    show this help
+  :quit, :exit             leave (or end the input)
+
+There is no line editing or arrow-key recall; run under rlwrap for that.
+`
+
+// repl is an interactive session on one database.
+type repl struct {
+	c        *cli
+	db       *gotype.Database
+	mode     string
+	history  []string
+	histFile string
+}
+
+// runREPL implements "repl".
+func (c *cli) runREPL(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("repl", flag.ContinueOnError)
+	dbName := fs.String("db", "", "database name (required)")
+	mode := fs.String("mode", "read", "initial transaction mode: read, write or schema")
+	histFile := fs.String("history", defaultHistoryFile(), `history file ("" disables history)`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dbName == "" || fs.NArg() != 0 {
+		return errors.New("repl: want -db NAME")
+	}
+	if _, ok := replModes[*mode]; !ok {
+		return fmt.Errorf("repl: unknown mode %q (want read, write or schema)", *mode)
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	r := &repl{c: c, db: gotype.NewDatabase(conn, *dbName), mode: *mode, histFile: *histFile}
+	if err := r.loadHistory(); err != nil {
+		r.historyFailed(err)
+	}
+	fmt.Fprintf(c.stdout, "connected to %s, database %s. Finish queries with an empty line, :help for commands.\n", c.cfg.addr, *dbName)
+	return r.loop(ctx)
+}
+
+func (r *repl) loop(ctx context.Context) error {
+	in := bufio.NewScanner(r.c.stdin)
+	in.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var lines []string
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			fmt.Fprintf(r.c.stdout, "%s[%s]> ", r.db.Name(), r.mode)
+		} else {
+			fmt.Fprint(r.c.stdout, "... ")
+		}
+		if !in.Scan() {
+			if len(lines) > 0 {
+				r.run(ctx, strings.Join(lines, "\n"))
+			}
+			fmt.Fprintln(r.c.stdout)
+			return in.Err()
+		}
+		line := strings.TrimRight(in.Text(), " \t\r")
+
+		if len(lines) == 0 {
+			switch cmd := strings.TrimSpace(line); {
+			case cmd == "":
+				continue
+			case cmd == ":quit" || cmd == ":exit":
+				return nil
+			case strings.HasPrefix(cmd, ":"), strings.HasPrefix(cmd, "!"):
+				r.command(ctx, cmd)
+				continue
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+			continue
+		}
+		r.run(ctx, strings.Join(lines, "\n"))
+		lines = lines[:0]
+	}
+}
+
+// command handles a ":" or "!" line.
+func (r *repl) command(ctx context.Context, cmd string) {
+	out := r.c.stdout
+	if n, ok := strings.CutPrefix(cmd, "!"); ok {
+		i, err := strconv.Atoi(n)
+		if err != nil || i < 1 || i > len(r.history) {
+			fmt.Fprintf(out, "no history entry %q\n", n)
+			return
+		}
+		query := r.history[i-1]
+		fmt.Fprintln(out, query)
+		r.run(ctx, query)
+		return
+	}
+	name := strings.TrimPrefix(cmd, ":")
+	if _, ok := replModes[name]; ok {
+		r.mode = name
+		return
+	}
+	switch name {
+	case "history":
+		for i, query := range r.history {
+			fmt.Fprintf(out, "%4d  %s\n", i+1, strings.ReplaceAll(query, "\n", "\n      "))
+		}
+	case "help":
+		fmt.Fprint(out, replHelp)
+	default:
+		fmt.Fprintf(out, "unknown command %s (:help lists commands)\n", cmd)
+	}
+}
+
+// run executes query in a transaction of the current mode and prints the
+// result and the time it took. Errors are printed, not returned, so that the
+// session goes on.
+func (r *repl) run(ctx context.Context, query string) {
+	if err := r.addHistory(query); err != nil {
+		r.historyFailed(err)
+	}
+	out := r.c.stdout
+	start := time.Now()
+	rows, err := r.exec(ctx, query)
+	elapsed := time.Since(start).Round(time.Microsecond)
+	if err != nil {
+		fmt.Fprintf(out, "error: %v\n", err)
+		return
+	}
+	for _, row := range rows {
+		data, err := json.MarshalIndent(row, "", "  ")
+		if err != nil {
+			fmt.Fprintf(out, "%v\n", row)
+			continue
+		}
+		fmt.Fprintf(out, "%s\n", data)
+	}
+	noun := "rows"
+	if len(rows) == 1 {
+		noun = "row"
+	}
+	if r.mode == "read" {
+		fmt.Fprintf(out, "%d %s in %s\n", len(rows), noun, elapsed)
+	} else {
+		fmt.Fprintf(out, "%d %s, committed in %s\n", len(rows), noun, elapsed)
+	}
+}
+
+func (r *repl) exec(ctx context.Context, query string) ([]map[string]any, error) {
+	tx, err := r.db.TransactionContext(ctx, replModes[r.mode])
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	rows, err := tx.QueryWithContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode != "read" {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit: %w", err)
+		}
+	}
+	return rows, nil
+}
+
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gotypeql_history")
+}
+
+// loadHistory reads the history file. Entries are stored one per line as
+// Go-quoted strings, so that line breaks, and with them the end of "#"
+// comments, survive. Lines that are not quoted are taken as they are.
+func (r *repl) loadHistory() error {
+	if r.histFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.histFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		if query, err := strconv.Unquote(line); err == nil {
+			line = query
+		}
+		r.history = append(r.history, line)
+	}
+	if len(r.history) > maxHistory {
+		r.history = r.history[len(r.history)-maxHistory:]
+		var b strings.Builder
+		for _, query := range r.history {
+			b.WriteString(strconv.Quote(query))
+			b.WriteByte('\n')
+		}
+		return os.WriteFile(r.histFile, []byte(b.String()), 0o600)
+	}
+	return nil
+}
+
+// addHistory records query unless it repeats the previous entry, and appends
+// it to the history file.
+func (r *repl) addHistory(query string) error {
+	if n := len(r.history); n > 0 && r.history[n-1] == query {
+		return nil
+	}
+	r.history = append(r.history, query)
+	if r.histFile == "" {
+		return nil
+	}
+	f, err := os.OpenFile(r.histFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, strconv.Quote(query)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// historyFailed reports a history file error and stops using the file for
+// the rest of the session, which goes on.
+func (r *repl) historyFailed(err error) {
+	fmt.Fprintf(r.c.stdout, "warning: history file %s: %v; history is not saved\n", r.histFile, err)
+	r.histFile = ""
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+func runREPL(t *testing.T, conn *fakeConn, input string, args ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	c := &cli{stdin: strings.NewReader(input), stdout: &out, dial: func(connConfig) (gotype.Conn, error) {
+		return conn, nil
+	}}
+	if err := c.run(context.Background(), append([]string{"repl"}, args...)); err != nil {
+		t.Fatalf("repl: %v", err)
+	}
+	return out.String()
+}
+
+var elapsedRe = regexp.MustCompile(`in [0-9.]+[µnm]?s\n`)
+
+func TestREPL_RunsQueriesInSelectedMode(t *testing.T) {
+	conn := &fakeConn{rows: []map[string]any{{"name": "Alice"}}}
+	input := "match $p isa person;\n  fetch { \"name\": $p.name };\n\n:write\ninsert $p isa person;\n\n"
+
+	out := runREPL(t, conn, input, "-db", "app", "-history", "")
+
+	wantQueries := []string{"match $p isa person;\n  fetch { \"name\": $p.name };", "insert $p isa person;"}
+	if !slices.Equal(conn.queries, wantQueries) {
+		t.Errorf("queries = %q, want %q", conn.queries, wantQueries)
+	}
+	if !slices.Equal(conn.txTypes, []int{int(gotype.ReadTransaction), int(gotype.WriteTransaction)}) {
+		t.Errorf("transaction types = %v", conn.txTypes)
+	}
+	out = elapsedRe.ReplaceAllString(out, "in T\n")
+	for _, want := range []string{
+		"app[read]> ... ... ",
+		"{\n  \"name\": \"Alice\"\n}\n1 row in T\n",
+		"app[write]> ",
+		"1 row, committed in T\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestREPL_History(t *testing.T) {
+	histFile := filepath.Join(t.TempDir(), "history")
+	// An unquoted line, as older versions wrote, and a quoted one.
+	if err := os.WriteFile(histFile, []byte("match $x isa thing;\n\"match $y isa thing;\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conn := &fakeConn{}
+	query := "match # people\n  $p isa person;"
+
+	out := runREPL(t, conn, query+"\n\n:history\n!3\n!1\n:quit\n", "-db", "app", "-history", histFile)
+
+	if !strings.Contains(out, "   1  match $x isa thing;\n   2  match $y isa thing;\n   3  match # people\n        $p isa person;\n") {
+		t.Errorf("history listing missing:\n%s", out)
+	}
+	if !slices.Equal(conn.queries, []string{query, query, "match $x isa thing;"}) {
+		t.Errorf("queries = %q", conn.queries)
+	}
+	data, err := os.ReadFile(histFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "match $x isa thing;\n\"match $y isa thing;\"\n\"match # people\\n  $p isa person;\"\n\"match $x isa thing;\"\n"
+	if string(data) != want {
+		t.Errorf("history file = %q, want %q", data, want)
+	}
+}
+
+func TestREPL_HistoryFileError(t *testing.T) {
+	conn := &fakeConn{}
+	// A directory cannot be appended to.
+	out := runREPL(t, conn, "match $p isa person;\n\nmatch $q isa person;\n\n:history\n", "-db", "app", "-history", t.TempDir())
+	if strings.Count(out, "warning: history file") != 1 || !strings.Contains(out, "history is not saved") {
+		t.Errorf("expected one history warning:\n%s", out)
+	}
+	if len(conn.queries) != 2 || !strings.Contains(out, "   2  match $q isa person;") {
+		t.Errorf("session did not go on:\n%s", out)
+	}
+}
+
+func TestREPL_Errors(t *testing.T) {
+	conn := &fakeConn{}
+	out := runREPL(t, conn, ":bogus\n!7\n", "-db", "app", "-history", "")
+	if !strings.Contains(out, "unknown command :bogus") || !strings.Contains(out, `no history entry "7"`) {
+		t.Errorf("output:\n%s", out)
+	}
+
+	c := &cli{stdin: strings.NewReader(""), stdout: &bytes.Buffer{}}
+	for _, args := range [][]string{{"repl"}, {"repl", "-db", "app", "-mode", "admin"}} {
+		if err := c.run(context.Background(), args); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/tqlgen"
+)
+
+// errSchemaDrift makes "schema diff" exit with status 3 after printing the
+// differences.
+var errSchemaDrift = errors.New("schema differs")
+
+// schemaChange is one difference reported by "schema diff".
+type schemaChange struct {
+	Change   string `json:"change"` // "added", "changed" or "removed"
+	Kind     string `json:"kind"`   // "attribute", "entity" or "relation"
+	Type     string `json:"type"`
+	Detail   string `json:"detail,omitempty"`
+	Breaking bool   `json:"breaking"`
+}
+
+func (c schemaChange) String() string {
+	switch c.Change {
+	case "added":
+		if c.Detail != "" {
+			return fmt.Sprintf("+ %s %s (%s)", c.Kind, c.Type, c.Detail)
+		}
+		return fmt.Sprintf("+ %s %s", c.Kind, c.Type)
+	case "removed":
+		return fmt.Sprintf("- %s %s", c.Kind, c.Type)
+	default:
+		return fmt.Sprintf("~ %s %s: %s", c.Kind, c.Type, c.Detail)
+	}
+}
+
+// runSchemaDiff implements "schema diff FILE -db NAME": it compares the
+// schema in FILE, the desired one, with the live schema of the database.
+func (c *cli) runSchemaDiff(ctx context.Context, args []string) error {
+	var file string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		file, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("schema diff", flag.ContinueOnError)
+	dbName := fs.String("db", "", "database name (required)")
+	asJSON := fs.Bool("json", false, "print the differences as JSON")
+	breaking := fs.Bool("breaking", false, "exit with status 3 only for breaking changes (removals and value type changes)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if file == "" && fs.NArg() == 1 {
+		file = fs.Arg(0)
+	} else if fs.NArg() != 0 {
+		file = ""
+	}
+	if file == "" || *dbName == "" {
+		return errors.New("schema diff: want FILE -db NAME")
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("schema diff: %w", err)
+	}
+	desired, err := gotype.IntrospectSchemaFromString(string(data))
+	if err != nil {
+		return fmt.Errorf("schema diff: %s: %w", file, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	schemaStr, err := conn.Schema(*dbName)
+	if err != nil {
+		return fmt.Errorf("schema diff %s: %w", *dbName, err)
+	}
+	current, err := gotype.IntrospectSchemaFromString(schemaStr)
+	if err != nil {
+		return fmt.Errorf("schema diff %s: %w", *dbName, err)
+	}
+
+	changes := compareSchemas(desired, current)
+	switch {
+	case *asJSON:
+		if err := c.writeJSON(nonNilChanges(changes)); err != nil {
+			return err
+		}
+	case len(changes) == 0:
+		fmt.Fprintf(c.stdout, "%s matches %s\n", *dbName, file)
+	default:
+		for _, ch := range changes {
+			fmt.Fprintln(c.stdout, ch)
+		}
+	}
+	if slices.ContainsFunc(changes, func(ch schemaChange) bool { return ch.Breaking || !*breaking }) {
+		return errSchemaDrift
+	}
+	return nil
+}
+
+func nonNilChanges(changes []schemaChange) []schemaChange {
+	if changes == nil {
+		return []schemaChange{}
+	}
+	return changes
+}
+
+// compareSchemas lists the changes that turn current into desired: the
+// additions found by gotype.DiffSchema, which drives migrations, plus the
+// removals and value type changes that a migration never applies.
+func compareSchemas(desired, current *tqlgen.ParsedSchema) []schemaChange {
+	desiredKinds, currentKinds := typeKinds(desired), typeKinds(current)
+	diff := gotype.DiffSchema(desired, current)
+
+	var changes []schemaChange
+	for _, a := range diff.AddAttributes {
+		changes = append(changes, schemaChange{Change: "added", Kind: "attribute", Type: a.Name, Detail: a.ValueType})
+	}
+	for _, t := range slices.Concat(diff.AddEntities, diff.AddRelations) {
+		fields := strings.Fields(t.TypeQL)
+		changes = append(changes, schemaChange{Change: "added", Kind: fields[0], Type: strings.TrimRight(fields[1], ",;")})
+	}
+	for _, o := range diff.AddOwns {
+		changes = append(changes, schemaChange{Change: "changed", Kind: desiredKinds[o.TypeName], Type: o.TypeName, Detail: "+ owns " + o.Attribute})
+	}
+	for _, r := range diff.AddRelates {
+		changes = append(changes, schemaChange{Change: "changed", Kind: "relation", Type: r.TypeName, Detail: "+ relates " + r.Role})
+	}
+	for _, name := range diff.RemoveTypes {
+		changes = append(changes, schemaChange{Change: "removed", Kind: currentKinds[name], Type: name, Breaking: true})
+	}
+
+	desiredAttrs := make(map[string]string, len(desired.Attributes))
+	for _, a := range desired.Attributes {
+		desiredAttrs[a.Name] = a.ValueType
+	}
+	for _, a := range current.Attributes {
+		valueType, ok := desiredAttrs[a.Name]
+		switch {
+		case !ok:
+			changes = append(changes, schemaChange{Change: "removed", Kind: "attribute", Type: a.Name, Breaking: true})
+		case valueType != a.ValueType:
+			changes = append(changes, schemaChange{Change: "changed", Kind: "attribute", Type: a.Name,
+				Detail: fmt.Sprintf("value %s -> %s", a.ValueType, valueType), Breaking: true})
+		}
+	}
+
+	desiredOwns, currentOwns := typeOwns(desired), typeOwns(current)
+	for typeName, owns := range currentOwns {
+		want, ok := desiredOwns[typeName]
+		if !ok {
+			continue // the whole type is removed
+		}
+		for _, attr := range owns {
+			if !slices.Contains(want, attr) {
+				changes = append(changes, schemaChange{Change: "changed", Kind: currentKinds[typeName], Type: typeName,
+					Detail: "- owns " + attr, Breaking: true})
+			}
+		}
+	}
+	desiredRelates := make(map[string][]string)
+	for _, r := range desired.Relations {
+		desiredRelates[r.Name] = []string{}
+		for _, rel := range r.Relates {
+			desiredRelates[r.Name] = append(desiredRelates[r.Name], rel.Role)
+		}
+	}
+	for _, r := range current.Relations {
+		want, ok := desiredRelates[r.Name]
+		if !ok {
+			continue
+		}
+		for _, rel := range r.Relates {
+			if !slices.Contains(want, rel.Role) {
+				changes = append(changes, schemaChange{Change: "changed", Kind: "relation", Type: r.Name,
+					Detail: "- relates " + rel.Role, Breaking: true})
+			}
+		}
+	}
+
+	order := map[string]int{"added": 0, "changed": 1, "removed": 2}
+	slices.SortFunc(changes, func(a, b schemaChange) int {
+		return cmp.Or(
+			cmp.Compare(order[a.Change], order[b.Change]),
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.Detail, b.Detail),
+		)
+	})
+	return changes
+}
+
+// typeKinds maps entity and relation labels to "entity" or "relation".
+func typeKinds(s *tqlgen.ParsedSchema) map[string]string {
+	kinds := make(map[string]string)
+	for _, e := range s.Entities {
+		kinds[e.Name] = "entity"
+	}
+	for _, r := range s.Relations {
+		kinds[r.Name] = "relation"
+	}
+	return kinds
+}
+
+// typeOwns maps entity and relation labels to the attributes they declare.
+func typeOwns(s *tqlgen.ParsedSchema) map[string][]string {
+	owns := make(map[string][]string)
+	for _, e := range s.Entities {
+		owns[e.Name] = []string{}
+		for _, o := range e.Owns {
+			owns[e.Name] = append(owns[e.Name], o.Attribute)
+		}
+	}
+	for _, r := range s.Relations {
+		owns[r.Name] = []string{}
+		for _, o := range r.Owns {
+			owns[r.Name] = append(owns[r.Name], o.Attribute)
+		}
+	}
+	return owns
+}
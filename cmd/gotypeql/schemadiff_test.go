@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const liveSchema = `define
+attribute name, value string;
+attribute age, value integer;
+attribute nickname, value string;
+entity person, owns name, owns age, owns nickname;
+entity robot, owns name;
+relation friendship, relates friend, relates witness;
+`
+
+const desiredSchema = `define
+attribute name, value string;
+attribute age, value string;
+attribute email, value string;
+entity person, owns name, owns age, owns email;
+entity company, owns name;
+relation friendship, relates friend, relates rival;
+`
+
+func writeSchemaFile(t *testing.T, schema string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "schema.tql")
+	if err := os.WriteFile(file, []byte(schema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestSchemaDiff(t *testing.T) {
+	conn := &fakeConn{dbs: map[string]string{"app": liveSchema}}
+	file := writeSchemaFile(t, desiredSchema)
+
+	out, _, err := runCLI(t, conn, "schema", "diff", file, "-db", "app")
+	if !errors.Is(err, errSchemaDrift) {
+		t.Fatalf("err = %v, want errSchemaDrift", err)
+	}
+	want := `+ entity company
++ attribute email (string)
+~ attribute age: value integer -> string
+~ relation friendship: + relates rival
+~ relation friendship: - relates witness
+~ entity person: + owns email
+~ entity person: - owns nickname
+- attribute nickname
+- entity robot
+`
+	if out != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestSchemaDiff_InSync(t *testing.T) {
+	conn := &fakeConn{dbs: map[string]string{"app": liveSchema}}
+	file := writeSchemaFile(t, liveSchema)
+
+	out, _, err := runCLI(t, conn, "schema", "diff", "-db", "app", file)
+	if err != nil {
+		t.Fatalf("schema diff: %v", err)
+	}
+	if out != "app matches "+file+"\n" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestSchemaDiff_BreakingOnly(t *testing.T) {
+	conn := &fakeConn{dbs: map[string]string{"app": "define\nattribute name, value string;\n"}}
+	file := writeSchemaFile(t, "define\nattribute name, value string;\nentity person, owns name;\n")
+
+	out, _, err := runCLI(t, conn, "schema", "diff", file, "-db", "app", "-breaking", "-json")
+	if err != nil {
+		t.Fatalf("additive change failed the -breaking gate: %v", err)
+	}
+	if !strings.Contains(out, `"change": "added"`) || !strings.Contains(out, `"breaking": false`) {
+		t.Errorf("output = %s", out)
+	}
+
+	conn.dbs["app"] = liveSchema
+	if _, _, err := runCLI(t, conn, "schema", "diff", file, "-db", "app", "-breaking"); !errors.Is(err, errSchemaDrift) {
+		t.Errorf("removals: err = %v, want errSchemaDrift", err)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSeed(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"01_people.json": `{"entities": [{"ref": "alice", "type": "person", "attributes": {"name": "Alice", "age": 30}}]}`,
+		"02_friends.yaml": `entities:
+  - ref: bob
+    type: person
+    attributes:
+      name: Bob
+relations:
+  - type: friendship
+    roles:
+      friend: [alice, bob]
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conn := &fakeConn{
+		dbs:  map[string]string{"app": importSchema},
+		rows: []map[string]any{{"_iid": "0x1"}},
+	}
+
+	out, _, err := runCLI(t, conn, "seed", "-db", "app", "-refs", dir)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if out != "alice\t0x1\nbob\t0x1\nseeded 2 entities and 1 relations from 2 files\n" {
+		t.Errorf("output = %q", out)
+	}
+	if len(conn.queries) != 3 || !strings.Contains(conn.queries[2], "links (friend: $p0, friend: $p1)") {
+		t.Errorf("queries = %q", conn.queries)
+	}
+
+	if _, _, err := runCLI(t, conn, "seed", "-db", "app", t.TempDir()); err == nil || !strings.Contains(err.Error(), "no fixture files") {
+		t.Errorf("empty dir: err = %v", err)
+	}
+	if _, _, err := runCLI(t, conn, "seed", dir); err == nil || !strings.Contains(err.Error(), "want -db NAME DIR") {
+		t.Errorf("missing -db: err = %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+)
+
+// AttributeStats profiles the values of one attribute across the instances
+// of a model, as returned by Manager.AttributeStats.
+type AttributeStats struct {
+	// Count is the number of values owned; an instance owning two values of
+	// a multi-valued attribute counts twice.
+	Count int64 `json:"count"`
+	// Distinct is the number of distinct values.
+	Distinct int64 `json:"distinct"`
+	// Min, Max and Mean are set for numeric attributes with at least one
+	// value.
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+	Mean *float64 `json:"mean,omitempty"`
+}
+
+// AttributeStats returns the count, distinct count, minimum, maximum and
+// mean of the values of attr owned by instances of T. Count, minimum,
+// maximum and mean come from one multi-reduce query; the distinct count needs
+// a second query, run in the same read transaction.
+func (m *Manager[T]) AttributeStats(ctx context.Context, attr string) (*AttributeStats, error) {
+	if err := checkCtx(ctx, "attribute_stats", m.info.TypeName); err != nil {
+		return nil, err
+	}
+	fi, ok := m.info.FieldByAttrName(attr)
+	if !ok {
+		return nil, fmt.Errorf("attribute_stats %s: %q is not an attribute of the model", m.info.TypeName, attr)
+	}
+	numeric := false
+	switch fi.ValueType {
+	case "integer", "double", "decimal":
+		numeric = true
+	}
+
+	match := fmt.Sprintf("match\n$e isa %s, has %s $v;", m.info.TypeName, attr)
+	reduce := "reduce $count = count($v)"
+	if numeric {
+		reduce += ", $min = min($v), $max = max($v), $mean = mean($v)"
+	}
+	queries := []string{
+		match + "\n" + reduce + ";",
+		match + "\nselect $v;\ndistinct;\nreduce $distinct = count($v);",
+	}
+
+	tx := m.readTransaction()
+	if tx == nil {
+		var err error
+		if tx, err = m.db.openTransaction(ctx, ReadTransaction); err != nil {
+			return nil, fmt.Errorf("attribute_stats %s: open read transaction: %w", m.info.TypeName, err)
+		}
+		defer tx.Close()
+	}
+	var rows []map[string]any
+	for _, query := range queries {
+		results, err := tx.QueryWithContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("attribute_stats %s.%s: %w", m.info.TypeName, attr, err)
+		}
+		row := map[string]any{}
+		if len(results) > 0 {
+			row = unwrapResult(results[0])
+		}
+		rows = append(rows, row)
+	}
+
+	stats := &AttributeStats{
+		Count:    toInt64(unwrapValue(rows[0]["count"])),
+		Distinct: toInt64(unwrapValue(rows[1]["distinct"])),
+	}
+	for key, dst := range map[string]**float64{"min": &stats.Min, "max": &stats.Max, "mean": &stats.Mean} {
+		if val := unwrapValue(rows[0][key]); val != nil {
+			*dst = new(toFloat64(val))
+		}
+	}
+	return stats, nil
+}
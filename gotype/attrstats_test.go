@@ -0,0 +1,33 @@
+package gotype
+
+import (
+	"context"
+	"testing"
+)
+
+func TestManager_AttributeStats(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	read := &mockTx{responses: [][]map[string]any{
+		{{"count": int64(3), "min": int64(25), "max": int64(30), "mean": 27.5}},
+		{{"distinct": int64(2)}},
+	}}
+	persons := MustNewManager[testPerson](NewDatabase(&mockConn{txs: []*mockTx{read}}, "test_db"))
+
+	stats, err := persons.AttributeStats(ctx, "age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Count != 3 || stats.Distinct != 2 {
+		t.Errorf("got count %d, distinct %d", stats.Count, stats.Distinct)
+	}
+	if stats.Min == nil || *stats.Min != 25 || stats.Max == nil || *stats.Max != 30 || stats.Mean == nil || *stats.Mean != 27.5 {
+		t.Errorf("got min %v, max %v, mean %v", stats.Min, stats.Max, stats.Mean)
+	}
+	assertContains(t, read.queries[0], "reduce $count = count($v), $min = min($v), $max = max($v), $mean = mean($v);")
+	assertContains(t, read.queries[1], "select $v;\ndistinct;\nreduce $distinct = count($v);")
+
+	if _, err := persons.AttributeStats(ctx, "salary"); err == nil {
+		t.Error("expected error for an attribute the model does not own")
+	}
+}
@@ -0,0 +1,263 @@
+package gotype
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// benchWide is a 50-attribute model used to measure how core paths scale with
+// model width. benchEntity (hydrate_bench_test.go) is the small counterpart.
+type benchWide struct {
+	BaseEntity
+	Attr01 string  `typedb:"attr-01,key"`
+	Attr02 int     `typedb:"attr-02"`
+	Attr03 float64 `typedb:"attr-03"`
+	Attr04 bool    `typedb:"attr-04"`
+	Attr05 *string `typedb:"attr-05"`
+	Attr06 string  `typedb:"attr-06"`
+	Attr07 int     `typedb:"attr-07"`
+	Attr08 float64 `typedb:"attr-08"`
+	Attr09 bool    `typedb:"attr-09"`
+	Attr10 *string `typedb:"attr-10"`
+	Attr11 string  `typedb:"attr-11"`
+	Attr12 int     `typedb:"attr-12"`
+	Attr13 float64 `typedb:"attr-13"`
+	Attr14 bool    `typedb:"attr-14"`
+	Attr15 *string `typedb:"attr-15"`
+	Attr16 string  `typedb:"attr-16"`
+	Attr17 int     `typedb:"attr-17"`
+	Attr18 float64 `typedb:"attr-18"`
+	Attr19 bool    `typedb:"attr-19"`
+	Attr20 *string `typedb:"attr-20"`
+	Attr21 string  `typedb:"attr-21"`
+	Attr22 int     `typedb:"attr-22"`
+	Attr23 float64 `typedb:"attr-23"`
+	Attr24 bool    `typedb:"attr-24"`
+	Attr25 *string `typedb:"attr-25"`
+	Attr26 string  `typedb:"attr-26"`
+	Attr27 int     `typedb:"attr-27"`
+	Attr28 float64 `typedb:"attr-28"`
+	Attr29 bool    `typedb:"attr-29"`
+	Attr30 *string `typedb:"attr-30"`
+	Attr31 string  `typedb:"attr-31"`
+	Attr32 int     `typedb:"attr-32"`
+	Attr33 float64 `typedb:"attr-33"`
+	Attr34 bool    `typedb:"attr-34"`
+	Attr35 *string `typedb:"attr-35"`
+	Attr36 string  `typedb:"attr-36"`
+	Attr37 int     `typedb:"attr-37"`
+	Attr38 float64 `typedb:"attr-38"`
+	Attr39 bool    `typedb:"attr-39"`
+	Attr40 *string `typedb:"attr-40"`
+	Attr41 string  `typedb:"attr-41"`
+	Attr42 int     `typedb:"attr-42"`
+	Attr43 float64 `typedb:"attr-43"`
+	Attr44 bool    `typedb:"attr-44"`
+	Attr45 *string `typedb:"attr-45"`
+	Attr46 string  `typedb:"attr-46"`
+	Attr47 int     `typedb:"attr-47"`
+	Attr48 float64 `typedb:"attr-48"`
+	Attr49 bool    `typedb:"attr-49"`
+	Attr50 *string `typedb:"attr-50"`
+}
+
+func registerBenchTypes(b *testing.B) {
+	b.Helper()
+	ClearRegistry()
+	if err := Register[benchEntity](); err != nil {
+		b.Fatal(err)
+	}
+	if err := Register[benchWide](); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func newBenchWide() *benchWide {
+	s := "optional"
+	w := &benchWide{}
+	v := reflectValue(w)
+	for i := 1; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(fmt.Sprintf("value %d", i))
+		case reflect.Int:
+			f.SetInt(int64(i))
+		case reflect.Float64:
+			f.SetFloat(float64(i) + 0.5)
+		case reflect.Bool:
+			f.SetBool(i%2 == 0)
+		case reflect.Pointer:
+			f.Set(reflect.ValueOf(&s))
+		}
+	}
+	return w
+}
+
+func benchWideRow() map[string]any {
+	row := map[string]any{"_iid": map[string]any{"value": "0xABC123"}}
+	info, _ := LookupType(typeOf[benchWide]())
+	for _, fi := range info.Fields {
+		var val any
+		switch fi.ValueType {
+		case "string":
+			val = "value " + fi.Tag.Name
+		case "long", "integer":
+			val = float64(7)
+		case "double":
+			val = 7.5
+		case "boolean":
+			val = true
+		}
+		row[fi.Tag.Name] = map[string]any{"value": val}
+	}
+	return row
+}
+
+func benchmarkBuildInsertQuery[T any](b *testing.B, instance *T) {
+	info, _ := LookupType(typeOf[T]())
+	strategy := strategyFor(info.Kind)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := strategy.BuildInsertQuery(info, instance, "e"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildInsertQuery_Small(b *testing.B) {
+	registerBenchTypes(b)
+	benchmarkBuildInsertQuery(b, &benchEntity{Name: "Alice", Email: "alice@example.com", Age: 30, City: "Paris", Score: 9})
+}
+
+func BenchmarkBuildInsertQuery_Wide(b *testing.B) {
+	registerBenchTypes(b)
+	benchmarkBuildInsertQuery(b, newBenchWide())
+}
+
+func BenchmarkHydrateNew_Small(b *testing.B) {
+	registerBenchTypes(b)
+	row := map[string]any{
+		"_iid":  "0xABC123",
+		"name":  "Alice",
+		"email": "alice@example.com",
+		"age":   float64(30),
+		"city":  "Paris",
+		"score": float64(9),
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := HydrateNew[benchEntity](row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHydrateNew_Wide(b *testing.B) {
+	registerBenchTypes(b)
+	row := benchWideRow()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := HydrateNew[benchWide](row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkBuildQuery[T any](b *testing.B, strAttr, intAttr string) {
+	mgr := MustNewManager[T](NewDatabase(&mockConn{}, "bench"))
+	q := mgr.Query().
+		Filter(Eq(strAttr, "Alice"), Gt(intAttr, 18)).
+		OrderDesc(intAttr).
+		Limit(25)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := q.buildQuery(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildQuery_Small(b *testing.B) {
+	registerBenchTypes(b)
+	benchmarkBuildQuery[benchEntity](b, "name", "age")
+}
+
+func BenchmarkBuildQuery_Wide(b *testing.B) {
+	registerBenchTypes(b)
+	benchmarkBuildQuery[benchWide](b, "attr-01", "attr-02")
+}
+
+func BenchmarkUnwrapResult_Wide(b *testing.B) {
+	registerBenchTypes(b)
+	row := benchWideRow()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if flat := unwrapResult(row); flat["_iid"] == nil {
+			b.Fatal("expected _iid")
+		}
+	}
+}
+
+// benchFetchRows builds n fetch result rows for benchEntity. Plain rows hold
+// bare values, as returned by fetch; wrapped rows use {"value": v} objects.
+func benchFetchRows(n int, wrapped bool) []map[string]any {
+	rows := make([]map[string]any, n)
+	for i := range rows {
+		row := map[string]any{
+			"_iid":  "0x" + strconv.Itoa(i),
+			"name":  "person " + strconv.Itoa(i),
+			"email": "person" + strconv.Itoa(i) + "@example.com",
+			"age":   float64(i % 90),
+		}
+		if wrapped {
+			for k, v := range row {
+				row[k] = map[string]any{"value": v}
+			}
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+func benchmarkUnwrapRows(b *testing.B, wrapped bool) {
+	rows := benchFetchRows(10_000, wrapped)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, row := range rows {
+			if flat := unwrapResult(row); flat["_iid"] == nil {
+				b.Fatal("expected _iid")
+			}
+		}
+	}
+}
+
+func BenchmarkUnwrapResult_10kRows(b *testing.B) {
+	benchmarkUnwrapRows(b, false)
+}
+
+func BenchmarkUnwrapResult_10kRowsWrapped(b *testing.B) {
+	benchmarkUnwrapRows(b, true)
+}
+
+func BenchmarkBuildPolymorphicFetch(b *testing.B) {
+	ClearRegistry()
+	MustRegister[testAnimal]()
+	MustRegister[testDog]()
+	info, _ := Lookup("test-animal")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := buildPolymorphicFetch(info, templateVar); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package gotype
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledQueryBuf bounds the capacity of buffers returned to the pool so a
+// single very large query does not pin its memory for the process lifetime.
+const maxPooledQueryBuf = 64 << 10
+
+var queryBufPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getQueryBuf returns an empty buffer for building a query string. Callers
+// must hand it back with putQueryBuf once the result has been copied out with
+// String.
+//
+// A bytes.Buffer is pooled rather than a strings.Builder because Builder.Reset
+// drops its backing array, which would defeat the reuse.
+func getQueryBuf() *bytes.Buffer {
+	return queryBufPool.Get().(*bytes.Buffer)
+}
+
+// putQueryBuf resets b and returns it to the pool.
+func putQueryBuf(b *bytes.Buffer) {
+	if b.Cap() > maxPooledQueryBuf {
+		return
+	}
+	b.Reset()
+	queryBufPool.Put(b)
+}
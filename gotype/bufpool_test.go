@@ -0,0 +1,35 @@
+package gotype
+
+import "testing"
+
+func TestQueryBuf_StringSurvivesReuse(t *testing.T) {
+	b := getQueryBuf()
+	b.WriteString("match $a;")
+	first := b.String()
+	putQueryBuf(b)
+
+	b = getQueryBuf()
+	if b.Len() != 0 {
+		t.Fatalf("pooled buffer not reset, len=%d", b.Len())
+	}
+	b.WriteString("XXXXXXXXX")
+	putQueryBuf(b)
+
+	if first != "match $a;" {
+		t.Errorf("string built from pooled buffer changed to %q", first)
+	}
+}
+
+func TestBuildBatchUpdate_Format(t *testing.T) {
+	got := buildBatchUpdate("person", "0x1", []string{"name", "age"}, []string{`has name "A"`, "has age 3"})
+	want := "match\n$e isa person, iid 0x1;\n" +
+		"try { $e has name $old0; };\n" +
+		"try { $e has age $old1; };\n" +
+		"delete\n" +
+		"try { $old0 of $e; };\n" +
+		"try { $old1 of $e; };\n" +
+		`insert $e has name "A", has age 3;`
+	if got != want {
+		t.Errorf("buildBatchUpdate =\n%s\nwant\n%s", got, want)
+	}
+}
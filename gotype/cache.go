@@ -0,0 +1,163 @@
+package gotype
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// CacheOption configures a MemoryCache, including the one Manager.WithCache
+// creates.
+type CacheOption func(*cacheConfig)
+
+type cacheConfig struct {
+	size int
+	ttl  time.Duration
+}
+
+// WithCacheSize sets the maximum number of cached entries (default 1024).
+// The least recently used entry is evicted once the cache is full.
+func WithCacheSize(n int) CacheOption {
+	return func(c *cacheConfig) {
+		if n > 0 {
+			c.size = n
+		}
+	}
+}
+
+// WithCacheTTL sets how long a cached entry stays valid. Zero, the default,
+// keeps entries until they are evicted or invalidated.
+func WithCacheTTL(d time.Duration) CacheOption {
+	return func(c *cacheConfig) { c.ttl = max(d, 0) }
+}
+
+// WithCache returns a copy of the manager whose GetByIID is served from its
+// own MemoryCache, configured by opts. It suits hot lookups of reference data
+// that rarely changes, and takes the place of the Database's shared cache
+// (see WithSharedCache) for GetByIID.
+//
+// Writes through the returned manager invalidate its cache the same way they
+// invalidate the shared one: Update, UpdateMany, Delete and DeleteMany drop
+// the affected IIDs, and Put and the Query-level writes drop every entry.
+// Writes made through other managers or other processes are not seen until
+// the entry expires, so pair the cache with WithCacheTTL when that matters.
+// Each hit is hydrated afresh, so callers never share an instance.
+func (m *Manager[T]) WithCache(opts ...CacheOption) *Manager[T] {
+	cp := *m
+	cp.cache = NewMemoryCache(opts...)
+	return &cp
+}
+
+// MemoryCache is an in-process Cache: a size-bounded LRU with optional
+// expiry, configured with WithCacheSize and WithCacheTTL.
+type MemoryCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	order *list.List // front is most recently used
+	items map[CacheKey]*list.Element
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+type memoryCacheEntry struct {
+	key     CacheKey
+	rows    []map[string]any
+	expires time.Time // zero when entries do not expire
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache(opts ...CacheOption) *MemoryCache {
+	cfg := cacheConfig{size: 1024}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &MemoryCache{
+		size:  cfg.size,
+		ttl:   cfg.ttl,
+		order: list.New(),
+		items: make(map[CacheKey]*list.Element, cfg.size),
+	}
+}
+
+// Get implements Cache.
+func (c *MemoryCache) Get(_ context.Context, key CacheKey) ([]map[string]any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && !timeNow().Before(entry.expires) {
+		c.removeElement(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.rows, true
+}
+
+// Set implements Cache. It evicts the least recently used entry when the
+// cache is full.
+func (c *MemoryCache) Set(_ context.Context, key CacheKey, rows []map[string]any) {
+	entry := &memoryCacheEntry{key: key, rows: rows}
+	if c.ttl > 0 {
+		entry.expires = timeNow().Add(c.ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Delete implements Cache.
+func (c *MemoryCache) Delete(_ context.Context, key CacheKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// DeleteType implements Cache.
+func (c *MemoryCache) DeleteType(_ context.Context, typeName string) {
+	c.removeFunc(func(key CacheKey) bool { return key.TypeName == typeName })
+}
+
+// DeleteQueries implements Cache.
+func (c *MemoryCache) DeleteQueries(context.Context) {
+	c.removeFunc(func(key CacheKey) bool { return key.QueryHash != "" })
+}
+
+// Len returns the number of cached entries, including expired ones not yet
+// evicted.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// removeFunc drops the entries whose key satisfies match.
+func (c *MemoryCache) removeFunc(match func(CacheKey) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.items {
+		if match(key) {
+			c.removeElement(el)
+		}
+	}
+}
+
+func (c *MemoryCache) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*memoryCacheEntry).key)
+}
@@ -0,0 +1,126 @@
+package gotype
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func personRow(iid, name string) [][]map[string]any {
+	return [][]map[string]any{{{"_iid": iid, "name": name, "email": name + "@example.com"}}}
+}
+
+func TestCache_GetByIIDServedFromCache(t *testing.T) {
+	registerTestTypes(t)
+	conn := &mockConn{txs: []*mockTx{{responses: personRow("0x1", "alice")}}}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db")).WithCache()
+	ctx := context.Background()
+
+	first, err := mgr.GetByIID(ctx, "0x1")
+	if err != nil || first == nil || first.Name != "alice" {
+		t.Fatalf("GetByIID = %+v, %v", first, err)
+	}
+	first.Name = "mutated"
+
+	second, err := mgr.GetByIID(ctx, "0x1")
+	if err != nil {
+		t.Fatalf("cached GetByIID: %v", err)
+	}
+	if conn.idx != 1 {
+		t.Errorf("expected a single read transaction, got %d", conn.idx)
+	}
+	if second.Name != "alice" {
+		t.Errorf("cached instance shares state with a previous caller: %q", second.Name)
+	}
+}
+
+func TestCache_InvalidatedByUpdateAndDelete(t *testing.T) {
+	registerTestTypes(t)
+	conn := &mockConn{txs: []*mockTx{
+		{responses: personRow("0x1", "alice")},
+		{}, // update
+		{responses: personRow("0x1", "alicia")},
+		{}, // delete
+		{}, // read after delete: no rows
+	}}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db")).WithCache()
+	ctx := context.Background()
+
+	p, _ := mgr.GetByIID(ctx, "0x1")
+	if err := mgr.Update(ctx, p); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	p, _ = mgr.GetByIID(ctx, "0x1")
+	if p == nil || p.Name != "alicia" {
+		t.Fatalf("expected a fresh read after Update, got %+v", p)
+	}
+	if err := mgr.Delete(ctx, p); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if p, _ = mgr.GetByIID(ctx, "0x1"); p != nil {
+		t.Errorf("expected nil after Delete, got %+v", p)
+	}
+	if conn.idx != 5 {
+		t.Errorf("expected 5 transactions, got %d", conn.idx)
+	}
+}
+
+func TestCache_LRUEviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(WithCacheSize(2))
+	key := func(iid string) CacheKey { return CacheKey{TypeName: "person", IID: iid} }
+	c.Set(ctx, key("0x1"), nil)
+	c.Set(ctx, key("0x2"), nil)
+	c.Get(ctx, key("0x1")) // 0x2 is now least recently used
+	c.Set(ctx, key("0x3"), nil)
+
+	if _, ok := c.Get(ctx, key("0x2")); ok {
+		t.Error("expected 0x2 to be evicted")
+	}
+	for _, iid := range []string{"0x1", "0x3"} {
+		if _, ok := c.Get(ctx, key(iid)); !ok {
+			t.Errorf("expected %s to be cached", iid)
+		}
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fixTimeNow(t, now)
+	ctx := context.Background()
+	c := NewMemoryCache(WithCacheSize(8), WithCacheTTL(time.Minute))
+	key := CacheKey{TypeName: "person", IID: "0x1"}
+	c.Set(ctx, key, nil)
+
+	fixTimeNow(t, now.Add(59*time.Second))
+	if _, ok := c.Get(ctx, key); !ok {
+		t.Fatal("entry expired early")
+	}
+	fixTimeNow(t, now.Add(time.Minute))
+	if _, ok := c.Get(ctx, key); ok {
+		t.Error("expected entry to expire after the TTL")
+	}
+}
+
+func TestCache_QueryWriteClearsManagerCache(t *testing.T) {
+	registerTestTypes(t)
+	conn := &mockConn{txs: []*mockTx{
+		{responses: personRow("0x1", "alice")},
+		{responses: [][]map[string]any{{{"count": 1}}}}, // Query.Delete
+	}}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db")).WithCache()
+	ctx := context.Background()
+
+	if _, err := mgr.GetByIID(ctx, "0x1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := mgr.cache.(*MemoryCache).Len(); n != 1 {
+		t.Fatalf("expected the GetByIID result to be cached, got %d entries", n)
+	}
+	if _, err := mgr.Query().Filter(Eq("name", "alice")).Delete(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := mgr.cache.(*MemoryCache).Len(); n != 0 {
+		t.Errorf("Query.Delete left %d entries", n)
+	}
+}
@@ -98,7 +98,7 @@ func TestDatabase_TransactionContext_UsesContextAwareConn(t *testing.T) {
 	if err != nil {
 		t.Fatalf("TransactionContext failed: %v", err)
 	}
-	if gotTx != tx {
+	if unwrapTx(gotTx) != tx {
 		t.Fatalf("TransactionContext returned unexpected tx: got %#v want %#v", gotTx, tx)
 	}
 	if conn.lastCtx != ctx {
@@ -129,7 +129,7 @@ func TestDatabase_BeginContext_UsesContextAwareConn(t *testing.T) {
 	if conn.lastCtx != ctx {
 		t.Fatal("BeginContext did not forward ctx to conn")
 	}
-	if tc.Tx() != tx {
+	if unwrapTx(tc.Tx()) != tx {
 		t.Fatalf("BeginContext returned unexpected tx: got %#v want %#v", tc.Tx(), tx)
 	}
 }
@@ -185,3 +185,11 @@ func TestTransactionContextHandleLeak_IncrementsLeakCounter(t *testing.T) {
 		t.Fatal("expected leaked TransactionContext to be marked closed")
 	}
 }
+
+// unwrapTx returns the Conn's transaction behind the Database's stats wrapper.
+func unwrapTx(tx Tx) Tx {
+	if w, ok := tx.(interface{ Unwrap() Tx }); ok {
+		return w.Unwrap()
+	}
+	return tx
+}
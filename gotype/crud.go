@@ -5,7 +5,8 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"strings"
+	"slices"
+	"strconv"
 
 	"github.com/CaliLuke/go-typeql/ast"
 )
@@ -13,16 +14,22 @@ import (
 // Manager provides high-level, generic CRUD (Create, Read, Update, Delete) operations
 // for a registered TypeDB model type T.
 type Manager[T any] struct {
-	db       *Database
-	info     *ModelInfo
-	strategy ModelStrategy
-	tx       Tx // non-nil when bound to a specific transaction
+	db            *Database
+	info          *ModelInfo
+	strategy      ModelStrategy
+	tx            Tx              // non-nil when bound to a specific transaction
+	txType        TransactionType // type of tx
+	readTx        Tx              // non-nil when reads run in a transaction (WithReadTransaction)
+	cache         Cache           // non-nil when enabled with WithCache
+	ownFieldsOnly bool            // inherited attributes are not fetched (IncludeInherited)
 }
 
 // NewManager creates a new Manager for the model type T.
-// T must be a struct that has been registered via Register[T]().
+// T must be registered in the database's registry (see Database.WithRegistry),
+// which is the default registry populated by Register[T]() unless overridden.
+// If it is not, the returned error wraps a *NotRegisteredError.
 func NewManager[T any](db *Database) (*Manager[T], error) {
-	info, err := lookupManagerInfo[T]()
+	info, err := lookupManagerInfo[T](db.Registry())
 	if err != nil {
 		return nil, err
 	}
@@ -46,8 +53,11 @@ func MustNewManager[T any](db *Database) *Manager[T] {
 
 // NewManagerWithTx creates a Manager bound to an existing transaction context.
 // All operations performed by this manager will use the provided transaction.
+// Reads are legal in every transaction type; writes need a write or schema
+// transaction and fail with a *TransactionTypeError, before any query is
+// sent, when tc is a read transaction.
 func NewManagerWithTx[T any](tc *TransactionContext) (*Manager[T], error) {
-	info, err := lookupManagerInfo[T]()
+	info, err := lookupManagerInfo[T](tc.db.Registry())
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +66,7 @@ func NewManagerWithTx[T any](tc *TransactionContext) (*Manager[T], error) {
 		info:     info,
 		strategy: strategyFor(info.Kind),
 		tx:       tc.Tx(),
+		txType:   tc.txType,
 	}, nil
 }
 
@@ -69,22 +80,63 @@ func MustNewManagerWithTx[T any](tc *TransactionContext) *Manager[T] {
 	return mgr
 }
 
-func lookupManagerInfo[T any]() (*ModelInfo, error) {
+// WithReadTransaction returns a copy of the manager whose reads, including
+// those of queries built from it, run in tc, so they see the snapshot of
+// other work done in tc, and its uncommitted writes when tc is a write
+// transaction. Writes keep opening their own write transactions, and the
+// per-manager and shared caches are bypassed. The caller keeps managing tc's
+// lifecycle.
+func (m *Manager[T]) WithReadTransaction(tc *TransactionContext) *Manager[T] {
+	cp := *m
+	cp.readTx = tc.Tx()
+	return &cp
+}
+
+// Info returns the metadata of the model type the manager works on.
+func (m *Manager[T]) Info() *ModelInfo {
+	return m.info
+}
+
+// Database returns the database the manager was created for.
+func (m *Manager[T]) Database() *Database {
+	return m.db
+}
+
+// ToDict is ToDict resolved through the manager's registry.
+func (m *Manager[T]) ToDict(instance *T) (map[string]any, error) {
+	if instance == nil {
+		return nil, fmt.Errorf("gotype: %s instance must not be nil", m.info.TypeName)
+	}
+	return toDictWithInfo(reflect.ValueOf(instance).Elem(), m.info)
+}
+
+// FromDict is FromDict resolved through the manager's registry. Role players
+// are read from nested maps keyed by role name, as in fetch results.
+func (m *Manager[T]) FromDict(data map[string]any, opts ...HydrateOption) (*T, error) {
+	if err := checkStrictHydration(m.info, data, opts); err != nil {
+		return nil, err
+	}
+	return hydrateNewWithInfo[T](m.info, data)
+}
+
+func lookupManagerInfo[T any](reg *Registry) (*ModelInfo, error) {
 	var zero T
 	t := reflect.TypeOf(zero)
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
 
-	info, ok := LookupType(t)
+	info, ok := reg.LookupType(t)
 	if !ok {
-		return nil, fmt.Errorf("gotype: type %s is not registered; call Register[%s]() first", t.Name(), t.Name())
+		return nil, fmt.Errorf("gotype: %w; call Register[%s]() first", &NotRegisteredError{TypeName: t.Name()}, t.Name())
 	}
 	return info, nil
 }
 
 // Insert adds a new instance of T to the database.
 // If T has key fields, the instance's internal IID will be populated upon success.
+// The relations of non-nil typed role-player fields (tagged "rel:") are
+// inserted in the same transaction; the linked entities must already exist.
 func (m *Manager[T]) Insert(ctx context.Context, instance *T) error {
 	if instance == nil {
 		return fmt.Errorf("insert %s: instance must not be nil", m.info.TypeName)
@@ -92,10 +144,13 @@ func (m *Manager[T]) Insert(ctx context.Context, instance *T) error {
 	if err := checkCtx(ctx, "insert", m.info.TypeName); err != nil {
 		return err
 	}
+	applyDefaults(m.info, instance)
+	stampTimestamps(m.info, instance, stampInsert)
 	insertQuery, err := m.strategy.BuildInsertQuery(m.info, instance, "e")
 	if err != nil {
 		return fmt.Errorf("insert %s: build query: %w", m.info.TypeName, err)
 	}
+	defer m.invalidateCache(ctx)
 
 	tx, autoCommit, err := m.writeTx()
 	if err != nil {
@@ -112,11 +167,17 @@ func (m *Manager[T]) Insert(ctx context.Context, instance *T) error {
 	}
 
 	// Parse IID from insert result (fetch clause returns it)
+	var iid string
 	if len(results) == 1 {
-		if iid := extractIID(results[0]); iid != "" {
+		if iid = extractIID(results[0]); iid != "" {
 			setIIDOnInfo(instance, m.info, iid)
 		}
 	}
+	if len(m.info.Links) > 0 {
+		if err := insertLinks(ctx, tx, m.info, reflect.ValueOf(instance).Elem(), iid); err != nil {
+			return fmt.Errorf("insert %s: %w", m.info.TypeName, err)
+		}
+	}
 
 	if autoCommit {
 		if err := tx.Commit(); err != nil {
@@ -133,13 +194,13 @@ func (m *Manager[T]) Get(ctx context.Context, filters map[string]any) ([]*T, err
 	if err != nil {
 		return nil, fmt.Errorf("get %s: build match: %w", m.info.TypeName, err)
 	}
-	fetchQuery, err := m.strategy.BuildFetchAll(m.info, "e")
+	fetchQuery, err := m.strategy.BuildFetchAll(m.fetchInfo(), "e")
 	if err != nil {
 		return nil, fmt.Errorf("get %s: build fetch: %w", m.info.TypeName, err)
 	}
 	query := matchQuery + "\n" + fetchQuery
 
-	results, err := m.readQuery(ctx, query)
+	results, err := m.cachedRead(ctx, query, m.readQuery)
 	if err != nil {
 		return nil, fmt.Errorf("get %s: %w", m.info.TypeName, err)
 	}
@@ -159,7 +220,7 @@ func (m *Manager[T]) GetWithRoles(ctx context.Context, filters map[string]any) (
 	if err != nil {
 		return nil, fmt.Errorf("get_with_roles %s: build match: %w", m.info.TypeName, err)
 	}
-	matchAdditions, fetchQuery, err := m.strategy.BuildFetchWithRoles(m.info, "e")
+	matchAdditions, fetchQuery, err := m.strategy.BuildFetchWithRoles(m.fetchInfo(), "e")
 	if err != nil {
 		return nil, fmt.Errorf("get_with_roles %s: build fetch: %w", m.info.TypeName, err)
 	}
@@ -168,7 +229,7 @@ func (m *Manager[T]) GetWithRoles(ctx context.Context, filters map[string]any) (
 	}
 	query := matchQuery + "\n" + fetchQuery
 
-	results, err := m.readQuery(ctx, query)
+	results, err := m.cachedRead(ctx, query, m.readQuery)
 	if err != nil {
 		return nil, fmt.Errorf("get_with_roles %s: %w", m.info.TypeName, err)
 	}
@@ -180,15 +241,27 @@ func (m *Manager[T]) GetWithRoles(ctx context.Context, filters map[string]any) (
 // It returns nil if no instance is found with the given IID.
 func (m *Manager[T]) GetByIID(ctx context.Context, iid string) (*T, error) {
 	matchQuery := fmt.Sprintf("match\n$e isa %s, iid %s;", m.info.TypeName, iid)
-	fetchQuery, err := m.strategy.BuildFetchAll(m.info, "e")
+	fetchQuery, err := m.strategy.BuildFetchAll(m.fetchInfo(), "e")
 	if err != nil {
 		return nil, fmt.Errorf("get_by_iid %s: build fetch: %w", m.info.TypeName, err)
 	}
 	query := matchQuery + "\n" + fetchQuery
 
-	results, err := m.readQuery(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("get_by_iid %s: %w", m.info.TypeName, err)
+	c := m.byIIDCache()
+	key := CacheKey{TypeName: m.info.TypeName, IID: iid}
+	var results []map[string]any
+	found := false
+	if c != nil {
+		results, found = c.Get(ctx, key)
+	}
+	if !found {
+		results, err = m.readQuery(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("get_by_iid %s: %w", m.info.TypeName, err)
+		}
+		if c != nil {
+			c.Set(ctx, key, results)
+		}
 	}
 
 	instances, err := m.hydrateResults(results)
@@ -203,6 +276,8 @@ func (m *Manager[T]) GetByIID(ctx context.Context, iid string) (*T, error) {
 
 // Update modifies an existing instance of T in the database.
 // The instance must have its IID populated (typically from a prior Get or Insert).
+// Only attributes are written: for relations the role players are kept (see
+// UpdatePlayers to change them).
 func (m *Manager[T]) Update(ctx context.Context, instance *T) error {
 	if instance == nil {
 		return fmt.Errorf("update %s: instance must not be nil", m.info.TypeName)
@@ -214,6 +289,7 @@ func (m *Manager[T]) Update(ctx context.Context, instance *T) error {
 	if iid == "" {
 		return fmt.Errorf("update %s: instance has no IID", m.info.TypeName)
 	}
+	defer m.invalidateCache(ctx, iid)
 
 	tx, autoCommit, err := m.writeTx()
 	return m.withWriteTx(ctx, "update", func() (Tx, bool, error) {
@@ -232,28 +308,50 @@ func (m *Manager[T]) updateInstanceInTx(ctx context.Context, tx Tx, instance *T)
 		return fmt.Errorf("update %s: instance has no IID", m.info.TypeName)
 	}
 
+	stampTimestamps(m.info, instance, stampUpdate)
 	v := reflectValue(instance)
 
 	// Collect non-key attribute names for deletion, and new values for insertion.
-	var delAttrs []string
-	var insHas []string
+	delAttrs := make([]string, 0, len(m.info.Fields))
+	insHas := make([]string, 0, len(m.info.Fields))
 
 	for _, fi := range m.info.Fields {
 		if fi.Tag.Key {
 			continue
 		}
+		if fi.Tag.AutoCreate && fi.fieldValue(v).IsZero() {
+			continue // keep the stored creation time
+		}
 		delAttrs = append(delAttrs, fi.Tag.Name)
 
-		field := v.Field(fi.FieldIndex)
-		if fi.IsPointer && field.IsNil() {
+		val, err := extractSingleFieldValue(v, fi)
+		if err != nil {
+			return fmt.Errorf("update %s: %w", m.info.TypeName, err)
+		}
+		if val == nil {
 			continue // nil optional: delete only, no insert
 		}
+		has, err := hasClause(fi.Tag.Name, val)
+		if err != nil {
+			return fmt.Errorf("update %s: %w", m.info.TypeName, err)
+		}
+		insHas = append(insHas, has)
+	}
 
-		val := field.Interface()
-		if fi.IsPointer {
-			val = field.Elem().Interface()
+	// Attributes in the extra bag replace existing values of the same type.
+	// Attributes removed from the bag are left untouched.
+	seenExtra := make(map[string]bool)
+	err := visitExtraValues(v, m.info, func(name string, val any) error {
+		if !seenExtra[name] {
+			seenExtra[name] = true
+			delAttrs = append(delAttrs, name)
 		}
-		insHas = append(insHas, fmt.Sprintf("has %s %s", fi.Tag.Name, FormatValue(val)))
+		has, err := hasClause(name, val)
+		insHas = append(insHas, has)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("update %s: %w", m.info.TypeName, err)
 	}
 
 	// Single query: match entity + try-match old attrs, delete old, insert new.
@@ -263,7 +361,7 @@ func (m *Manager[T]) updateInstanceInTx(ctx context.Context, tx Tx, instance *T)
 	}
 
 	query := buildBatchUpdate(m.info.TypeName, iid, delAttrs, insHas)
-	_, err := tx.QueryWithContext(ctx, query)
+	_, err = tx.QueryWithContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("update %s: %w", m.info.TypeName, err)
 	}
@@ -274,25 +372,44 @@ func (m *Manager[T]) updateInstanceInTx(ctx context.Context, tx Tx, instance *T)
 // all non-key attributes in one round-trip. Uses try { } blocks in both
 // the match and delete clauses so missing optional attributes are skipped.
 func buildBatchUpdate(typeName, iid string, delAttrs, insHas []string) string {
-	var b strings.Builder
-	fmt.Fprintf(&b, "match\n$e isa %s, iid %s;\n", typeName, iid)
+	b := getQueryBuf()
+	defer putQueryBuf(b)
+
+	b.WriteString("match\n$e isa ")
+	b.WriteString(typeName)
+	b.WriteString(", iid ")
+	b.WriteString(iid)
+	b.WriteString(";\n")
 
 	// Try-match each old attribute (try block needs inner ; and outer ;)
 	for i, attr := range delAttrs {
-		fmt.Fprintf(&b, "try { $e has %s $old%d; };\n", attr, i)
+		b.WriteString("try { $e has ")
+		b.WriteString(attr)
+		b.WriteString(" $old")
+		b.WriteString(strconv.Itoa(i))
+		b.WriteString("; };\n")
 	}
 
 	// Delete old values using try blocks
 	if len(delAttrs) > 0 {
 		b.WriteString("delete\n")
 		for i := range delAttrs {
-			fmt.Fprintf(&b, "try { $old%d of $e; };\n", i)
+			b.WriteString("try { $old")
+			b.WriteString(strconv.Itoa(i))
+			b.WriteString(" of $e; };\n")
 		}
 	}
 
 	// Insert new values
 	if len(insHas) > 0 {
-		fmt.Fprintf(&b, "insert $e %s;", strings.Join(insHas, ", "))
+		b.WriteString("insert $e ")
+		for i, h := range insHas {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(h)
+		}
+		b.WriteString(";")
 	}
 
 	return b.String()
@@ -322,6 +439,7 @@ func (m *Manager[T]) Delete(ctx context.Context, instance *T, opts ...DeleteOpti
 	if iid == "" {
 		return fmt.Errorf("delete %s: instance has no IID", m.info.TypeName)
 	}
+	defer m.invalidateCache(ctx, iid)
 
 	cfg := deleteConfig{}
 	for _, o := range opts {
@@ -340,6 +458,9 @@ func (m *Manager[T]) Delete(ctx context.Context, instance *T, opts ...DeleteOpti
 
 	query := fmt.Sprintf("match\n$e isa %s, iid %s;\ndelete $e;", m.info.TypeName, iid)
 	if m.tx != nil {
+		if err := m.writable(); err != nil {
+			return fmt.Errorf("delete %s: %w", m.info.TypeName, err)
+		}
 		_, err := m.tx.QueryWithContext(ctx, query)
 		if err != nil {
 			return fmt.Errorf("delete %s: %w", m.info.TypeName, err)
@@ -373,6 +494,7 @@ func (m *Manager[T]) DeleteMany(ctx context.Context, instances []*T, opts ...Del
 			return fmt.Errorf("delete_many %s[%d]: instance has no IID", m.info.TypeName, i)
 		}
 	}
+	defer m.invalidateCache(ctx, m.cachedIIDs(instances)...)
 
 	// Strict mode: pre-check existence of all instances
 	if cfg.strict {
@@ -416,6 +538,7 @@ func (m *Manager[T]) UpdateMany(ctx context.Context, instances []*T) error {
 			return fmt.Errorf("update_many %s[%d]: instance has no IID", m.info.TypeName, i)
 		}
 	}
+	defer m.invalidateCache(ctx, m.cachedIIDs(instances)...)
 
 	return m.withWriteTx(ctx, "update_many", m.writeTx, func(tx Tx) error {
 		for i, inst := range instances {
@@ -436,10 +559,12 @@ func (m *Manager[T]) Put(ctx context.Context, instance *T) error {
 	if err := checkCtx(ctx, "put", m.info.TypeName); err != nil {
 		return err
 	}
+	stampTimestamps(m.info, instance, stampPut)
 	putQuery, err := m.strategy.BuildPutQuery(m.info, instance, "e")
 	if err != nil {
 		return fmt.Errorf("put %s: build query: %w", m.info.TypeName, err)
 	}
+	defer m.invalidateCacheType(ctx)
 
 	return m.withWriteTx(ctx, "put", m.writeTx, func(tx Tx) error {
 		_, err = tx.QueryWithContext(ctx, putQuery)
@@ -469,56 +594,124 @@ func (m *Manager[T]) Put(ctx context.Context, instance *T) error {
 	})
 }
 
-// PutMany upserts multiple instances in a single transaction.
-func (m *Manager[T]) PutMany(ctx context.Context, instances []*T) error {
+// PutStatus is what PutMany did with one instance.
+type PutStatus int
+
+const (
+	// PutCreated means no instance with the same key existed, so one was
+	// inserted.
+	PutCreated PutStatus = iota
+	// PutExisted means an instance with the same key already existed.
+	PutExisted
+	// PutUnchecked means the instance was put but its type has no key
+	// fields, so whether it already existed is unknown.
+	PutUnchecked
+)
+
+// String returns the lowercase name of the status.
+func (s PutStatus) String() string {
+	switch s {
+	case PutCreated:
+		return "created"
+	case PutExisted:
+		return "existed"
+	case PutUnchecked:
+		return "unchecked"
+	}
+	return fmt.Sprintf("PutStatus(%d)", int(s))
+}
+
+// PutOutcome reports the result of PutMany for one instance.
+type PutOutcome struct {
+	Status PutStatus
+	// IID is the instance's IID, for types with key fields.
+	IID string
+}
+
+// PutMany upserts multiple instances in a single transaction and reports,
+// per instance, whether it was created or already existed, so that importers
+// can count inserts and updates. Existence is checked by key in the same
+// transaction, before each put. The batch is all or nothing: a nil instance,
+// one whose query cannot be built or a failing query returns an error and
+// nothing is written. After a successful commit the IIDs of keyed instances
+// are set.
+func (m *Manager[T]) PutMany(ctx context.Context, instances []*T) ([]PutOutcome, error) {
 	if len(instances) == 0 {
-		return nil
+		return nil, nil
 	}
 
+	defer m.invalidateCacheType(ctx)
+
+	outcomes := make([]PutOutcome, len(instances))
 	err := m.withWriteTx(ctx, "put_many", m.newWriteTx, func(tx Tx) error {
 		for i, inst := range instances {
 			if inst == nil {
 				return fmt.Errorf("put_many %s[%d]: instance must not be nil", m.info.TypeName, i)
 			}
-			varName := fmt.Sprintf("e%d", i)
-			putQuery, err := m.strategy.BuildPutQuery(m.info, inst, varName)
+			stampTimestamps(m.info, inst, stampPut)
+			putQuery, err := m.strategy.BuildPutQuery(m.info, inst, fmt.Sprintf("e%d", i))
 			if err != nil {
 				return fmt.Errorf("put_many %s[%d]: build query: %w", m.info.TypeName, i, err)
 			}
+			var iidQuery string
+			if len(m.info.KeyFields) > 0 {
+				matchQuery, err := m.strategy.BuildMatchByKey(m.info, inst, "e")
+				if err != nil {
+					return fmt.Errorf("put_many %s[%d]: build iid query: %w", m.info.TypeName, i, err)
+				}
+				iidQuery = matchQuery + "\n" + `fetch { "_iid": iid($e) };`
+			}
 
-			_, err = tx.QueryWithContext(ctx, putQuery)
+			outcome, err := putChecked(ctx, tx, putQuery, iidQuery)
 			if err != nil {
 				return fmt.Errorf("put_many %s[%d]: %w", m.info.TypeName, i, err)
 			}
+			outcomes[i] = outcome
 		}
 		return nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Fetch IIDs in a read transaction
-	for _, inst := range instances {
-		if len(m.info.KeyFields) > 0 {
-			matchQuery, err := m.strategy.BuildMatchByKey(m.info, inst, "e")
-			if err != nil {
-				return fmt.Errorf("put_many %s: build iid query: %w", m.info.TypeName, err)
-			}
-			iidQuery := matchQuery + "\n" + `fetch { "_iid": iid($e) };`
+	for i, inst := range instances {
+		if outcomes[i].IID != "" {
+			setIIDOnInfo(inst, m.info, outcomes[i].IID)
+		}
+	}
+	return outcomes, nil
+}
 
-			results, err := m.db.ExecuteRead(ctx, iidQuery)
-			if err != nil {
-				return fmt.Errorf("put_many %s: fetch iid: %w", m.info.TypeName, err)
-			}
-			if len(results) == 1 {
-				if iid := extractIID(results[0]); iid != "" {
-					setIIDOnInfo(inst, m.info, iid)
-				}
-			}
+// putChecked runs putQuery in tx. When iidQuery is set it runs it before
+// the put, to learn whether the instance existed, and after a creating put,
+// to learn its IID.
+func putChecked(ctx context.Context, tx Tx, putQuery, iidQuery string) (PutOutcome, error) {
+	if iidQuery == "" {
+		if _, err := tx.QueryWithContext(ctx, putQuery); err != nil {
+			return PutOutcome{}, err
 		}
+		return PutOutcome{Status: PutUnchecked}, nil
 	}
 
-	return nil
+	existing, err := tx.QueryWithContext(ctx, iidQuery)
+	if err != nil {
+		return PutOutcome{}, fmt.Errorf("check key: %w", err)
+	}
+	if _, err := tx.QueryWithContext(ctx, putQuery); err != nil {
+		return PutOutcome{}, err
+	}
+	if len(existing) == 1 {
+		return PutOutcome{Status: PutExisted, IID: extractIID(existing[0])}, nil
+	}
+	created, err := tx.QueryWithContext(ctx, iidQuery)
+	if err != nil {
+		return PutOutcome{}, fmt.Errorf("fetch iid: %w", err)
+	}
+	outcome := PutOutcome{Status: PutCreated}
+	if len(created) == 1 {
+		outcome.IID = extractIID(created[0])
+	}
+	return outcome, nil
 }
 
 // countByIID checks if an instance with the given IID exists.
@@ -539,23 +732,32 @@ func (m *Manager[T]) InsertMany(ctx context.Context, instances []*T) error {
 	if len(instances) == 0 {
 		return nil
 	}
+	return m.insertBatch(ctx, "insert_many", instances, 0)
+}
 
+// insertBatch inserts instances in one new write transaction and sets their
+// IIDs after commit. offset is added to indices in error messages so shards
+// of a larger slice report their position in it.
+func (m *Manager[T]) insertBatch(ctx context.Context, op string, instances []*T, offset int) error {
+	defer m.invalidateCache(ctx)
 	pendingIIDs := make([]string, len(instances))
-	err := m.withWriteTx(ctx, "insert_many", m.newWriteTx, func(tx Tx) error {
+	err := m.withWriteTx(ctx, op, m.newWriteTx, func(tx Tx) error {
 		for i, inst := range instances {
 			if inst == nil {
-				return fmt.Errorf("insert_many %s[%d]: instance must not be nil", m.info.TypeName, i)
+				return fmt.Errorf("%s %s[%d]: instance must not be nil", op, m.info.TypeName, offset+i)
 			}
-			varName := fmt.Sprintf("e%d", i)
+			varName := "e" + strconv.Itoa(i)
+			applyDefaults(m.info, inst)
+			stampTimestamps(m.info, inst, stampInsert)
 			insertQuery, err := m.strategy.BuildInsertQuery(m.info, inst, varName)
 			if err != nil {
-				return fmt.Errorf("insert_many %s[%d]: build query: %w", m.info.TypeName, i, err)
+				return fmt.Errorf("%s %s[%d]: build query: %w", op, m.info.TypeName, offset+i, err)
 			}
 
 			// Execute insert with fetch - get IID in same query
 			results, err := tx.QueryWithContext(ctx, insertQuery)
 			if err != nil {
-				return fmt.Errorf("insert_many %s[%d]: %w", m.info.TypeName, i, err)
+				return fmt.Errorf("%s %s[%d]: %w", op, m.info.TypeName, offset+i, err)
 			}
 
 			// Parse IID from insert result (fetch clause returns it)
@@ -614,8 +816,9 @@ func (m *Manager[T]) GetByIIDPolymorphic(ctx context.Context, iid string) (*T, s
 		}
 	}
 
-	instance, err := HydrateNew[T](results[0])
+	instance, err := hydrateNewWithInfo[T](m.info, results[0])
 	if err != nil {
+		m.db.stats.recordHydrationError()
 		return nil, "", fmt.Errorf("hydrate %s: %w", m.info.TypeName, err)
 	}
 	return instance, typeLabel, nil
@@ -654,8 +857,9 @@ func (m *Manager[T]) GetByIIDPolymorphicAny(ctx context.Context, iid string) (an
 		}
 	}
 
-	instance, err := HydrateAny(results[0])
+	instance, err := hydrateAnyIn(registryOf(m.info), results[0])
 	if err != nil {
+		m.db.stats.recordHydrationError()
 		return nil, "", fmt.Errorf("hydrate_any %s: %w", typeLabel, err)
 	}
 	return instance, typeLabel, nil
@@ -675,6 +879,9 @@ func checkCtx(ctx context.Context, op, typeName string) error {
 // If a bound tx is used, autoCommit is false (caller manages lifecycle).
 func (m *Manager[T]) writeTx() (tx Tx, autoCommit bool, err error) {
 	if m.tx != nil {
+		if err := m.writable(); err != nil {
+			return nil, false, err
+		}
 		return m.tx, false, nil
 	}
 	tx, err = m.db.Transaction(WriteTransaction)
@@ -684,6 +891,15 @@ func (m *Manager[T]) writeTx() (tx Tx, autoCommit bool, err error) {
 	return tx, true, nil
 }
 
+// writable returns a *TransactionTypeError if the bound transaction cannot
+// write.
+func (m *Manager[T]) writable() error {
+	if m.tx != nil && m.txType < WriteTransaction {
+		return &TransactionTypeError{Have: m.txType, Need: WriteTransaction}
+	}
+	return nil
+}
+
 func (m *Manager[T]) newWriteTx() (Tx, bool, error) {
 	tx, err := m.db.Transaction(WriteTransaction)
 	if err != nil {
@@ -713,10 +929,19 @@ func (m *Manager[T]) withWriteTx(ctx context.Context, op string, open func() (Tx
 	return nil
 }
 
+// readTransaction returns the transaction reads run in, or nil when each read
+// opens its own.
+func (m *Manager[T]) readTransaction() Tx {
+	if m.tx != nil {
+		return m.tx
+	}
+	return m.readTx
+}
+
 // readQuery executes a read query using the bound tx or a new read transaction.
 func (m *Manager[T]) readQuery(ctx context.Context, query string) ([]map[string]any, error) {
-	if m.tx != nil {
-		return m.tx.QueryWithContext(ctx, query)
+	if tx := m.readTransaction(); tx != nil {
+		return tx.QueryWithContext(ctx, query)
 	}
 	return m.db.ExecuteRead(ctx, query)
 }
@@ -728,7 +953,8 @@ func (m *Manager[T]) buildFilteredMatch(varName string, filters map[string]any)
 		return m.strategy.BuildMatchAll(m.info, varName)
 	}
 
-	var b strings.Builder
+	b := getQueryBuf()
+	defer putQueryBuf(b)
 	b.WriteString("match\n$")
 	b.WriteString(varName)
 	b.WriteString(" isa ")
@@ -737,21 +963,35 @@ func (m *Manager[T]) buildFilteredMatch(varName string, filters map[string]any)
 		b.WriteString(",\nhas ")
 		b.WriteString(attr)
 		b.WriteByte(' ')
-		b.WriteString(FormatValue(val))
+		lit, err := appendValue(b.AvailableBuffer(), val)
+		if err != nil {
+			return "", fmt.Errorf("attribute %s: %w", attr, err)
+		}
+		b.Write(lit)
 	}
 	b.WriteString(";")
 	return b.String(), nil
 }
 
 func (m *Manager[T]) hydrateResults(results []map[string]any) ([]*T, error) {
+	return m.hydrateResultsLimit(results, m.db.maxRows)
+}
+
+// hydrateResultsLimit hydrates results, failing with ErrTooManyRows before
+// hydrating anything when limit > 0 and there are more rows than limit.
+func (m *Manager[T]) hydrateResultsLimit(results []map[string]any, limit int) ([]*T, error) {
 	if len(results) == 0 {
 		return nil, nil
 	}
+	if err := m.checkRowLimit(len(results), limit); err != nil {
+		return nil, err
+	}
 
 	instances := make([]*T, 0, len(results))
 	for _, row := range results {
 		instance, err := hydrateNewWithInfo[T](m.info, row)
 		if err != nil {
+			m.db.stats.recordHydrationError()
 			return nil, fmt.Errorf("hydrate %s: %w", m.info.TypeName, err)
 		}
 		instances = append(instances, instance)
@@ -759,6 +999,49 @@ func (m *Manager[T]) hydrateResults(results []map[string]any) ([]*T, error) {
 	return instances, nil
 }
 
+// checkRowLimit returns an error wrapping ErrTooManyRows when limit > 0 and
+// rows exceeds it.
+func (m *Manager[T]) checkRowLimit(rows, limit int) error {
+	if limit > 0 && rows > limit {
+		return fmt.Errorf("hydrate %s: %w: %d rows exceed limit of %d", m.info.TypeName, ErrTooManyRows, rows, limit)
+	}
+	return nil
+}
+
+// hydrateAnyResults hydrates each row into the concrete type named by its
+// "_type" label, falling back to T for labels missing from the registry.
+// Like hydrateResultsLimit, it refuses more than limit rows when limit > 0.
+func (m *Manager[T]) hydrateAnyResults(results []map[string]any, limit int) ([]any, error) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+	if err := m.checkRowLimit(len(results), limit); err != nil {
+		return nil, err
+	}
+
+	reg := registryOf(m.info)
+	instances := make([]any, 0, len(results))
+	for _, row := range results {
+		var label string
+		if tl, ok := lookupResultValue(row, "_type"); ok {
+			label, _ = tl.(string)
+		}
+		var instance any
+		var err error
+		if _, ok := reg.ResolveType(label); ok {
+			instance, err = hydrateAnyIn(reg, row)
+		} else {
+			instance, err = hydrateNewWithInfo[T](m.info, row)
+		}
+		if err != nil {
+			m.db.stats.recordHydrationError()
+			return nil, fmt.Errorf("hydrate %s: %w", label, err)
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
 // getIIDOfInfo extracts the IID from any entity or relation pointer using
 // the already-resolved model info when available.
 func getIIDOfInfo[T any](instance *T, info *ModelInfo) string {
@@ -794,7 +1077,20 @@ func extractIID(result map[string]any) string {
 
 // unwrapResult flattens nested TypeDB result structures.
 // TypeDB fetch results may wrap values as {"value": X, "type": {...}}.
+// unwrapResult flattens {"value": v} wrappers in a result row. Rows without
+// wrapped values, the common case for fetch results, are returned as is;
+// callers must treat the returned map as read-only.
 func unwrapResult(result map[string]any) map[string]any {
+	wrapped := false
+	for _, val := range result {
+		if isWrappedValue(val) {
+			wrapped = true
+			break
+		}
+	}
+	if !wrapped {
+		return result
+	}
 	flat := make(map[string]any, len(result))
 	for key, val := range result {
 		flat[key] = unwrapValue(val)
@@ -802,6 +1098,16 @@ func unwrapResult(result map[string]any) map[string]any {
 	return flat
 }
 
+// isWrappedValue reports whether unwrapValue would replace val.
+func isWrappedValue(val any) bool {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = m["value"]
+	return ok
+}
+
 func unwrapValue(val any) any {
 	if val == nil {
 		return nil
@@ -820,6 +1126,22 @@ func unwrapValue(val any) any {
 // plus the union of all attribute fields from the base type and all registered
 // subtypes. This allows polymorphic retrieval in a single query.
 func buildPolymorphicFetch(info *ModelInfo, varName string) (string, error) {
+	if varName != templateVar || info.templates == nil {
+		return compilePolymorphicFetch(info, varName)
+	}
+	gen := registryOf(info).gen.Load()
+	if cached := info.templates.poly.Load(); cached != nil && cached.gen == gen {
+		return cached.fetch, nil
+	}
+	fetch, err := compilePolymorphicFetch(info, varName)
+	if err != nil {
+		return "", err
+	}
+	info.templates.poly.Store(&polyFetchTemplate{gen: gen, fetch: fetch})
+	return fetch, nil
+}
+
+func compilePolymorphicFetch(info *ModelInfo, varName string) (string, error) {
 	var items []ast.FetchItem
 	items = append(items, ast.FetchFunc("_iid", "iid", "$"+varName))
 	items = append(items, ast.FetchFunc("_type", "label", "$t"))
@@ -828,13 +1150,17 @@ func buildPolymorphicFetch(info *ModelInfo, varName string) (string, error) {
 		items = appendFetchField(items, fi, varName)
 	}
 	// Add subtype-only fields
-	for _, sub := range SubtypesOf(info.TypeName) {
+	subtypes := registryOf(info).SubtypesOf(info.TypeName)
+	for _, sub := range subtypes {
 		for _, fi := range sub.Fields {
 			if _, exists := fieldByName(info.Fields, fi.Tag.Name); !exists {
 				items = appendFetchField(items, fi, varName)
 			}
 		}
 	}
+	if info.extraField != nil || slices.ContainsFunc(subtypes, (*ModelInfo).HasExtra) {
+		items = append(items, ast.FetchNestedWildcard{Key: ExtraAttributesKey, Var: "$" + varName})
+	}
 
 	fetch := ast.Fetch(items...)
 	return compileNode(fetch)
@@ -2,12 +2,17 @@ package gotype
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 // --- Mock transaction and connection ---
+//
+// gotypetest.ScriptedConn is the exported version of these doubles. It
+// imports gotype, so this package's own tests cannot use it.
 
 type mockTx struct {
 	queries   []string
@@ -141,6 +146,48 @@ func TestManager_Insert_WrappedIID(t *testing.T) {
 	}
 }
 
+type testMarriage struct {
+	BaseRelation
+	Spouse1 *testPerson `typedb:"role:spouse,card=2..2"`
+	Spouse2 *testPerson `typedb:"role:spouse"`
+}
+
+func TestManager_Insert_RoleCardinality(t *testing.T) {
+	reg := NewRegistry()
+	MustRegisterIn[testPerson](reg)
+	MustRegisterIn[testMarriage](reg)
+	MustRegisterIn[testCompany](reg)
+	MustRegisterIn[testEmployment](reg)
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db").WithRegistry(reg)
+
+	alice := &testPerson{Name: "Alice"}
+	alice.SetIID("0x1")
+	err := MustNewManager[testMarriage](db).Insert(context.Background(), &testMarriage{Spouse1: alice})
+	var ce *RoleCardinalityError
+	if !errors.As(err, &ce) || ce.Role != "spouse" || ce.Count != 1 || len(tx.queries) != 0 {
+		t.Fatalf("err = %v, queries = %d", err, len(tx.queries))
+	}
+	if !strings.Contains(err.Error(), `test-marriage: role "spouse" has 1 player(s), want 2..2`) {
+		t.Errorf("err = %v", err)
+	}
+
+	// Cardinalities from a generated registry apply the same way.
+	if err := reg.SetRoleCardinality("test-employment", "employer", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	err = MustNewManager[testEmployment](db).Insert(context.Background(), &testEmployment{Employee: alice})
+	if !errors.As(err, &ce) || ce.Role != "employer" || ce.Max != 1 {
+		t.Errorf("err = %v", err)
+	}
+	if err := reg.SetRoleCardinality("test-employment", "boss", 1, 1); err == nil {
+		t.Error("expected error for unknown role")
+	}
+	if err := reg.SetRoleCardinality("robot", "boss", 1, 1); err == nil {
+		t.Error("expected error for unregistered relation")
+	}
+}
+
 func TestManager_All(t *testing.T) {
 	registerTestTypes(t)
 	readTx := &mockTx{
@@ -315,6 +362,25 @@ func TestManager_Update(t *testing.T) {
 	}
 }
 
+func TestManager_Update_Relation(t *testing.T) {
+	registerTestTypes(t)
+	tx := &mockTx{responses: [][]map[string]any{nil}}
+	mgr := MustNewManager[testEmployment](NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db"))
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	emp := &testEmployment{Employee: &testPerson{Name: "Alice"}, StartDate: &start}
+	emp.SetIID("0x5")
+
+	if err := mgr.UpdateMany(context.Background(), []*testEmployment{emp}); err != nil {
+		t.Fatal(err)
+	}
+	q := tx.queries[0]
+	assertContains(t, q, "$e isa test-employment, iid 0x5;")
+	assertContains(t, q, "insert $e has start-date 2024-01-15")
+	if strings.Contains(q, "links") {
+		t.Errorf("role players must be left untouched:\n%s", q)
+	}
+}
+
 func TestManager_Update_NilOptionalDeletesOnly(t *testing.T) {
 	registerTestTypes(t)
 	// When a pointer field is nil, Update should emit a delete query
@@ -458,6 +524,24 @@ func TestUnwrapResult(t *testing.T) {
 	}
 }
 
+func TestUnwrapResult_NoWrappedValuesReturnsRow(t *testing.T) {
+	input := map[string]any{
+		"_iid": "0x123",
+		"name": "Alice",
+		"tags": []any{"a", "b"},
+		"meta": map[string]any{"label": "person"},
+	}
+
+	flat := unwrapResult(input)
+	flat["marker"] = true
+	if input["marker"] != true {
+		t.Error("expected the original row to be returned when nothing is wrapped")
+	}
+	if n := testing.AllocsPerRun(100, func() { _ = unwrapResult(input) }); n != 0 {
+		t.Errorf("unwrapResult allocated %.0f times, want 0", n)
+	}
+}
+
 func TestManager_DeleteMany(t *testing.T) {
 	registerTestTypes(t)
 	writeTx := &mockTx{}
@@ -698,33 +782,77 @@ func TestManager_Put_SetsIID(t *testing.T) {
 
 func TestManager_PutMany(t *testing.T) {
 	registerTestTypes(t)
-	writeTx := &mockTx{}
-	readTx := &mockTx{
+	// Per instance: key check, put, then the IID fetch when it was created.
+	writeTx := &mockTx{
 		responses: [][]map[string]any{
+			{},
+			{},
 			{{"_iid": "0xP1"}},
 			{{"_iid": "0xP2"}},
+			{},
 		},
 	}
-	conn := &mockConn{txs: []*mockTx{writeTx, readTx, readTx}}
+	conn := &mockConn{txs: []*mockTx{writeTx}}
 	db := NewDatabase(conn, "test_db")
 	mgr := MustNewManager[testPerson](db)
 
 	p1 := &testPerson{Name: "Alice", Email: "a@example.com"}
 	p2 := &testPerson{Name: "Bob", Email: "b@example.com"}
 
-	err := mgr.PutMany(context.Background(), []*testPerson{p1, p2})
+	outcomes, err := mgr.PutMany(context.Background(), []*testPerson{p1, p2})
 	if err != nil {
 		t.Fatalf("PutMany failed: %v", err)
 	}
 
-	if len(writeTx.queries) != 2 {
-		t.Fatalf("expected 2 put queries, got %d", len(writeTx.queries))
+	if len(writeTx.queries) != 5 {
+		t.Fatalf("expected 5 queries, got %d: %q", len(writeTx.queries), writeTx.queries)
 	}
-	assertContains(t, writeTx.queries[0], "put")
+	assertContains(t, writeTx.queries[0], `has name "Alice"`)
 	assertContains(t, writeTx.queries[1], "put")
+	assertContains(t, writeTx.queries[4], "put")
 	if !writeTx.committed {
 		t.Error("transaction was not committed")
 	}
+
+	if len(outcomes) != 2 {
+		t.Fatalf("outcomes = %+v", outcomes)
+	}
+	if o := outcomes[0]; o.Status != PutCreated || o.IID != "0xP1" || p1.GetIID() != "0xP1" {
+		t.Errorf("outcomes[0] = %+v, iid %q", o, p1.GetIID())
+	}
+	if o := outcomes[1]; o.Status != PutExisted || o.IID != "0xP2" || p2.GetIID() != "0xP2" {
+		t.Errorf("outcomes[1] = %+v, iid %q", o, p2.GetIID())
+	}
+	if PutExisted.String() != "existed" {
+		t.Errorf("String() = %q", PutExisted)
+	}
+}
+
+func TestManager_PutMany_NilInstanceWritesNothing(t *testing.T) {
+	registerTestTypes(t)
+	writeTx := &mockTx{}
+	conn := &mockConn{txs: []*mockTx{writeTx}}
+	db := NewDatabase(conn, "test_db")
+	mgr := MustNewManager[testPerson](db)
+
+	p1 := &testPerson{Name: "Alice", Email: "a@example.com"}
+	outcomes, err := mgr.PutMany(context.Background(), []*testPerson{p1, nil})
+	if err == nil {
+		t.Fatal("expected error for nil instance")
+	}
+	assertContains(t, err.Error(), "put_many test-person[1]")
+	if outcomes != nil {
+		t.Errorf("expected no outcomes, got %+v", outcomes)
+	}
+	if writeTx.committed {
+		t.Error("transaction must not be committed")
+	}
+	if !writeTx.closed {
+		t.Error("transaction was not closed")
+	}
+	if p1.GetIID() != "" {
+		t.Errorf("expected no IID on rollback, got %q", p1.GetIID())
+	}
 }
 
 func TestManager_InsertMany_CommitFailureDoesNotSetIIDs(t *testing.T) {
@@ -766,9 +894,9 @@ func TestManager_PutMany_Empty(t *testing.T) {
 	db := NewDatabase(conn, "test_db")
 	mgr := MustNewManager[testPerson](db)
 
-	err := mgr.PutMany(context.Background(), nil)
-	if err != nil {
-		t.Fatalf("PutMany with empty slice should succeed, got: %v", err)
+	outcomes, err := mgr.PutMany(context.Background(), nil)
+	if err != nil || outcomes != nil {
+		t.Fatalf("PutMany with empty slice should succeed, got: %v, %v", outcomes, err)
 	}
 }
 
@@ -788,6 +916,10 @@ func TestNewManager_UnregisteredReturnsError(t *testing.T) {
 	if !strings.Contains(err.Error(), "not registered") {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	var nre *NotRegisteredError
+	if !errors.As(err, &nre) || nre.TypeName != "unregistered" {
+		t.Fatalf("expected *NotRegisteredError, got %T: %v", err, err)
+	}
 }
 
 func TestMustNewManager_Panics_Unregistered(t *testing.T) {
@@ -854,6 +986,74 @@ func TestNewManagerWithTx(t *testing.T) {
 	}
 }
 
+func TestNewManagerWithTx_ReadTransactionRejectsWrites(t *testing.T) {
+	registerTestTypes(t)
+	readTx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{readTx}}, "test_db")
+	tc, err := db.Begin(ReadTransaction)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tc.Close()
+	mgr := MustNewManagerWithTx[testPerson](tc)
+	ctx := context.Background()
+
+	var typeErr *TransactionTypeError
+	err = mgr.Insert(ctx, &testPerson{Name: "Alice"})
+	if !errors.As(err, &typeErr) || typeErr.Have != ReadTransaction || typeErr.Need != WriteTransaction {
+		t.Fatalf("Insert: got %v, want *TransactionTypeError", err)
+	}
+	alice := &testPerson{Name: "Alice"}
+	alice.SetIID("0x1")
+	if err := mgr.Delete(ctx, alice); !errors.As(err, &typeErr) {
+		t.Errorf("Delete: got %v, want *TransactionTypeError", err)
+	}
+	if _, err := mgr.Query().Delete(ctx); !errors.As(err, &typeErr) {
+		t.Errorf("Query.Delete: got %v, want *TransactionTypeError", err)
+	}
+	if len(readTx.queries) != 0 {
+		t.Errorf("expected no queries, got %v", readTx.queries)
+	}
+}
+
+func TestManager_WithReadTransaction(t *testing.T) {
+	registerTestTypes(t)
+	snapshot := &mockTx{responses: [][]map[string]any{
+		{{"name": "Alice", "_iid": "0x1"}},
+		{{"count": int64(1)}},
+	}}
+	write := &mockTx{responses: [][]map[string]any{
+		{{"_iid": map[string]any{"value": "0x2"}}},
+	}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{snapshot, write}}, "test_db")
+	tc, err := db.Begin(ReadTransaction)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tc.Close()
+	ctx := context.Background()
+	mgr := MustNewManager[testPerson](db).WithReadTransaction(tc)
+
+	people, err := mgr.Get(ctx, map[string]any{"name": "Alice"})
+	if err != nil || len(people) != 1 {
+		t.Fatalf("Get: got %v, %v", people, err)
+	}
+	if n, err := MustNewManager[testPerson](db).Query().WithReadTransaction(tc).Count(ctx); err != nil || n != 1 {
+		t.Fatalf("Count: got %d, %v", n, err)
+	}
+	if len(snapshot.queries) != 2 {
+		t.Fatalf("expected both reads in the snapshot, got %v", snapshot.queries)
+	}
+
+	// Writes still open their own transaction.
+	if err := mgr.Insert(ctx, &testPerson{Name: "Bob"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if !write.committed || snapshot.committed {
+		t.Error("expected the insert to commit its own write transaction")
+	}
+}
+
 func TestManager_GetByIIDPolymorphic(t *testing.T) {
 	registerTestTypes(t)
 
@@ -1157,3 +1357,61 @@ func TestEnsureDatabase_CancelledContext(t *testing.T) {
 		t.Fatal("expected error for cancelled context")
 	}
 }
+
+type testOrgPlayer struct {
+	BaseEntity
+	Name string `typedb:"name,key,type:organisation"`
+}
+
+type testMembership struct {
+	BaseRelation
+	Member *testPerson   `typedb:"role:member"`
+	Org    testOrgPlayer `typedb:"role:org"`
+	Since  *string       `typedb:"since"`
+}
+
+func TestManager_GetWithRoles_HydratesPlayers(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testPerson]()
+	MustRegister[testOrgPlayer]()
+	MustRegister[testMembership]()
+
+	readTx := &mockTx{
+		responses: [][]map[string]any{
+			{{
+				"_iid":  "0xREL",
+				"since": "2020",
+				"member": map[string]any{
+					"_iid":  "0xP1",
+					"name":  "Alice",
+					"email": "alice@example.com",
+				},
+				"org": map[string]any{
+					"_iid": "0xO1",
+					"name": "Acme",
+				},
+			}},
+		},
+	}
+	conn := &mockConn{txs: []*mockTx{readTx}}
+	mgr := MustNewManager[testMembership](NewDatabase(conn, "test_db"))
+
+	results, err := mgr.GetWithRoles(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetWithRoles failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	m := results[0]
+	if m.Member == nil || m.Member.Name != "Alice" || m.Member.GetIID() != "0xP1" {
+		t.Errorf("Member = %+v", m.Member)
+	}
+	if m.Org.Name != "Acme" || m.Org.GetIID() != "0xO1" {
+		t.Errorf("Org = %+v", m.Org)
+	}
+
+	// Players registered under a type: override are still fetched in full.
+	q := readTx.queries[0]
+	assertContains(t, q, `"org": [ match $e links (org: $org); fetch { "_iid": iid($org), "name": $org.name }; ]`)
+}
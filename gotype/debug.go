@@ -0,0 +1,148 @@
+package gotype
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DatabaseOption configures a Database created with NewDatabase or
+// NewDatabaseWithPool.
+type DatabaseOption func(*Database)
+
+// DebugOption configures the query dump enabled with WithDebug.
+type DebugOption func(*debugLogger)
+
+// RedactFunc returns the value to print in place of value for attribute attr.
+// It is called for every attribute value in logged queries and result rows;
+// values in queries are the TypeQL literals as written, e.g. `"alice"`.
+type RedactFunc func(attr string, value any) any
+
+// WithDebug makes the Database write every query to w: the transaction type,
+// the query text, the duration, the row count and the rows returned, or the
+// error. It is meant for development; use WithRedactor or RedactAttributes to
+// keep sensitive values out of the dump.
+func WithDebug(w io.Writer, opts ...DebugOption) DatabaseOption {
+	return func(db *Database) {
+		d := &debugLogger{w: w}
+		for _, o := range opts {
+			o(d)
+		}
+		db.debug = d
+	}
+}
+
+// WithRedactor installs fn as the redaction hook of the debug dump.
+func WithRedactor(fn RedactFunc) DebugOption {
+	return func(d *debugLogger) { d.redact = fn }
+}
+
+// RedactAttributes replaces the values of the named attributes with
+// "[REDACTED]" in the debug dump.
+func RedactAttributes(names ...string) DebugOption {
+	return WithRedactor(func(attr string, value any) any {
+		if slices.Contains(names, attr) {
+			return "[REDACTED]"
+		}
+		return value
+	})
+}
+
+// debugLogger writes the query dump of a Database. Writes are serialized so
+// concurrent transactions do not interleave their entries.
+type debugLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	redact RedactFunc
+	seq    int
+}
+
+// hasValueRe matches `has <attr> <literal>` in a query, capturing the
+// attribute name and its string, number or bare literal.
+var hasValueRe = regexp.MustCompile(`\bhas ([A-Za-z_][\w-]*) ("(?:[^"\\]|\\.)*"|[^\s,;]+)`)
+
+// logQuery writes one entry, with the query laid out by FormatTQL. rows may be nil when the results were streamed
+// and not retained; count is the number of rows either way.
+// fields are the WithContextFields attributes, appended to the header.
+func (d *debugLogger) logQuery(txType TransactionType, query string, rows []map[string]any, count int, dur time.Duration, err error, fields []slog.Attr) {
+	if d == nil {
+		return
+	}
+	var b strings.Builder
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seq++
+
+	fmt.Fprintf(&b, "-- typedb %s query #%d (%s)", txTypeName(txType), d.seq, dur.Round(time.Microsecond))
+	if err != nil {
+		fmt.Fprintf(&b, " error: %v", err)
+	} else {
+		fmt.Fprintf(&b, " %d rows", count)
+	}
+	for _, f := range fields {
+		fmt.Fprintf(&b, " %s", f)
+	}
+	b.WriteByte('\n')
+	for line := range strings.SplitSeq(d.redactQuery(FormatTQL(query)), "\n") {
+		b.WriteString("   ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	for i, row := range rows {
+		fmt.Fprintf(&b, "   => [%d] %s\n", i, d.formatRow(row))
+	}
+	io.WriteString(d.w, b.String())
+}
+
+func (d *debugLogger) redactQuery(query string) string {
+	if d.redact == nil {
+		return query
+	}
+	return hasValueRe.ReplaceAllStringFunc(query, func(m string) string {
+		sub := hasValueRe.FindStringSubmatch(m)
+		return "has " + sub[1] + " " + fmt.Sprint(d.redact(sub[1], sub[2]))
+	})
+}
+
+// formatRow renders row with keys in sorted order and attribute values
+// unwrapped and redacted.
+func (d *debugLogger) formatRow(row map[string]any) string {
+	keys := make([]string, 0, len(row))
+	for k := range row {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		val := unwrapValue(row[k])
+		if d.redact != nil {
+			val = d.redact(k, val)
+		}
+		fmt.Fprintf(&b, "%s: %v", k, val)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func txTypeName(t TransactionType) string {
+	switch t {
+	case ReadTransaction:
+		return "read"
+	case WriteTransaction:
+		return "write"
+	case SchemaTransaction:
+		return "schema"
+	default:
+		return fmt.Sprintf("tx(%d)", int(t))
+	}
+}
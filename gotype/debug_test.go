@@ -0,0 +1,76 @@
+package gotype
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWithDebug_DumpsQueriesAndRows(t *testing.T) {
+	registerTestTypes(t)
+	var buf bytes.Buffer
+	conn := &mockConn{txs: []*mockTx{
+		{responses: [][]map[string]any{{
+			{"name": map[string]any{"value": "alice"}, "email": "a@x"},
+		}}},
+	}}
+	db := NewDatabase(conn, "test_db", WithDebug(&buf))
+
+	if _, err := MustNewManager[testPerson](db).Get(context.Background(), map[string]any{"name": "alice"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	out := buf.String()
+	assertContains(t, out, "-- typedb read query #1 (")
+	assertContains(t, out, " 1 rows\n")
+	assertContains(t, out, "   match\n")
+	assertContains(t, out, `has name "alice"`)
+	assertContains(t, out, "   => [0] {email: a@x, name: alice}\n")
+}
+
+func TestWithDebug_RedactsAttributes(t *testing.T) {
+	registerTestTypes(t)
+	var buf bytes.Buffer
+	conn := &mockConn{txs: []*mockTx{
+		{responses: [][]map[string]any{{{"_iid": "0x1"}}}},
+		{responses: [][]map[string]any{{{"name": "bob", "email": "secret@x"}}}},
+	}}
+	db := NewDatabase(conn, "test_db", WithDebug(&buf, RedactAttributes("email")))
+	mgr := MustNewManager[testPerson](db)
+	ctx := context.Background()
+
+	if err := mgr.Insert(ctx, &testPerson{Name: "bob", Email: "secret@x"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if _, err := mgr.All(ctx); err != nil {
+		t.Fatalf("All: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "secret@x") {
+		t.Errorf("redacted value leaked into dump:\n%s", out)
+	}
+	assertContains(t, out, "has email [REDACTED]")
+	assertContains(t, out, `has name "bob"`)
+	assertContains(t, out, "{email: [REDACTED], name: bob}")
+	assertContains(t, out, "-- typedb write query #1")
+	assertContains(t, out, "-- typedb read query #2")
+}
+
+func TestWithDebug_ReportsErrors(t *testing.T) {
+	var buf bytes.Buffer
+	db := NewDatabase(&failingQueryConn{}, "test_db", WithDebug(&buf))
+
+	if _, err := db.ExecuteRead(context.Background(), "match $x isa person;"); err == nil {
+		t.Fatal("expected error")
+	}
+	assertContains(t, buf.String(), "error: boom\n   match\n     $x isa person;\n")
+}
+
+func TestWithDebug_Disabled(t *testing.T) {
+	db := NewDatabase(&mockConn{}, "test_db")
+	if db.debug != nil {
+		t.Error("debug dump should be off by default")
+	}
+}
@@ -0,0 +1,67 @@
+package gotype
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// parseFieldDefault converts the raw "default=" tag value into a value of the
+// field's type (the element type for pointer fields), using the same
+// conversions as hydration.
+func parseFieldDefault(fi *FieldInfo, raw string) (reflect.Value, error) {
+	if fi.IsSlice {
+		return reflect.Value{}, fmt.Errorf("default is not supported on multi-valued fields")
+	}
+
+	var val any = raw
+	if !fi.scanner {
+		var err error
+		switch fi.ValueType {
+		case "long", "integer":
+			val, err = strconv.ParseInt(raw, 10, 64)
+		case "double":
+			val, err = strconv.ParseFloat(raw, 64)
+		case "boolean":
+			val, err = strconv.ParseBool(raw)
+		}
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid default %q for %s: %w", raw, fi.ValueType, err)
+		}
+	}
+
+	target := reflect.New(fi.FieldType).Elem()
+	if err := setFieldValue(target, fi, val); err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid default %q: %w", raw, err)
+	}
+	if fi.IsPointer {
+		return target.Elem(), nil
+	}
+	return target, nil
+}
+
+// applyDefaults fills zero-valued fields of instance that declare a default.
+// Pointer fields receive a freshly allocated copy of the default.
+func applyDefaults(info *ModelInfo, instance any) {
+	if !info.hasDefaults {
+		return
+	}
+	v := reflectValue(instance)
+	for i := range info.Fields {
+		fi := &info.Fields[i]
+		if !fi.defaultValue.IsValid() {
+			continue
+		}
+		field := fi.fieldValue(v)
+		if !field.IsZero() {
+			continue
+		}
+		if fi.IsPointer {
+			ptr := reflect.New(fi.ElemType)
+			ptr.Elem().Set(fi.defaultValue)
+			field.Set(ptr)
+		} else {
+			field.Set(fi.defaultValue)
+		}
+	}
+}
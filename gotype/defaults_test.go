@@ -0,0 +1,97 @@
+package gotype
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testTicket struct {
+	BaseEntity
+	Title    string     `typedb:"title,key"`
+	Status   string     `typedb:"status,default=proposed"`
+	Priority *int       `typedb:"priority,default=3"`
+	Score    float64    `typedb:"score,default=0.5"`
+	Open     *bool      `typedb:"open,default=true"`
+	Due      *time.Time `typedb:"due,default=2026-01-02T00:00:00Z"`
+}
+
+type testBadDefault struct {
+	BaseEntity
+	Title    string `typedb:"title,key"`
+	Priority int    `typedb:"priority,default=high"`
+}
+
+type testSliceDefault struct {
+	BaseEntity
+	Title string   `typedb:"title,key"`
+	Tags  []string `typedb:"tag,default=x"`
+}
+
+func TestDefaults_Insert(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testTicket]()
+
+	writeTx := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1"}}}}
+	mgr := MustNewManager[testTicket](NewDatabase(&mockConn{txs: []*mockTx{writeTx}}, "test_db"))
+
+	tk := &testTicket{Title: "t"}
+	if err := mgr.Insert(context.Background(), tk); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if tk.Status != "proposed" || tk.Priority == nil || *tk.Priority != 3 || tk.Score != 0.5 {
+		t.Errorf("defaults not applied: %+v", tk)
+	}
+	if tk.Open == nil || !*tk.Open {
+		t.Errorf("Open = %v, want true", tk.Open)
+	}
+	if tk.Due == nil || !tk.Due.Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Due = %v", tk.Due)
+	}
+	q := writeTx.queries[0]
+	assertContains(t, q, `has status "proposed"`)
+	assertContains(t, q, "has priority 3")
+	assertContains(t, q, "has open true")
+}
+
+func TestDefaults_KeepsSetValues(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testTicket]()
+
+	writeTx := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1"}}}}
+	mgr := MustNewManager[testTicket](NewDatabase(&mockConn{txs: []*mockTx{writeTx}}, "test_db"))
+
+	prio := 0
+	tk := &testTicket{Title: "t", Status: "done", Priority: &prio}
+	if err := mgr.Insert(context.Background(), tk); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if tk.Status != "done" || *tk.Priority != 0 {
+		t.Errorf("set values overwritten: %+v", tk)
+	}
+}
+
+func TestDefaults_PointersNotShared(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testTicket]()
+	info, _ := Lookup("test-ticket")
+
+	a, b := &testTicket{}, &testTicket{}
+	applyDefaults(info, a)
+	applyDefaults(info, b)
+	*a.Priority = 9
+	if *b.Priority != 3 {
+		t.Errorf("default pointer shared between instances")
+	}
+}
+
+func TestDefaults_RegistrationErrors(t *testing.T) {
+	ClearRegistry()
+	if err := Register[testBadDefault](); err == nil || !strings.Contains(err.Error(), `invalid default "high"`) {
+		t.Errorf("expected invalid default error, got %v", err)
+	}
+	if err := Register[testSliceDefault](); err == nil || !strings.Contains(err.Error(), "multi-valued") {
+		t.Errorf("expected multi-valued error, got %v", err)
+	}
+}
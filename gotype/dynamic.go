@@ -0,0 +1,291 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DynamicManager provides CRUD operations on rows of map[string]any for a
+// TypeDB type identified by its label, without a Go struct. It is intended for
+// generic tooling (admin UIs, data browsers) that must handle any type.
+//
+// Row keys are attribute type names; slice values stand for multiple
+// attributes of the same type. Keys starting with "_" are synthetic: rows
+// returned by Get carry "_iid" and are ignored on Insert and Update.
+type DynamicManager struct {
+	db       *Database
+	typeName string
+}
+
+// NewDynamicManager creates a DynamicManager for the type label typeName.
+// The type does not need to be registered; if it is in the database's
+// registry, registration is only used to reject operations the type cannot
+// support.
+func NewDynamicManager(db *Database, typeName string) (*DynamicManager, error) {
+	if err := ValidateIdentifier(typeName, "type"); err != nil {
+		return nil, err
+	}
+	return &DynamicManager{db: db, typeName: typeName}, nil
+}
+
+// TypeName returns the TypeDB type label managed by m.
+func (m *DynamicManager) TypeName() string {
+	return m.typeName
+}
+
+// Insert inserts a new instance with the attributes in row and returns its IID.
+// Relations cannot be inserted dynamically because role players are not
+// expressible as attribute rows.
+func (m *DynamicManager) Insert(ctx context.Context, row map[string]any) (string, error) {
+	if err := checkCtx(ctx, "insert", m.typeName); err != nil {
+		return "", err
+	}
+	if info, ok := m.db.Registry().Lookup(m.typeName); ok && info.Kind == ModelKindRelation {
+		return "", fmt.Errorf("insert %s: dynamic insert of relations is not supported", m.typeName)
+	}
+	query, err := m.insertQuery(row)
+	if err != nil {
+		return "", fmt.Errorf("insert %s: %w", m.typeName, err)
+	}
+
+	results, err := m.db.ExecuteWrite(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("insert %s: %w", m.typeName, err)
+	}
+	if len(results) == 1 {
+		return extractIID(results[0]), nil
+	}
+	return "", nil
+}
+
+// InsertMany inserts rows in a single write transaction: either all of them
+// are inserted or none is. Errors name the index of the failing row.
+func (m *DynamicManager) InsertMany(ctx context.Context, rows []map[string]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := checkCtx(ctx, "insert_many", m.typeName); err != nil {
+		return err
+	}
+	if info, ok := m.db.Registry().Lookup(m.typeName); ok && info.Kind == ModelKindRelation {
+		return fmt.Errorf("insert_many %s: dynamic insert of relations is not supported", m.typeName)
+	}
+	queries := make([]string, len(rows))
+	for i, row := range rows {
+		query, err := m.insertQuery(row)
+		if err != nil {
+			return fmt.Errorf("insert_many %s[%d]: %w", m.typeName, i, err)
+		}
+		queries[i] = query
+	}
+
+	tx, err := m.db.TransactionContext(ctx, WriteTransaction)
+	if err != nil {
+		return fmt.Errorf("insert_many %s: %w", m.typeName, err)
+	}
+	defer tx.Close()
+	for i, query := range queries {
+		if _, err := tx.QueryWithContext(ctx, query); err != nil {
+			return fmt.Errorf("insert_many %s[%d]: %w", m.typeName, i, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("insert_many %s: commit: %w", m.typeName, err)
+	}
+	return nil
+}
+
+// insertQuery renders the insert query of row, fetching the new IID.
+func (m *DynamicManager) insertQuery(row map[string]any) (string, error) {
+	has, err := dynamicHasClauses(row)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "insert\n$e isa %s", m.typeName)
+	for _, h := range has {
+		b.WriteString(", ")
+		b.WriteString(h)
+	}
+	b.WriteString(";\nfetch {\n  \"_iid\": iid($e)\n};")
+	return b.String(), nil
+}
+
+// Get returns all instances matching the attribute filters as rows. Each row
+// holds every attribute of the instance plus "_iid".
+func (m *DynamicManager) Get(ctx context.Context, filters map[string]any) ([]map[string]any, error) {
+	match, err := m.filterMatch(filters)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", m.typeName, err)
+	}
+	return m.fetchRows(ctx, "get", match)
+}
+
+// DynamicLink is one role an instance plays in a relation, as returned by
+// DynamicManager.Links.
+type DynamicLink struct {
+	// IID is the IID of the instance playing the role.
+	IID string `json:"iid"`
+	// RelationIID is the IID of the relation.
+	RelationIID string `json:"relation_iid"`
+	// RelationType is the exact type label of the relation.
+	RelationType string `json:"relation_type"`
+	// Role is the scoped role label, e.g. "employment:employee".
+	Role string `json:"role"`
+}
+
+// Links returns the relations that instances matching the attribute filters
+// play a role in, one DynamicLink per instance, relation and role.
+func (m *DynamicManager) Links(ctx context.Context, filters map[string]any) ([]DynamicLink, error) {
+	match, err := m.filterMatch(filters)
+	if err != nil {
+		return nil, fmt.Errorf("links %s: %w", m.typeName, err)
+	}
+	query := match + "\n$r isa! $rt, links ($role: $e);\nfetch {\n" +
+		"  \"_iid\": iid($e),\n  \"relation\": iid($r),\n  \"type\": label($rt),\n  \"role\": label($role)\n};"
+	results, err := m.db.ExecuteRead(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("links %s: %w", m.typeName, err)
+	}
+	links := make([]DynamicLink, 0, len(results))
+	for _, result := range results {
+		link := DynamicLink{IID: extractIID(result)}
+		link.RelationIID, _ = unwrapValue(result["relation"]).(string)
+		link.RelationType, _ = unwrapValue(result["type"]).(string)
+		link.Role, _ = unwrapValue(result["role"]).(string)
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// filterMatch renders the match stage binding $e to the instances having
+// every attribute value in filters.
+func (m *DynamicManager) filterMatch(filters map[string]any) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "match\n$e isa %s", m.typeName)
+	for _, name := range sortedRowKeys(filters) {
+		if err := ValidateIdentifier(name, "attribute"); err != nil {
+			return "", err
+		}
+		has, err := hasClause(name, filters[name])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(",\n")
+		b.WriteString(has)
+	}
+	b.WriteString(";")
+	return b.String(), nil
+}
+
+// GetByIID returns the instance with the given IID, or nil if none exists.
+func (m *DynamicManager) GetByIID(ctx context.Context, iid string) (map[string]any, error) {
+	if !IsIID(iid) {
+		return nil, fmt.Errorf("get_by_iid %s: invalid IID %q", m.typeName, iid)
+	}
+	rows, err := m.fetchRows(ctx, "get_by_iid", fmt.Sprintf("match\n$e isa %s, iid %s;", m.typeName, iid))
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// Update replaces the values of every attribute type present in row on the
+// instance identified by iid. Attribute types absent from row are untouched;
+// a nil value deletes all attributes of that type.
+func (m *DynamicManager) Update(ctx context.Context, iid string, row map[string]any) error {
+	if !IsIID(iid) {
+		return fmt.Errorf("update %s: invalid IID %q", m.typeName, iid)
+	}
+	if err := checkCtx(ctx, "update", m.typeName); err != nil {
+		return err
+	}
+
+	delAttrs := sortedRowKeys(row)
+	if len(delAttrs) == 0 {
+		return nil
+	}
+	insHas, err := dynamicHasClauses(row)
+	if err != nil {
+		return fmt.Errorf("update %s: %w", m.typeName, err)
+	}
+
+	query := buildBatchUpdate(m.typeName, iid, delAttrs, insHas)
+	if _, err := m.db.ExecuteWrite(ctx, query); err != nil {
+		return fmt.Errorf("update %s: %w", m.typeName, err)
+	}
+	return nil
+}
+
+// Delete deletes the instance identified by iid.
+func (m *DynamicManager) Delete(ctx context.Context, iid string) error {
+	if !IsIID(iid) {
+		return fmt.Errorf("delete %s: invalid IID %q", m.typeName, iid)
+	}
+	if err := checkCtx(ctx, "delete", m.typeName); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("match\n$e isa %s, iid %s;\ndelete $e;", m.typeName, iid)
+	if _, err := m.db.ExecuteWrite(ctx, query); err != nil {
+		return fmt.Errorf("delete %s: %w", m.typeName, err)
+	}
+	return nil
+}
+
+// fetchRows runs match with an all-attributes fetch and flattens the results.
+func (m *DynamicManager) fetchRows(ctx context.Context, op, match string) ([]map[string]any, error) {
+	query := match + "\nfetch {\n  \"_iid\": iid($e),\n  \"" + ExtraAttributesKey + "\": { $e.* }\n};"
+	results, err := m.db.ExecuteRead(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", op, m.typeName, err)
+	}
+
+	rows := make([]map[string]any, 0, len(results))
+	for _, result := range results {
+		row := make(map[string]any)
+		if attrs, ok := result[ExtraAttributesKey].(map[string]any); ok {
+			for name, val := range attrs {
+				row[name] = unwrapExtraValue(val)
+			}
+		}
+		if iid := extractIID(result); iid != "" {
+			row["_iid"] = iid
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// dynamicHasClauses renders "has name value" clauses for row in name order.
+func dynamicHasClauses(row map[string]any) ([]string, error) {
+	var has []string
+	for _, name := range sortedRowKeys(row) {
+		if err := ValidateIdentifier(name, "attribute"); err != nil {
+			return nil, err
+		}
+		err := visitExtraValue(name, row[name], func(name string, val any) error {
+			clause, err := hasClause(name, val)
+			has = append(has, clause)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return has, nil
+}
+
+// sortedRowKeys returns the non-synthetic keys of row in sorted order.
+func sortedRowKeys(row map[string]any) []string {
+	names := make([]string, 0, len(row))
+	for name := range row {
+		if strings.HasPrefix(name, "_") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,168 @@
+package gotype
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDynamicManager_Insert(t *testing.T) {
+	ClearRegistry()
+	tx := &mockTx{responses: [][]map[string]any{{{"_iid": "0xD1"}}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db")
+	mgr, err := NewDynamicManager(db, "gadget")
+	if err != nil {
+		t.Fatalf("NewDynamicManager failed: %v", err)
+	}
+
+	iid, err := mgr.Insert(context.Background(), map[string]any{
+		"_iid":  "ignored",
+		"name":  "probe",
+		"tag":   []string{"a", "b"},
+		"count": 3,
+	})
+	if err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if iid != "0xD1" {
+		t.Errorf("iid = %q, want 0xD1", iid)
+	}
+	want := "insert\n$e isa gadget, has count 3, has name \"probe\", has tag \"a\", has tag \"b\";\n" +
+		"fetch {\n  \"_iid\": iid($e)\n};"
+	if tx.queries[0] != want {
+		t.Errorf("query =\n%s\nwant\n%s", tx.queries[0], want)
+	}
+	if !tx.committed {
+		t.Error("transaction was not committed")
+	}
+}
+
+func TestDynamicManager_InsertMany(t *testing.T) {
+	ClearRegistry()
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db")
+	mgr, _ := NewDynamicManager(db, "gadget")
+
+	err := mgr.InsertMany(context.Background(), []map[string]any{{"name": "a"}, {"name": "b"}})
+	if err != nil {
+		t.Fatalf("InsertMany failed: %v", err)
+	}
+	if len(tx.queries) != 2 || !strings.Contains(tx.queries[1], `$e isa gadget, has name "b";`) {
+		t.Errorf("queries = %q", tx.queries)
+	}
+	if !tx.committed {
+		t.Error("transaction was not committed")
+	}
+
+	err = mgr.InsertMany(context.Background(), []map[string]any{{"name": "a"}, {"bad name": 1}})
+	if err == nil || !strings.Contains(err.Error(), "insert_many gadget[1]") {
+		t.Errorf("err = %v, want the failing row index", err)
+	}
+}
+
+func TestDynamicManager_Get(t *testing.T) {
+	ClearRegistry()
+	tx := &mockTx{responses: [][]map[string]any{{
+		{
+			"_iid": "0xD1",
+			"_attributes": map[string]any{
+				"name": map[string]any{"value": "probe"},
+				"tag":  []any{"a", "b"},
+			},
+		},
+	}}}
+	mgr, _ := NewDynamicManager(NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db"), "gadget")
+
+	rows, err := mgr.Get(context.Background(), map[string]any{"name": "probe"})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	assertContains(t, tx.queries[0], "match\n$e isa gadget,\nhas name \"probe\";")
+	assertContains(t, tx.queries[0], `"_attributes": { $e.* }`)
+
+	want := []map[string]any{{"_iid": "0xD1", "name": "probe", "tag": []any{"a", "b"}}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %#v, want %#v", rows, want)
+	}
+}
+
+func TestDynamicManager_Links(t *testing.T) {
+	ClearRegistry()
+	tx := &mockTx{responses: [][]map[string]any{{
+		{"_iid": "0xD1", "relation": "0xR1", "type": "ownership", "role": "ownership:owner"},
+	}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db")
+	mgr, _ := NewDynamicManager(db, "gadget")
+
+	links, err := mgr.Links(context.Background(), map[string]any{"name": "probe"})
+	if err != nil {
+		t.Fatalf("Links failed: %v", err)
+	}
+	want := []DynamicLink{{IID: "0xD1", RelationIID: "0xR1", RelationType: "ownership", Role: "ownership:owner"}}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("links = %+v, want %+v", links, want)
+	}
+	assertContains(t, tx.queries[0], "match\n$e isa gadget,\nhas name \"probe\";\n$r isa! $rt, links ($role: $e);")
+	assertContains(t, tx.queries[0], `"role": label($role)`)
+}
+
+func TestDynamicManager_UpdateAndDelete(t *testing.T) {
+	ClearRegistry()
+	updateTx := &mockTx{}
+	deleteTx := &mockTx{}
+	mgr, _ := NewDynamicManager(NewDatabase(&mockConn{txs: []*mockTx{updateTx, deleteTx}}, "test_db"), "gadget")
+	ctx := context.Background()
+
+	if err := mgr.Update(ctx, "0xD1", map[string]any{"name": "renamed", "tag": nil}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	q := updateTx.queries[0]
+	assertContains(t, q, "try { $e has name $old0; };")
+	assertContains(t, q, "try { $e has tag $old1; };")
+	assertContains(t, q, `insert $e has name "renamed";`)
+
+	if err := mgr.Delete(ctx, "0xD1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if deleteTx.queries[0] != "match\n$e isa gadget, iid 0xD1;\ndelete $e;" {
+		t.Errorf("delete query = %q", deleteTx.queries[0])
+	}
+}
+
+func TestDynamicManager_Validation(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testPerson]()
+	MustRegister[testCompany]()
+	MustRegister[testEmployment]()
+	db := NewDatabase(&mockConn{}, "test_db")
+	ctx := context.Background()
+
+	if _, err := NewDynamicManager(db, "bad type"); err == nil {
+		t.Error("expected invalid type label error")
+	}
+
+	rel, _ := NewDynamicManager(db, "test-employment")
+	if _, err := rel.Insert(ctx, nil); err == nil || !strings.Contains(err.Error(), "relations is not supported") {
+		t.Errorf("expected relation insert error, got %v", err)
+	}
+
+	ent, _ := NewDynamicManager(db, "gadget")
+	if _, err := ent.Insert(ctx, map[string]any{"bad name": 1}); err == nil {
+		t.Error("expected invalid attribute name error")
+	}
+	if err := ent.Update(ctx, "", map[string]any{"name": "x"}); err == nil {
+		t.Error("expected empty iid error")
+	}
+	for _, iid := range []string{"0x1; delete $e", "1e00", "0x"} {
+		if _, err := ent.GetByIID(ctx, iid); err == nil || !strings.Contains(err.Error(), "invalid IID") {
+			t.Errorf("GetByIID(%q): expected invalid IID error, got %v", iid, err)
+		}
+		if err := ent.Update(ctx, iid, map[string]any{"name": "x"}); err == nil || !strings.Contains(err.Error(), "invalid IID") {
+			t.Errorf("Update(%q): expected invalid IID error, got %v", iid, err)
+		}
+		if err := ent.Delete(ctx, iid); err == nil || !strings.Contains(err.Error(), "invalid IID") {
+			t.Errorf("Delete(%q): expected invalid IID error, got %v", iid, err)
+		}
+	}
+}
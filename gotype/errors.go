@@ -1,7 +1,15 @@
 // Package gotype defines various error types for ORM operations and schema management.
 package gotype
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTooManyRows is returned when a read returns more rows than the limit set
+// with Database.WithMaxRows or Query.MaxRows. Nothing is hydrated in that case.
+var ErrTooManyRows = errors.New("too many rows")
 
 // NotRegisteredError is returned when an operation is attempted on a Go type
 // that has not been registered with the ORM.
@@ -46,6 +54,75 @@ func (e *HydrationError) Unwrap() error {
 	return e.Cause
 }
 
+// FieldError describes a problem with one field of a model instance, so that
+// APIs can map it back to the request field it came from.
+type FieldError struct {
+	Type   string `json:"type"`            // TypeDB type of the instance holding the field
+	Field  string `json:"field,omitempty"` // Go field path, e.g. "Employee.Age" through roles
+	Attr   string `json:"attr,omitempty"`  // TypeDB attribute name
+	Reason string `json:"reason"`
+	Value  any    `json:"value,omitempty"` // offending value, if any
+}
+
+// Error returns the error message for FieldError.
+func (e *FieldError) Error() string {
+	name := e.Field
+	if name == "" {
+		name = e.Attr
+	}
+	return fmt.Sprintf("field %s: %s", name, e.Reason)
+}
+
+// FieldErrors lists every field that failed hydration. It is returned,
+// possibly wrapped, by Hydrate, HydrateNew and the Manager read methods.
+type FieldErrors []FieldError
+
+// Error returns the error message for FieldErrors.
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// FieldErrorsOf returns the field errors carried by err: a FieldErrors or
+// the Errors of a StrictHydrationError, anywhere in its chain. It returns nil
+// if err has none.
+func FieldErrorsOf(err error) FieldErrors {
+	var fieldErrs FieldErrors
+	if errors.As(err, &fieldErrs) {
+		return fieldErrs
+	}
+	var strictErr *StrictHydrationError
+	if errors.As(err, &strictErr) {
+		return strictErr.Errors
+	}
+	return nil
+}
+
+// StrictHydrationError is returned by strict hydration (WithStrictHydration)
+// when a result row does not match the model.
+type StrictHydrationError struct {
+	TypeName   string
+	Unexpected []string // result keys not mapped to any field or role
+	Missing    []string // required fields absent from the row
+	// Errors holds one FieldError per unexpected key and missing field.
+	Errors FieldErrors
+}
+
+// Error returns the error message for StrictHydrationError.
+func (e *StrictHydrationError) Error() string {
+	var parts []string
+	if len(e.Unexpected) > 0 {
+		parts = append(parts, "unexpected keys "+strings.Join(e.Unexpected, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing required fields "+strings.Join(e.Missing, ", "))
+	}
+	return fmt.Sprintf("strict hydration of %s: %s", e.TypeName, strings.Join(parts, "; "))
+}
+
 // ReservedWordError is returned when a TypeQL reserved keyword is used
 // as a name for a type, attribute, or role.
 type ReservedWordError struct {
@@ -122,3 +199,36 @@ type NotUniqueError struct {
 func (e *NotUniqueError) Error() string {
 	return fmt.Sprintf("%s: expected unique, got %d", e.TypeName, e.Count)
 }
+
+// RoleCardinalityError is returned when a relation write would give a role
+// fewer or more players than its cardinality allows. It is detected before
+// the query is sent.
+type RoleCardinalityError struct {
+	Relation string
+	Role     string
+	Count    int
+	Min      int
+	Max      int // -1 when unbounded
+}
+
+// Error returns the error message for RoleCardinalityError.
+func (e *RoleCardinalityError) Error() string {
+	want := fmt.Sprintf("%d..", e.Min)
+	if e.Max >= 0 {
+		want += fmt.Sprint(e.Max)
+	}
+	return fmt.Sprintf("%s: role %q has %d player(s), want %s", e.Relation, e.Role, e.Count, want)
+}
+
+// TransactionTypeError is returned when an operation needs a kind of
+// transaction it was not given, such as a write through a manager bound to a
+// read transaction. It is detected before the query is sent.
+type TransactionTypeError struct {
+	Have TransactionType
+	Need TransactionType
+}
+
+// Error returns the error message for TransactionTypeError.
+func (e *TransactionTypeError) Error() string {
+	return fmt.Sprintf("needs a %s transaction, got a %s transaction", txTypeName(e.Need), txTypeName(e.Have))
+}
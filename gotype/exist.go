@@ -0,0 +1,68 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// whichExistBatch is the number of keys WhichExist checks per query.
+const whichExistBatch = 500
+
+// WhichExist reports which of keys, values of the single key attribute of T,
+// belong to a stored instance. Every key is present in the result. Keys are
+// checked in batches of a few hundred per query, so importers can split
+// large batches into inserts and updates cheaply. K must be the key field's
+// type or convertible from it.
+//
+// It is a function rather than a Manager method because methods cannot
+// declare type parameters.
+func WhichExist[T any, K comparable](ctx context.Context, m *Manager[T], keys []K) (map[K]bool, error) {
+	if err := checkCtx(ctx, "which_exist", m.info.TypeName); err != nil {
+		return nil, err
+	}
+	if len(m.info.KeyFields) != 1 {
+		return nil, fmt.Errorf("which_exist %s: model must have exactly one key attribute, has %d", m.info.TypeName, len(m.info.KeyFields))
+	}
+	key := m.info.KeyFields[0]
+	keyType := reflect.TypeFor[K]()
+	if !key.FieldType.ConvertibleTo(keyType) {
+		return nil, fmt.Errorf("which_exist %s: key %s of type %s does not convert to %s", m.info.TypeName, key.Tag.Name, key.FieldType, keyType)
+	}
+
+	out := make(map[K]bool, len(keys))
+	var pending []K
+	for _, k := range keys {
+		if _, ok := out[k]; !ok {
+			out[k] = false
+			pending = append(pending, k)
+		}
+	}
+	fetch := strings.Join(appendFetchProjectionItems(nil, []FieldInfo{key}, "e"), ", ")
+	for start := 0; start < len(pending); start += whichExistBatch {
+		batch := pending[start:min(start+whichExistBatch, len(pending))]
+		values := make([]any, len(batch))
+		for i, k := range batch {
+			values[i] = k
+		}
+		query := fmt.Sprintf("match\n$e isa %s;\n%s\nfetch {\n  %s\n};",
+			m.info.TypeName, strings.Join(In(key.Tag.Name, values).ToPatterns("e"), "\n"), fetch)
+		results, err := m.readQuery(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("which_exist %s: %w", m.info.TypeName, err)
+		}
+		for _, row := range results {
+			inst, err := hydrateNewWithInfo[T](m.info, row)
+			if err != nil {
+				m.db.stats.recordHydrationError()
+				return nil, fmt.Errorf("which_exist %s: %w", m.info.TypeName, err)
+			}
+			k, _ := reflect.TypeAssert[K](key.fieldValue(reflect.ValueOf(inst).Elem()).Convert(keyType))
+			if _, ok := out[k]; ok {
+				out[k] = true
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,32 @@
+package gotype
+
+import (
+	"context"
+	"maps"
+	"testing"
+)
+
+func TestWhichExist(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	read := &mockTx{responses: [][]map[string]any{{{"name": "Alice"}}}}
+	persons := MustNewManager[testPerson](NewDatabase(&mockConn{txs: []*mockTx{read}}, "test_db"))
+
+	got, err := WhichExist(ctx, persons, []string{"Alice", "Bob", "Alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got, map[string]bool{"Alice": true, "Bob": false}) {
+		t.Errorf("got %v", got)
+	}
+	assertContains(t, read.queries[0], `{ $e__name == "Alice"; } or { $e__name == "Bob"; };`)
+	assertContains(t, read.queries[0], `"name": $e.name`)
+
+	if _, err := WhichExist(ctx, persons, []int{1}); err == nil {
+		t.Error("expected error for a key type that does not convert")
+	}
+	employments := MustNewManager[testEmployment](NewDatabase(&mockConn{}, "test_db"))
+	if _, err := WhichExist(ctx, employments, []string{"x"}); err == nil {
+		t.Error("expected error for a model without a key")
+	}
+}
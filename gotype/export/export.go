@@ -0,0 +1,419 @@
+// Package export converts gotype models to Apache Arrow record batches and
+// Parquet files, for handing TypeDB data to DuckDB, Spark and other columnar
+// analytics tools.
+//
+// The Arrow schema is derived from the model's ModelInfo: an "_iid" column,
+// one column per attribute and, for relations, one column per role holding the
+// player's IID. Multi-valued attributes become list columns. Value types map
+// as follows:
+//
+//	string, decimal, duration  utf8
+//	boolean                    bool
+//	integer, long              int64
+//	double                     float64
+//	date                       date32
+//	datetime                   timestamp[ns]
+//	datetime-tz                timestamp[ns, tz=UTC]
+//
+// Decimals and durations are exported as their TypeQL text, since neither
+// fits an Arrow type without loss. For example:
+//
+//	f, _ := os.Create("people.parquet")
+//	defer f.Close()
+//	rows, err := export.WriteParquet(ctx, f, personMgr.Query().Filter(gotype.Gt("age", 18)))
+//
+// The package is a module of its own, github.com/CaliLuke/go-typeql/gotype/export,
+// so that programs not exporting data do not pull in Arrow.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// DefaultBatchSize is the number of rows per record batch.
+const DefaultBatchSize = 1024
+
+// Option configures an export.
+type Option func(*config)
+
+type config struct {
+	batchSize int
+	alloc     memory.Allocator
+	props     *parquet.WriterProperties
+}
+
+// WithBatchSize sets the number of rows per record batch, and per Parquet
+// row group (default DefaultBatchSize).
+func WithBatchSize(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
+// WithAllocator sets the allocator of the record batches (default
+// memory.DefaultAllocator).
+func WithAllocator(alloc memory.Allocator) Option {
+	return func(c *config) { c.alloc = alloc }
+}
+
+// WithParquetProperties replaces the Parquet writer properties (default
+// Snappy compression).
+func WithParquetProperties(props *parquet.WriterProperties) Option {
+	return func(c *config) { c.props = props }
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{batchSize: DefaultBatchSize, alloc: memory.DefaultAllocator}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.props == nil {
+		c.props = parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	}
+	return c
+}
+
+// Schema returns the Arrow schema of info's instances.
+func Schema(info *gotype.ModelInfo) *arrow.Schema {
+	fields := []arrow.Field{{Name: "_iid", Type: arrow.BinaryTypes.String}}
+	for _, fi := range info.Fields {
+		typ := arrowType(fi.ValueType)
+		if fi.IsSlice {
+			typ = arrow.ListOf(typ)
+		}
+		fields = append(fields, arrow.Field{Name: fi.Tag.Name, Type: typ, Nullable: !fi.Tag.Key})
+	}
+	for _, role := range info.Roles {
+		fields = append(fields, arrow.Field{Name: role.RoleName, Type: arrow.BinaryTypes.String, Nullable: true})
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+func arrowType(valueType string) arrow.DataType {
+	switch valueType {
+	case "boolean":
+		return arrow.FixedWidthTypes.Boolean
+	case "integer", "long":
+		return arrow.PrimitiveTypes.Int64
+	case "double":
+		return arrow.PrimitiveTypes.Float64
+	case "date":
+		return arrow.FixedWidthTypes.Date32
+	case "datetime":
+		return &arrow.TimestampType{Unit: arrow.Nanosecond}
+	case "datetime-tz":
+		return arrow.FixedWidthTypes.Timestamp_ns
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+// Records yields the instances matching q as record batches of Schema(q.Info()).
+// The query's ordering and pagination apply. Each batch is released when the
+// loop moves on; call Retain to keep one. Iteration stops at the first error.
+func Records[T any](ctx context.Context, q *gotype.Query[T], opts ...Option) iter.Seq2[arrow.RecordBatch, error] {
+	mgr := q.Manager()
+	rows := func(yield func(map[string]any, error) bool) {
+		for inst, err := range q.Iter(ctx) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			row, err := mgr.ToDict(inst)
+			if !yield(row, err) || err != nil {
+				return
+			}
+		}
+	}
+	return records(ctx, mgr.Database(), q.Info(), rows, newConfig(opts))
+}
+
+// TypeRecords yields every instance of the registered type typeName, and of
+// its subtypes, as record batches of its Schema. It needs no Go model type,
+// but reads all instances before yielding the first batch.
+func TypeRecords(ctx context.Context, db *gotype.Database, typeName string, opts ...Option) iter.Seq2[arrow.RecordBatch, error] {
+	return func(yield func(arrow.RecordBatch, error) bool) {
+		info, ok := db.Registry().Lookup(typeName)
+		if !ok {
+			yield(nil, fmt.Errorf("export: type %s is not registered", typeName))
+			return
+		}
+		mgr, err := gotype.NewDynamicManager(db, typeName)
+		if err != nil {
+			yield(nil, fmt.Errorf("export: %w", err))
+			return
+		}
+		all, err := mgr.Get(ctx, nil)
+		if err != nil {
+			yield(nil, fmt.Errorf("export %s: %w", typeName, err))
+			return
+		}
+		rows := func(yield func(map[string]any, error) bool) {
+			for _, row := range all {
+				if !yield(row, nil) {
+					return
+				}
+			}
+		}
+		records(ctx, db, info, rows, newConfig(opts))(yield)
+	}
+}
+
+// WriteParquet writes the instances matching q to w as a Parquet file and
+// returns the number of rows written.
+func WriteParquet[T any](ctx context.Context, w io.Writer, q *gotype.Query[T], opts ...Option) (int64, error) {
+	c := newConfig(opts)
+	return writeParquet(w, Schema(q.Info()), Records(ctx, q, opts...), c)
+}
+
+// WriteTypeParquet writes every instance of typeName to w as a Parquet file,
+// like TypeRecords, and returns the number of rows written.
+func WriteTypeParquet(ctx context.Context, w io.Writer, db *gotype.Database, typeName string, opts ...Option) (int64, error) {
+	info, ok := db.Registry().Lookup(typeName)
+	if !ok {
+		return 0, fmt.Errorf("export: type %s is not registered", typeName)
+	}
+	c := newConfig(opts)
+	return writeParquet(w, Schema(info), TypeRecords(ctx, db, typeName, opts...), c)
+}
+
+func writeParquet(w io.Writer, schema *arrow.Schema, batches iter.Seq2[arrow.RecordBatch, error], c *config) (int64, error) {
+	fw, err := pqarrow.NewFileWriter(schema, w, c.props, pqarrow.NewArrowWriterProperties(
+		pqarrow.WithAllocator(c.alloc), pqarrow.WithStoreSchema()))
+	if err != nil {
+		return 0, fmt.Errorf("export: %w", err)
+	}
+	var n int64
+	for rec, err := range batches {
+		if err != nil {
+			fw.Close()
+			return n, err
+		}
+		if err := fw.Write(rec); err != nil {
+			fw.Close()
+			return n, fmt.Errorf("export: %w", err)
+		}
+		n += rec.NumRows()
+	}
+	if err := fw.Close(); err != nil {
+		return n, fmt.Errorf("export: %w", err)
+	}
+	return n, nil
+}
+
+// records builds batches of c.batchSize rows, looking up the role players of
+// each batch of relations.
+func records(ctx context.Context, db *gotype.Database, info *gotype.ModelInfo, rows iter.Seq2[map[string]any, error], c *config) iter.Seq2[arrow.RecordBatch, error] {
+	return func(yield func(arrow.RecordBatch, error) bool) {
+		b := array.NewRecordBuilder(c.alloc, Schema(info))
+		defer b.Release()
+		batch := make([]map[string]any, 0, c.batchSize)
+		flush := func() bool {
+			rec, err := buildBatch(ctx, db, info, b, batch)
+			batch = batch[:0]
+			if err != nil {
+				yield(nil, err)
+				return false
+			}
+			defer rec.Release()
+			return yield(rec, nil)
+		}
+		for row, err := range rows {
+			if err != nil {
+				yield(nil, fmt.Errorf("export %s: %w", info.TypeName, err))
+				return
+			}
+			if batch = append(batch, row); len(batch) == c.batchSize && !flush() {
+				return
+			}
+		}
+		if len(batch) > 0 {
+			flush()
+		}
+	}
+}
+
+func buildBatch(ctx context.Context, db *gotype.Database, info *gotype.ModelInfo, b *array.RecordBuilder, batch []map[string]any) (arrow.RecordBatch, error) {
+	var players map[string]map[string]string
+	if len(info.Roles) > 0 {
+		var err error
+		if players, err = rolePlayers(ctx, db, info, batch); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range batch {
+		iid, _ := row["_iid"].(string)
+		b.Field(0).(*array.StringBuilder).Append(iid)
+		for i, fi := range info.Fields {
+			if err := appendValue(b.Field(i+1), fi.ValueType, row[fi.Tag.Name]); err != nil {
+				b.NewRecordBatch().Release()
+				return nil, fmt.Errorf("export %s %s: attribute %s: %w", info.TypeName, iid, fi.Tag.Name, err)
+			}
+		}
+		for i, role := range info.Roles {
+			field := b.Field(1 + len(info.Fields) + i).(*array.StringBuilder)
+			if player, ok := players[iid][role.RoleName]; ok {
+				field.Append(player)
+			} else {
+				field.AppendNull()
+			}
+		}
+	}
+	return b.NewRecordBatch(), nil
+}
+
+// rolePlayers returns the player IID of each role of the relations in batch,
+// keyed by relation IID and role name. A role with several players reports
+// the lowest IID.
+func rolePlayers(ctx context.Context, db *gotype.Database, info *gotype.ModelInfo, batch []map[string]any) (map[string]map[string]string, error) {
+	iids := make([]string, 0, len(batch))
+	for _, row := range batch {
+		if iid, _ := row["_iid"].(string); iid != "" {
+			iids = append(iids, iid)
+		}
+	}
+	players := make(map[string]map[string]string, len(iids))
+	if len(iids) == 0 {
+		return players, nil
+	}
+	query := "match\n" + strings.Join(gotype.IIDIn(iids...).ToPatterns("r"), "\n") +
+		"\n$r links ($role: $p);\nfetch {\n  \"_iid\": iid($r),\n  \"player\": iid($p),\n  \"role\": label($role)\n};"
+	results, err := db.ExecuteRead(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("export %s: role players: %w", info.TypeName, err)
+	}
+	for _, result := range results {
+		rel, _ := unwrap(result["_iid"]).(string)
+		player, _ := unwrap(result["player"]).(string)
+		role, _ := unwrap(result["role"]).(string)
+		if _, name, ok := strings.Cut(role, ":"); ok {
+			role = name
+		}
+		if players[rel] == nil {
+			players[rel] = make(map[string]string)
+		}
+		if cur, ok := players[rel][role]; !ok || player < cur {
+			players[rel][role] = player
+		}
+	}
+	return players, nil
+}
+
+// appendValue appends v, a ToDict or fetch value of the given value type, to
+// b. Multi-valued attributes are appended to list builders element by
+// element.
+func appendValue(b array.Builder, valueType string, v any) error {
+	if v == nil {
+		b.AppendNull()
+		return nil
+	}
+	lb, ok := b.(*array.ListBuilder)
+	if !ok {
+		return appendScalar(b, valueType, v)
+	}
+	lb.Append(true)
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return appendScalar(lb.ValueBuilder(), valueType, v)
+	}
+	for i := range rv.Len() {
+		if err := appendScalar(lb.ValueBuilder(), valueType, rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendScalar(b array.Builder, valueType string, v any) error {
+	v = unwrap(v)
+	if s, ok := v.(string); ok && !slices.Contains([]string{"", "string", "decimal", "duration"}, valueType) {
+		parsed, err := gotype.ParseAttributeValue(valueType, s)
+		if err != nil {
+			return err
+		}
+		v = parsed
+	}
+	rv := reflect.ValueOf(v)
+	switch b := b.(type) {
+	case *array.StringBuilder:
+		if s, ok := v.(string); ok {
+			b.Append(s)
+		} else {
+			b.Append(fmt.Sprint(v))
+		}
+	case *array.BooleanBuilder:
+		if rv.Kind() != reflect.Bool {
+			return fmt.Errorf("want boolean, got %T", v)
+		}
+		b.Append(rv.Bool())
+	case *array.Int64Builder:
+		switch {
+		case rv.CanInt():
+			b.Append(rv.Int())
+		case rv.CanUint():
+			b.Append(int64(rv.Uint()))
+		case rv.CanFloat() && rv.Float() == float64(int64(rv.Float())):
+			b.Append(int64(rv.Float()))
+		default:
+			return fmt.Errorf("want integer, got %T", v)
+		}
+	case *array.Float64Builder:
+		switch {
+		case rv.CanFloat():
+			b.Append(rv.Float())
+		case rv.CanInt():
+			b.Append(float64(rv.Int()))
+		case rv.CanUint():
+			b.Append(float64(rv.Uint()))
+		default:
+			return fmt.Errorf("want double, got %T", v)
+		}
+	case *array.Date32Builder:
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("want date, got %T", v)
+		}
+		b.Append(arrow.Date32FromTime(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)))
+	case *array.TimestampBuilder:
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("want datetime, got %T", v)
+		}
+		if valueType == "datetime" {
+			// Keep the wall clock of zone-less datetimes.
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		}
+		b.Append(arrow.Timestamp(t.UnixNano()))
+	default:
+		return fmt.Errorf("unsupported column type %s", b.Type())
+	}
+	return nil
+}
+
+// unwrap flattens a {"value": v} fetch wrapper.
+func unwrap(v any) any {
+	if m, ok := v.(map[string]any); ok {
+		if inner, ok := m["value"]; ok {
+			return inner
+		}
+	}
+	return v
+}
@@ -0,0 +1,202 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/export"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+)
+
+type person struct {
+	gotype.BaseEntity
+	Name      string     `typedb:"name,key"`
+	Age       *int       `typedb:"age,card=0..1"`
+	Score     float64    `typedb:"score"`
+	Joined    *time.Time `typedb:"joined,card=0..1"`
+	Nicknames []string   `typedb:"nickname,card=0.."`
+}
+
+type company struct {
+	gotype.BaseEntity
+	Title string `typedb:"title,key"`
+}
+
+type employment struct {
+	gotype.BaseRelation
+	Employee *person  `typedb:"role:employee"`
+	Employer *company `typedb:"role:employer"`
+	Active   bool     `typedb:"active"`
+}
+
+type fixture struct {
+	db         *gotype.Database
+	alice, bob *person
+	acme       *company
+	job        *employment
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	reg := gotype.NewRegistry()
+	for _, err := range []error{
+		gotype.RegisterIn[person](reg),
+		gotype.RegisterIn[company](reg),
+		gotype.RegisterIn[employment](reg),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := gotype.NewDatabase(gotypetest.NewFakeDB(), "test").WithRegistry(reg)
+	if err := db.ExecuteSchema(ctx, reg.GenerateSchema()); err != nil {
+		t.Fatal(err)
+	}
+	age, joined := 30, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	f := &fixture{
+		db:    db,
+		alice: &person{Name: "Alice", Age: &age, Score: 1.5, Joined: &joined, Nicknames: []string{"Al"}},
+		bob:   &person{Name: "Bob"},
+		acme:  &company{Title: "Acme"},
+	}
+	if err := gotype.MustNewManager[person](db).InsertMany(ctx, []*person{f.alice, f.bob}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gotype.MustNewManager[company](db).Insert(ctx, f.acme); err != nil {
+		t.Fatal(err)
+	}
+	f.job = &employment{Employee: f.alice, Employer: f.acme, Active: true}
+	if err := gotype.MustNewManager[employment](db).Insert(ctx, f.job); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestSchema(t *testing.T) {
+	f := newFixture(t)
+	info, _ := f.db.Registry().Lookup("employment")
+	if got := export.Schema(info).String(); got != `schema:
+  fields: 4
+    - _iid: type=utf8
+    - active: type=bool, nullable
+    - employee: type=utf8, nullable
+    - employer: type=utf8, nullable` {
+		t.Errorf("schema = %s", got)
+	}
+	info, _ = f.db.Registry().Lookup("person")
+	schema := export.Schema(info)
+	for name, want := range map[string]arrow.DataType{
+		"name":     arrow.BinaryTypes.String,
+		"age":      arrow.PrimitiveTypes.Int64,
+		"score":    arrow.PrimitiveTypes.Float64,
+		"joined":   &arrow.TimestampType{Unit: arrow.Nanosecond},
+		"nickname": arrow.ListOf(arrow.BinaryTypes.String),
+	} {
+		fields, _ := schema.FieldsByName(name)
+		if len(fields) != 1 || !arrow.TypeEqual(fields[0].Type, want) {
+			t.Errorf("%s: %v, want %s", name, fields, want)
+		}
+	}
+}
+
+func TestRecords(t *testing.T) {
+	f := newFixture(t)
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	q := gotype.MustNewManager[person](f.db).Query().OrderAsc("name")
+	var rows int64
+	for rec, err := range export.Records(context.Background(), q, export.WithBatchSize(1), export.WithAllocator(mem)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.NumRows() != 1 {
+			t.Fatalf("batch of %d rows", rec.NumRows())
+		}
+		row := rowOf(rec, 0)
+		switch rows++; row["name"] {
+		case "Alice":
+			if row["_iid"] != f.alice.GetIID() || row["age"] != int64(30) || row["score"] != 1.5 ||
+				row["joined"] != arrow.Timestamp(f.alice.Joined.UnixNano()) || row["nickname"] != `["Al"]` {
+				t.Errorf("Alice = %v", row)
+			}
+		case "Bob":
+			if row["age"] != nil || row["joined"] != nil {
+				t.Errorf("Bob = %v", row)
+			}
+		default:
+			t.Errorf("unexpected row %v", row)
+		}
+	}
+	if rows != 2 {
+		t.Errorf("%d rows, want 2", rows)
+	}
+}
+
+func TestTypeParquet(t *testing.T) {
+	f := newFixture(t)
+	var buf bytes.Buffer
+	n, err := export.WriteTypeParquet(context.Background(), &buf, f.db, "employment")
+	if err != nil || n != 1 {
+		t.Fatalf("WriteTypeParquet = %d, %v", n, err)
+	}
+
+	pf, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := fr.ReadTable(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer table.Release()
+	tr := array.NewTableReader(table, 0)
+	defer tr.Release()
+	if !tr.Next() {
+		t.Fatal("no rows")
+	}
+	row := rowOf(tr.RecordBatch(), 0)
+	if row["_iid"] != f.job.GetIID() || row["active"] != true ||
+		row["employee"] != f.alice.GetIID() || row["employer"] != f.acme.GetIID() {
+		t.Errorf("employment = %v", row)
+	}
+
+	if _, err := export.WriteTypeParquet(context.Background(), &buf, f.db, "robot"); err == nil {
+		t.Error("expected error for unregistered type")
+	}
+}
+
+// rowOf returns row i of rec by column name; list cells are rendered as
+// strings.
+func rowOf(rec arrow.RecordBatch, i int) map[string]any {
+	row := make(map[string]any)
+	for c, col := range rec.Columns() {
+		name := rec.ColumnName(c)
+		switch {
+		case col.IsNull(i):
+			row[name] = nil
+		case col.DataType().ID() == arrow.LIST:
+			row[name] = col.ValueStr(i)
+		default:
+			row[name] = col.GetOneForMarshal(i)
+			if ts, ok := col.(*array.Timestamp); ok {
+				row[name] = ts.Value(i)
+			}
+		}
+	}
+	return row
+}
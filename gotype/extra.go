@@ -0,0 +1,130 @@
+package gotype
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/ast"
+)
+
+// ExtraAttributesKey is the fetch key under which models with an ",extra"
+// field fetch all attributes of the instance ({ $x.* }). Hydration copies the
+// entries not mapped to struct fields into the extra map.
+const ExtraAttributesKey = "_attributes"
+
+var extraFieldType = reflect.TypeFor[map[string]any]()
+
+// HasExtra reports whether the model declares a map[string]any field tagged
+// ",extra" for unmapped attributes.
+func (m *ModelInfo) HasExtra() bool {
+	return m.extraField != nil
+}
+
+// isMappedAttr reports whether name is owned by a struct field or role of info.
+func isMappedAttr(info *ModelInfo, name string) bool {
+	if _, ok := fieldByName(info.Fields, name); ok {
+		return true
+	}
+	for _, role := range info.Roles {
+		if role.RoleName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// appendExtraFetch adds the attribute wildcard used to fill the extra field.
+func appendExtraFetch(items []ast.FetchItem, info *ModelInfo, varName string) []ast.FetchItem {
+	if info.extraField == nil {
+		return items
+	}
+	return append(items, ast.FetchNestedWildcard{Key: ExtraAttributesKey, Var: "$" + varName})
+}
+
+// hydrateExtra fills the extra field of v with unmapped attributes from data.
+// Entries come from the ExtraAttributesKey wildcard object and from top-level
+// keys that are neither mapped nor synthetic ("_"-prefixed).
+func hydrateExtra(v reflect.Value, info *ModelInfo, data map[string]any) {
+	if info.extraField == nil {
+		return
+	}
+	extra := make(map[string]any)
+	if attrs, ok := data[ExtraAttributesKey].(map[string]any); ok {
+		for name, val := range attrs {
+			if !isMappedAttr(info, name) {
+				extra[name] = unwrapExtraValue(val)
+			}
+		}
+	}
+	for name, val := range data {
+		if strings.HasPrefix(name, "_") || isMappedAttr(info, name) {
+			continue
+		}
+		extra[name] = unwrapExtraValue(val)
+	}
+	if len(extra) == 0 {
+		return
+	}
+	info.extraField.fieldValue(v).Set(reflect.ValueOf(extra))
+}
+
+func unwrapExtraValue(val any) any {
+	if list, ok := val.([]any); ok {
+		out := make([]any, len(list))
+		for i, item := range list {
+			out[i] = unwrapValue(item)
+		}
+		return out
+	}
+	return unwrapValue(val)
+}
+
+// visitExtraValues calls fn for every value in the extra field of v, in
+// attribute name order. Slice values produce one call per element.
+// Attributes that are also mapped to struct fields are skipped.
+func visitExtraValues(v reflect.Value, info *ModelInfo, fn func(name string, val any) error) error {
+	if info.extraField == nil {
+		return nil
+	}
+	extra, _ := info.extraField.fieldValue(v).Interface().(map[string]any)
+	if len(extra) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if isMappedAttr(info, name) {
+			continue
+		}
+		if err := visitExtraValue(name, extra[name], fn); err != nil {
+			return fmt.Errorf("field %s: %w", info.extraField.FieldName, err)
+		}
+	}
+	return nil
+}
+
+func visitExtraValue(name string, val any, fn func(name string, val any) error) error {
+	if val == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		for i := 0; i < rv.Len(); i++ {
+			if err := visitExtraValue(name, rv.Index(i).Interface(), fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	converted, err := attrValueOf(rv)
+	if err != nil {
+		return fmt.Errorf("attribute %s: %w", name, err)
+	}
+	return fn(name, converted)
+}
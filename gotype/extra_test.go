@@ -0,0 +1,136 @@
+package gotype
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testBagItem struct {
+	BaseEntity
+	Name  string         `typedb:"name,key"`
+	Attrs map[string]any `typedb:",extra"`
+}
+
+type testBadExtra struct {
+	BaseEntity
+	Name  string            `typedb:"name,key"`
+	Attrs map[string]string `typedb:",extra"`
+}
+
+func TestExtractModelInfo_ExtraField(t *testing.T) {
+	info, err := ExtractModelInfo(reflect.TypeOf(testBagItem{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !info.HasExtra() {
+		t.Fatal("expected extra field")
+	}
+	if len(info.Fields) != 1 {
+		t.Errorf("extra field must not be an attribute field, got %d fields", len(info.Fields))
+	}
+
+	if _, err := ExtractModelInfo(reflect.TypeOf(testBadExtra{})); err == nil ||
+		!strings.Contains(err.Error(), "must be map[string]any") {
+		t.Errorf("expected type error, got %v", err)
+	}
+}
+
+func TestExtraField_FetchIncludesWildcard(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testBagItem]()
+	info, _ := Lookup("test-bag-item")
+
+	fetch, err := buildFetchAll(info, "e")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, fetch, `"_attributes": { $e.* }`)
+}
+
+func TestExtraField_Hydrate(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testBagItem]()
+
+	item, err := HydrateNew[testBagItem](map[string]any{
+		"_iid": "0x1",
+		"name": "widget",
+		"_attributes": map[string]any{
+			"name":  "widget",
+			"color": "red",
+			"size":  []any{float64(1), float64(2)},
+		},
+		"weight": map[string]any{"value": float64(3.5)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"color":  "red",
+		"size":   []any{float64(1), float64(2)},
+		"weight": float64(3.5),
+	}
+	if !reflect.DeepEqual(item.Attrs, want) {
+		t.Errorf("Attrs = %#v, want %#v", item.Attrs, want)
+	}
+}
+
+func TestExtraField_InsertQuery(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testBagItem]()
+
+	q, err := ToInsertQuery(&testBagItem{
+		Name:  "widget",
+		Attrs: map[string]any{"size": []int{1, 2}, "color": "red", "name": "ignored"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, q, `has color "red"`)
+	assertContains(t, q, `has size 1`)
+	assertContains(t, q, `has size 2`)
+	if strings.Contains(q, "ignored") {
+		t.Errorf("mapped attribute in extra bag should be skipped:\n%s", q)
+	}
+	if strings.Index(q, "color") > strings.Index(q, "size") {
+		t.Errorf("extra attributes should be emitted in name order:\n%s", q)
+	}
+}
+
+func TestExtraField_Update(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testBagItem]()
+
+	tx := &mockTx{}
+	mgr := MustNewManager[testBagItem](NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db"))
+
+	item := &testBagItem{Name: "widget", Attrs: map[string]any{"color": "blue"}}
+	item.SetIID("0x1")
+	if err := mgr.Update(context.Background(), item); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	q := tx.queries[0]
+	assertContains(t, q, "try { $e has color $old0; };")
+	assertContains(t, q, `has color "blue"`)
+}
+
+func TestExtraField_ToDictRoundTrip(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testBagItem]()
+
+	d, err := ToDict(&testBagItem{Name: "widget", Attrs: map[string]any{"color": "red"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d["color"] != "red" {
+		t.Errorf("ToDict color = %v", d["color"])
+	}
+	back, err := FromDict[testBagItem](d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back.Attrs["color"] != "red" {
+		t.Errorf("round trip Attrs = %v", back.Attrs)
+	}
+}
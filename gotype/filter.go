@@ -80,6 +80,60 @@ func isScalarFilterValue(value any) bool {
 	}
 }
 
+// filterPatterns renders filters for varName. AttrValuer values in the
+// filters are converted first, so a failed conversion is returned as an error
+// instead of the raw value being formatted into the query.
+func filterPatterns(filters []Filter, varName string) ([]string, error) {
+	var patterns []string
+	for _, f := range filters {
+		if err := checkFilterValues(f); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, f.ToPatterns(varName)...)
+	}
+	return patterns, nil
+}
+
+// checkFilterValues returns the first AttrValuer conversion error among the
+// values of f and its nested filters.
+func checkFilterValues(f Filter) error {
+	var attr string
+	var values []any
+	switch f := f.(type) {
+	case *ComparisonFilter:
+		attr, values = f.Attr, []any{f.Value}
+	case *InFilter:
+		attr, values = f.Attr, f.Values
+	case *RangeFilter:
+		attr, values = f.Attr, []any{f.Min, f.Max}
+	case *ComputedFilter:
+		attr, values = f.VarName, []any{f.Value}
+	case *AndFilter:
+		return checkFilterList(f.Filters)
+	case *OrFilter:
+		return checkFilterList(f.Filters)
+	case *NotFilter:
+		return checkFilterValues(f.Inner)
+	case *RolePlayerFilter:
+		return checkFilterValues(f.Inner)
+	}
+	for _, v := range values {
+		if _, err := attrValue(v); err != nil {
+			return fmt.Errorf("filter on %s: %w", attr, err)
+		}
+	}
+	return nil
+}
+
+func checkFilterList(filters []Filter) error {
+	for _, f := range filters {
+		if err := checkFilterValues(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // --- Convenience constructors ---
 
 // Eq creates an equality filter: attribute == value.
@@ -112,6 +166,58 @@ func Lte(attr string, value any) Filter {
 	return &ComparisonFilter{Attr: attr, Op: "<=", Value: value}
 }
 
+// --- Attribute comparison filters ---
+
+// FieldComparisonFilter compares two attributes of the same instance, binding
+// each with a has-pattern and comparing the two variables.
+type FieldComparisonFilter struct {
+	Left  string
+	Op    string
+	Right string
+}
+
+// ToPatterns generates TypeQL patterns for an attribute comparison filter.
+func (f *FieldComparisonFilter) ToPatterns(varName string) []string {
+	left := sanitizeVar(varName + "__" + f.Left)
+	right := sanitizeVar(varName + "__" + f.Right)
+	return []string{
+		fmt.Sprintf("$%s has %s $%s;", varName, f.Left, left),
+		fmt.Sprintf("$%s has %s $%s;", varName, f.Right, right),
+		fmt.Sprintf("$%s %s $%s;", left, f.Op, right),
+	}
+}
+
+// FieldEq creates a filter: left attribute == right attribute.
+func FieldEq(left, right string) Filter {
+	return &FieldComparisonFilter{Left: left, Op: "==", Right: right}
+}
+
+// FieldNeq creates a filter: left attribute != right attribute.
+func FieldNeq(left, right string) Filter {
+	return &FieldComparisonFilter{Left: left, Op: "!=", Right: right}
+}
+
+// FieldGt creates a filter: left attribute > right attribute, e.g.
+// FieldGt("spent", "budget").
+func FieldGt(left, right string) Filter {
+	return &FieldComparisonFilter{Left: left, Op: ">", Right: right}
+}
+
+// FieldGte creates a filter: left attribute >= right attribute.
+func FieldGte(left, right string) Filter {
+	return &FieldComparisonFilter{Left: left, Op: ">=", Right: right}
+}
+
+// FieldLt creates a filter: left attribute < right attribute.
+func FieldLt(left, right string) Filter {
+	return &FieldComparisonFilter{Left: left, Op: "<", Right: right}
+}
+
+// FieldLte creates a filter: left attribute <= right attribute.
+func FieldLte(left, right string) Filter {
+	return &FieldComparisonFilter{Left: left, Op: "<=", Right: right}
+}
+
 // --- String filters ---
 
 // StringFilter applies string operations (contains, like) on an attribute.
@@ -163,7 +269,7 @@ func (f *InFilter) ToPatterns(varName string) []string {
 			return nil
 		}
 		// IN empty set → never true. Match impossible IID.
-		return []string{fmt.Sprintf("$%s iid 0xFFFFFFFFFFFFFFFF;", varName)}
+		return []string{fmt.Sprintf("$%s iid %s;", varName, noIID)}
 	}
 
 	attrVar := sanitizeVar(varName + "__" + f.Attr)
@@ -448,6 +554,46 @@ func RolePlayer(roleName string, inner Filter) Filter {
 	return &RolePlayerFilter{RoleName: roleName, Inner: inner}
 }
 
+// PlayerFilter matches relations in which the instance with the given IID
+// plays RoleName.
+type PlayerFilter struct {
+	RoleName string
+	IID      string
+}
+
+// noIID is an IID no instance has, used by filters that must match nothing.
+const noIID = "0xFFFFFFFFFFFFFFFF"
+
+// ToPatterns generates TypeQL patterns binding the role player by IID. A
+// malformed IID matches nothing.
+func (f *PlayerFilter) ToPatterns(varName string) []string {
+	iid := f.IID
+	if !IsIID(iid) {
+		iid = noIID
+	}
+	playerVar := fmt.Sprintf("%s_%s_%s", varName, sanitizeVar(f.RoleName), iid)
+	return []string{
+		fmt.Sprintf("$%s links (%s: $%s);", varName, f.RoleName, playerVar),
+		fmt.Sprintf("$%s iid %s;", playerVar, iid),
+	}
+}
+
+// Player creates a filter that matches relations in which player plays
+// roleName. player is an IID string or a stored model instance such as
+// *Person; anything else, or an instance without an IID, matches nothing.
+func Player(roleName string, player any) Filter {
+	var iid string
+	switch p := player.(type) {
+	case string:
+		iid = p
+	case interface{ GetIID() string }:
+		if rv := reflect.ValueOf(p); rv.Kind() != reflect.Pointer || !rv.IsNil() {
+			iid = p.GetIID()
+		}
+	}
+	return &PlayerFilter{RoleName: roleName, IID: iid}
+}
+
 // --- Computed expression filters ---
 
 // ComputedFilter uses a let-assignment to compute a value and compare it.
@@ -63,6 +63,26 @@ func TestLte(t *testing.T) {
 	assertContains(t, joined, "$e__age <= 20;")
 }
 
+func TestFieldGt(t *testing.T) {
+	patterns := FieldGt("spent", "budget").ToPatterns("e")
+	want := []string{"$e has spent $e__spent;", "$e has budget $e__budget;", "$e__spent > $e__budget;"}
+	if strings.Join(patterns, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q", patterns)
+	}
+}
+
+func TestFieldComparison_Operators(t *testing.T) {
+	for f, op := range map[Filter]string{
+		FieldEq("a", "b"): "==", FieldNeq("a", "b"): "!=", FieldGte("a", "b"): ">=",
+		FieldLt("a", "b"): "<", FieldLte("a", "b"): "<=",
+	} {
+		assertContains(t, strings.Join(f.ToPatterns("e"), " "), "$e__a "+op+" $e__b;")
+	}
+	joined := strings.Join(Not(FieldGt("unit-price", "list-price")).ToPatterns("e"), " ")
+	assertContains(t, joined, "not {")
+	assertContains(t, joined, "__unit_price > $e_n")
+}
+
 func TestContains(t *testing.T) {
 	f := Contains("name", "Ali")
 	joined := strings.Join(f.ToPatterns("e"), " ")
@@ -226,6 +246,19 @@ func TestRolePlayer_Nested(t *testing.T) {
 	assertContains(t, joined, "$employer has industry $employer__industry;")
 }
 
+func TestPlayer(t *testing.T) {
+	alice := &testPerson{Name: "Alice"}
+	alice.SetIID("0xab")
+	joined := strings.Join(And(Player("similar-memory", alice), Player("similar-memory", "0xcd")).ToPatterns("r"), " ")
+	assertContains(t, joined, "$r links (similar-memory: $r_similar_memory_0xab); $r_similar_memory_0xab iid 0xab;")
+	assertContains(t, joined, "$r links (similar-memory: $r_similar_memory_0xcd); $r_similar_memory_0xcd iid 0xcd;")
+
+	for _, player := range []any{&testPerson{}, (*testPerson)(nil), "0xab; delete", 42} {
+		joined := strings.Join(Player("employee", player).ToPatterns("r"), " ")
+		assertContains(t, joined, "iid 0xFFFFFFFFFFFFFFFF;")
+	}
+}
+
 func TestSanitizeVar_Hyphens(t *testing.T) {
 	f := Eq("start-date", "2024-01-15")
 	patterns := f.ToPatterns("e")
@@ -0,0 +1,383 @@
+package gotype
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/internal/yaml"
+)
+
+// Fixture is the content of one fixture file: entities and relations to
+// insert, identified by symbolic references that relations use to name
+// their role players. Files are JSON or YAML with the same layout:
+//
+//	{
+//	  "entities": [
+//	    {"ref": "alice", "type": "person", "attributes": {"name": "Alice", "age": 30}},
+//	    {"ref": "acme", "type": "company", "attributes": {"name": "Acme"}}
+//	  ],
+//	  "relations": [
+//	    {"type": "employment", "roles": {"employee": "alice", "employer": "acme"},
+//	     "attributes": {"start-date": "2024-01-01"}}
+//	  ]
+//	}
+type Fixture struct {
+	Entities  []FixtureEntity   `json:"entities"`
+	Relations []FixtureRelation `json:"relations"`
+	// Source is the path of the file the fixture was loaded from, used in
+	// errors. LoadFixtures sets it.
+	Source string `json:"-"`
+}
+
+// FixtureEntity is an entity to insert. Attribute values are JSON scalars, or
+// arrays for several values of one attribute type; strings are converted to
+// the attribute's value type, so dates can be written as "2024-01-01".
+type FixtureEntity struct {
+	Ref        string         `json:"ref"`
+	Type       string         `json:"type"`
+	Attributes map[string]any `json:"attributes"`
+}
+
+// FixtureRelation is a relation to insert. Roles maps role names to the
+// reference of the player, or a list of references for several players.
+// Players are entities or relations defined in any of the fixtures seeded
+// together.
+type FixtureRelation struct {
+	Ref        string                 `json:"ref"`
+	Type       string                 `json:"type"`
+	Roles      map[string]FixtureRefs `json:"roles"`
+	Attributes map[string]any         `json:"attributes"`
+}
+
+// FixtureRefs is one reference or a list of references.
+type FixtureRefs []string
+
+// UnmarshalJSON accepts a string or an array of strings.
+func (r *FixtureRefs) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*r = FixtureRefs{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("role players must be a reference or a list of references")
+	}
+	*r = many
+	return nil
+}
+
+// LoadFixtures reads the .json, .yaml and .yml files under dir in fsys,
+// walked in lexical order. YAML is converted to JSON first, so both formats
+// are decoded the same way, and unknown fields are rejected so that typos do
+// not silently drop data.
+func LoadFixtures(fsys fs.FS, dir string) ([]Fixture, error) {
+	var fixtures []Fixture
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch path.Ext(p) {
+		case ".json", ".yaml", ".yml":
+			if d.IsDir() {
+				return nil
+			}
+			f, err := readFixture(fsys, p)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			fixtures = append(fixtures, f)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+// readFixture decodes the fixture file name.
+func readFixture(fsys fs.FS, name string) (Fixture, error) {
+	f := Fixture{Source: name}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return f, err
+	}
+	if path.Ext(name) != ".json" {
+		v, err := yaml.Parse(data)
+		if err != nil {
+			return f, err
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return f, err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&f)
+	return f, err
+}
+
+// fixtureRef is an inserted instance a reference resolves to.
+type fixtureRef struct {
+	typeName, iid string
+}
+
+// SeedFixtures inserts the entities of fixtures, then their relations, in a
+// single write transaction: either everything is inserted or nothing is.
+// Entities keep the fixture order; each relation is inserted after the
+// relations playing roles in it, and in fixture order otherwise, so a
+// relation may reference players defined in any fixture. Attribute value
+// types are read from the database schema. It returns the IID of every
+// referenced instance by reference.
+func SeedFixtures(ctx context.Context, db *Database, fixtures []Fixture) (map[string]string, error) {
+	type entity struct {
+		FixtureEntity
+		what string
+	}
+	type relation struct {
+		FixtureRelation
+		what string
+	}
+	var entities []entity
+	var relations []relation
+	defined := make(map[string]string) // reference -> source of the fixture defining it
+	define := func(source, what, ref string) error {
+		if ref == "" {
+			return nil
+		}
+		if other, dup := defined[ref]; dup {
+			if other != "" {
+				other = " in " + other
+			}
+			return fmt.Errorf("seed %s: reference %q is already defined%s", what, ref, other)
+		}
+		defined[ref] = source
+		return nil
+	}
+	for _, f := range fixtures {
+		for i, e := range f.Entities {
+			what := fixtureName(f.Source, "entity", e.Type, e.Ref, i)
+			if err := define(f.Source, what, e.Ref); err != nil {
+				return nil, err
+			}
+			entities = append(entities, entity{e, what})
+		}
+		for i, r := range f.Relations {
+			what := fixtureName(f.Source, "relation", r.Type, r.Ref, i)
+			if err := define(f.Source, what, r.Ref); err != nil {
+				return nil, err
+			}
+			relations = append(relations, relation{r, what})
+		}
+	}
+	rels := make([]FixtureRelation, len(relations))
+	for i, r := range relations {
+		rels[i] = r.FixtureRelation
+	}
+	order, err := sortFixtureRelations(rels)
+	if err != nil {
+		return nil, fmt.Errorf("seed: %w", err)
+	}
+
+	schemaStr, err := db.Schema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("seed: %w", err)
+	}
+	schema, err := IntrospectSchemaFromString(schemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("seed: %w", err)
+	}
+	valueTypes := make(map[string]string, len(schema.Attributes))
+	for _, a := range schema.Attributes {
+		valueTypes[a.Name] = a.ValueType
+	}
+
+	tx, err := db.TransactionContext(ctx, WriteTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("seed: %w", err)
+	}
+	defer tx.Close()
+
+	refs := make(map[string]fixtureRef)
+	insert := func(what, ref, typeName, query string) error {
+		if err := ValidateIdentifier(typeName, "type"); err != nil {
+			return fmt.Errorf("seed %s: %w", what, err)
+		}
+		results, err := tx.QueryWithContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("seed %s: %w", what, err)
+		}
+		if ref != "" {
+			if len(results) != 1 || extractIID(results[0]) == "" {
+				return fmt.Errorf("seed %s: insert returned no IID", what)
+			}
+			refs[ref] = fixtureRef{typeName: typeName, iid: extractIID(results[0])}
+		}
+		return nil
+	}
+
+	for _, e := range entities {
+		has, err := fixtureHasClauses(e.Attributes, valueTypes)
+		if err != nil {
+			return nil, fmt.Errorf("seed %s: %w", e.what, err)
+		}
+		query := "insert\n$e isa " + e.Type + has + ";\nfetch {\n  \"_iid\": iid($e)\n};"
+		if err := insert(e.what, e.Ref, e.Type, query); err != nil {
+			return nil, err
+		}
+	}
+	for _, i := range order {
+		r, what := relations[i].FixtureRelation, relations[i].what
+		has, err := fixtureHasClauses(r.Attributes, valueTypes)
+		if err != nil {
+			return nil, fmt.Errorf("seed %s: %w", what, err)
+		}
+		var match, links []string
+		for _, role := range slices.Sorted(maps.Keys(r.Roles)) {
+			if err := ValidateIdentifier(role, "role"); err != nil {
+				return nil, fmt.Errorf("seed %s: %w", what, err)
+			}
+			for _, name := range r.Roles[role] {
+				player, ok := refs[name]
+				if !ok {
+					return nil, fmt.Errorf("seed %s: role %s: unknown reference %q", what, role, name)
+				}
+				v := fmt.Sprintf("$p%d", len(match))
+				match = append(match, fmt.Sprintf("%s isa %s, iid %s;", v, player.typeName, player.iid))
+				links = append(links, role+": "+v)
+			}
+		}
+		if len(links) == 0 {
+			return nil, fmt.Errorf("seed %s: no role players", what)
+		}
+		query := "match\n" + strings.Join(match, "\n") + "\ninsert\n$r isa " + r.Type +
+			", links (" + strings.Join(links, ", ") + ")" + has + ";\nfetch {\n  \"_iid\": iid($r)\n};"
+		if err := insert(what, r.Ref, r.Type, query); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("seed: commit: %w", err)
+	}
+	iids := make(map[string]string, len(refs))
+	for ref, r := range refs {
+		iids[ref] = r.iid
+	}
+	return iids, nil
+}
+
+// fixtureName names an entry in errors by its reference, or its position,
+// after the file it comes from when known.
+func fixtureName(source, kind, typeName, ref string, i int) string {
+	name := fmt.Sprintf("%s %s #%d", kind, typeName, i+1)
+	if ref != "" {
+		name = fmt.Sprintf("%s %q", typeName, ref)
+	}
+	if source != "" {
+		name = source + ": " + name
+	}
+	return name
+}
+
+// sortFixtureRelations returns the order in which to insert relations: each
+// after the relations playing roles in it, keeping the given order
+// otherwise. References to anything but relations are left for the insert
+// to resolve.
+func sortFixtureRelations(relations []FixtureRelation) ([]int, error) {
+	byRef := make(map[string]int)
+	for i, r := range relations {
+		if r.Ref != "" {
+			byRef[r.Ref] = i
+		}
+	}
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := make([]int, len(relations))
+	order := make([]int, 0, len(relations))
+	var visit func(i int, chain []string) error
+	visit = func(i int, chain []string) error {
+		r := relations[i]
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("relations reference each other in a cycle: %s", strings.Join(append(chain, r.Ref), " -> "))
+		}
+		state[i] = visiting
+		for _, role := range slices.Sorted(maps.Keys(r.Roles)) {
+			for _, ref := range r.Roles[role] {
+				if j, ok := byRef[ref]; ok {
+					if err := visit(j, append(chain, r.Ref)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		state[i] = done
+		order = append(order, i)
+		return nil
+	}
+	for i := range relations {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// fixtureHasClauses renders ", has name value" clauses in attribute name
+// order, converting JSON values to the attributes' value types.
+func fixtureHasClauses(attrs map[string]any, valueTypes map[string]string) (string, error) {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(attrs)) {
+		valueType, ok := valueTypes[name]
+		if !ok {
+			return "", fmt.Errorf("unknown attribute type %s", name)
+		}
+		values, ok := attrs[name].([]any)
+		if !ok {
+			values = []any{attrs[name]}
+		}
+		for _, raw := range values {
+			v, err := fixtureValue(valueType, raw)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", name, err)
+			}
+			has, err := hasClause(name, v)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(", ")
+			b.WriteString(has)
+		}
+	}
+	return b.String(), nil
+}
+
+func fixtureValue(valueType string, raw any) (any, error) {
+	switch v := raw.(type) {
+	case string:
+		return ParseAttributeValue(valueType, v)
+	case json.Number:
+		return ParseAttributeValue(valueType, v.String())
+	case bool:
+		if valueType != "boolean" {
+			return nil, fmt.Errorf("boolean value for a %s attribute", valueType)
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v", raw)
+	}
+}
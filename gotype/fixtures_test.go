@@ -0,0 +1,177 @@
+package gotype
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const fixtureSchema = `define
+attribute name, value string;
+attribute age, value integer;
+attribute start-date, value date;
+entity person, owns name, owns age, plays employment:employee;
+entity company, owns name, plays employment:employer;
+relation employment, relates employee, relates employer, owns start-date;
+`
+
+func TestLoadFixtures(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fixtures/02_jobs.json":   {Data: []byte(`{"relations": [{"type": "employment", "roles": {"employee": ["alice", "bob"], "employer": "acme"}}]}`)},
+		"fixtures/01_people.json": {Data: []byte(`{"entities": [{"ref": "alice", "type": "person", "attributes": {"age": 30}}]}`)},
+		"fixtures/03_more/bob.yml": {Data: []byte("entities:\n  - ref: bob\n    type: person\n    attributes:\n      age: 41\n      name: [Bob, Robert]\n")},
+		"fixtures/notes.txt":       {Data: []byte("ignored")},
+	}
+	fixtures, err := LoadFixtures(fsys, "fixtures")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	if len(fixtures) != 3 || fixtures[0].Entities[0].Ref != "alice" || fixtures[0].Source != "fixtures/01_people.json" {
+		t.Fatalf("fixtures = %+v", fixtures)
+	}
+	if got := fixtures[1].Relations[0].Roles["employee"]; len(got) != 2 || got[1] != "bob" {
+		t.Errorf("employee refs = %v", got)
+	}
+	bob := fixtures[2].Entities[0]
+	if fixtures[2].Source != "fixtures/03_more/bob.yml" || bob.Ref != "bob" || bob.Attributes["age"] != json.Number("41") {
+		t.Errorf("yaml fixture = %+v", fixtures[2])
+	}
+	if names, ok := bob.Attributes["name"].([]any); !ok || len(names) != 2 {
+		t.Errorf("yaml names = %#v", bob.Attributes["name"])
+	}
+
+	fsys["fixtures/04_bad.yaml"] = &fstest.MapFile{Data: []byte("entities:\n  - type: person\n    attrs: {}\n")}
+	if _, err := LoadFixtures(fsys, "fixtures"); err == nil || !strings.Contains(err.Error(), `04_bad.yaml: json: unknown field "attrs"`) {
+		t.Errorf("unknown yaml field: err = %v", err)
+	}
+	delete(fsys, "fixtures/04_bad.yaml")
+
+	fsys["fixtures/03_bad.json"] = &fstest.MapFile{Data: []byte(`{"entites": []}`)}
+	if _, err := LoadFixtures(fsys, "fixtures"); err == nil || !strings.Contains(err.Error(), "03_bad.json") {
+		t.Errorf("unknown field: err = %v", err)
+	}
+}
+
+func TestSeedFixtures(t *testing.T) {
+	tx := &mockTx{responses: [][]map[string]any{
+		{{"_iid": "0x1"}},
+		{{"_iid": "0x2"}},
+		{{"_iid": "0x3"}},
+	}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}, schemaStr: fixtureSchema}, "test_db")
+	fixtures := []Fixture{
+		{Entities: []FixtureEntity{
+			{Ref: "alice", Type: "person", Attributes: map[string]any{"name": "Alice", "age": "30"}},
+			{Ref: "acme", Type: "company", Attributes: map[string]any{"name": []any{"Acme", "ACME Corp"}}},
+		}},
+		{Relations: []FixtureRelation{
+			{Ref: "job", Type: "employment",
+				Roles:      map[string]FixtureRefs{"employer": {"acme"}, "employee": {"alice"}},
+				Attributes: map[string]any{"start-date": "2024-01-02"}},
+		}},
+	}
+
+	iids, err := SeedFixtures(context.Background(), db, fixtures)
+	if err != nil {
+		t.Fatalf("SeedFixtures: %v", err)
+	}
+	if iids["alice"] != "0x1" || iids["acme"] != "0x2" || iids["job"] != "0x3" {
+		t.Errorf("iids = %v", iids)
+	}
+	if !tx.committed {
+		t.Error("transaction was not committed")
+	}
+	assertContains(t, tx.queries[0], "insert\n$e isa person, has age 30, has name \"Alice\";")
+	assertContains(t, tx.queries[1], "$e isa company, has name \"Acme\", has name \"ACME Corp\";")
+	assertContains(t, tx.queries[2], "match\n$p0 isa person, iid 0x1;\n$p1 isa company, iid 0x2;\n"+
+		"insert\n$r isa employment, links (employee: $p0, employer: $p1), has start-date 2024-01-02;")
+}
+
+func TestSeedFixtures_DependencyOrder(t *testing.T) {
+	tx := &mockTx{responses: [][]map[string]any{
+		{{"_iid": "0x1"}},
+		{{"_iid": "0x2"}},
+		{{"_iid": "0x3"}},
+		{{"_iid": "0x4"}},
+	}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}, schemaStr: fixtureSchema}, "test_db")
+	fixtures := []Fixture{
+		// audit has job as a player although it comes first, and both
+		// reference entities of a later file.
+		{Source: "a.yaml", Relations: []FixtureRelation{
+			{Ref: "audit", Type: "review", Roles: map[string]FixtureRefs{"subject": {"job"}}},
+			{Ref: "job", Type: "employment", Roles: map[string]FixtureRefs{"employee": {"alice"}, "employer": {"acme"}}},
+		}},
+		{Source: "b.yaml", Entities: []FixtureEntity{{Ref: "alice", Type: "person"}, {Ref: "acme", Type: "company"}}},
+	}
+	iids, err := SeedFixtures(context.Background(), db, fixtures)
+	if err != nil {
+		t.Fatalf("SeedFixtures: %v", err)
+	}
+	if iids["alice"] != "0x1" || iids["acme"] != "0x2" || iids["job"] != "0x3" || iids["audit"] != "0x4" {
+		t.Errorf("iids = %v", iids)
+	}
+	assertContains(t, tx.queries[2], "$r isa employment")
+	assertContains(t, tx.queries[3], "$p0 isa employment, iid 0x3;")
+
+	fixtures[1].Entities = append(fixtures[1].Entities, FixtureEntity{Ref: "job", Type: "person"})
+	if _, err := SeedFixtures(context.Background(), db, fixtures); err == nil ||
+		!strings.Contains(err.Error(), `seed b.yaml: person "job": reference "job" is already defined in a.yaml`) {
+		t.Errorf("duplicate across files: err = %v", err)
+	}
+}
+
+func TestSortFixtureRelations(t *testing.T) {
+	rel := func(ref string, players ...string) FixtureRelation {
+		return FixtureRelation{Ref: ref, Type: "r", Roles: map[string]FixtureRefs{"p": players}}
+	}
+	order, err := sortFixtureRelations([]FixtureRelation{rel("x", "y"), rel("", "a"), rel("y", "z"), rel("z", "a", "b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(order); got != "[3 2 0 1]" {
+		t.Errorf("order = %s, want [3 2 0 1]", got)
+	}
+
+	_, err = sortFixtureRelations([]FixtureRelation{rel("x", "y"), rel("y", "x")})
+	if err == nil || !strings.Contains(err.Error(), "cycle: x -> y -> x") {
+		t.Errorf("cycle: %v", err)
+	}
+}
+
+func TestSeedFixtures_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture Fixture
+		want    string
+	}{
+		{"unknown reference", Fixture{Relations: []FixtureRelation{
+			{Type: "employment", Roles: map[string]FixtureRefs{"employee": {"ghost"}}},
+		}}, `employment #1: role employee: unknown reference "ghost"`},
+		{"unknown attribute", Fixture{Entities: []FixtureEntity{
+			{Ref: "a", Type: "person", Attributes: map[string]any{"nick": "x"}},
+		}}, `person "a": unknown attribute type nick`},
+		{"bad value", Fixture{Entities: []FixtureEntity{
+			{Type: "person", Attributes: map[string]any{"age": "old"}},
+		}}, `entity person #1: age: invalid integer "old"`},
+		{"duplicate reference", Fixture{Entities: []FixtureEntity{
+			{Ref: "a", Type: "person"}, {Ref: "a", Type: "person"},
+		}}, `reference "a" is already defined`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1"}}}}
+			db := NewDatabase(&mockConn{txs: []*mockTx{tx}, schemaStr: fixtureSchema}, "test_db")
+			_, err := SeedFixtures(context.Background(), db, []Fixture{tt.fixture})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+			if tx.committed {
+				t.Error("transaction committed despite the error")
+			}
+		})
+	}
+}
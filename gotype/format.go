@@ -2,6 +2,8 @@
 package gotype
 
 import (
+	"fmt"
+
 	"github.com/CaliLuke/go-typeql/ast"
 )
 
@@ -9,7 +11,46 @@ import (
 // It handles basic types, pointers, and time.Time, ensuring correct escaping
 // for use in TypeQL queries.
 //
-// This function delegates to ast.FormatGoValue for the actual formatting logic.
+// Values implementing AttrValuer are converted first. FormatValue has no way
+// to report a failed conversion and formats the original value instead; the
+// query builders check conversions up front and return the error. This
+// function delegates to ast.FormatGoValue for the actual formatting logic.
 func FormatValue(value any) string {
+	if converted, err := attrValue(value); err == nil {
+		value = converted
+	}
 	return ast.FormatGoValue(value)
 }
+
+// appendValue appends the TypeQL literal for value to dst, like FormatValue,
+// without building an intermediate string. A failed AttrValuer conversion is
+// returned as an error.
+func appendValue(dst []byte, value any) ([]byte, error) {
+	converted, err := attrValue(value)
+	if err != nil {
+		return dst, err
+	}
+	return ast.AppendGoValue(dst, converted), nil
+}
+
+// hasClause renders "has name value" with a single allocation for the
+// common scalar types.
+func hasClause(name string, value any) (string, error) {
+	var buf [128]byte
+	b := append(buf[:0], "has "...)
+	b = append(b, name...)
+	b = append(b, ' ')
+	b, err := appendValue(b, value)
+	if err != nil {
+		return "", fmt.Errorf("attribute %s: %w", name, err)
+	}
+	return string(b), nil
+}
+
+// attrValue converts AttrValuer values and returns other values unchanged.
+func attrValue(value any) (any, error) {
+	if v, ok := value.(AttrValuer); ok {
+		return v.AttrValue()
+	}
+	return value, nil
+}
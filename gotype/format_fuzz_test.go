@@ -0,0 +1,98 @@
+package gotype
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// unquoteTypeQL parses a TypeQL string literal, rejecting raw quotes, line
+// breaks, tabs and backslashes that do not start a known escape.
+func unquoteTypeQL(lit string) (string, error) {
+	if len(lit) < 2 || lit[0] != '"' || lit[len(lit)-1] != '"' {
+		return "", fmt.Errorf("not a quoted literal: %q", lit)
+	}
+	body := lit[1 : len(lit)-1]
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; c {
+		case '"', '\n', '\r', '\t':
+			return "", fmt.Errorf("unescaped %q at offset %d", c, i+1)
+		case '\\':
+			i++
+			if i == len(body) {
+				return "", fmt.Errorf("dangling backslash in %q", lit)
+			}
+			switch body[i] {
+			case '\\', '"':
+				b.WriteByte(body[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				return "", fmt.Errorf("unknown escape \\%c in %q", body[i], lit)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+type fuzzLabel string
+
+func FuzzFormatValue(f *testing.F) {
+	f.Add("", int64(0), 0.0, false)
+	f.Add(`say "hi"`, int64(-1), 3.14, true)
+	f.Add(`trailing backslash\`, int64(math.MaxInt64), 1e21, false)
+	f.Add(`\\"; delete $p; #`, int64(math.MinInt64), -0.0, true)
+	f.Add("line1\nline2\r\n\tend", int64(42), 5e-324, false)
+	f.Add("\x00\xff\xfe invalid utf-8 \xc3", int64(7), math.MaxFloat64, true)
+	f.Fuzz(func(t *testing.T, s string, n int64, x float64, b bool) {
+		strs := map[string]any{
+			"string":    s,
+			"*string":   &s,
+			"named":     fuzzLabel(s),
+			"AttrValue": testPrefs{Theme: s},
+		}
+		for name, v := range strs {
+			want := s
+			if name == "AttrValue" {
+				data, _ := json.Marshal(v)
+				want = string(data)
+			}
+			got, err := unquoteTypeQL(FormatValue(v))
+			if err != nil {
+				t.Fatalf("FormatValue(%s %q): %v", name, s, err)
+			}
+			if got != want {
+				t.Fatalf("FormatValue(%s %q) round-trips to %q, want %q", name, s, got, want)
+			}
+		}
+		if clause, err := hasClause("name", s); err != nil || clause != "has name "+FormatValue(s) {
+			t.Fatalf("hasClause(%q) = %s, %v", s, clause, err)
+		}
+
+		if got, err := strconv.ParseInt(FormatValue(n), 10, 64); err != nil || got != n {
+			t.Fatalf("FormatValue(%d) = %s", n, FormatValue(n))
+		}
+		if !math.IsNaN(x) && !math.IsInf(x, 0) {
+			lit := FormatValue(x)
+			if strings.ContainsAny(lit, "eE") {
+				t.Fatalf("FormatValue(%v) = %s uses an exponent", x, lit)
+			}
+			if got, err := strconv.ParseFloat(lit, 64); err != nil || got != x {
+				t.Fatalf("FormatValue(%v) = %s", x, lit)
+			}
+		}
+		if got := FormatValue(b); got != strconv.FormatBool(b) {
+			t.Fatalf("FormatValue(%v) = %s", b, got)
+		}
+	})
+}
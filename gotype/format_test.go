@@ -38,3 +38,23 @@ func TestFormatValue(t *testing.T) {
 		})
 	}
 }
+
+func TestHasClause(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{"alice", `has name "alice"`},
+		{`a"b`, `has name "a\"b"`},
+		{int64(42), "has name 42"},
+		{testPrefs{Theme: "dark"}, `has name "{\"theme\":\"dark\",\"tags\":null}"`},
+	}
+	for _, tt := range tests {
+		if got, err := hasClause("name", tt.value); err != nil || got != tt.want {
+			t.Errorf("hasClause(%#v) = %s, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+	if n := testing.AllocsPerRun(100, func() { _, _ = hasClause("name", int64(42)) }); n > 1 {
+		t.Errorf("hasClause allocated %.0f times, want at most 1", n)
+	}
+}
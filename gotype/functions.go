@@ -0,0 +1,79 @@
+package gotype
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/tqlgen"
+)
+
+// DefineFunction adds the TypeDB function funcTQL to the schema, or replaces
+// the function of the same name if the schema already has one, so that
+// running it again after editing the function is safe. funcTQL holds exactly
+// one function definition ("fun name(...) -> ...: ...;"), with or without a
+// leading define keyword. It runs in its own schema transaction.
+func DefineFunction(ctx context.Context, db *Database, funcTQL string) error {
+	name, body, err := parseFunction(funcTQL)
+	if err != nil {
+		return fmt.Errorf("define function: %w", err)
+	}
+	existing, err := ListFunctions(ctx, db)
+	if err != nil {
+		return fmt.Errorf("define function %s: %w", name, err)
+	}
+	keyword := "define"
+	if slices.ContainsFunc(existing, func(f tqlgen.FunctionSpec) bool { return f.Name == name }) {
+		keyword = "redefine"
+	}
+	if err := db.ExecuteSchema(ctx, keyword+"\n"+body); err != nil {
+		return fmt.Errorf("define function %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListFunctions returns the functions defined in the database schema, with
+// their parameters, return type and annotations.
+func ListFunctions(ctx context.Context, db *Database) ([]tqlgen.FunctionSpec, error) {
+	schemaStr, err := db.Schema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list functions: %w", err)
+	}
+	schema, err := IntrospectSchemaFromString(schemaStr)
+	if err != nil {
+		return nil, fmt.Errorf("list functions: parse schema: %w", err)
+	}
+	return schema.Functions, nil
+}
+
+// UndefineFunction removes the function name from the schema. It runs in
+// its own schema transaction.
+func UndefineFunction(ctx context.Context, db *Database, name string) error {
+	if err := db.ExecuteSchema(ctx, RemoveFunction{Name: name}.ToTypeQL()); err != nil {
+		return fmt.Errorf("undefine function %s: %w", name, err)
+	}
+	return nil
+}
+
+// parseFunction checks that funcTQL defines exactly one function and nothing
+// else, and returns its name and its definition without the define keyword.
+func parseFunction(funcTQL string) (name, body string, err error) {
+	body = strings.TrimSpace(funcTQL)
+	if rest, ok := strings.CutPrefix(body, "define"); ok {
+		body = strings.TrimSpace(rest)
+	}
+	if !strings.HasPrefix(body, "fun ") {
+		return "", "", errors.New(`expected a function definition starting with "fun"`)
+	}
+	schema, err := tqlgen.ParseSchema("define\n" + body)
+	if err != nil {
+		return "", "", fmt.Errorf("parse: %w", err)
+	}
+	others := len(schema.Attributes) + len(schema.Entities) + len(schema.Relations) + len(schema.Structs)
+	if len(schema.Functions) != 1 || others > 0 {
+		return "", "", fmt.Errorf("expected exactly one function definition, got %d functions and %d other definitions", len(schema.Functions), others)
+	}
+	return schema.Functions[0].Name, body, nil
+}
@@ -0,0 +1,61 @@
+package gotype
+
+import (
+	"context"
+	"testing"
+)
+
+const adultsFunc = `fun adults($min: integer) -> { person }:
+  match $p isa person, has age $a; $a >= $min;
+  return { $p };`
+
+func TestDefineFunction(t *testing.T) {
+	ctx := context.Background()
+
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}, schemaStr: "define\nentity person;"}, "test_db")
+	if err := DefineFunction(ctx, db, adultsFunc); err != nil {
+		t.Fatalf("DefineFunction: %v", err)
+	}
+	if tx.queries[0] != "define\n"+adultsFunc || !tx.committed {
+		t.Errorf("got %q, committed %v", tx.queries[0], tx.committed)
+	}
+
+	// An existing function of the same name is replaced.
+	tx = &mockTx{}
+	db = NewDatabase(&mockConn{txs: []*mockTx{tx}, schemaStr: "define\nentity person;\n" + adultsFunc}, "test_db")
+	if err := DefineFunction(ctx, db, "define\n"+adultsFunc); err != nil {
+		t.Fatalf("DefineFunction: %v", err)
+	}
+	if tx.queries[0] != "redefine\n"+adultsFunc {
+		t.Errorf("got %q", tx.queries[0])
+	}
+
+	for _, bad := range []string{"entity person;", adultsFunc + "\nattribute age, value integer;"} {
+		if err := DefineFunction(ctx, db, bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestListFunctions(t *testing.T) {
+	db := NewDatabase(&mockConn{schemaStr: "define\nentity person;\n" + adultsFunc}, "test_db")
+	fns, err := ListFunctions(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fns) != 1 || fns[0].Name != "adults" || len(fns[0].Parameters) != 1 || fns[0].ReturnType != "{ person }" {
+		t.Errorf("got %+v", fns)
+	}
+}
+
+func TestUndefineFunction(t *testing.T) {
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db")
+	if err := UndefineFunction(context.Background(), db, "adults"); err != nil {
+		t.Fatal(err)
+	}
+	if tx.queries[0] != "undefine fun adults;" || !tx.committed {
+		t.Errorf("got %q, committed %v", tx.queries[0], tx.committed)
+	}
+}
@@ -0,0 +1,130 @@
+package gotypetest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultTypeDBImage is the TypeDB server image StartTypeDB runs: the
+// version the driver and the repository's integration tests are pinned to.
+// Set TYPEDB_IMAGE or use WithImage to test against another version.
+const DefaultTypeDBImage = "typedb/typedb:3.12.0-rc2"
+
+// container is a TypeDB server started with the docker or podman CLI.
+type container struct {
+	runtime string
+	id      string
+}
+
+// containerRuntime returns the container CLI to use: override when set,
+// otherwise docker or podman, whichever is installed.
+func containerRuntime(override string) (string, error) {
+	if override != "" {
+		return exec.LookPath(override)
+	}
+	for _, name := range []string{"docker", "podman"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.New("neither docker nor podman is installed")
+}
+
+// startContainer runs image detached, publishing the TypeDB port on a free
+// loopback port, and returns the container with the address to dial.
+func startContainer(ctx context.Context, runtime, image string) (*container, string, error) {
+	out, err := runCLI(ctx, runtime, "run", "-d", "--rm", "-p", "127.0.0.1::1729", image)
+	if err != nil {
+		return nil, "", fmt.Errorf("start %s: %w", image, err)
+	}
+	c := &container{runtime: runtime, id: strings.TrimSpace(out)}
+	out, err = runCLI(ctx, runtime, "port", c.id, "1729/tcp")
+	if err != nil {
+		c.stop()
+		return nil, "", fmt.Errorf("find the published port: %w", err)
+	}
+	addr, err := publishedAddress(out)
+	if err != nil {
+		c.stop()
+		return nil, "", err
+	}
+	return c, addr, nil
+}
+
+// publishedAddress picks the address to dial from the output of
+// "docker port", which lists one binding per line.
+func publishedAddress(out string) (string, error) {
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		host, port, err := net.SplitHostPort(strings.TrimSpace(line))
+		if err != nil || port == "" {
+			continue
+		}
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "127.0.0.1"
+		}
+		return net.JoinHostPort(host, port), nil
+	}
+	return "", fmt.Errorf("no published port in %q", out)
+}
+
+// logs returns the last lines the server printed, for startup failures.
+func (c *container) logs() string {
+	out, err := exec.Command(c.runtime, "logs", "--tail", "30", c.id).CombinedOutput()
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
+
+func (c *container) stop() {
+	runCLI(context.Background(), c.runtime, "rm", "-f", c.id)
+}
+
+func runCLI(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// testDatabaseName returns a database name unique to this run of the test:
+// its name, lowercased and shortened, and a random suffix.
+func testDatabaseName(testName string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(testName) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteByte('_')
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return "gotypetest_" + strings.Trim(b.String(), "_") + "_" + hex.EncodeToString(suffix)
+}
+
+// envOr returns the environment variable key, or def when it is unset.
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,39 @@
+package gotypetest
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPublishedAddress(t *testing.T) {
+	tests := []struct {
+		out, want string
+	}{
+		{"127.0.0.1:49153\n", "127.0.0.1:49153"},
+		{"0.0.0.0:32768\n[::]:32768\n", "127.0.0.1:32768"},
+		{"[::1]:40000", "[::1]:40000"},
+	}
+	for _, tt := range tests {
+		got, err := publishedAddress(tt.out)
+		if err != nil || got != tt.want {
+			t.Errorf("publishedAddress(%q) = %q, %v; want %q", tt.out, got, err, tt.want)
+		}
+	}
+	if _, err := publishedAddress(""); err == nil {
+		t.Error("publishedAddress with no binding should fail")
+	}
+}
+
+func TestTestDatabaseName(t *testing.T) {
+	name := testDatabaseName("TestSignup/Duplicate Email")
+	if !regexp.MustCompile(`^gotypetest_testsignup_duplicate_email_[0-9a-f]{8}$`).MatchString(name) {
+		t.Errorf("testDatabaseName = %q", name)
+	}
+	if name == testDatabaseName("TestSignup/Duplicate Email") {
+		t.Error("testDatabaseName should differ between calls")
+	}
+	if long := testDatabaseName(strings.Repeat("x", 100)); len(long) > 64 {
+		t.Errorf("testDatabaseName is not shortened: %q", long)
+	}
+}
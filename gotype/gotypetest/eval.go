@@ -0,0 +1,1031 @@
+package gotypetest
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// Variables are bound to instances, attributes, types or reduced values.
+type (
+	instRef string // IID of an entity or relation
+	typeRef string // type or role label
+	attrRef struct {
+		name string
+		val  value
+	}
+	valRef value
+)
+
+type binding map[string]any
+
+func (b binding) with(name string, c any) binding {
+	next := maps.Clone(b)
+	next[name] = c
+	return next
+}
+
+// evaluator runs one query against the snapshot of a transaction.
+type evaluator struct {
+	tx      *fakeTx
+	summary gotype.WriteSummary // instances inserted and deleted so far
+}
+
+func (e *evaluator) st() *store          { return e.tx.st }
+func (e *evaluator) model() *schemaModel { return e.tx.st.model }
+
+func (e *evaluator) run(q *query) ([]map[string]any, error) {
+	return e.runFrom(q, []binding{{}})
+}
+
+// runFrom runs the stages of q on rows, the bindings of an enclosing query
+// for a fetch subquery.
+func (e *evaluator) runFrom(q *query, rows []binding) ([]map[string]any, error) {
+	var err error
+	for i, s := range q.stages {
+		switch s.kind {
+		case "insert", "put", "delete", "update":
+			if e.tx.txType == gotype.ReadTransaction {
+				return nil, fmt.Errorf("%s is not allowed in a read transaction", s.kind)
+			}
+		case "fetch":
+			if i != len(q.stages)-1 {
+				return nil, errors.New("fetch must be the last stage")
+			}
+			return e.fetch(s.fetch, rows)
+		}
+		switch s.kind {
+		case "match":
+			rows, err = e.match(s.patterns, rows)
+		case "insert":
+			rows, err = e.insert(s.patterns, rows)
+		case "put":
+			rows, err = e.put(s.patterns, rows)
+		case "delete":
+			rows, err = e.delete(s.patterns, rows)
+		case "update":
+			rows, err = e.update(s.patterns, rows)
+		case "reduce":
+			rows, err = e.reduce(s, rows)
+		case "sort":
+			e.sort(s.sorts, rows)
+		case "offset":
+			rows = rows[min(s.n, len(rows)):]
+		case "limit":
+			rows = rows[:min(s.n, len(rows))]
+		case "distinct":
+			rows = distinct(rows)
+		case "select":
+			for i, row := range rows {
+				kept := binding{}
+				for _, v := range s.vars {
+					if c, ok := row[v]; ok {
+						kept[v] = c
+					}
+				}
+				rows[i] = kept
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	out := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		out[i] = make(map[string]any, len(row))
+		for name, c := range row {
+			out[i][name] = e.concept(c)
+		}
+	}
+	return out, nil
+}
+
+// concept renders a bound variable as the driver does in concept rows.
+func (e *evaluator) concept(c any) any {
+	switch c := c.(type) {
+	case instRef:
+		inst, err := e.st().get(string(c))
+		if err != nil {
+			return nil
+		}
+		return map[string]any{"_kind": e.model().kind(inst), "_type": inst.typ, "_iid": inst.iid}
+	case typeRef:
+		kind := e.model().kinds[string(c)]
+		if kind == "" {
+			kind = "role"
+		}
+		return map[string]any{"_kind": kind + "type", "_label": string(c)}
+	case attrRef:
+		return c.val.out()
+	case valRef:
+		return value(c).out()
+	}
+	return nil
+}
+
+func (e *evaluator) match(pats []pattern, rows []binding) ([]binding, error) {
+	var err error
+	for _, p := range pats {
+		if rows, err = e.pattern(p, rows); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func (e *evaluator) pattern(p pattern, rows []binding) ([]binding, error) {
+	switch p.kind {
+	case "stmt":
+		var err error
+		for _, c := range p.constraints {
+			if rows, err = e.constraint(p.subject, c, rows); err != nil {
+				return nil, err
+			}
+		}
+		return rows, nil
+	case "cmp":
+		var out []binding
+		for _, row := range rows {
+			ok, err := e.compare(p, row)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				out = append(out, row)
+			}
+		}
+		return out, nil
+	case "and":
+		return e.match(p.inner, rows)
+	case "or":
+		var out []binding
+		for _, row := range rows {
+			var found []binding
+			for _, branch := range p.branches {
+				r, err := e.match(branch, []binding{row})
+				if err != nil {
+					return nil, err
+				}
+				found = append(found, r...)
+			}
+			out = append(out, distinct(found)...)
+		}
+		return out, nil
+	case "not", "try":
+		var out []binding
+		for _, row := range rows {
+			r, err := e.match(p.inner, []binding{row})
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case p.kind == "not" && len(r) == 0, p.kind == "try" && len(r) == 0:
+				out = append(out, row)
+			case p.kind == "try":
+				out = append(out, r...)
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s is not allowed in match", p.kind)
+}
+
+// distinct drops the answers of a disjunction that repeat an earlier one
+// on the variables bound by every answer, as branch-local variables are not
+// part of TypeDB answers.
+func distinct(rows []binding) []binding {
+	if len(rows) < 2 {
+		return rows
+	}
+	var shared []string
+	for name := range rows[0] {
+		if !slices.ContainsFunc(rows, func(r binding) bool { _, ok := r[name]; return !ok }) {
+			shared = append(shared, name)
+		}
+	}
+	slices.Sort(shared)
+	seen := make(map[string]bool)
+	var out []binding
+	for _, r := range rows {
+		var key strings.Builder
+		for _, name := range shared {
+			fmt.Fprintf(&key, "%s=%#v;", name, r[name])
+		}
+		if !seen[key.String()] {
+			seen[key.String()] = true
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// resolve returns the value of a comparison operand.
+func (e *evaluator) resolve(o operand, row binding) (value, error) {
+	if o.lit != nil {
+		return *o.lit, nil
+	}
+	switch c := row[o.varName].(type) {
+	case attrRef:
+		return c.val, nil
+	case valRef:
+		return value(c), nil
+	case nil:
+		return value{}, fmt.Errorf("variable $%s is not bound", o.varName)
+	}
+	return value{}, fmt.Errorf("variable $%s is not a value", o.varName)
+}
+
+func (e *evaluator) compare(p pattern, row binding) (bool, error) {
+	left, err := e.resolve(p.left, row)
+	if err != nil {
+		return false, err
+	}
+	right, err := e.resolve(p.right, row)
+	if err != nil {
+		return false, err
+	}
+	switch p.op {
+	case "contains", "like":
+		s, ok1 := left.v.(string)
+		sub, ok2 := right.v.(string)
+		if !ok1 || !ok2 {
+			return false, fmt.Errorf("%s needs string operands", p.op)
+		}
+		if p.op == "contains" {
+			return strings.Contains(strings.ToLower(s), strings.ToLower(sub)), nil
+		}
+		re, err := regexp.Compile(sub)
+		if err != nil {
+			return false, fmt.Errorf("like: %w", err)
+		}
+		return re.MatchString(s), nil
+	}
+	c, ok := compare(left, right)
+	if !ok {
+		return p.op == "!=", nil
+	}
+	switch p.op {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+// instances returns the instances subject may stand for in row: the bound
+// one, or every stored instance.
+func (e *evaluator) instances(subject string, row binding) ([]*instance, error) {
+	switch c := row[subject].(type) {
+	case nil:
+		return e.st().all(), nil
+	case instRef:
+		inst, err := e.st().get(string(c))
+		if err != nil {
+			return nil, nil
+		}
+		return []*instance{inst}, nil
+	}
+	return nil, fmt.Errorf("variable $%s is not an entity or relation", subject)
+}
+
+func (e *evaluator) constraint(subject string, c constraint, rows []binding) ([]binding, error) {
+	m := e.model()
+	if c.label != "" && (c.kind == "isa" || c.kind == "isa!" || c.kind == "sub" || c.kind == "sub!") {
+		if err := m.checkType(c.label); err != nil {
+			return nil, err
+		}
+		if m.kinds[c.label] == "attribute" {
+			return nil, fmt.Errorf("matching attribute instances (%s) is not supported", c.label)
+		}
+	}
+	var out []binding
+	for _, row := range rows {
+		switch c.kind {
+		case "isa", "isa!":
+			insts, err := e.instances(subject, row)
+			if err != nil {
+				return nil, err
+			}
+			for _, inst := range insts {
+				out = append(out, e.isa(subject, inst, c, row)...)
+			}
+		case "iid":
+			insts, err := e.instances(subject, row)
+			if err != nil {
+				return nil, err
+			}
+			for _, inst := range insts {
+				if strings.EqualFold(inst.iid, c.label) {
+					out = append(out, row.with(subject, instRef(inst.iid)))
+				}
+			}
+		case "has":
+			insts, err := e.instances(subject, row)
+			if err != nil {
+				return nil, err
+			}
+			for _, inst := range insts {
+				r, err := e.has(subject, inst, c, row)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, r...)
+			}
+		case "links":
+			insts, err := e.instances(subject, row)
+			if err != nil {
+				return nil, err
+			}
+			for _, inst := range insts {
+				out = append(out, e.links(subject, inst, c.players, row)...)
+			}
+		case "sub", "sub!":
+			r, err := e.sub(subject, c, row)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, r...)
+		}
+	}
+	return out, nil
+}
+
+func (e *evaluator) isa(subject string, inst *instance, c constraint, row binding) []binding {
+	m := e.model()
+	row = row.with(subject, instRef(inst.iid))
+	if c.labelVar == "" {
+		if c.kind == "isa!" && inst.typ == c.label || c.kind == "isa" && m.isSubtype(inst.typ, c.label) {
+			return []binding{row}
+		}
+		return nil
+	}
+	switch bound := row[c.labelVar].(type) {
+	case typeRef:
+		if c.kind == "isa!" && inst.typ == string(bound) || c.kind == "isa" && m.isSubtype(inst.typ, string(bound)) {
+			return []binding{row}
+		}
+		return nil
+	case nil:
+		if c.kind == "isa!" {
+			return []binding{row.with(c.labelVar, typeRef(inst.typ))}
+		}
+		var out []binding
+		for t := inst.typ; t != ""; t = m.parents[t] {
+			out = append(out, row.with(c.labelVar, typeRef(t)))
+		}
+		return out
+	}
+	return nil
+}
+
+func (e *evaluator) has(subject string, inst *instance, c constraint, row binding) ([]binding, error) {
+	row = row.with(subject, instRef(inst.iid))
+	var want *value
+	if c.value.lit != nil {
+		v, err := e.literal(c.label, *c.value.lit)
+		if err != nil {
+			return nil, err
+		}
+		want = &v
+	} else if bound, ok := row[c.value.varName]; ok {
+		v, err := e.resolve(c.value, row)
+		if err != nil {
+			return nil, err
+		}
+		if a, ok := bound.(attrRef); ok && a.name != c.label {
+			return nil, nil
+		}
+		want = &v
+	}
+	var out []binding
+	for _, a := range inst.attrs {
+		switch {
+		case a.name != c.label:
+		case want != nil:
+			if equal(a.val, *want) {
+				return []binding{row}, nil
+			}
+		default:
+			out = append(out, row.with(c.value.varName, attrRef(a)))
+		}
+	}
+	return out, nil
+}
+
+func (e *evaluator) links(subject string, inst *instance, players []rolePlayer, row binding) []binding {
+	rows := []binding{row.with(subject, instRef(inst.iid))}
+	for _, rp := range players {
+		var next []binding
+		for _, r := range rows {
+			for _, l := range inst.links {
+				if rp.role != "" && l.role != rp.role {
+					continue
+				}
+				cand := r
+				if rp.roleVar != "" {
+					switch bound := cand[rp.roleVar].(type) {
+					case nil:
+						cand = cand.with(rp.roleVar, typeRef(l.role))
+					case typeRef:
+						if string(bound) != l.role {
+							continue
+						}
+					default:
+						continue
+					}
+				}
+				switch bound := cand[rp.player].(type) {
+				case nil:
+					cand = cand.with(rp.player, instRef(l.player))
+				case instRef:
+					if !strings.EqualFold(string(bound), l.player) {
+						continue
+					}
+				default:
+					continue
+				}
+				next = append(next, cand)
+			}
+		}
+		rows = next
+	}
+	return rows
+}
+
+func (e *evaluator) sub(subject string, c constraint, row binding) ([]binding, error) {
+	m := e.model()
+	matches := func(t string) bool {
+		if c.kind == "sub!" {
+			return m.parents[t] == c.label
+		}
+		return m.isSubtype(t, c.label)
+	}
+	switch bound := row[subject].(type) {
+	case typeRef:
+		if matches(string(bound)) {
+			return []binding{row}, nil
+		}
+		return nil, nil
+	case nil:
+		var out []binding
+		for _, t := range slices.Sorted(maps.Keys(m.kinds)) {
+			if matches(t) {
+				out = append(out, row.with(subject, typeRef(t)))
+			}
+		}
+		if !m.defined && c.kind == "sub" {
+			out = append(out, row.with(subject, typeRef(c.label)))
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("variable $%s is not a type", subject)
+}
+
+// literal coerces v to the value type of attr, once a schema is defined.
+func (e *evaluator) literal(attr string, v value) (value, error) {
+	m := e.model()
+	if !m.defined {
+		return v, nil
+	}
+	if m.kinds[attr] != "attribute" {
+		return value{}, fmt.Errorf("attribute type %s is not defined", attr)
+	}
+	v, err := coerce(v, m.valueTypes[attr])
+	if err != nil {
+		return value{}, fmt.Errorf("%s: %w", attr, err)
+	}
+	return v, nil
+}
+
+func (e *evaluator) insert(pats []pattern, rows []binding) ([]binding, error) {
+	out := make([]binding, 0, len(rows))
+	for _, row := range rows {
+		row = maps.Clone(row)
+		for _, p := range pats {
+			if p.kind != "stmt" {
+				return nil, fmt.Errorf("%s is not allowed in insert", p.kind)
+			}
+			if err := e.insertStatement(p, row); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+func (e *evaluator) insertStatement(p pattern, row binding) error {
+	m := e.model()
+	if _, bound := row[p.subject]; !bound {
+		label := ""
+		for _, c := range p.constraints {
+			if c.kind == "isa" || c.kind == "isa!" {
+				label = c.label
+			}
+		}
+		switch {
+		case label == "":
+			return fmt.Errorf("$%s is neither bound nor given a type", p.subject)
+		case m.defined && m.kinds[label] == "":
+			return fmt.Errorf("type %s is not defined", label)
+		case m.kinds[label] == "attribute":
+			return fmt.Errorf("inserting standalone attributes (%s) is not supported", label)
+		case m.abstract[label]:
+			return fmt.Errorf("type %s is abstract", label)
+		}
+		iid := e.tx.db.newIID()
+		if err := e.tx.apply(createInstance(iid, label)); err != nil {
+			return err
+		}
+		e.summary.Inserted++
+		row[p.subject] = instRef(iid)
+	}
+	owner, ok := row[p.subject].(instRef)
+	if !ok {
+		return fmt.Errorf("$%s is not an entity or relation", p.subject)
+	}
+	inst, err := e.st().get(string(owner))
+	if err != nil {
+		return err
+	}
+	for _, c := range p.constraints {
+		switch c.kind {
+		case "isa", "isa!":
+		case "has":
+			v, err := e.ownedValue(inst, c, row)
+			if err != nil {
+				return err
+			}
+			if err := e.tx.apply(addAttr(inst.iid, c.label, v)); err != nil {
+				return err
+			}
+		case "links":
+			for _, rp := range c.players {
+				player, role, err := e.rolePlayer(inst, rp, row)
+				if err != nil {
+					return err
+				}
+				if err := e.tx.apply(addLink(inst.iid, role, player)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("%s is not allowed in insert", c.kind)
+		}
+	}
+	return nil
+}
+
+// ownedValue checks that inst may own the attribute of a has constraint and
+// returns its value.
+func (e *evaluator) ownedValue(inst *instance, c constraint, row binding) (value, error) {
+	m := e.model()
+	if m.defined {
+		if _, ok := m.owns[inst.typ][c.label]; !ok {
+			return value{}, fmt.Errorf("%s does not own %s", inst.typ, c.label)
+		}
+	}
+	if c.value.lit != nil {
+		return e.literal(c.label, *c.value.lit)
+	}
+	v, err := e.resolve(c.value, row)
+	if err != nil {
+		return value{}, err
+	}
+	return e.literal(c.label, v)
+}
+
+func (e *evaluator) rolePlayer(rel *instance, rp rolePlayer, row binding) (player, role string, err error) {
+	role = rp.role
+	if rp.roleVar != "" {
+		r, ok := row[rp.roleVar].(typeRef)
+		if !ok {
+			return "", "", fmt.Errorf("role variable $%s is not bound", rp.roleVar)
+		}
+		role = string(r)
+	}
+	if role == "" {
+		return "", "", fmt.Errorf("$%s has no role", rp.player)
+	}
+	if m := e.model(); m.defined && !m.relates[rel.typ][role] {
+		return "", "", fmt.Errorf("%s does not relate %s", rel.typ, role)
+	}
+	p, ok := row[rp.player].(instRef)
+	if !ok {
+		return "", "", fmt.Errorf("role player $%s is not bound to an entity or relation", rp.player)
+	}
+	return string(p), role, nil
+}
+
+// put inserts the patterns for each row they do not already match.
+func (e *evaluator) put(pats []pattern, rows []binding) ([]binding, error) {
+	var out []binding
+	for _, row := range rows {
+		found, err := e.match(pats, []binding{row})
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			if found, err = e.insert(pats, []binding{row}); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, found[0])
+	}
+	return out, nil
+}
+
+func (e *evaluator) delete(pats []pattern, rows []binding) ([]binding, error) {
+	for _, row := range rows {
+		if err := e.deletePatterns(pats, row, false); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// deletePatterns deletes what pats name in row. In a try block, patterns
+// with unbound variables are skipped instead of failing.
+func (e *evaluator) deletePatterns(pats []pattern, row binding, optional bool) error {
+	for _, p := range pats {
+		owner, ok := row[p.subject].(instRef)
+		if p.kind != "try" && !ok {
+			if optional {
+				continue
+			}
+			return fmt.Errorf("$%s is not bound to an entity or relation", p.subject)
+		}
+		var err error
+		switch p.kind {
+		case "try":
+			err = e.deletePatterns(p.inner, row, true)
+		case "delete":
+			if _, exists := e.st().instances[string(owner)]; exists {
+				e.summary.Deleted++
+			}
+			err = e.tx.apply(deleteInstance(string(owner)))
+		case "unhas":
+			a, ok := row[p.left.varName].(attrRef)
+			if !ok {
+				if optional {
+					continue
+				}
+				return fmt.Errorf("$%s is not bound to an attribute", p.left.varName)
+			}
+			err = e.tx.apply(removeAttr(string(owner), a.name, a.val))
+		case "unlink":
+			for _, rp := range p.constraints[0].players {
+				player, ok := row[rp.player].(instRef)
+				if !ok {
+					return fmt.Errorf("role player $%s is not bound", rp.player)
+				}
+				role := rp.role
+				if r, ok := row[rp.roleVar].(typeRef); ok {
+					role = string(r)
+				}
+				if err = e.tx.apply(removeLink(string(owner), role, string(player))); err != nil {
+					break
+				}
+			}
+		default:
+			return fmt.Errorf("%s is not allowed in delete", p.kind)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// update replaces the values of the attributes and role players named in
+// each statement.
+func (e *evaluator) update(pats []pattern, rows []binding) ([]binding, error) {
+	for _, row := range rows {
+		for _, p := range pats {
+			owner, ok := row[p.subject].(instRef)
+			if p.kind != "stmt" || !ok {
+				return nil, fmt.Errorf("update needs statements on bound variables")
+			}
+			inst, err := e.st().get(string(owner))
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range p.constraints {
+				switch c.kind {
+				case "has":
+					v, err := e.ownedValue(inst, c, row)
+					if err != nil {
+						return nil, err
+					}
+					for _, a := range slices.Clone(inst.attrs) {
+						if a.name == c.label {
+							if err := e.tx.apply(removeAttr(inst.iid, a.name, a.val)); err != nil {
+								return nil, err
+							}
+						}
+					}
+					if err := e.tx.apply(addAttr(inst.iid, c.label, v)); err != nil {
+						return nil, err
+					}
+				case "links":
+					for _, rp := range c.players {
+						player, role, err := e.rolePlayer(inst, rp, row)
+						if err != nil {
+							return nil, err
+						}
+						for _, l := range slices.Clone(inst.links) {
+							if l.role == role {
+								if err := e.tx.apply(removeLink(inst.iid, l.role, l.player)); err != nil {
+									return nil, err
+								}
+							}
+						}
+						if err := e.tx.apply(addLink(inst.iid, role, player)); err != nil {
+							return nil, err
+						}
+					}
+				default:
+					return nil, fmt.Errorf("%s is not allowed in update", c.kind)
+				}
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (e *evaluator) fetch(entries []fetchEntry, rows []binding) ([]map[string]any, error) {
+	docs := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		doc, err := e.document(entries, row)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func (e *evaluator) document(entries []fetchEntry, row binding) (map[string]any, error) {
+	doc := make(map[string]any, len(entries))
+	for _, f := range entries {
+		if f.expr == "object" {
+			nested, err := e.document(f.nested, row)
+			if err != nil {
+				return nil, err
+			}
+			doc[f.key] = nested
+			continue
+		}
+		if f.expr == "subquery" {
+			docs, err := e.runFrom(f.sub, []binding{row})
+			if err != nil {
+				return nil, err
+			}
+			list := make([]any, len(docs))
+			for i, d := range docs {
+				list[i] = d
+			}
+			doc[f.key] = list
+			continue
+		}
+		c := row[f.varName]
+		if f.expr == "var" {
+			doc[f.key] = e.concept(c)
+			continue
+		}
+		if t, ok := c.(typeRef); ok && f.expr == "label" {
+			doc[f.key] = string(t)
+			continue
+		}
+		ref, ok := c.(instRef)
+		if !ok {
+			if c != nil {
+				return nil, fmt.Errorf("fetch: $%s is not an entity or relation", f.varName)
+			}
+			doc[f.key] = nil
+			continue
+		}
+		inst, err := e.st().get(string(ref))
+		if err != nil {
+			doc[f.key] = nil
+			continue
+		}
+		switch f.expr {
+		case "iid":
+			doc[f.key] = inst.iid
+		case "label":
+			doc[f.key] = inst.typ
+		case "attr", "attrs":
+			values := []any{}
+			for _, a := range inst.attrs {
+				if a.name == f.attr {
+					values = append(values, a.val.out())
+				}
+			}
+			switch {
+			case f.expr == "attrs":
+				doc[f.key] = values
+			case len(values) > 1:
+				return nil, fmt.Errorf("fetch: %s owns several %s; fetch them as a list", inst.typ, f.attr)
+			case len(values) == 1:
+				doc[f.key] = values[0]
+			default:
+				doc[f.key] = nil
+			}
+		case "all":
+			all := e.allAttributes(inst)
+			if f.key == "" {
+				maps.Copy(doc, all)
+			} else {
+				doc[f.key] = all
+			}
+		}
+	}
+	return doc, nil
+}
+
+// allAttributes renders $e.*: single values for attributes owned at most
+// once, lists otherwise.
+func (e *evaluator) allAttributes(inst *instance) map[string]any {
+	grouped := make(map[string][]any)
+	for _, a := range inst.attrs {
+		grouped[a.name] = append(grouped[a.name], a.val.out())
+	}
+	out := make(map[string]any, len(grouped))
+	for name, values := range grouped {
+		if e.model().multiValued(inst.typ, name, len(values)) {
+			out[name] = values
+		} else {
+			out[name] = values[0]
+		}
+	}
+	return out
+}
+
+func (e *evaluator) reduce(s stage, rows []binding) ([]binding, error) {
+	type group struct {
+		key  binding
+		rows []binding
+	}
+	var groups []*group
+	index := make(map[string]*group)
+	for _, row := range rows {
+		key, keyText := binding{}, ""
+		for _, v := range s.groupBy {
+			key[v] = row[v]
+			keyText += fmt.Sprintf("%#v\x00", row[v])
+		}
+		g, ok := index[keyText]
+		if !ok {
+			g = &group{key: key}
+			index[keyText] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, row)
+	}
+	if len(groups) == 0 && len(s.groupBy) == 0 {
+		groups = append(groups, &group{key: binding{}})
+	}
+
+	out := make([]binding, 0, len(groups))
+	for _, g := range groups {
+		res := g.key
+		for _, r := range s.reduces {
+			v, ok, err := e.reducer(r, g.rows)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				res[r.varName] = valRef(v)
+			}
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+// reducer computes one reduce assignment; ok is false when the result is
+// empty, as for the mean of no values.
+func (e *evaluator) reducer(r reduceAssign, rows []binding) (value, bool, error) {
+	if r.fn == "count" {
+		n := 0
+		for _, row := range rows {
+			if _, ok := row[r.arg]; ok || r.arg == "" {
+				n++
+			}
+		}
+		return value{kind: "integer", v: int64(n)}, true, nil
+	}
+	var nums []float64
+	allInts := true
+	for _, row := range rows {
+		if row[r.arg] == nil {
+			continue
+		}
+		v, err := e.resolve(operand{varName: r.arg}, row)
+		if err != nil {
+			return value{}, false, err
+		}
+		n, ok := number(v)
+		if !ok {
+			return value{}, false, fmt.Errorf("%s($%s): %s values are not numeric", r.fn, r.arg, v.kind)
+		}
+		allInts = allInts && v.kind == "integer"
+		nums = append(nums, n)
+	}
+	if len(nums) == 0 {
+		if r.fn == "sum" {
+			return value{kind: "integer", v: int64(0)}, true, nil
+		}
+		return value{}, false, nil
+	}
+	slices.Sort(nums)
+	var sum float64
+	for _, n := range nums {
+		sum += n
+	}
+	mean := sum / float64(len(nums))
+	var result float64
+	keepInt := false
+	switch r.fn {
+	case "sum":
+		result, keepInt = sum, allInts
+	case "min":
+		result, keepInt = nums[0], allInts
+	case "max":
+		result, keepInt = nums[len(nums)-1], allInts
+	case "mean":
+		result = mean
+	case "median":
+		mid := len(nums) / 2
+		result = nums[mid]
+		if len(nums)%2 == 0 {
+			result = (nums[mid-1] + nums[mid]) / 2
+		}
+	case "std", "variance":
+		if len(nums) < 2 {
+			return value{}, false, nil
+		}
+		var sq float64
+		for _, n := range nums {
+			sq += (n - mean) * (n - mean)
+		}
+		result = sq / float64(len(nums)-1)
+		if r.fn == "std" {
+			result = math.Sqrt(result)
+		}
+	}
+	if keepInt {
+		return value{kind: "integer", v: int64(result)}, true, nil
+	}
+	return value{kind: "double", v: result}, true, nil
+}
+
+func (e *evaluator) sort(keys []sortKey, rows []binding) {
+	slices.SortStableFunc(rows, func(a, b binding) int {
+		for _, k := range keys {
+			c := e.sortCompare(a[k.varName], b[k.varName])
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// sortCompare orders bound variables; unbound ones sort last.
+func (e *evaluator) sortCompare(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	va, errA := e.resolve(operand{varName: "a"}, binding{"a": a})
+	vb, errB := e.resolve(operand{varName: "b"}, binding{"b": b})
+	if errA != nil || errB != nil {
+		return cmp.Compare(fmt.Sprint(e.concept(a)), fmt.Sprint(e.concept(b)))
+	}
+	c, _ := compare(va, vb)
+	return c
+}
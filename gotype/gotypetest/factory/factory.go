@@ -0,0 +1,350 @@
+// Package factory builds random but valid model instances for load tests
+// and integration suites that need many plausible entities:
+//
+//	f, err := factory.New(db.Registry(),
+//		factory.WithSeed(42),
+//		factory.WithEnums(schema.AttributeEnumValues), // tqlgen registry output
+//		factory.WithRegexes(schema.AttributeRegex),
+//	)
+//	people, err := factory.For[Person](f).
+//		With(func(p *Person) { p.Country = "NL" }).
+//		BuildN(10_000)
+//	err = persons.InsertMany(ctx, people)
+//
+// Values follow each field's value type and cardinality, the @values and
+// @regex constraints passed as options, and @key and @unique: a Factory
+// never generates the same value twice for a key or unique attribute. The
+// same seed produces the same instances, so failures are reproducible.
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// Factory generates attribute values for the models of a registry. It is
+// safe for concurrent use, but instances built concurrently are only
+// reproducible when built in the same order.
+type Factory struct {
+	reg          *gotype.Registry
+	seed         uint64
+	optionalRate float64
+	enums        map[string][]string
+	regexSource  map[string]string
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	patterns map[string]*pattern
+	used     map[string]map[any]bool // key and unique attribute -> values generated
+	seq      map[string]int          // attribute -> values generated
+}
+
+// Option configures a Factory.
+type Option func(*Factory)
+
+// WithSeed sets the random seed (default 1).
+func WithSeed(seed uint64) Option {
+	return func(f *Factory) { f.seed = seed }
+}
+
+// WithEnums restricts attributes to the given values, as @values does. The
+// AttributeEnumValues map of a tqlgen registry can be passed directly.
+func WithEnums(values map[string][]string) Option {
+	return func(f *Factory) { f.enums = values }
+}
+
+// WithRegexes makes attribute values match the given patterns, as @regex
+// does. The AttributeRegex map of a tqlgen registry can be passed directly.
+func WithRegexes(patterns map[string]string) Option {
+	return func(f *Factory) { f.regexSource = patterns }
+}
+
+// WithOptionalRate sets the probability that an optional attribute, a
+// pointer field or one with a minimum cardinality of 0, gets a value
+// (default 0.8).
+func WithOptionalRate(p float64) Option {
+	return func(f *Factory) { f.optionalRate = p }
+}
+
+// New returns a Factory for the models registered in reg, or in the global
+// registry when reg is nil. It fails if a pattern does not compile.
+func New(reg *gotype.Registry, opts ...Option) (*Factory, error) {
+	f := &Factory{
+		reg:          reg,
+		seed:         1,
+		optionalRate: 0.8,
+		patterns:     make(map[string]*pattern),
+		used:         make(map[string]map[any]bool),
+		seq:          make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	for attr, expr := range f.regexSource {
+		p, err := compilePattern(expr)
+		if err != nil {
+			return nil, fmt.Errorf("factory: regex for %s: %w", attr, err)
+		}
+		f.patterns[attr] = p
+	}
+	f.rng = rand.New(rand.NewPCG(f.seed, f.seed^0x9e3779b97f4a7c15))
+	return f, nil
+}
+
+// Builder builds instances of one model type. Builders are immutable:
+// With returns a new Builder.
+type Builder[T any] struct {
+	f      *Factory
+	tweaks []func(*T)
+}
+
+// For returns a Builder for T, which must be registered in the factory's
+// registry.
+func For[T any](f *Factory) *Builder[T] {
+	return &Builder[T]{f: f}
+}
+
+// With returns a Builder that also applies fn to every instance after
+// generating its attributes, to set role players or fix values. Values set
+// by fn are not checked for uniqueness.
+func (b *Builder[T]) With(fn func(*T)) *Builder[T] {
+	tweaks := append(b.tweaks[:len(b.tweaks):len(b.tweaks)], fn)
+	return &Builder[T]{f: b.f, tweaks: tweaks}
+}
+
+// Build returns one instance. Role players of relations are left nil; set
+// them with With.
+func (b *Builder[T]) Build() (*T, error) {
+	info, err := b.f.lookup(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	return b.build(info)
+}
+
+// BuildN returns n instances.
+func (b *Builder[T]) BuildN(n int) ([]*T, error) {
+	info, err := b.f.lookup(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*T, n)
+	for i := range out {
+		if out[i], err = b.build(info); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (b *Builder[T]) build(info *gotype.ModelInfo) (*T, error) {
+	v := new(T)
+	if err := b.f.fill(reflect.ValueOf(v).Elem(), info); err != nil {
+		return nil, err
+	}
+	for _, fn := range b.tweaks {
+		fn(v)
+	}
+	return v, nil
+}
+
+func (f *Factory) lookup(t reflect.Type) (*gotype.ModelInfo, error) {
+	var info *gotype.ModelInfo
+	var ok bool
+	if f.reg != nil {
+		info, ok = f.reg.LookupType(t)
+	} else {
+		info, ok = gotype.LookupType(t)
+	}
+	if !ok {
+		return nil, &gotype.NotRegisteredError{TypeName: t.Name()}
+	}
+	if info.IsAbstract {
+		return nil, fmt.Errorf("factory: %s is abstract", info.TypeName)
+	}
+	return info, nil
+}
+
+// fill sets every attribute field of v.
+func (f *Factory) fill(v reflect.Value, info *gotype.ModelInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, fi := range info.Fields {
+		if fi.Tag.AutoCreate || fi.Tag.AutoUpdate {
+			continue // set by gotype on write
+		}
+		field := v.FieldByName(fi.FieldName)
+		if !field.IsValid() || !field.CanSet() {
+			continue
+		}
+		if err := f.fillField(field, fi); err != nil {
+			return fmt.Errorf("factory: %s.%s: %w", info.TypeName, fi.Tag.Name, err)
+		}
+	}
+	return nil
+}
+
+func (f *Factory) fillField(field reflect.Value, fi gotype.FieldInfo) error {
+	required := fi.Tag.Key || (fi.Tag.CardMin != nil && *fi.Tag.CardMin > 0) ||
+		(!fi.IsPointer && !fi.IsSlice && fi.Tag.CardMin == nil)
+	if !required && f.rng.Float64() >= f.optionalRate {
+		return nil
+	}
+
+	switch {
+	case fi.IsSlice:
+		lo, hi := 1, 3
+		if fi.Tag.CardMin != nil {
+			lo = *fi.Tag.CardMin
+		}
+		if fi.Tag.CardMax != nil {
+			hi = *fi.Tag.CardMax
+		}
+		hi = max(lo, min(hi, lo+3))
+		n := lo + f.rng.IntN(hi-lo+1)
+		slice := reflect.MakeSlice(field.Type(), 0, n)
+		for range n {
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if ok, err := f.value(elem, fi); err != nil || !ok {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		field.Set(slice)
+	case fi.IsPointer:
+		elem := reflect.New(field.Type().Elem())
+		if ok, err := f.value(elem.Elem(), fi); err != nil || !ok {
+			return err
+		}
+		field.Set(elem)
+	default:
+		if _, err := f.value(field, fi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errExhausted reports that no unused value could be found for a key or
+// unique attribute.
+var errExhausted = errors.New("no unused value left for a key or unique attribute")
+
+// value sets v to a generated value for the field. It reports false, and
+// leaves v unset, for Go types it does not generate.
+func (f *Factory) value(v reflect.Value, fi gotype.FieldInfo) (bool, error) {
+	attr := fi.Tag.Name
+	unique := fi.Tag.Key || fi.Tag.Unique
+	for range 100 {
+		f.seq[attr]++
+		ok, err := f.generate(v, attr, fi.ValueType, unique)
+		if err != nil || !ok {
+			return ok, err
+		}
+		if !unique {
+			return true, nil
+		}
+		used := f.used[attr]
+		if used == nil {
+			used = make(map[any]bool)
+			f.used[attr] = used
+		}
+		if key := v.Interface(); !used[key] {
+			used[key] = true
+			return true, nil
+		}
+	}
+	return false, errExhausted
+}
+
+// epoch is the start of the range generated datetimes fall in.
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (f *Factory) generate(v reflect.Value, attr, valueType string, unique bool) (bool, error) {
+	if v.Kind() == reflect.String {
+		if values := f.enums[attr]; len(values) > 0 {
+			v.SetString(values[f.rng.IntN(len(values))])
+			return true, nil
+		}
+		if p := f.patterns[attr]; p != nil {
+			s, err := p.generate(f.rng)
+			v.SetString(s)
+			return err == nil, err
+		}
+	}
+
+	seq := f.seq[attr]
+	switch v.Kind() {
+	case reflect.String:
+		s := f.word()
+		if unique {
+			s += "-" + strconv.Itoa(seq)
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		v.SetBool(f.rng.IntN(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := int64(seq)
+		if !unique {
+			n = f.rng.Int64N(1000)
+		}
+		if v.OverflowInt(n) {
+			return false, errExhausted
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := uint64(seq)
+		if !unique {
+			n = f.rng.Uint64N(1000)
+		}
+		if v.OverflowUint(n) {
+			return false, errExhausted
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		x := math.Round(f.rng.Float64()*100_000) / 100
+		if unique {
+			x += float64(seq) * 1000
+		}
+		v.SetFloat(x)
+	default:
+		if v.Type() != reflect.TypeFor[time.Time]() {
+			return false, nil
+		}
+		t := epoch.Add(time.Duration(f.rng.Int64N(5*365*24*3600)) * time.Second)
+		if unique {
+			t = epoch.Add(time.Duration(seq) * time.Second)
+		}
+		if valueType == "date" {
+			t = t.Truncate(24 * time.Hour)
+			if unique {
+				t = epoch.AddDate(0, 0, seq)
+			}
+		}
+		v.Set(reflect.ValueOf(t))
+	}
+	return true, nil
+}
+
+var (
+	consonants = []string{"b", "d", "f", "g", "k", "l", "m", "n", "p", "r", "s", "t", "v", "z", "ch", "th"}
+	vowels     = []string{"a", "e", "i", "o", "u", "ai", "ei"}
+)
+
+// word returns a pronounceable, capitalized made-up word.
+func (f *Factory) word() string {
+	var b []byte
+	for range 2 + f.rng.IntN(2) {
+		b = append(b, consonants[f.rng.IntN(len(consonants))]...)
+		b = append(b, vowels[f.rng.IntN(len(vowels))]...)
+	}
+	b[0] -= 'a' - 'A'
+	return string(b)
+}
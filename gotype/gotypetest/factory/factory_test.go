@@ -0,0 +1,190 @@
+package factory_test
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest/factory"
+)
+
+type person struct {
+	gotype.BaseEntity
+	Name      string     `typedb:"name,key"`
+	Email     string     `typedb:"email,unique"`
+	Status    string     `typedb:"status"`
+	Code      *string    `typedb:"code,card=0..1"`
+	Age       int        `typedb:"age"`
+	Score     float64    `typedb:"score"`
+	Active    bool       `typedb:"active"`
+	Tags      []string   `typedb:"tag,card=1..3"`
+	BirthDate time.Time  `typedb:"birth-date"`
+	CreatedAt time.Time  `typedb:"created-at,autocreate"`
+	UpdatedAt *time.Time `typedb:"updated-at,autoupdate"`
+}
+
+type company struct {
+	gotype.BaseEntity
+	ID int `typedb:"company-id,key"`
+}
+
+type employment struct {
+	gotype.BaseRelation
+	Employee *person  `typedb:"role:employee"`
+	Employer *company `typedb:"role:employer"`
+	Since    int      `typedb:"since"`
+}
+
+var (
+	statuses = []string{"active", "suspended", "closed"}
+	codeExpr = `^[A-Z]{3}-\d{2,4}$`
+)
+
+func newFactory(t *testing.T, opts ...factory.Option) (*factory.Factory, *gotype.Registry) {
+	t.Helper()
+	reg := gotype.NewRegistry()
+	for _, err := range []error{
+		gotype.RegisterIn[person](reg),
+		gotype.RegisterIn[company](reg),
+		gotype.RegisterIn[employment](reg),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts = append([]factory.Option{
+		factory.WithEnums(map[string][]string{"status": statuses}),
+		factory.WithRegexes(map[string]string{"code": codeExpr}),
+	}, opts...)
+	f, err := factory.New(reg, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, reg
+}
+
+func TestFactory_Deterministic(t *testing.T) {
+	build := func(seed uint64) []*person {
+		f, _ := newFactory(t, factory.WithSeed(seed))
+		people, err := factory.For[person](f).BuildN(50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return people
+	}
+	if a, b := build(7), build(7); !reflect.DeepEqual(a, b) {
+		t.Error("same seed built different instances")
+	}
+	if a, b := build(7), build(8); reflect.DeepEqual(a, b) {
+		t.Error("different seeds built the same instances")
+	}
+}
+
+func TestFactory_Constraints(t *testing.T) {
+	f, _ := newFactory(t, factory.WithOptionalRate(0.5))
+	people, err := factory.For[person](f).BuildN(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := regexp.MustCompile(codeExpr)
+	names := make(map[string]bool)
+	emails := make(map[string]bool)
+	var withCode int
+	for _, p := range people {
+		if p.Name == "" || names[p.Name] {
+			t.Fatalf("key name %q is empty or repeated", p.Name)
+		}
+		names[p.Name] = true
+		if emails[p.Email] {
+			t.Fatalf("unique email %q repeated", p.Email)
+		}
+		emails[p.Email] = true
+		if !slices.Contains(statuses, p.Status) {
+			t.Errorf("status %q not in %v", p.Status, statuses)
+		}
+		if p.Code != nil {
+			withCode++
+			if !code.MatchString(*p.Code) {
+				t.Errorf("code %q does not match %s", *p.Code, codeExpr)
+			}
+		}
+		if len(p.Tags) < 1 || len(p.Tags) > 3 {
+			t.Errorf("%d tags, want 1..3", len(p.Tags))
+		}
+		if p.BirthDate.IsZero() || p.BirthDate.Nanosecond() != 0 {
+			t.Errorf("birth date %v not set to whole seconds", p.BirthDate)
+		}
+		if !p.CreatedAt.IsZero() || p.UpdatedAt != nil {
+			t.Error("autocreate and autoupdate fields should be left to gotype")
+		}
+	}
+	if withCode < 400 || withCode > 600 {
+		t.Errorf("%d of 1000 optional codes set, want about 500", withCode)
+	}
+}
+
+func TestFactory_WithAndRelations(t *testing.T) {
+	f, _ := newFactory(t)
+	alice, err := factory.For[person](f).With(func(p *person) { p.Name = "Alice" }).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alice.Name != "Alice" {
+		t.Errorf("Name = %q, want Alice", alice.Name)
+	}
+	acme, err := factory.For[company](f).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := factory.For[employment](f).
+		With(func(e *employment) { e.Employee, e.Employer = alice, acme }).
+		BuildN(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobs[0].Employee != alice || jobs[1].Employer != acme {
+		t.Error("role players not set by With")
+	}
+}
+
+func TestFactory_Errors(t *testing.T) {
+	if _, err := factory.New(nil, factory.WithRegexes(map[string]string{"code": "("})); err == nil {
+		t.Error("invalid regex: want error")
+	}
+	f, _ := newFactory(t)
+	if _, err := factory.For[struct{ gotype.BaseEntity }](f).Build(); err == nil {
+		t.Error("unregistered type: want error")
+	}
+	small, _ := newFactory(t, factory.WithEnums(map[string][]string{"name": {"only"}}))
+	if _, err := factory.For[person](small).BuildN(2); err == nil {
+		t.Error("key with one allowed value: want error on second instance")
+	}
+}
+
+func TestFactory_InsertIntoFakeDB(t *testing.T) {
+	f, reg := newFactory(t)
+	db := gotype.NewDatabase(gotypetest.NewFakeDB(), "test").WithRegistry(reg)
+	ctx := context.Background()
+	if err := db.ExecuteSchema(ctx, reg.GenerateSchema()); err != nil {
+		t.Fatal(err)
+	}
+	people, err := factory.For[person](f).BuildN(200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr, err := gotype.NewManager[person](db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.InsertMany(ctx, people); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := mgr.Query().Count(ctx); err != nil || n != 200 {
+		t.Errorf("Count = %d, %v; want 200", n, err)
+	}
+}
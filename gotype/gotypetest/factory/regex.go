@@ -0,0 +1,108 @@
+package factory
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// pattern generates strings matching a regular expression.
+type pattern struct {
+	re   *regexp.Regexp
+	tree *syntax.Regexp
+}
+
+func compilePattern(expr string) (*pattern, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	return &pattern{re: re, tree: tree.Simplify()}, nil
+}
+
+// generate returns a random string matching the pattern. Word boundaries
+// and similar assertions are not planned for, so candidates are checked and
+// a few attempts are made.
+func (p *pattern) generate(rng *rand.Rand) (string, error) {
+	for range 20 {
+		var b strings.Builder
+		generateRegex(&b, p.tree, rng)
+		if s := b.String(); p.re.MatchString(s) {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("cannot generate a value matching %q", p.re)
+}
+
+// maxExtraRepeats bounds the repetitions added to *, + and open {n,}.
+const maxExtraRepeats = 3
+
+func generateRegex(b *strings.Builder, re *syntax.Regexp, rng *rand.Rand) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(pickFromClass(re.Rune, rng))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+		b.WriteByte(alphabet[rng.IntN(len(alphabet))])
+	case syntax.OpCapture:
+		generateRegex(b, re.Sub[0], rng)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			generateRegex(b, sub, rng)
+		}
+	case syntax.OpAlternate:
+		generateRegex(b, re.Sub[rng.IntN(len(re.Sub))], rng)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := repeatBounds(re)
+		for range lo + rng.IntN(hi-lo+1) {
+			generateRegex(b, re.Sub[0], rng)
+		}
+	}
+	// Anchors, boundaries and empty matches produce no text.
+}
+
+func repeatBounds(re *syntax.Regexp) (lo, hi int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, maxExtraRepeats
+	case syntax.OpPlus:
+		return 1, 1 + maxExtraRepeats
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	if re.Max < 0 {
+		return re.Min, re.Min + maxExtraRepeats
+	}
+	return re.Min, re.Max
+}
+
+// pickFromClass picks a rune from a character class, given as range
+// pairs, preferring printable ASCII so values stay readable.
+func pickFromClass(ranges []rune, rng *rand.Rand) rune {
+	var ascii []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], ' '), min(ranges[i+1], '~')
+		if lo <= hi {
+			ascii = append(ascii, lo, hi)
+		}
+	}
+	if len(ascii) > 0 {
+		ranges = ascii
+	}
+	i := 2 * rng.IntN(len(ranges)/2)
+	lo, hi := ranges[i], min(ranges[i+1], unicode.MaxRune)
+	r := lo + rng.Int32N(hi-lo+1)
+	if r >= 0xD800 && r <= 0xDFFF { // surrogates are not valid runes
+		return lo
+	}
+	return r
+}
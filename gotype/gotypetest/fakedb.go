@@ -0,0 +1,647 @@
+// Package gotypetest provides test helpers for code built on gotype.
+//
+// FakeDB is an in-memory gotype.Conn that stores entities, relations and
+// their attributes and answers the queries gotype generates, so business
+// logic using Managers can be unit tested without a TypeDB server:
+//
+//	fake := gotypetest.NewFakeDB()
+//	db := gotype.NewDatabase(fake, "test")
+//	if err := db.ExecuteSchema(ctx, gotype.GenerateSchema()); err != nil {
+//		t.Fatal(err)
+//	}
+//	persons := gotype.MustNewManager[Person](db)
+//
+// StartTypeDB, built with the "cgo" and "typedb" tags like the driver, runs
+// integration tests against a real server in a throwaway container.
+package gotypetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/tqlgen"
+)
+
+// FakeDB is an in-memory gotype.Conn. It understands the TypeQL subset that
+// gotype generates: match with isa, isa!, has, iid, links and sub
+// statements, comparisons, or/not/try blocks; insert, put, delete and
+// update; fetch with list subqueries, reduce, sort, offset, limit, select
+// and distinct. Other queries fail with an error naming the unsupported
+// construct.
+//
+// Databases are created on first use. Once a schema is defined, types,
+// ownerships, value types, @key and @unique are checked like TypeDB does;
+// without one, any type and attribute is accepted.
+//
+// Each transaction works on a snapshot of the database. Committing a write
+// replays its changes on the current state, so concurrent transactions only
+// conflict when they touch the same instance or violate a key. A FakeDB is
+// safe for concurrent use.
+type FakeDB struct {
+	mu      sync.Mutex
+	dbs     map[string]*store
+	nextIID atomic.Uint64
+	closed  atomic.Bool
+}
+
+// NewFakeDB returns an empty FakeDB.
+func NewFakeDB() *FakeDB {
+	return &FakeDB{dbs: make(map[string]*store)}
+}
+
+var _ gotype.Conn = (*FakeDB)(nil)
+
+// Transaction opens a transaction on a snapshot of dbName, creating the
+// database if needed.
+func (f *FakeDB) Transaction(dbName string, txType int) (gotype.Tx, error) {
+	if f.closed.Load() {
+		return nil, errors.New("gotypetest: connection is closed")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st, ok := f.dbs[dbName]
+	if !ok {
+		st = newStore()
+		f.dbs[dbName] = st
+	}
+	return &fakeTx{db: f, name: dbName, txType: gotype.TransactionType(txType), st: st.clone(), open: true}, nil
+}
+
+// Schema returns the schema defined in dbName, normalized by
+// gotype.NormalizeSchema.
+func (f *FakeDB) Schema(dbName string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st, ok := f.dbs[dbName]
+	if !ok {
+		return "", fmt.Errorf("gotypetest: database %s does not exist", dbName)
+	}
+	return st.schemaText, nil
+}
+
+// DatabaseCreate creates an empty database.
+func (f *FakeDB) DatabaseCreate(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.dbs[name]; ok {
+		return fmt.Errorf("gotypetest: database %s already exists", name)
+	}
+	f.dbs[name] = newStore()
+	return nil
+}
+
+// DatabaseDelete removes a database and its data.
+func (f *FakeDB) DatabaseDelete(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.dbs[name]; !ok {
+		return fmt.Errorf("gotypetest: database %s does not exist", name)
+	}
+	delete(f.dbs, name)
+	return nil
+}
+
+// DatabaseContains reports whether the database exists.
+func (f *FakeDB) DatabaseContains(name string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.dbs[name]
+	return ok, nil
+}
+
+// DatabaseAll returns the database names in order.
+func (f *FakeDB) DatabaseAll() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Sorted(maps.Keys(f.dbs)), nil
+}
+
+// Close makes later transactions fail. Data is kept.
+func (f *FakeDB) Close() { f.closed.Store(true) }
+
+// IsOpen reports whether Close has not been called.
+func (f *FakeDB) IsOpen() bool { return !f.closed.Load() }
+
+func (f *FakeDB) newIID() string {
+	return fmt.Sprintf("0x1e%020x", f.nextIID.Add(1))
+}
+
+// fakeTx runs queries against a private snapshot and records every change
+// so that Commit can replay it on the current database.
+type fakeTx struct {
+	db      *FakeDB
+	name    string
+	txType  gotype.TransactionType
+	mu      sync.Mutex
+	st      *store
+	journal []mutation
+	open    bool
+}
+
+// mutation is one change, applied to the snapshot when the query runs and
+// again to the database on commit.
+type mutation func(*store) error
+
+func (t *fakeTx) Query(query string) ([]map[string]any, error) {
+	return t.QueryWithContext(context.Background(), query)
+}
+
+func (t *fakeTx) QueryWithContext(ctx context.Context, text string) ([]map[string]any, error) {
+	res, err := t.QueryResult(ctx, text)
+	return res.Rows, err
+}
+
+// QueryResult implements gotype.ResultTx. Queries in write and schema
+// transactions report the entity and relation instances they inserted and
+// deleted; relations removed because they lost their last role player are
+// not counted.
+func (t *fakeTx) QueryResult(ctx context.Context, text string) (gotype.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return gotype.Result{}, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.open {
+		return gotype.Result{}, errors.New("gotypetest: transaction is closed")
+	}
+	q, err := parseQuery(text)
+	if err != nil {
+		return gotype.Result{}, fmt.Errorf("gotypetest: %w", err)
+	}
+	var summary *gotype.WriteSummary
+	if t.txType != gotype.ReadTransaction {
+		summary = &gotype.WriteSummary{}
+	}
+	if q.schemaOp != "" {
+		if t.txType != gotype.SchemaTransaction {
+			return gotype.Result{}, fmt.Errorf("gotypetest: %s requires a schema transaction", q.schemaOp)
+		}
+		if q.schemaOp != "define" {
+			return gotype.Result{}, fmt.Errorf("gotypetest: %s is not supported", q.schemaOp)
+		}
+		return gotype.Result{Summary: summary}, t.apply(func(s *store) error { return s.define(text) })
+	}
+	e := &evaluator{tx: t}
+	rows, err := e.run(q)
+	if err != nil {
+		return gotype.Result{}, fmt.Errorf("gotypetest: %w", err)
+	}
+	if summary != nil {
+		*summary = e.summary
+	}
+	return gotype.Result{Rows: rows, Summary: summary}, nil
+}
+
+// apply runs m on the snapshot and records it for Commit.
+func (t *fakeTx) apply(m mutation) error {
+	if err := m(t.st); err != nil {
+		return err
+	}
+	t.journal = append(t.journal, m)
+	return nil
+}
+
+func (t *fakeTx) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.open {
+		return errors.New("gotypetest: transaction is closed")
+	}
+	t.open = false
+	if t.txType == gotype.ReadTransaction || len(t.journal) == 0 {
+		return nil
+	}
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	live, ok := t.db.dbs[t.name]
+	if !ok {
+		return fmt.Errorf("gotypetest: database %s was deleted", t.name)
+	}
+	next := live.clone()
+	for _, m := range t.journal {
+		if err := m(next); err != nil {
+			return fmt.Errorf("gotypetest: commit: %w", err)
+		}
+	}
+	if err := next.validate(); err != nil {
+		return fmt.Errorf("gotypetest: commit: %w", err)
+	}
+	t.db.dbs[t.name] = next
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.journal = nil
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	if live, ok := t.db.dbs[t.name]; ok {
+		t.st = live.clone()
+	}
+	return nil
+}
+
+func (t *fakeTx) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.open = false
+}
+
+func (t *fakeTx) IsOpen() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.open
+}
+
+// value is an attribute value: a string, int64, float64, bool or
+// time.Time, with the TypeQL value type it was written as.
+type value struct {
+	kind string
+	v    any
+}
+
+// out converts v to the representation the driver returns: datetimes as
+// strings, everything else as is.
+func (v value) out() any {
+	t, ok := v.v.(time.Time)
+	if !ok {
+		return v.v
+	}
+	switch v.kind {
+	case "date":
+		return t.Format("2006-01-02")
+	case "datetime-tz":
+		return t.Format(time.RFC3339Nano)
+	default:
+		return t.Format("2006-01-02T15:04:05.999999999")
+	}
+}
+
+// compare orders a and b; ok is false when they are not comparable.
+func compare(a, b value) (c int, ok bool) {
+	switch x := a.v.(type) {
+	case string:
+		y, ok := b.v.(string)
+		return strings.Compare(x, y), ok
+	case bool:
+		y, ok := b.v.(bool)
+		if !ok || x == y {
+			return 0, ok
+		}
+		if !x {
+			return -1, true
+		}
+		return 1, true
+	case time.Time:
+		y, ok := b.v.(time.Time)
+		return x.Compare(y), ok
+	}
+	x, okx := number(a)
+	y, oky := number(b)
+	if !okx || !oky {
+		return 0, false
+	}
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	}
+	return 0, true
+}
+
+func number(v value) (float64, bool) {
+	switch n := v.v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func equal(a, b value) bool {
+	c, ok := compare(a, b)
+	return ok && c == 0
+}
+
+// coerce converts v to valueType, as TypeDB does for integer literals
+// assigned to double attributes and dates assigned to datetimes.
+func coerce(v value, valueType string) (value, error) {
+	if valueType == "" || v.kind == valueType {
+		return v, nil
+	}
+	switch {
+	case valueType == "long" && v.kind == "integer":
+		return v, nil
+	case (valueType == "double" || valueType == "decimal") && (v.kind == "integer" || v.kind == "double"):
+		f, _ := number(v)
+		return value{kind: valueType, v: f}, nil
+	case valueType == "datetime" && v.kind == "date":
+		return value{kind: valueType, v: v.v}, nil
+	case valueType == "datetime" && v.kind == "datetime-tz":
+		// gotype writes time.Time values with their offset; the datetime
+		// keeps the UTC wall clock.
+		return value{kind: valueType, v: v.v.(time.Time).UTC()}, nil
+	case valueType == "datetime-tz" && (v.kind == "date" || v.kind == "datetime"):
+		return value{kind: valueType, v: v.v}, nil
+	case valueType == "date" && v.kind == "datetime":
+		if t := v.v.(time.Time); t.Equal(t.Truncate(24 * time.Hour)) {
+			return value{kind: valueType, v: t}, nil
+		}
+	}
+	return value{}, fmt.Errorf("a %s value cannot be assigned to a %s attribute", v.kind, valueType)
+}
+
+// instance is a stored entity or relation.
+type instance struct {
+	iid   string
+	typ   string
+	attrs []ownedAttr
+	links []roleLink
+}
+
+type ownedAttr struct {
+	name string
+	val  value
+}
+
+type roleLink struct {
+	role, player string
+}
+
+// store is the content of one database.
+type store struct {
+	schemaText string
+	model      *schemaModel
+	instances  map[string]*instance
+	order      []string // IIDs in insertion order
+}
+
+func newStore() *store {
+	return &store{model: newSchemaModel(nil), instances: make(map[string]*instance)}
+}
+
+func (s *store) clone() *store {
+	c := &store{
+		schemaText: s.schemaText,
+		model:      s.model,
+		instances:  make(map[string]*instance, len(s.instances)),
+		order:      slices.Clone(s.order),
+	}
+	for iid, inst := range s.instances {
+		c.instances[iid] = &instance{
+			iid:   inst.iid,
+			typ:   inst.typ,
+			attrs: slices.Clone(inst.attrs),
+			links: slices.Clone(inst.links),
+		}
+	}
+	return c
+}
+
+// define adds the definitions of a define query to the schema.
+func (s *store) define(text string) error {
+	merged := gotype.NormalizeSchema(s.schemaText + "\n" + text)
+	parsed, err := gotype.IntrospectSchemaFromString(merged)
+	if err != nil {
+		return fmt.Errorf("define: %w", err)
+	}
+	s.schemaText, s.model = merged, newSchemaModel(parsed)
+	return nil
+}
+
+// all returns the live instances in insertion order.
+func (s *store) all() []*instance {
+	out := make([]*instance, 0, len(s.instances))
+	for _, iid := range s.order {
+		if inst, ok := s.instances[iid]; ok {
+			out = append(out, inst)
+		}
+	}
+	return out
+}
+
+func (s *store) get(iid string) (*instance, error) {
+	inst, ok := s.instances[strings.ToLower(iid)]
+	if !ok {
+		return nil, fmt.Errorf("instance %s no longer exists", iid)
+	}
+	return inst, nil
+}
+
+func createInstance(iid, typ string) mutation {
+	return func(s *store) error {
+		s.instances[iid] = &instance{iid: iid, typ: typ}
+		s.order = append(s.order, iid)
+		return nil
+	}
+}
+
+func addAttr(iid, name string, v value) mutation {
+	return func(s *store) error {
+		inst, err := s.get(iid)
+		if err != nil {
+			return err
+		}
+		for _, a := range inst.attrs {
+			if a.name == name && equal(a.val, v) {
+				return nil
+			}
+		}
+		inst.attrs = append(inst.attrs, ownedAttr{name: name, val: v})
+		return nil
+	}
+}
+
+func removeAttr(iid, name string, v value) mutation {
+	return func(s *store) error {
+		if inst, ok := s.instances[iid]; ok {
+			inst.attrs = slices.DeleteFunc(inst.attrs, func(a ownedAttr) bool { return a.name == name && equal(a.val, v) })
+		}
+		return nil
+	}
+}
+
+func addLink(rel, role, player string) mutation {
+	return func(s *store) error {
+		inst, err := s.get(rel)
+		if err != nil {
+			return err
+		}
+		if _, err := s.get(player); err != nil {
+			return err
+		}
+		inst.links = append(inst.links, roleLink{role: role, player: player})
+		return nil
+	}
+}
+
+func removeLink(rel, role, player string) mutation {
+	return func(s *store) error {
+		if inst, ok := s.instances[rel]; ok {
+			if i := slices.Index(inst.links, roleLink{role: role, player: player}); i >= 0 {
+				inst.links = slices.Delete(inst.links, i, i+1)
+			}
+		}
+		return nil
+	}
+}
+
+// deleteInstance removes an instance and its role in relations. Relations
+// left without role players are removed too, as TypeDB does.
+func deleteInstance(iid string) mutation {
+	return func(s *store) error {
+		if _, ok := s.instances[iid]; !ok {
+			return nil
+		}
+		delete(s.instances, iid)
+		for _, inst := range s.all() {
+			n := len(inst.links)
+			inst.links = slices.DeleteFunc(inst.links, func(l roleLink) bool { return l.player == iid })
+			if n > 0 && len(inst.links) == 0 {
+				deleteInstance(inst.iid)(s)
+			}
+		}
+		return nil
+	}
+}
+
+// validate checks @key and @unique constraints.
+func (s *store) validate() error {
+	seen := make(map[string]string)
+	for _, inst := range s.all() {
+		for attr, o := range s.model.owns[inst.typ] {
+			if !o.Key && !o.Unique {
+				continue
+			}
+			var values []value
+			for _, a := range inst.attrs {
+				if a.name == attr {
+					values = append(values, a.val)
+				}
+			}
+			if o.Key && len(values) != 1 {
+				return fmt.Errorf("%s %s must have exactly one key %s, has %d", inst.typ, inst.iid, attr, len(values))
+			}
+			for _, v := range values {
+				k := attr + "\x00" + v.kind + "\x00" + fmt.Sprint(v.out())
+				if other, dup := seen[k]; dup && other != inst.iid {
+					what := "key"
+					if !o.Key {
+						what = "unique"
+					}
+					return fmt.Errorf("%s violation: %s %s is already owned by %s", what, attr, gotype.FormatValue(v.out()), other)
+				}
+				seen[k] = inst.iid
+			}
+		}
+	}
+	return nil
+}
+
+// schemaModel indexes a parsed schema. An empty model accepts any type.
+type schemaModel struct {
+	defined    bool
+	kinds      map[string]string // label -> "entity", "relation" or "attribute"
+	parents    map[string]string
+	abstract   map[string]bool
+	valueTypes map[string]string
+	owns       map[string]map[string]tqlgen.OwnsSpec // with inherited ownerships
+	relates    map[string]map[string]bool
+}
+
+func newSchemaModel(s *tqlgen.ParsedSchema) *schemaModel {
+	m := &schemaModel{
+		kinds:      make(map[string]string),
+		parents:    make(map[string]string),
+		abstract:   make(map[string]bool),
+		valueTypes: make(map[string]string),
+		owns:       make(map[string]map[string]tqlgen.OwnsSpec),
+		relates:    make(map[string]map[string]bool),
+	}
+	if s == nil {
+		return m
+	}
+	s.AccumulateInheritance()
+	m.defined = len(s.Attributes)+len(s.Entities)+len(s.Relations) > 0
+	for _, a := range s.Attributes {
+		m.kinds[a.Name] = "attribute"
+		m.valueTypes[a.Name] = a.ValueType
+	}
+	addOwns := func(typ string, owns []tqlgen.OwnsSpec) {
+		m.owns[typ] = make(map[string]tqlgen.OwnsSpec)
+		for _, o := range owns {
+			m.owns[typ][o.Attribute] = o
+		}
+	}
+	for _, e := range s.Entities {
+		m.kinds[e.Name], m.parents[e.Name], m.abstract[e.Name] = "entity", e.Parent, e.Abstract
+		addOwns(e.Name, e.Owns)
+	}
+	for _, r := range s.Relations {
+		m.kinds[r.Name], m.parents[r.Name], m.abstract[r.Name] = "relation", r.Parent, r.Abstract
+		addOwns(r.Name, r.Owns)
+		m.relates[r.Name] = make(map[string]bool)
+		for _, rel := range r.Relates {
+			m.relates[r.Name][rel.Role] = true
+		}
+	}
+	return m
+}
+
+// isSubtype reports whether typ is label or one of its subtypes.
+func (m *schemaModel) isSubtype(typ, label string) bool {
+	for t := typ; t != ""; t = m.parents[t] {
+		if t == label {
+			return true
+		}
+		if m.parents[t] == t {
+			break
+		}
+	}
+	return false
+}
+
+// checkType reports an unknown label once a schema is defined.
+func (m *schemaModel) checkType(label string) error {
+	if m.defined && m.kinds[label] == "" {
+		return fmt.Errorf("type %s is not defined", label)
+	}
+	return nil
+}
+
+// multiValued reports whether typ may own several attr values, from the
+// @card of the ownership; without a schema it depends on count.
+func (m *schemaModel) multiValued(typ, attr string, count int) bool {
+	o, ok := m.owns[typ][attr]
+	if !ok {
+		return count > 1
+	}
+	if o.Key || o.Card == "" {
+		return false
+	}
+	_, maxCount, _ := strings.Cut(o.Card, "..")
+	n, err := strconv.Atoi(maxCount)
+	return err != nil || n > 1
+}
+
+func (m *schemaModel) kind(inst *instance) string {
+	if k := m.kinds[inst.typ]; k != "" {
+		return k
+	}
+	if len(inst.links) > 0 {
+		return "relation"
+	}
+	return "entity"
+}
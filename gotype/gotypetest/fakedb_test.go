@@ -0,0 +1,407 @@
+package gotypetest_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+)
+
+type person struct {
+	gotype.BaseEntity
+	Name     string     `typedb:"name,key"`
+	Age      *int       `typedb:"age,card=0..1"`
+	Email    string     `typedb:"email,unique"`
+	Score    float64    `typedb:"score"`
+	Tags     []string   `typedb:"tag,card=0.."`
+	JoinedAt *time.Time `typedb:"joined-at,card=0..1"`
+}
+
+type company struct {
+	gotype.BaseEntity
+	Title string `typedb:"title,key"`
+}
+
+type employment struct {
+	gotype.BaseRelation
+	Employee *person  `typedb:"role:employee"`
+	Employer *company `typedb:"role:employer"`
+	Since    int      `typedb:"since"`
+}
+
+type animal struct {
+	gotype.BaseEntity
+	Nick string `typedb:"nick,key"`
+}
+
+type dog struct {
+	gotype.BaseEntity
+	Nick  string `typedb:"nick,key"`
+	Breed string `typedb:"breed"`
+}
+
+func newTestDB(t *testing.T) (*gotypetest.FakeDB, *gotype.Database) {
+	t.Helper()
+	reg := gotype.NewRegistry()
+	for _, err := range []error{
+		gotype.RegisterIn[person](reg),
+		gotype.RegisterIn[company](reg),
+		gotype.RegisterIn[employment](reg),
+		gotype.RegisterIn[animal](reg),
+		gotype.RegisterIn[dog](reg),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	animalInfo, _ := reg.Lookup("animal")
+	animalInfo.IsAbstract = true
+	dogInfo, _ := reg.Lookup("dog")
+	dogInfo.Supertype = "animal"
+	fake := gotypetest.NewFakeDB()
+	db := gotype.NewDatabase(fake, "test").WithRegistry(reg)
+	if err := db.ExecuteSchema(context.Background(), reg.GenerateSchema()); err != nil {
+		t.Fatalf("ExecuteSchema: %v", err)
+	}
+	return fake, db
+}
+
+func intPtr(n int) *int { return &n }
+
+func names(people []*person) string {
+	out := make([]string, len(people))
+	for i, p := range people {
+		out[i] = p.Name
+	}
+	return strings.Join(out, ",")
+}
+
+func seedPeople(t *testing.T, persons *gotype.Manager[person]) {
+	t.Helper()
+	err := persons.InsertMany(context.Background(), []*person{
+		{Name: "Alice", Age: intPtr(30), Email: "alice@example.com", Score: 4.5, Tags: []string{"admin", "dev"}},
+		{Name: "Bob", Age: intPtr(25), Email: "bob@example.com", Score: 3},
+		{Name: "Carol", Email: "carol@example.com", Score: 5},
+	})
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+}
+
+func TestFakeDB_CRUD(t *testing.T) {
+	ctx := context.Background()
+	_, db := newTestDB(t)
+	persons := gotype.MustNewManager[person](db)
+
+	joined := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	alice := &person{Name: "Alice", Age: intPtr(30), Email: "alice@example.com", Score: 4, Tags: []string{"admin", "dev"}, JoinedAt: &joined}
+	if err := persons.Insert(ctx, alice); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if alice.GetIID() == "" {
+		t.Fatal("Insert did not set the IID")
+	}
+
+	got, err := persons.Get(ctx, map[string]any{"name": "Alice"})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+	p := got[0]
+	if p.GetIID() != alice.GetIID() || *p.Age != 30 || p.Score != 4 || p.Email != "alice@example.com" ||
+		strings.Join(p.Tags, ",") != "admin,dev" || p.JoinedAt == nil || !p.JoinedAt.Equal(joined) {
+		t.Errorf("Get returned %+v", p)
+	}
+
+	byIID, err := persons.GetByIID(ctx, alice.GetIID())
+	if err != nil || byIID == nil || byIID.Name != "Alice" {
+		t.Fatalf("GetByIID = %+v, %v", byIID, err)
+	}
+
+	p.Age = nil
+	p.Score = 2.5
+	if err := persons.Update(ctx, p); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	p, err = persons.GetByIID(ctx, alice.GetIID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Age != nil || p.Score != 2.5 {
+		t.Errorf("after Update: age %v, score %v", p.Age, p.Score)
+	}
+
+	if err := persons.Delete(ctx, p); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	all, err := persons.All(ctx)
+	if err != nil || len(all) != 0 {
+		t.Errorf("All after Delete = %v, %v", all, err)
+	}
+}
+
+func TestFakeDB_Query(t *testing.T) {
+	ctx := context.Background()
+	_, db := newTestDB(t)
+	persons := gotype.MustNewManager[person](db)
+	seedPeople(t, persons)
+
+	tests := []struct {
+		name  string
+		query *gotype.Query[person]
+		want  string
+	}{
+		{"eq", persons.Query().Filter(gotype.Eq("name", "Bob")), "Bob"},
+		{"gt", persons.Query().Filter(gotype.Gt("score", 3.5)).OrderAsc("name"), "Alice,Carol"},
+		{"in", persons.Query().Filter(gotype.In("name", []any{"Alice", "Carol"})).OrderDesc("name"), "Carol,Alice"},
+		{"or", persons.Query().Filter(gotype.Or(gotype.Eq("name", "Bob"), gotype.Lt("age", 28))).OrderAsc("name"), "Bob"},
+		{"not", persons.Query().Filter(gotype.Not(gotype.Eq("name", "Bob"))).OrderAsc("name"), "Alice,Carol"},
+		{"contains", persons.Query().Filter(gotype.Contains("email", "CAROL")), "Carol"},
+		{"has", persons.Query().Filter(gotype.HasAttr("age")).OrderAsc("age"), "Bob,Alice"},
+		{"not has", persons.Query().Filter(gotype.NotHasAttr("age")), "Carol"},
+		{"page", persons.Query().OrderAsc("name").Offset(1).Limit(1), "Bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.Execute(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names(got) != tt.want {
+				t.Errorf("got %s, want %s", names(got), tt.want)
+			}
+		})
+	}
+
+	n, err := persons.Query().Filter(gotype.Gte("score", 3)).Count(ctx)
+	if err != nil || n != 3 {
+		t.Errorf("Count = %d, %v", n, err)
+	}
+	avg, err := persons.Query().Avg("score").Execute(ctx)
+	if err != nil || avg != 4.166666666666667 {
+		t.Errorf("Avg = %v, %v", avg, err)
+	}
+	deleted, err := persons.Query().Filter(gotype.Lt("score", 4)).Delete(ctx)
+	if err != nil || deleted != 1 {
+		t.Errorf("Delete = %d, %v", deleted, err)
+	}
+	if n, _ := persons.Query().Count(ctx); n != 2 {
+		t.Errorf("Count after Delete = %d", n)
+	}
+}
+
+func TestFakeDB_Relations(t *testing.T) {
+	ctx := context.Background()
+	_, db := newTestDB(t)
+	persons := gotype.MustNewManager[person](db)
+	companies := gotype.MustNewManager[company](db)
+	jobs := gotype.MustNewManager[employment](db)
+	seedPeople(t, persons)
+
+	alice, _ := persons.Get(ctx, map[string]any{"name": "Alice"})
+	acme := &company{Title: "Acme"}
+	if err := companies.Insert(ctx, acme); err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Insert(ctx, &employment{Employee: alice[0], Employer: acme, Since: 2020}); err != nil {
+		t.Fatalf("Insert relation: %v", err)
+	}
+
+	got, err := jobs.GetWithRoles(ctx, nil)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("GetWithRoles = %v, %v", got, err)
+	}
+	if got[0].Since != 2020 || got[0].Employee == nil || got[0].Employee.Name != "Alice" || got[0].Employer.Title != "Acme" {
+		t.Errorf("GetWithRoles returned %+v", got[0])
+	}
+
+	// As in TypeDB, a relation is removed with its last role player.
+	if err := persons.Delete(ctx, alice[0]); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := jobs.Query().Count(ctx); err != nil || n != 1 {
+		t.Errorf("relations after deleting one player = %d, %v", n, err)
+	}
+	got, err = jobs.GetWithRoles(ctx, nil)
+	if err != nil || len(got) != 1 || got[0].Employee != nil || got[0].Employer == nil || got[0].Employer.Title != "Acme" {
+		t.Errorf("GetWithRoles with a missing player = %+v, %v", got, err)
+	}
+	if err := companies.Delete(ctx, acme); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := jobs.Query().Count(ctx); err != nil || n != 0 {
+		t.Errorf("relations after deleting both players = %d, %v", n, err)
+	}
+}
+
+func TestFakeDB_Polymorphic(t *testing.T) {
+	ctx := context.Background()
+	_, db := newTestDB(t)
+	dogs := gotype.MustNewManager[dog](db)
+	rex := &dog{Nick: "Rex", Breed: "collie"}
+	if err := dogs.Insert(ctx, rex); err != nil {
+		t.Fatal(err)
+	}
+	animals := gotype.MustNewManager[animal](db)
+	got, label, err := animals.GetByIIDPolymorphicAny(ctx, rex.GetIID())
+	if err != nil || label != "dog" {
+		t.Fatalf("GetByIIDPolymorphicAny = %v, %q, %v", got, label, err)
+	}
+	if d, ok := got.(*dog); !ok || d.Breed != "collie" {
+		t.Errorf("got %#v", got)
+	}
+	if err := animals.Insert(ctx, &animal{Nick: "Generic"}); err == nil || !strings.Contains(err.Error(), "abstract") {
+		t.Errorf("inserting an abstract type: %v", err)
+	}
+}
+
+func TestFakeDB_Constraints(t *testing.T) {
+	ctx := context.Background()
+	_, db := newTestDB(t)
+	persons := gotype.MustNewManager[person](db)
+	seedPeople(t, persons)
+
+	err := persons.Insert(ctx, &person{Name: "Alice", Email: "other@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "key violation") {
+		t.Errorf("duplicate key: %v", err)
+	}
+	err = persons.Insert(ctx, &person{Name: "Dave", Email: "bob@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "unique violation") {
+		t.Errorf("duplicate unique: %v", err)
+	}
+	if n, _ := persons.Query().Count(ctx); n != 3 {
+		t.Errorf("failed inserts were committed: %d persons", n)
+	}
+
+	// Put only inserts when the instance does not exist yet.
+	for range 2 {
+		if err := persons.Put(ctx, &person{Name: "Erin", Email: "erin@example.com"}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if n, _ := persons.Query().Count(ctx); n != 4 {
+		t.Errorf("Count after Put twice = %d, want 4", n)
+	}
+}
+
+func TestFakeDB_Transactions(t *testing.T) {
+	ctx := context.Background()
+	_, db := newTestDB(t)
+	persons := gotype.MustNewManager[person](db)
+
+	tc, err := db.Begin(gotype.WriteTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inTx := gotype.MustNewManagerWithTx[person](tc)
+	if err := inTx.Insert(ctx, &person{Name: "Temp", Email: "temp@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := inTx.Get(ctx, map[string]any{"name": "Temp"}); err != nil || len(got) != 1 {
+		t.Errorf("uncommitted insert not visible in its transaction: %v, %v", got, err)
+	}
+	if n, _ := persons.Query().Count(ctx); n != 0 {
+		t.Errorf("uncommitted insert visible outside its transaction")
+	}
+	if err := tc.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	tc.Close()
+	if n, _ := persons.Query().Count(ctx); n != 0 {
+		t.Errorf("rolled back insert was committed")
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			p := &person{Name: "P" + string(rune('a'+i)), Email: string(rune('a'+i)) + "@example.com"}
+			if err := persons.Insert(ctx, p); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if n, _ := persons.Query().Count(ctx); n != 20 {
+		t.Errorf("Count after concurrent inserts = %d", n)
+	}
+}
+
+func TestFakeDB_Conn(t *testing.T) {
+	fake := gotypetest.NewFakeDB()
+	if err := fake.DatabaseCreate("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.DatabaseCreate("a"); err == nil {
+		t.Error("creating a database twice should fail")
+	}
+	tx, err := fake.Transaction("b", int(gotype.ReadTransaction))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Query(`insert $p isa thing, has name "x";`); err == nil {
+		t.Error("insert in a read transaction should fail")
+	}
+	if _, err := tx.Query(`match $p isa thing; limit 1; let $x = 1;`); err == nil {
+		t.Error("unsupported syntax should fail")
+	}
+	tx.Close()
+	if dbs, _ := fake.DatabaseAll(); strings.Join(dbs, ",") != "a,b" {
+		t.Errorf("DatabaseAll = %v", dbs)
+	}
+
+	// Without a schema any type is accepted.
+	tx, _ = fake.Transaction("b", int(gotype.WriteTransaction))
+	if _, err := tx.Query(`insert $p isa thing, has name "x", has size 3;`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	tx, _ = fake.Transaction("b", int(gotype.ReadTransaction))
+	defer tx.Close()
+	rows, err := tx.Query(`match $p isa thing, has size $s; $s > 2; fetch { "name": $p.name, "size": $s };`)
+	if err != nil || len(rows) != 1 || rows[0]["name"] != "x" || rows[0]["size"] != int64(3) {
+		t.Errorf("fetch = %v, %v", rows, err)
+	}
+
+	fake.Close()
+	if _, err := fake.Transaction("b", int(gotype.ReadTransaction)); err == nil || fake.IsOpen() {
+		t.Error("Transaction after Close should fail")
+	}
+}
+
+func TestFakeDB_WriteSummary(t *testing.T) {
+	ctx := context.Background()
+	fake := gotypetest.NewFakeDB()
+	tx, _ := fake.Transaction("s", int(gotype.WriteTransaction))
+	rtx, ok := tx.(gotype.ResultTx)
+	if !ok {
+		t.Fatal("fake transaction does not implement gotype.ResultTx")
+	}
+	res, err := rtx.QueryResult(ctx, `insert $a isa thing, has name "a"; $b isa thing, has name "b";`)
+	if err != nil || res.Summary == nil || *res.Summary != (gotype.WriteSummary{Inserted: 2}) {
+		t.Fatalf("insert result = %+v, %v", res, err)
+	}
+	res, err = rtx.QueryResult(ctx, `match $t isa thing; delete $t;`)
+	if err != nil || res.Summary == nil || *res.Summary != (gotype.WriteSummary{Deleted: 2}) {
+		t.Fatalf("delete result = %+v, %v", res, err)
+	}
+	tx.Close()
+
+	tx, _ = fake.Transaction("s", int(gotype.ReadTransaction))
+	defer tx.Close()
+	if res, err := tx.(gotype.ResultTx).QueryResult(ctx, `match $t isa thing; fetch { "name": $t.name };`); err != nil || res.Summary != nil {
+		t.Errorf("read result = %+v, %v", res, err)
+	}
+
+	_, db := newTestDB(t)
+	persons := gotype.MustNewManager[person](db)
+	seedPeople(t, persons)
+	n, err := persons.Query().Filter(gotype.Gt("score", 3.5)).Delete(ctx)
+	if err != nil || n != 2 {
+		t.Errorf("Delete = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,73 @@
+package gotypetest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// LoadFixtures inserts the fixtures of every .yaml, .yml and .json file in
+// fsys, walked in lexical order, and returns the IID of each referenced
+// instance by reference. Files use the layout of gotype.Fixture:
+//
+//	entities:
+//	  - ref: alice
+//	    type: person
+//	    attributes:
+//	      name: Alice
+//	      age: 30
+//	relations:
+//	  - ref: alice-at-acme
+//	    type: employment
+//	    roles:
+//	      employee: alice
+//	      employer: acme
+//
+// Files are read with gotype.LoadFixtures and inserted with
+// gotype.SeedFixtures in one transaction, so a relation may reference
+// players defined in any file. Every type must be registered in the
+// database's registry.
+func LoadFixtures(ctx context.Context, db *gotype.Database, fsys fs.FS) (map[string]string, error) {
+	fixtures, err := gotype.LoadFixtures(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %w", err)
+	}
+	reg := db.Registry()
+	for _, f := range fixtures {
+		for _, e := range f.Entities {
+			if err := checkFixtureType(reg, e.Type, gotype.ModelKindEntity); err != nil {
+				return nil, fmt.Errorf("fixtures: %s: %w", f.Source, err)
+			}
+		}
+		for _, r := range f.Relations {
+			if err := checkFixtureType(reg, r.Type, gotype.ModelKindRelation); err != nil {
+				return nil, fmt.Errorf("fixtures: %s: %w", f.Source, err)
+			}
+		}
+	}
+	iids, err := gotype.SeedFixtures(ctx, db, fixtures)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %w", err)
+	}
+	return iids, nil
+}
+
+func checkFixtureType(reg *gotype.Registry, typeName string, kind gotype.ModelKind) error {
+	info, ok := reg.Lookup(typeName)
+	switch {
+	case !ok:
+		return fmt.Errorf("type %q is not registered", typeName)
+	case info.Kind != kind:
+		return fmt.Errorf("type %q is not %s", typeName, kindName(kind))
+	}
+	return nil
+}
+
+func kindName(kind gotype.ModelKind) string {
+	if kind == gotype.ModelKindRelation {
+		return "a relation"
+	}
+	return "an entity"
+}
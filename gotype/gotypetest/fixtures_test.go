@@ -0,0 +1,79 @@
+package gotypetest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	ctx := context.Background()
+	_, db := newTestDB(t)
+	fsys := fstest.MapFS{
+		// Relations may reference entities of files loaded later.
+		"a_jobs.yaml": {Data: []byte(`
+relations:
+  - ref: alice-at-acme
+    type: employment
+    roles:
+      employee: alice
+      employer: acme
+    attributes:
+      since: 2020
+`)},
+		"people/b.yml": {Data: []byte(`
+entities:
+  - ref: alice
+    type: person
+    attributes:
+      name: Alice
+      email: alice@example.com
+      score: 4.5
+      tag: [admin, dev]
+`)},
+		"c_companies.json": {Data: []byte(`{"entities": [{"ref": "acme", "type": "company", "attributes": {"title": "Acme"}}]}`)},
+		"README.md":        {Data: []byte("not a fixture")},
+	}
+	iids, err := gotypetest.LoadFixtures(ctx, db, fsys)
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	if len(iids) != 3 {
+		t.Fatalf("iids = %v", iids)
+	}
+
+	alice, err := gotype.MustNewManager[person](db).GetByIID(ctx, iids["alice"])
+	if err != nil || alice == nil || alice.Score != 4.5 || strings.Join(alice.Tags, ",") != "admin,dev" {
+		t.Fatalf("alice = %+v, %v", alice, err)
+	}
+	jobs, err := gotype.MustNewManager[employment](db).GetWithRoles(ctx, nil)
+	if err != nil || len(jobs) != 1 || jobs[0].GetIID() != iids["alice-at-acme"] || jobs[0].Employer.Title != "Acme" {
+		t.Errorf("jobs = %v, %v", jobs, err)
+	}
+}
+
+func TestLoadFixtures_Errors(t *testing.T) {
+	tests := []struct {
+		name, file, want string
+	}{
+		{"unregistered", "entities:\n  - type: robot\n", `type "robot" is not registered`},
+		{"wrong kind", "relations:\n  - type: person\n", `type "person" is not a relation`},
+		{"unknown key", "entities:\n  - type: person\n    attrs: {}\n", `unknown field "attrs"`},
+		{"unknown reference", "relations:\n  - type: employment\n    roles:\n      employee: nobody\n", `unknown reference "nobody"`},
+		{"duplicate reference", "entities:\n  - ref: a\n    type: company\n  - ref: a\n    type: company\n", `reference "a" is already defined`},
+		{"yaml", "entities:\n\t- type: person\n", "tabs are not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, db := newTestDB(t)
+			_, err := gotypetest.LoadFixtures(context.Background(), db, fstest.MapFS{"f.yaml": {Data: []byte(tt.file)}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package gotypetest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// update is the -update flag of test binaries importing gotypetest. A
+// package that defines its own -update flag conflicts with it; use this one.
+var update = flag.Bool("update", false, "rewrite golden files with the queries the tests produce")
+
+// AssertQueryGolden compares query with the golden file at path, ignoring
+// differences NormalizeQuery removes. With "go test -update", or when
+// GOTYPETEST_UPDATE=1, the golden file is written instead:
+//
+//	gotypetest.AssertQueryGolden(t, query, "testdata/insert_person.tql")
+//
+// On mismatch the test fails showing where the normalized queries first
+// differ, followed by both in full.
+func AssertQueryGolden(t testing.TB, query, path string) {
+	t.Helper()
+	got := NormalizeQuery(query)
+	if *update || os.Getenv("GOTYPETEST_UPDATE") == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("gotypetest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got+"\n"), 0o644); err != nil {
+			t.Fatalf("gotypetest: %v", err)
+		}
+		return
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("gotypetest: golden file %s does not exist; run go test -update to create it", path)
+	}
+	if err != nil {
+		t.Fatalf("gotypetest: %v", err)
+	}
+	want := NormalizeQuery(string(data))
+	if firstDiff := firstDifference(got, want); firstDiff != "" {
+		t.Errorf("gotypetest: query does not match %s (run go test -update to accept it)\n%s\ngot:\n%s\nwant:\n%s",
+			path, firstDiff, got, want)
+	}
+}
+
+// NormalizeQuery returns the canonical form of a TypeQL query used by
+// AssertQueryGolden:
+//
+//   - comments and blank lines are dropped, lines are trimmed and runs of
+//     spaces within a line become one space, outside string literals;
+//   - variables ending in a number are renumbered per prefix in order of
+//     first use, so "$e3 ... $e4" becomes "$e0 ... $e1" and batches built
+//     from a non-zero offset compare equal.
+//
+// Line breaks are kept so that golden files stay readable, but
+// AssertQueryGolden does not compare them.
+func NormalizeQuery(query string) string {
+	var b strings.Builder
+	renames := make(map[string]string)
+	counts := make(map[string]int)
+	space := false
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\n' || c == '\r':
+			b.WriteByte('\n')
+			space = false
+			i++
+		case c == ' ' || c == '\t':
+			space = true
+			i++
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		default:
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			switch {
+			case c == '"' || c == '\'':
+				end := closingQuote(query, i)
+				b.WriteString(query[i:end])
+				i = end
+			case c == '$':
+				j := i + 1
+				for j < len(query) && isVarChar(query[j]) {
+					j++
+				}
+				b.WriteString("$" + renameVar(query[i+1:j], renames, counts))
+				i = j
+			default:
+				b.WriteByte(c)
+				i++
+			}
+		}
+	}
+	var lines []string
+	for line := range strings.SplitSeq(b.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// closingQuote returns the offset just past the string literal at start.
+func closingQuote(s string, start int) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case s[start]:
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func renameVar(name string, renames map[string]string, counts map[string]int) string {
+	prefix := strings.TrimRight(name, "0123456789")
+	if prefix == name || prefix == "" {
+		return name
+	}
+	if r, ok := renames[name]; ok {
+		return r
+	}
+	r := prefix + strconv.Itoa(counts[prefix])
+	counts[prefix]++
+	renames[name] = r
+	return r
+}
+
+// firstDifference shows where the flattened queries first differ, with
+// some context, since golden files may break lines differently.
+func firstDifference(got, want string) string {
+	g, w := strings.ReplaceAll(got, "\n", " "), strings.ReplaceAll(want, "\n", " ")
+	if g == w {
+		return ""
+	}
+	i := 0
+	for i < len(g) && i < len(w) && g[i] == w[i] {
+		i++
+	}
+	from := max(0, i-30)
+	excerpt := func(s string) string { return strconv.Quote(s[min(from, len(s)):min(i+30, len(s))]) }
+	return fmt.Sprintf("first difference at offset %d:\n  got:  %s\n  want: %s", i, excerpt(g), excerpt(w))
+}
@@ -0,0 +1,64 @@
+package gotypetest_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	got := gotypetest.NormalizeQuery("  match\n\n$e7 isa   person, # comment\n has name \"a  # b\";\n$e8 has tag $e7__x;\t\n")
+	want := "match\n$e0 isa person,\nhas name \"a  # b\";\n$e1 has tag $e7__x;"
+	if got != want {
+		t.Errorf("NormalizeQuery =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAssertQueryGolden(t *testing.T) {
+	// Same query with other whitespace, line breaks and variable numbers.
+	gotypetest.AssertQueryGolden(t, `match $employee isa person, iid 0x1;
+insert
+  $e4 isa employment, links (employee: $employee),
+      has since 2020;
+  $e5 isa employment, links (employee: $employee), has since 2021;`, "testdata/insert_person.tql")
+
+}
+
+func TestAssertQueryGolden_Mismatch(t *testing.T) {
+	if flag.Lookup("update").Value.String() == "true" {
+		t.Skip("-update accepts every query")
+	}
+	path := filepath.Join(t.TempDir(), "q.tql")
+	if err := os.WriteFile(path, []byte("match\n$employee isa person, iid 0x1;\ninsert $e0 isa employment;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOTYPETEST_UPDATE", "0")
+	rec := &recorder{TB: t}
+	gotypetest.AssertQueryGolden(rec, "match $employee isa person, iid 0x2;", path)
+	if !strings.Contains(rec.msg, "first difference at offset 34") || !strings.Contains(rec.msg, "iid 0x2") {
+		t.Errorf("mismatch message = %q", rec.msg)
+	}
+}
+
+func TestAssertQueryGolden_Update(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "q.tql")
+	t.Setenv("GOTYPETEST_UPDATE", "1")
+	gotypetest.AssertQueryGolden(t, "match $x3 isa person;  ", path)
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "match $x0 isa person;\n" {
+		t.Errorf("golden file = %q, %v", data, err)
+	}
+}
+
+// recorder captures the failure of an assertion expected to fail.
+type recorder struct {
+	testing.TB
+	msg string
+}
+
+func (r *recorder) Errorf(format string, args ...any) { r.msg = fmt.Sprintf(format, args...) }
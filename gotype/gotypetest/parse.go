@@ -0,0 +1,845 @@
+package gotypetest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file parses the subset of TypeQL that gotype generates: match with
+// isa/isa!/has/iid/links/sub statements, comparisons, or/not/try blocks;
+// insert, put, delete and update; fetch documents, with list subqueries;
+// reduce, sort, offset, limit, select and distinct.
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokVar
+	tokString
+	tokWord
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// lex splits a query into tokens. Within a word, ':' is kept when followed
+// by a word character ("employment:employee", "10:20:30") and '.' and '+'
+// when they continue a number or timestamp.
+func lex(query string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case c == '$':
+			j := i + 1
+			for j < len(query) && isVarChar(query[j]) {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("invalid variable at offset %d", i)
+			}
+			toks = append(toks, token{tokVar, query[i+1 : j]})
+			i = j
+		case c == '"' || c == '\'':
+			s, n, err := lexString(query[i:])
+			if err != nil {
+				return nil, fmt.Errorf("offset %d: %w", i, err)
+			}
+			toks = append(toks, token{tokString, s})
+			i += n
+		case isWordChar(c) || c == '-' && i+1 < len(query) && isDigit(query[i+1]):
+			j := i + 1
+			for j < len(query) {
+				d := query[j]
+				next := byte(0)
+				if j+1 < len(query) {
+					next = query[j+1]
+				}
+				switch {
+				case isWordChar(d) || d == '-':
+				case d == ':' && isWordChar(next):
+				case (d == '.' || d == '+') && isDigit(query[j-1]) && isDigit(next):
+				default:
+					goto done
+				}
+				j++
+			}
+		done:
+			toks = append(toks, token{tokWord, query[i:j]})
+			i = j
+		default:
+			if i+1 < len(query) {
+				if two := query[i : i+2]; two == "==" || two == "!=" || two == "<=" || two == ">=" {
+					toks = append(toks, token{tokPunct, two})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune(";,{}()[]:.*!=<>", rune(c)) {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			toks = append(toks, token{tokPunct, string(c)})
+			i++
+		}
+	}
+	return append(toks, token{kind: tokEOF}), nil
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isWordChar(c byte) bool {
+	return c == '_' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isVarChar(c byte) bool { return isWordChar(c) || c == '-' }
+
+// lexString decodes the quoted string at the start of s and returns it with
+// the number of bytes consumed.
+func lexString(s string) (string, int, error) {
+	q := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == q:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				r, n, ok := decodeUnicode(s[i+1:])
+				if !ok {
+					return "", 0, fmt.Errorf("invalid unicode escape")
+				}
+				b.WriteRune(r)
+				i += n
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// decodeUnicode decodes the XXXX or {X...} following "\u".
+func decodeUnicode(s string) (rune, int, bool) {
+	if strings.HasPrefix(s, "{") {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return 0, 0, false
+		}
+		n, err := strconv.ParseUint(s[1:end], 16, 32)
+		return rune(n), end + 1, err == nil
+	}
+	if len(s) < 4 {
+		return 0, 0, false
+	}
+	n, err := strconv.ParseUint(s[:4], 16, 32)
+	return rune(n), 4, err == nil
+}
+
+// query is a parsed pipeline: a define/undefine/redefine, or a sequence of
+// stages.
+type query struct {
+	schemaOp string // "define", "undefine" or "redefine"
+	stages   []stage
+}
+
+type stage struct {
+	kind     string // match, insert, put, delete, update, fetch, reduce, sort, offset, limit, select
+	patterns []pattern
+	fetch    []fetchEntry
+	reduces  []reduceAssign
+	groupBy  []string
+	sorts    []sortKey
+	n        int
+	vars     []string
+}
+
+// pattern is a statement or a block.
+type pattern struct {
+	kind        string // "stmt", "cmp", "and", "or", "not", "try", "delete", "unhas", "unlink"
+	subject     string
+	constraints []constraint
+	branches    [][]pattern // or
+	inner       []pattern   // and, not, try
+
+	op          string  // cmp
+	left, right operand // cmp; left only for "unhas" (the attribute)
+}
+
+type constraint struct {
+	kind     string // "isa", "isa!", "has", "iid", "links", "sub", "sub!"
+	label    string
+	labelVar string
+	value    operand
+	players  []rolePlayer
+}
+
+type rolePlayer struct {
+	role, roleVar, player string
+}
+
+// operand is a variable or a literal value.
+type operand struct {
+	varName string
+	lit     *value
+}
+
+type fetchEntry struct {
+	key     string // "" for a spread "$e.*"
+	expr    string // "iid", "label", "attr", "attrs", "all", "var", "object", "subquery"
+	varName string
+	attr    string
+	nested  []fetchEntry
+	sub     *query // subquery, run once per row with the row's bindings
+}
+
+type reduceAssign struct {
+	varName, fn, arg string
+}
+
+type sortKey struct {
+	varName string
+	desc    bool
+}
+
+var stageKeywords = map[string]bool{
+	"match": true, "insert": true, "put": true, "delete": true, "update": true,
+	"fetch": true, "reduce": true, "sort": true, "offset": true, "limit": true, "select": true,
+	"distinct": true,
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func parseQuery(text string) (*query, error) {
+	if op := schemaKeyword(text); op != "" {
+		return &query{schemaOp: op}, nil
+	}
+	toks, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	q := &query{}
+	for p.peek().kind != tokEOF {
+		s, err := p.stage()
+		if err != nil {
+			return nil, err
+		}
+		q.stages = append(q.stages, s)
+	}
+	if len(q.stages) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	return q, nil
+}
+
+// schemaKeyword returns "define", "undefine" or "redefine" when the query
+// starts with one, skipping blank lines and comments. Schema queries are
+// not lexed: annotations use syntax the data query lexer does not know.
+func schemaKeyword(text string) string {
+	for line := range strings.SplitSeq(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		word, _, _ := strings.Cut(line, " ")
+		word = strings.TrimRight(word, ";")
+		if word == "define" || word == "undefine" || word == "redefine" {
+			return word
+		}
+		return ""
+	}
+	return ""
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == s
+}
+
+func (p *parser) isWord(s string) bool {
+	t := p.peek()
+	return t.kind == tokWord && t.text == s
+}
+
+func (p *parser) expect(s string) error {
+	t := p.next()
+	if (t.kind == tokPunct || t.kind == tokWord) && t.text == s {
+		return nil
+	}
+	return fmt.Errorf("expected %q, found %s", s, describe(t))
+}
+
+func (p *parser) expectVar() (string, error) {
+	t := p.next()
+	if t.kind != tokVar {
+		return "", fmt.Errorf("expected a variable, found %s", describe(t))
+	}
+	return t.text, nil
+}
+
+func (p *parser) expectWord() (string, error) {
+	t := p.next()
+	if t.kind != tokWord {
+		return "", fmt.Errorf("expected a label, found %s", describe(t))
+	}
+	return t.text, nil
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokVar:
+		return "$" + t.text
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return strconv.Quote(t.text)
+	}
+}
+
+func (p *parser) atStageEnd() bool {
+	t := p.peek()
+	return t.kind == tokEOF || t.kind == tokWord && stageKeywords[t.text]
+}
+
+func (p *parser) stage() (stage, error) {
+	t := p.next()
+	if t.kind != tokWord || !stageKeywords[t.text] {
+		return stage{}, fmt.Errorf("expected a query stage, found %s", describe(t))
+	}
+	s := stage{kind: t.text}
+	var err error
+	switch s.kind {
+	case "match", "insert", "put", "update":
+		s.patterns, err = p.patterns(s.kind, func() bool { return p.atStageEnd() })
+	case "delete":
+		s.patterns, err = p.patterns("delete", func() bool { return p.atStageEnd() })
+	case "fetch":
+		if err = p.expect("{"); err == nil {
+			s.fetch, err = p.fetchEntries()
+		}
+		if err == nil && p.isPunct(";") {
+			p.next()
+		}
+	case "reduce":
+		err = p.reduce(&s)
+	case "sort":
+		for err == nil {
+			var key sortKey
+			if key.varName, err = p.expectVar(); err != nil {
+				break
+			}
+			if p.isWord("asc") || p.isWord("desc") {
+				key.desc = p.next().text == "desc"
+			}
+			s.sorts = append(s.sorts, key)
+			if !p.isPunct(",") {
+				break
+			}
+			p.next()
+		}
+		if err == nil {
+			err = p.expect(";")
+		}
+	case "distinct":
+		err = p.expect(";")
+	case "offset", "limit":
+		w, werr := p.expectWord()
+		if werr != nil {
+			return s, werr
+		}
+		if s.n, err = strconv.Atoi(w); err != nil || s.n < 0 {
+			return s, fmt.Errorf("invalid %s %q", s.kind, w)
+		}
+		err = p.expect(";")
+	case "select":
+		for err == nil {
+			var v string
+			if v, err = p.expectVar(); err == nil {
+				s.vars = append(s.vars, v)
+			}
+			if !p.isPunct(",") {
+				break
+			}
+			p.next()
+		}
+		if err == nil {
+			err = p.expect(";")
+		}
+	}
+	if err != nil {
+		return s, fmt.Errorf("%s: %w", s.kind, err)
+	}
+	return s, nil
+}
+
+// patterns parses statements until end reports true.
+func (p *parser) patterns(stageKind string, end func() bool) ([]pattern, error) {
+	var out []pattern
+	for !end() {
+		pat, err := p.pattern(stageKind)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pat)
+	}
+	return out, nil
+}
+
+func (p *parser) block(stageKind string) ([]pattern, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	pats, err := p.patterns(stageKind, func() bool { return p.isPunct("}") || p.peek().kind == tokEOF })
+	if err != nil {
+		return nil, err
+	}
+	return pats, p.expect("}")
+}
+
+func (p *parser) endStatement() error {
+	if p.isPunct(";") {
+		p.next()
+		return nil
+	}
+	// The last statement of a stage may omit its ";" before "fetch" etc.
+	if p.atStageEnd() || p.isPunct("}") {
+		return nil
+	}
+	return fmt.Errorf("expected \";\", found %s", describe(p.peek()))
+}
+
+func (p *parser) pattern(stageKind string) (pattern, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokWord && (t.text == "not" || t.text == "try"):
+		p.next()
+		inner, err := p.block(stageKind)
+		if err != nil {
+			return pattern{}, err
+		}
+		return pattern{kind: t.text, inner: inner}, p.endStatement()
+	case t.kind == tokPunct && t.text == "{":
+		var branches [][]pattern
+		for {
+			b, err := p.block(stageKind)
+			if err != nil {
+				return pattern{}, err
+			}
+			branches = append(branches, b)
+			if !p.isWord("or") {
+				break
+			}
+			p.next()
+		}
+		if len(branches) == 1 {
+			return pattern{kind: "and", inner: branches[0]}, p.endStatement()
+		}
+		return pattern{kind: "or", branches: branches}, p.endStatement()
+	case stageKind == "delete" && t.kind == tokWord && t.text == "has":
+		p.next()
+		return p.deleteOf()
+	case stageKind == "delete" && t.kind == tokWord && t.text == "links":
+		p.next()
+		players, err := p.rolePlayers()
+		if err != nil {
+			return pattern{}, err
+		}
+		if err := p.expect("of"); err != nil {
+			return pattern{}, err
+		}
+		subject, err := p.expectVar()
+		if err != nil {
+			return pattern{}, err
+		}
+		return pattern{kind: "unlink", subject: subject, constraints: []constraint{{kind: "links", players: players}}}, p.endStatement()
+	case t.kind != tokVar:
+		return pattern{}, fmt.Errorf("unsupported statement starting with %s", describe(t))
+	}
+
+	subject := p.next().text
+	if stageKind == "delete" {
+		if p.isWord("of") {
+			p.pos--
+			return p.deleteOf()
+		}
+		if p.isPunct(";") || p.atStageEnd() || p.isPunct("}") {
+			return pattern{kind: "delete", subject: subject}, p.endStatement()
+		}
+	}
+	if op, ok := p.comparator(); ok {
+		right, err := p.operand()
+		if err != nil {
+			return pattern{}, err
+		}
+		return pattern{kind: "cmp", op: op, left: operand{varName: subject}, right: right}, p.endStatement()
+	}
+
+	pat := pattern{kind: "stmt", subject: subject}
+	for {
+		c, err := p.constraint()
+		if err != nil {
+			return pattern{}, err
+		}
+		pat.constraints = append(pat.constraints, c)
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return pat, p.endStatement()
+}
+
+// deleteOf parses "$attr of $owner" after an optional "has".
+func (p *parser) deleteOf() (pattern, error) {
+	attr, err := p.expectVar()
+	if err != nil {
+		return pattern{}, err
+	}
+	if err := p.expect("of"); err != nil {
+		return pattern{}, err
+	}
+	owner, err := p.expectVar()
+	if err != nil {
+		return pattern{}, err
+	}
+	return pattern{kind: "unhas", subject: owner, left: operand{varName: attr}}, p.endStatement()
+}
+
+func (p *parser) comparator() (string, bool) {
+	t := p.peek()
+	if t.kind == tokPunct && (t.text == "==" || t.text == "!=" || t.text == "<" || t.text == "<=" || t.text == ">" || t.text == ">=") {
+		p.next()
+		return t.text, true
+	}
+	if t.kind == tokWord && (t.text == "contains" || t.text == "like") {
+		p.next()
+		return t.text, true
+	}
+	return "", false
+}
+
+func (p *parser) constraint() (constraint, error) {
+	w, err := p.expectWord()
+	if err != nil {
+		return constraint{}, err
+	}
+	c := constraint{kind: w}
+	switch w {
+	case "isa", "sub":
+		if p.isPunct("!") {
+			p.next()
+			c.kind += "!"
+		}
+		t := p.next()
+		switch t.kind {
+		case tokWord:
+			c.label = t.text
+		case tokVar:
+			c.labelVar = t.text
+		default:
+			return c, fmt.Errorf("expected a type after %s, found %s", c.kind, describe(t))
+		}
+	case "has":
+		if c.label, err = p.expectWord(); err != nil {
+			return c, err
+		}
+		if c.value, err = p.operand(); err != nil {
+			return c, err
+		}
+	case "iid":
+		if c.label, err = p.expectWord(); err != nil {
+			return c, err
+		}
+	case "links":
+		if c.players, err = p.rolePlayers(); err != nil {
+			return c, err
+		}
+	default:
+		return c, fmt.Errorf("unsupported constraint %q", w)
+	}
+	return c, nil
+}
+
+func (p *parser) rolePlayers() ([]rolePlayer, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var players []rolePlayer
+	for {
+		var rp rolePlayer
+		t := p.next()
+		switch {
+		case t.kind == tokWord && p.isPunct(":"):
+			p.next()
+			rp.role = t.text[strings.LastIndexByte(t.text, ':')+1:]
+			v, err := p.expectVar()
+			if err != nil {
+				return nil, err
+			}
+			rp.player = v
+		case t.kind == tokVar && p.isPunct(":"):
+			p.next()
+			rp.roleVar = t.text
+			v, err := p.expectVar()
+			if err != nil {
+				return nil, err
+			}
+			rp.player = v
+		case t.kind == tokVar:
+			rp.player = t.text
+		default:
+			return nil, fmt.Errorf("expected a role player, found %s", describe(t))
+		}
+		players = append(players, rp)
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return players, p.expect(")")
+}
+
+func (p *parser) operand() (operand, error) {
+	t := p.next()
+	switch t.kind {
+	case tokVar:
+		return operand{varName: t.text}, nil
+	case tokString:
+		return operand{lit: &value{kind: "string", v: t.text}}, nil
+	case tokWord:
+		v, err := parseLiteral(t.text)
+		if err != nil {
+			return operand{}, err
+		}
+		return operand{lit: &v}, nil
+	}
+	return operand{}, fmt.Errorf("expected a value, found %s", describe(t))
+}
+
+var (
+	intRe      = regexp.MustCompile(`^-?\d+$`)
+	doubleRe   = regexp.MustCompile(`^-?\d+\.\d+([eE][-+]?\d+)?$`)
+	dateRe     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	datetimeRe = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2}(\.\d+)?)?$`)
+)
+
+// parseLiteral parses an unquoted TypeQL value.
+func parseLiteral(text string) (value, error) {
+	switch {
+	case text == "true" || text == "false":
+		return value{kind: "boolean", v: text == "true"}, nil
+	case intRe.MatchString(text):
+		n, err := strconv.ParseInt(text, 10, 64)
+		return value{kind: "integer", v: n}, err
+	case doubleRe.MatchString(text):
+		f, err := strconv.ParseFloat(text, 64)
+		return value{kind: "double", v: f}, err
+	case dateRe.MatchString(text):
+		t, err := time.Parse("2006-01-02", text)
+		return value{kind: "date", v: t}, err
+	case datetimeRe.MatchString(text):
+		layout := "2006-01-02T15:04"
+		if len(text) > len(layout) {
+			layout = "2006-01-02T15:04:05"
+		}
+		t, err := time.Parse(layout, text)
+		return value{kind: "datetime", v: t}, err
+	}
+	if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return value{kind: "datetime-tz", v: t}, nil
+	}
+	return value{}, fmt.Errorf("unsupported value %q", text)
+}
+
+// fetchEntries parses the entries of a fetch object after its "{".
+func (p *parser) fetchEntries() ([]fetchEntry, error) {
+	var entries []fetchEntry
+	for !p.isPunct("}") {
+		var e fetchEntry
+		switch t := p.next(); t.kind {
+		case tokString:
+			e.key = t.text
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if err := p.fetchValue(&e); err != nil {
+				return nil, fmt.Errorf("%q: %w", e.key, err)
+			}
+		case tokVar:
+			// A spread "$e.*" merges all attributes into the object.
+			if err := p.expect("."); err != nil {
+				return nil, err
+			}
+			if err := p.expect("*"); err != nil {
+				return nil, err
+			}
+			e = fetchEntry{expr: "all", varName: t.text}
+		default:
+			return nil, fmt.Errorf("expected a fetch key, found %s", describe(t))
+		}
+		entries = append(entries, e)
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return entries, p.expect("}")
+}
+
+func (p *parser) fetchValue(e *fetchEntry) error {
+	t := p.next()
+	switch {
+	case t.kind == tokWord && (t.text == "iid" || t.text == "label"):
+		e.expr = t.text
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		v, err := p.expectVar()
+		if err != nil {
+			return err
+		}
+		e.varName = v
+		return p.expect(")")
+	case t.kind == tokVar:
+		e.varName = t.text
+		if !p.isPunct(".") {
+			e.expr = "var"
+			return nil
+		}
+		p.next()
+		if p.isPunct("*") {
+			p.next()
+			e.expr = "all"
+			return nil
+		}
+		attr, err := p.expectWord()
+		e.expr, e.attr = "attr", attr
+		return err
+	case t.kind == tokPunct && t.text == "[" && p.isWord("match"):
+		sub := &query{}
+		for !p.isPunct("]") {
+			s, err := p.stage()
+			if err != nil {
+				return err
+			}
+			sub.stages = append(sub.stages, s)
+		}
+		p.next()
+		e.expr, e.sub = "subquery", sub
+		return nil
+	case t.kind == tokPunct && t.text == "[":
+		v, err := p.expectVar()
+		if err != nil {
+			return fmt.Errorf("only [$var.attribute] lists are supported")
+		}
+		if err := p.expect("."); err != nil {
+			return err
+		}
+		attr, err := p.expectWord()
+		if err != nil {
+			return err
+		}
+		e.expr, e.varName, e.attr = "attrs", v, attr
+		return p.expect("]")
+	case t.kind == tokPunct && t.text == "{":
+		nested, err := p.fetchEntries()
+		e.expr, e.nested = "object", nested
+		return err
+	}
+	return fmt.Errorf("unsupported fetch value %s", describe(t))
+}
+
+func (p *parser) reduce(s *stage) error {
+	for {
+		v, err := p.expectVar()
+		if err != nil {
+			return err
+		}
+		if err := p.expect("="); err != nil {
+			return err
+		}
+		fn, err := p.expectWord()
+		if err != nil {
+			return err
+		}
+		switch fn {
+		case "count", "sum", "mean", "min", "max", "median", "std", "variance":
+		default:
+			return fmt.Errorf("unsupported reducer %q", fn)
+		}
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		a := reduceAssign{varName: v, fn: fn}
+		if !p.isPunct(")") {
+			if a.arg, err = p.expectVar(); err != nil {
+				return err
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		s.reduces = append(s.reduces, a)
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+		// gotype separates the grouping from the reducers with a comma.
+		if p.isWord("group") || p.isWord("groupby") {
+			break
+		}
+	}
+	if p.isWord("group") || p.isWord("groupby") {
+		p.next()
+		for {
+			v, err := p.expectVar()
+			if err != nil {
+				return err
+			}
+			s.groupBy = append(s.groupBy, v)
+			if !p.isPunct(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	return p.expect(";")
+}
@@ -0,0 +1,544 @@
+package gotypetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// recording is the file written by Recorder and read by Replayer.
+type recording struct {
+	Database     string        `json:"database,omitempty"`
+	Interactions []interaction `json:"interactions"`
+}
+
+// interaction is one call to the connection or one of its transactions, with
+// what it returned.
+type interaction struct {
+	Op       string           `json:"op"` // "transaction", "query", "commit", "rollback", "schema" or a database operation
+	Database string           `json:"database,omitempty"`
+	TxType   int              `json:"tx_type,omitempty"`
+	Query    string           `json:"query,omitempty"`
+	Rows     []map[string]any `json:"rows,omitempty"`
+	Result   any              `json:"result,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// key identifies the calls an interaction answers.
+func (in *interaction) key() string {
+	return strings.Join([]string{in.Op, in.Database, strconv.Itoa(in.TxType), in.Query}, "\x00")
+}
+
+func (in *interaction) describe() string {
+	s := in.Op
+	if in.Database != "" {
+		s += " on " + in.Database
+	}
+	if in.Query != "" {
+		s += ":\n" + in.Query
+	}
+	return s
+}
+
+// errorText returns the message recorded for err.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// replayedError returns the error an interaction recorded, if any.
+func (in *interaction) replayedError() error {
+	if in.Error == "" {
+		return nil
+	}
+	return errors.New(in.Error)
+}
+
+// Recorder is a gotype.Conn that forwards every call to another connection
+// and records each query with the rows or error it returned, so the run can
+// later be replayed without a server by a Replayer. Save writes the
+// recording.
+type Recorder struct {
+	conn gotype.Conn
+
+	mu       sync.Mutex
+	database string
+	recorded []interaction
+}
+
+// NewRecorder returns a Recorder forwarding to conn.
+func NewRecorder(conn gotype.Conn) *Recorder {
+	return &Recorder{conn: conn}
+}
+
+func (r *Recorder) record(in interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.database == "" {
+		r.database = in.Database
+	}
+	r.recorded = append(r.recorded, in)
+}
+
+// Save writes the interactions recorded so far to path as indented JSON,
+// creating its directory if needed.
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	rec := recording{Database: r.database, Interactions: make([]interaction, len(r.recorded))}
+	copy(rec.Interactions, r.recorded)
+	r.mu.Unlock()
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save recording: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("save recording: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Transaction opens a transaction on the wrapped connection.
+func (r *Recorder) Transaction(dbName string, txType int) (gotype.Tx, error) {
+	return r.TransactionContext(context.Background(), dbName, txType)
+}
+
+// TransactionContext opens a transaction, passing ctx on when the wrapped
+// connection accepts one.
+func (r *Recorder) TransactionContext(ctx context.Context, dbName string, txType int) (gotype.Tx, error) {
+	var tx gotype.Tx
+	var err error
+	if conn, ok := r.conn.(interface {
+		TransactionContext(context.Context, string, int) (gotype.Tx, error)
+	}); ok {
+		tx, err = conn.TransactionContext(ctx, dbName, txType)
+	} else {
+		tx, err = r.conn.Transaction(dbName, txType)
+	}
+	r.record(interaction{Op: "transaction", Database: dbName, TxType: txType, Error: errorText(err)})
+	if err != nil {
+		return nil, err
+	}
+	return &recordingTx{tx: tx, r: r, dbName: dbName, txType: txType}, nil
+}
+
+// Schema returns the schema from the wrapped connection.
+func (r *Recorder) Schema(dbName string) (string, error) {
+	schema, err := r.conn.Schema(dbName)
+	r.record(interaction{Op: "schema", Database: dbName, Result: schema, Error: errorText(err)})
+	return schema, err
+}
+
+// DatabaseCreate creates the database on the wrapped connection.
+func (r *Recorder) DatabaseCreate(name string) error {
+	err := r.conn.DatabaseCreate(name)
+	r.record(interaction{Op: "database-create", Database: name, Error: errorText(err)})
+	return err
+}
+
+// DatabaseDelete deletes the database on the wrapped connection.
+func (r *Recorder) DatabaseDelete(name string) error {
+	err := r.conn.DatabaseDelete(name)
+	r.record(interaction{Op: "database-delete", Database: name, Error: errorText(err)})
+	return err
+}
+
+// DatabaseContains reports whether the database exists on the wrapped
+// connection.
+func (r *Recorder) DatabaseContains(name string) (bool, error) {
+	ok, err := r.conn.DatabaseContains(name)
+	r.record(interaction{Op: "database-contains", Database: name, Result: ok, Error: errorText(err)})
+	return ok, err
+}
+
+// DatabaseAll lists the databases of the wrapped connection.
+func (r *Recorder) DatabaseAll() ([]string, error) {
+	names, err := r.conn.DatabaseAll()
+	r.record(interaction{Op: "database-all", Result: names, Error: errorText(err)})
+	return names, err
+}
+
+// Close closes the wrapped connection. It does not save the recording.
+func (r *Recorder) Close() { r.conn.Close() }
+
+// IsOpen reports whether the wrapped connection is open.
+func (r *Recorder) IsOpen() bool { return r.conn.IsOpen() }
+
+type recordingTx struct {
+	tx     gotype.Tx
+	r      *Recorder
+	dbName string
+	txType int
+}
+
+func (t *recordingTx) Query(query string) ([]map[string]any, error) {
+	return t.QueryWithContext(context.Background(), query)
+}
+
+func (t *recordingTx) QueryWithContext(ctx context.Context, query string) ([]map[string]any, error) {
+	rows, err := t.tx.QueryWithContext(ctx, query)
+	t.r.record(interaction{Op: "query", Database: t.dbName, TxType: t.txType, Query: query, Rows: encodeRows(rows), Error: errorText(err)})
+	return rows, err
+}
+
+func (t *recordingTx) Commit() error {
+	err := t.tx.Commit()
+	t.r.record(interaction{Op: "commit", Database: t.dbName, TxType: t.txType, Error: errorText(err)})
+	return err
+}
+
+func (t *recordingTx) Rollback() error {
+	err := t.tx.Rollback()
+	t.r.record(interaction{Op: "rollback", Database: t.dbName, TxType: t.txType, Error: errorText(err)})
+	return err
+}
+
+func (t *recordingTx) Close()       { t.tx.Close() }
+func (t *recordingTx) IsOpen() bool { return t.tx.IsOpen() }
+
+// Replayer is a gotype.Conn answering calls from a recording made by
+// Recorder, without a server. Each call gets the response recorded for the
+// same operation, database, transaction type and query text; calls repeated
+// with the same arguments get their responses in recorded order. A call
+// with no response left fails with an error naming it.
+//
+// Recorded errors are replayed as plain errors with the same message, and
+// numbers come back as int64, uint64 or float64, so hydration sees the
+// payload shapes of the real driver.
+type Replayer struct {
+	database string
+
+	mu      sync.Mutex
+	pending map[string][]interaction
+	order   []string // keys in recorded order, for Unused
+	closed  bool
+}
+
+// NewReplayer loads the recording at path.
+func NewReplayer(path string) (*Replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load recording: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	var rec recording
+	if err := dec.Decode(&rec); err != nil {
+		return nil, fmt.Errorf("load recording %s: %w", path, err)
+	}
+	p := &Replayer{database: rec.Database, pending: make(map[string][]interaction)}
+	for _, in := range rec.Interactions {
+		for _, row := range in.Rows {
+			for k, v := range row {
+				row[k] = decodeValue(v)
+			}
+		}
+		in.Result = decodeValue(in.Result)
+		k := in.key()
+		if _, seen := p.pending[k]; !seen {
+			p.order = append(p.order, k)
+		}
+		p.pending[k] = append(p.pending[k], in)
+	}
+	return p, nil
+}
+
+// Database returns the name of the database the recording was made on.
+func (p *Replayer) Database() string { return p.database }
+
+// Unused describes the recorded calls that have not been replayed, so a
+// test can check that the code under test still makes all of them.
+func (p *Replayer) Unused() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var unused []string
+	for _, k := range p.order {
+		for _, in := range p.pending[k] {
+			unused = append(unused, in.describe())
+		}
+	}
+	return unused
+}
+
+// next returns the response recorded for want.
+func (p *Replayer) next(want interaction) (interaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := want.key()
+	queue := p.pending[k]
+	if len(queue) == 0 {
+		return interaction{}, fmt.Errorf("gotypetest: replay: no recorded response for %s", want.describe())
+	}
+	p.pending[k] = queue[1:]
+	return queue[0], nil
+}
+
+// Transaction replays opening a transaction.
+func (p *Replayer) Transaction(dbName string, txType int) (gotype.Tx, error) {
+	in, err := p.next(interaction{Op: "transaction", Database: dbName, TxType: txType})
+	if err != nil {
+		return nil, err
+	}
+	if err := in.replayedError(); err != nil {
+		return nil, err
+	}
+	return &replayTx{p: p, dbName: dbName, txType: txType, open: true}, nil
+}
+
+// Schema replays a schema request.
+func (p *Replayer) Schema(dbName string) (string, error) {
+	in, err := p.next(interaction{Op: "schema", Database: dbName})
+	if err != nil {
+		return "", err
+	}
+	schema, _ := in.Result.(string)
+	return schema, in.replayedError()
+}
+
+// DatabaseCreate replays creating a database.
+func (p *Replayer) DatabaseCreate(name string) error {
+	return p.replayError(interaction{Op: "database-create", Database: name})
+}
+
+// DatabaseDelete replays deleting a database.
+func (p *Replayer) DatabaseDelete(name string) error {
+	return p.replayError(interaction{Op: "database-delete", Database: name})
+}
+
+// DatabaseContains replays checking whether a database exists.
+func (p *Replayer) DatabaseContains(name string) (bool, error) {
+	in, err := p.next(interaction{Op: "database-contains", Database: name})
+	if err != nil {
+		return false, err
+	}
+	ok, _ := in.Result.(bool)
+	return ok, in.replayedError()
+}
+
+// DatabaseAll replays listing the databases.
+func (p *Replayer) DatabaseAll() ([]string, error) {
+	in, err := p.next(interaction{Op: "database-all"})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if list, ok := in.Result.([]any); ok {
+		for _, v := range list {
+			if s, ok := v.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+	return names, in.replayedError()
+}
+
+func (p *Replayer) replayError(want interaction) error {
+	in, err := p.next(want)
+	if err != nil {
+		return err
+	}
+	return in.replayedError()
+}
+
+// Close marks the Replayer closed.
+func (p *Replayer) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+}
+
+// IsOpen reports whether Close has not been called.
+func (p *Replayer) IsOpen() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.closed
+}
+
+type replayTx struct {
+	p      *Replayer
+	dbName string
+	txType int
+	open   bool
+}
+
+func (t *replayTx) Query(query string) ([]map[string]any, error) {
+	return t.QueryWithContext(context.Background(), query)
+}
+
+func (t *replayTx) QueryWithContext(ctx context.Context, query string) ([]map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	in, err := t.p.next(interaction{Op: "query", Database: t.dbName, TxType: t.txType, Query: query})
+	if err != nil {
+		return nil, err
+	}
+	if err := in.replayedError(); err != nil {
+		return nil, err
+	}
+	rows := make([]map[string]any, len(in.Rows))
+	for i, row := range in.Rows {
+		rows[i] = cloneRow(row)
+	}
+	return rows, nil
+}
+
+func (t *replayTx) Commit() error {
+	t.open = false
+	return t.p.replayError(interaction{Op: "commit", Database: t.dbName, TxType: t.txType})
+}
+
+func (t *replayTx) Rollback() error {
+	return t.p.replayError(interaction{Op: "rollback", Database: t.dbName, TxType: t.txType})
+}
+
+func (t *replayTx) Close()       { t.open = false }
+func (t *replayTx) IsOpen() bool { return t.open }
+
+// cloneRow copies a replayed row so callers may modify it.
+func cloneRow(row map[string]any) map[string]any {
+	out := make(map[string]any, len(row))
+	for k, v := range row {
+		out[k] = cloneValue(v)
+	}
+	return out
+}
+
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return cloneRow(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = cloneValue(e)
+		}
+		return out
+	}
+	return v
+}
+
+// encodeRows copies rows, prepared for JSON so that decodeValue restores
+// their number types: whole floats keep a decimal point, which
+// encoding/json would drop.
+func encodeRows(rows []map[string]any) []map[string]any {
+	if rows == nil {
+		return nil
+	}
+	out := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		enc := make(map[string]any, len(row))
+		for k, v := range row {
+			enc[k] = encodeValue(v)
+		}
+		out[i] = enc
+	}
+	return out
+}
+
+func encodeValue(v any) any {
+	switch v := v.(type) {
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return json.Number(strconv.FormatFloat(v, 'f', 1, 64))
+		}
+	case float32:
+		return encodeValue(float64(v))
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = encodeValue(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = encodeValue(e)
+		}
+		return out
+	}
+	return v
+}
+
+// decodeValue converts the json.Numbers of a decoded value to int64,
+// uint64 or float64.
+func decodeValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		s := v.String()
+		if !strings.ContainsAny(s, ".eE") {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+			if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+				return n
+			}
+		}
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	case map[string]any:
+		for k, e := range v {
+			v[k] = decodeValue(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = decodeValue(e)
+		}
+	}
+	return v
+}
+
+// RecordOrReplay returns a database for a test that runs against a server
+// once and from a recording afterwards. With "go test -update", or when
+// GOTYPETEST_UPDATE=1, it calls dial, records every call made through the
+// returned database and saves the recording to path when the test ends.
+// Otherwise it replays path, without calling dial:
+//
+//	db := gotypetest.RecordOrReplay(t, "testdata/signup.json", func() *gotype.Database {
+//		return gotypetest.StartTypeDB(t)
+//	})
+//
+// Replay matches calls by query text, so the test must generate the same
+// queries on every run: no random names or current times. The test fails
+// if replayed code makes a call that was not recorded, or leaves recorded
+// calls unmade. The returned database uses the default registry; set
+// another with WithRegistry.
+func RecordOrReplay(t testing.TB, path string, dial func() *gotype.Database) *gotype.Database {
+	t.Helper()
+	if *update || os.Getenv("GOTYPETEST_UPDATE") == "1" {
+		db := dial()
+		rec := NewRecorder(db.GetConn())
+		t.Cleanup(func() {
+			if err := rec.Save(path); err != nil {
+				t.Errorf("gotypetest: %v", err)
+			}
+		})
+		return gotype.NewDatabase(rec, db.Name())
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		t.Fatalf("gotypetest: recording %s does not exist; run go test -update against a server to create it", path)
+	}
+	p, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("gotypetest: %v", err)
+	}
+	t.Cleanup(func() {
+		if unused := p.Unused(); len(unused) > 0 && !t.Failed() {
+			t.Errorf("gotypetest: %d recorded calls were not replayed, starting with %s", len(unused), unused[0])
+		}
+	})
+	return gotype.NewDatabase(p, p.Database())
+}
@@ -0,0 +1,108 @@
+package gotypetest_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+)
+
+// exercise runs the same calls against a recording or a replaying database
+// and returns what they produced.
+func exercise(t *testing.T, db *gotype.Database) (*person, error) {
+	t.Helper()
+	ctx := context.Background()
+	persons := gotype.MustNewManager[person](db)
+	if err := persons.Insert(ctx, &person{Name: "Alice", Age: intPtr(30), Email: "alice@example.com", Score: 4, Tags: []string{"admin", "dev"}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	got, err := persons.Get(ctx, map[string]any{"name": "Alice"})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+	return got[0], persons.Insert(ctx, &person{Name: "Alice", Email: "other@example.com"})
+}
+
+func TestRecorderReplayer(t *testing.T) {
+	_, fakeDB := newTestDB(t)
+	reg := fakeDB.Registry()
+	path := filepath.Join(t.TempDir(), "recording.json")
+
+	rec := gotypetest.NewRecorder(fakeDB.GetConn())
+	recorded, recordedErr := exercise(t, gotype.NewDatabase(rec, "test").WithRegistry(reg))
+	if recordedErr == nil {
+		t.Fatal("inserting a duplicate key should fail")
+	}
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "4.0") {
+		t.Errorf("whole floats should keep their decimal point:\n%s", data)
+	}
+
+	p, err := gotypetest.NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Database() != "test" {
+		t.Errorf("Database = %q", p.Database())
+	}
+	replayed, replayedErr := exercise(t, gotype.NewDatabase(p, p.Database()).WithRegistry(reg))
+	if replayed.GetIID() != recorded.GetIID() || *replayed.Age != 30 || replayed.Score != 4 ||
+		strings.Join(replayed.Tags, ",") != "admin,dev" {
+		t.Errorf("replayed %+v, recorded %+v", replayed, recorded)
+	}
+	if replayedErr == nil || replayedErr.Error() != recordedErr.Error() {
+		t.Errorf("replayed error %v, recorded %v", replayedErr, recordedErr)
+	}
+	if unused := p.Unused(); len(unused) != 0 {
+		t.Errorf("Unused = %q", unused)
+	}
+
+	_, err = gotype.MustNewManager[person](gotype.NewDatabase(p, "test").WithRegistry(reg)).All(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("unrecorded call: %v", err)
+	}
+}
+
+func TestRecordOrReplay(t *testing.T) {
+	_, fakeDB := newTestDB(t)
+	reg := fakeDB.Registry()
+	path := filepath.Join(t.TempDir(), "testdata", "recording.json")
+
+	t.Run("record", func(t *testing.T) {
+		t.Setenv("GOTYPETEST_UPDATE", "1")
+		db := gotypetest.RecordOrReplay(t, path, func() *gotype.Database { return fakeDB })
+		exercise(t, db.WithRegistry(reg))
+	})
+	if flag.Lookup("update").Value.String() == "true" {
+		t.Skip("-update records instead of replaying")
+	}
+	t.Run("replay", func(t *testing.T) {
+		t.Setenv("GOTYPETEST_UPDATE", "0")
+		db := gotypetest.RecordOrReplay(t, path, func() *gotype.Database {
+			t.Fatal("replay should not dial")
+			return nil
+		})
+		exercise(t, db.WithRegistry(reg))
+	})
+
+	var rec *recorder
+	t.Run("unused", func(t *testing.T) {
+		t.Setenv("GOTYPETEST_UPDATE", "0")
+		rec = &recorder{TB: t}
+		gotypetest.RecordOrReplay(rec, path, nil)
+	})
+	if !strings.Contains(rec.msg, "were not replayed") {
+		t.Errorf("unused calls message = %q", rec.msg)
+	}
+}
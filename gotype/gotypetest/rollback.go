@@ -0,0 +1,42 @@
+package gotypetest
+
+import (
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// WithRollback runs fn inside a write transaction that is always rolled
+// back, so integration tests can share a database without cleaning up
+// after each case:
+//
+//	gotypetest.WithRollback(t, db, func(tc *gotype.TransactionContext) {
+//		persons := gotype.MustNewManagerWithTx[Person](tc)
+//		...
+//	})
+//
+// Only work done through tc is isolated: managers bound to it with
+// gotype.NewManagerWithTx, and tc.Tx() queries. Code that opens its own
+// transactions on db writes to the database, and does not see the
+// uncommitted data. The rollback also runs when fn fails the test or
+// panics. fn must not commit or close tc; WithRollback fails the test if it
+// did.
+func WithRollback(t testing.TB, db *gotype.Database, fn func(tc *gotype.TransactionContext)) {
+	t.Helper()
+	tc, err := db.Begin(gotype.WriteTransaction)
+	if err != nil {
+		t.Fatalf("gotypetest: WithRollback: %v", err)
+	}
+	defer func() {
+		if !tc.Tx().IsOpen() {
+			tc.Close()
+			t.Errorf("gotypetest: WithRollback: the transaction was committed or closed; its changes may have been kept")
+			return
+		}
+		if err := tc.Rollback(); err != nil {
+			t.Errorf("gotypetest: WithRollback: rollback: %v", err)
+		}
+		tc.Close()
+	}()
+	fn(tc)
+}
@@ -0,0 +1,44 @@
+package gotypetest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+)
+
+func TestWithRollback(t *testing.T) {
+	ctx := context.Background()
+	_, db := newTestDB(t)
+	persons := gotype.MustNewManager[person](db)
+
+	for _, name := range []string{"Alice", "Bob"} {
+		t.Run(name, func(t *testing.T) {
+			gotypetest.WithRollback(t, db, func(tc *gotype.TransactionContext) {
+				inTx := gotype.MustNewManagerWithTx[person](tc)
+				// The same key in every case: earlier cases left nothing behind.
+				if err := inTx.Insert(ctx, &person{Name: "Temp", Email: name + "@example.com"}); err != nil {
+					t.Fatal(err)
+				}
+				got, err := inTx.Get(ctx, map[string]any{"name": "Temp"})
+				if err != nil || len(got) != 1 || got[0].Email != name+"@example.com" {
+					t.Errorf("Get in transaction = %v, %v", got, err)
+				}
+			})
+		})
+	}
+	if n, err := persons.Query().Count(ctx); err != nil || n != 0 {
+		t.Errorf("Count after WithRollback = %d, %v", n, err)
+	}
+
+	rec := &recorder{TB: t}
+	gotypetest.WithRollback(rec, db, func(tc *gotype.TransactionContext) {
+		if err := tc.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if rec.msg == "" {
+		t.Error("committing inside WithRollback should fail the test")
+	}
+}
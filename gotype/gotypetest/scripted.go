@@ -0,0 +1,324 @@
+package gotypetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ScriptedConn is a gotype.Conn that answers queries with scripted rows and
+// records everything it is asked, for unit tests that check which queries
+// code sends rather than what a database would do with them:
+//
+//	conn := gotypetest.NewScriptedConn()
+//	conn.On(`insert \$e isa person`).Return(map[string]any{"_iid": "0x1"})
+//	conn.On(`isa person`).Return(map[string]any{"name": "Alice"})
+//	db := gotype.NewDatabase(conn, "test")
+//	...
+//	q := conn.Queries()[0]
+//
+// Each query gets the response of the first rule, in the order they were
+// added, whose pattern matches it and which has uses left. Queries no rule
+// matches get no rows. Patterns are regular expressions matched anywhere in
+// the query text; use (?s) to let "." span lines.
+//
+// ScriptedConn is safe for concurrent use. Use FakeDB instead when the code
+// under test needs data to persist between queries.
+type ScriptedConn struct {
+	mu        sync.Mutex
+	rules     []*Response
+	txs       []*ScriptedTx
+	queries   []string
+	dbs       map[string]bool
+	schema    string
+	txErr     error
+	commitErr error
+	closed    bool
+}
+
+// NewScriptedConn returns a ScriptedConn with no rules.
+func NewScriptedConn() *ScriptedConn {
+	return &ScriptedConn{dbs: make(map[string]bool)}
+}
+
+// Response is the answer scripted for the queries matching a pattern.
+type Response struct {
+	pattern *regexp.Regexp
+	rows    []map[string]any
+	err     error
+	uses    int // remaining uses; negative means unlimited
+}
+
+// On adds a rule for the queries matching pattern and returns its response,
+// which answers with no rows until Return or Fail is called. Script
+// responses before the code under test runs. On panics if pattern is not a
+// valid regular expression.
+func (c *ScriptedConn) On(pattern string) *Response {
+	r := &Response{pattern: regexp.MustCompile(pattern), uses: -1}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules = append(c.rules, r)
+	return r
+}
+
+// Return makes the matching queries return rows.
+func (r *Response) Return(rows ...map[string]any) *Response {
+	r.rows, r.err = rows, nil
+	return r
+}
+
+// Fail makes the matching queries fail with err.
+func (r *Response) Fail(err error) *Response {
+	r.rows, r.err = nil, err
+	return r
+}
+
+// Times limits the response to the next n matching queries; later ones fall
+// through to the following rules. Adding the same pattern again after
+// Times(1) scripts successive answers to a repeated query.
+func (r *Response) Times(n int) *Response {
+	r.uses = n
+	return r
+}
+
+// Once is Times(1).
+func (r *Response) Once() *Response { return r.Times(1) }
+
+// respond returns the scripted answer for query.
+func (c *ScriptedConn) respond(query string) ([]map[string]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range c.rules {
+		if r.uses == 0 || !r.pattern.MatchString(query) {
+			continue
+		}
+		if r.uses > 0 {
+			r.uses--
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		rows := make([]map[string]any, len(r.rows))
+		for i, row := range r.rows {
+			rows[i] = maps.Clone(row)
+		}
+		return rows, nil
+	}
+	return nil, nil
+}
+
+// FailTransactions makes opening transactions fail with err; nil restores
+// success.
+func (c *ScriptedConn) FailTransactions(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.txErr = err
+}
+
+// FailCommits makes committing transactions fail with err; nil restores
+// success.
+func (c *ScriptedConn) FailCommits(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commitErr = err
+}
+
+// SetSchema sets the schema returned by Schema.
+func (c *ScriptedConn) SetSchema(schema string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schema = schema
+}
+
+// Transactions returns the transactions opened so far, in order.
+func (c *ScriptedConn) Transactions() []*ScriptedTx {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.txs)
+}
+
+// Queries returns every query sent so far, in order, across transactions.
+func (c *ScriptedConn) Queries() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.queries)
+}
+
+// Transaction opens a scripted transaction. The database is created if it
+// does not exist.
+func (c *ScriptedConn) Transaction(dbName string, txType int) (gotype.Tx, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, errors.New("gotypetest: connection is closed")
+	}
+	if c.txErr != nil {
+		return nil, c.txErr
+	}
+	c.dbs[dbName] = true
+	tx := &ScriptedTx{conn: c, txType: gotype.TransactionType(txType), dbName: dbName, open: true}
+	c.txs = append(c.txs, tx)
+	return tx, nil
+}
+
+// Schema returns the schema set with SetSchema.
+func (c *ScriptedConn) Schema(dbName string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.schema, nil
+}
+
+// DatabaseCreate records the database as existing.
+func (c *ScriptedConn) DatabaseCreate(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dbs[name] {
+		return fmt.Errorf("gotypetest: database %s already exists", name)
+	}
+	c.dbs[name] = true
+	return nil
+}
+
+// DatabaseDelete forgets the database.
+func (c *ScriptedConn) DatabaseDelete(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dbs[name] {
+		return fmt.Errorf("gotypetest: database %s does not exist", name)
+	}
+	delete(c.dbs, name)
+	return nil
+}
+
+// DatabaseContains reports whether the database was created or used.
+func (c *ScriptedConn) DatabaseContains(name string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dbs[name], nil
+}
+
+// DatabaseAll returns the database names in order.
+func (c *ScriptedConn) DatabaseAll() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Sorted(maps.Keys(c.dbs)), nil
+}
+
+// Close makes later transactions fail.
+func (c *ScriptedConn) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+// IsOpen reports whether Close has not been called.
+func (c *ScriptedConn) IsOpen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.closed
+}
+
+// ScriptedTx is a transaction of a ScriptedConn. Its accessors report what
+// the code under test did with it.
+type ScriptedTx struct {
+	conn   *ScriptedConn
+	txType gotype.TransactionType
+	dbName string
+
+	// Guarded by conn.mu.
+	queries    []string
+	open       bool
+	committed  bool
+	rolledBack bool
+}
+
+// Type returns the transaction type.
+func (t *ScriptedTx) Type() gotype.TransactionType { return t.txType }
+
+// Database returns the name of the database the transaction was opened on.
+func (t *ScriptedTx) Database() string { return t.dbName }
+
+// Queries returns the queries sent in the transaction, in order.
+func (t *ScriptedTx) Queries() []string {
+	t.conn.mu.Lock()
+	defer t.conn.mu.Unlock()
+	return slices.Clone(t.queries)
+}
+
+// Committed reports whether the transaction was committed successfully.
+func (t *ScriptedTx) Committed() bool {
+	t.conn.mu.Lock()
+	defer t.conn.mu.Unlock()
+	return t.committed
+}
+
+// RolledBack reports whether Rollback was called.
+func (t *ScriptedTx) RolledBack() bool {
+	t.conn.mu.Lock()
+	defer t.conn.mu.Unlock()
+	return t.rolledBack
+}
+
+// Query records query and returns the scripted response.
+func (t *ScriptedTx) Query(query string) ([]map[string]any, error) {
+	return t.QueryWithContext(context.Background(), query)
+}
+
+// QueryWithContext records query and returns the scripted response.
+func (t *ScriptedTx) QueryWithContext(ctx context.Context, query string) ([]map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	t.conn.mu.Lock()
+	if !t.open {
+		t.conn.mu.Unlock()
+		return nil, errors.New("gotypetest: transaction is closed")
+	}
+	t.queries = append(t.queries, query)
+	t.conn.queries = append(t.conn.queries, query)
+	t.conn.mu.Unlock()
+	return t.conn.respond(query)
+}
+
+// Commit closes the transaction, failing if FailCommits is set.
+func (t *ScriptedTx) Commit() error {
+	t.conn.mu.Lock()
+	defer t.conn.mu.Unlock()
+	if !t.open {
+		return errors.New("gotypetest: transaction is closed")
+	}
+	t.open = false
+	if t.conn.commitErr != nil {
+		return t.conn.commitErr
+	}
+	t.committed = true
+	return nil
+}
+
+// Rollback records the rollback. The transaction stays open.
+func (t *ScriptedTx) Rollback() error {
+	t.conn.mu.Lock()
+	defer t.conn.mu.Unlock()
+	t.rolledBack = true
+	return nil
+}
+
+// Close closes the transaction.
+func (t *ScriptedTx) Close() {
+	t.conn.mu.Lock()
+	defer t.conn.mu.Unlock()
+	t.open = false
+}
+
+// IsOpen reports whether the transaction has not been committed or closed.
+func (t *ScriptedTx) IsOpen() bool {
+	t.conn.mu.Lock()
+	defer t.conn.mu.Unlock()
+	return t.open
+}
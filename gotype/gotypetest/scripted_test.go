@@ -0,0 +1,85 @@
+package gotypetest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+)
+
+func newScriptedDB(t *testing.T) (*gotypetest.ScriptedConn, *gotype.Database) {
+	t.Helper()
+	reg := gotype.NewRegistry()
+	if err := gotype.RegisterIn[person](reg); err != nil {
+		t.Fatal(err)
+	}
+	conn := gotypetest.NewScriptedConn()
+	return conn, gotype.NewDatabase(conn, "test").WithRegistry(reg)
+}
+
+func TestScriptedConn(t *testing.T) {
+	ctx := context.Background()
+	conn, db := newScriptedDB(t)
+	persons := gotype.MustNewManager[person](db)
+	conn.On(`^insert`).Return(map[string]any{"_iid": "0xabc"})
+	conn.On(`has name "Alice"`).Return(map[string]any{"_iid": "0xabc", "name": "Alice", "email": "alice@example.com"})
+
+	alice := &person{Name: "Alice", Email: "alice@example.com"}
+	if err := persons.Insert(ctx, alice); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if alice.GetIID() != "0xabc" {
+		t.Errorf("IID = %q", alice.GetIID())
+	}
+	got, err := persons.Get(ctx, map[string]any{"name": "Alice"})
+	if err != nil || len(got) != 1 || got[0].Email != "alice@example.com" {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+	if none, err := persons.Get(ctx, map[string]any{"name": "Bob"}); err != nil || len(none) != 0 {
+		t.Errorf("unmatched query = %v, %v", none, err)
+	}
+
+	txs := conn.Transactions()
+	if len(txs) != 3 || txs[0].Type() != gotype.WriteTransaction || !txs[0].Committed() || txs[1].Type() != gotype.ReadTransaction {
+		t.Fatalf("transactions = %+v", txs)
+	}
+	queries := conn.Queries()
+	if len(queries) != 3 || !strings.Contains(queries[0], `has email "alice@example.com"`) || !strings.Contains(queries[2], `"Bob"`) {
+		t.Errorf("queries = %q", queries)
+	}
+}
+
+func TestScriptedConn_Responses(t *testing.T) {
+	ctx := context.Background()
+	conn, db := newScriptedDB(t)
+	persons := gotype.MustNewManager[person](db)
+
+	conn.On(`^match`).Once().Return(map[string]any{"name": "First", "email": "a@example.com"})
+	conn.On(`^match`).Once().Return()
+	boom := errors.New("boom")
+	conn.On(`^match`).Fail(boom)
+	for i, want := range []string{"First", ""} {
+		got, err := persons.All(ctx)
+		if err != nil || names(got) != want {
+			t.Errorf("call %d: All = %q, %v", i, names(got), err)
+		}
+	}
+	if _, err := persons.All(ctx); !errors.Is(err, boom) {
+		t.Errorf("scripted failure: %v", err)
+	}
+
+	conn.FailCommits(errors.New("conflict"))
+	if err := persons.Insert(ctx, &person{Name: "Bob", Email: "bob@example.com"}); err == nil || !strings.Contains(err.Error(), "conflict") {
+		t.Errorf("Insert with failing commit: %v", err)
+	}
+	if txs := conn.Transactions(); txs[len(txs)-1].Committed() {
+		t.Error("failed commit reported as committed")
+	}
+	conn.FailTransactions(errors.New("unavailable"))
+	if _, err := persons.All(ctx); err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("All with failing transactions: %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+//go:build cgo && typedb
+
+package gotypetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/driver"
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// TypeDBOption configures StartTypeDB.
+type TypeDBOption func(*typedbConfig)
+
+type typedbConfig struct {
+	image    string
+	runtime  string
+	address  string
+	user     string
+	password string
+	timeout  time.Duration
+}
+
+// WithImage sets the TypeDB server image (default DefaultTypeDBImage, or
+// the TYPEDB_IMAGE environment variable).
+func WithImage(image string) TypeDBOption {
+	return func(c *typedbConfig) { c.image = image }
+}
+
+// WithContainerRuntime sets the container CLI, "docker" or "podman"
+// (default: whichever is installed, or the CONTAINER_RUNTIME environment
+// variable).
+func WithContainerRuntime(name string) TypeDBOption {
+	return func(c *typedbConfig) { c.runtime = name }
+}
+
+// WithAddress uses the TypeDB server at address instead of starting a
+// container. It defaults to the TEST_DB_ADDRESS environment variable, so a
+// suite can run against a shared server in CI and its own container
+// elsewhere.
+func WithAddress(address string) TypeDBOption {
+	return func(c *typedbConfig) { c.address = address }
+}
+
+// WithCredentials sets the user and password (default admin/password, the
+// credentials of a fresh server).
+func WithCredentials(user, password string) TypeDBOption {
+	return func(c *typedbConfig) { c.user, c.password = user, password }
+}
+
+// WithStartupTimeout bounds how long StartTypeDB waits for the server to
+// accept connections (default 2 minutes, which includes pulling the image).
+func WithStartupTimeout(d time.Duration) TypeDBOption {
+	return func(c *typedbConfig) { c.timeout = d }
+}
+
+// StartTypeDB starts a TypeDB server in a container, waits until it accepts
+// connections, creates an empty database named after the test and returns
+// it. The database, connection and container are removed when the test
+// ends.
+//
+// The test is skipped when neither docker nor podman is installed, so
+// suites can call StartTypeDB unconditionally. With WithAddress or
+// TEST_DB_ADDRESS, no container is started and only the database is
+// created and dropped.
+//
+//	func TestSignup(t *testing.T) {
+//		db := gotypetest.StartTypeDB(t)
+//		if err := db.ExecuteSchema(ctx, gotype.GenerateSchema()); err != nil {
+//			t.Fatal(err)
+//		}
+//		...
+//	}
+func StartTypeDB(t testing.TB, opts ...TypeDBOption) *gotype.Database {
+	t.Helper()
+	cfg := typedbConfig{
+		image:    envOr("TYPEDB_IMAGE", DefaultTypeDBImage),
+		runtime:  envOr("CONTAINER_RUNTIME", ""),
+		address:  envOr("TEST_DB_ADDRESS", ""),
+		user:     "admin",
+		password: "password",
+		timeout:  2 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
+	defer cancel()
+	addr := cfg.address
+	var server *container
+	if addr == "" {
+		runtime, err := containerRuntime(cfg.runtime)
+		if err != nil {
+			t.Skipf("gotypetest: cannot start TypeDB: %v", err)
+		}
+		if server, addr, err = startContainer(ctx, runtime, cfg.image); err != nil {
+			t.Fatalf("gotypetest: %v", err)
+		}
+		t.Cleanup(server.stop)
+	}
+
+	drv, err := dialUntilReady(ctx, addr, cfg.user, cfg.password)
+	if err != nil {
+		if server != nil {
+			t.Fatalf("gotypetest: TypeDB at %s did not become ready: %v\n%s", addr, err, server.logs())
+		}
+		t.Fatalf("gotypetest: connect to TypeDB at %s: %v", addr, err)
+	}
+	conn := &driverConn{drv: drv}
+	t.Cleanup(conn.Close)
+
+	name := testDatabaseName(t.Name())
+	if err := conn.DatabaseCreate(name); err != nil {
+		t.Fatalf("gotypetest: create database %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		if err := conn.DatabaseDelete(name); err != nil {
+			t.Logf("gotypetest: delete database %s: %v", name, err)
+		}
+	})
+	return gotype.NewDatabase(conn, name)
+}
+
+// dialUntilReady retries connecting until the server answers or ctx ends.
+// A listening port is not enough: the server accepts TCP connections before
+// it can serve requests, so readiness is a successful database listing.
+func dialUntilReady(ctx context.Context, addr, user, password string) (*driver.Driver, error) {
+	for {
+		drv, err := driver.Open(addr, user, password)
+		if err == nil {
+			if _, err = drv.Databases().All(); err == nil {
+				return drv, nil
+			}
+			drv.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// driverConn wraps a *driver.Driver to satisfy gotype.Conn.
+type driverConn struct {
+	drv *driver.Driver
+}
+
+func (c *driverConn) Transaction(dbName string, txType int) (gotype.Tx, error) {
+	tx, err := c.drv.Transaction(dbName, driver.TransactionType(txType))
+	if err != nil {
+		return nil, err
+	}
+	return driverTx{tx}, nil
+}
+
+// driverTx adds gotype.ResultTx to a driver transaction, which returns the
+// answer rows of write queries but no counts.
+type driverTx struct {
+	*driver.Transaction
+}
+
+func (t driverTx) QueryResult(ctx context.Context, query string) (gotype.Result, error) {
+	rows, err := t.QueryWithContext(ctx, query)
+	if err != nil {
+		return gotype.Result{}, err
+	}
+	return gotype.Result{Rows: rows, Summary: gotype.RowSummary(query, rows)}, nil
+}
+
+func (c *driverConn) Schema(dbName string) (string, error) {
+	return c.drv.Databases().Schema(dbName)
+}
+
+func (c *driverConn) DatabaseCreate(name string) error {
+	return c.drv.Databases().Create(name)
+}
+
+func (c *driverConn) DatabaseDelete(name string) error {
+	return c.drv.Databases().Delete(name)
+}
+
+func (c *driverConn) DatabaseContains(name string) (bool, error) {
+	return c.drv.Databases().Contains(name)
+}
+
+func (c *driverConn) DatabaseAll() ([]string, error) {
+	return c.drv.Databases().All()
+}
+
+func (c *driverConn) Close() {
+	c.drv.Close()
+}
+
+func (c *driverConn) IsOpen() bool {
+	return c.drv.IsOpen()
+}
@@ -0,0 +1,31 @@
+//go:build cgo && typedb && integration
+
+package gotypetest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+)
+
+func TestStartTypeDB(t *testing.T) {
+	ctx := context.Background()
+	db := gotypetest.StartTypeDB(t)
+	reg := gotype.NewRegistry()
+	if err := gotype.RegisterIn[company](reg); err != nil {
+		t.Fatal(err)
+	}
+	db = db.WithRegistry(reg)
+	if err := db.ExecuteSchema(ctx, reg.GenerateSchema()); err != nil {
+		t.Fatalf("ExecuteSchema: %v", err)
+	}
+	companies := gotype.MustNewManager[company](db)
+	if err := companies.Insert(ctx, &company{Title: "Acme"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := companies.Query().Count(ctx); err != nil || n != 1 {
+		t.Errorf("Count = %d, %v", n, err)
+	}
+}
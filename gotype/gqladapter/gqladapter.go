@@ -0,0 +1,437 @@
+// Package gqladapter backs GraphQL resolvers, such as the ones gqlgen
+// generates, with gotype queries.
+//
+// List fields take a ListArgs and return a Connection following the Relay
+// cursor connection spec:
+//
+//	func (r *queryResolver) People(ctx context.Context, where map[string]any, orderBy []*model.Order, first *int, after *string) (*gqladapter.Connection[Person], error) {
+//		return gqladapter.Paginate(ctx, r.people.Query(), gqladapter.ListArgs{
+//			Where: where, OrderBy: gqladapter.Orders(orderBy), First: first, After: after,
+//		})
+//	}
+//
+// Where is the decoded GraphQL filter input, with fields named by attribute
+// (name, joined-at), Go field (JoinedAt) or GraphQL field (joinedAt):
+//
+//	{age: {gte: 18}, name: "Alice", OR: [{nickname: {isNull: true}}, {NOT: {tag: {in: ["x"]}}}]}
+//
+// A bare value means eq. The operators are eq, neq, gt, gte, lt, lte, in,
+// notIn, contains, startsWith, regex and isNull; AND, OR and NOT combine
+// filters. Values are converted to the attribute's value type, so datetimes
+// can be given as strings.
+//
+// Fields that traverse relations filter the target type with Linked, and
+// RolePlayerOf resolves a role field of a relation object.
+package gqladapter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ListArgs holds the arguments of a list field.
+type ListArgs struct {
+	Where   map[string]any
+	OrderBy []Order
+	First   *int    // page size; nil returns every remaining row
+	After   *string // cursor of the last row of the previous page
+}
+
+// Order sorts by one field. Direction is "ASC" (the default) or "DESC",
+// in any case.
+type Order struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"`
+}
+
+// Orders converts generated order input types with Field and Direction
+// string fields, such as []*model.Order, to Orders.
+func Orders[O any](in []O) []Order {
+	out := make([]Order, 0, len(in))
+	for _, o := range in {
+		raw, err := json.Marshal(o)
+		if err != nil {
+			continue
+		}
+		var order Order
+		if json.Unmarshal(raw, &order) == nil && order.Field != "" {
+			out = append(out, order)
+		}
+	}
+	return out
+}
+
+// Connection is a page of results in Relay form.
+type Connection[T any] struct {
+	Edges      []*Edge[T] `json:"edges"`
+	PageInfo   *PageInfo  `json:"pageInfo"`
+	TotalCount int64      `json:"totalCount"`
+}
+
+// Nodes returns the instances of the page in order.
+func (c *Connection[T]) Nodes() []*T {
+	nodes := make([]*T, len(c.Edges))
+	for i, e := range c.Edges {
+		nodes[i] = e.Node
+	}
+	return nodes
+}
+
+// Edge is one result with its cursor.
+type Edge[T any] struct {
+	Cursor string `json:"cursor"`
+	Node   *T     `json:"node"`
+}
+
+// PageInfo describes the position of a page.
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+// Paginate applies args to q and runs it, along with a count of every
+// matching row for TotalCount. Filters already on q are kept.
+func Paginate[T any](ctx context.Context, q *gotype.Query[T], args ListArgs) (*Connection[T], error) {
+	if err := Apply(q, args.Where, args.OrderBy); err != nil {
+		return nil, err
+	}
+	offset := 0
+	if args.After != nil {
+		n, err := DecodeCursor(*args.After)
+		if err != nil {
+			return nil, err
+		}
+		offset = n + 1
+	}
+	if args.First != nil && *args.First < 0 {
+		return nil, fmt.Errorf("gqladapter: first must not be negative")
+	}
+
+	total, err := q.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q.Offset(offset)
+	if args.First != nil {
+		// Fetch one extra row to learn whether there is a next page.
+		q.Limit(*args.First + 1)
+	}
+	rows, err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &Connection[T]{
+		Edges:      make([]*Edge[T], 0, len(rows)),
+		PageInfo:   &PageInfo{HasPreviousPage: offset > 0},
+		TotalCount: total,
+	}
+	if args.First != nil && len(rows) > *args.First {
+		rows = rows[:*args.First]
+		conn.PageInfo.HasNextPage = true
+	}
+	for i, row := range rows {
+		conn.Edges = append(conn.Edges, &Edge[T]{Cursor: EncodeCursor(offset + i), Node: row})
+	}
+	if n := len(conn.Edges); n > 0 {
+		conn.PageInfo.StartCursor = &conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = &conn.Edges[n-1].Cursor
+	}
+	return conn, nil
+}
+
+const cursorPrefix = "gotype:"
+
+// EncodeCursor returns the opaque cursor of the row at offset.
+func EncodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// DecodeCursor returns the offset encoded in cursor.
+func DecodeCursor(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err == nil {
+		if text, ok := strings.CutPrefix(string(raw), cursorPrefix); ok {
+			if n, err := strconv.Atoi(text); err == nil && n >= 0 {
+				return n, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("gqladapter: invalid cursor %q", cursor)
+}
+
+// Apply adds the filters of where and the sort keys of orderBy to q.
+func Apply[T any](q *gotype.Query[T], where map[string]any, orderBy []Order) error {
+	info := q.Info()
+	if len(where) > 0 {
+		f, err := Where(info, where)
+		if err != nil {
+			return err
+		}
+		q.Filter(f)
+	}
+	for _, o := range orderBy {
+		fi, err := field(info, o.Field)
+		if err != nil {
+			return err
+		}
+		switch strings.ToUpper(o.Direction) {
+		case "", "ASC":
+			q.OrderAsc(fi.Tag.Name)
+		case "DESC":
+			q.OrderDesc(fi.Tag.Name)
+		default:
+			return fmt.Errorf("gqladapter: invalid direction %q for %s", o.Direction, o.Field)
+		}
+	}
+	return nil
+}
+
+// Where converts a GraphQL filter input into a Filter on the model
+// described by info.
+func Where(info *gotype.ModelInfo, where map[string]any) (gotype.Filter, error) {
+	var filters []gotype.Filter
+	for _, key := range sortedKeys(where) {
+		val := where[key]
+		switch strings.ToUpper(key) {
+		case "AND", "OR":
+			list, ok := val.([]any)
+			if !ok {
+				list = anySlice(val)
+			}
+			var branches []gotype.Filter
+			for _, item := range list {
+				m, ok := item.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("gqladapter: %s expects a list of filters", key)
+				}
+				f, err := Where(info, m)
+				if err != nil {
+					return nil, err
+				}
+				branches = append(branches, f)
+			}
+			if len(branches) == 0 {
+				continue
+			}
+			if strings.EqualFold(key, "OR") {
+				filters = append(filters, gotype.Or(branches...))
+			} else {
+				filters = append(filters, gotype.And(branches...))
+			}
+		case "NOT":
+			m, ok := val.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("gqladapter: NOT expects a filter")
+			}
+			f, err := Where(info, m)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, gotype.Not(f))
+		default:
+			fi, err := field(info, key)
+			if err != nil {
+				return nil, err
+			}
+			ops, ok := val.(map[string]any)
+			if !ok {
+				ops = map[string]any{"eq": val}
+			}
+			for _, op := range sortedKeys(ops) {
+				f, err := fieldFilter(fi, op, ops[op])
+				if err != nil {
+					return nil, err
+				}
+				filters = append(filters, f)
+			}
+		}
+	}
+	return gotype.And(filters...), nil
+}
+
+func fieldFilter(fi gotype.FieldInfo, op string, val any) (gotype.Filter, error) {
+	attr := fi.Tag.Name
+	switch op {
+	case "isNull":
+		isNull, ok := val.(bool)
+		if !ok {
+			return nil, fmt.Errorf("gqladapter: %s.isNull expects a boolean", attr)
+		}
+		if isNull {
+			return gotype.NotHasAttr(attr), nil
+		}
+		return gotype.HasAttr(attr), nil
+	case "contains", "startsWith", "regex":
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("gqladapter: %s.%s expects a string", attr, op)
+		}
+		switch op {
+		case "contains":
+			return gotype.Contains(attr, s), nil
+		case "startsWith":
+			return gotype.Startswith(attr, s), nil
+		}
+		return gotype.Regex(attr, s), nil
+	case "in", "notIn":
+		var values []any
+		for _, item := range anySlice(val) {
+			v, err := coerce(fi, item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		if op == "in" {
+			return gotype.In(attr, values), nil
+		}
+		return gotype.NotIn(attr, values), nil
+	}
+
+	v, err := coerce(fi, val)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "eq":
+		return gotype.Eq(attr, v), nil
+	case "neq":
+		return gotype.Neq(attr, v), nil
+	case "gt":
+		return gotype.Gt(attr, v), nil
+	case "gte":
+		return gotype.Gte(attr, v), nil
+	case "lt":
+		return gotype.Lt(attr, v), nil
+	case "lte":
+		return gotype.Lte(attr, v), nil
+	}
+	return nil, fmt.Errorf("gqladapter: unknown operator %q on %s", op, attr)
+}
+
+// coerce converts a decoded GraphQL value to the attribute's value type.
+// Strings are parsed for non-string attributes and json.Number is resolved.
+func coerce(fi gotype.FieldInfo, val any) (any, error) {
+	var text string
+	switch v := val.(type) {
+	case string:
+		if fi.ValueType == "string" {
+			return v, nil
+		}
+		text = v
+	case json.Number:
+		text = v.String()
+	case nil:
+		return nil, fmt.Errorf("gqladapter: null value for %s; use isNull", fi.Tag.Name)
+	default:
+		return val, nil
+	}
+	v, err := gotype.ParseAttributeValue(fi.ValueType, text)
+	if err != nil {
+		return nil, fmt.Errorf("gqladapter: %s: %w", fi.Tag.Name, err)
+	}
+	return v, nil
+}
+
+// field resolves a filter or order key to a model field by attribute name,
+// Go field name or lower camel case Go field name.
+func field(info *gotype.ModelInfo, name string) (gotype.FieldInfo, error) {
+	if fi, ok := info.FieldByAttrName(name); ok {
+		return fi, nil
+	}
+	for _, fi := range info.Fields {
+		if strings.EqualFold(fi.FieldName, name) {
+			return fi, nil
+		}
+	}
+	return gotype.FieldInfo{}, fmt.Errorf("gqladapter: %s has no field %q", info.TypeName, name)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// anySlice returns the elements of a list value of any element type.
+func anySlice(val any) []any {
+	if list, ok := val.([]any); ok {
+		return list
+	}
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return []any{val}
+	}
+	var list []any
+	if json.Unmarshal(raw, &list) != nil {
+		return []any{val}
+	}
+	return list
+}
+
+// LinkedFilter matches instances that play Role in a relation of type
+// Relation whose OtherRole is played by the instance with OtherIID. Build it
+// with Linked.
+type LinkedFilter struct {
+	Relation  string
+	Role      string
+	OtherRole string
+	OtherIID  string
+}
+
+// ToPatterns generates the relation traversal patterns.
+func (f *LinkedFilter) ToPatterns(varName string) []string {
+	base := "$" + varName + "__" + strings.ReplaceAll(f.Relation+"_"+f.OtherRole, "-", "_")
+	return []string{
+		fmt.Sprintf("%s isa %s, links (%s: $%s, %s: %s_player);", base, f.Relation, f.Role, varName, f.OtherRole, base),
+		fmt.Sprintf("%s_player iid %s;", base, f.OtherIID),
+	}
+}
+
+// Linked returns a filter for the field resolver of a relation traversal:
+// the instances playing role in a relation whose otherRole is played by the
+// instance with otherIID. For a Company.employees field:
+//
+//	f, err := gqladapter.Linked("employment", "employee", "employer", company.GetIID())
+//	return gqladapter.Paginate(ctx, r.people.Query().Filter(f), args)
+func Linked(relation, role, otherRole, otherIID string) (gotype.Filter, error) {
+	if !gotype.IsIID(otherIID) {
+		return nil, fmt.Errorf("gqladapter: invalid iid %q", otherIID)
+	}
+	return &LinkedFilter{Relation: relation, Role: role, OtherRole: otherRole, OtherIID: otherIID}, nil
+}
+
+// RolePlayerOf resolves a role field of a relation object: the player of
+// role in the relation with relationIID, or nil if the role is not played.
+func RolePlayerOf[P any](ctx context.Context, players *gotype.Manager[P], relation, relationIID, role string) (*P, error) {
+	if !gotype.IsIID(relationIID) {
+		return nil, fmt.Errorf("gqladapter: invalid iid %q", relationIID)
+	}
+	return players.Query().Filter(&playsInFilter{relation: relation, role: role, iid: relationIID}).First(ctx)
+}
+
+// playsInFilter matches the player of role in one relation instance.
+type playsInFilter struct {
+	relation, role, iid string
+}
+
+func (f *playsInFilter) ToPatterns(varName string) []string {
+	rel := "$" + varName + "__in_" + strings.ReplaceAll(f.relation, "-", "_")
+	return []string{
+		fmt.Sprintf("%s isa %s, links (%s: $%s);", rel, f.relation, f.role, varName),
+		fmt.Sprintf("%s iid %s;", rel, f.iid),
+	}
+}
@@ -0,0 +1,268 @@
+package gqladapter_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+	"github.com/CaliLuke/go-typeql/gotype/gqladapter"
+)
+
+type person struct {
+	gotype.BaseEntity
+	Name     string     `typedb:"name,key"`
+	Age      *int       `typedb:"age,card=0..1"`
+	JoinedAt *time.Time `typedb:"joined-at,card=0..1"`
+}
+
+type company struct {
+	gotype.BaseEntity
+	Title string `typedb:"title,key"`
+}
+
+type employment struct {
+	gotype.BaseRelation
+	Employee *person  `typedb:"role:employee"`
+	Employer *company `typedb:"role:employer"`
+	Since    int      `typedb:"since"`
+}
+
+type fixture struct {
+	people      *gotype.Manager[person]
+	employments *gotype.Manager[employment]
+	companies   map[string]*company
+	persons     map[string]*person
+}
+
+// newFixture stores Alice (20), Bob (25), Carol (30), Dave (35) and Eve
+// (no age). Alice and Bob work at Acme, Carol at Globex.
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	reg := gotype.NewRegistry()
+	for _, err := range []error{
+		gotype.RegisterIn[person](reg),
+		gotype.RegisterIn[company](reg),
+		gotype.RegisterIn[employment](reg),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := gotype.NewDatabase(gotypetest.NewFakeDB(), "test").WithRegistry(reg)
+	if err := db.ExecuteSchema(ctx, reg.GenerateSchema()); err != nil {
+		t.Fatalf("ExecuteSchema: %v", err)
+	}
+
+	f := &fixture{
+		people:      gotype.MustNewManager[person](db),
+		employments: gotype.MustNewManager[employment](db),
+		companies:   make(map[string]*company),
+		persons:     make(map[string]*person),
+	}
+	companies := gotype.MustNewManager[company](db)
+	for _, title := range []string{"Acme", "Globex"} {
+		c := &company{Title: title}
+		if err := companies.Insert(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+		f.companies[title] = c
+	}
+	for i, name := range []string{"Alice", "Bob", "Carol", "Dave", "Eve"} {
+		p := &person{Name: name}
+		if name != "Eve" {
+			age := 20 + 5*i
+			joined := time.Date(2020+i, 1, 1, 0, 0, 0, 0, time.UTC)
+			p.Age, p.JoinedAt = &age, &joined
+		}
+		if err := f.people.Insert(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+		f.persons[name] = p
+	}
+	for _, e := range []struct{ person, company string }{{"Alice", "Acme"}, {"Bob", "Acme"}, {"Carol", "Globex"}} {
+		rel := &employment{Employee: f.persons[e.person], Employer: f.companies[e.company], Since: 2020}
+		if err := f.employments.Insert(ctx, rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+func nodeNames(conn *gqladapter.Connection[person]) []string {
+	var out []string
+	for _, p := range conn.Nodes() {
+		out = append(out, p.Name)
+	}
+	return out
+}
+
+func TestWhere(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	byName := []gqladapter.Order{{Field: "name"}}
+
+	tests := []struct {
+		name  string
+		where map[string]any
+		want  []string
+	}{
+		{"bare value", map[string]any{"name": "Bob"}, []string{"Bob"}},
+		{"range", map[string]any{"age": map[string]any{"gte": 25, "lt": 35}}, []string{"Bob", "Carol"}},
+		{"go field name", map[string]any{"Age": map[string]any{"gt": 30}}, []string{"Dave"}},
+		{"camel case datetime string", map[string]any{"joinedAt": map[string]any{"gte": "2022-01-01"}}, []string{"Carol", "Dave"}},
+		{"in", map[string]any{"name": map[string]any{"in": []string{"Alice", "Eve"}}}, []string{"Alice", "Eve"}},
+		{"notIn", map[string]any{"name": map[string]any{"notIn": []any{"Alice", "Eve"}}}, []string{"Bob", "Carol", "Dave"}},
+		{"isNull", map[string]any{"age": map[string]any{"isNull": true}}, []string{"Eve"}},
+		{"startsWith", map[string]any{"name": map[string]any{"startsWith": "Ca"}}, []string{"Carol"}},
+		{"OR", map[string]any{"OR": []any{
+			map[string]any{"name": "Alice"},
+			map[string]any{"age": map[string]any{"gte": 35}},
+		}}, []string{"Alice", "Dave"}},
+		{"NOT", map[string]any{
+			"age": map[string]any{"isNull": false},
+			"NOT": map[string]any{"name": map[string]any{"contains": "o"}},
+		}, []string{"Alice", "Dave"}},
+		{"AND", map[string]any{"AND": []map[string]any{
+			{"age": map[string]any{"gt": 20}},
+			{"age": map[string]any{"lt": 30}},
+		}}, []string{"Bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := gqladapter.Paginate(ctx, f.people.Query(), gqladapter.ListArgs{Where: tt.where, OrderBy: byName})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := nodeNames(conn); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, where := range []map[string]any{
+		{"salary": 1},
+		{"age": map[string]any{"near": 1}},
+		{"age": map[string]any{"gt": "old"}},
+		{"age": nil},
+		{"OR": "x"},
+	} {
+		if _, err := gqladapter.Paginate(ctx, f.people.Query(), gqladapter.ListArgs{Where: where}); err == nil {
+			t.Errorf("where %v: expected error", where)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	two := 2
+	args := gqladapter.ListArgs{OrderBy: []gqladapter.Order{{Field: "name", Direction: "desc"}}, First: &two}
+
+	var pages [][]string
+	for {
+		conn, err := gqladapter.Paginate(ctx, f.people.Query(), args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if conn.TotalCount != 5 {
+			t.Errorf("TotalCount = %d, want 5", conn.TotalCount)
+		}
+		if conn.PageInfo.HasPreviousPage != (len(pages) > 0) {
+			t.Errorf("page %d: HasPreviousPage = %v", len(pages), conn.PageInfo.HasPreviousPage)
+		}
+		pages = append(pages, nodeNames(conn))
+		if !conn.PageInfo.HasNextPage {
+			break
+		}
+		args.After = conn.PageInfo.EndCursor
+	}
+	want := [][]string{{"Eve", "Dave"}, {"Carol", "Bob"}, {"Alice"}}
+	if !slices.EqualFunc(pages, want, slices.Equal) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+
+	for _, args := range []gqladapter.ListArgs{
+		{After: new("bm90IGEgY3Vyc29y")},
+		{OrderBy: []gqladapter.Order{{Field: "name", Direction: "sideways"}}},
+		{OrderBy: []gqladapter.Order{{Field: "salary"}}},
+		{First: new(-1)},
+	} {
+		if _, err := gqladapter.Paginate(ctx, f.people.Query(), args); err == nil {
+			t.Errorf("%+v: expected error", args)
+		}
+	}
+}
+
+func TestOrders(t *testing.T) {
+	type direction string
+	type order struct {
+		Field     string    `json:"field"`
+		Direction direction `json:"direction"`
+	}
+	got := gqladapter.Orders([]*order{{Field: "age", Direction: "DESC"}, nil, {Field: "name"}})
+	want := []gqladapter.Order{{Field: "age", Direction: "DESC"}, {Field: "name"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Orders = %v, want %v", got, want)
+	}
+}
+
+func TestLinked(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	employees, err := gqladapter.Linked("employment", "employee", "employer", f.companies["Acme"].GetIID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := gqladapter.Paginate(ctx, f.people.Query().Filter(employees), gqladapter.ListArgs{
+		Where:   map[string]any{"age": map[string]any{"gte": 20}},
+		OrderBy: []gqladapter.Order{{Field: "age", Direction: "DESC"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nodeNames(conn); !slices.Equal(got, []string{"Bob", "Alice"}) || conn.TotalCount != 2 {
+		t.Errorf("Acme employees = %v (total %d)", got, conn.TotalCount)
+	}
+
+	// Linked composes with NOT: people not employed at Acme.
+	conn, err = gqladapter.Paginate(ctx, f.people.Query().Filter(gotype.Not(employees)), gqladapter.ListArgs{
+		OrderBy: []gqladapter.Order{{Field: "name"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nodeNames(conn); !slices.Equal(got, []string{"Carol", "Dave", "Eve"}) {
+		t.Errorf("not at Acme = %v", got)
+	}
+
+	if _, err := gqladapter.Linked("employment", "employee", "employer", "0x1; match"); err == nil {
+		t.Error("expected error for invalid iid")
+	}
+}
+
+func TestRolePlayerOf(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	rels, err := f.employments.Query().Filter(gotype.RolePlayer("employer", gotype.Eq("title", "Globex"))).Execute(ctx)
+	if err != nil || len(rels) != 1 {
+		t.Fatalf("Globex employments = %v, %v", rels, err)
+	}
+	p, err := gqladapter.RolePlayerOf(ctx, f.people, "employment", rels[0].GetIID(), "employee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || p.Name != "Carol" {
+		t.Errorf("employee = %+v, want Carol", p)
+	}
+
+	if _, err := gqladapter.RolePlayerOf(ctx, f.people, "employment", "bad", "employee"); err == nil || !strings.Contains(err.Error(), "invalid iid") {
+		t.Errorf("err = %v, want invalid iid", err)
+	}
+}
@@ -0,0 +1,208 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// graphNode is one instance reached by InsertGraph.
+type graphNode struct {
+	v    reflect.Value // addressable struct value
+	info *ModelInfo
+	// deps are the role players of a relation.
+	deps []*graphNode
+}
+
+// graphWalker collects the instances reachable from a root.
+type graphWalker struct {
+	reg   *Registry
+	nodes map[graphKey]*graphNode
+	order []*graphNode // discovery order
+}
+
+type graphKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// InsertGraph inserts root and every registered entity and relation reachable
+// from it in one transaction: the role players of relations, typed
+// role-player fields (tagged "rel:"), and untagged fields holding a model, a
+// pointer to one or a slice of either. Instances that already have an IID are
+// referenced but not inserted again.
+//
+// Entities are inserted first, then relations once their role players are
+// stored, then the relations of typed role-player fields. A relation that is,
+// directly or through other relations, its own role player is an error. The
+// IIDs of the inserted instances are set on success.
+func (m *Manager[T]) InsertGraph(ctx context.Context, root *T) error {
+	if root == nil {
+		return fmt.Errorf("insert_graph %s: root must not be nil", m.info.TypeName)
+	}
+	if err := checkCtx(ctx, "insert_graph", m.info.TypeName); err != nil {
+		return err
+	}
+	w := &graphWalker{reg: registryOf(m.info), nodes: make(map[graphKey]*graphNode)}
+	w.visit(reflect.ValueOf(root).Elem(), m.info)
+	order, err := w.insertOrder()
+	if err != nil {
+		return fmt.Errorf("insert_graph %s: %w", m.info.TypeName, err)
+	}
+	defer m.invalidateCache(ctx)
+
+	// IIDs are assigned as instances are inserted; on failure they are
+	// cleared again so that no instance refers to a rolled-back IID.
+	var inserted []*graphNode
+	err = m.withWriteTx(ctx, "insert_graph", m.writeTx, func(tx Tx) error {
+		for _, n := range order {
+			if err := insertGraphNode(ctx, tx, n); err != nil {
+				return fmt.Errorf("insert_graph %s: %w", m.info.TypeName, err)
+			}
+			inserted = append(inserted, n)
+		}
+		for _, n := range w.order {
+			if len(n.info.Links) == 0 {
+				continue
+			}
+			if err := insertLinks(ctx, tx, n.info, n.v, getIIDFromValueInfo(n.v, n.info)); err != nil {
+				return fmt.Errorf("insert_graph %s: %s: %w", m.info.TypeName, n.info.TypeName, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for _, n := range inserted {
+			setIIDWithInfo(n.v, n.info, "")
+		}
+	}
+	return err
+}
+
+// visit records v, an instance of info, and the instances it references.
+func (w *graphWalker) visit(v reflect.Value, info *ModelInfo) *graphNode {
+	key := graphKey{addr: v.Addr().Pointer(), typ: v.Type()}
+	if n, ok := w.nodes[key]; ok {
+		return n
+	}
+	n := &graphNode{v: v, info: info}
+	w.nodes[key] = n
+	w.order = append(w.order, n)
+
+	for _, role := range info.Roles {
+		n.deps = append(n.deps, w.visitField(role.fieldValue(v))...)
+	}
+	for i := range info.Links {
+		w.visitField(info.Links[i].fieldValue(v))
+	}
+	for field := range info.GoType.Fields() {
+		if _, tagged := field.Tag.Lookup("typedb"); field.Anonymous || !field.IsExported() || tagged {
+			continue
+		}
+		w.visitField(v.FieldByIndex(field.Index))
+	}
+	return n
+}
+
+// visitField visits the models held by field: a model struct, a pointer to
+// one, or a slice of either. Other values are ignored.
+func (w *graphWalker) visitField(field reflect.Value) []*graphNode {
+	switch field.Kind() {
+	case reflect.Pointer:
+		if field.IsNil() {
+			return nil
+		}
+		return w.visitField(field.Elem())
+	case reflect.Slice:
+		var nodes []*graphNode
+		for i := range field.Len() {
+			nodes = append(nodes, w.visitField(field.Index(i))...)
+		}
+		return nodes
+	case reflect.Struct:
+		if !field.CanAddr() {
+			return nil
+		}
+		if info, ok := w.reg.LookupType(field.Type()); ok {
+			return []*graphNode{w.visit(field, info)}
+		}
+	}
+	return nil
+}
+
+// insertOrder returns the instances to insert: entities in discovery order,
+// then relations after the relations they depend on.
+func (w *graphWalker) insertOrder() ([]*graphNode, error) {
+	var order []*graphNode
+	for _, n := range w.order {
+		if n.info.Kind == ModelKindEntity && !n.stored() {
+			order = append(order, n)
+		}
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[*graphNode]int)
+	var path []string
+	var sort func(n *graphNode) error
+	sort = func(n *graphNode) error {
+		switch state[n] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("relation cycle: %s", strings.Join(append(path, n.info.TypeName), " -> "))
+		}
+		state[n] = visiting
+		path = append(path, n.info.TypeName)
+		for _, dep := range n.deps {
+			if dep.info.Kind == ModelKindRelation && !dep.stored() {
+				if err := sort(dep); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = done
+		order = append(order, n)
+		return nil
+	}
+	for _, n := range w.order {
+		if n.info.Kind == ModelKindRelation && !n.stored() {
+			if err := sort(n); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return order, nil
+}
+
+func (n *graphNode) stored() bool {
+	return getIIDFromValueInfo(n.v, n.info) != ""
+}
+
+// insertGraphNode inserts n in tx and sets its IID.
+func insertGraphNode(ctx context.Context, tx Tx, n *graphNode) error {
+	instance := n.v.Addr().Interface()
+	applyDefaults(n.info, instance)
+	stampTimestamps(n.info, instance, stampInsert)
+	query, err := strategyFor(n.info.Kind).BuildInsertQuery(n.info, instance, "e")
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", n.info.TypeName, err)
+	}
+	results, err := tx.QueryWithContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%s: %w", n.info.TypeName, err)
+	}
+	var iid string
+	if len(results) == 1 {
+		iid = extractIID(results[0])
+	}
+	if iid == "" {
+		return fmt.Errorf("%s: insert returned no IID", n.info.TypeName)
+	}
+	setIIDWithInfo(n.v, n.info, iid)
+	return nil
+}
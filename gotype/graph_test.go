@@ -0,0 +1,76 @@
+package gotype
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type testGraphRoot struct {
+	BaseEntity
+	Name    string        `typedb:"name,key"`
+	Friends []*testPerson // untagged: traversed by InsertGraph
+	Jobs    []testEmployment
+}
+
+type testNested struct {
+	BaseRelation
+	Inner *testNested     `typedb:"role:inner"`
+	Job   *testEmployment `typedb:"role:job"`
+}
+
+func TestInsertGraph(t *testing.T) {
+	reg := NewRegistry()
+	MustRegisterIn[testPerson](reg)
+	MustRegisterIn[testCompany](reg)
+	MustRegisterIn[testEmployment](reg)
+	MustRegisterIn[testGraphRoot](reg)
+	ctx := context.Background()
+
+	alice := &testPerson{Name: "Alice"}
+	acme := &testCompany{Name: "Acme"}
+	acme.SetIID("0x9") // already stored: referenced, not inserted
+	root := &testGraphRoot{
+		Name:    "root",
+		Friends: []*testPerson{alice, alice},
+		Jobs:    []testEmployment{{Employee: alice, Employer: acme}},
+	}
+	tx := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1"}}, {{"_iid": "0x2"}}, {{"_iid": "0x3"}}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db").WithRegistry(reg)
+	if err := MustNewManager[testGraphRoot](db).InsertGraph(ctx, root); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.queries) != 3 || !tx.committed {
+		t.Fatalf("queries = %q, committed = %v", tx.queries, tx.committed)
+	}
+	assertContains(t, tx.queries[0], "test-graph-root")
+	assertContains(t, tx.queries[1], `$e has name "Alice";`)
+	assertContains(t, tx.queries[2], "$employee isa test-person, iid 0x2;\n$employer isa test-company, iid 0x9;")
+	if root.GetIID() != "0x1" || alice.GetIID() != "0x2" || root.Jobs[0].GetIID() != "0x3" {
+		t.Errorf("iids = %s %s %s", root.GetIID(), alice.GetIID(), root.Jobs[0].GetIID())
+	}
+
+	// A failed insert clears the IIDs assigned so far.
+	bob := &testPerson{Name: "Bob"}
+	tx = &mockTx{responses: [][]map[string]any{{{"_iid": "0x4"}}, {}}}
+	db = NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db").WithRegistry(reg)
+	err := MustNewManager[testEmployment](db).InsertGraph(ctx, &testEmployment{Employee: bob, Employer: &testCompany{Name: "Nope"}})
+	if err == nil || tx.committed || bob.GetIID() != "" {
+		t.Errorf("err = %v, committed = %v, iid = %q", err, tx.committed, bob.GetIID())
+	}
+}
+
+func TestInsertGraph_Cycle(t *testing.T) {
+	reg := NewRegistry()
+	MustRegisterIn[testPerson](reg)
+	MustRegisterIn[testCompany](reg)
+	MustRegisterIn[testEmployment](reg)
+	MustRegisterIn[testNested](reg)
+	a := &testNested{Job: &testEmployment{Employee: &testPerson{Name: "Alice"}}}
+	a.Inner = &testNested{Inner: a}
+	db := NewDatabase(&mockConn{}, "test_db").WithRegistry(reg)
+	err := MustNewManager[testNested](db).InsertGraph(context.Background(), a)
+	if err == nil || !strings.Contains(err.Error(), "relation cycle: test-nested -> test-nested -> test-nested") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package gotype
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// healthReadQuery is the default probe: a read that touches only the schema.
+const healthReadQuery = "match\nentity $t;\nlimit 1;"
+
+// HealthOption configures CheckHealth and HealthHandler.
+type HealthOption func(*healthConfig)
+
+type healthConfig struct {
+	timeout    time.Duration
+	query      string
+	schemaHash string
+}
+
+// WithHealthTimeout bounds the duration of a health check (default 2s).
+func WithHealthTimeout(d time.Duration) HealthOption {
+	return func(c *healthConfig) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithHealthQuery replaces the read query run by the health check.
+func WithHealthQuery(query string) HealthOption {
+	return func(c *healthConfig) { c.query = query }
+}
+
+// WithExpectedSchemaHash makes the health check fail unless the database
+// records hash as its last schema applied by EnsureSchema, so instances
+// report unready until their schema migration has run.
+func WithExpectedSchemaHash(hash string) HealthOption {
+	return func(c *healthConfig) { c.schemaHash = hash }
+}
+
+// HealthReport is the result of CheckHealth, served as JSON by HealthHandler.
+type HealthReport struct {
+	// Status is "ok" or "unavailable".
+	Status   string `json:"status"`
+	Database string `json:"database"`
+	// Latency is the duration of the read query, in milliseconds.
+	Latency float64 `json:"latency_ms"`
+	// SchemaHash is the hash recorded by EnsureSchema, if any.
+	SchemaHash string `json:"schema_hash,omitempty"`
+	// Error describes the failed check.
+	Error string `json:"error,omitempty"`
+}
+
+// OK reports whether every check passed.
+func (r HealthReport) OK() bool {
+	return r.Status == "ok"
+}
+
+// CheckHealth runs a cheap read query against db and looks up the schema hash
+// recorded by EnsureSchema. The schema hash only fails the check when
+// WithExpectedSchemaHash is given and it does not match.
+func CheckHealth(ctx context.Context, db *Database, opts ...HealthOption) HealthReport {
+	cfg := healthConfig{timeout: 2 * time.Second, query: healthReadQuery}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout)
+	defer cancel()
+
+	report := HealthReport{Status: "ok", Database: db.Name()}
+	start := time.Now()
+	_, err := db.ExecuteRead(ctx, cfg.query)
+	report.Latency = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		report.Status = "unavailable"
+		report.Error = fmt.Sprintf("read: %v", err)
+		return report
+	}
+
+	stored, err := storedSchemaHash(ctx, db)
+	if err == nil {
+		report.SchemaHash = stored
+	}
+	if cfg.schemaHash == "" {
+		return report
+	}
+	switch {
+	case err != nil:
+		report.Status = "unavailable"
+		report.Error = fmt.Sprintf("schema: %v", err)
+	case stored != cfg.schemaHash:
+		report.Status = "unavailable"
+		report.Error = fmt.Sprintf("schema: hash %q does not match expected %q", stored, cfg.schemaHash)
+	}
+	return report
+}
+
+// HealthHandler returns an http.Handler for readiness probes. It runs
+// CheckHealth on every request and writes the HealthReport as JSON, with
+// status 200 when the database is healthy and 503 otherwise.
+func HealthHandler(db *Database, opts ...HealthOption) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := CheckHealth(r.Context(), db, opts...)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.OK() {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,79 @@
+package gotype
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveHealth(t *testing.T, h http.Handler) (int, HealthReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var report HealthReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, report
+}
+
+func TestHealthHandler_OK(t *testing.T) {
+	conn := &mockConn{txs: []*mockTx{
+		{},
+		{responses: [][]map[string]any{{{"hash": "abc"}}}},
+	}}
+	db := NewDatabase(conn, "test_db")
+
+	code, report := serveHealth(t, HealthHandler(db))
+	if code != http.StatusOK || !report.OK() {
+		t.Fatalf("code = %d, report = %+v", code, report)
+	}
+	if report.Database != "test_db" || report.SchemaHash != "abc" {
+		t.Errorf("report = %+v", report)
+	}
+	assertContains(t, conn.txs[0].queries[0], "entity $t;")
+}
+
+func TestHealthHandler_ReadFailure(t *testing.T) {
+	db := NewDatabase(&failingQueryConn{}, "test_db")
+
+	code, report := serveHealth(t, HealthHandler(db))
+	if code != http.StatusServiceUnavailable || report.OK() {
+		t.Fatalf("code = %d, report = %+v", code, report)
+	}
+	if report.Error != "read: boom" {
+		t.Errorf("Error = %q", report.Error)
+	}
+}
+
+func TestHealthHandler_SchemaHashMismatch(t *testing.T) {
+	conn := &mockConn{txs: []*mockTx{
+		{},
+		{responses: [][]map[string]any{{{"hash": "old"}}}},
+	}}
+	db := NewDatabase(conn, "test_db")
+
+	code, report := serveHealth(t, HealthHandler(db, WithExpectedSchemaHash("new")))
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("code = %d, report = %+v", code, report)
+	}
+	assertContains(t, report.Error, `hash "old" does not match expected "new"`)
+}
+
+func TestCheckHealth_CustomQuery(t *testing.T) {
+	conn := &mockConn{txs: []*mockTx{{}, {}}}
+	db := NewDatabase(conn, "test_db")
+
+	report := CheckHealth(context.Background(), db, WithHealthQuery("match $p isa person; limit 1;"))
+	if !report.OK() || report.SchemaHash != "" {
+		t.Errorf("report = %+v", report)
+	}
+	if got := conn.txs[0].queries[0]; got != "match $p isa person; limit 1;" {
+		t.Errorf("health query = %q", got)
+	}
+}
@@ -0,0 +1,473 @@
+// Package httpadapter serves gotype models over HTTP as JSON resources.
+//
+// NewResourceHandler turns a Manager into a CRUD handler:
+//
+//	GET    /       list, filtered, sorted and paginated by query parameters
+//	POST   /       create
+//	GET    /{iid}  get
+//	PATCH  /{iid}  update the attributes present in the body
+//	DELETE /{iid}  delete
+//
+// Mount it under a prefix with http.StripPrefix:
+//
+//	mux.Handle("/people/", http.StripPrefix("/people", httpadapter.NewResourceHandler(people)))
+//
+// Instances are read and written as objects keyed by attribute name, as by
+// Manager.ToDict, with the IID under "_iid". Relations are created with each
+// role player given as an object holding its "_iid", keyed by role name; reads
+// return the relation's own attributes, as Manager.GetByIID does. WithOut,
+// WithCreate and WithPatch switch any of these to DTOs such as the ones
+// tqlgen generates.
+//
+// List requests accept:
+//
+//	attr=v         attribute equals v
+//	attr__op=v     op is one of neq, gt, gte, lt, lte, contains, startswith,
+//	               in (comma separated values) or exists (true or false)
+//	sort=a,-b      order by a ascending, then b descending
+//	limit, offset  pagination; limit defaults to and is capped by WithMaxLimit
+//
+// Values are parsed according to the attribute's value type. The response is
+// {"items": [...], "total": n, "limit": l, "offset": o}.
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// DefaultMaxLimit is the page size used when WithMaxLimit is not given.
+const DefaultMaxLimit = 100
+
+// maxBodyBytes bounds the request bodies read by create and patch.
+const maxBodyBytes = 1 << 20
+
+// Option configures a ResourceHandler.
+type Option[T any] func(*ResourceHandler[T])
+
+// WithMaxLimit sets the page size used when a list request has no limit, and
+// the largest limit accepted.
+func WithMaxLimit[T any](n int) Option[T] {
+	return func(h *ResourceHandler[T]) { h.maxLimit = n }
+}
+
+// WithOut renders instances through the DTO returned by out.
+func WithOut[T, O any](out func(*T) O) Option[T] {
+	return func(h *ResourceHandler[T]) {
+		h.encode = func(instance *T) (any, error) { return out(instance), nil }
+	}
+}
+
+// WithCreate reads create bodies as a C and builds the instance with create.
+func WithCreate[T, C any](create func(C) (*T, error)) Option[T] {
+	return func(h *ResourceHandler[T]) {
+		h.decodeCreate = func(body []byte) (*T, error) {
+			var dto C
+			if err := json.Unmarshal(body, &dto); err != nil {
+				return nil, err
+			}
+			return create(dto)
+		}
+	}
+}
+
+// WithPatch reads patch bodies as a P and applies them to the stored instance
+// with apply.
+func WithPatch[T, P any](apply func(P, *T) error) Option[T] {
+	return func(h *ResourceHandler[T]) {
+		h.applyPatch = func(body []byte, instance *T) (*T, error) {
+			var dto P
+			if err := json.Unmarshal(body, &dto); err != nil {
+				return nil, err
+			}
+			if err := apply(dto, instance); err != nil {
+				return nil, err
+			}
+			return instance, nil
+		}
+	}
+}
+
+// ResourceHandler is an http.Handler serving CRUD operations on one model
+// type. Create it with NewResourceHandler.
+type ResourceHandler[T any] struct {
+	mgr      *gotype.Manager[T]
+	info     *gotype.ModelInfo
+	maxLimit int
+	mux      *http.ServeMux
+
+	encode       func(*T) (any, error)
+	decodeCreate func([]byte) (*T, error)
+	applyPatch   func([]byte, *T) (*T, error)
+}
+
+// NewResourceHandler returns a handler serving mgr's model type.
+func NewResourceHandler[T any](mgr *gotype.Manager[T], opts ...Option[T]) *ResourceHandler[T] {
+	h := &ResourceHandler[T]{mgr: mgr, info: mgr.Info(), maxLimit: DefaultMaxLimit}
+	h.encode = h.toDict
+	h.decodeCreate = h.fromDict
+	h.applyPatch = h.mergeDict
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("GET /{$}", h.list)
+	h.mux.HandleFunc("POST /{$}", h.create)
+	h.mux.HandleFunc("GET /{iid}", h.get)
+	h.mux.HandleFunc("PATCH /{iid}", h.patch)
+	h.mux.HandleFunc("DELETE /{iid}", h.delete)
+	return h
+}
+
+// ServeHTTP dispatches the request to the CRUD operation for its method and
+// path.
+func (h *ResourceHandler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type listResponse struct {
+	Items  []any `json:"items"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+func (h *ResourceHandler[T]) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := r.URL.Query()
+	filters, err := h.parseFilters(params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit, offset, err := h.parsePage(params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	q := h.mgr.Query().Filter(filters...)
+	total, err := q.Count(ctx)
+	if err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	if err := h.applySort(q, params.Get("sort")); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	instances, err := q.Limit(limit).Offset(offset).Execute(ctx)
+	if err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+
+	resp := listResponse{Items: make([]any, 0, len(instances)), Total: total, Limit: limit, Offset: offset}
+	for _, instance := range instances {
+		item, err := h.encode(instance)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *ResourceHandler[T]) get(w http.ResponseWriter, r *http.Request) {
+	instance, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	h.writeInstance(w, http.StatusOK, instance)
+}
+
+func (h *ResourceHandler[T]) create(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	instance, err := h.decodeCreate(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.mgr.Insert(r.Context(), instance); err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	h.writeInstance(w, http.StatusCreated, instance)
+}
+
+func (h *ResourceHandler[T]) patch(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	instance, err := h.applyPatch(body, existing)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.mgr.Update(r.Context(), instance); err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	h.writeInstance(w, http.StatusOK, instance)
+}
+
+func (h *ResourceHandler[T]) delete(w http.ResponseWriter, r *http.Request) {
+	instance, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	if err := h.mgr.Delete(r.Context(), instance); err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookup loads the instance named by the request path, writing a 400 or 404
+// response when there is none.
+func (h *ResourceHandler[T]) lookup(w http.ResponseWriter, r *http.Request) (*T, bool) {
+	iid := r.PathValue("iid")
+	if !gotype.IsIID(iid) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid iid %q", iid))
+		return nil, false
+	}
+	instance, err := h.mgr.GetByIID(r.Context(), iid)
+	if err != nil {
+		h.writeStoreError(w, err)
+		return nil, false
+	}
+	if instance == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s %s not found", h.info.TypeName, iid))
+		return nil, false
+	}
+	return instance, true
+}
+
+// toDict is the default encoding: ToDict plus the IIDs of any role players
+// set on the instance.
+func (h *ResourceHandler[T]) toDict(instance *T) (any, error) {
+	data, err := h.mgr.ToDict(instance)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(instance).Elem()
+	for _, role := range h.info.Roles {
+		field := v.FieldByName(role.FieldName)
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		if player, ok := field.Addr().Interface().(interface{ GetIID() string }); ok && player.GetIID() != "" {
+			data[role.RoleName] = map[string]any{"_iid": player.GetIID()}
+		}
+	}
+	return data, nil
+}
+
+// fromDict is the default create decoding. Every required attribute must be
+// present and no unknown key is accepted.
+func (h *ResourceHandler[T]) fromDict(body []byte) (*T, error) {
+	var data map[string]any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	delete(data, "_iid")
+	return h.mgr.FromDict(data, gotype.WithStrictHydration())
+}
+
+// mergeDict is the default patch: attributes in the body replace the stored
+// ones and null clears an optional attribute.
+func (h *ResourceHandler[T]) mergeDict(body []byte, existing *T) (*T, error) {
+	var patch map[string]any
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return nil, err
+	}
+	data, err := h.toDict(existing)
+	if err != nil {
+		return nil, err
+	}
+	merged := data.(map[string]any)
+	for key, val := range patch {
+		if key == "_iid" {
+			continue
+		}
+		if val == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = val
+	}
+	return h.mgr.FromDict(merged, gotype.WithStrictHydration())
+}
+
+func (h *ResourceHandler[T]) parseFilters(params url.Values) ([]gotype.Filter, error) {
+	var filters []gotype.Filter
+	for key, values := range params {
+		switch key {
+		case "sort", "limit", "offset":
+			continue
+		}
+		attr, op, _ := strings.Cut(key, "__")
+		fi, ok := h.info.FieldByAttrName(attr)
+		if !ok {
+			return nil, fmt.Errorf("unknown attribute %q", attr)
+		}
+		for _, text := range values {
+			f, err := buildFilter(fi, op, text)
+			if err != nil {
+				return nil, fmt.Errorf("filter %s: %w", key, err)
+			}
+			filters = append(filters, f)
+		}
+	}
+	return filters, nil
+}
+
+func buildFilter(fi gotype.FieldInfo, op, text string) (gotype.Filter, error) {
+	attr := fi.Tag.Name
+	switch op {
+	case "exists":
+		exists, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", text)
+		}
+		if exists {
+			return gotype.HasAttr(attr), nil
+		}
+		return gotype.NotHasAttr(attr), nil
+	case "contains":
+		return gotype.Contains(attr, text), nil
+	case "startswith":
+		return gotype.Startswith(attr, text), nil
+	case "in":
+		var values []any
+		for part := range strings.SplitSeq(text, ",") {
+			v, err := gotype.ParseAttributeValue(fi.ValueType, part)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return gotype.In(attr, values), nil
+	}
+
+	v, err := gotype.ParseAttributeValue(fi.ValueType, text)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "", "eq":
+		return gotype.Eq(attr, v), nil
+	case "neq":
+		return gotype.Neq(attr, v), nil
+	case "gt":
+		return gotype.Gt(attr, v), nil
+	case "gte":
+		return gotype.Gte(attr, v), nil
+	case "lt":
+		return gotype.Lt(attr, v), nil
+	case "lte":
+		return gotype.Lte(attr, v), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+func (h *ResourceHandler[T]) parsePage(params url.Values) (limit, offset int, err error) {
+	limit = h.maxLimit
+	if text := params.Get("limit"); text != "" {
+		limit, err = strconv.Atoi(text)
+		if err != nil || limit < 1 || limit > h.maxLimit {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", h.maxLimit)
+		}
+	}
+	if text := params.Get("offset"); text != "" {
+		offset, err = strconv.Atoi(text)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+	return limit, offset, nil
+}
+
+func (h *ResourceHandler[T]) applySort(q *gotype.Query[T], spec string) error {
+	if spec == "" {
+		return nil
+	}
+	for attr := range strings.SplitSeq(spec, ",") {
+		desc := strings.HasPrefix(attr, "-")
+		attr = strings.TrimPrefix(attr, "-")
+		if _, ok := h.info.FieldByAttrName(attr); !ok {
+			return fmt.Errorf("cannot sort by unknown attribute %q", attr)
+		}
+		if desc {
+			q.OrderDesc(attr)
+		} else {
+			q.OrderAsc(attr)
+		}
+	}
+	return nil
+}
+
+func (h *ResourceHandler[T]) writeInstance(w http.ResponseWriter, status int, instance *T) {
+	body, err := h.encode(instance)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, status, body)
+}
+
+// writeStoreError reports a Manager error: invalid instances are the
+// client's fault, anything else is the server's.
+func (h *ResourceHandler[T]) writeStoreError(w http.ResponseWriter, err error) {
+	var keyErr *gotype.KeyAttributeError
+	if gotype.FieldErrorsOf(err) != nil || errors.As(err, &keyErr) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+type errorResponse struct {
+	Error  string             `json:"error"`
+	Fields gotype.FieldErrors `json:"fields,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error(), Fields: gotype.FieldErrorsOf(err)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	var body json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+		return nil, false
+	}
+	return body, true
+}
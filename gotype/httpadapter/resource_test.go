@@ -0,0 +1,297 @@
+package httpadapter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+	"github.com/CaliLuke/go-typeql/gotype/httpadapter"
+)
+
+type person struct {
+	gotype.BaseEntity
+	Name     string  `typedb:"name,key"`
+	Age      *int    `typedb:"age,card=0..1"`
+	Nickname *string `typedb:"nickname,card=0..1"`
+}
+
+type company struct {
+	gotype.BaseEntity
+	Title string `typedb:"title,key"`
+}
+
+type employment struct {
+	gotype.BaseRelation
+	Employee *person  `typedb:"role:employee"`
+	Employer *company `typedb:"role:employer"`
+	Since    int      `typedb:"since"`
+}
+
+func newTestDB(t *testing.T) *gotype.Database {
+	t.Helper()
+	reg := gotype.NewRegistry()
+	for _, err := range []error{
+		gotype.RegisterIn[person](reg),
+		gotype.RegisterIn[company](reg),
+		gotype.RegisterIn[employment](reg),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := gotype.NewDatabase(gotypetest.NewFakeDB(), "test").WithRegistry(reg)
+	if err := db.ExecuteSchema(context.Background(), reg.GenerateSchema()); err != nil {
+		t.Fatalf("ExecuteSchema: %v", err)
+	}
+	return db
+}
+
+// serve mounts h under /res like an application would and returns a
+// function issuing requests against it.
+func serve(t *testing.T, h http.Handler) func(method, path string, body any) (int, map[string]any) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle("/res/", http.StripPrefix("/res", h))
+	return func(method, path string, body any) (int, map[string]any) {
+		t.Helper()
+		var reader *bytes.Reader
+		if s, ok := body.(string); ok {
+			reader = bytes.NewReader([]byte(s))
+		} else {
+			raw, err := json.Marshal(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader = bytes.NewReader(raw)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, "/res"+path, reader))
+		var out map[string]any
+		if rec.Header().Get("Content-Type") == "application/json" {
+			if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+				t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+			}
+		}
+		return rec.Code, out
+	}
+}
+
+func names(t *testing.T, resp map[string]any) []string {
+	t.Helper()
+	items, ok := resp["items"].([]any)
+	if !ok {
+		t.Fatalf("no items in %v", resp)
+	}
+	var out []string
+	for _, item := range items {
+		out = append(out, item.(map[string]any)["name"].(string))
+	}
+	return out
+}
+
+func TestResourceHandler_CRUD(t *testing.T) {
+	db := newTestDB(t)
+	do := serve(t, httpadapter.NewResourceHandler(gotype.MustNewManager[person](db)))
+
+	code, created := do("POST", "/", map[string]any{"name": "Alice", "age": 30, "nickname": "Al"})
+	if code != http.StatusCreated {
+		t.Fatalf("create = %d %v", code, created)
+	}
+	iid, _ := created["_iid"].(string)
+	if iid == "" || created["name"] != "Alice" || created["age"] != float64(30) {
+		t.Fatalf("created = %v", created)
+	}
+
+	code, got := do("GET", "/"+iid, nil)
+	if code != http.StatusOK || got["nickname"] != "Al" {
+		t.Fatalf("get = %d %v", code, got)
+	}
+
+	code, patched := do("PATCH", "/"+iid, map[string]any{"age": 31, "nickname": nil})
+	if code != http.StatusOK {
+		t.Fatalf("patch = %d %v", code, patched)
+	}
+	code, got = do("GET", "/"+iid, nil)
+	if code != http.StatusOK || got["age"] != float64(31) || got["name"] != "Alice" {
+		t.Errorf("after patch = %d %v", code, got)
+	}
+	if _, ok := got["nickname"]; ok {
+		t.Errorf("nickname not cleared: %v", got)
+	}
+
+	if code, _ := do("DELETE", "/"+iid, nil); code != http.StatusNoContent {
+		t.Errorf("delete = %d", code)
+	}
+	if code, _ := do("GET", "/"+iid, nil); code != http.StatusNotFound {
+		t.Errorf("get after delete = %d, want 404", code)
+	}
+	if code, _ := do("PATCH", "/"+iid, map[string]any{"age": 1}); code != http.StatusNotFound {
+		t.Errorf("patch after delete = %d, want 404", code)
+	}
+}
+
+func TestResourceHandler_List(t *testing.T) {
+	db := newTestDB(t)
+	mgr := gotype.MustNewManager[person](db)
+	for i, name := range []string{"Alice", "Bob", "Carol", "Dave", "Eve"} {
+		age := 20 + 5*i
+		if err := mgr.Insert(context.Background(), &person{Name: name, Age: &age}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	do := serve(t, httpadapter.NewResourceHandler(mgr, httpadapter.WithMaxLimit[person](3)))
+
+	tests := []struct {
+		query string
+		want  []string
+		total float64
+	}{
+		{"?sort=name", []string{"Alice", "Bob", "Carol"}, 5},
+		{"?sort=name&offset=3", []string{"Dave", "Eve"}, 5},
+		{"?age__gte=30&sort=-age", []string{"Eve", "Dave", "Carol"}, 3},
+		{"?age__gt=20&age__lt=35&sort=age", []string{"Bob", "Carol"}, 2},
+		{"?name__in=Bob,Eve&sort=name", []string{"Bob", "Eve"}, 2},
+		{"?name=Carol", []string{"Carol"}, 1},
+		{"?name__startswith=D", []string{"Dave"}, 1},
+		{"?nickname__exists=true", nil, 0},
+		{"?age__neq=20&sort=age&limit=1", []string{"Bob"}, 4},
+	}
+	for _, tt := range tests {
+		code, resp := do("GET", "/"+tt.query, nil)
+		if code != http.StatusOK {
+			t.Errorf("%s = %d %v", tt.query, code, resp)
+			continue
+		}
+		if got := names(t, resp); !slices.Equal(got, tt.want) || resp["total"] != tt.total {
+			t.Errorf("%s = %v (total %v), want %v (total %v)", tt.query, got, resp["total"], tt.want, tt.total)
+		}
+	}
+
+	for _, query := range []string{"?salary=1", "?age=old", "?age__near=3", "?sort=salary", "?limit=4", "?limit=0", "?offset=-1"} {
+		if code, resp := do("GET", "/"+query, nil); code != http.StatusBadRequest || resp["error"] == "" {
+			t.Errorf("%s = %d %v, want 400", query, code, resp)
+		}
+	}
+}
+
+func TestResourceHandler_InvalidRequests(t *testing.T) {
+	db := newTestDB(t)
+	do := serve(t, httpadapter.NewResourceHandler(gotype.MustNewManager[person](db)))
+
+	code, resp := do("POST", "/", map[string]any{"name": "Alice", "salary": 10})
+	if code != http.StatusBadRequest {
+		t.Fatalf("unknown attribute = %d %v", code, resp)
+	}
+	fields, _ := resp["fields"].([]any)
+	if len(fields) != 1 || fields[0].(map[string]any)["attr"] != "salary" {
+		t.Errorf("fields = %v, want salary", resp["fields"])
+	}
+	if code, resp := do("POST", "/", map[string]any{"age": 3}); code != http.StatusBadRequest {
+		t.Errorf("missing key = %d %v", code, resp)
+	}
+	if code, resp := do("POST", "/", "{not json"); code != http.StatusBadRequest {
+		t.Errorf("bad JSON = %d %v", code, resp)
+	}
+	if code, resp := do("GET", "/0x1;match", nil); code != http.StatusBadRequest || !strings.Contains(resp["error"].(string), "invalid iid") {
+		t.Errorf("bad iid = %d %v", code, resp)
+	}
+	if code, _ := do("PUT", "/", map[string]any{}); code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT = %d, want 405", code)
+	}
+}
+
+func TestResourceHandler_Relation(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	alice := &person{Name: "Alice"}
+	acme := &company{Title: "Acme"}
+	if err := gotype.MustNewManager[person](db).Insert(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := gotype.MustNewManager[company](db).Insert(ctx, acme); err != nil {
+		t.Fatal(err)
+	}
+	do := serve(t, httpadapter.NewResourceHandler(gotype.MustNewManager[employment](db)))
+
+	code, created := do("POST", "/", map[string]any{
+		"since":    2020,
+		"employee": map[string]any{"_iid": alice.GetIID()},
+		"employer": map[string]any{"_iid": acme.GetIID()},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("create = %d %v", code, created)
+	}
+
+	employee, _ := created["employee"].(map[string]any)
+	employer, _ := created["employer"].(map[string]any)
+	if employee["_iid"] != alice.GetIID() || employer["_iid"] != acme.GetIID() {
+		t.Errorf("created = %v", created)
+	}
+
+	iid := created["_iid"].(string)
+	if code, resp := do("PATCH", "/"+iid, map[string]any{"since": 2021}); code != http.StatusOK {
+		t.Fatalf("patch = %d %v", code, resp)
+	}
+	stored, err := gotype.MustNewManager[employment](db).GetWithRoles(ctx, map[string]any{"since": 2021})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].Employee.Name != "Alice" || stored[0].Employer.Title != "Acme" {
+		t.Errorf("stored = %+v", stored)
+	}
+}
+
+type personOut struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type personCreate struct {
+	Name string `json:"name"`
+}
+
+type personPatch struct {
+	Nickname *string `json:"nickname"`
+}
+
+func TestResourceHandler_DTOs(t *testing.T) {
+	db := newTestDB(t)
+	do := serve(t, httpadapter.NewResourceHandler(gotype.MustNewManager[person](db),
+		httpadapter.WithOut(func(p *person) personOut {
+			return personOut{ID: p.GetIID(), Name: p.Name}
+		}),
+		httpadapter.WithCreate(func(in personCreate) (*person, error) {
+			return &person{Name: strings.TrimSpace(in.Name)}, nil
+		}),
+		httpadapter.WithPatch(func(in personPatch, p *person) error {
+			if in.Nickname != nil {
+				p.Nickname = in.Nickname
+			}
+			return nil
+		}),
+	))
+
+	code, created := do("POST", "/", map[string]any{"name": "  Alice "})
+	if code != http.StatusCreated || created["name"] != "Alice" || created["id"] == "" {
+		t.Fatalf("create = %d %v", code, created)
+	}
+	id := created["id"].(string)
+	if code, resp := do("PATCH", "/"+id, map[string]any{"nickname": "Al"}); code != http.StatusOK {
+		t.Fatalf("patch = %d %v", code, resp)
+	}
+
+	stored, err := gotype.MustNewManager[person](db).GetByIID(context.Background(), id)
+	if err != nil || stored == nil {
+		t.Fatalf("GetByIID: %v", err)
+	}
+	if stored.Nickname == nil || *stored.Nickname != "Al" {
+		t.Errorf("nickname = %v, want Al", stored.Nickname)
+	}
+}
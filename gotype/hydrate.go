@@ -2,8 +2,12 @@
 package gotype
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"sort"
+	"strings"
 	"sync/atomic"
 	"time"
 )
@@ -12,13 +16,31 @@ import (
 // This prevents infinite loops when the database graph contains cycles.
 const MaxHydrationDepth = 10
 
+// HydrateOption configures Hydrate and HydrateNew.
+type HydrateOption func(*hydrateConfig)
+
+type hydrateConfig struct {
+	strict bool
+}
+
+// WithStrictHydration makes hydration fail with a *StrictHydrationError when
+// the row contains keys that map to no field or role, or lacks values for
+// required fields, instead of silently ignoring them. Use it to catch drift
+// between the schema and the Go structs.
+func WithStrictHydration() HydrateOption {
+	return func(c *hydrateConfig) { c.strict = true }
+}
+
 // Hydrate populates the fields of a target struct pointer with data from a map
 // of TypeDB attribute names to values. The struct type must be registered.
-func Hydrate(target any, data map[string]any) error {
+func Hydrate(target any, data map[string]any, opts ...HydrateOption) error {
 	v, info, err := hydrateTargetInfo(target)
 	if err != nil {
 		return err
 	}
+	if err := checkStrictHydration(info, data, opts); err != nil {
+		return err
+	}
 	var visited map[string]bool
 	if len(info.Roles) > 0 {
 		visited = make(map[string]bool)
@@ -61,7 +83,9 @@ func hydrateValueWithDepth(v reflect.Value, info *ModelInfo, data map[string]any
 		}
 	}
 
-	// Set attribute fields
+	// Set attribute fields, collecting every failure rather than stopping at
+	// the first one.
+	var fieldErrs FieldErrors
 	for i := range info.Fields {
 		fi := &info.Fields[i]
 		val, ok := lookupResultValue(data, fi.Tag.Name)
@@ -72,51 +96,104 @@ func hydrateValueWithDepth(v reflect.Value, info *ModelInfo, data map[string]any
 			continue
 		}
 
-		field := v.Field(fi.FieldIndex)
+		field := fi.fieldValue(v)
 		if err := setFieldValue(field, fi, val); err != nil {
-			return fmt.Errorf("field %s: %w", fi.FieldName, err)
+			fieldErrs = append(fieldErrs, FieldError{
+				Type:   info.TypeName,
+				Field:  fi.FieldName,
+				Attr:   fi.Tag.Name,
+				Reason: err.Error(),
+				Value:  val,
+			})
 		}
 	}
+	hydrateExtra(v, info, data)
 
-	// Set role player fields (relations only)
+	// Set role player fields (relations only). A role holds one player object
+	// or a list of them; fields mapping the same role take its players in
+	// order, a slice field taking all that are left.
+	taken := make(map[string]int)
 	for _, role := range info.Roles {
-		roleData, ok := lookupResultValue(data, role.RoleName)
-		if !ok {
+		players := rolePlayerObjects(data[role.RoleName])
+		players = players[min(taken[role.RoleName], len(players)):]
+		if len(players) == 0 {
 			continue
 		}
-		roleMap, ok := roleData.(map[string]any)
+		playerInfo, ok := role.playerInfo(registryOf(info))
 		if !ok {
 			continue
 		}
-
-		playerInfo, ok := Lookup(role.PlayerTypeName)
-		if !ok {
-			continue
+		field := role.fieldValue(v)
+		if field.Kind() != reflect.Slice {
+			players = players[:1]
 		}
+		taken[role.RoleName] += len(players)
 
-		// Create a new instance of the player type and hydrate it
-		playerPtr := reflect.New(playerInfo.GoType)
-		nextVisited := visited
-		if nextVisited == nil {
-			nextVisited = make(map[string]bool)
-		}
-		if err := hydrateValueWithDepth(playerPtr.Elem(), playerInfo, roleMap, depth+1, nextVisited); err != nil {
-			return fmt.Errorf("role %s: %w", role.RoleName, err)
-		}
+		for _, roleMap := range players {
+			// Create a new instance of the player type and hydrate it. Each
+			// player gets its own copy of visited, so only a player that is
+			// also its own ancestor counts as a cycle.
+			playerPtr := reflect.New(playerInfo.GoType)
+			nextVisited := maps.Clone(visited)
+			if nextVisited == nil {
+				nextVisited = make(map[string]bool)
+			}
+			if err := hydrateValueWithDepth(playerPtr.Elem(), playerInfo, roleMap, depth+1, nextVisited); err != nil {
+				var nested FieldErrors
+				if !errors.As(err, &nested) {
+					return fmt.Errorf("role %s: %w", role.RoleName, err)
+				}
+				for _, fe := range nested {
+					fe.Field = role.FieldName + "." + fe.Field
+					fieldErrs = append(fieldErrs, fe)
+				}
+				continue
+			}
 
-		// Set the field (which is a pointer to the player type)
-		field := v.Field(role.FieldIndex)
-		if field.Kind() == reflect.Pointer && field.Type().Elem() == playerInfo.GoType {
-			field.Set(playerPtr)
+			// Set the field: a pointer to the player type, the player struct
+			// itself, or a slice of either.
+			switch {
+			case field.Kind() == reflect.Pointer && field.Type().Elem() == playerInfo.GoType:
+				field.Set(playerPtr)
+			case field.Type() == playerInfo.GoType:
+				field.Set(playerPtr.Elem())
+			case field.Kind() == reflect.Slice && field.Type().Elem() == playerPtr.Type():
+				field.Set(reflect.Append(field, playerPtr))
+			case field.Kind() == reflect.Slice && field.Type().Elem() == playerInfo.GoType:
+				field.Set(reflect.Append(field, playerPtr.Elem()))
+			}
 		}
 	}
 
+	if len(fieldErrs) > 0 {
+		return fieldErrs
+	}
+	return nil
+}
+
+// rolePlayerObjects returns the player objects of a fetched role: one
+// object, or a list of them as returned by a fetch subquery. Role player
+// objects are never value-wrapped; reading them directly keeps a player
+// attribute named "value" from being unwrapped.
+func rolePlayerObjects(entry any) []map[string]any {
+	switch entry := entry.(type) {
+	case map[string]any:
+		return []map[string]any{entry}
+	case []any:
+		players := make([]map[string]any, 0, len(entry))
+		for _, p := range entry {
+			if m, ok := p.(map[string]any); ok {
+				players = append(players, m)
+			}
+		}
+		return players
+	}
 	return nil
 }
 
 // HydrateNew is a convenience function that creates a new instance of type T,
 // hydrates it with the provided data, and returns a pointer to it.
-func HydrateNew[T any](data map[string]any) (*T, error) {
+func HydrateNew[T any](data map[string]any, opts ...HydrateOption) (*T, error) {
 	var zero T
 	t := reflect.TypeOf(zero)
 	if t.Kind() == reflect.Pointer {
@@ -126,14 +203,67 @@ func HydrateNew[T any](data map[string]any) (*T, error) {
 	if !ok {
 		return nil, fmt.Errorf("type %s is not registered", t.Name())
 	}
+	if err := checkStrictHydration(info, data, opts); err != nil {
+		return nil, err
+	}
 	return hydrateNewWithInfo[T](info, data)
 }
 
+// checkStrictHydration validates data against info when strict hydration is
+// requested. Synthetic "_"-prefixed keys are always accepted, as are unmapped
+// attributes when the model has an ",extra" field.
+func checkStrictHydration(info *ModelInfo, data map[string]any, opts []HydrateOption) error {
+	cfg := hydrateConfig{}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	if !cfg.strict {
+		return nil
+	}
+
+	var unexpected, missing []string
+	var fieldErrs FieldErrors
+	if info.extraField == nil {
+		for key := range data {
+			if strings.HasPrefix(key, "_") || isMappedAttr(info, key) {
+				continue
+			}
+			unexpected = append(unexpected, key)
+		}
+	}
+	sort.Strings(unexpected)
+	for _, key := range unexpected {
+		fieldErrs = append(fieldErrs, FieldError{
+			Type: info.TypeName, Attr: key, Reason: "unexpected attribute", Value: data[key],
+		})
+	}
+	for _, fi := range info.Fields {
+		if !fi.isRequired() {
+			continue
+		}
+		if val, ok := lookupResultValue(data, fi.Tag.Name); !ok || val == nil {
+			missing = append(missing, fi.Tag.Name)
+			fieldErrs = append(fieldErrs, FieldError{
+				Type: info.TypeName, Field: fi.FieldName, Attr: fi.Tag.Name, Reason: "required",
+			})
+		}
+	}
+	if len(fieldErrs) == 0 {
+		return nil
+	}
+	return &StrictHydrationError{TypeName: info.TypeName, Unexpected: unexpected, Missing: missing, Errors: fieldErrs}
+}
+
 // HydrateAny creates and hydrates an instance of the concrete type identified
 // by the "_type" field in data. This enables true polymorphic hydration where
 // the returned value's concrete type matches the TypeDB type label.
 // Returns the hydrated instance as any (actual type is a pointer to the concrete struct).
 func HydrateAny(data map[string]any) (any, error) {
+	return hydrateAnyIn(globalRegistry, data)
+}
+
+// hydrateAnyIn implements HydrateAny, resolving "_type" in reg.
+func hydrateAnyIn(reg *Registry, data map[string]any) (any, error) {
 	typeVal, ok := lookupResultValue(data, "_type")
 	if !ok {
 		return nil, fmt.Errorf("hydrate_any: _type field missing or not a string")
@@ -143,7 +273,7 @@ func HydrateAny(data map[string]any) (any, error) {
 		return nil, fmt.Errorf("hydrate_any: _type field missing or not a string")
 	}
 
-	modelInfo, ok := ResolveType(typeLabel)
+	modelInfo, ok := reg.ResolveType(typeLabel)
 	if !ok {
 		return nil, fmt.Errorf("hydrate_any: type %q not registered", typeLabel)
 	}
@@ -205,6 +335,9 @@ func setIIDOnBaseField(fv reflect.Value, iid string) bool {
 }
 
 func setFieldValue(field reflect.Value, fi *FieldInfo, val any) error {
+	if fi.scanner {
+		return scanFieldValue(field, fi, val)
+	}
 	if fi.IsSlice {
 		return setSliceField(field, fi, val)
 	}
@@ -1,6 +1,10 @@
 package gotype
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -238,6 +242,51 @@ func TestHydrate_VisitedIIDStopsCycle(t *testing.T) {
 	}
 }
 
+type testTeam struct {
+	BaseRelation
+	Lead    *TestPerson  `typedb:"role:member"`
+	Members []TestPerson `typedb:"role:member"`
+	Mentor  *TestPerson  `typedb:"role:mentor"`
+}
+
+func TestHydrate_RolePlayerLists(t *testing.T) {
+	ClearRegistry()
+	MustRegister[TestPerson]()
+	MustRegister[testTeam]()
+
+	data := map[string]any{
+		"_iid": "0xT",
+		"member": []any{
+			map[string]any{"_iid": "0xA", "name": "Alice"},
+			map[string]any{"_iid": "0xB", "name": "Bob"},
+			map[string]any{"_iid": "0xC", "name": "Carol"},
+		},
+		// Alice also plays mentor; that is not a cycle.
+		"mentor": []any{map[string]any{"_iid": "0xA", "name": "Alice"}},
+	}
+	team := &testTeam{}
+	if err := Hydrate(team, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if team.Lead == nil || team.Lead.Name != "Alice" {
+		t.Errorf("Lead = %+v", team.Lead)
+	}
+	if len(team.Members) != 2 || team.Members[0].Name != "Bob" || team.Members[1].GetIID() != "0xC" {
+		t.Errorf("Members = %+v", team.Members)
+	}
+	if team.Mentor == nil || team.Mentor.Name != "Alice" {
+		t.Errorf("Mentor = %+v", team.Mentor)
+	}
+
+	empty := &testTeam{}
+	if err := Hydrate(empty, map[string]any{"_iid": "0xT", "member": []any{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if empty.Lead != nil || empty.Members != nil {
+		t.Errorf("expected no players, got %+v", empty)
+	}
+}
+
 func TestHydrate_SliceField(t *testing.T) {
 	ClearRegistry()
 	MustRegister[TestPersonWithTags]()
@@ -313,3 +362,114 @@ func TestHydrateAny_UnregisteredType(t *testing.T) {
 		t.Fatal("expected error for unregistered type")
 	}
 }
+
+func TestHydrateNew_StrictHydration(t *testing.T) {
+	ClearRegistry()
+	MustRegister[TestPerson]()
+
+	data := map[string]any{
+		"_iid":     "0x1",
+		"name":     "Alice",
+		"nickname": "Al",
+		"phone":    "555",
+	}
+
+	// Default mode silently ignores unknown keys and missing fields.
+	if _, err := HydrateNew[TestPerson](data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := HydrateNew[TestPerson](data, WithStrictHydration())
+	var strictErr *StrictHydrationError
+	if !errors.As(err, &strictErr) {
+		t.Fatalf("expected *StrictHydrationError, got %v", err)
+	}
+	if !reflect.DeepEqual(strictErr.Unexpected, []string{"nickname", "phone"}) {
+		t.Errorf("Unexpected = %v", strictErr.Unexpected)
+	}
+	if !reflect.DeepEqual(strictErr.Missing, []string{"email"}) {
+		t.Errorf("Missing = %v", strictErr.Missing)
+	}
+	want := "strict hydration of test-person: unexpected keys nickname, phone; missing required fields email"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestHydrate_StrictHydrationAcceptsMatchingRow(t *testing.T) {
+	ClearRegistry()
+	MustRegister[TestPerson]()
+
+	person := &TestPerson{}
+	err := Hydrate(person, map[string]any{
+		"_iid":  "0x1",
+		"_type": "test-person",
+		"name":  "Alice",
+		"email": map[string]any{"value": "alice@example.com"},
+	}, WithStrictHydration())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if person.Email != "alice@example.com" {
+		t.Errorf("Email = %q", person.Email)
+	}
+}
+
+func TestHydrate_FieldErrorsCollectsEveryField(t *testing.T) {
+	ClearRegistry()
+	MustRegister[TestPerson]()
+	MustRegister[TestCompany]()
+	MustRegister[TestEmployment]()
+
+	data := map[string]any{
+		"salary": true,
+		"employee": map[string]any{
+			"name": "Alice",
+			"age":  true,
+		},
+		"employer": map[string]any{
+			"name":    "Acme",
+			"founded": []any{"x"},
+		},
+	}
+
+	err := Hydrate(&TestEmployment{}, data)
+	var fieldErrs FieldErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("expected FieldErrors, got %v", err)
+	}
+	want := FieldErrors{
+		{Type: "test-employment", Field: "Salary", Attr: "salary", Reason: "cannot coerce bool to float", Value: true},
+		{Type: "test-person", Field: "Employee.Age", Attr: "age", Reason: "cannot coerce bool to integer", Value: true},
+		{Type: "test-company", Field: "Employer.Founded", Attr: "founded", Reason: "cannot coerce []interface {} to integer", Value: []any{"x"}},
+	}
+	if !reflect.DeepEqual(fieldErrs, want) {
+		t.Errorf("FieldErrors =\n%#v\nwant\n%#v", fieldErrs, want)
+	}
+	if got := err.Error(); !strings.HasPrefix(got, "field Salary: cannot coerce bool to float; field Employee.Age: ") {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestFieldErrorsOf(t *testing.T) {
+	ClearRegistry()
+	MustRegister[TestPerson]()
+
+	_, err := HydrateNew[TestPerson](map[string]any{"name": "Alice", "phone": "555"}, WithStrictHydration())
+	got := FieldErrorsOf(fmt.Errorf("get: %w", err))
+	want := FieldErrors{
+		{Type: "test-person", Attr: "phone", Reason: "unexpected attribute", Value: "555"},
+		{Type: "test-person", Field: "Email", Attr: "email", Reason: "required"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldErrorsOf =\n%#v\nwant\n%#v", got, want)
+	}
+
+	_, err = HydrateNew[TestPerson](map[string]any{"name": "Alice", "age": "old"})
+	if fe := FieldErrorsOf(err); len(fe) != 1 || fe[0].Field != "Age" {
+		t.Errorf("FieldErrorsOf = %#v", fe)
+	}
+	if FieldErrorsOf(errors.New("boom")) != nil {
+		t.Error("FieldErrorsOf should be nil for unrelated errors")
+	}
+}
@@ -0,0 +1,489 @@
+// Package importer loads Neo4j CSV exports into TypeDB through registered
+// gotype models, for teams migrating a property graph.
+//
+// Both common export layouts are understood. A combined file written by
+// apoc.export.csv.all has _id, _labels, _start, _end and _type columns next
+// to the properties; neo4j-admin style files mark columns as :ID, :LABEL,
+// :START_ID, :END_ID and :TYPE, with optional ID spaces such as
+// "personId:ID(Person)" and typed properties such as "born:int".
+//
+// A Mapping says which Neo4j labels become which entity types and which
+// relationship types become which relation types:
+//
+//	im, err := importer.New(db, importer.Mapping{
+//		Nodes: []importer.NodeMapping{
+//			{Label: "Person", Type: "person", Properties: map[string]string{"fullName": "name"}},
+//			{Label: "Company", Type: "company"},
+//		},
+//		Relationships: []importer.RelationshipMapping{
+//			{Type: "WORKS_AT", Relation: "employment", StartRole: "employee", EndRole: "employer"},
+//		},
+//	})
+//	stats, err := im.Import(ctx, nodesCSV)
+//	stats, err = im.Import(ctx, relationshipsCSV)
+//
+// Rows are inserted in chunked write transactions (WithChunkSize). The
+// Importer remembers the IID of every node it inserted, so relationships can
+// come from a later Import call on the same Importer. An import that fails
+// part way keeps the chunks committed before the failure.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// DefaultChunkSize is the number of rows inserted per transaction.
+const DefaultChunkSize = 500
+
+// Mapping maps Neo4j labels and relationship types onto registered models.
+// It can be decoded from JSON.
+type Mapping struct {
+	Nodes         []NodeMapping         `json:"nodes"`
+	Relationships []RelationshipMapping `json:"relationships"`
+}
+
+// NodeMapping maps nodes carrying Label to the entity type Type. A node with
+// several mapped labels uses the first matching NodeMapping.
+type NodeMapping struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	// Properties maps Neo4j property names to attribute names. An empty
+	// attribute name drops the property. Unlisted properties go to the
+	// attribute of the same name or its kebab-case form (birthDate to
+	// birth-date) when the model has one, and are dropped otherwise.
+	Properties map[string]string `json:"properties"`
+}
+
+// RelationshipMapping maps relationships of Type to the relation Relation,
+// whose StartRole is played by the start node and EndRole by the end node.
+type RelationshipMapping struct {
+	Type       string            `json:"type"`
+	Relation   string            `json:"relation"`
+	StartRole  string            `json:"startRole"`
+	EndRole    string            `json:"endRole"`
+	Properties map[string]string `json:"properties"`
+}
+
+// LoadMapping decodes a JSON Mapping, rejecting unknown fields.
+func LoadMapping(r io.Reader) (Mapping, error) {
+	var m Mapping
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return Mapping{}, fmt.Errorf("importer: mapping: %w", err)
+	}
+	return m, nil
+}
+
+// Stats counts the rows of an Import.
+type Stats struct {
+	Nodes         int
+	Relationships int
+	// Skipped counts rows with an unmapped label or relationship type, and
+	// relationships whose start or end node was not imported.
+	Skipped int
+}
+
+// Option configures an Importer.
+type Option func(*Importer)
+
+// WithChunkSize sets how many rows are inserted per transaction (default
+// DefaultChunkSize).
+func WithChunkSize(n int) Option {
+	return func(im *Importer) {
+		if n > 0 {
+			im.chunkSize = n
+		}
+	}
+}
+
+// Importer inserts Neo4j CSV rows according to a Mapping. It is not safe
+// for concurrent use.
+type Importer struct {
+	db        *gotype.Database
+	chunkSize int
+	nodes     []nodeTarget
+	rels      map[string]relTarget
+	// imported maps node IDs, qualified by their ID space, to the inserted
+	// instances.
+	imported map[string]node
+}
+
+type node struct {
+	typeName, iid string
+}
+
+type nodeTarget struct {
+	label string
+	info  *gotype.ModelInfo
+	props map[string]string
+}
+
+type relTarget struct {
+	info               *gotype.ModelInfo
+	startRole, endRole string
+	props              map[string]string
+}
+
+// New returns an Importer for db. Every type in m must be registered in the
+// Database's registry, and every role must belong to its relation.
+func New(db *gotype.Database, m Mapping, opts ...Option) (*Importer, error) {
+	im := &Importer{
+		db:        db,
+		chunkSize: DefaultChunkSize,
+		rels:      make(map[string]relTarget),
+		imported:  make(map[string]node),
+	}
+	for _, opt := range opts {
+		opt(im)
+	}
+	reg := db.Registry()
+	for _, nm := range m.Nodes {
+		info, ok := reg.Lookup(nm.Type)
+		if !ok || info.Kind != gotype.ModelKindEntity {
+			return nil, fmt.Errorf("importer: label %s: %s is not a registered entity type", nm.Label, nm.Type)
+		}
+		if err := checkProperties(info, nm.Properties); err != nil {
+			return nil, fmt.Errorf("importer: label %s: %w", nm.Label, err)
+		}
+		im.nodes = append(im.nodes, nodeTarget{label: nm.Label, info: info, props: nm.Properties})
+	}
+	for _, rm := range m.Relationships {
+		info, ok := reg.Lookup(rm.Relation)
+		if !ok || info.Kind != gotype.ModelKindRelation {
+			return nil, fmt.Errorf("importer: relationship %s: %s is not a registered relation type", rm.Type, rm.Relation)
+		}
+		for _, role := range []string{rm.StartRole, rm.EndRole} {
+			if !slices.ContainsFunc(info.Roles, func(r gotype.RoleInfo) bool { return r.RoleName == role }) {
+				return nil, fmt.Errorf("importer: relationship %s: %s has no role %q", rm.Type, rm.Relation, role)
+			}
+		}
+		if _, dup := im.rels[rm.Type]; dup {
+			return nil, fmt.Errorf("importer: relationship %s is mapped twice", rm.Type)
+		}
+		if err := checkProperties(info, rm.Properties); err != nil {
+			return nil, fmt.Errorf("importer: relationship %s: %w", rm.Type, err)
+		}
+		im.rels[rm.Type] = relTarget{info: info, startRole: rm.StartRole, endRole: rm.EndRole, props: rm.Properties}
+	}
+	return im, nil
+}
+
+// checkProperties checks an explicit property mapping against info.
+func checkProperties(info *gotype.ModelInfo, explicit map[string]string) error {
+	for _, prop := range slices.Sorted(maps.Keys(explicit)) {
+		attr := explicit[prop]
+		if _, ok := info.FieldByAttrName(attr); attr != "" && !ok {
+			return fmt.Errorf("property %s: %s has no attribute %s", prop, info.TypeName, attr)
+		}
+	}
+	return nil
+}
+
+// Import reads one CSV export from r and inserts its nodes, then its
+// relationships. Nodes are inserted while reading; relationships are
+// buffered until the end of the input so they can refer to nodes that come
+// after them.
+func (im *Importer) Import(ctx context.Context, r io.Reader) (Stats, error) {
+	var stats Stats
+	cr := csv.NewReader(r)
+	raw, err := cr.Read()
+	if err != nil {
+		return stats, fmt.Errorf("importer: read header: %w", err)
+	}
+	h := parseHeader(raw)
+	cr.FieldsPerRecord = len(raw)
+
+	b := &batch{im: im, stats: &stats, nodes: make(map[string]node)}
+	var relRows []csvRow
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("importer: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		row := csvRow{line: line, fields: fields}
+		switch {
+		case h.start >= 0 && fields[h.start] != "":
+			relRows = append(relRows, row)
+		case h.id >= 0 && fields[h.id] != "":
+			if err := im.addNode(ctx, b, h, row); err != nil {
+				return stats, err
+			}
+		default:
+			return stats, fmt.Errorf("importer: line %d: row has neither a node ID nor a start node", line)
+		}
+	}
+	if err := b.flush(ctx); err != nil {
+		return stats, err
+	}
+	for _, row := range relRows {
+		if err := im.addRelationship(ctx, b, h, row); err != nil {
+			return stats, err
+		}
+	}
+	return stats, b.flush(ctx)
+}
+
+type csvRow struct {
+	line   int
+	fields []string
+}
+
+func (im *Importer) addNode(ctx context.Context, b *batch, h header, row csvRow) error {
+	var labels []string
+	if h.labels >= 0 {
+		labels = strings.FieldsFunc(row.fields[h.labels], func(r rune) bool { return r == ':' || r == ';' })
+	}
+	i := slices.IndexFunc(im.nodes, func(t nodeTarget) bool { return slices.Contains(labels, t.label) })
+	if i < 0 {
+		b.stats.Skipped++
+		return nil
+	}
+	target := im.nodes[i]
+	has, err := hasClauses(target.info, target.props, h, row)
+	if err != nil {
+		return fmt.Errorf("importer: line %d: %w", row.line, err)
+	}
+	query := "insert\n$e isa " + target.info.TypeName + has + ";\nfetch {\n  \"_iid\": iid($e)\n};"
+	id := h.idSpace + "\x00" + row.fields[h.id]
+	_, done := im.imported[id]
+	if _, pending := b.nodes[id]; done || pending {
+		return fmt.Errorf("importer: line %d: duplicate node ID %s", row.line, row.fields[h.id])
+	}
+	results, err := b.query(ctx, row.line, query)
+	if err != nil {
+		return err
+	}
+	iid := ""
+	if len(results) == 1 {
+		iid, _ = results[0]["_iid"].(string)
+	}
+	if iid == "" {
+		return fmt.Errorf("importer: line %d: insert returned no IID", row.line)
+	}
+	b.nodes[id] = node{typeName: target.info.TypeName, iid: iid}
+	return b.next(ctx)
+}
+
+func (im *Importer) addRelationship(ctx context.Context, b *batch, h header, row csvRow) error {
+	relType := ""
+	if h.relType >= 0 {
+		relType = row.fields[h.relType]
+	}
+	target, ok := im.rels[relType]
+	if !ok || h.end < 0 {
+		b.stats.Skipped++
+		return nil
+	}
+	start, okStart := im.imported[h.startSpace+"\x00"+row.fields[h.start]]
+	end, okEnd := im.imported[h.endSpace+"\x00"+row.fields[h.end]]
+	if !okStart || !okEnd {
+		b.stats.Skipped++
+		return nil
+	}
+	has, err := hasClauses(target.info, target.props, h, row)
+	if err != nil {
+		return fmt.Errorf("importer: line %d: %w", row.line, err)
+	}
+	query := fmt.Sprintf("match\n$s isa %s, iid %s;\n$t isa %s, iid %s;\ninsert\n$r isa %s, links (%s: $s, %s: $t)%s;",
+		start.typeName, start.iid, end.typeName, end.iid,
+		target.info.TypeName, target.startRole, target.endRole, has)
+	if _, err := b.query(ctx, row.line, query); err != nil {
+		return err
+	}
+	b.rels++
+	return b.next(ctx)
+}
+
+// hasClauses renders ", has attr value" for the mapped, non-empty properties
+// of row, converting cells to the attributes' value types.
+func hasClauses(info *gotype.ModelInfo, explicit map[string]string, h header, row csvRow) (string, error) {
+	var b strings.Builder
+	for _, p := range h.props {
+		cell := row.fields[p.col]
+		if cell == "" {
+			continue
+		}
+		attr, ok := explicit[p.name]
+		if !ok {
+			attr = p.name
+			if _, found := info.FieldByAttrName(attr); !found {
+				attr = kebab(p.name)
+			}
+		}
+		field, found := info.FieldByAttrName(attr)
+		if attr == "" || !found {
+			continue
+		}
+		values := []string{cell}
+		if strings.HasPrefix(cell, "[") {
+			var list []any
+			if err := json.Unmarshal([]byte(cell), &list); err == nil {
+				values = values[:0]
+				for _, v := range list {
+					values = append(values, fmt.Sprint(v))
+				}
+			}
+		} else if p.array {
+			values = strings.Split(cell, ";")
+		}
+		if len(values) > 1 && !field.IsSlice {
+			return "", fmt.Errorf("property %s: %d values for single-valued attribute %s", p.name, len(values), attr)
+		}
+		for _, s := range values {
+			v, err := gotype.ParseAttributeValue(field.ValueType, s)
+			if err != nil {
+				return "", fmt.Errorf("property %s: %w", p.name, err)
+			}
+			b.WriteString(", has ")
+			b.WriteString(attr)
+			b.WriteByte(' ')
+			b.WriteString(gotype.FormatValue(v))
+		}
+	}
+	return b.String(), nil
+}
+
+// kebab converts camelCase and snake_case property names to kebab-case.
+func kebab(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_':
+			b.WriteByte('-')
+		case unicode.IsUpper(r):
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// header locates the special columns of an export; -1 marks a missing one.
+type header struct {
+	id, labels, start, end, relType int
+	idSpace, startSpace, endSpace   string
+	props                           []property
+}
+
+type property struct {
+	name  string
+	col   int
+	array bool
+}
+
+// parseHeader recognizes APOC column names and neo4j-admin column types.
+func parseHeader(raw []string) header {
+	h := header{id: -1, labels: -1, start: -1, end: -1, relType: -1}
+	for i, cell := range raw {
+		name, typ, _ := strings.Cut(strings.TrimSpace(cell), ":")
+		space := ""
+		if open := strings.IndexByte(typ, '('); open >= 0 && strings.HasSuffix(typ, ")") {
+			typ, space = typ[:open], typ[open+1:len(typ)-1]
+		}
+		switch {
+		case strings.EqualFold(typ, "ID") || (typ == "" && name == "_id"):
+			h.id, h.idSpace = i, space
+		case strings.EqualFold(typ, "LABEL") || (typ == "" && name == "_labels"):
+			h.labels = i
+		case strings.EqualFold(typ, "START_ID") || (typ == "" && name == "_start"):
+			h.start, h.startSpace = i, space
+		case strings.EqualFold(typ, "END_ID") || (typ == "" && name == "_end"):
+			h.end, h.endSpace = i, space
+		case strings.EqualFold(typ, "TYPE") || (typ == "" && name == "_type"):
+			h.relType = i
+		case strings.EqualFold(typ, "IGNORE") || name == "":
+		default:
+			h.props = append(h.props, property{name: name, col: i, array: strings.HasSuffix(typ, "[]")})
+		}
+	}
+	return h
+}
+
+// batch groups inserts into write transactions of the Importer's chunk
+// size. Inserted nodes and relationships are counted, and node IDs become
+// visible to relationships, only once their transaction commits.
+type batch struct {
+	im    *Importer
+	stats *Stats
+	tx    gotype.Tx
+	rows  int
+	nodes map[string]node
+	rels  int
+}
+
+// query runs query in the current transaction, opening one if needed.
+func (b *batch) query(ctx context.Context, line int, query string) ([]map[string]any, error) {
+	if b.tx == nil {
+		tx, err := b.im.db.TransactionContext(ctx, gotype.WriteTransaction)
+		if err != nil {
+			return nil, fmt.Errorf("importer: %w", err)
+		}
+		b.tx = tx
+	}
+	results, err := b.tx.QueryWithContext(ctx, query)
+	if err != nil {
+		b.discard()
+		return nil, fmt.Errorf("importer: line %d: %w", line, err)
+	}
+	b.rows++
+	return results, nil
+}
+
+// next commits the transaction once it holds a full chunk.
+func (b *batch) next(ctx context.Context) error {
+	if b.rows >= b.im.chunkSize {
+		return b.flush(ctx)
+	}
+	return nil
+}
+
+// flush commits the current transaction.
+func (b *batch) flush(ctx context.Context) error {
+	if b.tx == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		b.discard()
+		return err
+	}
+	if err := b.tx.Commit(); err != nil {
+		b.discard()
+		return fmt.Errorf("importer: commit: %w", err)
+	}
+	b.tx.Close()
+	maps.Copy(b.im.imported, b.nodes)
+	b.stats.Nodes += len(b.nodes)
+	b.stats.Relationships += b.rels
+	b.reset()
+	return nil
+}
+
+// discard closes the current transaction without committing it.
+func (b *batch) discard() {
+	b.tx.Close()
+	b.reset()
+}
+
+func (b *batch) reset() {
+	b.tx, b.rows, b.rels = nil, 0, 0
+	clear(b.nodes)
+}
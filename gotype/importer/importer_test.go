@@ -0,0 +1,212 @@
+package importer_test
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+	"github.com/CaliLuke/go-typeql/gotype/importer"
+)
+
+type person struct {
+	gotype.BaseEntity
+	Name      string     `typedb:"name,key"`
+	BirthDate *time.Time `typedb:"birth-date,card=0..1"`
+	Nicknames []string   `typedb:"nickname,card=0.."`
+}
+
+type company struct {
+	gotype.BaseEntity
+	Title string `typedb:"title,key"`
+}
+
+type employment struct {
+	gotype.BaseRelation
+	Employee *person  `typedb:"role:employee"`
+	Employer *company `typedb:"role:employer"`
+	Since    *int     `typedb:"since,card=0..1"`
+}
+
+var mapping = importer.Mapping{
+	Nodes: []importer.NodeMapping{
+		{Label: "Person", Type: "person", Properties: map[string]string{"fullName": "name", "ssn": ""}},
+		{Label: "Company", Type: "company", Properties: map[string]string{"name": "title"}},
+	},
+	Relationships: []importer.RelationshipMapping{
+		{Type: "WORKS_AT", Relation: "employment", StartRole: "employee", EndRole: "employer"},
+	},
+}
+
+func newDB(t *testing.T) *gotype.Database {
+	t.Helper()
+	reg := gotype.NewRegistry()
+	for _, err := range []error{
+		gotype.RegisterIn[person](reg),
+		gotype.RegisterIn[company](reg),
+		gotype.RegisterIn[employment](reg),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := gotype.NewDatabase(gotypetest.NewFakeDB(), "test").WithRegistry(reg)
+	if err := db.ExecuteSchema(context.Background(), reg.GenerateSchema()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// employers returns "employee@employer:since" for every employment.
+func employers(t *testing.T, db *gotype.Database) []string {
+	t.Helper()
+	rels, err := gotype.MustNewManager[employment](db).GetWithRoles(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, r := range rels {
+		s := r.Employee.Name + "@" + r.Employer.Title
+		if r.Since != nil {
+			s += ":" + strconv.Itoa(*r.Since)
+		}
+		out = append(out, s)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func TestImportAPOC(t *testing.T) {
+	db := newDB(t)
+	ctx := context.Background()
+	// The relationship row comes before the company node it points at.
+	const export = `_id,_labels,fullName,birthDate,nickname,ssn,name,_start,_end,_type,since
+0,:Person,Alice,1990-04-01,"[""Al"",""Ali""]",123,,,,,
+1,:Person:Employee,Bob,,,456,,,,,
+,,,,,,,0,2,WORKS_AT,2019
+,,,,,,,1,2,WORKS_AT,
+,,,,,,,0,1,KNOWS,
+2,:Company,,,,,Acme,,,,
+3,:Robot,,,,,,,,,
+`
+	im, err := importer.New(db, mapping, importer.WithChunkSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := im.Import(ctx, strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != (importer.Stats{Nodes: 3, Relationships: 2, Skipped: 2}) {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	people, err := gotype.MustNewManager[person](db).Get(ctx, map[string]any{"name": "Alice"})
+	if err != nil || len(people) != 1 {
+		t.Fatalf("Alice = %v, %v", people, err)
+	}
+	alice := people[0]
+	if alice.BirthDate == nil || alice.BirthDate.Year() != 1990 {
+		t.Errorf("birth date = %v", alice.BirthDate)
+	}
+	slices.Sort(alice.Nicknames)
+	if !slices.Equal(alice.Nicknames, []string{"Al", "Ali"}) {
+		t.Errorf("nicknames = %v", alice.Nicknames)
+	}
+	if got := employers(t, db); !slices.Equal(got, []string{"Alice@Acme:2019", "Bob@Acme"}) {
+		t.Errorf("employments = %v", got)
+	}
+}
+
+func TestImportAdminFiles(t *testing.T) {
+	db := newDB(t)
+	ctx := context.Background()
+	const people = `personId:ID(Person),fullName,nickname:string[],:LABEL
+p1,Carol,Caz;CC,Person
+`
+	const companies = `companyId:ID(Company),name,:LABEL
+p1,Globex,Company
+`
+	const jobs = `:START_ID(Person),:END_ID(Company),since:int,:TYPE
+p1,p1,2021,WORKS_AT
+p2,p1,2022,WORKS_AT
+`
+	im, err := importer.New(db, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total importer.Stats
+	for _, file := range []string{people, companies, jobs} {
+		stats, err := im.Import(ctx, strings.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		total.Nodes += stats.Nodes
+		total.Relationships += stats.Relationships
+		total.Skipped += stats.Skipped
+	}
+	// p2 is unknown in the Person ID space.
+	if total != (importer.Stats{Nodes: 2, Relationships: 1, Skipped: 1}) {
+		t.Errorf("stats = %+v", total)
+	}
+	if got := employers(t, db); !slices.Equal(got, []string{"Carol@Globex:2021"}) {
+		t.Errorf("employments = %v", got)
+	}
+	carol, err := gotype.MustNewManager[person](db).Get(ctx, map[string]any{"name": "Carol"})
+	if err != nil || len(carol) != 1 || len(carol[0].Nicknames) != 2 {
+		t.Errorf("Carol = %+v, %v", carol, err)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	db := newDB(t)
+	ctx := context.Background()
+
+	for name, m := range map[string]importer.Mapping{
+		"unknown type":      {Nodes: []importer.NodeMapping{{Label: "X", Type: "robot"}}},
+		"relation as node":  {Nodes: []importer.NodeMapping{{Label: "X", Type: "employment"}}},
+		"unknown attribute": {Nodes: []importer.NodeMapping{{Label: "X", Type: "person", Properties: map[string]string{"a": "salary"}}}},
+		"unknown role": {Relationships: []importer.RelationshipMapping{
+			{Type: "R", Relation: "employment", StartRole: "boss", EndRole: "employer"},
+		}},
+	} {
+		if _, err := importer.New(db, m); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	im, err := importer.New(db, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, export := range map[string]string{
+		"bad value":      "_id,_labels,fullName,birthDate\n0,:Person,Dan,yesterday\n",
+		"list in scalar": "_id,_labels,fullName\n0,:Person,\"[\"\"A\"\",\"\"B\"\"]\"\n",
+		"duplicate id":   "_id,_labels,fullName\n0,:Person,Eve\n0,:Person,Fay\n",
+		"no id":          "_labels,fullName\n:Person,Gus\n",
+	} {
+		if _, err := im.Import(ctx, strings.NewReader(export)); err == nil || !strings.Contains(err.Error(), "line") {
+			t.Errorf("%s: err = %v, want error with line", name, err)
+		}
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	m, err := importer.LoadMapping(strings.NewReader(`{
+  "nodes": [{"label": "Person", "type": "person", "properties": {"fullName": "name"}}],
+  "relationships": [{"type": "WORKS_AT", "relation": "employment", "startRole": "employee", "endRole": "employer"}]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Nodes[0].Properties["fullName"] != "name" || m.Relationships[0].EndRole != "employer" {
+		t.Errorf("mapping = %+v", m)
+	}
+	if _, err := importer.LoadMapping(strings.NewReader(`{"edges": []}`)); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
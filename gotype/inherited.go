@@ -0,0 +1,68 @@
+package gotype
+
+import "slices"
+
+// IncludeInherited returns a copy of the manager whose fetches include the
+// attributes T inherits from its registered supertypes (the default) or,
+// when include is false, only the attributes declared on T itself, for read
+// paths that need no more than the subtype's own fields. The inherited
+// fields of the returned instances are left at their zero value; Get,
+// GetWithRoles, GetByIID and queries built from the manager are affected,
+// polymorphic fetches are not. GetByIID bypasses the instance caches while
+// inherited attributes are excluded, so partial instances are never cached.
+func (m *Manager[T]) IncludeInherited(include bool) *Manager[T] {
+	cp := *m
+	cp.ownFieldsOnly = !include
+	return &cp
+}
+
+// IncludeInherited controls whether the query fetches the attributes T
+// inherits from its registered supertypes, as Manager.IncludeInherited does.
+func (q *Query[T]) IncludeInherited(include bool) *Query[T] {
+	q.mgr = q.mgr.IncludeInherited(include)
+	return q
+}
+
+// fetchInfo returns the model info the manager builds fetch clauses from.
+func (m *Manager[T]) fetchInfo() *ModelInfo {
+	if m.ownFieldsOnly {
+		return ownFieldsInfo(m.info)
+	}
+	return m.info
+}
+
+// ownFieldsInfo returns a copy of info without the attributes it inherits
+// from its registered supertypes, or info itself when it inherits none. The
+// copy has no templates, so its fetches are compiled on demand.
+func ownFieldsInfo(info *ModelInfo) *ModelInfo {
+	inherited := inheritedAttrs(info)
+	if len(inherited) == 0 {
+		return info
+	}
+	isInherited := func(fi FieldInfo) bool { return inherited[fi.Tag.Name] }
+	own := *info
+	own.Fields = slices.DeleteFunc(slices.Clone(info.Fields), isInherited)
+	own.KeyFields = slices.DeleteFunc(slices.Clone(info.KeyFields), isInherited)
+	own.templates = nil
+	return &own
+}
+
+// inheritedAttrs returns the attribute names owned by the registered
+// supertypes of info, following Supertype until a type is not registered.
+func inheritedAttrs(info *ModelInfo) map[string]bool {
+	reg := registryOf(info)
+	attrs := make(map[string]bool)
+	seen := map[string]bool{info.TypeName: true}
+	for name := info.Supertype; name != "" && !seen[name]; {
+		seen[name] = true
+		super, ok := reg.Lookup(name)
+		if !ok {
+			break
+		}
+		for _, fi := range super.Fields {
+			attrs[fi.Tag.Name] = true
+		}
+		name = super.Supertype
+	}
+	return attrs
+}
@@ -0,0 +1,58 @@
+package gotype
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestManager_IncludeInherited(t *testing.T) {
+	reg := NewRegistry()
+	MustRegisterIn[testAnimal](reg)
+	MustRegisterIn[testDog](reg)
+	dogInfo, _ := reg.Lookup("test-dog")
+	dogInfo.Supertype = "test-animal"
+
+	txs := []*mockTx{
+		{responses: [][]map[string]any{{{"_iid": "0x1", "breed": "collie"}}}},
+		{responses: [][]map[string]any{{{"_iid": "0x1", "breed": "collie"}}}},
+		{responses: [][]map[string]any{{{"_iid": "0x1", "name": "Rex", "breed": "collie"}}}},
+	}
+	db := NewDatabase(&mockConn{txs: txs}, "test_db").WithRegistry(reg)
+	dogs := MustNewManager[testDog](db).WithCache()
+	own := dogs.IncludeInherited(false)
+
+	got, err := own.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Breed != "collie" || got[0].Name != "" {
+		t.Fatalf("got %+v", got)
+	}
+	assertContains(t, txs[0].queries[0], `"breed": $e.breed`)
+	if strings.Contains(txs[0].queries[0], `"name"`) {
+		t.Errorf("inherited attribute fetched: %s", txs[0].queries[0])
+	}
+
+	// Partial instances stay out of the cache.
+	if _, err := own.GetByIID(context.Background(), "0x1"); err != nil {
+		t.Fatal(err)
+	}
+	dog, err := dogs.GetByIID(context.Background(), "0x1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dog.Name != "Rex" {
+		t.Errorf("GetByIID served a partial instance: %+v", dog)
+	}
+	assertContains(t, txs[2].queries[0], `"name": $e.name`)
+
+	// Queries follow the option too.
+	q, err := dogs.Query().IncludeInherited(false).buildQuery()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(q, `"name"`) {
+		t.Errorf("query fetched inherited attribute: %s", q)
+	}
+}
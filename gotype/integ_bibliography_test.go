@@ -0,0 +1,480 @@
+//go:build integration && cgo && typedb
+
+package gotype_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ---------------------------------------------------------------------------
+// Bibliography domain models
+// ---------------------------------------------------------------------------
+
+type BibPaper struct {
+	gotype.BaseEntity
+	Title     string `typedb:"paper-title,key"`
+	Year      int    `typedb:"paper-year"`
+	Citations int    `typedb:"citation-count"`
+}
+
+type BibAuthor struct {
+	gotype.BaseEntity
+	Name string `typedb:"author-name,key"`
+}
+
+type BibVenue struct {
+	gotype.BaseEntity
+	Name string `typedb:"venue-name,key"`
+	Kind string `typedb:"venue-kind"`
+}
+
+type BibAuthored struct {
+	gotype.BaseRelation
+	Author   *BibAuthor `typedb:"role:author"`
+	Paper    *BibPaper  `typedb:"role:paper"`
+	Position int        `typedb:"author-position"`
+}
+
+type BibCites struct {
+	gotype.BaseRelation
+	Citing  *BibPaper `typedb:"role:citing"`
+	Cited   *BibPaper `typedb:"role:cited"`
+	Context string    `typedb:"citation-context"`
+}
+
+type BibPublishedIn struct {
+	gotype.BaseRelation
+	Paper *BibPaper `typedb:"role:publication"`
+	Venue *BibVenue `typedb:"role:venue"`
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+func setupBibliographyDB(t *testing.T) *gotype.Database {
+	return setupTestDBWith(t, func() {
+		_ = gotype.Register[BibPaper](gotype.WithTypeName("paper"))
+		_ = gotype.Register[BibAuthor](gotype.WithTypeName("author"))
+		_ = gotype.Register[BibVenue](gotype.WithTypeName("venue"))
+		_ = gotype.Register[BibAuthored](gotype.WithTypeName("authored"))
+		_ = gotype.Register[BibCites](gotype.WithTypeName("cites"))
+		_ = gotype.Register[BibPublishedIn](gotype.WithTypeName("published-in"))
+	})
+}
+
+// bibCitations is the citation graph used by seedBibliography, as
+// citing -> cited with the citation context.
+var bibCitations = []struct{ citing, cited, context string }{
+	{"P2", "P1", "background"},
+	{"P3", "P1", "background"},
+	{"P4", "P1", "method"},
+	{"P4", "P2", "background"},
+	{"P5", "P3", "method"},
+	{"P5", "P4", "background"},
+	{"P5", "P1", "background"},
+	{"P6", "P4", "comparison"},
+	{"P6", "P2", "comparison"},
+	{"P7", "P1", "background"},
+	{"P7", "P3", "background"},
+	{"P7", "P5", "method"},
+}
+
+// seedBibliography loads seven papers by five authors in three venues:
+//
+//	P1 2015 ada, ben   ICML       P5 2019 ada, dee   NeurIPS
+//	P2 2016 ben, cy    JMLR       P6 2020 eli        JMLR
+//	P3 2017 ada        NeurIPS    P7 2021 ben, ada   ICML
+//	P4 2018 cy, dee    ICML
+//
+// Citation counts are left at zero; tests that need them call
+// storeCitationCounts.
+func seedBibliography(t *testing.T) *gotype.Database {
+	t.Helper()
+	db := setupBibliographyDB(t)
+	ctx := context.Background()
+
+	venueMgr := gotype.MustNewManager[BibVenue](db)
+	venues := make(map[string]*BibVenue)
+	for _, v := range []*BibVenue{
+		{Name: "ICML", Kind: "conference"},
+		{Name: "NeurIPS", Kind: "conference"},
+		{Name: "JMLR", Kind: "journal"},
+	} {
+		venues[v.Name] = insertAndGet(t, ctx, venueMgr, v, "venue-name", v.Name)
+	}
+
+	authorMgr := gotype.MustNewManager[BibAuthor](db)
+	authors := make(map[string]*BibAuthor)
+	for _, name := range []string{"ada", "ben", "cy", "dee", "eli"} {
+		authors[name] = insertAndGet(t, ctx, authorMgr, &BibAuthor{Name: name}, "author-name", name)
+	}
+
+	paperMgr := gotype.MustNewManager[BibPaper](db)
+	authoredMgr := gotype.MustNewManager[BibAuthored](db)
+	publishedMgr := gotype.MustNewManager[BibPublishedIn](db)
+	papers := make(map[string]*BibPaper)
+	for _, p := range []struct {
+		title   string
+		year    int
+		venue   string
+		authors []string
+	}{
+		{"P1", 2015, "ICML", []string{"ada", "ben"}},
+		{"P2", 2016, "JMLR", []string{"ben", "cy"}},
+		{"P3", 2017, "NeurIPS", []string{"ada"}},
+		{"P4", 2018, "ICML", []string{"cy", "dee"}},
+		{"P5", 2019, "NeurIPS", []string{"ada", "dee"}},
+		{"P6", 2020, "JMLR", []string{"eli"}},
+		{"P7", 2021, "ICML", []string{"ben", "ada"}},
+	} {
+		paper := insertAndGet(t, ctx, paperMgr, &BibPaper{Title: p.title, Year: p.year}, "paper-title", p.title)
+		papers[p.title] = paper
+		assertInsert(t, ctx, publishedMgr, &BibPublishedIn{Paper: paper, Venue: venues[p.venue]})
+		for i, a := range p.authors {
+			assertInsert(t, ctx, authoredMgr, &BibAuthored{Author: authors[a], Paper: paper, Position: i + 1})
+		}
+	}
+
+	citesMgr := gotype.MustNewManager[BibCites](db)
+	for _, c := range bibCitations {
+		assertInsert(t, ctx, citesMgr, &BibCites{Citing: papers[c.citing], Cited: papers[c.cited], Context: c.context})
+	}
+
+	return db
+}
+
+// storeCitationCounts counts incoming citations per paper with the query
+// builder and writes each count to citation-count with Query.Update.
+func storeCitationCounts(t *testing.T, ctx context.Context, db *gotype.Database) map[string]int64 {
+	t.Helper()
+	citesMgr := gotype.MustNewManager[BibCites](db)
+	paperMgr := gotype.MustNewManager[BibPaper](db)
+	counts := make(map[string]int64)
+	for _, title := range []string{"P1", "P2", "P3", "P4", "P5", "P6", "P7"} {
+		n, err := citesMgr.Query().
+			Filter(gotype.RolePlayer("cited", gotype.Eq("paper-title", title))).
+			Count(ctx)
+		if err != nil {
+			t.Fatalf("count citations of %s: %v", title, err)
+		}
+		counts[title] = n
+		if _, err := paperMgr.Query().
+			Filter(gotype.Eq("paper-title", title)).
+			Update(ctx, map[string]any{"citation-count": n}); err != nil {
+			t.Fatalf("store citations of %s: %v", title, err)
+		}
+	}
+	return counts
+}
+
+// coauthors returns the sorted co-authors of name.
+func coauthors(t *testing.T, ctx context.Context, db *gotype.Database, name string) []string {
+	t.Helper()
+	return fetchColumn(t, ctx, db, "name", fmt.Sprintf(`match
+$a isa author, has author-name %q;
+$w1 isa authored, links (author: $a, paper: $p);
+$w2 isa authored, links (author: $co, paper: $p);
+not { $co is $a; };
+fetch { "name": $co.author-name };`, name))
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestIntegration_Bibliography_AllInserted(t *testing.T) {
+	db := seedBibliography(t)
+	ctx := context.Background()
+
+	assertCount(t, ctx, gotype.MustNewManager[BibPaper](db), 7)
+	assertCount(t, ctx, gotype.MustNewManager[BibAuthor](db), 5)
+	assertCount(t, ctx, gotype.MustNewManager[BibVenue](db), 3)
+	assertCount(t, ctx, gotype.MustNewManager[BibAuthored](db), 12)
+	assertCount(t, ctx, gotype.MustNewManager[BibCites](db), len(bibCitations))
+	assertCount(t, ctx, gotype.MustNewManager[BibPublishedIn](db), 7)
+
+	firsts, err := gotype.MustNewManager[BibAuthored](db).Query().
+		Filter(
+			gotype.RolePlayer("author", gotype.Eq("author-name", "ada")),
+			gotype.Eq("author-position", 1),
+		).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("count first-author papers: %v", err)
+	}
+	if firsts != 3 {
+		t.Errorf("ada is first author on %d papers, want 3", firsts)
+	}
+}
+
+func TestIntegration_Bibliography_CitationCounts(t *testing.T) {
+	db := seedBibliography(t)
+	ctx := context.Background()
+
+	counts := storeCitationCounts(t, ctx, db)
+	want := map[string]int64{"P1": 5, "P2": 2, "P3": 2, "P4": 2, "P5": 1, "P6": 0, "P7": 0}
+	if !maps.Equal(counts, want) {
+		t.Fatalf("citation counts = %v, want %v", counts, want)
+	}
+
+	paperMgr := gotype.MustNewManager[BibPaper](db)
+	total, err := paperMgr.Query().Sum("citation-count").Execute(ctx)
+	if err != nil {
+		t.Fatalf("sum citations: %v", err)
+	}
+	if total != float64(len(bibCitations)) {
+		t.Errorf("total citations = %v, want %d", total, len(bibCitations))
+	}
+
+	// Most cited first, ties broken by title.
+	top, err := paperMgr.Query().
+		Filter(gotype.Gt("citation-count", 0)).
+		OrderDesc("citation-count").
+		OrderAsc("paper-title").
+		Limit(3).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("most cited: %v", err)
+	}
+	var titles []string
+	for _, p := range top {
+		titles = append(titles, p.Title)
+	}
+	if !slices.Equal(titles, []string{"P1", "P2", "P3"}) {
+		t.Errorf("most cited = %v, want [P1 P2 P3]", titles)
+	}
+
+	// Citations received per publication year, and per citation context.
+	byYear, err := paperMgr.Query().
+		Filter(gotype.Range("paper-year", 2015, 2018)).
+		GroupBy("paper-year").
+		Aggregate(ctx, gotype.AggregateSpec{Attr: "citation-count", Fn: "sum"})
+	if err != nil {
+		t.Fatalf("citations by year: %v", err)
+	}
+	for year, n := range map[string]float64{"2015": 5, "2016": 2, "2017": 2, "2018": 2} {
+		if byYear[year]["sum_citation-count"] != n {
+			t.Errorf("%s: %v citations, want %v", year, byYear[year]["sum_citation-count"], n)
+		}
+	}
+	byContext, err := gotype.MustNewManager[BibCites](db).Query().
+		GroupBy("citation-context").
+		Aggregate(ctx, gotype.AggregateSpec{Attr: "citation-context", Fn: "count"})
+	if err != nil {
+		t.Fatalf("citations by context: %v", err)
+	}
+	for kind, n := range map[string]float64{"background": 7, "method": 3, "comparison": 2} {
+		if byContext[kind]["count_citation-context"] != n {
+			t.Errorf("%s citations = %v, want %v", kind, byContext[kind]["count_citation-context"], n)
+		}
+	}
+
+	// Citations per venue through the published-in relation.
+	perVenue := countBy(t, ctx, db, "venue", `match
+$c isa cites, links (cited: $p);
+$pub isa published-in, links (publication: $p, venue: $v);
+fetch { "venue": $v.venue-name };`)
+	if !maps.Equal(perVenue, map[string]int{"ICML": 7, "NeurIPS": 3, "JMLR": 2}) {
+		t.Errorf("citations per venue = %v", perVenue)
+	}
+}
+
+func TestIntegration_Bibliography_HIndex(t *testing.T) {
+	db := seedBibliography(t)
+	ctx := context.Background()
+	storeCitationCounts(t, ctx, db)
+
+	hIndex := func(name string) int {
+		rows, err := db.ExecuteRead(ctx, fmt.Sprintf(`match
+$a isa author, has author-name %q;
+$w isa authored, links (author: $a, paper: $p);
+$p has citation-count $n;
+sort $n desc;
+fetch { "n": $n };`, name))
+		if err != nil {
+			t.Fatalf("citations of %s: %v", name, err)
+		}
+		h := 0
+		for i, row := range rows {
+			if int(fetchedNumber(t, row["n"])) >= i+1 {
+				h = i + 1
+			}
+		}
+		return h
+	}
+	for name, want := range map[string]int{"ada": 2, "ben": 2, "cy": 2, "dee": 1, "eli": 0} {
+		if got := hIndex(name); got != want {
+			t.Errorf("h-index of %s = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestIntegration_Bibliography_CoAuthorship(t *testing.T) {
+	db := seedBibliography(t)
+	ctx := context.Background()
+
+	if got := coauthors(t, ctx, db, "ada"); !slices.Equal(got, []string{"ben", "dee"}) {
+		t.Errorf("co-authors of ada = %v, want [ben dee]", got)
+	}
+	if got := coauthors(t, ctx, db, "eli"); len(got) != 0 {
+		t.Errorf("eli writes alone, got co-authors %v", got)
+	}
+
+	// Collaboration distance by breadth-first search over co-authorship.
+	distance := map[string]int{"ada": 0}
+	frontier := []string{"ada"}
+	for d := 1; len(frontier) > 0; d++ {
+		var next []string
+		for _, name := range frontier {
+			for _, co := range coauthors(t, ctx, db, name) {
+				if _, seen := distance[co]; !seen {
+					distance[co] = d
+					next = append(next, co)
+				}
+			}
+		}
+		frontier = next
+	}
+	want := map[string]int{"ada": 0, "ben": 1, "dee": 1, "cy": 2}
+	if !maps.Equal(distance, want) {
+		t.Errorf("collaboration distance from ada = %v, want %v", distance, want)
+	}
+
+	// Pairs who wrote more than one paper together.
+	rows, err := db.ExecuteRead(ctx, `match
+$w1 isa authored, links (author: $a, paper: $p);
+$w2 isa authored, links (author: $b, paper: $p);
+$a has author-name $na;
+$b has author-name $nb;
+$na < $nb;
+fetch { "a": $na, "b": $nb };`)
+	if err != nil {
+		t.Fatalf("co-author pairs: %v", err)
+	}
+	pairs := make(map[string]int)
+	for _, row := range rows {
+		pairs[fmt.Sprint(row["a"])+"+"+fmt.Sprint(row["b"])]++
+	}
+	want = map[string]int{"ada+ben": 2, "ben+cy": 1, "cy+dee": 1, "ada+dee": 1}
+	if !maps.Equal(pairs, want) {
+		t.Errorf("co-author pairs = %v, want %v", pairs, want)
+	}
+}
+
+func TestIntegration_Bibliography_SelfCitationExclusion(t *testing.T) {
+	db := seedBibliography(t)
+	ctx := context.Background()
+
+	// A citation is a self-citation when the two papers share an author.
+	external := countBy(t, ctx, db, "cited", `match
+$c isa cites, links (citing: $from, cited: $to);
+not {
+  $w1 isa authored, links (author: $x, paper: $from);
+  $w2 isa authored, links (author: $x, paper: $to);
+};
+fetch { "cited": $to.paper-title };`)
+	if !maps.Equal(external, map[string]int{"P1": 1, "P2": 1, "P4": 1}) {
+		t.Errorf("external citations = %v, want P1, P2 and P4 once each", external)
+	}
+
+	self := countBy(t, ctx, db, "cited", `match
+$c isa cites, links (citing: $from, cited: $to);
+$w1 isa authored, links (author: $x, paper: $from);
+$w2 isa authored, links (author: $x, paper: $to);
+fetch { "cited": $to.paper-title };`)
+	// P7 -> P1 shares two authors, so it appears twice.
+	if !maps.Equal(self, map[string]int{"P1": 5, "P2": 1, "P3": 2, "P4": 1, "P5": 1}) {
+		t.Errorf("self-citation rows = %v", self)
+	}
+
+	// Papers nobody outside the author list has cited.
+	ignored := fetchColumn(t, ctx, db, "title", `match
+$p isa paper;
+not {
+  $c isa cites, links (citing: $from, cited: $p);
+  not {
+    $w1 isa authored, links (author: $x, paper: $from);
+    $w2 isa authored, links (author: $x, paper: $p);
+  };
+};
+fetch { "title": $p.paper-title };`)
+	if !slices.Equal(ignored, []string{"P3", "P5", "P6", "P7"}) {
+		t.Errorf("papers without external citations = %v, want [P3 P5 P6 P7]", ignored)
+	}
+}
+
+func TestIntegration_Bibliography_Timelines(t *testing.T) {
+	db := seedBibliography(t)
+	ctx := context.Background()
+	paperMgr := gotype.MustNewManager[BibPaper](db)
+
+	titles := func(papers []*BibPaper) []string {
+		var out []string
+		for _, p := range papers {
+			out = append(out, p.Title)
+		}
+		return out
+	}
+
+	window, err := paperMgr.Query().
+		Filter(gotype.Range("paper-year", 2016, 2019)).
+		OrderAsc("paper-year").
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("papers 2016-2019: %v", err)
+	}
+	if got := titles(window); !slices.Equal(got, []string{"P2", "P3", "P4", "P5"}) {
+		t.Errorf("2016-2019 timeline = %v", got)
+	}
+
+	latest, err := paperMgr.Query().OrderDesc("paper-year").Limit(3).Execute(ctx)
+	if err != nil {
+		t.Fatalf("latest papers: %v", err)
+	}
+	if got := titles(latest); !slices.Equal(got, []string{"P7", "P6", "P5"}) {
+		t.Errorf("latest papers = %v", got)
+	}
+
+	// Pages of two, oldest first, cover the whole list once.
+	var paged []string
+	for offset := 0; ; offset += 2 {
+		page, err := paperMgr.Query().OrderAsc("paper-year").Offset(offset).Limit(2).Execute(ctx)
+		if err != nil {
+			t.Fatalf("page at %d: %v", offset, err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		paged = append(paged, titles(page)...)
+	}
+	if !slices.Equal(paged, []string{"P1", "P2", "P3", "P4", "P5", "P6", "P7"}) {
+		t.Errorf("paged timeline = %v", paged)
+	}
+
+	// An author's timeline in the database's own sort order.
+	rows, err := db.ExecuteRead(ctx, `match
+$a isa author, has author-name "ada";
+$w isa authored, links (author: $a, paper: $p);
+$p has paper-year $y;
+$pub isa published-in, links (publication: $p, venue: $v);
+sort $y;
+fetch { "title": $p.paper-title, "venue": $v.venue-name };`)
+	if err != nil {
+		t.Fatalf("ada's timeline: %v", err)
+	}
+	var timeline []string
+	for _, row := range rows {
+		timeline = append(timeline, fmt.Sprintf("%v@%v", row["title"], row["venue"]))
+	}
+	want := []string{"P1@ICML", "P3@NeurIPS", "P5@NeurIPS", "P7@ICML"}
+	if !slices.Equal(timeline, want) {
+		t.Errorf("ada's timeline = %v, want %v", timeline, want)
+	}
+}
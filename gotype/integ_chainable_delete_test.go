@@ -23,10 +23,13 @@ func TestIntegration_ChainableDelete_ExpressionFilter(t *testing.T) {
 	seedPersons(t, ctx, mgr)
 
 	// Delete persons with age > 30 → Charlie(35)
-	_, err := mgr.Query().Filter(gotype.Gt("age", 30)).Delete(ctx)
+	count, err := mgr.Query().Filter(gotype.Gt("age", 30)).Delete(ctx)
 	if err != nil {
 		t.Fatalf("delete: %v", err)
 	}
+	if count != 1 {
+		t.Errorf("expected delete count 1, got %d", count)
+	}
 
 	remaining, err := mgr.All(ctx)
 	if err != nil {
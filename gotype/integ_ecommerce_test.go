@@ -0,0 +1,545 @@
+//go:build integration && cgo && typedb
+
+package gotype_test
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ---------------------------------------------------------------------------
+// E-commerce domain models
+//
+// The Go names are prefixed to stay clear of the bookstore models; the
+// TypeDB names are set with WithTypeName. "contains" is a TypeQL keyword, so
+// the order/line/product relation is order-contains.
+// ---------------------------------------------------------------------------
+
+type EcomCustomer struct {
+	gotype.BaseEntity
+	Email string `typedb:"email,key"`
+	Name  string `typedb:"name"`
+	Tier  string `typedb:"tier"`
+}
+
+type EcomProduct struct {
+	gotype.BaseEntity
+	SKU      string  `typedb:"sku,key"`
+	Name     string  `typedb:"name"`
+	Category string  `typedb:"category"`
+	Price    float64 `typedb:"price"`
+	Stock    int     `typedb:"stock"`
+}
+
+type EcomOrder struct {
+	gotype.BaseEntity
+	Number   string    `typedb:"order-number,key"`
+	Status   string    `typedb:"status"`
+	PlacedAt time.Time `typedb:"placed-at"`
+}
+
+// EcomOrderLine keeps the SKU it was sold under, so sales can be grouped
+// without joining back to the product.
+type EcomOrderLine struct {
+	gotype.BaseEntity
+	LineID    string  `typedb:"line-id,key"`
+	SKU       string  `typedb:"sku"`
+	Quantity  int     `typedb:"quantity"`
+	LineTotal float64 `typedb:"line-total"`
+}
+
+type EcomReview struct {
+	gotype.BaseEntity
+	ReviewID string `typedb:"review-id,key"`
+	Rating   int    `typedb:"rating"`
+	Body     string `typedb:"body"`
+}
+
+type EcomPlaced struct {
+	gotype.BaseRelation
+	Buyer *EcomCustomer `typedb:"role:buyer"`
+	Order *EcomOrder    `typedb:"role:placed-order"`
+}
+
+type EcomContains struct {
+	gotype.BaseRelation
+	Container *EcomOrder     `typedb:"role:container"`
+	Line      *EcomOrderLine `typedb:"role:line"`
+	Item      *EcomProduct   `typedb:"role:item"`
+}
+
+type EcomReviewed struct {
+	gotype.BaseRelation
+	Author  *EcomCustomer `typedb:"role:author"`
+	Review  *EcomReview   `typedb:"role:review"`
+	Subject *EcomProduct  `typedb:"role:subject"`
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+func setupEcommerceDB(t *testing.T) *gotype.Database {
+	return setupTestDBWith(t, func() {
+		_ = gotype.Register[EcomCustomer](gotype.WithTypeName("customer"))
+		_ = gotype.Register[EcomProduct](gotype.WithTypeName("product"))
+		_ = gotype.Register[EcomOrder](gotype.WithTypeName("purchase-order"))
+		_ = gotype.Register[EcomOrderLine](gotype.WithTypeName("order-line"))
+		_ = gotype.Register[EcomReview](gotype.WithTypeName("product-review"))
+		_ = gotype.Register[EcomPlaced](gotype.WithTypeName("placed"))
+		_ = gotype.Register[EcomContains](gotype.WithTypeName("order-contains"))
+		_ = gotype.Register[EcomReviewed](gotype.WithTypeName("reviewed"))
+	})
+}
+
+type ecommerceFixture struct {
+	db        *gotype.Database
+	customers map[string]*EcomCustomer // by email
+	products  map[string]*EcomProduct  // by SKU
+	orders    map[string]*EcomOrder    // by order number
+}
+
+type ecomLine struct {
+	sku string
+	qty int
+}
+
+func seedEcommerce(t *testing.T) ecommerceFixture {
+	t.Helper()
+	db := setupEcommerceDB(t)
+	ctx := context.Background()
+	f := ecommerceFixture{
+		db:        db,
+		customers: make(map[string]*EcomCustomer),
+		products:  make(map[string]*EcomProduct),
+		orders:    make(map[string]*EcomOrder),
+	}
+
+	customerMgr := gotype.MustNewManager[EcomCustomer](db)
+	productMgr := gotype.MustNewManager[EcomProduct](db)
+	orderMgr := gotype.MustNewManager[EcomOrder](db)
+	lineMgr := gotype.MustNewManager[EcomOrderLine](db)
+	reviewMgr := gotype.MustNewManager[EcomReview](db)
+	placedMgr := gotype.MustNewManager[EcomPlaced](db)
+	containsMgr := gotype.MustNewManager[EcomContains](db)
+	reviewedMgr := gotype.MustNewManager[EcomReviewed](db)
+
+	// Customers. Dan has no orders.
+	customers := []*EcomCustomer{
+		{Email: "ann@shop.test", Name: "Ann", Tier: "gold"},
+		{Email: "ben@shop.test", Name: "Ben", Tier: "silver"},
+		{Email: "cat@shop.test", Name: "Cat", Tier: "silver"},
+		{Email: "dan@shop.test", Name: "Dan", Tier: "bronze"},
+	}
+	assertInsertMany(t, ctx, customerMgr, customers)
+	for _, c := range customers {
+		f.customers[c.Email] = assertGetOne(t, ctx, customerMgr, map[string]any{"email": c.Email})
+	}
+
+	// Products. Nobody has bought the desk.
+	products := []*EcomProduct{
+		{SKU: "KB-01", Name: "Keyboard", Category: "peripherals", Price: 49.99, Stock: 100},
+		{SKU: "MS-01", Name: "Mouse", Category: "peripherals", Price: 19.99, Stock: 200},
+		{SKU: "MN-01", Name: "Monitor", Category: "displays", Price: 199.99, Stock: 30},
+		{SKU: "CB-01", Name: "Cable", Category: "accessories", Price: 9.99, Stock: 500},
+		{SKU: "DK-01", Name: "Desk", Category: "furniture", Price: 299.00, Stock: 10},
+	}
+	assertInsertMany(t, ctx, productMgr, products)
+	for _, p := range products {
+		f.products[p.SKU] = assertGetOne(t, ctx, productMgr, map[string]any{"sku": p.SKU})
+	}
+
+	// Orders:
+	//   ORD-1 Ann  KB-01 x1, MS-01 x2
+	//   ORD-2 Ann  MN-01 x2
+	//   ORD-3 Ben  KB-01 x1, CB-01 x5
+	//   ORD-4 Cat  KB-01 x2, MS-01 x1, CB-01 x1
+	orders := []struct {
+		number, buyer, status string
+		placed                time.Time
+		lines                 []ecomLine
+	}{
+		{"ORD-1", "ann@shop.test", "delivered", time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC), []ecomLine{{"KB-01", 1}, {"MS-01", 2}}},
+		{"ORD-2", "ann@shop.test", "shipped", time.Date(2026, 2, 10, 9, 30, 0, 0, time.UTC), []ecomLine{{"MN-01", 2}}},
+		{"ORD-3", "ben@shop.test", "delivered", time.Date(2026, 1, 20, 16, 15, 0, 0, time.UTC), []ecomLine{{"KB-01", 1}, {"CB-01", 5}}},
+		{"ORD-4", "cat@shop.test", "pending", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), []ecomLine{{"KB-01", 2}, {"MS-01", 1}, {"CB-01", 1}}},
+	}
+	for _, o := range orders {
+		order := &EcomOrder{Number: o.number, Status: o.status, PlacedAt: o.placed}
+		assertInsert(t, ctx, orderMgr, order)
+		order = assertGetOne(t, ctx, orderMgr, map[string]any{"order-number": o.number})
+		f.orders[o.number] = order
+		assertInsert(t, ctx, placedMgr, &EcomPlaced{Buyer: f.customers[o.buyer], Order: order})
+
+		for i, l := range o.lines {
+			product := f.products[l.sku]
+			line := &EcomOrderLine{
+				LineID:    fmt.Sprintf("%s-%d", o.number, i+1),
+				SKU:       l.sku,
+				Quantity:  l.qty,
+				LineTotal: product.Price * float64(l.qty),
+			}
+			assertInsert(t, ctx, lineMgr, line)
+			line = assertGetOne(t, ctx, lineMgr, map[string]any{"line-id": line.LineID})
+			assertInsert(t, ctx, containsMgr, &EcomContains{Container: order, Line: line, Item: product})
+		}
+	}
+
+	// Reviews.
+	reviews := []struct {
+		id, author, sku string
+		rating          int
+	}{
+		{"REV-1", "ann@shop.test", "KB-01", 5},
+		{"REV-2", "ben@shop.test", "KB-01", 4},
+		{"REV-3", "cat@shop.test", "CB-01", 3},
+		{"REV-4", "ann@shop.test", "MN-01", 4},
+	}
+	for _, r := range reviews {
+		review := &EcomReview{ReviewID: r.id, Rating: r.rating, Body: "Review " + r.id}
+		assertInsert(t, ctx, reviewMgr, review)
+		review = assertGetOne(t, ctx, reviewMgr, map[string]any{"review-id": r.id})
+		assertInsert(t, ctx, reviewedMgr, &EcomReviewed{
+			Author:  f.customers[r.author],
+			Review:  review,
+			Subject: f.products[r.sku],
+		})
+	}
+
+	return f
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestIntegration_Ecommerce_AllInserted(t *testing.T) {
+	f := seedEcommerce(t)
+	ctx := context.Background()
+
+	assertCount(t, ctx, gotype.MustNewManager[EcomCustomer](f.db), 4)
+	assertCount(t, ctx, gotype.MustNewManager[EcomProduct](f.db), 5)
+	assertCount(t, ctx, gotype.MustNewManager[EcomOrder](f.db), 4)
+	assertCount(t, ctx, gotype.MustNewManager[EcomOrderLine](f.db), 8)
+	assertCount(t, ctx, gotype.MustNewManager[EcomReview](f.db), 4)
+	assertCount(t, ctx, gotype.MustNewManager[EcomPlaced](f.db), 4)
+	assertCount(t, ctx, gotype.MustNewManager[EcomContains](f.db), 8)
+	assertCount(t, ctx, gotype.MustNewManager[EcomReviewed](f.db), 4)
+}
+
+func TestIntegration_Ecommerce_OrdersPlacedByCustomer(t *testing.T) {
+	f := seedEcommerce(t)
+	ctx := context.Background()
+	mgr := gotype.MustNewManager[EcomPlaced](f.db)
+
+	for email, want := range map[string]int64{
+		"ann@shop.test": 2,
+		"ben@shop.test": 1,
+		"dan@shop.test": 0,
+	} {
+		n, err := mgr.Query().
+			Filter(gotype.RolePlayer("buyer", gotype.Eq("email", email))).
+			Count(ctx)
+		if err != nil {
+			t.Fatalf("count orders of %s: %v", email, err)
+		}
+		if n != want {
+			t.Errorf("%s placed %d orders, want %d", email, n, want)
+		}
+	}
+}
+
+func TestIntegration_Ecommerce_OrderHistory(t *testing.T) {
+	f := seedEcommerce(t)
+	ctx := context.Background()
+
+	// Customer -> placed -> order -> order-contains -> line and product.
+	rows, err := f.db.ExecuteRead(ctx, `match
+$c isa customer, has email "ann@shop.test";
+$pl isa placed, links (buyer: $c, placed-order: $o);
+$oc isa order-contains, links (container: $o, line: $l, item: $p);
+fetch {
+  "order": $o.order-number,
+  "placed": $o.placed-at,
+  "product": $p.name,
+  "quantity": $l.quantity,
+  "total": $l.line-total
+};`)
+	if err != nil {
+		t.Fatalf("order history: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 order lines for Ann, got %d: %v", len(rows), rows)
+	}
+
+	totals := make(map[string]float64)
+	products := make(map[string][]string)
+	for _, row := range rows {
+		order := fmt.Sprint(row["order"])
+		totals[order] += fetchedNumber(t, row["total"])
+		products[order] = append(products[order], fmt.Sprint(row["product"]))
+	}
+	if len(totals) != 2 {
+		t.Fatalf("expected 2 orders for Ann, got %v", slices.Sorted(maps.Keys(totals)))
+	}
+	// ORD-1: 49.99 + 2*19.99; ORD-2: 2*199.99
+	if math.Abs(totals["ORD-1"]-89.97) > 0.01 {
+		t.Errorf("ORD-1 total = %.2f, want 89.97", totals["ORD-1"])
+	}
+	if math.Abs(totals["ORD-2"]-399.98) > 0.01 {
+		t.Errorf("ORD-2 total = %.2f, want 399.98", totals["ORD-2"])
+	}
+	slices.Sort(products["ORD-1"])
+	if !slices.Equal(products["ORD-1"], []string{"Keyboard", "Mouse"}) {
+		t.Errorf("ORD-1 products = %v, want [Keyboard Mouse]", products["ORD-1"])
+	}
+
+	// Most recent order first, through the ORM.
+	latest, err := gotype.MustNewManager[EcomOrder](f.db).Query().
+		Filter(gotype.In("order-number", []any{"ORD-1", "ORD-2"})).
+		OrderDesc("placed-at").
+		First(ctx)
+	if err != nil {
+		t.Fatalf("latest order: %v", err)
+	}
+	if latest == nil || latest.Number != "ORD-2" {
+		t.Errorf("expected latest order ORD-2, got %+v", latest)
+	}
+}
+
+func TestIntegration_Ecommerce_TopSellingProducts(t *testing.T) {
+	f := seedEcommerce(t)
+	ctx := context.Background()
+	mgr := gotype.MustNewManager[EcomOrderLine](f.db)
+
+	sales, err := mgr.Query().GroupBy("sku").Aggregate(ctx,
+		gotype.AggregateSpec{Attr: "quantity", Fn: "sum"},
+		gotype.AggregateSpec{Attr: "line-total", Fn: "sum"},
+	)
+	if err != nil {
+		t.Fatalf("group by sku: %v", err)
+	}
+	if len(sales) != 4 {
+		t.Fatalf("expected sales for 4 products, got %v", sales)
+	}
+
+	bySKU := func(key string) []string {
+		skus := slices.Collect(maps.Keys(sales))
+		slices.SortFunc(skus, func(a, b string) int {
+			return cmp.Compare(sales[b][key], sales[a][key])
+		})
+		return skus
+	}
+	// Units: CB-01 6, KB-01 4, MS-01 3, MN-01 2.
+	if got := bySKU("sum_quantity"); !slices.Equal(got, []string{"CB-01", "KB-01", "MS-01", "MN-01"}) {
+		t.Errorf("by units sold = %v", got)
+	}
+	// Revenue: MN-01 399.98, KB-01 199.96, MS-01 59.97, CB-01 59.94.
+	if got := bySKU("sum_line-total"); !slices.Equal(got, []string{"MN-01", "KB-01", "MS-01", "CB-01"}) {
+		t.Errorf("by revenue = %v", got)
+	}
+
+	// Filters apply before grouping: count the lines of more than one unit.
+	bulk, err := mgr.Query().Filter(gotype.Gt("quantity", 1)).GroupBy("sku").Aggregate(ctx,
+		gotype.AggregateSpec{Attr: "quantity", Fn: "count"},
+	)
+	if err != nil {
+		t.Fatalf("group bulk lines: %v", err)
+	}
+	if got := slices.Sorted(maps.Keys(bulk)); !slices.Equal(got, []string{"CB-01", "KB-01", "MN-01", "MS-01"}) {
+		t.Errorf("SKUs sold in bulk = %v", got)
+	}
+}
+
+func TestIntegration_Ecommerce_AlsoBought(t *testing.T) {
+	f := seedEcommerce(t)
+	ctx := context.Background()
+
+	// Customers who bought KB-01, and every product they bought.
+	rows, err := f.db.ExecuteRead(ctx, `match
+$target isa product, has sku "KB-01";
+$c isa customer;
+$p1 isa placed, links (buyer: $c, placed-order: $o1);
+$oc1 isa order-contains, links (container: $o1, item: $target);
+$p2 isa placed, links (buyer: $c, placed-order: $o2);
+$oc2 isa order-contains, links (container: $o2, item: $other);
+fetch { "customer": $c.email, "sku": $other.sku };`)
+	if err != nil {
+		t.Fatalf("also bought: %v", err)
+	}
+
+	buyers := make(map[string]map[string]bool) // sku -> customers
+	for _, row := range rows {
+		sku := fmt.Sprint(row["sku"])
+		if sku == "KB-01" {
+			continue
+		}
+		if buyers[sku] == nil {
+			buyers[sku] = make(map[string]bool)
+		}
+		buyers[sku][fmt.Sprint(row["customer"])] = true
+	}
+	skus := slices.Collect(maps.Keys(buyers))
+	slices.SortFunc(skus, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(buyers[b]), len(buyers[a])), cmp.Compare(a, b))
+	})
+	// Ann: MS-01, MN-01. Ben: CB-01. Cat: MS-01, CB-01.
+	if !slices.Equal(skus, []string{"CB-01", "MS-01", "MN-01"}) {
+		t.Errorf("also bought = %v, want [CB-01 MS-01 MN-01]", skus)
+	}
+	if len(buyers["MS-01"]) != 2 || !buyers["MS-01"]["cat@shop.test"] {
+		t.Errorf("MS-01 co-buyers = %v, want Ann and Cat", buyers["MS-01"])
+	}
+	if buyers["DK-01"] != nil {
+		t.Error("DK-01 was never bought")
+	}
+}
+
+func TestIntegration_Ecommerce_ProductRatings(t *testing.T) {
+	f := seedEcommerce(t)
+	ctx := context.Background()
+
+	rows, err := f.db.ExecuteRead(ctx, `match
+$rv isa reviewed, links (review: $r, subject: $p);
+fetch { "sku": $p.sku, "rating": $r.rating };`)
+	if err != nil {
+		t.Fatalf("ratings: %v", err)
+	}
+	sum := make(map[string]float64)
+	count := make(map[string]int)
+	for _, row := range rows {
+		sku := fmt.Sprint(row["sku"])
+		sum[sku] += fetchedNumber(t, row["rating"])
+		count[sku]++
+	}
+	if count["KB-01"] != 2 || sum["KB-01"]/2 != 4.5 {
+		t.Errorf("KB-01: %d reviews averaging %.2f, want 2 averaging 4.50", count["KB-01"], sum["KB-01"]/float64(count["KB-01"]))
+	}
+
+	// Every review in the fixture is by a customer who bought the product.
+	unverified, err := f.db.ExecuteRead(ctx, `match
+$rv isa reviewed, links (author: $c, review: $r, subject: $p);
+not {
+  $pl isa placed, links (buyer: $c, placed-order: $o);
+  $oc isa order-contains, links (container: $o, item: $p);
+};
+fetch { "review": $r.review-id };`)
+	if err != nil {
+		t.Fatalf("unverified reviews: %v", err)
+	}
+	if len(unverified) != 0 {
+		t.Errorf("expected no unverified reviews, got %v", unverified)
+	}
+}
+
+// placeOrder decrements stock and records an order for one customer and
+// product inside tc. It returns an error, leaving the caller to decide
+// whether to commit, when stock is short.
+func placeOrder(ctx context.Context, tc *gotype.TransactionContext, customer *EcomCustomer, sku, number string, qty int) error {
+	products := gotype.MustNewManagerWithTx[EcomProduct](tc)
+	orders := gotype.MustNewManagerWithTx[EcomOrder](tc)
+	lines := gotype.MustNewManagerWithTx[EcomOrderLine](tc)
+
+	product, err := products.Query().Filter(gotype.Eq("sku", sku)).First(ctx)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return fmt.Errorf("no product %s", sku)
+	}
+	if product.Stock < qty {
+		return fmt.Errorf("%s: %d in stock, %d ordered", sku, product.Stock, qty)
+	}
+	product.Stock -= qty
+	if err := products.Update(ctx, product); err != nil {
+		return err
+	}
+
+	order := &EcomOrder{Number: number, Status: "pending", PlacedAt: time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)}
+	if err := orders.Insert(ctx, order); err != nil {
+		return err
+	}
+	line := &EcomOrderLine{LineID: number + "-1", SKU: sku, Quantity: qty, LineTotal: product.Price * float64(qty)}
+	if err := lines.Insert(ctx, line); err != nil {
+		return err
+	}
+	if err := gotype.MustNewManagerWithTx[EcomPlaced](tc).Insert(ctx, &EcomPlaced{Buyer: customer, Order: order}); err != nil {
+		return err
+	}
+	return gotype.MustNewManagerWithTx[EcomContains](tc).Insert(ctx, &EcomContains{Container: order, Line: line, Item: product})
+}
+
+func TestIntegration_Ecommerce_InventoryCommit(t *testing.T) {
+	f := seedEcommerce(t)
+	ctx := context.Background()
+
+	tc, err := f.db.Begin(gotype.WriteTransaction)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tc.Close()
+	if err := placeOrder(ctx, tc, f.customers["dan@shop.test"], "MN-01", "ORD-5", 3); err != nil {
+		t.Fatalf("place order: %v", err)
+	}
+	if err := tc.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	monitor := assertGetOne(t, ctx, gotype.MustNewManager[EcomProduct](f.db), map[string]any{"sku": "MN-01"})
+	if monitor.Stock != 27 {
+		t.Errorf("MN-01 stock = %d, want 27", monitor.Stock)
+	}
+	n, err := gotype.MustNewManager[EcomPlaced](f.db).Query().
+		Filter(gotype.RolePlayer("buyer", gotype.Eq("email", "dan@shop.test"))).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("count Dan's orders: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Dan has %d orders after commit, want 1", n)
+	}
+}
+
+func TestIntegration_Ecommerce_InventoryRollback(t *testing.T) {
+	f := seedEcommerce(t)
+	ctx := context.Background()
+
+	tc, err := f.db.Begin(gotype.WriteTransaction)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tc.Close()
+	// The first order fits; the second exceeds what is left, so the whole
+	// transaction is abandoned.
+	if err := placeOrder(ctx, tc, f.customers["dan@shop.test"], "DK-01", "ORD-5", 6); err != nil {
+		t.Fatalf("first order: %v", err)
+	}
+	if err := placeOrder(ctx, tc, f.customers["ben@shop.test"], "DK-01", "ORD-6", 6); err == nil {
+		t.Fatal("expected the second order to fail on stock")
+	}
+	if err := tc.Rollback(); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+
+	desk := assertGetOne(t, ctx, gotype.MustNewManager[EcomProduct](f.db), map[string]any{"sku": "DK-01"})
+	if desk.Stock != 10 {
+		t.Errorf("DK-01 stock = %d after rollback, want 10", desk.Stock)
+	}
+	exists, err := gotype.MustNewManager[EcomOrder](f.db).Query().
+		Filter(gotype.Eq("order-number", "ORD-5")).
+		Exists(ctx)
+	if err != nil {
+		t.Fatalf("exists: %v", err)
+	}
+	if exists {
+		t.Error("ORD-5 should not exist after rollback")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/CaliLuke/go-typeql/gotype"
@@ -123,3 +124,48 @@ func insertAndGet[T any](t *testing.T, ctx context.Context, mgr *gotype.Manager[
 	assertInsert(t, ctx, mgr, instance)
 	return assertGetOne(t, ctx, mgr, map[string]any{key: value})
 }
+
+// fetchedNumber converts a numeric value from a raw fetch result to float64.
+func fetchedNumber(t *testing.T, v any) float64 {
+	t.Helper()
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	case int:
+		return float64(n)
+	}
+	t.Fatalf("expected a number, got %T %v", v, v)
+	return 0
+}
+
+// fetchColumn runs a read query and returns the sorted, distinct values of
+// one fetched key.
+func fetchColumn(t *testing.T, ctx context.Context, db *gotype.Database, key, query string) []string {
+	t.Helper()
+	rows, err := db.ExecuteRead(ctx, query)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, query)
+	}
+	var out []string
+	for _, row := range rows {
+		out = append(out, fmt.Sprint(row[key]))
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// countBy runs a read query and counts the rows per value of one fetched key.
+func countBy(t *testing.T, ctx context.Context, db *gotype.Database, key, query string) map[string]int {
+	t.Helper()
+	rows, err := db.ExecuteRead(ctx, query)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, query)
+	}
+	counts := make(map[string]int)
+	for _, row := range rows {
+		counts[fmt.Sprint(row[key])]++
+	}
+	return counts
+}
@@ -0,0 +1,491 @@
+//go:build integration && cgo && typedb
+
+package gotype_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ---------------------------------------------------------------------------
+// Fraud detection domain models
+// ---------------------------------------------------------------------------
+
+type FraudAccount struct {
+	gotype.BaseEntity
+	AccountID string `typedb:"account-id,key"`
+	Holder    string `typedb:"holder"`
+	Flagged   bool   `typedb:"flagged"`
+}
+
+type FraudDevice struct {
+	gotype.BaseEntity
+	DeviceID string `typedb:"device-id,key"`
+	OS       string `typedb:"os"`
+}
+
+type FraudMerchant struct {
+	gotype.BaseEntity
+	MerchantID string `typedb:"merchant-id,key"`
+	Name       string `typedb:"merchant-name"`
+}
+
+// FraudTxn is one movement of money, recorded by a transfers or pays
+// relation.
+type FraudTxn struct {
+	gotype.BaseEntity
+	TxnID      string    `typedb:"txn-id,key"`
+	Amount     float64   `typedb:"amount"`
+	OccurredAt time.Time `typedb:"occurred-at"`
+	Channel    string    `typedb:"channel"`
+}
+
+type FraudTransfers struct {
+	gotype.BaseRelation
+	Sender   *FraudAccount `typedb:"role:sender"`
+	Receiver *FraudAccount `typedb:"role:receiver"`
+	Record   *FraudTxn     `typedb:"role:record"`
+}
+
+type FraudUsesDevice struct {
+	gotype.BaseRelation
+	User   *FraudAccount `typedb:"role:user"`
+	Device *FraudDevice  `typedb:"role:device"`
+}
+
+type FraudPays struct {
+	gotype.BaseRelation
+	Payer   *FraudAccount  `typedb:"role:payer"`
+	Payee   *FraudMerchant `typedb:"role:payee"`
+	Payment *FraudTxn      `typedb:"role:payment"`
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+func setupFraudDB(t *testing.T) *gotype.Database {
+	return setupTestDBWith(t, func() {
+		_ = gotype.Register[FraudAccount](gotype.WithTypeName("account"))
+		_ = gotype.Register[FraudDevice](gotype.WithTypeName("device"))
+		_ = gotype.Register[FraudMerchant](gotype.WithTypeName("merchant"))
+		_ = gotype.Register[FraudTxn](gotype.WithTypeName("financial-transaction"))
+		_ = gotype.Register[FraudTransfers](gotype.WithTypeName("transfers"))
+		_ = gotype.Register[FraudUsesDevice](gotype.WithTypeName("uses-device"))
+		_ = gotype.Register[FraudPays](gotype.WithTypeName("pays"))
+	})
+}
+
+// fraudDay is the day the velocity tests look at.
+var fraudDay = time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+func fraudAt(day, hour, minute int) time.Time {
+	return time.Date(2026, time.Month(day/100), day%100, hour, minute, 0, 0, time.UTC)
+}
+
+type fraudFixture struct {
+	db       *gotype.Database
+	accounts map[string]*FraudAccount
+}
+
+// seedFraud loads eight accounts:
+//
+//	devices:   D1 A1,A2   D2 A2,A3   D3 A4   D4 A5   D5 A6,A7
+//	transfers: A1→A2→A3→A4→A7 (the mule chain), A1→A5→A8, A1→A6, A1→A8
+//	payments:  A4 → M1, A8 → M2
+//
+// A3 starts out flagged.
+func seedFraud(t *testing.T) fraudFixture {
+	t.Helper()
+	db := setupFraudDB(t)
+	ctx := context.Background()
+	f := fraudFixture{db: db, accounts: make(map[string]*FraudAccount)}
+
+	accountMgr := gotype.MustNewManager[FraudAccount](db)
+	deviceMgr := gotype.MustNewManager[FraudDevice](db)
+	merchantMgr := gotype.MustNewManager[FraudMerchant](db)
+	txnMgr := gotype.MustNewManager[FraudTxn](db)
+	transfersMgr := gotype.MustNewManager[FraudTransfers](db)
+	usesMgr := gotype.MustNewManager[FraudUsesDevice](db)
+	paysMgr := gotype.MustNewManager[FraudPays](db)
+
+	var accounts []*FraudAccount
+	for i := 1; i <= 8; i++ {
+		accounts = append(accounts, &FraudAccount{
+			AccountID: fmt.Sprintf("A%d", i),
+			Holder:    fmt.Sprintf("Holder %d", i),
+			Flagged:   i == 3,
+		})
+	}
+	assertInsertMany(t, ctx, accountMgr, accounts)
+	for _, a := range accounts {
+		f.accounts[a.AccountID] = assertGetOne(t, ctx, accountMgr, map[string]any{"account-id": a.AccountID})
+	}
+
+	devices := map[string][]string{
+		"D1": {"A1", "A2"},
+		"D2": {"A2", "A3"},
+		"D3": {"A4"},
+		"D4": {"A5"},
+		"D5": {"A6", "A7"},
+	}
+	for _, id := range slices.Sorted(maps.Keys(devices)) {
+		assertInsert(t, ctx, deviceMgr, &FraudDevice{DeviceID: id, OS: "android"})
+		device := assertGetOne(t, ctx, deviceMgr, map[string]any{"device-id": id})
+		for _, acc := range devices[id] {
+			assertInsert(t, ctx, usesMgr, &FraudUsesDevice{User: f.accounts[acc], Device: device})
+		}
+	}
+
+	merchants := map[string]*FraudMerchant{}
+	for _, m := range []*FraudMerchant{
+		{MerchantID: "M1", Name: "LuxWatch"},
+		{MerchantID: "M2", Name: "Corner Grocer"},
+	} {
+		assertInsert(t, ctx, merchantMgr, m)
+		merchants[m.MerchantID] = assertGetOne(t, ctx, merchantMgr, map[string]any{"merchant-id": m.MerchantID})
+	}
+
+	newTxn := func(id string, amount float64, at time.Time, channel string) *FraudTxn {
+		assertInsert(t, ctx, txnMgr, &FraudTxn{TxnID: id, Amount: amount, OccurredAt: at, Channel: channel})
+		return assertGetOne(t, ctx, txnMgr, map[string]any{"txn-id": id})
+	}
+
+	transfers := []struct {
+		id, from, to string
+		amount       float64
+		at           time.Time
+		channel      string
+	}{
+		{"T1", "A1", "A2", 900, fraudAt(501, 10, 0), "online"},
+		{"T2", "A2", "A3", 850, fraudAt(501, 10, 5), "online"},
+		{"T3", "A3", "A4", 800, fraudAt(501, 10, 10), "online"},
+		{"T4", "A1", "A5", 50, fraudAt(501, 12, 0), "mobile"},
+		{"T5", "A1", "A6", 30, fraudAt(501, 12, 30), "mobile"},
+		{"T6", "A5", "A8", 20, fraudAt(420, 9, 0), "branch"},
+		{"T7", "A1", "A8", 10, fraudAt(401, 9, 0), "branch"},
+		{"T8", "A4", "A7", 100, fraudAt(502, 8, 0), "online"},
+	}
+	for _, tr := range transfers {
+		assertInsert(t, ctx, transfersMgr, &FraudTransfers{
+			Sender:   f.accounts[tr.from],
+			Receiver: f.accounts[tr.to],
+			Record:   newTxn(tr.id, tr.amount, tr.at, tr.channel),
+		})
+	}
+
+	payments := []struct {
+		id, from, to string
+		amount       float64
+		at           time.Time
+		channel      string
+	}{
+		{"P1", "A4", "M1", 780, fraudAt(501, 11, 0), "online"},
+		{"P2", "A8", "M2", 15, fraudAt(425, 18, 0), "mobile"},
+	}
+	for _, p := range payments {
+		assertInsert(t, ctx, paysMgr, &FraudPays{
+			Payer:   f.accounts[p.from],
+			Payee:   merchants[p.to],
+			Payment: newTxn(p.id, p.amount, p.at, p.channel),
+		})
+	}
+
+	return f
+}
+
+// fraudEdges fetches the sender → receivers map of all transfers.
+func fraudEdges(t *testing.T, ctx context.Context, db *gotype.Database) map[string][]string {
+	t.Helper()
+	rows, err := db.ExecuteRead(ctx, `match
+$tr isa transfers, links (sender: $s, receiver: $r);
+fetch { "from": $s.account-id, "to": $r.account-id };`)
+	if err != nil {
+		t.Fatalf("fetch transfers: %v", err)
+	}
+	edges := make(map[string][]string)
+	for _, row := range rows {
+		from := fmt.Sprint(row["from"])
+		edges[from] = append(edges[from], fmt.Sprint(row["to"]))
+	}
+	return edges
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestIntegration_Fraud_AllInserted(t *testing.T) {
+	f := seedFraud(t)
+	ctx := context.Background()
+
+	assertCount(t, ctx, gotype.MustNewManager[FraudAccount](f.db), 8)
+	assertCount(t, ctx, gotype.MustNewManager[FraudDevice](f.db), 5)
+	assertCount(t, ctx, gotype.MustNewManager[FraudMerchant](f.db), 2)
+	assertCount(t, ctx, gotype.MustNewManager[FraudTxn](f.db), 10)
+	assertCount(t, ctx, gotype.MustNewManager[FraudTransfers](f.db), 8)
+	assertCount(t, ctx, gotype.MustNewManager[FraudUsesDevice](f.db), 8)
+	assertCount(t, ctx, gotype.MustNewManager[FraudPays](f.db), 2)
+}
+
+func TestIntegration_Fraud_SharedDeviceRings(t *testing.T) {
+	f := seedFraud(t)
+	ctx := context.Background()
+
+	rows, err := f.db.ExecuteRead(ctx, `match
+$u1 isa uses-device, links (user: $a, device: $d);
+$u2 isa uses-device, links (user: $b, device: $d);
+fetch { "a": $a.account-id, "b": $b.account-id };`)
+	if err != nil {
+		t.Fatalf("shared devices: %v", err)
+	}
+
+	// Union accounts that share a device; each component is a ring.
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(x string) string {
+		if p, ok := parent[x]; ok && p != x {
+			parent[x] = find(p)
+			return parent[x]
+		}
+		parent[x] = x
+		return x
+	}
+	for _, row := range rows {
+		a, b := fmt.Sprint(row["a"]), fmt.Sprint(row["b"])
+		if a == b {
+			continue
+		}
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[max(ra, rb)] = min(ra, rb)
+		}
+	}
+	rings := make(map[string][]string)
+	for acc := range parent {
+		root := find(acc)
+		rings[root] = append(rings[root], acc)
+	}
+	var got [][]string
+	for _, members := range rings {
+		slices.Sort(members)
+		got = append(got, members)
+	}
+	slices.SortFunc(got, func(a, b []string) int { return slices.Compare(a, b) })
+
+	want := [][]string{{"A1", "A2", "A3"}, {"A6", "A7"}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("rings = %v, want %v", got, want)
+	}
+
+	// The ORM sees D2's two users through a role-player filter.
+	n, err := gotype.MustNewManager[FraudUsesDevice](f.db).Query().
+		Filter(gotype.RolePlayer("device", gotype.Eq("device-id", "D2"))).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("count D2 users: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("D2 has %d users, want 2", n)
+	}
+}
+
+func TestIntegration_Fraud_TransactionVelocity(t *testing.T) {
+	f := seedFraud(t)
+	ctx := context.Background()
+	dayEnd := fraudDay.Add(24 * time.Hour)
+
+	// Transfers sent by A1 on the day, via two role-player filters.
+	n, err := gotype.MustNewManager[FraudTransfers](f.db).Query().
+		Filter(
+			gotype.RolePlayer("sender", gotype.Eq("account-id", "A1")),
+			gotype.RolePlayer("record", gotype.Range("occurred-at", fraudDay, dayEnd)),
+		).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("count A1 transfers: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("A1 sent %d transfers on the day, want 3", n)
+	}
+
+	// Count and volume per sender on the day.
+	rows, err := f.db.ExecuteRead(ctx, `match
+$tr isa transfers, links (sender: $s, record: $x);
+$x has occurred-at $at;
+$at >= 2026-05-01T00:00:00;
+$at < 2026-05-02T00:00:00;
+fetch { "account": $s.account-id, "amount": $x.amount };`)
+	if err != nil {
+		t.Fatalf("velocity: %v", err)
+	}
+	count := make(map[string]int)
+	volume := make(map[string]float64)
+	for _, row := range rows {
+		acc := fmt.Sprint(row["account"])
+		count[acc]++
+		volume[acc] += fetchedNumber(t, row["amount"])
+	}
+	if count["A1"] != 3 || volume["A1"] != 980 {
+		t.Errorf("A1: %d transfers worth %.2f, want 3 worth 980", count["A1"], volume["A1"])
+	}
+	if count["A4"] != 0 || count["A5"] != 0 {
+		t.Errorf("A4 and A5 sent nothing on the day, got %d and %d", count["A4"], count["A5"])
+	}
+
+	// All money movements on the day by channel.
+	byChannel, err := gotype.MustNewManager[FraudTxn](f.db).Query().
+		Filter(gotype.Gte("occurred-at", fraudDay), gotype.Lt("occurred-at", dayEnd)).
+		GroupBy("channel").
+		Aggregate(ctx,
+			gotype.AggregateSpec{Attr: "amount", Fn: "count"},
+			gotype.AggregateSpec{Attr: "amount", Fn: "sum"},
+		)
+	if err != nil {
+		t.Fatalf("group by channel: %v", err)
+	}
+	// online: T1, T2, T3, P1. mobile: T4, T5.
+	if got := byChannel["online"]; got["count_amount"] != 4 || math.Abs(got["sum_amount"]-3330) > 0.01 {
+		t.Errorf("online = %v, want 4 worth 3330", got)
+	}
+	if got := byChannel["mobile"]; got["count_amount"] != 2 || math.Abs(got["sum_amount"]-80) > 0.01 {
+		t.Errorf("mobile = %v, want 2 worth 80", got)
+	}
+	if _, ok := byChannel["branch"]; ok {
+		t.Error("no branch transactions on the day")
+	}
+}
+
+func TestIntegration_Fraud_MultiHopMoneyFlow(t *testing.T) {
+	f := seedFraud(t)
+	ctx := context.Background()
+
+	// Three hops spelled out in one query.
+	rows, err := f.db.ExecuteRead(ctx, `match
+$src isa account, has account-id "A1";
+$t1 isa transfers, links (sender: $src, receiver: $h1);
+$t2 isa transfers, links (sender: $h1, receiver: $h2);
+$t3 isa transfers, links (sender: $h2, receiver: $h3);
+fetch { "hop1": $h1.account-id, "hop2": $h2.account-id, "hop3": $h3.account-id };`)
+	if err != nil {
+		t.Fatalf("three hops: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected one three-hop path from A1, got %v", rows)
+	}
+	path := []string{fmt.Sprint(rows[0]["hop1"]), fmt.Sprint(rows[0]["hop2"]), fmt.Sprint(rows[0]["hop3"])}
+	if !slices.Equal(path, []string{"A2", "A3", "A4"}) {
+		t.Errorf("path = %v, want [A2 A3 A4]", path)
+	}
+
+	// Breadth-first over the fetched edges for everything within three hops.
+	edges := fraudEdges(t, ctx, f.db)
+	depth := map[string]int{"A1": 0}
+	queue := []string{"A1"}
+	for len(queue) > 0 {
+		acc := queue[0]
+		queue = queue[1:]
+		if depth[acc] == 3 {
+			continue
+		}
+		for _, next := range edges[acc] {
+			if _, seen := depth[next]; !seen {
+				depth[next] = depth[acc] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	delete(depth, "A1")
+	if got := slices.Sorted(maps.Keys(depth)); !slices.Equal(got, []string{"A2", "A3", "A4", "A5", "A6", "A8"}) {
+		t.Errorf("reachable within 3 hops = %v", got)
+	}
+	if depth["A8"] != 1 {
+		t.Errorf("A8 depth = %d, want 1 (direct transfer T7)", depth["A8"])
+	}
+}
+
+func TestIntegration_Fraud_FlaggedCascade(t *testing.T) {
+	f := seedFraud(t)
+	ctx := context.Background()
+	accounts := gotype.MustNewManager[FraudAccount](f.db)
+
+	// Flag everyone who received money from a flagged account until
+	// nothing changes.
+	for round := 1; ; round++ {
+		rows, err := f.db.ExecuteRead(ctx, `match
+$s isa account, has flagged true;
+$tr isa transfers, links (sender: $s, receiver: $r);
+$r has flagged false;
+fetch { "account": $r.account-id };`)
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		if round > 5 {
+			t.Fatal("cascade did not settle")
+		}
+		var ids []any
+		for _, row := range rows {
+			ids = append(ids, fmt.Sprint(row["account"]))
+		}
+		if _, err := accounts.Query().Filter(gotype.In("account-id", ids)).
+			Update(ctx, map[string]any{"flagged": true}); err != nil {
+			t.Fatalf("round %d: flag %v: %v", round, ids, err)
+		}
+	}
+
+	flagged, err := accounts.Query().Filter(gotype.Eq("flagged", true)).OrderAsc("account-id").Execute(ctx)
+	if err != nil {
+		t.Fatalf("flagged accounts: %v", err)
+	}
+	var got []string
+	for _, a := range flagged {
+		got = append(got, a.AccountID)
+	}
+	// A3 → A4 (T3) → A7 (T8).
+	if !slices.Equal(got, []string{"A3", "A4", "A7"}) {
+		t.Errorf("flagged = %v, want [A3 A4 A7]", got)
+	}
+
+	// Merchants paid by flagged accounts.
+	rows, err := f.db.ExecuteRead(ctx, `match
+$a isa account, has flagged true;
+$p isa pays, links (payer: $a, payee: $m);
+fetch { "merchant": $m.merchant-name };`)
+	if err != nil {
+		t.Fatalf("exposed merchants: %v", err)
+	}
+	if len(rows) != 1 || fmt.Sprint(rows[0]["merchant"]) != "LuxWatch" {
+		t.Errorf("exposed merchants = %v, want LuxWatch", rows)
+	}
+
+	// Unflagged accounts sharing a device with a flagged one.
+	rows, err = f.db.ExecuteRead(ctx, `match
+$bad isa account, has flagged true;
+$u1 isa uses-device, links (user: $bad, device: $d);
+$u2 isa uses-device, links (user: $other, device: $d);
+$other has flagged false;
+fetch { "account": $other.account-id };`)
+	if err != nil {
+		t.Fatalf("device neighbours: %v", err)
+	}
+	var neighbours []string
+	for _, row := range rows {
+		neighbours = append(neighbours, fmt.Sprint(row["account"]))
+	}
+	slices.Sort(neighbours)
+	neighbours = slices.Compact(neighbours)
+	// A2 shares D2 with A3; A6 shares D5 with A7.
+	if !slices.Equal(neighbours, []string{"A2", "A6"}) {
+		t.Errorf("device neighbours of flagged accounts = %v, want [A2 A6]", neighbours)
+	}
+}
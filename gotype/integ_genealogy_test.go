@@ -0,0 +1,351 @@
+//go:build integration && cgo && typedb
+
+package gotype_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ---------------------------------------------------------------------------
+// Genealogy domain models — self-referential relations
+// ---------------------------------------------------------------------------
+
+type GenPerson struct {
+	gotype.BaseEntity
+	Name       string `typedb:"full-name,key"`
+	BirthYear  int    `typedb:"birth-year"`
+	Generation int    `typedb:"generation"`
+}
+
+type Parentage struct {
+	gotype.BaseRelation
+	Parent *GenPerson `typedb:"role:parent"`
+	Child  *GenPerson `typedb:"role:child"`
+}
+
+type Marriage struct {
+	gotype.BaseRelation
+	FirstSpouse  *GenPerson `typedb:"role:first-spouse"`
+	SecondSpouse *GenPerson `typedb:"role:second-spouse"`
+	Year         int        `typedb:"marriage-year"`
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+// setupGenealogyDB also limits each person to one marriage per spouse role,
+// which the generated schema does not express.
+func setupGenealogyDB(t *testing.T) *gotype.Database {
+	t.Helper()
+	db := setupTestDBWith(t, func() {
+		_ = gotype.Register[GenPerson](gotype.WithTypeName("person"))
+		_ = gotype.Register[Parentage]()
+		_ = gotype.Register[Marriage]()
+	})
+	err := db.ExecuteSchema(context.Background(), `define
+person plays marriage:first-spouse @card(0..1), plays marriage:second-spouse @card(0..1);`)
+	if err != nil {
+		t.Fatalf("marriage cardinality: %v", err)
+	}
+	return db
+}
+
+// seedGenealogy loads four generations:
+//
+//	1  Arthur = Beatrice
+//	2  Carl = Erin          Dora = Hugo
+//	3  Finn = Jade, Gwen    Ivy
+//	4  Kai
+//
+// Erin, Hugo and Jade married in and have no parents in the tree.
+func seedGenealogy(t *testing.T) (*gotype.Database, map[string]*GenPerson) {
+	t.Helper()
+	db := setupGenealogyDB(t)
+	ctx := context.Background()
+
+	personMgr := gotype.MustNewManager[GenPerson](db)
+	people := make(map[string]*GenPerson)
+	for _, p := range []*GenPerson{
+		{Name: "Arthur", BirthYear: 1920, Generation: 1},
+		{Name: "Beatrice", BirthYear: 1922, Generation: 1},
+		{Name: "Carl", BirthYear: 1947, Generation: 2},
+		{Name: "Dora", BirthYear: 1950, Generation: 2},
+		{Name: "Erin", BirthYear: 1949, Generation: 2},
+		{Name: "Hugo", BirthYear: 1948, Generation: 2},
+		{Name: "Finn", BirthYear: 1972, Generation: 3},
+		{Name: "Gwen", BirthYear: 1975, Generation: 3},
+		{Name: "Ivy", BirthYear: 1974, Generation: 3},
+		{Name: "Jade", BirthYear: 1973, Generation: 3},
+		{Name: "Kai", BirthYear: 2002, Generation: 4},
+	} {
+		people[p.Name] = insertAndGet(t, ctx, personMgr, p, "full-name", p.Name)
+	}
+
+	parentMgr := gotype.MustNewManager[Parentage](db)
+	for _, pc := range [][2]string{
+		{"Arthur", "Carl"}, {"Beatrice", "Carl"},
+		{"Arthur", "Dora"}, {"Beatrice", "Dora"},
+		{"Carl", "Finn"}, {"Erin", "Finn"},
+		{"Carl", "Gwen"}, {"Erin", "Gwen"},
+		{"Dora", "Ivy"}, {"Hugo", "Ivy"},
+		{"Finn", "Kai"}, {"Jade", "Kai"},
+	} {
+		assertInsert(t, ctx, parentMgr, &Parentage{Parent: people[pc[0]], Child: people[pc[1]]})
+	}
+
+	marriageMgr := gotype.MustNewManager[Marriage](db)
+	for _, m := range []struct {
+		a, b string
+		year int
+	}{
+		{"Arthur", "Beatrice", 1945},
+		{"Carl", "Erin", 1970},
+		{"Dora", "Hugo", 1972},
+		{"Finn", "Jade", 2000},
+	} {
+		assertInsert(t, ctx, marriageMgr, &Marriage{FirstSpouse: people[m.a], SecondSpouse: people[m.b], Year: m.year})
+	}
+
+	return db, people
+}
+
+// relatives walks parentage links from name, towards parents when up is
+// true and towards children otherwise, and returns everyone reached keyed
+// by distance.
+func relatives(t *testing.T, ctx context.Context, db *gotype.Database, name string, up bool) map[string]int {
+	t.Helper()
+	from, to := "child", "parent"
+	if !up {
+		from, to = to, from
+	}
+	depth := make(map[string]int)
+	frontier := []string{name}
+	for d := 1; len(frontier) > 0; d++ {
+		var next []string
+		for _, n := range frontier {
+			for _, r := range fetchColumn(t, ctx, db, "name", fmt.Sprintf(`match
+$p isa person, has full-name %q;
+$pa isa parentage, links (%s: $p, %s: $r);
+fetch { "name": $r.full-name };`, n, from, to)) {
+				if _, seen := depth[r]; !seen {
+					depth[r] = d
+					next = append(next, r)
+				}
+			}
+		}
+		frontier = next
+	}
+	return depth
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestIntegration_Genealogy_AllInserted(t *testing.T) {
+	db, _ := seedGenealogy(t)
+	ctx := context.Background()
+
+	assertCount(t, ctx, gotype.MustNewManager[GenPerson](db), 11)
+	assertCount(t, ctx, gotype.MustNewManager[Parentage](db), 12)
+	assertCount(t, ctx, gotype.MustNewManager[Marriage](db), 4)
+
+	// Both roles of a self-referential relation filter independently.
+	n, err := gotype.MustNewManager[Parentage](db).Query().
+		Filter(gotype.RolePlayer("parent", gotype.Eq("full-name", "Carl"))).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("count Carl's children: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Carl has %d children, want 2", n)
+	}
+}
+
+func TestIntegration_Genealogy_Ancestors(t *testing.T) {
+	db, _ := seedGenealogy(t)
+	ctx := context.Background()
+
+	want := map[string]int{"Finn": 1, "Jade": 1, "Carl": 2, "Erin": 2, "Arthur": 3, "Beatrice": 3}
+	if got := relatives(t, ctx, db, "Kai", true); !maps.Equal(got, want) {
+		t.Errorf("ancestors of Kai = %v, want %v", got, want)
+	}
+	if got := relatives(t, ctx, db, "Hugo", true); len(got) != 0 {
+		t.Errorf("Hugo has no recorded ancestors, got %v", got)
+	}
+
+	// Great-grandparents as one fixed-depth query.
+	got := fetchColumn(t, ctx, db, "name", `match
+$kai isa person, has full-name "Kai";
+$p1 isa parentage, links (parent: $a1, child: $kai);
+$p2 isa parentage, links (parent: $a2, child: $a1);
+$p3 isa parentage, links (parent: $a3, child: $a2);
+fetch { "name": $a3.full-name };`)
+	if !slices.Equal(got, []string{"Arthur", "Beatrice"}) {
+		t.Errorf("great-grandparents of Kai = %v", got)
+	}
+}
+
+func TestIntegration_Genealogy_Descendants(t *testing.T) {
+	db, _ := seedGenealogy(t)
+	ctx := context.Background()
+
+	arthur := relatives(t, ctx, db, "Arthur", false)
+	want := map[string]int{"Carl": 1, "Dora": 1, "Finn": 2, "Gwen": 2, "Ivy": 2, "Kai": 3}
+	if !maps.Equal(arthur, want) {
+		t.Errorf("descendants of Arthur = %v, want %v", arthur, want)
+	}
+	if got := relatives(t, ctx, db, "Hugo", false); !maps.Equal(got, map[string]int{"Ivy": 1}) {
+		t.Errorf("descendants of Hugo = %v", got)
+	}
+	if got := relatives(t, ctx, db, "Kai", false); len(got) != 0 {
+		t.Errorf("Kai has no descendants, got %v", got)
+	}
+
+	// Stored generations agree with the distance from Arthur.
+	people, err := gotype.MustNewManager[GenPerson](db).Query().
+		Filter(gotype.In("full-name", []any{"Carl", "Dora", "Finn", "Gwen", "Ivy", "Kai"})).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("query descendants: %v", err)
+	}
+	for _, p := range people {
+		if p.Generation != arthur[p.Name]+1 {
+			t.Errorf("%s: generation %d, but %d steps below Arthur", p.Name, p.Generation, arthur[p.Name])
+		}
+	}
+}
+
+func TestIntegration_Genealogy_SiblingsAndCousins(t *testing.T) {
+	db, _ := seedGenealogy(t)
+	ctx := context.Background()
+
+	siblings := func(name string) []string {
+		rows, err := db.ExecuteRead(ctx, fmt.Sprintf(`match
+$me isa person, has full-name %q;
+$p1 isa parentage, links (parent: $par, child: $me);
+$p2 isa parentage, links (parent: $par, child: $sib);
+fetch { "name": $sib.full-name };`, name))
+		if err != nil {
+			t.Fatalf("siblings of %s: %v", name, err)
+		}
+		var out []string
+		for _, row := range rows {
+			if n := fmt.Sprint(row["name"]); n != name {
+				out = append(out, n)
+			}
+		}
+		slices.Sort(out)
+		return slices.Compact(out)
+	}
+	if got := siblings("Finn"); !slices.Equal(got, []string{"Gwen"}) {
+		t.Errorf("siblings of Finn = %v, want [Gwen]", got)
+	}
+	if got := siblings("Ivy"); len(got) != 0 {
+		t.Errorf("Ivy is an only child, got siblings %v", got)
+	}
+
+	// Cousins: children of a parent's siblings.
+	cousins := func(name string) []string {
+		rows, err := db.ExecuteRead(ctx, fmt.Sprintf(`match
+$me isa person, has full-name %q;
+$p1 isa parentage, links (parent: $par, child: $me);
+$p2 isa parentage, links (parent: $gp, child: $par);
+$p3 isa parentage, links (parent: $gp, child: $aunt);
+$p4 isa parentage, links (parent: $aunt, child: $cousin);
+fetch { "parent": $par.full-name, "aunt": $aunt.full-name, "cousin": $cousin.full-name };`, name))
+		if err != nil {
+			t.Fatalf("cousins of %s: %v", name, err)
+		}
+		var out []string
+		for _, row := range rows {
+			if row["parent"] != row["aunt"] {
+				out = append(out, fmt.Sprint(row["cousin"]))
+			}
+		}
+		slices.Sort(out)
+		return slices.Compact(out)
+	}
+	if got := cousins("Ivy"); !slices.Equal(got, []string{"Finn", "Gwen"}) {
+		t.Errorf("cousins of Ivy = %v, want [Finn Gwen]", got)
+	}
+	if got := cousins("Gwen"); !slices.Equal(got, []string{"Ivy"}) {
+		t.Errorf("cousins of Gwen = %v, want [Ivy]", got)
+	}
+	if got := cousins("Carl"); len(got) != 0 {
+		t.Errorf("Carl has no cousins in the tree, got %v", got)
+	}
+}
+
+func TestIntegration_Genealogy_MarriageCardinality(t *testing.T) {
+	db, people := seedGenealogy(t)
+	ctx := context.Background()
+	mgr := gotype.MustNewManager[Marriage](db)
+
+	// Hugo already is a second spouse (of Dora).
+	remarriage := &Marriage{FirstSpouse: people["Ivy"], SecondSpouse: people["Hugo"], Year: 2005}
+	if err := mgr.Insert(ctx, remarriage); err == nil {
+		t.Fatal("expected a second marriage as second-spouse to violate @card(0..1)")
+	}
+
+	// After the divorce, he may marry again.
+	divorced, err := mgr.Query().
+		Filter(
+			gotype.RolePlayer("first-spouse", gotype.Eq("full-name", "Dora")),
+			gotype.RolePlayer("second-spouse", gotype.Eq("full-name", "Hugo")),
+		).
+		Delete(ctx)
+	if err != nil {
+		t.Fatalf("divorce: %v", err)
+	}
+	if divorced != 1 {
+		t.Fatalf("deleted %d marriages, want 1", divorced)
+	}
+	assertInsert(t, ctx, mgr, remarriage)
+	assertCount(t, ctx, mgr, 4)
+}
+
+func TestIntegration_Genealogy_GenerationCounts(t *testing.T) {
+	db, _ := seedGenealogy(t)
+	ctx := context.Background()
+	mgr := gotype.MustNewManager[GenPerson](db)
+
+	stats, err := mgr.Query().GroupBy("generation").Aggregate(ctx,
+		gotype.AggregateSpec{Attr: "full-name", Fn: "count"},
+		gotype.AggregateSpec{Attr: "birth-year", Fn: "min"},
+		gotype.AggregateSpec{Attr: "birth-year", Fn: "max"},
+	)
+	if err != nil {
+		t.Fatalf("group by generation: %v", err)
+	}
+	want := map[string][3]float64{
+		"1": {2, 1920, 1922},
+		"2": {4, 1947, 1950},
+		"3": {4, 1972, 1975},
+		"4": {1, 2002, 2002},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("expected %d generations, got %v", len(want), stats)
+	}
+	for gen, w := range want {
+		got := stats[gen]
+		if got["count_full-name"] != w[0] || got["min_birth-year"] != w[1] || got["max_birth-year"] != w[2] {
+			t.Errorf("generation %s = %v, want count %v, born %v-%v", gen, got, w[0], w[1], w[2])
+		}
+	}
+
+	deepest, err := mgr.Query().Max("generation").Execute(ctx)
+	if err != nil {
+		t.Fatalf("max generation: %v", err)
+	}
+	if deepest != 4 {
+		t.Errorf("deepest generation = %v, want 4", deepest)
+	}
+}
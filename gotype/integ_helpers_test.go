@@ -68,7 +68,21 @@ func (a *driverAdapter) Transaction(dbName string, txType int) (gotype.Tx, error
 	if err != nil {
 		return nil, err
 	}
-	return tx, nil
+	return driverTx{tx}, nil
+}
+
+// driverTx adds gotype.ResultTx to a driver transaction, which returns the
+// answer rows of write queries but no counts.
+type driverTx struct {
+	*driver.Transaction
+}
+
+func (t driverTx) QueryResult(ctx context.Context, query string) (gotype.Result, error) {
+	rows, err := t.QueryWithContext(ctx, query)
+	if err != nil {
+		return gotype.Result{}, err
+	}
+	return gotype.Result{Rows: rows, Summary: gotype.RowSummary(query, rows)}, nil
 }
 
 func (a *driverAdapter) Schema(dbName string) (string, error) {
@@ -0,0 +1,518 @@
+//go:build integration && cgo && typedb
+
+package gotype_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ---------------------------------------------------------------------------
+// HR / org chart domain models
+// ---------------------------------------------------------------------------
+
+type HREmployee struct {
+	gotype.BaseEntity
+	Name   string `typedb:"employee-name,key"`
+	Grade  int    `typedb:"grade"`
+	Salary int    `typedb:"salary"`
+}
+
+type HRDepartment struct {
+	gotype.BaseEntity
+	Name string `typedb:"department-name,key"`
+}
+
+type HRPosition struct {
+	gotype.BaseEntity
+	Code        string     `typedb:"position-code,key"`
+	Title       string     `typedb:"position-title"`
+	Department  string     `typedb:"position-department"`
+	FilledSince *time.Time `typedb:"filled-since"`
+}
+
+type HRReportsTo struct {
+	gotype.BaseRelation
+	Subordinate *HREmployee `typedb:"role:subordinate"`
+	Manager     *HREmployee `typedb:"role:manager"`
+}
+
+type HRBelongsTo struct {
+	gotype.BaseRelation
+	Member     *HREmployee   `typedb:"role:member"`
+	Department *HRDepartment `typedb:"role:department"`
+}
+
+type HRHolds struct {
+	gotype.BaseRelation
+	Holder   *HREmployee `typedb:"role:holder"`
+	Position *HRPosition `typedb:"role:held-position"`
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+func setupHRDB(t *testing.T) *gotype.Database {
+	return setupTestDBWith(t, func() {
+		_ = gotype.Register[HREmployee](gotype.WithTypeName("employee"))
+		_ = gotype.Register[HRDepartment](gotype.WithTypeName("department"))
+		_ = gotype.Register[HRPosition](gotype.WithTypeName("position"))
+		_ = gotype.Register[HRReportsTo](gotype.WithTypeName("reports-to"))
+		_ = gotype.Register[HRBelongsTo](gotype.WithTypeName("belongs-to"))
+		_ = gotype.Register[HRHolds](gotype.WithTypeName("holds"))
+	})
+}
+
+var hrHireDate = time.Date(2020, 1, 6, 9, 0, 0, 0, time.UTC)
+
+// seedHR loads ten employees in three departments:
+//
+//	Morgan (executive)
+//	  Riley (engineering)            Sasha (sales)
+//	    Taylor         Xan             Yara   Zed
+//	      Uma  Vic  Wes
+//
+// Twelve positions exist; P-AE3 (sales) and P-DS1 (engineering) are vacant
+// and carry no filled-since date.
+func seedHR(t *testing.T) (*gotype.Database, map[string]*HREmployee) {
+	t.Helper()
+	db := setupHRDB(t)
+	ctx := context.Background()
+
+	deptMgr := gotype.MustNewManager[HRDepartment](db)
+	depts := make(map[string]*HRDepartment)
+	for _, name := range []string{"executive", "engineering", "sales"} {
+		depts[name] = insertAndGet(t, ctx, deptMgr, &HRDepartment{Name: name}, "department-name", name)
+	}
+
+	empMgr := gotype.MustNewManager[HREmployee](db)
+	posMgr := gotype.MustNewManager[HRPosition](db)
+	belongsMgr := gotype.MustNewManager[HRBelongsTo](db)
+	holdsMgr := gotype.MustNewManager[HRHolds](db)
+	emps := make(map[string]*HREmployee)
+	for _, e := range []struct {
+		name          string
+		grade, salary int
+		dept          string
+		code, title   string
+	}{
+		{"Morgan", 10, 300, "executive", "P-CEO", "Chief Executive"},
+		{"Riley", 9, 250, "engineering", "P-CTO", "Chief Technology Officer"},
+		{"Sasha", 9, 240, "sales", "P-VPS", "VP Sales"},
+		{"Taylor", 7, 180, "engineering", "P-EM1", "Engineering Manager"},
+		{"Xan", 6, 150, "engineering", "P-SE4", "Senior Engineer"},
+		{"Uma", 5, 120, "engineering", "P-SE1", "Software Engineer"},
+		{"Vic", 5, 120, "engineering", "P-SE2", "Software Engineer"},
+		{"Wes", 4, 100, "engineering", "P-SE3", "Software Engineer"},
+		{"Yara", 5, 110, "sales", "P-AE1", "Account Executive"},
+		{"Zed", 4, 90, "sales", "P-AE2", "Account Executive"},
+	} {
+		emp := insertAndGet(t, ctx, empMgr, &HREmployee{Name: e.name, Grade: e.grade, Salary: e.salary}, "employee-name", e.name)
+		emps[e.name] = emp
+		since := hrHireDate
+		pos := insertAndGet(t, ctx, posMgr,
+			&HRPosition{Code: e.code, Title: e.title, Department: e.dept, FilledSince: &since},
+			"position-code", e.code)
+		assertInsert(t, ctx, holdsMgr, &HRHolds{Holder: emp, Position: pos})
+		assertInsert(t, ctx, belongsMgr, &HRBelongsTo{Member: emp, Department: depts[e.dept]})
+	}
+	assertInsertMany(t, ctx, posMgr, []*HRPosition{
+		{Code: "P-AE3", Title: "Account Executive", Department: "sales"},
+		{Code: "P-DS1", Title: "Data Scientist", Department: "engineering"},
+	})
+
+	reportsMgr := gotype.MustNewManager[HRReportsTo](db)
+	for _, sm := range [][2]string{
+		{"Riley", "Morgan"}, {"Sasha", "Morgan"},
+		{"Taylor", "Riley"}, {"Xan", "Riley"},
+		{"Uma", "Taylor"}, {"Vic", "Taylor"}, {"Wes", "Taylor"},
+		{"Yara", "Sasha"}, {"Zed", "Sasha"},
+	} {
+		assertInsert(t, ctx, reportsMgr, &HRReportsTo{Subordinate: emps[sm[0]], Manager: emps[sm[1]]})
+	}
+
+	return db, emps
+}
+
+// managementChain follows reports-to upwards from name and returns the
+// managers in order, nearest first.
+func managementChain(t *testing.T, ctx context.Context, db *gotype.Database, name string) []string {
+	t.Helper()
+	var chain []string
+	for {
+		managers := fetchColumn(t, ctx, db, "name", fmt.Sprintf(`match
+$e isa employee, has employee-name %q;
+$r isa reports-to, links (subordinate: $e, manager: $m);
+fetch { "name": $m.employee-name };`, name))
+		if len(managers) == 0 {
+			return chain
+		}
+		if len(managers) > 1 {
+			t.Fatalf("%s reports to %v", name, managers)
+		}
+		name = managers[0]
+		if slices.Contains(chain, name) {
+			t.Fatalf("reporting cycle through %s: %v", name, chain)
+		}
+		chain = append(chain, name)
+	}
+}
+
+// allReports returns everyone below name in the org chart.
+func allReports(t *testing.T, ctx context.Context, db *gotype.Database, name string) []string {
+	t.Helper()
+	var out []string
+	frontier := []string{name}
+	for len(frontier) > 0 {
+		var next []string
+		for _, m := range frontier {
+			next = append(next, fetchColumn(t, ctx, db, "name", fmt.Sprintf(`match
+$m isa employee, has employee-name %q;
+$r isa reports-to, links (subordinate: $e, manager: $m);
+fetch { "name": $e.employee-name };`, m))...)
+		}
+		out = append(out, next...)
+		frontier = next
+	}
+	slices.Sort(out)
+	return out
+}
+
+func positionCodes(positions []*HRPosition) []string {
+	var out []string
+	for _, p := range positions {
+		out = append(out, p.Code)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestIntegration_HR_AllInserted(t *testing.T) {
+	db, _ := seedHR(t)
+	ctx := context.Background()
+
+	assertCount(t, ctx, gotype.MustNewManager[HREmployee](db), 10)
+	assertCount(t, ctx, gotype.MustNewManager[HRDepartment](db), 3)
+	assertCount(t, ctx, gotype.MustNewManager[HRPosition](db), 12)
+	assertCount(t, ctx, gotype.MustNewManager[HRReportsTo](db), 9)
+	assertCount(t, ctx, gotype.MustNewManager[HRBelongsTo](db), 10)
+	assertCount(t, ctx, gotype.MustNewManager[HRHolds](db), 10)
+}
+
+func TestIntegration_HR_ManagementChain(t *testing.T) {
+	db, _ := seedHR(t)
+	ctx := context.Background()
+
+	for name, want := range map[string][]string{
+		"Wes":    {"Taylor", "Riley", "Morgan"},
+		"Xan":    {"Riley", "Morgan"},
+		"Zed":    {"Sasha", "Morgan"},
+		"Morgan": nil,
+	} {
+		if got := managementChain(t, ctx, db, name); !slices.Equal(got, want) {
+			t.Errorf("chain of %s = %v, want %v", name, got, want)
+		}
+	}
+
+	if got := allReports(t, ctx, db, "Riley"); !slices.Equal(got, []string{"Taylor", "Uma", "Vic", "Wes", "Xan"}) {
+		t.Errorf("everyone under Riley = %v", got)
+	}
+	if got := allReports(t, ctx, db, "Morgan"); len(got) != 9 {
+		t.Errorf("everyone under Morgan = %v, want 9 people", got)
+	}
+
+	// Skip-level: people whose manager's manager is Riley.
+	got := fetchColumn(t, ctx, db, "name", `match
+$top isa employee, has employee-name "Riley";
+$r1 isa reports-to, links (subordinate: $mid, manager: $top);
+$r2 isa reports-to, links (subordinate: $e, manager: $mid);
+fetch { "name": $e.employee-name };`)
+	if !slices.Equal(got, []string{"Uma", "Vic", "Wes"}) {
+		t.Errorf("Riley's skip-level reports = %v", got)
+	}
+
+	// Only the CEO has no manager.
+	got = fetchColumn(t, ctx, db, "name", `match
+$e isa employee;
+not { $r isa reports-to, links (subordinate: $e); };
+fetch { "name": $e.employee-name };`)
+	if !slices.Equal(got, []string{"Morgan"}) {
+		t.Errorf("employees without a manager = %v, want [Morgan]", got)
+	}
+}
+
+func TestIntegration_HR_SpanOfControl(t *testing.T) {
+	db, _ := seedHR(t)
+	ctx := context.Background()
+
+	span := countBy(t, ctx, db, "manager", `match
+$r isa reports-to, links (manager: $m);
+fetch { "manager": $m.employee-name };`)
+	want := map[string]int{"Morgan": 2, "Riley": 2, "Sasha": 2, "Taylor": 3}
+	if !maps.Equal(span, want) {
+		t.Errorf("span of control = %v, want %v", span, want)
+	}
+
+	n, err := gotype.MustNewManager[HRReportsTo](db).Query().
+		Filter(gotype.RolePlayer("manager", gotype.Eq("employee-name", "Taylor"))).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("count Taylor's reports: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Taylor has %d direct reports, want 3", n)
+	}
+
+	// Managers are exactly the employees with at least one report.
+	managers, err := gotype.MustNewManager[HREmployee](db).Query().
+		Filter(gotype.Gte("grade", 7)).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("senior grades: %v", err)
+	}
+	for _, m := range managers {
+		if span[m.Name] == 0 {
+			t.Errorf("%s (grade %d) manages nobody", m.Name, m.Grade)
+		}
+	}
+
+	headcount := countBy(t, ctx, db, "department", `match
+$b isa belongs-to, links (department: $d);
+fetch { "department": $d.department-name };`)
+	if !maps.Equal(headcount, map[string]int{"executive": 1, "engineering": 6, "sales": 3}) {
+		t.Errorf("headcount = %v", headcount)
+	}
+
+	byGrade, err := gotype.MustNewManager[HREmployee](db).Query().GroupBy("grade").Aggregate(ctx,
+		gotype.AggregateSpec{Attr: "employee-name", Fn: "count"},
+		gotype.AggregateSpec{Attr: "salary", Fn: "mean"},
+	)
+	if err != nil {
+		t.Fatalf("group by grade: %v", err)
+	}
+	for grade, w := range map[string][2]float64{
+		"10": {1, 300}, "9": {2, 245}, "7": {1, 180}, "6": {1, 150}, "5": {3, 350.0 / 3}, "4": {2, 95},
+	} {
+		got := byGrade[grade]
+		if got["count_employee-name"] != w[0] || math.Abs(got["mean_salary"]-w[1]) > 1e-6 {
+			t.Errorf("grade %s = %v, want %v people averaging %v", grade, got, w[0], w[1])
+		}
+	}
+}
+
+func TestIntegration_HR_Vacancies(t *testing.T) {
+	db, _ := seedHR(t)
+	ctx := context.Background()
+	posMgr := gotype.MustNewManager[HRPosition](db)
+
+	vacant, err := posMgr.Query().Filter(gotype.NotHasAttr("filled-since")).Execute(ctx)
+	if err != nil {
+		t.Fatalf("vacant positions: %v", err)
+	}
+	if got := positionCodes(vacant); !slices.Equal(got, []string{"P-AE3", "P-DS1"}) {
+		t.Errorf("vacancies = %v, want [P-AE3 P-DS1]", got)
+	}
+	for _, p := range vacant {
+		if p.FilledSince != nil {
+			t.Errorf("%s: vacant but filled since %v", p.Code, p.FilledSince)
+		}
+	}
+
+	// The attribute agrees with the holds relation.
+	unheld := fetchColumn(t, ctx, db, "code", `match
+$p isa position;
+not { $h isa holds, links (held-position: $p); };
+fetch { "code": $p.position-code };`)
+	if !slices.Equal(unheld, []string{"P-AE3", "P-DS1"}) {
+		t.Errorf("positions nobody holds = %v", unheld)
+	}
+
+	engineering, err := posMgr.Query().
+		Filter(gotype.NotHasAttr("filled-since"), gotype.Eq("position-department", "engineering")).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("engineering vacancies: %v", err)
+	}
+	if got := positionCodes(engineering); !slices.Equal(got, []string{"P-DS1"}) {
+		t.Errorf("engineering vacancies = %v, want [P-DS1]", got)
+	}
+
+	filled, err := posMgr.Query().Filter(gotype.HasAttr("filled-since")).Count(ctx)
+	if err != nil {
+		t.Fatalf("count filled: %v", err)
+	}
+	if filled != 10 {
+		t.Errorf("filled positions = %d, want 10", filled)
+	}
+}
+
+// TestIntegration_HR_ReorgInOneTransaction splits a data team out of
+// engineering: Xan leads it, Vic moves under Xan, a new hire fills P-DS1,
+// and Taylor's remaining engineers are promoted with UpdateWith. Every step
+// runs in one write transaction, so nothing is visible until commit.
+func TestIntegration_HR_ReorgInOneTransaction(t *testing.T) {
+	db, emps := seedHR(t)
+	ctx := context.Background()
+
+	tc, err := db.Begin(gotype.WriteTransaction)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tc.Close()
+
+	deptMgr := gotype.MustNewManagerWithTx[HRDepartment](tc)
+	empMgr := gotype.MustNewManagerWithTx[HREmployee](tc)
+	posMgr := gotype.MustNewManagerWithTx[HRPosition](tc)
+	reportsMgr := gotype.MustNewManagerWithTx[HRReportsTo](tc)
+	belongsMgr := gotype.MustNewManagerWithTx[HRBelongsTo](tc)
+	holdsMgr := gotype.MustNewManagerWithTx[HRHolds](tc)
+
+	data := &HRDepartment{Name: "data"}
+	if err := deptMgr.Insert(ctx, data); err != nil {
+		t.Fatalf("insert data department: %v", err)
+	}
+
+	// Move Vic from Taylor to Xan, and Vic and Xan from engineering to data.
+	if _, err := reportsMgr.Query().
+		Filter(gotype.RolePlayer("subordinate", gotype.Eq("employee-name", "Vic"))).
+		Delete(ctx); err != nil {
+		t.Fatalf("detach Vic from Taylor: %v", err)
+	}
+	if _, err := belongsMgr.Query().
+		Filter(gotype.RolePlayer("member", gotype.In("employee-name", []any{"Vic", "Xan"}))).
+		Delete(ctx); err != nil {
+		t.Fatalf("leave engineering: %v", err)
+	}
+	if err := reportsMgr.Insert(ctx, &HRReportsTo{Subordinate: emps["Vic"], Manager: emps["Xan"]}); err != nil {
+		t.Fatalf("Vic reports to Xan: %v", err)
+	}
+
+	// Hire into the vacant data scientist position.
+	ash := &HREmployee{Name: "Ash", Grade: 5, Salary: 125}
+	if err := empMgr.Insert(ctx, ash); err != nil {
+		t.Fatalf("hire Ash: %v", err)
+	}
+	dataScientist, err := posMgr.Query().Filter(gotype.Eq("position-code", "P-DS1")).First(ctx)
+	if err != nil || dataScientist == nil {
+		t.Fatalf("find P-DS1: %v", err)
+	}
+	if err := holdsMgr.Insert(ctx, &HRHolds{Holder: ash, Position: dataScientist}); err != nil {
+		t.Fatalf("Ash holds P-DS1: %v", err)
+	}
+	if _, err := posMgr.Query().
+		Filter(gotype.Eq("position-code", "P-DS1")).
+		Update(ctx, map[string]any{"filled-since": hrHireDate.AddDate(5, 0, 0)}); err != nil {
+		t.Fatalf("fill P-DS1: %v", err)
+	}
+	if err := reportsMgr.Insert(ctx, &HRReportsTo{Subordinate: ash, Manager: emps["Xan"]}); err != nil {
+		t.Fatalf("Ash reports to Xan: %v", err)
+	}
+	for _, member := range []*HREmployee{emps["Vic"], emps["Xan"], ash} {
+		if err := belongsMgr.Insert(ctx, &HRBelongsTo{Member: member, Department: data}); err != nil {
+			t.Fatalf("%s joins data: %v", member.Name, err)
+		}
+	}
+
+	// Xan becomes a manager; Taylor's remaining reports are promoted.
+	if _, err := posMgr.Query().
+		Filter(gotype.Eq("position-code", "P-SE4")).
+		Update(ctx, map[string]any{"position-title": "Data Lead"}); err != nil {
+		t.Fatalf("retitle P-SE4: %v", err)
+	}
+	promoted, err := empMgr.Query().
+		Filter(gotype.In("employee-name", []any{"Xan", "Uma", "Wes"})).
+		UpdateWith(ctx, func(e *HREmployee) {
+			e.Grade++
+			e.Salary += 10
+		})
+	if err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	if len(promoted) != 3 {
+		t.Fatalf("promoted %d employees, want 3", len(promoted))
+	}
+
+	// Readers outside the transaction still see the old org chart.
+	if got := managementChain(t, ctx, db, "Vic"); !slices.Equal(got, []string{"Taylor", "Riley", "Morgan"}) {
+		t.Errorf("before commit, chain of Vic = %v", got)
+	}
+	assertCount(t, ctx, gotype.MustNewManager[HREmployee](db), 10)
+
+	if err := tc.Commit(); err != nil {
+		t.Fatalf("commit reorg: %v", err)
+	}
+
+	if got := managementChain(t, ctx, db, "Vic"); !slices.Equal(got, []string{"Xan", "Riley", "Morgan"}) {
+		t.Errorf("chain of Vic = %v, want [Xan Riley Morgan]", got)
+	}
+	if got := allReports(t, ctx, db, "Xan"); !slices.Equal(got, []string{"Ash", "Vic"}) {
+		t.Errorf("Xan's team = %v, want [Ash Vic]", got)
+	}
+	if got := allReports(t, ctx, db, "Taylor"); !slices.Equal(got, []string{"Uma", "Wes"}) {
+		t.Errorf("Taylor's team = %v, want [Uma Wes]", got)
+	}
+	headcount := countBy(t, ctx, db, "department", `match
+$b isa belongs-to, links (department: $d);
+fetch { "department": $d.department-name };`)
+	if !maps.Equal(headcount, map[string]int{"executive": 1, "engineering": 4, "sales": 3, "data": 3}) {
+		t.Errorf("headcount after reorg = %v", headcount)
+	}
+
+	empMgrDB := gotype.MustNewManager[HREmployee](db)
+	for name, want := range map[string][2]int{"Xan": {7, 160}, "Uma": {6, 130}, "Wes": {5, 110}, "Vic": {5, 120}} {
+		e := assertGetOne(t, ctx, empMgrDB, map[string]any{"employee-name": name})
+		if e.Grade != want[0] || e.Salary != want[1] {
+			t.Errorf("%s: grade %d salary %d, want %v", name, e.Grade, e.Salary, want)
+		}
+	}
+	lead := assertGetOne(t, ctx, gotype.MustNewManager[HRPosition](db), map[string]any{"position-code": "P-SE4"})
+	if lead.Title != "Data Lead" {
+		t.Errorf("P-SE4 title = %q, want Data Lead", lead.Title)
+	}
+	vacant, err := gotype.MustNewManager[HRPosition](db).Query().Filter(gotype.NotHasAttr("filled-since")).Execute(ctx)
+	if err != nil {
+		t.Fatalf("vacancies after reorg: %v", err)
+	}
+	if got := positionCodes(vacant); !slices.Equal(got, []string{"P-AE3"}) {
+		t.Errorf("vacancies after reorg = %v, want [P-AE3]", got)
+	}
+}
+
+func TestIntegration_HR_AbandonedReorgLeavesNoTrace(t *testing.T) {
+	db, emps := seedHR(t)
+	ctx := context.Background()
+
+	tc, err := db.Begin(gotype.WriteTransaction)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	empMgr := gotype.MustNewManagerWithTx[HREmployee](tc)
+	if _, err := empMgr.Query().UpdateWith(ctx, func(e *HREmployee) { e.Salary = 0 }); err != nil {
+		t.Fatalf("zero salaries: %v", err)
+	}
+	reportsMgr := gotype.MustNewManagerWithTx[HRReportsTo](tc)
+	if _, err := reportsMgr.Query().Filter(gotype.RolePlayer("manager", gotype.Eq("employee-name", "Morgan"))).Delete(ctx); err != nil {
+		t.Fatalf("detach Morgan's reports: %v", err)
+	}
+	tc.Close()
+
+	e := assertGetOne(t, ctx, gotype.MustNewManager[HREmployee](db), map[string]any{"employee-name": "Sasha"})
+	if e.Salary != emps["Sasha"].Salary {
+		t.Errorf("Sasha's salary = %d after rollback, want %d", e.Salary, emps["Sasha"].Salary)
+	}
+	if got := managementChain(t, ctx, db, "Zed"); !slices.Equal(got, []string{"Sasha", "Morgan"}) {
+		t.Errorf("chain of Zed after rollback = %v", got)
+	}
+}
@@ -0,0 +1,613 @@
+//go:build integration && cgo && typedb
+
+package gotype_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ---------------------------------------------------------------------------
+// Knowledge graph domain models — abstract concept hierarchy
+// ---------------------------------------------------------------------------
+
+// KGConcept is the abstract root of the ontology; concrete concepts are
+// topics, techniques and algorithms (a kind of technique).
+type KGConcept struct {
+	gotype.BaseEntity
+	Label string `typedb:"concept-label,key"`
+}
+
+type KGTopic struct {
+	gotype.BaseEntity
+	Label      string `typedb:"concept-label,key"`
+	Discipline string `typedb:"discipline"`
+}
+
+type KGTechnique struct {
+	gotype.BaseEntity
+	Label    string `typedb:"concept-label,key"`
+	Maturity string `typedb:"maturity"`
+}
+
+type KGAlgorithm struct {
+	gotype.BaseEntity
+	Label      string `typedb:"concept-label,key"`
+	Maturity   string `typedb:"maturity"`
+	Complexity string `typedb:"complexity"`
+}
+
+type KGDocument struct {
+	gotype.BaseEntity
+	Title string `typedb:"doc-title,key"`
+	Year  int    `typedb:"published-year"`
+}
+
+type KGAuthor struct {
+	gotype.BaseEntity
+	Name string `typedb:"author-name,key"`
+}
+
+type KGAuthorship struct {
+	gotype.BaseRelation
+	Author *KGAuthor   `typedb:"role:author"`
+	Work   *KGDocument `typedb:"role:work"`
+}
+
+type KGMentions struct {
+	gotype.BaseRelation
+	Document *KGDocument `typedb:"role:mentioning"`
+	Concept  *KGConcept  `typedb:"role:mentioned"`
+	Count    int         `typedb:"mention-count"`
+}
+
+type KGCites struct {
+	gotype.BaseRelation
+	Citing *KGDocument `typedb:"role:citing"`
+	Cited  *KGDocument `typedb:"role:cited"`
+}
+
+type KGBroaderThan struct {
+	gotype.BaseRelation
+	Broader  *KGConcept `typedb:"role:broader"`
+	Narrower *KGConcept `typedb:"role:narrower"`
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+// setupKnowledgeDB generates the schema for the abstract concept and the
+// relations, then defines the concept subtypes by hand so that they inherit
+// label ownership and the concept roles instead of redeclaring them. The
+// subtypes are registered afterwards with their supertype set, which is what
+// polymorphic queries resolve against.
+func setupKnowledgeDB(t *testing.T) *gotype.Database {
+	t.Helper()
+	db := setupTestDBWith(t, func() {
+		_ = gotype.Register[KGConcept](gotype.WithTypeName("concept"))
+		_ = gotype.Register[KGDocument](gotype.WithTypeName("document"))
+		_ = gotype.Register[KGAuthor](gotype.WithTypeName("author"))
+		_ = gotype.Register[KGAuthorship](gotype.WithTypeName("authorship"))
+		_ = gotype.Register[KGMentions](gotype.WithTypeName("mentions"))
+		_ = gotype.Register[KGCites](gotype.WithTypeName("cites"))
+		_ = gotype.Register[KGBroaderThan](gotype.WithTypeName("broader-than"))
+		concept, _ := gotype.Lookup("concept")
+		concept.IsAbstract = true
+	})
+	err := db.ExecuteSchema(context.Background(), `define
+attribute discipline, value string;
+attribute maturity, value string;
+attribute complexity, value string;
+entity topic, sub concept, owns discipline;
+entity technique, sub concept, owns maturity;
+entity algorithm, sub technique, owns complexity;`)
+	if err != nil {
+		t.Fatalf("concept subtypes: %v", err)
+	}
+
+	gotype.MustRegister[KGTopic](gotype.WithTypeName("topic"))
+	gotype.MustRegister[KGTechnique](gotype.WithTypeName("technique"))
+	gotype.MustRegister[KGAlgorithm](gotype.WithTypeName("algorithm"))
+	for sub, super := range map[string]string{"topic": "concept", "technique": "concept", "algorithm": "technique"} {
+		info, _ := gotype.Lookup(sub)
+		info.Supertype = super
+	}
+	return db
+}
+
+// seedKnowledge loads a small machine-learning ontology:
+//
+//	computer-science (topic)          mathematics (topic)
+//	  machine-learning (topic)          optimization (technique)
+//	    neural-network (technique)        gradient-descent (algorithm)
+//	      backpropagation (algorithm) ------'
+//	    decision-tree (algorithm)
+//
+// Backpropagation has two broader concepts. Documents D1..D5 mention
+// concepts and cite each other; D4 and D5 are surveys that cite older work.
+func seedKnowledge(t *testing.T) *gotype.Database {
+	t.Helper()
+	db := setupKnowledgeDB(t)
+	ctx := context.Background()
+
+	assertInsertMany(t, ctx, gotype.MustNewManager[KGTopic](db), []*KGTopic{
+		{Label: "computer-science", Discipline: "computing"},
+		{Label: "machine-learning", Discipline: "computing"},
+		{Label: "mathematics", Discipline: "mathematics"},
+	})
+	assertInsertMany(t, ctx, gotype.MustNewManager[KGTechnique](db), []*KGTechnique{
+		{Label: "neural-network", Maturity: "established"},
+		{Label: "optimization", Maturity: "classical"},
+	})
+	assertInsertMany(t, ctx, gotype.MustNewManager[KGAlgorithm](db), []*KGAlgorithm{
+		{Label: "backpropagation", Maturity: "established", Complexity: "linear"},
+		{Label: "gradient-descent", Maturity: "classical", Complexity: "iterative"},
+		{Label: "decision-tree", Maturity: "classical", Complexity: "n-log-n"},
+	})
+
+	broaderMgr := gotype.MustNewManager[KGBroaderThan](db)
+	for _, bn := range [][2]string{
+		{"computer-science", "machine-learning"},
+		{"machine-learning", "neural-network"},
+		{"machine-learning", "decision-tree"},
+		{"neural-network", "backpropagation"},
+		{"mathematics", "optimization"},
+		{"optimization", "gradient-descent"},
+		{"gradient-descent", "backpropagation"},
+	} {
+		assertInsert(t, ctx, broaderMgr, &KGBroaderThan{
+			Broader:  &KGConcept{Label: bn[0]},
+			Narrower: &KGConcept{Label: bn[1]},
+		})
+	}
+
+	docMgr := gotype.MustNewManager[KGDocument](db)
+	docs := make(map[string]*KGDocument)
+	for _, d := range []*KGDocument{
+		{Title: "D1 Learning representations by back-propagating errors", Year: 1986},
+		{Title: "D2 Stochastic gradient methods", Year: 1990},
+		{Title: "D3 Induction of decision trees", Year: 1986},
+		{Title: "D4 A survey of machine learning", Year: 2010},
+		{Title: "D5 Deep learning review", Year: 2015},
+	} {
+		docs[d.Title[:2]] = insertAndGet(t, ctx, docMgr, d, "doc-title", d.Title)
+	}
+
+	authorMgr := gotype.MustNewManager[KGAuthor](db)
+	authors := make(map[string]*KGAuthor)
+	for _, name := range []string{"Hinton", "Quinlan", "Robbins", "LeCun"} {
+		authors[name] = insertAndGet(t, ctx, authorMgr, &KGAuthor{Name: name}, "author-name", name)
+	}
+	authorshipMgr := gotype.MustNewManager[KGAuthorship](db)
+	for _, aw := range [][2]string{
+		{"Hinton", "D1"}, {"Robbins", "D2"}, {"Quinlan", "D3"},
+		{"Hinton", "D4"}, {"Quinlan", "D4"},
+		{"LeCun", "D5"}, {"Hinton", "D5"},
+	} {
+		assertInsert(t, ctx, authorshipMgr, &KGAuthorship{Author: authors[aw[0]], Work: docs[aw[1]]})
+	}
+
+	citesMgr := gotype.MustNewManager[KGCites](db)
+	for _, cc := range [][2]string{
+		{"D4", "D1"}, {"D4", "D2"}, {"D4", "D3"},
+		{"D5", "D1"}, {"D5", "D2"}, {"D5", "D4"},
+		{"D3", "D1"},
+	} {
+		assertInsert(t, ctx, citesMgr, &KGCites{Citing: docs[cc[0]], Cited: docs[cc[1]]})
+	}
+
+	mentionsMgr := gotype.MustNewManager[KGMentions](db)
+	for _, m := range []struct {
+		doc, concept string
+		count        int
+	}{
+		{"D1", "backpropagation", 12},
+		{"D1", "neural-network", 7},
+		{"D2", "gradient-descent", 9},
+		{"D3", "decision-tree", 15},
+		{"D4", "machine-learning", 20},
+		{"D4", "decision-tree", 3},
+		{"D5", "neural-network", 25},
+		{"D5", "backpropagation", 4},
+	} {
+		assertInsert(t, ctx, mentionsMgr, &KGMentions{
+			Document: docs[m.doc],
+			Concept:  &KGConcept{Label: m.concept},
+			Count:    m.count,
+		})
+	}
+
+	return db
+}
+
+// conceptClosure walks broader-than links from label, towards broader
+// concepts when up is true and towards narrower ones otherwise, and returns
+// every concept reached keyed by its shortest distance.
+func conceptClosure(t *testing.T, ctx context.Context, db *gotype.Database, label string, up bool) map[string]int {
+	t.Helper()
+	from, to := "narrower", "broader"
+	if !up {
+		from, to = to, from
+	}
+	depth := make(map[string]int)
+	frontier := []string{label}
+	for d := 1; len(frontier) > 0; d++ {
+		var next []string
+		for _, l := range frontier {
+			for _, r := range fetchColumn(t, ctx, db, "label", fmt.Sprintf(`match
+$c isa concept, has concept-label %q;
+$b isa broader-than, links (%s: $c, %s: $r);
+fetch { "label": $r.concept-label };`, l, from, to)) {
+				if _, seen := depth[r]; !seen {
+					depth[r] = d
+					next = append(next, r)
+				}
+			}
+		}
+		frontier = next
+	}
+	return depth
+}
+
+// conceptLabels returns the sorted labels of polymorphic query results.
+func conceptLabels(t *testing.T, results []any) []string {
+	t.Helper()
+	var out []string
+	for _, r := range results {
+		switch c := r.(type) {
+		case *KGTopic:
+			out = append(out, c.Label)
+		case *KGTechnique:
+			out = append(out, c.Label)
+		case *KGAlgorithm:
+			out = append(out, c.Label)
+		default:
+			t.Fatalf("unexpected concept type %T", r)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestIntegration_Knowledge_AllInserted(t *testing.T) {
+	db := seedKnowledge(t)
+	ctx := context.Background()
+
+	// Queries on a type include its subtypes.
+	assertCount(t, ctx, gotype.MustNewManager[KGConcept](db), 8)
+	assertCount(t, ctx, gotype.MustNewManager[KGTopic](db), 3)
+	assertCount(t, ctx, gotype.MustNewManager[KGTechnique](db), 5)
+	assertCount(t, ctx, gotype.MustNewManager[KGAlgorithm](db), 3)
+
+	assertCount(t, ctx, gotype.MustNewManager[KGDocument](db), 5)
+	assertCount(t, ctx, gotype.MustNewManager[KGAuthor](db), 4)
+	assertCount(t, ctx, gotype.MustNewManager[KGAuthorship](db), 7)
+	assertCount(t, ctx, gotype.MustNewManager[KGCites](db), 7)
+	assertCount(t, ctx, gotype.MustNewManager[KGMentions](db), 8)
+	assertCount(t, ctx, gotype.MustNewManager[KGBroaderThan](db), 7)
+}
+
+func TestIntegration_Knowledge_AbstractConceptRejectsInsert(t *testing.T) {
+	db := setupKnowledgeDB(t)
+	ctx := context.Background()
+	mgr := gotype.MustNewManager[KGConcept](db)
+
+	if err := mgr.Insert(ctx, &KGConcept{Label: "orphan"}); err == nil {
+		t.Fatal("expected inserting an abstract concept to fail")
+	}
+	assertCount(t, ctx, mgr, 0)
+}
+
+func TestIntegration_Knowledge_TransitiveBroader(t *testing.T) {
+	db := seedKnowledge(t)
+	ctx := context.Background()
+
+	want := map[string]int{
+		"neural-network": 1, "gradient-descent": 1,
+		"machine-learning": 2, "optimization": 2,
+		"computer-science": 3, "mathematics": 3,
+	}
+	if got := conceptClosure(t, ctx, db, "backpropagation", true); !maps.Equal(got, want) {
+		t.Errorf("broader concepts of backpropagation = %v, want %v", got, want)
+	}
+	if got := conceptClosure(t, ctx, db, "computer-science", true); len(got) != 0 {
+		t.Errorf("computer-science is a root, got broader concepts %v", got)
+	}
+
+	want = map[string]int{"machine-learning": 1, "neural-network": 2, "decision-tree": 2, "backpropagation": 3}
+	if got := conceptClosure(t, ctx, db, "computer-science", false); !maps.Equal(got, want) {
+		t.Errorf("narrower concepts of computer-science = %v, want %v", got, want)
+	}
+
+	// Concepts reachable from both roots sit on the diamond.
+	fromCS := conceptClosure(t, ctx, db, "computer-science", false)
+	fromMath := conceptClosure(t, ctx, db, "mathematics", false)
+	var shared []string
+	for l := range fromCS {
+		if _, ok := fromMath[l]; ok {
+			shared = append(shared, l)
+		}
+	}
+	if !slices.Equal(shared, []string{"backpropagation"}) {
+		t.Errorf("concepts under both roots = %v, want [backpropagation]", shared)
+	}
+}
+
+func TestIntegration_Knowledge_DocumentsUnderTopic(t *testing.T) {
+	db := seedKnowledge(t)
+	ctx := context.Background()
+
+	// Documents mentioning machine learning or anything narrower.
+	labels := []any{"machine-learning"}
+	for l := range conceptClosure(t, ctx, db, "machine-learning", false) {
+		labels = append(labels, l)
+	}
+	mentions, err := gotype.MustNewManager[KGMentions](db).Query().
+		Filter(gotype.RolePlayer("mentioned", gotype.In("concept-label", labels))).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("query mentions: %v", err)
+	}
+	var titles []string
+	for _, m := range mentions {
+		titles = append(titles, m.Document.Title[:2])
+	}
+	slices.Sort(titles)
+	if got := slices.Compact(titles); !slices.Equal(got, []string{"D1", "D3", "D4", "D5"}) {
+		t.Errorf("documents under machine-learning = %v", got)
+	}
+
+	// Optimization reaches D2 only through gradient-descent.
+	got := fetchColumn(t, ctx, db, "title", `match
+$root isa concept, has concept-label "optimization";
+$b isa broader-than, links (broader: $root, narrower: $c);
+$m isa mentions, links (mentioning: $d, mentioned: $c);
+fetch { "title": $d.doc-title };`)
+	if len(got) != 1 || got[0][:2] != "D2" {
+		t.Errorf("documents one level under optimization = %v, want D2", got)
+	}
+}
+
+func TestIntegration_Knowledge_CoCitation(t *testing.T) {
+	db := seedKnowledge(t)
+	ctx := context.Background()
+
+	n, err := gotype.MustNewManager[KGCites](db).Query().
+		Filter(gotype.RolePlayer("cited", gotype.Startswith("doc-title", "D1"))).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("count citations of D1: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("D1 cited %d times, want 3", n)
+	}
+
+	// Two documents are co-cited once for every document citing both.
+	rows, err := db.ExecuteRead(ctx, `match
+$c1 isa cites, links (citing: $src, cited: $a);
+$c2 isa cites, links (citing: $src, cited: $b);
+$a has doc-title $ta;
+$b has doc-title $tb;
+$ta < $tb;
+fetch { "a": $a.doc-title, "b": $b.doc-title };`)
+	if err != nil {
+		t.Fatalf("co-citation: %v", err)
+	}
+	pairs := make(map[string]int)
+	for _, row := range rows {
+		pairs[fmt.Sprint(row["a"])[:2]+"+"+fmt.Sprint(row["b"])[:2]]++
+	}
+	want := map[string]int{"D1+D2": 2, "D1+D3": 1, "D2+D3": 1, "D1+D4": 1, "D2+D4": 1}
+	if !maps.Equal(pairs, want) {
+		t.Errorf("co-citation counts = %v, want %v", pairs, want)
+	}
+
+	// Bibliographic coupling: documents that cite a common source with D5.
+	got := fetchColumn(t, ctx, db, "title", `match
+$d5 isa document, has doc-title $t5;
+$t5 like "^D5 .*";
+$c1 isa cites, links (citing: $d5, cited: $shared);
+$c2 isa cites, links (citing: $other, cited: $shared);
+not { $other is $d5; };
+fetch { "title": $other.doc-title };`)
+	var coupled []string
+	for _, title := range got {
+		coupled = append(coupled, title[:2])
+	}
+	if !slices.Equal(coupled, []string{"D3", "D4"}) {
+		t.Errorf("documents coupled with D5 = %v, want [D3 D4]", coupled)
+	}
+}
+
+func TestIntegration_Knowledge_AuthorCitations(t *testing.T) {
+	db := seedKnowledge(t)
+	ctx := context.Background()
+
+	// Citations received by each author's documents; Hinton also cites himself.
+	rows, err := db.ExecuteRead(ctx, `match
+$aw isa authorship, links (author: $au, work: $d);
+$c isa cites, links (cited: $d);
+fetch { "author": $au.author-name };`)
+	if err != nil {
+		t.Fatalf("author citations: %v", err)
+	}
+	received := make(map[string]int)
+	for _, row := range rows {
+		received[fmt.Sprint(row["author"])]++
+	}
+	want := map[string]int{"Hinton": 4, "Robbins": 2, "Quinlan": 2}
+	if !maps.Equal(received, want) {
+		t.Errorf("citations received = %v, want %v", received, want)
+	}
+
+	got := fetchColumn(t, ctx, db, "title", `match
+$au isa author, has author-name "Hinton";
+$w1 isa authorship, links (author: $au, work: $citing);
+$w2 isa authorship, links (author: $au, work: $cited);
+$c isa cites, links (citing: $citing, cited: $cited);
+fetch { "title": $citing.doc-title };`)
+	if len(got) != 2 || got[0][:2] != "D4" || got[1][:2] != "D5" {
+		t.Errorf("Hinton's self-citing documents = %v, want D4 and D5", got)
+	}
+}
+
+func TestIntegration_Knowledge_PolymorphicConcepts(t *testing.T) {
+	db := seedKnowledge(t)
+	ctx := context.Background()
+
+	all, err := gotype.MustNewManager[KGConcept](db).QueryPolymorphic(ctx)
+	if err != nil {
+		t.Fatalf("polymorphic concepts: %v", err)
+	}
+	byType := make(map[string]int)
+	for _, c := range all {
+		byType[fmt.Sprintf("%T", c)]++
+	}
+	wantTypes := map[string]int{
+		"*gotype_test.KGTopic":     3,
+		"*gotype_test.KGTechnique": 2,
+		"*gotype_test.KGAlgorithm": 3,
+	}
+	if !maps.Equal(byType, wantTypes) {
+		t.Errorf("concept types = %v, want %v", byType, wantTypes)
+	}
+
+	// Subtype attributes are hydrated for direct subtypes of the queried type.
+	techniques, err := gotype.MustNewManager[KGTechnique](db).Query().
+		Filter(gotype.Eq("maturity", "classical")).
+		OrderAsc("concept-label").
+		ExecutePolymorphic(ctx)
+	if err != nil {
+		t.Fatalf("polymorphic techniques: %v", err)
+	}
+	if got := conceptLabels(t, techniques); !slices.Equal(got, []string{"decision-tree", "gradient-descent", "optimization"}) {
+		t.Fatalf("classical techniques = %v", got)
+	}
+	for _, r := range techniques {
+		switch c := r.(type) {
+		case *KGAlgorithm:
+			if c.Complexity == "" || c.Maturity != "classical" {
+				t.Errorf("algorithm %s not fully hydrated: %+v", c.Label, c)
+			}
+		case *KGTechnique:
+			if c.Label != "optimization" {
+				t.Errorf("unexpected plain technique %s", c.Label)
+			}
+		}
+	}
+
+	// Attribute filters apply across the whole hierarchy.
+	matched, err := gotype.MustNewManager[KGConcept](db).Query().
+		Filter(gotype.Like("concept-label", "^(neural|gradient).*")).
+		ExecutePolymorphic(ctx)
+	if err != nil {
+		t.Fatalf("polymorphic label filter: %v", err)
+	}
+	if len(matched) != 2 {
+		t.Fatalf("expected 2 concepts, got %d", len(matched))
+	}
+	for _, r := range matched {
+		switch c := r.(type) {
+		case *KGTechnique:
+			if c.Label != "neural-network" {
+				t.Errorf("technique %s, want neural-network", c.Label)
+			}
+		case *KGAlgorithm:
+			if c.Label != "gradient-descent" {
+				t.Errorf("algorithm %s, want gradient-descent", c.Label)
+			}
+		default:
+			t.Errorf("unexpected %T", r)
+		}
+	}
+
+	// The exact-type query does not see subtypes.
+	exact := fetchColumn(t, ctx, db, "label", `match
+$c isa! technique;
+fetch { "label": $c.concept-label };`)
+	if !slices.Equal(exact, []string{"neural-network", "optimization"}) {
+		t.Errorf("exact techniques = %v", exact)
+	}
+}
+
+func TestIntegration_Knowledge_LabelSearch(t *testing.T) {
+	db := seedKnowledge(t)
+	ctx := context.Background()
+	conceptMgr := gotype.MustNewManager[KGConcept](db)
+
+	for _, tc := range []struct {
+		pattern string
+		want    []string
+	}{
+		{"^machine.*", []string{"machine-learning"}},
+		{".*-(network|tree)$", []string{"decision-tree", "neural-network"}},
+		{".*ation$", []string{"backpropagation", "optimization"}},
+		{"^quantum.*", nil},
+	} {
+		results, err := conceptMgr.Query().Filter(gotype.Like("concept-label", tc.pattern)).Execute(ctx)
+		if err != nil {
+			t.Fatalf("like %q: %v", tc.pattern, err)
+		}
+		var got []string
+		for _, c := range results {
+			got = append(got, c.Label)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("like %q = %v, want %v", tc.pattern, got, tc.want)
+		}
+	}
+
+	// Like combines with other filters on the same query.
+	results, err := gotype.MustNewManager[KGTopic](db).Query().
+		Filter(gotype.Like("concept-label", ".*-.*"), gotype.Eq("discipline", "computing")).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("topic search: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected 2 hyphenated computing topics, got %d", len(results))
+	}
+
+	// Documents mentioning a concept whose label matches, heaviest first.
+	mentions, err := gotype.MustNewManager[KGMentions](db).Query().
+		Filter(gotype.RolePlayer("mentioned", gotype.Like("concept-label", "^(back|neural).*"))).
+		OrderDesc("mention-count").
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("mentions search: %v", err)
+	}
+	var counts []int
+	for _, m := range mentions {
+		counts = append(counts, m.Count)
+	}
+	if !slices.Equal(counts, []int{25, 12, 7, 4}) {
+		t.Errorf("mention counts = %v, want [25 12 7 4]", counts)
+	}
+
+	// Title search on documents.
+	titles, err := gotype.MustNewManager[KGDocument](db).Query().
+		Filter(gotype.Like("doc-title", ".*[Ll]earning.*")).
+		OrderAsc("published-year").
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("title search: %v", err)
+	}
+	var years []int
+	for _, d := range titles {
+		years = append(years, d.Year)
+	}
+	if !slices.Equal(years, []int{1986, 2010, 2015}) {
+		t.Errorf("learning titles by year = %v, want [1986 2010 2015]", years)
+	}
+}
@@ -0,0 +1,481 @@
+//go:build integration && cgo && typedb
+
+package gotype_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ---------------------------------------------------------------------------
+// Logistics domain models
+// ---------------------------------------------------------------------------
+
+type LogDepot struct {
+	gotype.BaseEntity
+	Code string `typedb:"depot-code,key"`
+	City string `typedb:"city"`
+}
+
+type LogVehicle struct {
+	gotype.BaseEntity
+	Plate    string  `typedb:"plate,key"`
+	Capacity float64 `typedb:"capacity-kg"`
+}
+
+type LogRoute struct {
+	gotype.BaseEntity
+	Code string `typedb:"route-code,key"`
+}
+
+type LogStop struct {
+	gotype.BaseEntity
+	Code    string `typedb:"stop-code,key"`
+	Address string `typedb:"address"`
+}
+
+type LogParcel struct {
+	gotype.BaseEntity
+	Tracking    string     `typedb:"tracking-number,key"`
+	Weight      float64    `typedb:"weight-kg"`
+	Status      string     `typedb:"parcel-status"`
+	DeliveredAt *time.Time `typedb:"delivered-at"`
+}
+
+// LogAssignedTo puts a vehicle on a route out of a depot.
+type LogAssignedTo struct {
+	gotype.BaseRelation
+	Vehicle *LogVehicle `typedb:"role:assigned-vehicle"`
+	Route   *LogRoute   `typedb:"role:assigned-route"`
+	Depot   *LogDepot   `typedb:"role:home-depot"`
+}
+
+type LogVisits struct {
+	gotype.BaseRelation
+	Route *LogRoute `typedb:"role:visiting-route"`
+	Stop  *LogStop  `typedb:"role:visited-stop"`
+	Order int       `typedb:"stop-order"`
+}
+
+// LogCarries loads a parcel on a vehicle for drop-off at a stop.
+type LogCarries struct {
+	gotype.BaseRelation
+	Vehicle *LogVehicle `typedb:"role:carrier"`
+	Parcel  *LogParcel  `typedb:"role:cargo"`
+	DropOff *LogStop    `typedb:"role:drop-off"`
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+func setupLogisticsDB(t *testing.T) *gotype.Database {
+	return setupTestDBWith(t, func() {
+		_ = gotype.Register[LogDepot](gotype.WithTypeName("depot"))
+		_ = gotype.Register[LogVehicle](gotype.WithTypeName("vehicle"))
+		_ = gotype.Register[LogRoute](gotype.WithTypeName("route"))
+		_ = gotype.Register[LogStop](gotype.WithTypeName("stop"))
+		_ = gotype.Register[LogParcel](gotype.WithTypeName("parcel"))
+		_ = gotype.Register[LogAssignedTo](gotype.WithTypeName("assigned-to"))
+		_ = gotype.Register[LogVisits](gotype.WithTypeName("visits"))
+		_ = gotype.Register[LogCarries](gotype.WithTypeName("carries"))
+	})
+}
+
+// seedLogistics loads two depots, four vehicles and three routes:
+//
+//	R1  D-NORTH  VAN-1    S1 S2 S3   P01 P02 P03 P04  (550 of 500 kg)
+//	R2  D-NORTH  TRUCK-1  S4 S5      P05 P06          (1300 of 2000 kg)
+//	R3  D-SOUTH  VAN-2    S6 S7      P07 P08          (190 of 300 kg)
+//
+// VAN-3 is idle. Carried parcels are "loaded"; P09 and P10 are "pending"
+// and not on any vehicle.
+func seedLogistics(t *testing.T) *gotype.Database {
+	t.Helper()
+	db := setupLogisticsDB(t)
+	ctx := context.Background()
+
+	depotMgr := gotype.MustNewManager[LogDepot](db)
+	depots := make(map[string]*LogDepot)
+	for _, d := range []*LogDepot{{Code: "D-NORTH", City: "Oslo"}, {Code: "D-SOUTH", City: "Bergen"}} {
+		depots[d.Code] = insertAndGet(t, ctx, depotMgr, d, "depot-code", d.Code)
+	}
+
+	vehicleMgr := gotype.MustNewManager[LogVehicle](db)
+	vehicles := make(map[string]*LogVehicle)
+	for _, v := range []*LogVehicle{
+		{Plate: "VAN-1", Capacity: 500},
+		{Plate: "VAN-2", Capacity: 300},
+		{Plate: "VAN-3", Capacity: 400},
+		{Plate: "TRUCK-1", Capacity: 2000},
+	} {
+		vehicles[v.Plate] = insertAndGet(t, ctx, vehicleMgr, v, "plate", v.Plate)
+	}
+
+	stopMgr := gotype.MustNewManager[LogStop](db)
+	stops := make(map[string]*LogStop)
+	for i := 1; i <= 7; i++ {
+		code := fmt.Sprintf("S%d", i)
+		stops[code] = insertAndGet(t, ctx, stopMgr, &LogStop{Code: code, Address: fmt.Sprintf("%d Harbour Road", i*10)}, "stop-code", code)
+	}
+
+	routeMgr := gotype.MustNewManager[LogRoute](db)
+	assignedMgr := gotype.MustNewManager[LogAssignedTo](db)
+	visitsMgr := gotype.MustNewManager[LogVisits](db)
+	for _, r := range []struct {
+		code, depot, vehicle string
+		stops                []string
+	}{
+		{"R1", "D-NORTH", "VAN-1", []string{"S1", "S2", "S3"}},
+		{"R2", "D-NORTH", "TRUCK-1", []string{"S4", "S5"}},
+		{"R3", "D-SOUTH", "VAN-2", []string{"S6", "S7"}},
+	} {
+		route := insertAndGet(t, ctx, routeMgr, &LogRoute{Code: r.code}, "route-code", r.code)
+		assertInsert(t, ctx, assignedMgr, &LogAssignedTo{Vehicle: vehicles[r.vehicle], Route: route, Depot: depots[r.depot]})
+		for i, s := range r.stops {
+			assertInsert(t, ctx, visitsMgr, &LogVisits{Route: route, Stop: stops[s], Order: i + 1})
+		}
+	}
+
+	parcelMgr := gotype.MustNewManager[LogParcel](db)
+	carriesMgr := gotype.MustNewManager[LogCarries](db)
+	for _, p := range []struct {
+		tracking      string
+		weight        float64
+		stop, vehicle string
+	}{
+		{"P01", 120, "S1", "VAN-1"},
+		{"P02", 80, "S2", "VAN-1"},
+		{"P03", 150, "S3", "VAN-1"},
+		{"P04", 200, "S3", "VAN-1"},
+		{"P05", 900, "S4", "TRUCK-1"},
+		{"P06", 400, "S5", "TRUCK-1"},
+		{"P07", 100, "S6", "VAN-2"},
+		{"P08", 90, "S7", "VAN-2"},
+		{"P09", 60, "", ""},
+		{"P10", 30, "", ""},
+	} {
+		status := "loaded"
+		if p.vehicle == "" {
+			status = "pending"
+		}
+		parcel := insertAndGet(t, ctx, parcelMgr, &LogParcel{Tracking: p.tracking, Weight: p.weight, Status: status}, "tracking-number", p.tracking)
+		if p.vehicle != "" {
+			assertInsert(t, ctx, carriesMgr, &LogCarries{Vehicle: vehicles[p.vehicle], Parcel: parcel, DropOff: stops[p.stop]})
+		}
+	}
+
+	return db
+}
+
+// routeManifest returns "parcel@stop" for everything carried on a route, in
+// stop order.
+func routeManifest(t *testing.T, ctx context.Context, db *gotype.Database, route string) []string {
+	t.Helper()
+	rows, err := db.ExecuteRead(ctx, fmt.Sprintf(`match
+$r isa route, has route-code %q;
+$a isa assigned-to, links (assigned-route: $r, assigned-vehicle: $v);
+$c isa carries, links (carrier: $v, cargo: $p, drop-off: $s);
+$vi isa visits, links (visiting-route: $r, visited-stop: $s), has stop-order $o;
+$p has tracking-number $tn;
+sort $o, $tn;
+fetch { "parcel": $tn, "stop": $s.stop-code };`, route))
+	if err != nil {
+		t.Fatalf("manifest of %s: %v", route, err)
+	}
+	var out []string
+	for _, row := range rows {
+		out = append(out, fmt.Sprintf("%v@%v", row["parcel"], row["stop"]))
+	}
+	return out
+}
+
+// parcelStatuses returns the parcel count per status.
+func parcelStatuses(t *testing.T, ctx context.Context, db *gotype.Database) map[string]float64 {
+	t.Helper()
+	groups, err := gotype.MustNewManager[LogParcel](db).Query().
+		GroupBy("parcel-status").
+		Aggregate(ctx, gotype.AggregateSpec{Attr: "tracking-number", Fn: "count"})
+	if err != nil {
+		t.Fatalf("parcels by status: %v", err)
+	}
+	out := make(map[string]float64, len(groups))
+	for status, aggs := range groups {
+		out[status] = aggs["count_tracking-number"]
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestIntegration_Logistics_AllInserted(t *testing.T) {
+	db := seedLogistics(t)
+	ctx := context.Background()
+
+	assertCount(t, ctx, gotype.MustNewManager[LogDepot](db), 2)
+	assertCount(t, ctx, gotype.MustNewManager[LogVehicle](db), 4)
+	assertCount(t, ctx, gotype.MustNewManager[LogRoute](db), 3)
+	assertCount(t, ctx, gotype.MustNewManager[LogStop](db), 7)
+	assertCount(t, ctx, gotype.MustNewManager[LogParcel](db), 10)
+	assertCount(t, ctx, gotype.MustNewManager[LogAssignedTo](db), 3)
+	assertCount(t, ctx, gotype.MustNewManager[LogVisits](db), 7)
+	assertCount(t, ctx, gotype.MustNewManager[LogCarries](db), 8)
+
+	// Ternary relations hydrate all three players.
+	assignments, err := gotype.MustNewManager[LogAssignedTo](db).Query().
+		Filter(gotype.RolePlayer("home-depot", gotype.Eq("depot-code", "D-NORTH"))).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("north assignments: %v", err)
+	}
+	var got []string
+	for _, a := range assignments {
+		got = append(got, a.Route.Code+"/"+a.Vehicle.Plate+"/"+a.Depot.City)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"R1/VAN-1/Oslo", "R2/TRUCK-1/Oslo"}) {
+		t.Errorf("north assignments = %v", got)
+	}
+}
+
+func TestIntegration_Logistics_RouteToParcels(t *testing.T) {
+	db := seedLogistics(t)
+	ctx := context.Background()
+
+	for route, want := range map[string][]string{
+		"R1": {"P01@S1", "P02@S2", "P03@S3", "P04@S3"},
+		"R2": {"P05@S4", "P06@S5"},
+		"R3": {"P07@S6", "P08@S7"},
+	} {
+		if got := routeManifest(t, ctx, db, route); !slices.Equal(got, want) {
+			t.Errorf("manifest of %s = %v, want %v", route, got, want)
+		}
+	}
+
+	// Everything leaving a depot, across its routes.
+	got := fetchColumn(t, ctx, db, "parcel", `match
+$d isa depot, has depot-code "D-NORTH";
+$a isa assigned-to, links (home-depot: $d, assigned-vehicle: $v);
+$c isa carries, links (carrier: $v, cargo: $p);
+fetch { "parcel": $p.tracking-number };`)
+	if !slices.Equal(got, []string{"P01", "P02", "P03", "P04", "P05", "P06"}) {
+		t.Errorf("parcels out of D-NORTH = %v", got)
+	}
+
+	// No parcel is dropped at a stop its vehicle's route does not visit.
+	stray := fetchColumn(t, ctx, db, "parcel", `match
+$a isa assigned-to, links (assigned-route: $r, assigned-vehicle: $v);
+$c isa carries, links (carrier: $v, cargo: $p, drop-off: $s);
+not { $vi isa visits, links (visiting-route: $r, visited-stop: $s); };
+fetch { "parcel": $p.tracking-number };`)
+	if len(stray) != 0 {
+		t.Errorf("parcels off-route = %v", stray)
+	}
+
+	// Stop-order filters on the visits relation.
+	last, err := gotype.MustNewManager[LogVisits](db).Query().
+		Filter(gotype.RolePlayer("visiting-route", gotype.Eq("route-code", "R1"))).
+		OrderDesc("stop-order").
+		First(ctx)
+	if err != nil {
+		t.Fatalf("last stop of R1: %v", err)
+	}
+	if last == nil || last.Stop.Code != "S3" || last.Order != 3 {
+		t.Errorf("last stop of R1 = %+v, want S3 at 3", last)
+	}
+}
+
+func TestIntegration_Logistics_VehicleLoad(t *testing.T) {
+	db := seedLogistics(t)
+	ctx := context.Background()
+
+	rows, err := db.ExecuteRead(ctx, `match
+$c isa carries, links (carrier: $v, cargo: $p);
+fetch { "plate": $v.plate, "capacity": $v.capacity-kg, "weight": $p.weight-kg };`)
+	if err != nil {
+		t.Fatalf("vehicle loads: %v", err)
+	}
+	load := make(map[string]float64)
+	capacity := make(map[string]float64)
+	for _, row := range rows {
+		plate := fmt.Sprint(row["plate"])
+		load[plate] += fetchedNumber(t, row["weight"])
+		capacity[plate] = fetchedNumber(t, row["capacity"])
+	}
+	if !maps.Equal(load, map[string]float64{"VAN-1": 550, "TRUCK-1": 1300, "VAN-2": 190}) {
+		t.Errorf("load per vehicle = %v", load)
+	}
+	var overloaded []string
+	for plate, kg := range load {
+		if kg > capacity[plate] {
+			overloaded = append(overloaded, plate)
+		}
+	}
+	if !slices.Equal(overloaded, []string{"VAN-1"}) {
+		t.Errorf("overloaded vehicles = %v, want [VAN-1]", overloaded)
+	}
+
+	// Totals per status agree with the per-vehicle sums.
+	byStatus, err := gotype.MustNewManager[LogParcel](db).Query().GroupBy("parcel-status").Aggregate(ctx,
+		gotype.AggregateSpec{Attr: "tracking-number", Fn: "count"},
+		gotype.AggregateSpec{Attr: "weight-kg", Fn: "sum"},
+		gotype.AggregateSpec{Attr: "weight-kg", Fn: "max"},
+	)
+	if err != nil {
+		t.Fatalf("parcels by status: %v", err)
+	}
+	if got := byStatus["loaded"]; got["count_tracking-number"] != 8 || got["sum_weight-kg"] != 2040 || got["max_weight-kg"] != 900 {
+		t.Errorf("loaded = %v, want 8 parcels, 2040 kg, heaviest 900", got)
+	}
+	if got := byStatus["pending"]; got["count_tracking-number"] != 2 || got["sum_weight-kg"] != 90 {
+		t.Errorf("pending = %v, want 2 parcels, 90 kg", got)
+	}
+
+	// Idle vehicles have no assignment.
+	idle := fetchColumn(t, ctx, db, "plate", `match
+$v isa vehicle;
+not { $a isa assigned-to, links (assigned-vehicle: $v); };
+fetch { "plate": $v.plate };`)
+	if !slices.Equal(idle, []string{"VAN-3"}) {
+		t.Errorf("idle vehicles = %v, want [VAN-3]", idle)
+	}
+}
+
+func TestIntegration_Logistics_Undelivered(t *testing.T) {
+	db := seedLogistics(t)
+	ctx := context.Background()
+	parcelMgr := gotype.MustNewManager[LogParcel](db)
+
+	// Parcels no vehicle carries.
+	unassigned := fetchColumn(t, ctx, db, "parcel", `match
+$p isa parcel;
+not { $c isa carries, links (cargo: $p); };
+fetch { "parcel": $p.tracking-number };`)
+	if !slices.Equal(unassigned, []string{"P09", "P10"}) {
+		t.Errorf("unassigned parcels = %v, want [P09 P10]", unassigned)
+	}
+
+	n, err := parcelMgr.Query().Filter(gotype.NotHasAttr("delivered-at")).Count(ctx)
+	if err != nil {
+		t.Fatalf("count undelivered: %v", err)
+	}
+	if n != 10 {
+		t.Errorf("undelivered before any drop-off = %d, want 10", n)
+	}
+
+	// Deliver everything for S1 and S3 and record the time.
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	delivered, err := parcelMgr.Query().
+		Filter(gotype.In("tracking-number", []any{"P01", "P03", "P04"})).
+		Update(ctx, map[string]any{"parcel-status": "delivered", "delivered-at": now})
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if delivered != 3 {
+		t.Errorf("delivered %d parcels, want 3", delivered)
+	}
+
+	// Stops on R1 still waiting for a parcel.
+	waiting := fetchColumn(t, ctx, db, "stop", `match
+$r isa route, has route-code "R1";
+$vi isa visits, links (visiting-route: $r, visited-stop: $s);
+$c isa carries, links (cargo: $p, drop-off: $s);
+not { $p has delivered-at $d; };
+fetch { "stop": $s.stop-code };`)
+	if !slices.Equal(waiting, []string{"S2"}) {
+		t.Errorf("R1 stops still waiting = %v, want [S2]", waiting)
+	}
+
+	// Stops with nothing left to drop off, across all routes.
+	done := fetchColumn(t, ctx, db, "stop", `match
+$s isa stop;
+not {
+  $c isa carries, links (cargo: $p, drop-off: $s);
+  not { $p has parcel-status "delivered"; };
+};
+fetch { "stop": $s.stop-code };`)
+	if !slices.Equal(done, []string{"S1", "S3"}) {
+		t.Errorf("completed stops = %v, want [S1 S3]", done)
+	}
+
+	remaining, err := parcelMgr.Query().
+		Filter(gotype.NotHasAttr("delivered-at")).
+		OrderAsc("tracking-number").
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("undelivered: %v", err)
+	}
+	var got []string
+	for _, p := range remaining {
+		got = append(got, p.Tracking)
+	}
+	if !slices.Equal(got, []string{"P02", "P05", "P06", "P07", "P08", "P09", "P10"}) {
+		t.Errorf("undelivered parcels = %v", got)
+	}
+	p := assertGetOne(t, ctx, parcelMgr, map[string]any{"tracking-number": "P03"})
+	if p.DeliveredAt == nil || !p.DeliveredAt.Equal(now) {
+		t.Errorf("P03 delivered at %v, want %v", p.DeliveredAt, now)
+	}
+}
+
+func TestIntegration_Logistics_BulkStatusTransitions(t *testing.T) {
+	db := seedLogistics(t)
+	ctx := context.Background()
+	parcelMgr := gotype.MustNewManager[LogParcel](db)
+
+	transition := func(from, to string, extra ...gotype.Filter) int64 {
+		t.Helper()
+		n, err := parcelMgr.Query().
+			Filter(append([]gotype.Filter{gotype.Eq("parcel-status", from)}, extra...)...).
+			Update(ctx, map[string]any{"parcel-status": to})
+		if err != nil {
+			t.Fatalf("%s -> %s: %v", from, to, err)
+		}
+		return n
+	}
+
+	if n := transition("loaded", "in-transit"); n != 8 {
+		t.Errorf("dispatched %d parcels, want 8", n)
+	}
+	if got := parcelStatuses(t, ctx, db); !maps.Equal(got, map[string]float64{"in-transit": 8, "pending": 2}) {
+		t.Errorf("after dispatch = %v", got)
+	}
+
+	// Heavy parcels are held back at the depot.
+	if n := transition("in-transit", "held", gotype.Gte("weight-kg", 400)); n != 2 {
+		t.Errorf("held %d heavy parcels, want 2", n)
+	}
+	// Nothing is in a status that does not exist.
+	if n := transition("lost", "found"); n != 0 {
+		t.Errorf("transitioned %d lost parcels, want 0", n)
+	}
+	// Pending parcels light enough for VAN-3 are loaded.
+	if n := transition("pending", "loaded", gotype.Lt("weight-kg", 50)); n != 1 {
+		t.Errorf("loaded %d pending parcels, want 1", n)
+	}
+
+	want := map[string]float64{"in-transit": 6, "held": 2, "pending": 1, "loaded": 1}
+	if got := parcelStatuses(t, ctx, db); !maps.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	held, err := parcelMgr.Query().Filter(gotype.Eq("parcel-status", "held")).Execute(ctx)
+	if err != nil {
+		t.Fatalf("held parcels: %v", err)
+	}
+	var got []string
+	for _, p := range held {
+		got = append(got, p.Tracking)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"P05", "P06"}) {
+		t.Errorf("held parcels = %v, want [P05 P06]", got)
+	}
+}
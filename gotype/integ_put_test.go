@@ -82,10 +82,28 @@ func TestIntegration_PutMany(t *testing.T) {
 		{Name: "PM2", Email: "pm2@test.com"},
 		{Name: "PM3", Email: "pm3@test.com"},
 	}
-	if err := mgr.PutMany(ctx, persons); err != nil {
+	outcomes, err := mgr.PutMany(ctx, persons)
+	if err != nil {
 		t.Fatalf("PutMany failed: %v", err)
 	}
 
+	assertCount(t, ctx, mgr, 3)
+	for i, o := range outcomes {
+		if o.Status != gotype.PutCreated {
+			t.Errorf("outcomes[%d] = %v, want created", i, o.Status)
+		}
+	}
+
+	// Putting the same instances again finds them all.
+	outcomes, err = mgr.PutMany(ctx, persons)
+	if err != nil {
+		t.Fatalf("second PutMany failed: %v", err)
+	}
+	for i, o := range outcomes {
+		if o.Status != gotype.PutExisted || o.IID != persons[i].GetIID() {
+			t.Errorf("outcomes[%d] = %+v, want existed", i, o)
+		}
+	}
 	assertCount(t, ctx, mgr, 3)
 
 	// Verify IIDs were populated
@@ -101,7 +119,7 @@ func TestIntegration_PutMany_Empty(t *testing.T) {
 	ctx := context.Background()
 	mgr := gotype.MustNewManager[Person](db)
 
-	if err := mgr.PutMany(ctx, nil); err != nil {
+	if _, err := mgr.PutMany(ctx, nil); err != nil {
 		t.Fatalf("PutMany empty should succeed: %v", err)
 	}
 	assertCount(t, ctx, mgr, 0)
@@ -0,0 +1,405 @@
+//go:build integration && cgo && typedb
+
+package gotype_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ---------------------------------------------------------------------------
+// RBAC domain models
+//
+// Unlike the flat IAM suite, groups nest (subgroup-of), grants carry an
+// allow or deny effect, and permissions protect resources:
+//
+//	user -member-of-> group -subgroup-of->* group -assigned-> role
+//	role -grants(effect)-> permission -protects-> resource
+// ---------------------------------------------------------------------------
+
+type RBACUser struct {
+	gotype.BaseEntity
+	Username string `typedb:"username,key"`
+}
+
+type RBACGroup struct {
+	gotype.BaseEntity
+	Name string `typedb:"group-name,key"`
+}
+
+type RBACRole struct {
+	gotype.BaseEntity
+	Name string `typedb:"role-name,key"`
+}
+
+type RBACPermission struct {
+	gotype.BaseEntity
+	Name   string `typedb:"perm-name,key"`
+	Action string `typedb:"action"`
+}
+
+type RBACResource struct {
+	gotype.BaseEntity
+	Name string `typedb:"resource-name,key"`
+}
+
+type RBACMemberOf struct {
+	gotype.BaseRelation
+	Member *RBACUser  `typedb:"role:member"`
+	Group  *RBACGroup `typedb:"role:group"`
+}
+
+type RBACSubgroupOf struct {
+	gotype.BaseRelation
+	Child  *RBACGroup `typedb:"role:child"`
+	Parent *RBACGroup `typedb:"role:parent"`
+}
+
+type RBACAssigned struct {
+	gotype.BaseRelation
+	Holder *RBACGroup `typedb:"role:holder"`
+	Role   *RBACRole  `typedb:"role:assigned-role"`
+}
+
+type RBACGrants struct {
+	gotype.BaseRelation
+	Grantee    *RBACRole       `typedb:"role:grantee"`
+	Permission *RBACPermission `typedb:"role:permission"`
+	Effect     string          `typedb:"effect"` // allow or deny
+}
+
+type RBACProtects struct {
+	gotype.BaseRelation
+	Guard    *RBACPermission `typedb:"role:guard"`
+	Resource *RBACResource   `typedb:"role:protected"`
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+func setupRBACDB(t *testing.T) *gotype.Database {
+	return setupTestDBWith(t, func() {
+		_ = gotype.Register[RBACUser](gotype.WithTypeName("user"))
+		_ = gotype.Register[RBACGroup](gotype.WithTypeName("user-group"))
+		_ = gotype.Register[RBACRole](gotype.WithTypeName("access-role"))
+		_ = gotype.Register[RBACPermission](gotype.WithTypeName("permission"))
+		_ = gotype.Register[RBACResource](gotype.WithTypeName("resource"))
+		_ = gotype.Register[RBACMemberOf](gotype.WithTypeName("member-of"))
+		_ = gotype.Register[RBACSubgroupOf](gotype.WithTypeName("subgroup-of"))
+		_ = gotype.Register[RBACAssigned](gotype.WithTypeName("assigned"))
+		_ = gotype.Register[RBACGrants](gotype.WithTypeName("grants"))
+		_ = gotype.Register[RBACProtects](gotype.WithTypeName("protects"))
+	})
+}
+
+// seedRBAC loads:
+//
+//	groups:      payments -> backend -> engineering;  contractors
+//	members:     ana payments, ben backend, cy engineering, dee contractors
+//	assigned:    engineering reader, backend deployer, payments billing,
+//	             contractors reader + restricted
+//	grants:      reader allow repo:read, prod:read, billing:read
+//	             deployer allow prod:deploy
+//	             billing allow billing:read
+//	             restricted deny billing:read
+//	protects:    repo:read repo, prod:read + prod:deploy prod-cluster,
+//	             billing:read billing-db
+//
+// Engineering's reader role also grants billing:read, so everyone in the
+// engineering tree can read billing; contractors are denied it.
+func seedRBAC(t *testing.T) *gotype.Database {
+	t.Helper()
+	db := setupRBACDB(t)
+	ctx := context.Background()
+
+	users := make(map[string]*RBACUser)
+	userMgr := gotype.MustNewManager[RBACUser](db)
+	for _, name := range []string{"ana", "ben", "cy", "dee"} {
+		users[name] = insertAndGet(t, ctx, userMgr, &RBACUser{Username: name}, "username", name)
+	}
+	groups := make(map[string]*RBACGroup)
+	groupMgr := gotype.MustNewManager[RBACGroup](db)
+	for _, name := range []string{"engineering", "backend", "payments", "contractors"} {
+		groups[name] = insertAndGet(t, ctx, groupMgr, &RBACGroup{Name: name}, "group-name", name)
+	}
+	roles := make(map[string]*RBACRole)
+	roleMgr := gotype.MustNewManager[RBACRole](db)
+	for _, name := range []string{"reader", "deployer", "billing", "restricted"} {
+		roles[name] = insertAndGet(t, ctx, roleMgr, &RBACRole{Name: name}, "role-name", name)
+	}
+	perms := make(map[string]*RBACPermission)
+	permMgr := gotype.MustNewManager[RBACPermission](db)
+	for _, p := range []*RBACPermission{
+		{Name: "repo:read", Action: "read"},
+		{Name: "prod:read", Action: "read"},
+		{Name: "prod:deploy", Action: "deploy"},
+		{Name: "billing:read", Action: "read"},
+	} {
+		perms[p.Name] = insertAndGet(t, ctx, permMgr, p, "perm-name", p.Name)
+	}
+	resources := make(map[string]*RBACResource)
+	resourceMgr := gotype.MustNewManager[RBACResource](db)
+	for _, name := range []string{"repo", "prod-cluster", "billing-db"} {
+		resources[name] = insertAndGet(t, ctx, resourceMgr, &RBACResource{Name: name}, "resource-name", name)
+	}
+
+	memberMgr := gotype.MustNewManager[RBACMemberOf](db)
+	for user, group := range map[string]string{"ana": "payments", "ben": "backend", "cy": "engineering", "dee": "contractors"} {
+		assertInsert(t, ctx, memberMgr, &RBACMemberOf{Member: users[user], Group: groups[group]})
+	}
+	subgroupMgr := gotype.MustNewManager[RBACSubgroupOf](db)
+	assertInsert(t, ctx, subgroupMgr, &RBACSubgroupOf{Child: groups["payments"], Parent: groups["backend"]})
+	assertInsert(t, ctx, subgroupMgr, &RBACSubgroupOf{Child: groups["backend"], Parent: groups["engineering"]})
+
+	assignedMgr := gotype.MustNewManager[RBACAssigned](db)
+	for _, a := range []struct{ group, role string }{
+		{"engineering", "reader"},
+		{"backend", "deployer"},
+		{"payments", "billing"},
+		{"contractors", "reader"},
+		{"contractors", "restricted"},
+	} {
+		assertInsert(t, ctx, assignedMgr, &RBACAssigned{Holder: groups[a.group], Role: roles[a.role]})
+	}
+
+	grantsMgr := gotype.MustNewManager[RBACGrants](db)
+	for _, g := range []struct{ role, perm, effect string }{
+		{"reader", "repo:read", "allow"},
+		{"reader", "prod:read", "allow"},
+		{"reader", "billing:read", "allow"},
+		{"deployer", "prod:deploy", "allow"},
+		{"billing", "billing:read", "allow"},
+		{"restricted", "billing:read", "deny"},
+	} {
+		assertInsert(t, ctx, grantsMgr, &RBACGrants{Grantee: roles[g.role], Permission: perms[g.perm], Effect: g.effect})
+	}
+
+	protectsMgr := gotype.MustNewManager[RBACProtects](db)
+	for perm, resource := range map[string]string{
+		"repo:read":    "repo",
+		"prod:read":    "prod-cluster",
+		"prod:deploy":  "prod-cluster",
+		"billing:read": "billing-db",
+	} {
+		assertInsert(t, ctx, protectsMgr, &RBACProtects{Guard: perms[perm], Resource: resources[resource]})
+	}
+
+	return db
+}
+
+// rbacGroupsOf returns the groups user belongs to, directly or through any
+// number of subgroup-of links.
+func rbacGroupsOf(t *testing.T, ctx context.Context, db *gotype.Database, user string) []string {
+	t.Helper()
+	frontier := fetchColumn(t, ctx, db, "group", fmt.Sprintf(`match
+$u isa user, has username %q;
+$m isa member-of, links (member: $u, group: $g);
+fetch { "group": $g.group-name };`, user))
+	seen := make(map[string]bool)
+	for len(frontier) > 0 {
+		g := frontier[0]
+		frontier = frontier[1:]
+		if seen[g] {
+			continue
+		}
+		seen[g] = true
+		frontier = append(frontier, fetchColumn(t, ctx, db, "parent", fmt.Sprintf(`match
+$c isa user-group, has group-name %q;
+$s isa subgroup-of, links (child: $c, parent: $p);
+fetch { "parent": $p.group-name };`, g))...)
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// rbacEffective resolves the permissions user holds: everything allowed to
+// a role of one of their groups, minus everything denied to any of them.
+func rbacEffective(t *testing.T, ctx context.Context, db *gotype.Database, user string) []string {
+	t.Helper()
+	effect := func(group, effect string) []string {
+		return fetchColumn(t, ctx, db, "perm", fmt.Sprintf(`match
+$g isa user-group, has group-name %q;
+$a isa assigned, links (holder: $g, assigned-role: $r);
+$gr isa grants, links (grantee: $r, permission: $p), has effect %q;
+fetch { "perm": $p.perm-name };`, group, effect))
+	}
+	allowed := make(map[string]bool)
+	denied := make(map[string]bool)
+	for _, g := range rbacGroupsOf(t, ctx, db, user) {
+		for _, p := range effect(g, "allow") {
+			allowed[p] = true
+		}
+		for _, p := range effect(g, "deny") {
+			denied[p] = true
+		}
+	}
+	maps.DeleteFunc(allowed, func(p string, _ bool) bool { return denied[p] })
+	return slices.Sorted(maps.Keys(allowed))
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestIntegration_RBAC_AllInserted(t *testing.T) {
+	db := seedRBAC(t)
+	ctx := context.Background()
+
+	assertCount(t, ctx, gotype.MustNewManager[RBACUser](db), 4)
+	assertCount(t, ctx, gotype.MustNewManager[RBACGroup](db), 4)
+	assertCount(t, ctx, gotype.MustNewManager[RBACMemberOf](db), 4)
+	assertCount(t, ctx, gotype.MustNewManager[RBACSubgroupOf](db), 2)
+	assertCount(t, ctx, gotype.MustNewManager[RBACAssigned](db), 5)
+	assertCount(t, ctx, gotype.MustNewManager[RBACGrants](db), 6)
+	assertCount(t, ctx, gotype.MustNewManager[RBACProtects](db), 4)
+
+	denies, err := gotype.MustNewManager[RBACGrants](db).Query().
+		Filter(gotype.Eq("effect", "deny")).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("count denies: %v", err)
+	}
+	if denies != 1 {
+		t.Errorf("expected 1 deny grant, got %d", denies)
+	}
+}
+
+func TestIntegration_RBAC_GroupInheritanceChain(t *testing.T) {
+	db := seedRBAC(t)
+	ctx := context.Background()
+
+	// The two-hop chain spelled out in one query.
+	top := fetchColumn(t, ctx, db, "group", `match
+$u isa user, has username "ana";
+$m isa member-of, links (member: $u, group: $g0);
+$s1 isa subgroup-of, links (child: $g0, parent: $g1);
+$s2 isa subgroup-of, links (child: $g1, parent: $g2);
+fetch { "group": $g2.group-name };`)
+	if !slices.Equal(top, []string{"engineering"}) {
+		t.Errorf("ana's grandparent group = %v, want [engineering]", top)
+	}
+
+	for user, want := range map[string][]string{
+		"ana": {"backend", "engineering", "payments"},
+		"ben": {"backend", "engineering"},
+		"cy":  {"engineering"},
+		"dee": {"contractors"},
+	} {
+		if got := rbacGroupsOf(t, ctx, db, user); !slices.Equal(got, want) {
+			t.Errorf("groups of %s = %v, want %v", user, got, want)
+		}
+	}
+}
+
+func TestIntegration_RBAC_EffectivePermissions(t *testing.T) {
+	db := seedRBAC(t)
+	ctx := context.Background()
+
+	for user, want := range map[string][]string{
+		// payments billing + backend deployer + engineering reader
+		"ana": {"billing:read", "prod:deploy", "prod:read", "repo:read"},
+		"ben": {"billing:read", "prod:deploy", "prod:read", "repo:read"},
+		"cy":  {"billing:read", "prod:read", "repo:read"},
+		// reader allows billing:read, restricted denies it
+		"dee": {"prod:read", "repo:read"},
+	} {
+		if got := rbacEffective(t, ctx, db, user); !slices.Equal(got, want) {
+			t.Errorf("effective permissions of %s = %v, want %v", user, got, want)
+		}
+	}
+}
+
+func TestIntegration_RBAC_NegativeGrants(t *testing.T) {
+	db := seedRBAC(t)
+	ctx := context.Background()
+
+	// Allowed through a directly held role, unless another role of the
+	// same group denies it.
+	got := fetchColumn(t, ctx, db, "perm", `match
+$u isa user, has username "dee";
+$m isa member-of, links (member: $u, group: $g);
+$a isa assigned, links (holder: $g, assigned-role: $r);
+$gr isa grants, links (grantee: $r, permission: $p), has effect "allow";
+not {
+  $a2 isa assigned, links (holder: $g, assigned-role: $r2);
+  $d isa grants, links (grantee: $r2, permission: $p), has effect "deny";
+};
+fetch { "perm": $p.perm-name };`)
+	if !slices.Equal(got, []string{"prod:read", "repo:read"}) {
+		t.Errorf("dee's allowed permissions = %v, want [prod:read repo:read]", got)
+	}
+
+	// Groups with no deny grant at all.
+	clean := fetchColumn(t, ctx, db, "group", `match
+$g isa user-group;
+not {
+  $a isa assigned, links (holder: $g, assigned-role: $r);
+  $d isa grants, links (grantee: $r), has effect "deny";
+};
+fetch { "group": $g.group-name };`)
+	if !slices.Equal(clean, []string{"backend", "engineering", "payments"}) {
+		t.Errorf("groups without denies = %v", clean)
+	}
+}
+
+func TestIntegration_RBAC_WhoCanAccess(t *testing.T) {
+	db := seedRBAC(t)
+	ctx := context.Background()
+
+	guards := func(resource string) []string {
+		return fetchColumn(t, ctx, db, "perm", fmt.Sprintf(`match
+$res isa resource, has resource-name %q;
+$pr isa protects, links (guard: $p, protected: $res);
+fetch { "perm": $p.perm-name };`, resource))
+	}
+	whoCan := func(resource string) []string {
+		needed := guards(resource)
+		users := fetchColumn(t, ctx, db, "user", `match $u isa user; fetch { "user": $u.username };`)
+		var out []string
+		for _, u := range users {
+			for _, p := range rbacEffective(t, ctx, db, u) {
+				if slices.Contains(needed, p) {
+					out = append(out, u)
+					break
+				}
+			}
+		}
+		return out
+	}
+
+	if got := guards("prod-cluster"); !slices.Equal(got, []string{"prod:deploy", "prod:read"}) {
+		t.Errorf("prod-cluster guards = %v", got)
+	}
+	for resource, want := range map[string][]string{
+		"billing-db":   {"ana", "ben", "cy"},
+		"prod-cluster": {"ana", "ben", "cy", "dee"},
+	} {
+		if got := whoCan(resource); !slices.Equal(got, want) {
+			t.Errorf("who can access %s = %v, want %v", resource, got, want)
+		}
+	}
+
+	// Who can deploy, as one query over the inheritance chain of any depth
+	// up to two.
+	deployers := fetchColumn(t, ctx, db, "user", `match
+$res isa resource, has resource-name "prod-cluster";
+$pr isa protects, links (guard: $p, protected: $res);
+$p has action "deploy";
+$gr isa grants, links (grantee: $r, permission: $p), has effect "allow";
+$a isa assigned, links (holder: $g, assigned-role: $r);
+{ $m isa member-of, links (member: $u, group: $g); } or
+{ $s isa subgroup-of, links (child: $c, parent: $g);
+  $m isa member-of, links (member: $u, group: $c); } or
+{ $s1 isa subgroup-of, links (child: $c1, parent: $g);
+  $s2 isa subgroup-of, links (child: $c2, parent: $c1);
+  $m isa member-of, links (member: $u, group: $c2); };
+fetch { "user": $u.username };`)
+	if !slices.Equal(deployers, []string{"ana", "ben"}) {
+		t.Errorf("deployers = %v, want [ana ben]", deployers)
+	}
+}
@@ -0,0 +1,389 @@
+//go:build integration && cgo && typedb
+
+package gotype_test
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// ---------------------------------------------------------------------------
+// Recommendation domain models
+// ---------------------------------------------------------------------------
+
+type RecMember struct {
+	gotype.BaseEntity
+	Name string `typedb:"member-name,key"`
+}
+
+type RecItem struct {
+	gotype.BaseEntity
+	Name     string  `typedb:"item-name,key"`
+	Category string  `typedb:"category"`
+	Price    float64 `typedb:"list-price"`
+}
+
+type RecTag struct {
+	gotype.BaseEntity
+	Name string `typedb:"tag-name,key"`
+}
+
+type RecRated struct {
+	gotype.BaseRelation
+	Rater *RecMember `typedb:"role:rater"`
+	Item  *RecItem   `typedb:"role:rated-item"`
+	Stars int        `typedb:"stars"`
+}
+
+type RecTaggedWith struct {
+	gotype.BaseRelation
+	Item *RecItem `typedb:"role:tagged-item"`
+	Tag  *RecTag  `typedb:"role:item-tag"`
+}
+
+type RecSimilarTo struct {
+	gotype.BaseRelation
+	Source     *RecItem `typedb:"role:source-item"`
+	Similar    *RecItem `typedb:"role:similar-item"`
+	SharedTags int      `typedb:"shared-tags"`
+	Similarity float64  `typedb:"similarity"`
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+func setupRecommendationDB(t *testing.T) *gotype.Database {
+	return setupTestDBWith(t, func() {
+		_ = gotype.Register[RecMember](gotype.WithTypeName("member"))
+		_ = gotype.Register[RecItem](gotype.WithTypeName("catalog-item"))
+		_ = gotype.Register[RecTag](gotype.WithTypeName("tag"))
+		_ = gotype.Register[RecRated](gotype.WithTypeName("rated"))
+		_ = gotype.Register[RecTaggedWith](gotype.WithTypeName("tagged-with"))
+		_ = gotype.Register[RecSimilarTo](gotype.WithTypeName("similar-to"))
+	})
+}
+
+// recItemTags is the tagging used by seedRecommendation.
+var recItemTags = map[string][]string{
+	"dune":         {"sci-fi", "space", "classic"},
+	"foundation":   {"sci-fi", "space", "classic"},
+	"neuromancer":  {"sci-fi", "cyberpunk"},
+	"alien":        {"sci-fi", "space", "horror"},
+	"blade-runner": {"sci-fi", "cyberpunk", "classic"},
+	"arrival":      {"sci-fi"},
+	"portal":       {"puzzle", "sci-fi"},
+	"myst":         {"puzzle", "classic"},
+}
+
+// seedRecommendation loads eight items in three categories, their tags, and
+// nineteen star ratings by five members.
+func seedRecommendation(t *testing.T) (*gotype.Database, map[string]*RecItem) {
+	t.Helper()
+	db := setupRecommendationDB(t)
+	ctx := context.Background()
+
+	itemMgr := gotype.MustNewManager[RecItem](db)
+	items := make(map[string]*RecItem)
+	for _, it := range []*RecItem{
+		{Name: "dune", Category: "book", Price: 10},
+		{Name: "foundation", Category: "book", Price: 8},
+		{Name: "neuromancer", Category: "book", Price: 9},
+		{Name: "alien", Category: "film", Price: 15},
+		{Name: "blade-runner", Category: "film", Price: 12},
+		{Name: "arrival", Category: "film", Price: 18},
+		{Name: "portal", Category: "game", Price: 20},
+		{Name: "myst", Category: "game", Price: 10},
+	} {
+		items[it.Name] = insertAndGet(t, ctx, itemMgr, it, "item-name", it.Name)
+	}
+
+	tagMgr := gotype.MustNewManager[RecTag](db)
+	tags := make(map[string]*RecTag)
+	for _, name := range []string{"sci-fi", "space", "classic", "cyberpunk", "horror", "puzzle"} {
+		tags[name] = insertAndGet(t, ctx, tagMgr, &RecTag{Name: name}, "tag-name", name)
+	}
+	taggedMgr := gotype.MustNewManager[RecTaggedWith](db)
+	for item, names := range recItemTags {
+		for _, tag := range names {
+			assertInsert(t, ctx, taggedMgr, &RecTaggedWith{Item: items[item], Tag: tags[tag]})
+		}
+	}
+
+	memberMgr := gotype.MustNewManager[RecMember](db)
+	ratedMgr := gotype.MustNewManager[RecRated](db)
+	for member, ratings := range map[string]map[string]int{
+		"alice": {"dune": 5, "foundation": 4, "alien": 5, "portal": 2},
+		"bob":   {"dune": 4, "neuromancer": 5, "blade-runner": 5, "myst": 3},
+		"carol": {"dune": 2, "arrival": 4, "alien": 3},
+		"dave":  {"dune": 4, "foundation": 5, "alien": 4, "blade-runner": 2, "portal": 5},
+		"erin":  {"neuromancer": 4, "myst": 5, "arrival": 5},
+	} {
+		m := insertAndGet(t, ctx, memberMgr, &RecMember{Name: member}, "member-name", member)
+		for item, stars := range ratings {
+			assertInsert(t, ctx, ratedMgr, &RecRated{Rater: m, Item: items[item], Stars: stars})
+		}
+	}
+
+	return db, items
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestIntegration_Recommendation_AllInserted(t *testing.T) {
+	db, _ := seedRecommendation(t)
+	ctx := context.Background()
+
+	assertCount(t, ctx, gotype.MustNewManager[RecMember](db), 5)
+	assertCount(t, ctx, gotype.MustNewManager[RecItem](db), 8)
+	assertCount(t, ctx, gotype.MustNewManager[RecTag](db), 6)
+	assertCount(t, ctx, gotype.MustNewManager[RecRated](db), 19)
+	assertCount(t, ctx, gotype.MustNewManager[RecTaggedWith](db), 19)
+
+	n, err := gotype.MustNewManager[RecTaggedWith](db).Query().
+		Filter(gotype.RolePlayer("item-tag", gotype.Eq("tag-name", "sci-fi"))).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("count sci-fi items: %v", err)
+	}
+	if n != 7 {
+		t.Errorf("sci-fi items = %d, want 7", n)
+	}
+}
+
+func TestIntegration_Recommendation_AlsoLiked(t *testing.T) {
+	db, _ := seedRecommendation(t)
+	ctx := context.Background()
+
+	// Items rated 4+ by members who rated dune 4+, counted per endorsing member.
+	got := countBy(t, ctx, db, "item", `match
+$x isa catalog-item, has item-name "dune";
+$r1 isa rated, links (rater: $u, rated-item: $x), has stars $s1;
+$s1 >= 4;
+$r2 isa rated, links (rater: $u, rated-item: $y), has stars $s2;
+$s2 >= 4;
+not { $y is $x; };
+fetch { "item": $y.item-name };`)
+	want := map[string]int{"alien": 2, "foundation": 2, "blade-runner": 1, "neuromancer": 1, "portal": 1}
+	if !maps.Equal(got, want) {
+		t.Errorf("also liked with dune = %v, want %v", got, want)
+	}
+
+	// Personalised: what members who share carol's high ratings also liked,
+	// minus anything carol has already rated.
+	got = countBy(t, ctx, db, "item", `match
+$me isa member, has member-name "carol";
+$mine isa rated, links (rater: $me, rated-item: $x), has stars $s0;
+$s0 >= 4;
+$theirs isa rated, links (rater: $u, rated-item: $x), has stars $s1;
+$s1 >= 4;
+not { $u is $me; };
+$rec isa rated, links (rater: $u, rated-item: $y), has stars $s2;
+$s2 >= 4;
+not { $seen isa rated, links (rater: $me, rated-item: $y); };
+fetch { "item": $y.item-name };`)
+	want = map[string]int{"myst": 1, "neuromancer": 1}
+	if !maps.Equal(got, want) {
+		t.Errorf("recommendations for carol = %v, want %v", got, want)
+	}
+
+	// The same signal through the query builder: ratings of one item.
+	ratedMgr := gotype.MustNewManager[RecRated](db)
+	high, err := ratedMgr.Query().
+		Filter(
+			gotype.RolePlayer("rated-item", gotype.Eq("item-name", "dune")),
+			gotype.Gte("stars", 4),
+		).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("high dune ratings: %v", err)
+	}
+	var raters []string
+	for _, r := range high {
+		raters = append(raters, r.Rater.Name)
+	}
+	slices.Sort(raters)
+	if !slices.Equal(raters, []string{"alice", "bob", "dave"}) {
+		t.Errorf("members rating dune 4+ = %v", raters)
+	}
+}
+
+func TestIntegration_Recommendation_TagOverlap(t *testing.T) {
+	db, _ := seedRecommendation(t)
+	ctx := context.Background()
+
+	shared := countBy(t, ctx, db, "item", `match
+$x isa catalog-item, has item-name "dune";
+$t1 isa tagged-with, links (tagged-item: $x, item-tag: $tag);
+$t2 isa tagged-with, links (tagged-item: $y, item-tag: $tag);
+not { $y is $x; };
+fetch { "item": $y.item-name };`)
+	want := map[string]int{
+		"foundation": 3, "alien": 2, "blade-runner": 2,
+		"neuromancer": 1, "arrival": 1, "portal": 1, "myst": 1,
+	}
+	if !maps.Equal(shared, want) {
+		t.Errorf("tags shared with dune = %v, want %v", shared, want)
+	}
+
+	// Items with no tags in common with myst.
+	none := fetchColumn(t, ctx, db, "item", `match
+$x isa catalog-item, has item-name "myst";
+$y isa catalog-item;
+not { $y is $x; };
+not {
+  $t1 isa tagged-with, links (tagged-item: $x, item-tag: $tag);
+  $t2 isa tagged-with, links (tagged-item: $y, item-tag: $tag);
+};
+fetch { "item": $y.item-name };`)
+	if !slices.Equal(none, []string{"alien", "arrival", "neuromancer"}) {
+		t.Errorf("items sharing no tag with myst = %v", none)
+	}
+}
+
+func TestIntegration_Recommendation_SimilarTo(t *testing.T) {
+	db, items := seedRecommendation(t)
+	ctx := context.Background()
+	mgr := gotype.MustNewManager[RecSimilarTo](db)
+
+	// Materialise Jaccard similarity for pairs sharing at least two tags.
+	var links []*RecSimilarTo
+	for a, tagsA := range recItemTags {
+		for b, tagsB := range recItemTags {
+			if a == b {
+				continue
+			}
+			common := 0
+			for _, tag := range tagsA {
+				if slices.Contains(tagsB, tag) {
+					common++
+				}
+			}
+			if common < 2 {
+				continue
+			}
+			union := len(tagsA) + len(tagsB) - common
+			links = append(links, &RecSimilarTo{
+				Source:     items[a],
+				Similar:    items[b],
+				SharedTags: common,
+				Similarity: float64(common) / float64(union),
+			})
+		}
+	}
+	assertInsertMany(t, ctx, mgr, links)
+
+	results, err := mgr.Query().
+		Filter(gotype.RolePlayer("source-item", gotype.Eq("item-name", "dune"))).
+		OrderDesc("similarity").
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("similar to dune: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 items similar to dune, got %d", len(results))
+	}
+	if results[0].Similar.Name != "foundation" || results[0].Similarity != 1 {
+		t.Errorf("most similar to dune = %s (%v), want foundation (1)", results[0].Similar.Name, results[0].Similarity)
+	}
+	for _, r := range results[1:] {
+		if r.SharedTags != 2 || r.Similarity != 0.5 {
+			t.Errorf("%s: shared %d, similarity %v, want 2 and 0.5", r.Similar.Name, r.SharedTags, r.Similarity)
+		}
+	}
+
+	// Similarity is stored in both directions.
+	back, err := mgr.Query().
+		Filter(
+			gotype.RolePlayer("source-item", gotype.Eq("item-name", "alien")),
+			gotype.RolePlayer("similar-item", gotype.Eq("item-name", "dune")),
+		).
+		First(ctx)
+	if err != nil {
+		t.Fatalf("alien -> dune: %v", err)
+	}
+	if back == nil || back.Similarity != 0.5 {
+		t.Errorf("alien -> dune = %+v, want similarity 0.5", back)
+	}
+}
+
+func TestIntegration_Recommendation_PopularityReports(t *testing.T) {
+	db, _ := seedRecommendation(t)
+	ctx := context.Background()
+	ratedMgr := gotype.MustNewManager[RecRated](db)
+
+	// Star histogram across all ratings.
+	hist, err := ratedMgr.Query().GroupBy("stars").Aggregate(ctx,
+		gotype.AggregateSpec{Attr: "stars", Fn: "count"},
+	)
+	if err != nil {
+		t.Fatalf("star histogram: %v", err)
+	}
+	want := map[string]float64{"5": 8, "4": 6, "3": 2, "2": 3}
+	if len(hist) != len(want) {
+		t.Fatalf("expected %d star buckets, got %v", len(want), hist)
+	}
+	for stars, n := range want {
+		if hist[stars]["count_stars"] != n {
+			t.Errorf("%s stars: %v ratings, want %v", stars, hist[stars]["count_stars"], n)
+		}
+	}
+
+	// The same histogram restricted to films.
+	films, err := ratedMgr.Query().
+		Filter(gotype.RolePlayer("rated-item", gotype.Eq("category", "film"))).
+		GroupBy("stars").
+		Aggregate(ctx, gotype.AggregateSpec{Attr: "stars", Fn: "count"})
+	if err != nil {
+		t.Fatalf("film histogram: %v", err)
+	}
+	for stars, n := range map[string]float64{"5": 3, "4": 2, "3": 1, "2": 1} {
+		if films[stars]["count_stars"] != n {
+			t.Errorf("film %s stars: %v ratings, want %v", stars, films[stars]["count_stars"], n)
+		}
+	}
+
+	// Catalogue shape per category.
+	byCategory, err := gotype.MustNewManager[RecItem](db).Query().GroupBy("category").Aggregate(ctx,
+		gotype.AggregateSpec{Attr: "item-name", Fn: "count"},
+		gotype.AggregateSpec{Attr: "list-price", Fn: "mean"},
+	)
+	if err != nil {
+		t.Fatalf("items by category: %v", err)
+	}
+	for cat, w := range map[string][2]float64{"book": {3, 9}, "film": {3, 15}, "game": {2, 15}} {
+		got := byCategory[cat]
+		if got["count_item-name"] != w[0] || math.Abs(got["mean_list-price"]-w[1]) > 1e-9 {
+			t.Errorf("%s = %v, want %v items averaging %v", cat, got, w[0], w[1])
+		}
+	}
+
+	// Per-item average and rating count, ranked by popularity.
+	counts := countBy(t, ctx, db, "item", `match
+$r isa rated, links (rated-item: $i);
+fetch { "item": $i.item-name };`)
+	ranked := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		return cmp.Or(counts[b]-counts[a], cmp.Compare(a, b))
+	})
+	if ranked[0] != "dune" || counts["dune"] != 4 || counts["alien"] != 3 {
+		t.Errorf("popularity ranking = %v (%v)", ranked, counts)
+	}
+	avg, err := ratedMgr.Query().
+		Filter(gotype.RolePlayer("rated-item", gotype.Eq("item-name", "dune"))).
+		Avg("stars").
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("average dune rating: %v", err)
+	}
+	if math.Abs(avg-3.75) > 1e-9 {
+		t.Errorf("average dune rating = %v, want 3.75", avg)
+	}
+}
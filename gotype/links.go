@@ -0,0 +1,519 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/ast"
+)
+
+// LinkInfo contains metadata about a typed role-player field of an entity
+// model: a pointer to an entity related to it through a relation, tagged
+// "rel:relation.role" with the role the pointed-to entity plays.
+//
+//	type Person struct {
+//	    gotype.BaseEntity
+//	    Name     string   `typedb:"name,key"`
+//	    Employer *Company `typedb:"rel:employment.employer"`
+//	}
+//
+// The owning entity plays the relation's other role. Insert creates the
+// relation along with the entity and Preload reads the field back.
+type LinkInfo struct {
+	// FieldName is the name of the Go struct field.
+	FieldName string
+	// FieldIndex is the 0-based index of the field in the Go struct. For
+	// fields hoisted from an embedded struct it is the index of the embedding
+	// field.
+	FieldIndex int
+	// Relation is the TypeDB relation type linking the two entities.
+	Relation string
+	// Role is the role played by the entity the field points to.
+	Role string
+
+	// index is the reflect index path of the field, including embedded structs.
+	index []int
+	// playerType is the struct type the field points to.
+	playerType reflect.Type
+}
+
+// fieldValue returns the struct field described by l within v, following
+// embedded structs for hoisted fields.
+func (l *LinkInfo) fieldValue(v reflect.Value) reflect.Value {
+	if len(l.index) == 0 {
+		return v.Field(l.FieldIndex)
+	}
+	return v.FieldByIndex(l.index)
+}
+
+// resolvedLink is a LinkInfo resolved against the registry of its model.
+type resolvedLink struct {
+	*LinkInfo
+	relation *ModelInfo
+	player   *ModelInfo
+	// ownRole is the role played by the owning entity.
+	ownRole string
+}
+
+// resolveLink looks up the relation and player models of link, a field of
+// info. The owning entity's role is the relation's other role; when the
+// relation has several, the one whose player is info.
+func resolveLink(info *ModelInfo, link *LinkInfo) (resolvedLink, error) {
+	reg := registryOf(info)
+	rel, ok := reg.Lookup(link.Relation)
+	if !ok || rel.Kind != ModelKindRelation {
+		return resolvedLink{}, fmt.Errorf("field %s: relation %s is not registered", link.FieldName, link.Relation)
+	}
+	player, ok := reg.LookupType(link.playerType)
+	if !ok {
+		return resolvedLink{}, fmt.Errorf("field %s: %w", link.FieldName, &NotRegisteredError{TypeName: link.playerType.Name()})
+	}
+
+	var found bool
+	var others []RoleInfo
+	for _, role := range rel.Roles {
+		if role.RoleName == link.Role {
+			found = true
+		} else {
+			others = append(others, role)
+		}
+	}
+	if !found {
+		return resolvedLink{}, fmt.Errorf("field %s: relation %s has no role %s", link.FieldName, rel.TypeName, link.Role)
+	}
+	resolved := resolvedLink{LinkInfo: link, relation: rel, player: player}
+	switch len(others) {
+	case 0:
+		// A symmetric relation such as friendship (friend, friend).
+		resolved.ownRole = link.Role
+		return resolved, nil
+	case 1:
+		resolved.ownRole = others[0].RoleName
+		return resolved, nil
+	}
+	var candidates []string
+	for _, role := range others {
+		if role.playerType == info.GoType || role.PlayerTypeName == info.TypeName {
+			candidates = append(candidates, role.RoleName)
+		}
+	}
+	if len(candidates) != 1 {
+		return resolvedLink{}, fmt.Errorf("field %s: cannot tell which role of %s %s plays", link.FieldName, rel.TypeName, info.TypeName)
+	}
+	resolved.ownRole = candidates[0]
+	return resolved, nil
+}
+
+// insertLinks creates the relations of the non-nil link fields of v, an
+// instance of info stored as iid, in tx. The linked entities must have an IID
+// or key attributes.
+func insertLinks(ctx context.Context, tx Tx, info *ModelInfo, v reflect.Value, iid string) error {
+	patterns := []ast.Pattern{ast.IidPattern{Variable: "$e", IID: iid}}
+	var inserts, players []string
+	for i := range info.Links {
+		field := info.Links[i].fieldValue(v)
+		if field.IsNil() {
+			continue
+		}
+		link, err := resolveLink(info, &info.Links[i])
+		if err != nil {
+			return err
+		}
+		if getIIDFromValueInfo(field.Elem(), link.player) == "" && len(link.player.KeyFields) == 0 {
+			return fmt.Errorf("field %s: %s has neither an IID nor key attributes", link.FieldName, link.player.TypeName)
+		}
+		target := fmt.Sprintf("t%d", i)
+		pattern, err := playerPattern(target, field.Elem(), link.player)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", link.FieldName, err)
+		}
+		patterns = append(patterns, pattern)
+		players = append(players, link.player.TypeName)
+		inserts = append(inserts, fmt.Sprintf("$l%d isa %s, links (%s: $e, %s: $%s);",
+			i, link.relation.TypeName, link.ownRole, link.Role, target))
+	}
+	if len(inserts) == 0 {
+		return nil
+	}
+	if iid == "" {
+		return fmt.Errorf("links: insert returned no IID")
+	}
+
+	match, err := compileNode(ast.Match(patterns...))
+	if err != nil {
+		return fmt.Errorf("links: %w", err)
+	}
+	query, err := appendIIDFetch(match+"\ninsert\n"+strings.Join(inserts, "\n"), "e")
+	if err != nil {
+		return fmt.Errorf("links: %w", err)
+	}
+	results, err := tx.QueryWithContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("links: %w", err)
+	}
+	if len(results) == 0 {
+		// The match found no row: a linked entity does not exist.
+		return fmt.Errorf("links: %w", &NotFoundError{TypeName: strings.Join(players, " or ")})
+	}
+	return nil
+}
+
+// Preload populates the typed role-player fields (tagged "rel:") of
+// instances, which must have IIDs, with one query per field. fields names the
+// Go fields to load; every link field is loaded when it is empty. A field
+// whose relation does not exist is set to nil; when the entity has several
+// such relations, the player with the lowest IID is kept.
+func (m *Manager[T]) Preload(ctx context.Context, instances []*T, fields ...string) error {
+	links := m.info.Links
+	if len(fields) > 0 {
+		links = nil
+		for _, name := range fields {
+			i := slices.IndexFunc(m.info.Links, func(l LinkInfo) bool { return l.FieldName == name })
+			if i < 0 {
+				return fmt.Errorf("preload %s: no typed role-player field %s", m.info.TypeName, name)
+			}
+			links = append(links, m.info.Links[i])
+		}
+	}
+
+	byIID := make(map[string][]reflect.Value, len(instances))
+	var iids []string
+	for _, inst := range instances {
+		if inst == nil {
+			continue
+		}
+		iid := getIIDOfInfo(inst, m.info)
+		if iid == "" {
+			return fmt.Errorf("preload %s: instance has no IID", m.info.TypeName)
+		}
+		if _, ok := byIID[iid]; !ok {
+			iids = append(iids, iid)
+		}
+		byIID[iid] = append(byIID[iid], reflect.ValueOf(inst).Elem())
+	}
+	if len(iids) == 0 {
+		return nil
+	}
+
+	for i := range links {
+		if err := m.preloadLink(ctx, &links[i], iids, byIID); err != nil {
+			return fmt.Errorf("preload %s: %w", m.info.TypeName, err)
+		}
+	}
+	return nil
+}
+
+func (m *Manager[T]) preloadLink(ctx context.Context, info *LinkInfo, iids []string, byIID map[string][]reflect.Value) error {
+	link, err := resolveLink(m.info, info)
+	if err != nil {
+		return err
+	}
+	items := appendFetchProjectionItems([]string{`"_iid": iid($t)`}, link.player.Fields, "t")
+	query := fmt.Sprintf("match\n%s\n$l isa %s, links (%s: $e, %s: $t);\nfetch {\n  \"_iid\": iid($e),\n  \"player\": { %s }\n};",
+		strings.Join(IIDIn(iids...).ToPatterns("e"), "\n"), link.relation.TypeName, link.ownRole, link.Role, strings.Join(items, ", "))
+	results, err := m.readQuery(ctx, query)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", link.FieldName, err)
+	}
+
+	players := make(map[string]map[string]any, len(results))
+	for _, row := range results {
+		player, ok := row["player"].(map[string]any)
+		if !ok {
+			continue
+		}
+		iid := extractIID(row)
+		if cur, ok := players[iid]; !ok || extractIID(player) < extractIID(cur) {
+			players[iid] = player
+		}
+	}
+
+	for iid, values := range byIID {
+		var ptr reflect.Value
+		if data, ok := players[iid]; ok {
+			ptr = reflect.New(link.player.GoType)
+			if err := hydrateValueWithDepth(ptr.Elem(), link.player, data, 0, nil); err != nil {
+				m.db.stats.recordHydrationError()
+				return fmt.Errorf("field %s: %w", link.FieldName, err)
+			}
+		}
+		for _, v := range values {
+			field := link.fieldValue(v)
+			if ptr.IsValid() && ptr.Type() == field.Type() {
+				field.Set(ptr)
+			} else {
+				field.SetZero()
+			}
+		}
+	}
+	return nil
+}
+
+// Link creates a relation of type relation between a, playing roleA, and b,
+// playing roleB, owning attrs. It needs no Go model for the relation, and
+// covers many-to-many relations. Both entities must already be stored: a
+// with its IID set, b given as an IID string or a model instance with its
+// IID set. Multi-valued attributes take a slice.
+func (m *Manager[T]) Link(ctx context.Context, a *T, relation, roleA string, b any, roleB string, attrs map[string]any) error {
+	match, err := m.linkMatch(a, roleA, b, roleB, relation)
+	if err != nil {
+		return fmt.Errorf("link %s: %w", m.info.TypeName, err)
+	}
+	var q strings.Builder
+	fmt.Fprintf(&q, "%s\ninsert\n$r isa %s, links (%s: $a, %s: $b)", match, relation, roleA, roleB)
+	for _, name := range slices.Sorted(maps.Keys(attrs)) {
+		if err := ValidateIdentifier(name, "attribute"); err != nil {
+			return fmt.Errorf("link %s: %w", m.info.TypeName, err)
+		}
+		for _, val := range attrValues(attrs[name]) {
+			has, err := hasClause(name, val)
+			if err != nil {
+				return fmt.Errorf("link %s: %w", m.info.TypeName, err)
+			}
+			q.WriteString(", ")
+			q.WriteString(has)
+		}
+	}
+	q.WriteString(";")
+	query, err := appendIIDFetch(q.String(), "r")
+	if err != nil {
+		return fmt.Errorf("link %s: %w", m.info.TypeName, err)
+	}
+	defer m.invalidateCache(ctx)
+
+	return m.withWriteTx(ctx, "link", m.writeTx, func(tx Tx) error {
+		results, err := tx.QueryWithContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("link %s: %w", m.info.TypeName, err)
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("link %s: %w", m.info.TypeName, &NotFoundError{TypeName: m.info.TypeName + " or linked instance"})
+		}
+		return nil
+	})
+}
+
+// Unlink deletes every relation of type relation in which a plays roleA and
+// b plays roleB, and returns how many were deleted. a and b are given as for
+// Link.
+func (m *Manager[T]) Unlink(ctx context.Context, a *T, relation, roleA string, b any, roleB string) (int64, error) {
+	match, err := m.linkMatch(a, roleA, b, roleB, relation)
+	if err != nil {
+		return 0, fmt.Errorf("unlink %s: %w", m.info.TypeName, err)
+	}
+	match += fmt.Sprintf("\n$r isa %s, links (%s: $a, %s: $b);", relation, roleA, roleB)
+	defer m.invalidateCache(ctx)
+
+	var count int64
+	err = m.withWriteTx(ctx, "unlink", m.writeTx, func(tx Tx) error {
+		summarized := reportsSummary(tx)
+		if !summarized {
+			results, err := tx.QueryWithContext(ctx, match+"\nreduce $count = count($r);")
+			if err != nil {
+				return fmt.Errorf("unlink %s: count: %w", m.info.TypeName, err)
+			}
+			if len(results) > 0 {
+				count = extractCount(results[0])
+			}
+		}
+		res, err := queryResult(ctx, tx, match+"\ndelete $r;")
+		if err != nil {
+			return fmt.Errorf("unlink %s: %w", m.info.TypeName, err)
+		}
+		if summarized {
+			if res.Summary == nil {
+				return fmt.Errorf("unlink %s: transaction reported no write summary", m.info.TypeName)
+			}
+			count = res.Summary.Deleted
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// UpdatePlayers re-points roles of the stored relation rel to other players,
+// keeping the relation and its attributes. players maps role names to the
+// IID of the new player; the current players of each role are unlinked
+// first. On success the role fields of rel hold placeholder players with
+// only the new IID set.
+func (m *Manager[T]) UpdatePlayers(ctx context.Context, rel *T, players map[string]string) error {
+	if m.info.Kind != ModelKindRelation {
+		return fmt.Errorf("update_players %s: not a relation", m.info.TypeName)
+	}
+	if rel == nil {
+		return fmt.Errorf("update_players %s: instance must not be nil", m.info.TypeName)
+	}
+	if err := checkCtx(ctx, "update_players", m.info.TypeName); err != nil {
+		return err
+	}
+	iid := getIIDOfInfo(rel, m.info)
+	if iid == "" {
+		return fmt.Errorf("update_players %s: instance has no IID", m.info.TypeName)
+	}
+	if len(players) == 0 {
+		return nil
+	}
+
+	// Apply the change to a copy first so the role cardinality is checked
+	// against the result and rel stays untouched on failure.
+	updated := reflect.New(m.info.GoType).Elem()
+	updated.Set(reflect.ValueOf(rel).Elem())
+	roles := slices.Sorted(maps.Keys(players))
+	for _, role := range roles {
+		if !IsIID(players[role]) {
+			return fmt.Errorf("update_players %s: role %q: invalid IID %q", m.info.TypeName, role, players[role])
+		}
+		if err := setRolePlayer(m.info, updated, role, players[role]); err != nil {
+			return fmt.Errorf("update_players %s: %w", m.info.TypeName, err)
+		}
+	}
+	if err := checkRoleCardinality(m.info, updated); err != nil {
+		return fmt.Errorf("update_players %s: %w", m.info.TypeName, err)
+	}
+
+	relMatch := ast.IidPattern{Variable: "$r", IID: iid}
+	var queries []string
+	patterns := []ast.Pattern{relMatch}
+	var links []string
+	for _, role := range roles {
+		v := sanitizeVar(role)
+		del, err := compileNode(ast.Match(relMatch))
+		if err != nil {
+			return fmt.Errorf("update_players %s: %w", m.info.TypeName, err)
+		}
+		queries = append(queries, fmt.Sprintf("%s\n$r links (%s: $old_%s);\ndelete\nlinks (%s: $old_%s) of $r;", del, role, v, role, v))
+		patterns = append(patterns, ast.IidPattern{Variable: "$new_" + v, IID: players[role]})
+		links = append(links, fmt.Sprintf("%s: $new_%s", role, v))
+	}
+	match, err := compileNode(ast.Match(patterns...))
+	if err != nil {
+		return fmt.Errorf("update_players %s: %w", m.info.TypeName, err)
+	}
+	insert, err := appendIIDFetch(fmt.Sprintf("%s\ninsert\n$r links (%s);", match, strings.Join(links, ", ")), "r")
+	if err != nil {
+		return fmt.Errorf("update_players %s: %w", m.info.TypeName, err)
+	}
+	defer m.invalidateCache(ctx, iid)
+
+	err = m.withWriteTx(ctx, "update_players", m.writeTx, func(tx Tx) error {
+		for _, q := range queries {
+			if _, err := tx.QueryWithContext(ctx, q); err != nil {
+				return fmt.Errorf("update_players %s: %w", m.info.TypeName, err)
+			}
+		}
+		results, err := tx.QueryWithContext(ctx, insert)
+		if err != nil {
+			return fmt.Errorf("update_players %s: %w", m.info.TypeName, err)
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("update_players %s: %w", m.info.TypeName, &NotFoundError{TypeName: m.info.TypeName + " or player"})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	reflect.ValueOf(rel).Elem().Set(updated)
+	return nil
+}
+
+// setRolePlayer sets the fields of role in v, a relation value, to a single
+// player holding only iid: the first field of the role gets the player and
+// any others are cleared.
+func setRolePlayer(info *ModelInfo, v reflect.Value, role, iid string) error {
+	found := false
+	for i := range info.Roles {
+		r := &info.Roles[i]
+		if r.RoleName != role {
+			continue
+		}
+		field := r.fieldValue(v)
+		if found {
+			field.SetZero()
+			continue
+		}
+		found = true
+		player := reflect.New(r.playerType)
+		setIIDWithInfo(player.Elem(), nil, iid)
+		switch field.Kind() {
+		case reflect.Pointer:
+			field.Set(player)
+		case reflect.Slice:
+			elem := player
+			if field.Type().Elem().Kind() != reflect.Pointer {
+				elem = player.Elem()
+			}
+			field.Set(reflect.Append(reflect.MakeSlice(field.Type(), 0, 1), elem))
+		default:
+			field.Set(player.Elem())
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	return nil
+}
+
+// linkMatch matches a as $a and b as $b by IID for Link and Unlink.
+func (m *Manager[T]) linkMatch(a *T, roleA string, b any, roleB string, relation string) (string, error) {
+	if err := ValidateIdentifier(relation, "relation"); err != nil {
+		return "", err
+	}
+	for _, role := range []string{roleA, roleB} {
+		if err := ValidateIdentifier(role, "role"); err != nil {
+			return "", err
+		}
+	}
+	if a == nil {
+		return "", fmt.Errorf("instance must not be nil")
+	}
+	iidA := getIIDOfInfo(a, m.info)
+	if iidA == "" {
+		return "", fmt.Errorf("instance has no IID")
+	}
+	if !IsIID(iidA) {
+		return "", fmt.Errorf("invalid IID %q", iidA)
+	}
+	var iidB string
+	switch b := b.(type) {
+	case string:
+		iidB = b
+	case interface{ GetIID() string }:
+		if rv := reflect.ValueOf(b); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "", fmt.Errorf("linked instance must not be nil")
+		}
+		iidB = b.GetIID()
+	default:
+		return "", fmt.Errorf("linked instance must be an IID or a model pointer, got %T", b)
+	}
+	if iidB == "" {
+		return "", fmt.Errorf("linked instance has no IID")
+	}
+	if !IsIID(iidB) {
+		return "", fmt.Errorf("linked instance: invalid IID %q", iidB)
+	}
+	return compileNode(ast.Match(
+		ast.IidPattern{Variable: "$a", IID: iidA},
+		ast.IidPattern{Variable: "$b", IID: iidB},
+	))
+}
+
+// attrValues returns the values of an attribute given as a scalar or a slice.
+func attrValues(val any) []any {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return []any{val}
+	}
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values
+}
@@ -0,0 +1,217 @@
+package gotype
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testWorker struct {
+	BaseEntity
+	Name     string       `typedb:"name,key"`
+	Employer *testCompany `typedb:"rel:test-employment.employer"`
+}
+
+type testLinkedRelation struct {
+	BaseRelation
+	Boss *testCompany `typedb:"rel:test-employment.employer"`
+}
+
+func registerLinkTypes(t *testing.T) *Registry {
+	t.Helper()
+	reg := NewRegistry()
+	MustRegisterIn[testWorker](reg)
+	MustRegisterIn[testCompany](reg)
+	MustRegisterIn[testEmployment](reg)
+	return reg
+}
+
+func TestLinks_ModelInfo(t *testing.T) {
+	reg := registerLinkTypes(t)
+	info, _ := reg.LookupType(typeOf[testWorker]())
+	if len(info.Fields) != 1 || len(info.Links) != 1 {
+		t.Fatalf("fields = %d, links = %d", len(info.Fields), len(info.Links))
+	}
+	link, err := resolveLink(info, &info.Links[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.relation.TypeName != "test-employment" || link.ownRole != "employee" || link.player.TypeName != "test-company" {
+		t.Errorf("resolved = %s %s %s", link.relation.TypeName, link.ownRole, link.player.TypeName)
+	}
+
+	if _, err := ExtractModelInfo(typeOf[testLinkedRelation]()); err == nil {
+		t.Error("expected error for typed role player on a relation")
+	}
+}
+
+func TestLinks_InsertCreatesRelation(t *testing.T) {
+	reg := registerLinkTypes(t)
+	tx := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1"}}, {{"_iid": "0x1"}}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db").WithRegistry(reg)
+	mgr := MustNewManager[testWorker](db)
+
+	w := &testWorker{Name: "Alice", Employer: &testCompany{Name: "Acme"}}
+	if err := mgr.Insert(context.Background(), w); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.queries) != 2 || !tx.committed {
+		t.Fatalf("queries = %d, committed = %v", len(tx.queries), tx.committed)
+	}
+	q := tx.queries[1]
+	assertContains(t, q, "$e iid 0x1;")
+	assertContains(t, q, `$t0 isa test-company, has name "Acme";`)
+	assertContains(t, q, "$l0 isa test-employment, links (employee: $e, employer: $t0);")
+
+	// A missing linked entity rolls the insert back.
+	tx = &mockTx{responses: [][]map[string]any{{{"_iid": "0x2"}}}}
+	db = NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db").WithRegistry(reg)
+	err := MustNewManager[testWorker](db).Insert(context.Background(), &testWorker{Name: "Bob", Employer: &testCompany{Name: "Nope"}})
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || tx.committed {
+		t.Errorf("err = %v, committed = %v", err, tx.committed)
+	}
+}
+
+func TestLinks_Preload(t *testing.T) {
+	reg := registerLinkTypes(t)
+	read := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1", "name": "Alice"}, {"_iid": "0x2", "name": "Bob"}}}}
+	preload := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1", "player": map[string]any{"_iid": "0x9", "name": "Acme"}}}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{read, preload}}, "test_db").WithRegistry(reg)
+	mgr := MustNewManager[testWorker](db)
+
+	workers, err := mgr.Query().Preload().Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workers) != 2 || workers[0].Employer == nil || workers[0].Employer.Name != "Acme" ||
+		workers[0].Employer.GetIID() != "0x9" || workers[1].Employer != nil {
+		t.Fatalf("workers = %+v", workers)
+	}
+	q := preload.queries[0]
+	assertContains(t, q, "{ $e iid 0x1; } or { $e iid 0x2; };")
+	assertContains(t, q, "$l isa test-employment, links (employee: $e, employer: $t);")
+	if !strings.Contains(q, `"name": $t.name`) {
+		t.Errorf("player attributes not fetched:\n%s", q)
+	}
+
+	if err := mgr.Preload(context.Background(), workers, "Boss"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestLinkUnlink(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	link := &mockTx{responses: [][]map[string]any{{{"_iid": "0x9"}}}}
+	unlink := &mockTx{responses: [][]map[string]any{{{"count": int64(2)}}}}
+	mgr := MustNewManager[testPerson](NewDatabase(&mockConn{txs: []*mockTx{link, unlink}}, "test_db"))
+	alice := &testPerson{Name: "Alice"}
+	alice.SetIID("0x1")
+	acme := &testCompany{Name: "Acme"}
+	acme.SetIID("0x2")
+
+	err := mgr.Link(ctx, alice, "friendship", "friend", acme, "friend", map[string]any{"since": 2020, "tag": []string{"a", "b"}})
+	if err != nil || !link.committed {
+		t.Fatalf("Link = %v, committed = %v", err, link.committed)
+	}
+	q := link.queries[0]
+	assertContains(t, q, "$a iid 0x1;")
+	assertContains(t, q, "$b iid 0x2;")
+	assertContains(t, q, `$r isa friendship, links (friend: $a, friend: $b), has since 2020, has tag "a", has tag "b";`)
+
+	n, err := mgr.Unlink(ctx, alice, "friendship", "friend", "0x2", "friend")
+	if err != nil || n != 2 || !unlink.committed {
+		t.Fatalf("Unlink = %d, %v", n, err)
+	}
+	assertContains(t, unlink.queries[1], "$r isa friendship, links (friend: $a, friend: $b);\ndelete $r;")
+
+	if err := mgr.Link(ctx, alice, "friendship", "friend", &testCompany{}, "friend", nil); err == nil {
+		t.Error("expected error for linked instance without IID")
+	}
+	if err := mgr.Link(ctx, alice, "friendship", "friend", 42, "friend", nil); err == nil {
+		t.Error("expected error for unsupported linked value")
+	}
+}
+
+func TestLinkUnlink_RejectsInvalidInput(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	tx := &mockTx{}
+	conn := &mockConn{txs: []*mockTx{tx}}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db"))
+	alice := &testPerson{Name: "Alice"}
+	alice.SetIID("0x1")
+	forged := &testPerson{Name: "Mallory"}
+	forged.SetIID("0x1; delete $x")
+
+	tests := []struct {
+		name     string
+		a        *testPerson
+		relation string
+		roleA    string
+		b        any
+		roleB    string
+		attrs    map[string]any
+	}{
+		{"empty relation", alice, "", "friend", "0x2", "friend", nil},
+		{"relation", alice, "friendship; delete $x", "friend", "0x2", "friend", nil},
+		{"role a", alice, "friendship", "friend)", "0x2", "friend", nil},
+		{"role b", alice, "friendship", "friend", "0x2", "friend: $x", nil},
+		{"iid a", forged, "friendship", "friend", "0x2", "friend", nil},
+		{"iid b", alice, "friendship", "friend", "0x2; delete $x", "friend", nil},
+		{"attribute", alice, "friendship", "friend", "0x2", "friend", map[string]any{"since 1, has x": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := mgr.Link(ctx, tt.a, tt.relation, tt.roleA, tt.b, tt.roleB, tt.attrs); err == nil {
+				t.Error("Link: expected error")
+			}
+			if tt.attrs != nil {
+				return
+			}
+			if _, err := mgr.Unlink(ctx, tt.a, tt.relation, tt.roleA, tt.b, tt.roleB); err == nil {
+				t.Error("Unlink: expected error")
+			}
+		})
+	}
+	if conn.idx != 0 || len(tx.queries) != 0 {
+		t.Errorf("expected no transaction to be opened, got queries %v", tx.queries)
+	}
+}
+
+func TestUpdatePlayers(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	tx := &mockTx{responses: [][]map[string]any{{}, {{"_iid": "0x5"}}}}
+	mgr := MustNewManager[testEmployment](NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db"))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	job := &testEmployment{Employee: &testPerson{Name: "Alice"}, StartDate: &start}
+	job.SetIID("0x5")
+
+	if err := mgr.UpdatePlayers(ctx, job, map[string]string{"employee": "0x2"}); err != nil || !tx.committed {
+		t.Fatalf("UpdatePlayers = %v, committed = %v", err, tx.committed)
+	}
+	assertContains(t, tx.queries[0], "$r iid 0x5;\n$r links (employee: $old_employee);\ndelete\nlinks (employee: $old_employee) of $r;")
+	assertContains(t, tx.queries[1], "$new_employee iid 0x2;\ninsert\n$r links (employee: $new_employee);")
+	if job.Employee.GetIID() != "0x2" || job.Employee.Name != "" || job.StartDate != &start {
+		t.Errorf("job = %+v", job)
+	}
+
+	// A missing player rolls back and leaves the instance untouched.
+	tx = &mockTx{responses: [][]map[string]any{{}, {}}}
+	mgr = MustNewManager[testEmployment](NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db"))
+	err := mgr.UpdatePlayers(ctx, job, map[string]string{"employer": "0x9"})
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || tx.committed || job.Employer != nil {
+		t.Errorf("err = %v, committed = %v", err, tx.committed)
+	}
+
+	for _, players := range []map[string]string{{"boss": "0x2"}, {"employee": "alice"}} {
+		if err := mgr.UpdatePlayers(ctx, job, players); err == nil {
+			t.Errorf("expected error for %v", players)
+		}
+	}
+}
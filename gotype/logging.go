@@ -0,0 +1,76 @@
+package gotype
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ContextFieldsFunc extracts request-scoped attributes, such as request or
+// tenant IDs, from the context a query runs under.
+type ContextFieldsFunc func(ctx context.Context) []slog.Attr
+
+// WithLogger makes the Database log every query to l as a "typedb.query"
+// record carrying the transaction type, query text, duration and row count.
+// Successful queries are logged at debug level, failed ones at error level
+// with the error attached.
+func WithLogger(l *slog.Logger) DatabaseOption {
+	return func(db *Database) { db.logger = l }
+}
+
+// WithContextFields makes the Database attach the attributes returned by fn
+// to every query it logs, both through WithLogger and WithDebug. Manager
+// operations pass their context down to the transaction, so a middleware
+// that stores a request ID in the context gets it on every query:
+//
+//	gotype.WithContextFields(func(ctx context.Context) []slog.Attr {
+//		return []slog.Attr{slog.String("request_id", requestID(ctx))}
+//	})
+//
+// Queries run through Tx.Query, which takes no context, use the context the
+// transaction was opened with.
+func WithContextFields(fn ContextFieldsFunc) DatabaseOption {
+	return func(db *Database) { db.ctxFields = fn }
+}
+
+// ContextFields returns the attributes WithContextFields extracts from ctx,
+// or nil if none is configured. Tracing integrations can use it to tag spans
+// with the same fields as the query logs.
+func (db *Database) ContextFields(ctx context.Context) []slog.Attr {
+	if db.ctxFields == nil || ctx == nil {
+		return nil
+	}
+	return db.ctxFields(ctx)
+}
+
+// logQuery reports one executed query to the slog logger and the debug dump.
+func (db *Database) logQuery(ctx context.Context, txType TransactionType, query string, rows []map[string]any, count int, dur time.Duration, err error) {
+	if db.logger == nil && db.debug == nil {
+		return
+	}
+	fields := db.ContextFields(ctx)
+	db.debug.logQuery(txType, query, rows, count, dur, err, fields)
+	if db.logger == nil {
+		return
+	}
+
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelError
+	}
+	if !db.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := make([]slog.Attr, 0, 5+len(fields))
+	attrs = append(attrs,
+		slog.String("tx", txTypeName(txType)),
+		slog.String("query", query),
+		slog.Duration("duration", dur),
+		slog.Int("rows", count),
+	)
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	attrs = append(attrs, fields...)
+	db.logger.LogAttrs(ctx, level, "typedb.query", attrs...)
+}
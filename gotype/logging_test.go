@@ -0,0 +1,74 @@
+package gotype
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func requestIDFields(ctx context.Context) []slog.Attr {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return []slog.Attr{slog.String("request_id", id)}
+	}
+	return nil
+}
+
+func TestWithLogger_ContextFieldsOnManagerQueries(t *testing.T) {
+	registerTestTypes(t)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	conn := &mockConn{txs: []*mockTx{
+		{responses: [][]map[string]any{{{"name": "a"}, {"name": "b"}}}},
+	}}
+	db := NewDatabase(conn, "test_db", WithLogger(logger), WithContextFields(requestIDFields))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-42")
+	if _, err := MustNewManager[testPerson](db).All(ctx); err != nil {
+		t.Fatalf("All: %v", err)
+	}
+
+	out := buf.String()
+	assertContains(t, out, "level=DEBUG msg=typedb.query tx=read")
+	assertContains(t, out, "rows=2")
+	assertContains(t, out, "request_id=req-42")
+}
+
+func TestWithLogger_ErrorsAtErrorLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	db := NewDatabase(&failingQueryConn{}, "test_db", WithLogger(logger))
+
+	if _, err := db.ExecuteRead(context.Background(), "match $x isa person;"); err == nil {
+		t.Fatal("expected error")
+	}
+	assertContains(t, buf.String(), "level=ERROR msg=typedb.query tx=read")
+	assertContains(t, buf.String(), "error=boom")
+}
+
+func TestWithContextFields_DebugDumpAndTxQuery(t *testing.T) {
+	var buf bytes.Buffer
+	conn := &mockConn{txs: []*mockTx{{responses: [][]map[string]any{{}}}}}
+	db := NewDatabase(conn, "test_db", WithDebug(&buf), WithContextFields(requestIDFields))
+
+	// Tx.Query takes no context; the one the transaction was opened with is used.
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-7")
+	tx, err := db.TransactionContext(ctx, ReadTransaction)
+	if err != nil {
+		t.Fatalf("TransactionContext: %v", err)
+	}
+	defer tx.Close()
+	if _, err := tx.Query("match $x isa person;"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	assertContains(t, buf.String(), " 0 rows request_id=req-7\n")
+
+	if got := db.ContextFields(ctx); len(got) != 1 || got[0].Value.String() != "req-7" {
+		t.Errorf("ContextFields = %v", got)
+	}
+	if got := NewDatabase(conn, "test_db").ContextFields(ctx); got != nil {
+		t.Errorf("ContextFields without option = %v, want nil", got)
+	}
+}
@@ -256,7 +256,13 @@ func diffRelations(diff *SchemaDiff, desired []tqlgen.RelationSpec, currentRelat
 // DiffSchemaFromRegistry compares the currently registered Go models against
 // the provided database schema.
 func DiffSchemaFromRegistry(currentDB *tqlgen.ParsedSchema) *SchemaDiff {
-	desired := registryToParseSchema()
+	return globalRegistry.DiffSchema(currentDB)
+}
+
+// DiffSchema compares the models registered in r against the provided
+// database schema.
+func (r *Registry) DiffSchema(currentDB *tqlgen.ParsedSchema) *SchemaDiff {
+	desired := registryToParseSchema(r)
 	return DiffSchema(desired, currentDB)
 }
 
@@ -278,7 +284,7 @@ func MigrateFromSchema(ctx context.Context, db *Database, currentSchemaStr strin
 		return nil, fmt.Errorf("migrate: parse current schema: %w", err)
 	}
 
-	diff := DiffSchemaFromRegistry(current)
+	diff := db.Registry().DiffSchema(current)
 	if diff.IsEmpty() {
 		return diff, nil
 	}
@@ -296,7 +302,7 @@ func MigrateFromSchema(ctx context.Context, db *Database, currentSchemaStr strin
 // MigrateFromEmpty applies the complete schema defined by registered Go models
 // to an empty database.
 func MigrateFromEmpty(ctx context.Context, db *Database) error {
-	schema := GenerateSchema()
+	schema := db.Registry().GenerateSchema()
 	if schema == "" {
 		return nil
 	}
@@ -341,7 +347,7 @@ func SyncSchema(ctx context.Context, db *Database, opts ...SyncSchemaOption) (*S
 		return nil, fmt.Errorf("sync schema: parse current schema: %w", err)
 	}
 
-	diff := DiffSchemaFromRegistry(current)
+	diff := db.Registry().DiffSchema(current)
 
 	if cfg.skipIfMatch && diff.IsEmpty() && !diff.HasBreakingChanges() {
 		return diff, nil
@@ -371,8 +377,8 @@ func SyncSchema(ctx context.Context, db *Database, opts ...SyncSchemaOption) (*S
 
 // registryToParseSchema converts the registered types into a tqlgen.ParsedSchema
 // for comparison with the database schema.
-func registryToParseSchema() *tqlgen.ParsedSchema {
-	types := RegisteredTypes()
+func registryToParseSchema(r *Registry) *tqlgen.ParsedSchema {
+	types := r.RegisteredTypes()
 	schema := &tqlgen.ParsedSchema{}
 
 	attrsSeen := make(map[string]bool)
@@ -411,6 +417,7 @@ func registryToParseSchema() *tqlgen.ParsedSchema {
 			for _, role := range info.Roles {
 				r.Relates = append(r.Relates, tqlgen.RelatesSpec{
 					Role: role.RoleName,
+					Card: formatCardString(role.CardMin, role.CardMax),
 				})
 			}
 			for _, f := range info.Fields {
@@ -256,6 +256,48 @@ func (op RenameAttribute) IsReversible() bool     { return false }
 func (op RenameAttribute) IsDestructive() bool    { return false }
 func (op RenameAttribute) RollbackTypeQL() string { return "" }
 
+// --- Functions ---
+
+// AddFunction defines a TypeDB function. TypeQL is the function definition
+// ("fun name(...) -> ...: ...;") without the define keyword.
+type AddFunction struct {
+	Name   string
+	TypeQL string
+}
+
+func (op AddFunction) ToTypeQL() string       { return "define\n" + op.TypeQL }
+func (op AddFunction) IsReversible() bool     { return true }
+func (op AddFunction) IsDestructive() bool    { return false }
+func (op AddFunction) RollbackTypeQL() string { return fmt.Sprintf("undefine fun %s;", op.Name) }
+
+// RedefineFunction replaces the definition of an existing TypeDB function.
+// OldTypeQL, the previous definition, makes the operation reversible.
+type RedefineFunction struct {
+	Name      string
+	TypeQL    string
+	OldTypeQL string
+}
+
+func (op RedefineFunction) ToTypeQL() string    { return "redefine\n" + op.TypeQL }
+func (op RedefineFunction) IsReversible() bool  { return op.OldTypeQL != "" }
+func (op RedefineFunction) IsDestructive() bool { return false }
+func (op RedefineFunction) RollbackTypeQL() string {
+	if op.OldTypeQL == "" {
+		return ""
+	}
+	return "redefine\n" + op.OldTypeQL
+}
+
+// RemoveFunction removes a TypeDB function.
+type RemoveFunction struct {
+	Name string
+}
+
+func (op RemoveFunction) ToTypeQL() string       { return fmt.Sprintf("undefine fun %s;", op.Name) }
+func (op RemoveFunction) IsReversible() bool     { return false }
+func (op RemoveFunction) IsDestructive() bool    { return true }
+func (op RemoveFunction) RollbackTypeQL() string { return "" }
+
 // --- Arbitrary TypeQL ---
 
 // RunTypeQL executes arbitrary TypeQL as a migration step.
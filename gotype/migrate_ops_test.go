@@ -79,6 +79,34 @@ func TestRemoveRole_ToTypeQL(t *testing.T) {
 	}
 }
 
+func TestFunctionOperations(t *testing.T) {
+	def := "fun adults() -> { person }:\n  match $p isa person;\n  return { $p };"
+	add := AddFunction{Name: "adults", TypeQL: def}
+	if got := add.ToTypeQL(); got != "define\n"+def {
+		t.Errorf("AddFunction: got %q", got)
+	}
+	if !add.IsReversible() || add.RollbackTypeQL() != "undefine fun adults;" {
+		t.Errorf("AddFunction rollback: %q", add.RollbackTypeQL())
+	}
+
+	redef := RedefineFunction{Name: "adults", TypeQL: def}
+	if got := redef.ToTypeQL(); got != "redefine\n"+def {
+		t.Errorf("RedefineFunction: got %q", got)
+	}
+	if redef.IsReversible() {
+		t.Error("RedefineFunction without OldTypeQL should not be reversible")
+	}
+	redef.OldTypeQL = "fun adults() -> { person }:\n  match $p isa person, has age $a; $a >= 18;\n  return { $p };"
+	if !redef.IsReversible() || redef.RollbackTypeQL() != "redefine\n"+redef.OldTypeQL {
+		t.Errorf("RedefineFunction rollback: %q", redef.RollbackTypeQL())
+	}
+
+	remove := RemoveFunction{Name: "adults"}
+	if remove.ToTypeQL() != "undefine fun adults;" || !remove.IsDestructive() || remove.IsReversible() {
+		t.Errorf("RemoveFunction: %q", remove.ToTypeQL())
+	}
+}
+
 func TestSchemaDiff_BreakingChanges(t *testing.T) {
 	diff := &SchemaDiff{
 		RemoveTypes: []string{"obsolete-entity"},
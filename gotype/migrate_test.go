@@ -316,7 +316,7 @@ func TestDiffSchemaFromRegistry(t *testing.T) {
 
 func TestRegistryToParseSchema(t *testing.T) {
 	registerTestTypes(t)
-	schema := registryToParseSchema()
+	schema := registryToParseSchema(globalRegistry)
 
 	// Should have attributes from testPerson (name, email, age)
 	attrNames := make(map[string]bool)
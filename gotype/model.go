@@ -4,9 +4,11 @@ package gotype
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"sort"
-	"strings"
 	"time"
+
+	"github.com/CaliLuke/go-typeql/tqlgen"
 )
 
 // ModelKind specifies whether a registered TypeDB model is an entity or a relation.
@@ -28,7 +30,9 @@ type FieldInfo struct {
 	Doc string
 	// FieldName is the name of the field in the Go struct.
 	FieldName string
-	// FieldIndex is the 0-based index of the field in the Go struct.
+	// FieldIndex is the 0-based index of the field in the Go struct. For
+	// fields hoisted from an embedded struct it is the index of the embedding
+	// field; the full path is used internally when reading and writing values.
 	FieldIndex int
 	// FieldType is the reflection type of the field.
 	FieldType reflect.Type
@@ -40,6 +44,13 @@ type FieldInfo struct {
 	ElemType reflect.Type
 	// ValueType is the TypeDB value type (e.g., "string", "long", "boolean").
 	ValueType string
+	// scanner is true when the attribute type implements AttrScanner.
+	scanner bool
+	// index is the reflect index path of the field, including embedded structs.
+	index []int
+	// defaultValue is the parsed "default=" tag value, if any. For pointer
+	// fields it holds the element value.
+	defaultValue reflect.Value
 	// timeLayoutHint caches the last successful datetime parsing layout index.
 	timeLayoutHint uint32
 }
@@ -65,9 +76,25 @@ type ModelInfo struct {
 	Fields []FieldInfo
 	// Roles is a list of metadata for each role player field (only for relations).
 	Roles []RoleInfo
+	// Links is a list of metadata for each typed role-player field, tagged
+	// "rel:relation.role" (only for entities).
+	Links []LinkInfo
+	// Hierarchy is set for relations registered WithHierarchy.
+	Hierarchy *Hierarchy
 	// KeyFields is a subset of Fields containing attributes marked as keys.
 	KeyFields      []FieldInfo
 	baseFieldIndex int
+	// extraField is the optional map[string]any field tagged ",extra".
+	extraField *FieldInfo
+	// registry is the registry the model was registered in.
+	registry *Registry
+	// hasTimestamps is true when a field is tagged autocreate or autoupdate.
+	hasTimestamps bool
+	// hasDefaults is true when a field declares a "default=" value.
+	hasDefaults bool
+	// templates holds query fragments compiled at registration; nil for
+	// models built directly with ExtractModelInfo.
+	templates *queryTemplates
 }
 
 // FieldByName retrieves FieldInfo by the Go struct field name.
@@ -93,6 +120,12 @@ func (m *ModelInfo) FieldByAttrName(attrName string) (FieldInfo, bool) {
 // ExtractModelInfo analyzes a Go struct type and extracts its TypeDB model metadata.
 // The struct must embed BaseEntity or BaseRelation to be a valid model.
 func ExtractModelInfo(t reflect.Type) (*ModelInfo, error) {
+	return extractModelInfo(t, tqlgen.KebabCase{}, "")
+}
+
+// extractModelInfo is ExtractModelInfo with the naming strategy of the
+// registry and the type name, if it is not derived from the struct name.
+func extractModelInfo(t reflect.Type, naming tqlgen.NamingStrategy, typeName string) (*ModelInfo, error) {
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
@@ -114,7 +147,10 @@ func ExtractModelInfo(t reflect.Type) (*ModelInfo, error) {
 	info.baseFieldIndex = baseFieldIndex
 
 	// Default type name: kebab-case struct name (e.g. UserAccount → user-account)
-	info.TypeName = toKebabCase(t.Name())
+	info.TypeName = typeName
+	if info.TypeName == "" {
+		info.TypeName = naming.TypeName(t.Name())
+	}
 	info.Doc = schemaDocForType(t)
 	info.Meta = schemaMetaForType(t)
 
@@ -123,43 +159,101 @@ func ExtractModelInfo(t reflect.Type) (*ModelInfo, error) {
 	info.Roles = make([]RoleInfo, 0, max(1, fieldCount/2))
 	info.KeyFields = make([]FieldInfo, 0, 1)
 
-	// Scan fields
-	for field := range t.Fields() {
+	if err := collectModelFields(info, t, t, nil, naming); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// collectModelFields scans the fields of st (the root type t or a struct
+// embedded in it) and appends attribute and role fields to info. Tagged fields
+// of embedded plain structs are hoisted into the owning model. Attribute
+// fields whose tag has no name are named by naming.
+func collectModelFields(info *ModelInfo, t, st reflect.Type, prefix []int, naming tqlgen.NamingStrategy) error {
+	for field := range st.Fields() {
+		tagStr, tagged := field.Tag.Lookup("typedb")
+		index := append(append([]int(nil), prefix...), field.Index...)
+
+		// Skip the embedded base types; flatten other embedded structs,
+		// including unexported ones whose exported fields are promoted.
+		if field.Anonymous {
+			if isEmbeddedFieldGroup(field, tagStr) {
+				if err := collectModelFields(info, t, field.Type, index, naming); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
 		// Skip unexported fields
 		if !field.IsExported() {
 			continue
 		}
 
-		// Skip the embedded base types
-		if field.Anonymous {
+		if !tagged || tagStr == "-" {
 			continue
 		}
 
-		tagStr := field.Tag.Get("typedb")
-		if tagStr == "" || tagStr == "-" {
-			continue
+		// Fields shadowed by the owning struct are not visible to Go code
+		// and are not hoisted either.
+		if len(prefix) > 0 {
+			if visible, ok := t.FieldByName(field.Name); !ok || !slices.Equal(visible.Index, index) {
+				continue
+			}
 		}
 
 		tag, err := ParseTag(tagStr)
 		if err != nil {
-			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			return fmt.Errorf("field %s: %w", field.Name, err)
 		}
 
 		if tag.Skip {
 			continue
 		}
 
+		if tag.Extra {
+			if field.Type != extraFieldType {
+				return fmt.Errorf("field %s: extra field must be map[string]any, got %s", field.Name, field.Type)
+			}
+			if info.extraField != nil {
+				return fmt.Errorf("field %s: duplicate extra field (already %s)", field.Name, info.extraField.FieldName)
+			}
+			fi := buildFieldInfo(field, index[0], tag)
+			fi.index = index
+			info.extraField = &fi
+			continue
+		}
+
 		// Handle abstract flag
 		if tag.Abstract {
 			info.IsAbstract = true
 		}
 
-		if tag.IsRole() {
+		if tag.IsLink() {
+			if info.Kind != ModelKindEntity {
+				return fmt.Errorf("field %s: typed role player declared on a relation", field.Name)
+			}
+			if field.Type.Kind() != reflect.Pointer || field.Type.Elem().Kind() != reflect.Struct {
+				return fmt.Errorf("field %s: typed role player must be a pointer to struct, got %s", field.Name, field.Type)
+			}
+			info.Links = append(info.Links, LinkInfo{
+				FieldName:  field.Name,
+				FieldIndex: index[0],
+				Relation:   tag.LinkRelation,
+				Role:       tag.LinkRole,
+				index:      index,
+				playerType: field.Type.Elem(),
+			})
+		} else if tag.IsRole() {
 			// Role player field
 			role := RoleInfo{
 				RoleName:   tag.RoleName,
 				FieldName:  field.Name,
-				FieldIndex: field.Index[0],
+				FieldIndex: index[0],
+				CardMin:    tag.CardMin,
+				CardMax:    tag.CardMax,
+				index:      index,
 			}
 
 			// Determine player type name
@@ -173,12 +267,31 @@ func ExtractModelInfo(t reflect.Type) (*ModelInfo, error) {
 					ft = ft.Elem()
 				}
 			}
-			role.PlayerTypeName = toKebabCase(ft.Name())
+			role.PlayerTypeName = naming.TypeName(ft.Name())
+			role.playerType = ft
 
 			info.Roles = append(info.Roles, role)
 		} else {
 			// Attribute field
-			fi := buildFieldInfo(field, field.Index[0], tag)
+			if tag.AutoCreate || tag.AutoUpdate {
+				if !isTimestampField(field.Type) {
+					return fmt.Errorf("field %s: autocreate/autoupdate requires time.Time or *time.Time, got %s", field.Name, field.Type)
+				}
+				info.hasTimestamps = true
+			}
+			if tag.Name == "" {
+				tag.Name = naming.AttributeName(info.TypeName, field.Name)
+			}
+			fi := buildFieldInfo(field, index[0], tag)
+			fi.index = index
+			if tag.Default != nil {
+				def, err := parseFieldDefault(&fi, *tag.Default)
+				if err != nil {
+					return fmt.Errorf("field %s: %w", field.Name, err)
+				}
+				fi.defaultValue = def
+				info.hasDefaults = true
+			}
 			info.Fields = append(info.Fields, fi)
 
 			if tag.Key {
@@ -186,8 +299,20 @@ func ExtractModelInfo(t reflect.Type) (*ModelInfo, error) {
 			}
 		}
 	}
+	return nil
+}
 
-	return info, nil
+// isEmbeddedFieldGroup reports whether an anonymous field is a plain struct
+// whose tagged fields should be hoisted into the owning model.
+func isEmbeddedFieldGroup(field reflect.StructField, tagStr string) bool {
+	if tagStr != "" || field.Type.Kind() != reflect.Struct {
+		return false
+	}
+	switch field.Type {
+	case baseEntityType, baseRelationType, reflect.TypeOf(time.Time{}):
+		return false
+	}
+	return !isAttrCodec(field.Type)
 }
 
 var (
@@ -287,19 +412,64 @@ func buildFieldInfo(field reflect.StructField, index int, tag FieldTag) FieldInf
 		fi.ElemType = ft.Elem()
 		ft = ft.Elem()
 	}
-	if ft.Kind() == reflect.Slice {
+	if ft.Kind() == reflect.Slice && !isAttrCodec(ft) {
 		fi.IsSlice = true
 		fi.ElemType = ft.Elem()
 		ft = ft.Elem()
 		if ft.Kind() == reflect.Pointer {
 			ft = ft.Elem()
+		} else {
+			fi.scanner = isAttrScanner(ft)
 		}
+	} else {
+		fi.scanner = isAttrScanner(ft)
 	}
 
 	fi.ValueType = goTypeToTypeDB(ft)
 	return fi
 }
 
+// fieldValue returns the struct field described by fi within v, following
+// embedded structs for hoisted fields.
+func (fi *FieldInfo) fieldValue(v reflect.Value) reflect.Value {
+	if len(fi.index) == 0 {
+		return v.Field(fi.FieldIndex)
+	}
+	return v.FieldByIndex(fi.index)
+}
+
+// fieldValue returns the struct field described by r within v, following
+// embedded structs for hoisted fields.
+func (r *RoleInfo) fieldValue(v reflect.Value) reflect.Value {
+	if len(r.index) == 0 {
+		return v.Field(r.FieldIndex)
+	}
+	return v.FieldByIndex(r.index)
+}
+
+// isRequired reports whether the field must have a value: non-pointer scalar
+// fields, and slices with a minimum cardinality above zero.
+func (fi *FieldInfo) isRequired() bool {
+	if fi.IsPointer {
+		return false
+	}
+	if fi.IsSlice {
+		return fi.Tag.CardMin != nil && *fi.Tag.CardMin > 0
+	}
+	return true
+}
+
+// playerInfo resolves the registered model of the role player in reg. The Go
+// type is preferred so players registered with a type: override are found.
+func (r *RoleInfo) playerInfo(reg *Registry) (*ModelInfo, bool) {
+	if r.playerType != nil {
+		if info, ok := reg.LookupType(r.playerType); ok {
+			return info, true
+		}
+	}
+	return reg.Lookup(r.PlayerTypeName)
+}
+
 // ToDict converts a registered model instance to a map[string]any using
 // TypeDB attribute names as keys. Includes "_iid" if set.
 func ToDict[T any](instance *T) (map[string]any, error) {
@@ -313,12 +483,11 @@ func ToDict[T any](instance *T) (map[string]any, error) {
 	if !ok {
 		return nil, fmt.Errorf("gotype: type %s is not registered", t.Name())
 	}
+	return toDictWithInfo(reflect.ValueOf(instance).Elem(), info)
+}
 
-	v := reflect.ValueOf(instance)
-	if v.Kind() == reflect.Pointer {
-		v = v.Elem()
-	}
-
+// toDictWithInfo is ToDict for an already-resolved model.
+func toDictWithInfo(v reflect.Value, info *ModelInfo) (map[string]any, error) {
 	result := make(map[string]any)
 
 	// Include IID if present
@@ -328,16 +497,27 @@ func ToDict[T any](instance *T) (map[string]any, error) {
 	}
 
 	for _, fi := range info.Fields {
-		field := v.Field(fi.FieldIndex)
+		field := fi.fieldValue(v)
 
 		if fi.IsPointer {
 			if field.IsNil() {
 				// Omit nil optional fields
 				continue
 			}
-			result[fi.Tag.Name] = field.Elem().Interface()
-		} else {
-			result[fi.Tag.Name] = field.Interface()
+			field = field.Elem()
+		}
+		val, err := attrValueOf(field)
+		if err != nil {
+			return nil, fmt.Errorf("gotype: field %s: %w", fi.FieldName, err)
+		}
+		result[fi.Tag.Name] = val
+	}
+	if info.extraField != nil {
+		extra, _ := info.extraField.fieldValue(v).Interface().(map[string]any)
+		for name, val := range extra {
+			if !isMappedAttr(info, name) {
+				result[name] = val
+			}
 		}
 	}
 
@@ -382,23 +562,9 @@ func lookupStrategy[T any]() (*ModelInfo, ModelStrategy, error) {
 }
 
 // toKebabCase converts a PascalCase Go struct name to kebab-case.
-// e.g. "UserAccount" → "user-account", "HTTPServer" → "httpserver"
+// e.g. "UserAccount" → "user-account", "HTTPServer" → "h-t-t-p-server"
 func toKebabCase(name string) string {
-	if name == "" {
-		return ""
-	}
-	var b strings.Builder
-	for i, r := range name {
-		if r >= 'A' && r <= 'Z' {
-			if i > 0 {
-				b.WriteByte('-')
-			}
-			b.WriteByte(byte(r - 'A' + 'a'))
-		} else {
-			b.WriteRune(r)
-		}
-	}
-	return b.String()
+	return tqlgen.KebabCase{}.TypeName(name)
 }
 
 // goTypeToTypeDB maps Go types to TypeDB value type strings.
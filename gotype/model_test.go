@@ -171,6 +171,85 @@ func TestExtractModelInfo_NoBase(t *testing.T) {
 	}
 }
 
+type testAudit struct {
+	CreatedBy string     `typedb:"created-by"`
+	CreatedAt *time.Time `typedb:"created-at"`
+	internal  string
+}
+
+type testAuditedDoc struct {
+	BaseEntity
+	testAudit
+	Title string `typedb:"title,key"`
+}
+
+type testShadowedAudit struct {
+	BaseEntity
+	testAudit
+	Name      string `typedb:"name,key"`
+	CreatedBy string `typedb:"author"`
+}
+
+func TestExtractModelInfo_EmbeddedStructFlattened(t *testing.T) {
+	info, err := ExtractModelInfo(reflect.TypeOf(testAuditedDoc{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, fi := range info.Fields {
+		names = append(names, fi.Tag.Name)
+	}
+	want := []string{"created-by", "created-at", "title"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("fields = %v, want %v", names, want)
+	}
+	if got := info.Fields[0].index; !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("created-by index = %v, want [1 0]", got)
+	}
+}
+
+func TestExtractModelInfo_EmbeddedStructShadowed(t *testing.T) {
+	info, err := ExtractModelInfo(reflect.TypeOf(testShadowedAudit{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, fi := range info.Fields {
+		names = append(names, fi.Tag.Name)
+	}
+	want := []string{"created-at", "name", "author"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("fields = %v, want %v", names, want)
+	}
+}
+
+func TestEmbeddedStruct_InsertAndHydrate(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testAuditedDoc]()
+
+	doc := &testAuditedDoc{Title: "spec"}
+	doc.CreatedBy = "alice"
+	q, err := ToInsertQuery(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(q, `has created-by "alice"`) {
+		t.Errorf("insert missing hoisted field:\n%s", q)
+	}
+
+	got, err := HydrateNew[testAuditedDoc](map[string]any{
+		"title":      "spec",
+		"created-by": "bob",
+		"created-at": "2024-01-02T03:04:05",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.CreatedBy != "bob" || got.CreatedAt == nil || got.CreatedAt.Year() != 2024 {
+		t.Errorf("hydrated audit = %+v", got.testAudit)
+	}
+}
+
 func TestGoTypeToTypeDB(t *testing.T) {
 	tests := []struct {
 		goType reflect.Type
@@ -0,0 +1,236 @@
+package gotype
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// outboxSchema defines the TypeQL schema for outbox events.
+const outboxSchema = `define
+attribute outbox-id, value string;
+attribute outbox-topic, value string;
+attribute outbox-payload, value string;
+attribute outbox-created-at, value datetime;
+attribute outbox-attempts, value integer;
+attribute outbox-last-error, value string;
+attribute outbox-dispatched-at, value datetime;
+entity outbox-event,
+    owns outbox-id @key,
+    owns outbox-topic,
+    owns outbox-payload,
+    owns outbox-created-at,
+    owns outbox-attempts,
+    owns outbox-last-error,
+    owns outbox-dispatched-at;`
+
+// OutboxEvent is an event recorded by Outbox.Add and waiting for dispatch.
+type OutboxEvent struct {
+	// ID identifies the event; consumers can use it to drop duplicates.
+	ID      string
+	Topic   string
+	Payload []byte
+	// CreatedAt is when the event was added.
+	CreatedAt time.Time
+	// Attempts counts the failed dispatches so far.
+	Attempts int
+}
+
+// OutboxDispatcher publishes one event, typically to a message broker. A
+// nil error marks the event dispatched.
+type OutboxDispatcher func(ctx context.Context, event OutboxEvent) error
+
+// OutboxOption configures an Outbox.
+type OutboxOption func(*Outbox)
+
+// WithOutboxBatchSize sets how many events one DispatchPending call reads
+// (default 100).
+func WithOutboxBatchSize(n int) OutboxOption {
+	return func(o *Outbox) { o.batchSize = n }
+}
+
+// WithOutboxMaxAttempts sets how many failed dispatches an event gets
+// before DispatchPending stops picking it up (default 10). The event stays
+// in the database with its last error for inspection.
+func WithOutboxMaxAttempts(n int) OutboxOption {
+	return func(o *Outbox) { o.maxAttempts = n }
+}
+
+// WithOutboxInterval sets how long Run waits between polls (default 1s).
+func WithOutboxInterval(d time.Duration) OutboxOption {
+	return func(o *Outbox) { o.interval = d }
+}
+
+// Outbox implements the transactional outbox pattern: Add records an event
+// in the transaction of the domain writes it describes, so the event exists
+// if and only if they commit, and DispatchPending or Run later hands pending
+// events to a dispatcher and marks them dispatched.
+//
+// Delivery is at least once: an event whose dispatch succeeded but whose
+// mark failed, or that two pollers picked up together, is dispatched again.
+// Consumers should drop duplicates by OutboxEvent.ID.
+type Outbox struct {
+	db          *Database
+	batchSize   int
+	maxAttempts int
+	interval    time.Duration
+}
+
+// NewOutbox returns an Outbox storing events in db.
+func NewOutbox(db *Database, opts ...OutboxOption) *Outbox {
+	o := &Outbox{db: db, batchSize: 100, maxAttempts: 10, interval: time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// EnsureSchema defines the outbox-event type and its attributes. It is
+// idempotent.
+func (o *Outbox) EnsureSchema(ctx context.Context) error {
+	return o.db.ExecuteSchema(ctx, outboxSchema)
+}
+
+// Add records an event in tc, which must be a write transaction; otherwise
+// the error wraps a *TransactionTypeError. The event becomes visible to
+// DispatchPending when tc commits. It returns the event ID.
+func (o *Outbox) Add(ctx context.Context, tc *TransactionContext, topic string, payload []byte) (string, error) {
+	if tc.txType < WriteTransaction {
+		return "", fmt.Errorf("outbox: add %s: %w", topic, &TransactionTypeError{Have: tc.txType, Need: WriteTransaction})
+	}
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", fmt.Errorf("outbox: generate id: %w", err)
+	}
+	id := hex.EncodeToString(raw[:])
+	query := fmt.Sprintf(`insert
+$e isa outbox-event,
+has outbox-id %s,
+has outbox-topic %s,
+has outbox-payload %s,
+has outbox-created-at %s,
+has outbox-attempts 0;`,
+		FormatValue(id), FormatValue(topic),
+		FormatValue(base64.StdEncoding.EncodeToString(payload)), FormatValue(timeNow().UTC()))
+	if _, err := tc.Tx().QueryWithContext(ctx, query); err != nil {
+		return "", fmt.Errorf("outbox: add %s: %w", topic, err)
+	}
+	return id, nil
+}
+
+// Pending returns up to the batch size of undispatched events that have
+// attempts left, oldest first.
+func (o *Outbox) Pending(ctx context.Context) ([]OutboxEvent, error) {
+	query := fmt.Sprintf(`match
+$e isa outbox-event, has outbox-created-at $created, has outbox-attempts $attempts;
+not { $e has outbox-dispatched-at $done; };
+$attempts < %d;
+sort $created;
+limit %d;
+fetch {
+  "id": $e.outbox-id,
+  "topic": $e.outbox-topic,
+  "payload": $e.outbox-payload,
+  "created-at": $created,
+  "attempts": $attempts
+};`, o.maxAttempts, o.batchSize)
+
+	results, err := o.db.ExecuteRead(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: read pending: %w", err)
+	}
+	events := make([]OutboxEvent, 0, len(results))
+	for _, row := range results {
+		flat := unwrapResult(row)
+		ev := OutboxEvent{Attempts: int(toFloat64(flat["attempts"]))}
+		ev.ID, _ = flat["id"].(string)
+		ev.Topic, _ = flat["topic"].(string)
+		if s, ok := flat["payload"].(string); ok {
+			if ev.Payload, err = base64.StdEncoding.DecodeString(s); err != nil {
+				return nil, fmt.Errorf("outbox: event %s: decode payload: %w", ev.ID, err)
+			}
+		}
+		switch v := flat["created-at"].(type) {
+		case time.Time:
+			ev.CreatedAt = v
+		case string:
+			if t, err := ParseAttributeValue("datetime", v); err == nil {
+				ev.CreatedAt = t.(time.Time)
+			}
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// DispatchPending hands each pending event to dispatch, in creation order,
+// and marks it dispatched when dispatch succeeds. A failed dispatch is
+// recorded on the event, which is retried by later calls until it runs out
+// of attempts. It returns the number of events dispatched; the error reports
+// only failures to read or update the outbox.
+func (o *Outbox) DispatchPending(ctx context.Context, dispatch OutboxDispatcher) (int, error) {
+	events, err := o.Pending(ctx)
+	if err != nil {
+		return 0, err
+	}
+	dispatched := 0
+	for _, ev := range events {
+		if err := ctx.Err(); err != nil {
+			return dispatched, err
+		}
+		if derr := dispatch(ctx, ev); derr != nil {
+			if err := o.recordFailure(ctx, ev, derr); err != nil {
+				return dispatched, err
+			}
+			continue
+		}
+		query := fmt.Sprintf(`match
+$e isa outbox-event, has outbox-id %s;
+insert $e has outbox-dispatched-at %s;`, FormatValue(ev.ID), FormatValue(timeNow().UTC()))
+		if _, err := o.db.ExecuteWrite(ctx, query); err != nil {
+			return dispatched, fmt.Errorf("outbox: mark %s dispatched: %w", ev.ID, err)
+		}
+		dispatched++
+	}
+	return dispatched, nil
+}
+
+func (o *Outbox) recordFailure(ctx context.Context, ev OutboxEvent, cause error) error {
+	query := fmt.Sprintf(`match
+$e isa outbox-event, has outbox-id %s;
+try { $e has outbox-attempts $old0; };
+try { $e has outbox-last-error $old1; };
+delete
+try { $old0 of $e; };
+try { $old1 of $e; };
+insert $e has outbox-attempts %d, has outbox-last-error %s;`,
+		FormatValue(ev.ID), ev.Attempts+1, FormatValue(cause.Error()))
+	if _, err := o.db.ExecuteWrite(ctx, query); err != nil {
+		return fmt.Errorf("outbox: record failure of %s: %w", ev.ID, err)
+	}
+	return nil
+}
+
+// Run polls with DispatchPending until ctx is done, waiting for the
+// interval after every poll that did not dispatch a full batch. Outbox errors are
+// logged through the Database's logger (WithLogger) and polling continues.
+// It returns ctx's error.
+func (o *Outbox) Run(ctx context.Context, dispatch OutboxDispatcher) error {
+	for {
+		n, err := o.DispatchPending(ctx, dispatch)
+		if err != nil && ctx.Err() == nil && o.db.logger != nil {
+			o.db.logger.ErrorContext(ctx, "outbox poll failed", "error", err)
+		}
+		if n >= o.batchSize && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(o.interval):
+		}
+	}
+}
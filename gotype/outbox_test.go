@@ -0,0 +1,158 @@
+package gotype
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutbox_EnsureSchema(t *testing.T) {
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test")
+
+	if err := NewOutbox(db).EnsureSchema(context.Background()); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	assertContains(t, tx.queries[0], "entity outbox-event")
+	assertContains(t, tx.queries[0], "owns outbox-id @key")
+	if !tx.committed {
+		t.Error("schema transaction not committed")
+	}
+}
+
+func TestOutbox_AddUsesCallerTransaction(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fixTimeNow(t, now)
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test")
+	tc, err := db.Begin(WriteTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	id, err := NewOutbox(db).Add(context.Background(), tc, "orders", []byte(`{"id":1}`))
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if len(id) != 32 {
+		t.Errorf("id = %q, want 32 hex digits", id)
+	}
+	q := tx.queries[0]
+	assertContains(t, q, "isa outbox-event")
+	assertContains(t, q, `has outbox-id "`+id+`"`)
+	assertContains(t, q, `has outbox-topic "orders"`)
+	assertContains(t, q, `has outbox-payload "eyJpZCI6MX0="`)
+	assertContains(t, q, "has outbox-created-at "+FormatValue(now))
+	assertContains(t, q, "has outbox-attempts 0")
+	if tx.committed {
+		t.Error("Add committed the caller's transaction")
+	}
+}
+
+func TestOutbox_AddRejectsReadTransaction(t *testing.T) {
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test")
+	tc, err := db.Begin(ReadTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	_, err = NewOutbox(db).Add(context.Background(), tc, "orders", nil)
+	var typeErr *TransactionTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Add: got %v, want *TransactionTypeError", err)
+	}
+	if len(tx.queries) != 0 {
+		t.Errorf("expected no queries, got %v", tx.queries)
+	}
+}
+
+func TestOutbox_DispatchPending(t *testing.T) {
+	fixTimeNow(t, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	read := &mockTx{responses: [][]map[string]any{{
+		{"id": "a1", "topic": "orders", "payload": "b25l", "created-at": "2025-06-01T10:00:00", "attempts": float64(0)},
+		{"id": "b2", "topic": "orders", "payload": map[string]any{"value": "dHdv"}, "created-at": time.Date(2025, 6, 1, 11, 0, 0, 0, time.UTC), "attempts": map[string]any{"value": float64(2)}},
+	}}}
+	markA, failB := &mockTx{}, &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{read, markA, failB}}, "test")
+	outbox := NewOutbox(db, WithOutboxBatchSize(5), WithOutboxMaxAttempts(3))
+
+	var seen []OutboxEvent
+	n, err := outbox.DispatchPending(context.Background(), func(ctx context.Context, ev OutboxEvent) error {
+		seen = append(seen, ev)
+		if ev.ID == "b2" {
+			return errors.New(`broker said "no"`)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DispatchPending: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("dispatched %d, want 1", n)
+	}
+
+	assertContains(t, read.queries[0], "not { $e has outbox-dispatched-at $done; };")
+	assertContains(t, read.queries[0], "$attempts < 3;")
+	assertContains(t, read.queries[0], "sort $created;")
+	assertContains(t, read.queries[0], "limit 5;")
+
+	if len(seen) != 2 || string(seen[0].Payload) != "one" || string(seen[1].Payload) != "two" {
+		t.Fatalf("dispatched events = %+v", seen)
+	}
+	if seen[0].CreatedAt.Hour() != 10 || seen[1].CreatedAt.Hour() != 11 || seen[1].Attempts != 2 {
+		t.Errorf("decoded events = %+v", seen)
+	}
+
+	assertContains(t, markA.queries[0], `has outbox-id "a1"`)
+	assertContains(t, markA.queries[0], "insert $e has outbox-dispatched-at 2025-06-01T12:00:00")
+	if !markA.committed {
+		t.Error("dispatch mark not committed")
+	}
+	assertContains(t, failB.queries[0], `has outbox-id "b2"`)
+	assertContains(t, failB.queries[0], `has outbox-attempts 3, has outbox-last-error "broker said \"no\""`)
+	if strings.Contains(failB.queries[0], "outbox-dispatched-at") {
+		t.Error("failed event marked dispatched")
+	}
+}
+
+func TestOutbox_DispatchPending_StoreError(t *testing.T) {
+	read := &mockTx{responses: [][]map[string]any{{
+		{"id": "a1", "topic": "t", "payload": "", "attempts": float64(0)},
+	}}}
+	// No transaction left for the mark.
+	db := NewDatabase(&mockConn{txs: []*mockTx{read}}, "test")
+
+	n, err := NewOutbox(db).DispatchPending(context.Background(), func(context.Context, OutboxEvent) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "mark a1 dispatched") {
+		t.Errorf("err = %v, want mark failure", err)
+	}
+	if n != 0 {
+		t.Errorf("dispatched %d, want 0", n)
+	}
+}
+
+func TestOutbox_RunStopsWithContext(t *testing.T) {
+	var txs []*mockTx
+	for range 1000 {
+		txs = append(txs, &mockTx{})
+	}
+	db := NewDatabase(&mockConn{txs: txs}, "test")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := NewOutbox(db, WithOutboxInterval(time.Millisecond)).Run(ctx, func(context.Context, OutboxEvent) error {
+		t.Error("dispatch called with no events")
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run = %v, want deadline exceeded", err)
+	}
+	if txs[1].queries == nil {
+		t.Error("Run polled only once")
+	}
+}
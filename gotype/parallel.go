@@ -0,0 +1,63 @@
+package gotype
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// defaultParallelWorkers is the number of concurrent write transactions used
+// by InsertManyParallel when WithWorkers is not given.
+const defaultParallelWorkers = 4
+
+// ParallelOption configures InsertManyParallel.
+type ParallelOption func(*parallelConfig)
+
+type parallelConfig struct {
+	workers int
+}
+
+// WithWorkers sets the number of write transactions InsertManyParallel runs
+// concurrently. Values below 1 are treated as 1.
+func WithWorkers(n int) ParallelOption {
+	return func(c *parallelConfig) { c.workers = max(n, 1) }
+}
+
+// InsertManyParallel inserts instances using several write transactions that
+// run concurrently. The slice is split into one contiguous shard per worker
+// and each shard is inserted as with InsertMany.
+//
+// Shards commit independently: if some fail, the others are still committed
+// and their instances get IIDs. The returned error joins the shard errors in
+// slice order; each names the index of the failing instance in instances.
+// The connection must support concurrent transactions (see NewDatabaseWithPool).
+func (m *Manager[T]) InsertManyParallel(ctx context.Context, instances []*T, opts ...ParallelOption) error {
+	if len(instances) == 0 {
+		return nil
+	}
+	if err := checkCtx(ctx, "insert_many_parallel", m.info.TypeName); err != nil {
+		return err
+	}
+	cfg := parallelConfig{workers: defaultParallelWorkers}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	workers := min(cfg.workers, len(instances))
+	shardSize := (len(instances) + workers - 1) / workers
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		start := w * shardSize
+		if start >= len(instances) {
+			break
+		}
+		end := min(start+shardSize, len(instances))
+		wg.Go(func() {
+			errs[w] = m.insertBatch(ctx, "insert_many_parallel", instances[start:end], start)
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
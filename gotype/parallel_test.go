@@ -0,0 +1,113 @@
+package gotype
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// parallelConn hands out a fresh mockTx per transaction and is safe for
+// concurrent use. Inserts of a person named failName fail.
+type parallelConn struct {
+	mockConn
+	mu       sync.Mutex
+	txs      []*mockTx
+	failName string
+	nextIID  atomic.Int64
+}
+
+type parallelTx struct {
+	*mockTx
+	conn *parallelConn
+}
+
+func (c *parallelConn) Transaction(dbName string, txType int) (Tx, error) {
+	tx := &mockTx{}
+	c.mu.Lock()
+	c.txs = append(c.txs, tx)
+	c.mu.Unlock()
+	return &parallelTx{mockTx: tx, conn: c}, nil
+}
+
+func (t *parallelTx) QueryWithContext(ctx context.Context, query string) ([]map[string]any, error) {
+	t.queries = append(t.queries, query)
+	if t.conn.failName != "" && strings.Contains(query, fmt.Sprintf("%q", t.conn.failName)) {
+		return nil, errors.New("boom")
+	}
+	return []map[string]any{{"_iid": fmt.Sprintf("0x%d", t.conn.nextIID.Add(1))}}, nil
+}
+
+func newParallelPeople(n int) []*testPerson {
+	people := make([]*testPerson, n)
+	for i := range people {
+		people[i] = &testPerson{Name: fmt.Sprintf("p%d", i), Email: "x@example.com"}
+	}
+	return people
+}
+
+func TestManager_InsertManyParallel(t *testing.T) {
+	registerTestTypes(t)
+	conn := &parallelConn{}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db"))
+
+	people := newParallelPeople(10)
+	if err := mgr.InsertManyParallel(context.Background(), people, WithWorkers(3)); err != nil {
+		t.Fatalf("InsertManyParallel failed: %v", err)
+	}
+	if len(conn.txs) != 3 {
+		t.Errorf("expected 3 transactions, got %d", len(conn.txs))
+	}
+	for i, p := range people {
+		if p.GetIID() == "" {
+			t.Errorf("people[%d] has no IID", i)
+		}
+	}
+	for i, tx := range conn.txs {
+		if !tx.committed {
+			t.Errorf("tx %d not committed", i)
+		}
+	}
+}
+
+func TestManager_InsertManyParallel_ErrorsInOrder(t *testing.T) {
+	registerTestTypes(t)
+	conn := &parallelConn{failName: "p7"}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db"))
+
+	people := newParallelPeople(8)
+	people[1] = nil
+	err := mgr.InsertManyParallel(context.Background(), people, WithWorkers(4))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	msg := err.Error()
+	first := strings.Index(msg, "test-person[1]: instance must not be nil")
+	second := strings.Index(msg, "test-person[7]: boom")
+	if first < 0 || second < 0 || first > second {
+		t.Fatalf("expected ordered shard errors, got:\n%s", msg)
+	}
+	// Shards without failures are still committed.
+	if people[2].GetIID() == "" || people[4].GetIID() == "" {
+		t.Error("instances in successful shards should have IIDs")
+	}
+	if people[6].GetIID() != "" {
+		t.Error("instances in the failed shard must not get IIDs")
+	}
+}
+
+func TestManager_InsertManyParallel_MoreWorkersThanInstances(t *testing.T) {
+	registerTestTypes(t)
+	conn := &parallelConn{}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db"))
+
+	if err := mgr.InsertManyParallel(context.Background(), newParallelPeople(2), WithWorkers(16)); err != nil {
+		t.Fatalf("InsertManyParallel failed: %v", err)
+	}
+	if len(conn.txs) != 2 {
+		t.Errorf("expected 2 transactions, got %d", len(conn.txs))
+	}
+}
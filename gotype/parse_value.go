@@ -0,0 +1,55 @@
+package gotype
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseAttributeValue converts text, such as a CSV cell, into a Go value of
+// the given TypeDB value type, ready for FormatValue. Supported value types
+// are string, integer (or long), double, boolean, date, datetime and
+// datetime-tz. Datetimes accept RFC 3339, "2006-01-02T15:04:05",
+// "2006-01-02 15:04:05" and "2006-01-02"; datetimes without a zone are read
+// as UTC.
+func ParseAttributeValue(valueType, text string) (any, error) {
+	switch valueType {
+	case "string":
+		return text, nil
+	case "integer", "long":
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", text)
+		}
+		return n, nil
+	case "double":
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid double %q", text)
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", text)
+		}
+		return b, nil
+	case "date", "datetime", "datetime-tz":
+		layouts := []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999", "2006-01-02"}
+		if valueType == "date" {
+			layouts = layouts[3:]
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(text)); err == nil {
+				if valueType != "datetime-tz" {
+					t = t.UTC()
+				}
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid %s %q", valueType, text)
+	default:
+		return nil, fmt.Errorf("unsupported value type %q", valueType)
+	}
+}
@@ -0,0 +1,45 @@
+package gotype
+
+import "testing"
+
+func TestParseAttributeValue(t *testing.T) {
+	tests := []struct {
+		valueType, text, want string
+	}{
+		{"string", `say "hi"`, `"say \"hi\""`},
+		{"integer", " 42", "42"},
+		{"long", "-7", "-7"},
+		{"double", "2.5", "2.5"},
+		{"boolean", "true", "true"},
+		{"date", "2024-03-01", "2024-03-01"},
+		{"datetime", "2024-03-01T10:20:30", "2024-03-01T10:20:30"},
+		{"datetime", "2024-03-01 10:20:30", "2024-03-01T10:20:30"},
+		{"datetime", "2024-03-01T12:20:30+02:00", "2024-03-01T10:20:30"},
+		{"datetime-tz", "2024-03-01T12:20:30+02:00", "2024-03-01T12:20:30+02:00"},
+	}
+	for _, tt := range tests {
+		v, err := ParseAttributeValue(tt.valueType, tt.text)
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.valueType, tt.text, err)
+			continue
+		}
+		if got := FormatValue(v); got != tt.want {
+			t.Errorf("%s %q = %s, want %s", tt.valueType, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseAttributeValue_Errors(t *testing.T) {
+	for _, tt := range []struct{ valueType, text string }{
+		{"integer", "4.5"},
+		{"double", "abc"},
+		{"boolean", "yes"},
+		{"date", "2024-03-01T10:00:00"},
+		{"datetime", "March 1st"},
+		{"decimal", "1.5"},
+	} {
+		if _, err := ParseAttributeValue(tt.valueType, tt.text); err == nil {
+			t.Errorf("%s %q: expected error", tt.valueType, tt.text)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultPathMaxDepth is the number of relations ShortestPath crosses at
+// most when PathOptions.MaxDepth is zero.
+const DefaultPathMaxDepth = 6
+
+// PathOptions configures ShortestPath.
+type PathOptions struct {
+	// Relations lists the relation types to follow, including their
+	// subtypes. Empty follows every relation.
+	Relations []string
+	// MaxDepth bounds the number of relations on the path. Zero means
+	// DefaultPathMaxDepth.
+	MaxDepth int
+}
+
+// PathNode is one instance on a path returned by ShortestPath.
+type PathNode struct {
+	// IID is the IID of the instance.
+	IID string `json:"iid"`
+	// Type is the exact type label of the instance.
+	Type string `json:"type"`
+}
+
+// edge is one relation linking two instances, as found by neighbours.
+type edge struct {
+	From, To         string
+	Relation         string
+	RelationType     string
+	FromRole, ToRole string
+	ToType           string
+}
+
+// ShortestPath returns a shortest path between the instances fromIID and
+// toIID, alternating instances and the relations linking them: from,
+// relation, instance, ..., relation, to. The search runs breadth-first from
+// both ends, one query per level, and gives up after opts.MaxDepth
+// relations; a nil path and nil error then mean no path was found. A missing
+// endpoint is a *NotFoundError.
+func ShortestPath(ctx context.Context, db *Database, fromIID, toIID string, opts PathOptions) ([]PathNode, error) {
+	if !IsIID(fromIID) || !IsIID(toIID) {
+		return nil, fmt.Errorf("shortest_path: invalid IID %q or %q", fromIID, toIID)
+	}
+	if err := checkCtx(ctx, "shortest_path", "path"); err != nil {
+		return nil, err
+	}
+	for _, rel := range opts.Relations {
+		if err := ValidateIdentifier(rel, "relation"); err != nil {
+			return nil, fmt.Errorf("shortest_path: %w", err)
+		}
+	}
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultPathMaxDepth
+	}
+
+	types, err := instanceTypes(ctx, db, fromIID, toIID)
+	if err != nil {
+		return nil, fmt.Errorf("shortest_path: %w", err)
+	}
+	for _, iid := range []string{fromIID, toIID} {
+		if types[iid] == "" {
+			return nil, fmt.Errorf("shortest_path: %w", &NotFoundError{TypeName: iid})
+		}
+	}
+	if fromIID == toIID {
+		return []PathNode{{IID: fromIID, Type: types[fromIID]}}, nil
+	}
+
+	// Each side records, per reached instance, the edge it was reached by.
+	type side struct {
+		via      map[string]*edge
+		frontier []string
+	}
+	fwd := &side{via: map[string]*edge{fromIID: nil}, frontier: []string{fromIID}}
+	bwd := &side{via: map[string]*edge{toIID: nil}, frontier: []string{toIID}}
+	depth := func(s *side, iid string) int {
+		n := 0
+		for e := s.via[iid]; e != nil; e = s.via[e.From] {
+			n++
+		}
+		return n
+	}
+
+	for hops := 0; hops < maxDepth && len(fwd.frontier) > 0 && len(bwd.frontier) > 0; hops++ {
+		cur, other := fwd, bwd
+		if len(bwd.frontier) < len(fwd.frontier) {
+			cur, other = bwd, fwd
+		}
+		edges, err := neighbours(ctx, db, cur.frontier, opts.Relations)
+		if err != nil {
+			return nil, fmt.Errorf("shortest_path: %w", err)
+		}
+		var next []string
+		var meet *edge
+		best := 0
+		for _, e := range edges {
+			types[e.To] = e.ToType
+			if _, ok := other.via[e.To]; ok {
+				if n := depth(other, e.To); meet == nil || n < best {
+					meet, best = e, n
+				}
+				continue
+			}
+			if _, seen := cur.via[e.To]; !seen {
+				cur.via[e.To] = e
+				next = append(next, e.To)
+			}
+		}
+		if meet != nil {
+			path := tracePath(cur.via, meet.From, types)
+			path = append(path, PathNode{IID: meet.Relation, Type: meet.RelationType})
+			rest := tracePath(other.via, meet.To, types)
+			slices.Reverse(rest)
+			path = append(path, rest...)
+			if path[0].IID != fromIID {
+				slices.Reverse(path)
+			}
+			return path, nil
+		}
+		cur.frontier = next
+	}
+	return nil, nil
+}
+
+// tracePath returns the path from the start of a search to iid, following
+// the edges recorded in via.
+func tracePath(via map[string]*edge, iid string, types map[string]string) []PathNode {
+	path := []PathNode{{IID: iid, Type: types[iid]}}
+	for e := via[iid]; e != nil; e = via[e.From] {
+		path = append(path,
+			PathNode{IID: e.Relation, Type: e.RelationType},
+			PathNode{IID: e.From, Type: types[e.From]})
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// instanceTypes returns the exact type label of each of iids that exists.
+func instanceTypes(ctx context.Context, db *Database, iids ...string) (map[string]string, error) {
+	query := fmt.Sprintf("match\n%s\n$e isa! $t;\nfetch {\n  \"_iid\": iid($e),\n  \"type\": label($t)\n};",
+		strings.Join(IIDIn(iids...).ToPatterns("e"), "\n"))
+	results, err := db.ExecuteRead(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]string, len(results))
+	for _, row := range results {
+		types[extractIID(row)], _ = unwrapValue(row["type"]).(string)
+	}
+	return types, nil
+}
+
+// neighbours returns the edges from the instances iids to every other
+// player of a relation they play in, restricted to the relation types
+// relations when it is not empty. Edges are sorted for a stable search.
+func neighbours(ctx context.Context, db *Database, iids []string, relations []string) ([]*edge, error) {
+	var b strings.Builder
+	b.WriteString("match\n")
+	b.WriteString(strings.Join(IIDIn(iids...).ToPatterns("a"), "\n"))
+	b.WriteString("\n$r isa! $rt, links ($ra: $a), links ($rb: $b);\n$b isa! $bt;\n")
+	if len(relations) > 0 {
+		branches := make([]string, len(relations))
+		for i, rel := range relations {
+			branches[i] = fmt.Sprintf("{ $r isa %s; }", rel)
+		}
+		b.WriteString(strings.Join(branches, " or "))
+		b.WriteString(";\n")
+	}
+	b.WriteString("fetch {\n  \"from\": iid($a),\n  \"relation\": iid($r),\n  \"relation_type\": label($rt),\n" +
+		"  \"from_role\": label($ra),\n  \"to\": iid($b),\n  \"to_role\": label($rb),\n  \"to_type\": label($bt)\n};")
+	results, err := db.ExecuteRead(ctx, b.String())
+	if err != nil {
+		return nil, err
+	}
+	str := func(row map[string]any, key string) string {
+		s, _ := unwrapValue(row[key]).(string)
+		return s
+	}
+	edges := make([]*edge, 0, len(results))
+	for _, row := range results {
+		e := &edge{
+			From:         str(row, "from"),
+			To:           str(row, "to"),
+			Relation:     str(row, "relation"),
+			RelationType: str(row, "relation_type"),
+			FromRole:     str(row, "from_role"),
+			ToRole:       str(row, "to_role"),
+			ToType:       str(row, "to_type"),
+		}
+		if e.To != e.From {
+			edges = append(edges, e)
+		}
+	}
+	slices.SortFunc(edges, func(x, y *edge) int {
+		return strings.Compare(x.From+x.Relation+x.To, y.From+y.Relation+y.To)
+	})
+	return edges, nil
+}
@@ -0,0 +1,49 @@
+package gotype
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestShortestPath(t *testing.T) {
+	ctx := context.Background()
+	hop := func(from, rel, to string) map[string]any {
+		return map[string]any{"from": from, "relation": rel, "relation_type": "knows", "to": to, "to_type": "person"}
+	}
+	types := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1", "type": "person"}, {"_iid": "0x3", "type": "person"}}}}
+	level1 := &mockTx{responses: [][]map[string]any{{hop("0x1", "0xa", "0x2")}}}
+	level2 := &mockTx{responses: [][]map[string]any{{hop("0x2", "0xa", "0x1"), hop("0x2", "0xb", "0x3")}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{types, level1, level2}}, "test_db")
+
+	path, err := ShortestPath(ctx, db, "0x1", "0x3", PathOptions{Relations: []string{"knows", "likes"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PathNode{{"0x1", "person"}, {"0xa", "knows"}, {"0x2", "person"}, {"0xb", "knows"}, {"0x3", "person"}}
+	if !reflect.DeepEqual(path, want) {
+		t.Errorf("path = %v", path)
+	}
+	assertContains(t, level1.queries[0], "$a iid 0x1;\n$r isa! $rt, links ($ra: $a), links ($rb: $b);")
+	assertContains(t, level1.queries[0], "{ $r isa knows; } or { $r isa likes; };")
+	assertContains(t, level2.queries[0], "$a iid 0x2;")
+
+	// No path within MaxDepth.
+	types = &mockTx{responses: [][]map[string]any{{{"_iid": "0x1", "type": "person"}, {"_iid": "0x3", "type": "person"}}}}
+	level1 = &mockTx{responses: [][]map[string]any{{hop("0x1", "0xa", "0x2")}}}
+	db = NewDatabase(&mockConn{txs: []*mockTx{types, level1}}, "test_db")
+	if path, err := ShortestPath(ctx, db, "0x1", "0x3", PathOptions{MaxDepth: 1}); err != nil || path != nil {
+		t.Errorf("path = %v, err = %v", path, err)
+	}
+
+	types = &mockTx{responses: [][]map[string]any{{{"_iid": "0x1", "type": "person"}}}}
+	db = NewDatabase(&mockConn{txs: []*mockTx{types}}, "test_db")
+	var nf *NotFoundError
+	if _, err := ShortestPath(ctx, db, "0x1", "0x3", PathOptions{}); !errors.As(err, &nf) {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := ShortestPath(ctx, db, "0x1", "0x3", PathOptions{Relations: []string{"match"}}); err == nil {
+		t.Error("expected error for reserved relation name")
+	}
+}
@@ -380,7 +380,7 @@ func (p *ConnPool) cleanIdleConnections() {
 // NewDatabaseWithPool creates a Database that uses a connection pool for concurrent access.
 // The pool is created with the given configuration and pre-warmed with MinSize connections.
 // The Database takes ownership of the pool and will close it when Database.Close() is called.
-func NewDatabaseWithPool(config PoolConfig, dbName string, factory func() (Conn, error)) (*Database, error) {
+func NewDatabaseWithPool(config PoolConfig, dbName string, factory func() (Conn, error), opts ...DatabaseOption) (*Database, error) {
 	pool, err := NewConnPool(config, factory)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -388,11 +388,16 @@ func NewDatabaseWithPool(config PoolConfig, dbName string, factory func() (Conn,
 
 	poolConn := &poolConnAdapter{pool: pool, dbName: dbName}
 
-	return &Database{
+	db := &Database{
 		conn:    poolConn,
 		dbName:  dbName,
 		ownConn: true,
-	}, nil
+		stats:   &dbStats{},
+	}
+	for _, o := range opts {
+		o(db)
+	}
+	return db, nil
 }
 
 // poolConnAdapter adapts a ConnPool to the Conn interface.
@@ -505,6 +510,15 @@ func (pt *pooledTx) QueryWithContext(ctx context.Context, query string) ([]map[s
 	return pt.tx.QueryWithContext(ctx, query)
 }
 
+func (pt *pooledTx) QueryResult(ctx context.Context, query string) (Result, error) {
+	return queryResult(ctx, pt.tx, query)
+}
+
+// Unwrap returns the transaction opened by the pooled connection.
+func (pt *pooledTx) Unwrap() Tx {
+	return pt.tx
+}
+
 func (pt *pooledTx) Commit() error {
 	err := pt.tx.Commit()
 	pt.once.Do(func() { pt.pool.Put(pt.conn) })
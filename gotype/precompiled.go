@@ -0,0 +1,78 @@
+package gotype
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/ast"
+)
+
+// PrecompiledQueries holds TypeQL text produced ahead of time for a model's
+// most common queries, so the Manager does not build them at runtime. tqlgen
+// emits them with RenderConfig.Queries; they must stay in sync with the
+// model's fields, so regenerate them whenever the struct changes.
+//
+// All queries bind the instance to $e. Empty fields are built at runtime.
+type PrecompiledQueries struct {
+	// TypeName is the TypeDB type the queries were generated for. The
+	// queries are ignored when the model registers under a different name.
+	TypeName string
+	// FetchAll is the fetch clause for "_iid" and every owned attribute.
+	FetchAll string
+	// Count is the complete query counting all instances.
+	Count string
+	// MatchByKey is the match clause selecting one entity by its key
+	// attributes, with one %s verb per key field in declaration order.
+	MatchByKey string
+}
+
+// QueryPrecompiled can be implemented by a model to supply PrecompiledQueries
+// the Manager prefers over building queries itself.
+type QueryPrecompiled interface {
+	PrecompiledQueries() PrecompiledQueries
+}
+
+// precompiledQueriesFor returns the queries supplied by t's QueryPrecompiled
+// implementation, if they were generated for typeName.
+func precompiledQueriesFor(t reflect.Type, typeName string) (PrecompiledQueries, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return PrecompiledQueries{}, false
+	}
+	pq, ok := reflect.New(t).Interface().(QueryPrecompiled)
+	if !ok {
+		return PrecompiledQueries{}, false
+	}
+	queries := pq.PrecompiledQueries()
+	if queries.TypeName != typeName {
+		return PrecompiledQueries{}, false
+	}
+	return queries, true
+}
+
+// precompiledMatchByKey fills the MatchByKey template of info with the key
+// values of v. It reports false when there is no template, or when a key is
+// unset and the query must be built at runtime.
+func precompiledMatchByKey(info *ModelInfo, v reflect.Value) (string, bool, error) {
+	tmpl := info.templates.matchByKey
+	if tmpl == "" || strings.Count(tmpl, "%s") != len(info.KeyFields) {
+		return "", false, nil
+	}
+	args := make([]any, len(info.KeyFields))
+	for i, fi := range info.KeyFields {
+		val, err := extractSingleFieldValue(v, fi)
+		if err != nil {
+			return "", false, err
+		}
+		if val == nil {
+			return "", false, nil
+		}
+		// Format exactly as the compiled has-constraint would.
+		lit := ast.ValueFromGo(val).(ast.LiteralValue)
+		args[i] = ast.FormatLiteralWithOptions(lit.Val, lit.ValueType, defaultCompiler.Literals)
+	}
+	return fmt.Sprintf(tmpl, args...), true, nil
+}
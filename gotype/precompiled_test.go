@@ -0,0 +1,87 @@
+package gotype
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPrecompiled struct {
+	BaseEntity
+	Email string    `typedb:"email,key"`
+	Born  time.Time `typedb:"born,key"`
+	Age   *int      `typedb:"age"`
+}
+
+// Matches what tqlgen emits for the model.
+func (testPrecompiled) PrecompiledQueries() PrecompiledQueries {
+	return PrecompiledQueries{
+		TypeName:   "test-precompiled",
+		FetchAll:   "fetch {\n  \"_iid\": iid($e),\n  \"email\": $e.email,\n  \"born\": $e.born,\n  \"age\": $e.age\n};",
+		Count:      "match\n$e isa test-precompiled;\nreduce $count = count($e);",
+		MatchByKey: "match\n$e isa test-precompiled, has email %s, has born %s;",
+	}
+}
+
+type testPrecompiledOnlyFetch struct {
+	BaseEntity
+	Name string `typedb:"name,key"`
+}
+
+func (testPrecompiledOnlyFetch) PrecompiledQueries() PrecompiledQueries {
+	return PrecompiledQueries{TypeName: "test-precompiled-only-fetch", FetchAll: `fetch { "_iid": iid($e) };`}
+}
+
+func TestPrecompiled_MatchesRuntimeQueries(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testPrecompiled]()
+	info, _ := Lookup("test-precompiled")
+	pq := testPrecompiled{}.PrecompiledQueries()
+
+	if info.templates.fetchAll != pq.FetchAll || info.templates.count != pq.Count {
+		t.Fatalf("precompiled queries not installed: %+v", info.templates)
+	}
+	fetch, err := compileFetchAll(info, templateVar)
+	if err != nil || fetch != pq.FetchAll {
+		t.Errorf("runtime fetch differs:\n%s\nwant\n%s", fetch, pq.FetchAll)
+	}
+
+	instance := &testPrecompiled{Email: `a"b@example.com`, Born: time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)}
+	got, err := strategyFor(info.Kind).BuildMatchByKey(info, instance, "e")
+	if err != nil {
+		t.Fatalf("BuildMatchByKey: %v", err)
+	}
+	saved := info.templates.matchByKey
+	info.templates.matchByKey = ""
+	want, _ := strategyFor(info.Kind).BuildMatchByKey(info, instance, "e")
+	info.templates.matchByKey = saved
+	if got != want {
+		t.Errorf("precompiled match by key = %q, runtime = %q", got, want)
+	}
+}
+
+func TestPrecompiled_ManagerPrefersTemplates(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testPrecompiledOnlyFetch]()
+	readTx := &mockTx{}
+	mgr := MustNewManager[testPrecompiledOnlyFetch](NewDatabase(&mockConn{txs: []*mockTx{readTx}}, "test_db"))
+
+	if _, err := mgr.All(context.Background()); err != nil {
+		t.Fatalf("All: %v", err)
+	}
+	if len(readTx.queries) != 1 || !strings.HasSuffix(readTx.queries[0], `fetch { "_iid": iid($e) };`) {
+		t.Errorf("expected the precompiled fetch, got %q", readTx.queries)
+	}
+}
+
+func TestPrecompiled_IgnoredForOtherTypeName(t *testing.T) {
+	ClearRegistry()
+	if err := Register[testPrecompiledOnlyFetch](WithTypeName("renamed")); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	info, _ := Lookup("renamed")
+	if strings.Contains(info.templates.fetchAll, `fetch { "_iid": iid($e) };`) {
+		t.Error("precompiled queries for another type name must be ignored")
+	}
+}
@@ -4,6 +4,9 @@ package gotype
 import (
 	"context"
 	"fmt"
+	"iter"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -16,6 +19,8 @@ type Query[T any] struct {
 	orderBy []OrderClause
 	limit   int
 	offset  int
+	maxRows int      // 0 inherits Database.MaxRows, negative disables the guard
+	preload []string // non-nil when Preload was called
 }
 
 // OrderClause specifies an attribute name and sort direction for query results.
@@ -31,6 +36,23 @@ func (q *Query[T]) Filter(filters ...Filter) *Query[T] {
 	return q
 }
 
+// WithPlayer restricts a relation query to the relations in which player,
+// an IID string or a stored model instance, plays role:
+//
+//	emps.Query().WithPlayer("employee", alice).Execute(ctx)
+//
+// It is shorthand for Filter(Player(role, player)).
+func (q *Query[T]) WithPlayer(role string, player any) *Query[T] {
+	return q.Filter(Player(role, player))
+}
+
+// WithReadTransaction makes the query's reads run in tc, as
+// Manager.WithReadTransaction does. Update and Delete are not affected.
+func (q *Query[T]) WithReadTransaction(tc *TransactionContext) *Query[T] {
+	q.mgr = q.mgr.WithReadTransaction(tc)
+	return q
+}
+
 // OrderAsc adds an ascending sort order on the specified attribute.
 func (q *Query[T]) OrderAsc(attr string) *Query[T] {
 	q.orderBy = append(q.orderBy, OrderClause{Attr: attr, Desc: false})
@@ -55,6 +77,33 @@ func (q *Query[T]) Offset(n int) *Query[T] {
 	return q
 }
 
+// MaxRows overrides the Database's WithMaxRows limit for this query: reads
+// returning more than n rows fail with ErrTooManyRows instead of being
+// hydrated. n <= 0 removes the limit for this query.
+func (q *Query[T]) MaxRows(n int) *Query[T] {
+	q.maxRows = n
+	if n <= 0 {
+		q.maxRows = -1
+	}
+	return q
+}
+
+// Preload makes Execute, All and First populate the named typed role-player
+// fields of the results, or all of them when none are named. See
+// Manager.Preload.
+func (q *Query[T]) Preload(fields ...string) *Query[T] {
+	q.preload = append([]string{}, fields...)
+	return q
+}
+
+// rowLimit returns the effective row limit, or 0 if the query is unbounded.
+func (q *Query[T]) rowLimit() int {
+	if q.maxRows != 0 {
+		return max(q.maxRows, 0)
+	}
+	return q.mgr.db.maxRows
+}
+
 // Exists returns true if the query matches at least one instance in the database.
 func (q *Query[T]) Exists(ctx context.Context) (bool, error) {
 	count, err := q.Count(ctx)
@@ -75,11 +124,82 @@ func (q *Query[T]) Execute(ctx context.Context) ([]*T, error) {
 	if err != nil {
 		return nil, fmt.Errorf("query %s: build: %w", q.mgr.info.TypeName, err)
 	}
-	results, err := q.mgr.db.ExecuteRead(ctx, query)
+	results, err := q.mgr.cachedRead(ctx, query, q.mgr.readQuery)
 	if err != nil {
 		return nil, fmt.Errorf("query %s: %w", q.mgr.info.TypeName, err)
 	}
-	return q.mgr.hydrateResults(results)
+	instances, err := q.mgr.hydrateResultsLimit(results, q.rowLimit())
+	if err != nil || q.preload == nil {
+		return instances, err
+	}
+	if err := q.mgr.Preload(ctx, instances, q.preload...); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// ExecutePolymorphic performs the query over T and all of its subtypes and
+// hydrates each row into its registered concrete type, selected by the "_type"
+// label. Elements are pointers to the concrete structs (e.g. *Dog for a query
+// on Animal). Rows whose type is not registered are hydrated as *T.
+func (q *Query[T]) ExecutePolymorphic(ctx context.Context) ([]any, error) {
+	query, err := q.buildPolymorphicQuery()
+	if err != nil {
+		return nil, fmt.Errorf("query_polymorphic %s: build: %w", q.mgr.info.TypeName, err)
+	}
+	results, err := q.mgr.readQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query_polymorphic %s: %w", q.mgr.info.TypeName, err)
+	}
+	return q.mgr.hydrateAnyResults(results, q.rowLimit())
+}
+
+// Iter executes the query and yields the results one at a time, hydrating each
+// row only when the caller asks for it. Nothing beyond the current row is
+// retained, so breaking out of the loop early or processing rows as they come
+// keeps peak memory well below Execute. When the read transaction implements
+// StreamingTx, rows are also received incrementally from the driver.
+//
+// The read transaction stays open until iteration finishes. Iteration stops
+// after the first error, which is yielded with a nil result.
+func (q *Query[T]) Iter(ctx context.Context) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		query, err := q.buildQuery()
+		if err != nil {
+			yield(nil, fmt.Errorf("query %s: build: %w", q.mgr.info.TypeName, err))
+			return
+		}
+
+		tx := q.mgr.readTransaction()
+		if tx == nil {
+			if tx, err = q.mgr.db.TransactionContext(ctx, ReadTransaction); err != nil {
+				yield(nil, fmt.Errorf("query %s: %w", q.mgr.info.TypeName, err))
+				return
+			}
+			defer tx.Close()
+		}
+
+		limit, rows := q.rowLimit(), 0
+		for row, err := range queryRows(ctx, tx, query) {
+			if err != nil {
+				yield(nil, fmt.Errorf("query %s: %w", q.mgr.info.TypeName, err))
+				return
+			}
+			if rows++; limit > 0 && rows > limit {
+				yield(nil, fmt.Errorf("query %s: %w: more than %d rows", q.mgr.info.TypeName, ErrTooManyRows, limit))
+				return
+			}
+			instance, err := hydrateNewWithInfo[T](q.mgr.info, row)
+			if err != nil {
+				q.mgr.db.stats.recordHydrationError()
+				yield(nil, fmt.Errorf("hydrate %s: %w", q.mgr.info.TypeName, err))
+				return
+			}
+			if !yield(instance, nil) {
+				return
+			}
+		}
+	}
 }
 
 // First executes the query with a limit of 1 and returns the first result, or nil if none found.
@@ -101,7 +221,7 @@ func (q *Query[T]) Count(ctx context.Context) (int64, error) {
 	if err != nil {
 		return 0, fmt.Errorf("count %s: build: %w", q.mgr.info.TypeName, err)
 	}
-	results, err := q.mgr.db.ExecuteRead(ctx, query)
+	results, err := q.mgr.cachedRead(ctx, query, q.mgr.readQuery)
 	if err != nil {
 		return 0, fmt.Errorf("count %s: %w", q.mgr.info.TypeName, err)
 	}
@@ -111,7 +231,46 @@ func (q *Query[T]) Count(ctx context.Context) (int64, error) {
 	return extractCount(results[0]), nil
 }
 
-// Delete removes all instances that match the query filters.
+// Page returns page number page (counted from 1) of size matches, in the
+// query's sort order, together with the total number of matches. The fetch
+// and the count run in one read transaction, the manager's when it has one,
+// so total always agrees with the items. The query's own limit and offset
+// are ignored and q is left unchanged.
+func (q *Query[T]) Page(ctx context.Context, page, size int) (items []*T, total int64, err error) {
+	if page < 1 || size < 1 {
+		return nil, 0, fmt.Errorf("page %s: page and size must be positive, got %d and %d", q.mgr.info.TypeName, page, size)
+	}
+	pq := *q
+	pq.limit, pq.offset = size, (page-1)*size
+	if q.mgr.readTransaction() == nil {
+		tx, err := q.mgr.db.TransactionContext(ctx, ReadTransaction)
+		if err != nil {
+			return nil, 0, fmt.Errorf("page %s: %w", q.mgr.info.TypeName, err)
+		}
+		defer tx.Close()
+		mgr := *q.mgr
+		mgr.readTx = tx
+		pq.mgr = &mgr
+	}
+
+	if total, err = pq.Count(ctx); err != nil {
+		return nil, 0, err
+	}
+	if total <= int64(pq.offset) {
+		return []*T{}, total, nil
+	}
+	if items, err = pq.Execute(ctx); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Delete removes all instances that match the query filters and returns how
+// many were deleted. When the transaction is a ResultTx the number comes
+// from the WriteSummary of the delete, and a missing summary is an error;
+// otherwise the matches are counted in the same write transaction, just
+// before the delete. Either way it is exact rather than an estimate.
+// A transaction bound to the manager is used as in UpdateWith.
 func (q *Query[T]) Delete(ctx context.Context) (int64, error) {
 	countQuery, err := q.buildCountQuery()
 	if err != nil {
@@ -121,28 +280,42 @@ func (q *Query[T]) Delete(ctx context.Context) (int64, error) {
 	if err != nil {
 		return 0, fmt.Errorf("delete %s: build delete: %w", q.mgr.info.TypeName, err)
 	}
+	defer q.mgr.invalidateCacheType(ctx)
 
-	tx, err := q.mgr.db.Transaction(WriteTransaction)
+	tx, autoCommit, err := q.mgr.writeTx()
 	if err != nil {
 		return 0, fmt.Errorf("delete %s: %w", q.mgr.info.TypeName, err)
 	}
-	defer tx.Close()
-
-	countResults, err := tx.QueryWithContext(ctx, countQuery)
-	if err != nil {
-		return 0, fmt.Errorf("delete %s: count: %w", q.mgr.info.TypeName, err)
+	if autoCommit {
+		defer tx.Close()
 	}
+
+	summarized := reportsSummary(tx)
 	var count int64
-	if len(countResults) > 0 {
-		count = extractCount(countResults[0])
+	if !summarized {
+		countResults, err := tx.QueryWithContext(ctx, countQuery)
+		if err != nil {
+			return 0, fmt.Errorf("delete %s: count: %w", q.mgr.info.TypeName, err)
+		}
+		if len(countResults) > 0 {
+			count = extractCount(countResults[0])
+		}
 	}
 
-	_, err = tx.QueryWithContext(ctx, deleteQuery)
+	res, err := queryResult(ctx, tx, deleteQuery)
 	if err != nil {
 		return 0, fmt.Errorf("delete %s: %w", q.mgr.info.TypeName, err)
 	}
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("delete %s: commit: %w", q.mgr.info.TypeName, err)
+	if summarized {
+		if res.Summary == nil {
+			return 0, fmt.Errorf("delete %s: transaction reported no write summary", q.mgr.info.TypeName)
+		}
+		count = res.Summary.Deleted
+	}
+	if autoCommit {
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("delete %s: commit: %w", q.mgr.info.TypeName, err)
+		}
 	}
 	return count, nil
 }
@@ -150,19 +323,29 @@ func (q *Query[T]) Delete(ctx context.Context) (int64, error) {
 // --- Query building ---
 
 func (q *Query[T]) buildMatchClause() (string, error) {
+	return q.buildMatchClauseFor("$e isa " + q.mgr.info.TypeName + ";")
+}
+
+// buildPolymorphicMatchClause matches instances of T and all its subtypes,
+// binding the concrete type to $t for the "_type" fetch.
+func (q *Query[T]) buildPolymorphicMatchClause() (string, error) {
+	return q.buildMatchClauseFor("$e isa! $t;\n$t sub " + q.mgr.info.TypeName + ";")
+}
+
+func (q *Query[T]) buildMatchClauseFor(isa string) (string, error) {
 	varName := "e"
-	var b strings.Builder
-	b.WriteString("match\n$")
-	b.WriteString(varName)
-	b.WriteString(" isa ")
-	b.WriteString(q.mgr.info.TypeName)
-	b.WriteString(";")
-
-	for _, f := range q.filters {
-		for _, pattern := range f.ToPatterns(varName) {
-			b.WriteByte('\n')
-			b.WriteString(pattern)
-		}
+	b := getQueryBuf()
+	defer putQueryBuf(b)
+	b.WriteString("match\n")
+	b.WriteString(isa)
+
+	patterns, err := filterPatterns(q.filters, varName)
+	if err != nil {
+		return "", err
+	}
+	for _, pattern := range patterns {
+		b.WriteByte('\n')
+		b.WriteString(pattern)
 	}
 
 	return b.String(), nil
@@ -173,12 +356,30 @@ func (q *Query[T]) buildQuery() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	fetch, err := q.mgr.strategy.BuildFetchAll(q.mgr.info, "e")
+	fetch, err := q.mgr.strategy.BuildFetchAll(q.mgr.fetchInfo(), "e")
 	if err != nil {
 		return "", err
 	}
+	return q.buildSelectQuery(match, fetch), nil
+}
 
-	var b strings.Builder
+func (q *Query[T]) buildPolymorphicQuery() (string, error) {
+	match, err := q.buildPolymorphicMatchClause()
+	if err != nil {
+		return "", err
+	}
+	fetch, err := buildPolymorphicFetch(q.mgr.info, "e")
+	if err != nil {
+		return "", err
+	}
+	return q.buildSelectQuery(match, fetch), nil
+}
+
+// buildSelectQuery appends the sort and pagination clauses and the fetch to match.
+func (q *Query[T]) buildSelectQuery(match, fetch string) string {
+	b := getQueryBuf()
+	defer putQueryBuf(b)
+	b.Grow(len(match) + len(fetch) + 32*len(q.orderBy) + 32)
 	b.WriteString(match)
 
 	// Sort
@@ -223,18 +424,18 @@ func (q *Query[T]) buildQuery() (string, error) {
 
 	b.WriteByte('\n')
 	b.WriteString(fetch)
-	return b.String(), nil
+	return b.String()
 }
 
 func (q *Query[T]) buildCountQuery() (string, error) {
+	if tmpl := q.mgr.info.templates; len(q.filters) == 0 && tmpl != nil && tmpl.count != "" {
+		return tmpl.count, nil
+	}
 	match, err := q.buildMatchClause()
 	if err != nil {
 		return "", err
 	}
-	var b strings.Builder
-	b.WriteString(match)
-	b.WriteString("\nreduce $count = count($e);")
-	return b.String(), nil
+	return match + "\nreduce $count = count($e);", nil
 }
 
 func (q *Query[T]) buildDeleteQuery() (string, error) {
@@ -242,21 +443,24 @@ func (q *Query[T]) buildDeleteQuery() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	var b strings.Builder
-	b.WriteString(match)
-	b.WriteString("\ndelete $e;")
-	return b.String(), nil
+	return match + "\ndelete $e;", nil
 }
 
 // UpdateWith fetches all matching instances, applies fn to each, then updates them all.
 // The fetch and update are performed within a single write transaction for atomicity.
+// On a manager bound to a transaction (NewManagerWithTx) that transaction is
+// used and left for the caller to commit.
 func (q *Query[T]) UpdateWith(ctx context.Context, fn func(*T)) ([]*T, error) {
+	defer q.mgr.invalidateCacheType(ctx)
+
 	// Use a single write transaction for both fetch and update to prevent race conditions.
-	tx, err := q.mgr.db.Transaction(WriteTransaction)
+	tx, autoCommit, err := q.mgr.writeTx()
 	if err != nil {
 		return nil, fmt.Errorf("update_with %s: %w", q.mgr.info.TypeName, err)
 	}
-	defer tx.Close()
+	if autoCommit {
+		defer tx.Close()
+	}
 
 	// Phase 1: fetch matching instances within the write transaction
 	query, err := q.buildQuery()
@@ -267,7 +471,7 @@ func (q *Query[T]) UpdateWith(ctx context.Context, fn func(*T)) ([]*T, error) {
 	if err != nil {
 		return nil, fmt.Errorf("update_with %s: fetch: %w", q.mgr.info.TypeName, err)
 	}
-	results, err := q.mgr.hydrateResults(rawResults)
+	results, err := q.mgr.hydrateResultsLimit(rawResults, q.rowLimit())
 	if err != nil {
 		return nil, fmt.Errorf("update_with %s: hydrate: %w", q.mgr.info.TypeName, err)
 	}
@@ -287,31 +491,73 @@ func (q *Query[T]) UpdateWith(ctx context.Context, fn func(*T)) ([]*T, error) {
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("update_with %s: commit: %w", q.mgr.info.TypeName, err)
+	if autoCommit {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("update_with %s: commit: %w", q.mgr.info.TypeName, err)
+		}
 	}
 	return results, nil
 }
 
-// Update performs a bulk attribute update on all matching instances.
-// Keys in the updates map are TypeDB attribute names; values are the new values.
-// Returns the number of instances updated.
+// Update performs a bulk attribute update on all matching instances, entities
+// or relations; role players are left as they are. Keys in the updates map are
+// TypeDB attribute names of the model; values are the new values, a slice for
+// a multi-valued attribute, or nil to delete the attribute.
+// Returns the number of instances updated, counted in the same write
+// transaction as the update, as Delete does. A transaction bound to the
+// manager is used as in UpdateWith.
 func (q *Query[T]) Update(ctx context.Context, updates map[string]any) (int64, error) {
 	if len(updates) == 0 {
 		return 0, nil
 	}
 
+	for attr := range updates {
+		if _, ok := q.mgr.info.FieldByAttrName(attr); !ok && q.mgr.info.extraField == nil {
+			return 0, fmt.Errorf("bulk_update %s: %q is not an attribute of the model", q.mgr.info.TypeName, attr)
+		}
+	}
+
 	// Build match clause from filters
 	match, err := q.buildMatchClause()
 	if err != nil {
 		return 0, fmt.Errorf("bulk_update %s: build: %w", q.mgr.info.TypeName, err)
 	}
 
-	tx, err := q.mgr.db.Transaction(WriteTransaction)
+	// Build a single match-delete-insert query for all attributes. A nil
+	// value only deletes; a slice replaces all values of a multi-valued
+	// attribute.
+	var tryMatches []string
+	var tryDeletes []string
+	var insHas []string
+	for i, attr := range slices.Sorted(maps.Keys(updates)) {
+		tryMatches = append(tryMatches, fmt.Sprintf("try { $e has %s $old%d; };", attr, i))
+		tryDeletes = append(tryDeletes, fmt.Sprintf("try { $old%d of $e; };", i))
+		if updates[attr] == nil {
+			continue
+		}
+		for _, val := range attrValues(updates[attr]) {
+			has, err := hasClause(attr, val)
+			if err != nil {
+				return 0, fmt.Errorf("bulk_update %s: %w", q.mgr.info.TypeName, err)
+			}
+			insHas = append(insHas, has)
+		}
+	}
+
+	query := match + "\n" + strings.Join(tryMatches, "\n") +
+		"\ndelete\n" + strings.Join(tryDeletes, "\n")
+	if len(insHas) > 0 {
+		query += fmt.Sprintf("\ninsert $e %s;", strings.Join(insHas, ", "))
+	}
+	defer q.mgr.invalidateCacheType(ctx)
+
+	tx, autoCommit, err := q.mgr.writeTx()
 	if err != nil {
 		return 0, fmt.Errorf("bulk_update %s: %w", q.mgr.info.TypeName, err)
 	}
-	defer tx.Close()
+	if autoCommit {
+		defer tx.Close()
+	}
 
 	countQuery, err := q.buildCountQuery()
 	if err != nil {
@@ -326,28 +572,15 @@ func (q *Query[T]) Update(ctx context.Context, updates map[string]any) (int64, e
 		count = extractCount(countResults[0])
 	}
 
-	// Build a single match-delete-insert query for all attributes
-	var tryMatches []string
-	var tryDeletes []string
-	var insHas []string
-	i := 0
-	for attr, val := range updates {
-		tryMatches = append(tryMatches, fmt.Sprintf("try { $e has %s $old%d; };", attr, i))
-		tryDeletes = append(tryDeletes, fmt.Sprintf("try { $old%d of $e; };", i))
-		insHas = append(insHas, fmt.Sprintf("has %s %s", attr, FormatValue(val)))
-		i++
-	}
-
-	query := match + "\n" + strings.Join(tryMatches, "\n") +
-		"\ndelete\n" + strings.Join(tryDeletes, "\n") +
-		fmt.Sprintf("\ninsert $e %s;", strings.Join(insHas, ", "))
 	_, err = tx.QueryWithContext(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("bulk_update %s: %w", q.mgr.info.TypeName, err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("bulk_update %s: commit: %w", q.mgr.info.TypeName, err)
+	if autoCommit {
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("bulk_update %s: commit: %w", q.mgr.info.TypeName, err)
+		}
 	}
 	return count, nil
 }
@@ -402,9 +635,11 @@ func (aq *AggregateQuery[T]) Execute(ctx context.Context) (float64, error) {
 	varName := "e"
 	var patterns []string
 	patterns = append(patterns, fmt.Sprintf("$%s isa %s;", varName, aq.mgr.info.TypeName))
-	for _, f := range aq.filters {
-		patterns = append(patterns, f.ToPatterns(varName)...)
+	filterPats, err := filterPatterns(aq.filters, varName)
+	if err != nil {
+		return 0, fmt.Errorf("%s %s.%s: %w", aq.fn, aq.mgr.info.TypeName, aq.attr, err)
 	}
+	patterns = append(patterns, filterPats...)
 
 	attrVar := sanitizeVar(varName + "__" + aq.attr)
 	patterns = append(patterns, fmt.Sprintf("$%s has %s $%s;", varName, aq.attr, attrVar))
@@ -412,7 +647,7 @@ func (aq *AggregateQuery[T]) Execute(ctx context.Context) (float64, error) {
 	match := "match\n" + strings.Join(patterns, "\n")
 	query := match + fmt.Sprintf("\nreduce $result = %s($%s);", aq.fn, attrVar)
 
-	results, err := aq.mgr.db.ExecuteRead(ctx, query)
+	results, err := aq.mgr.readQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("%s %s.%s: %w", aq.fn, aq.mgr.info.TypeName, aq.attr, err)
 	}
@@ -442,9 +677,11 @@ func (q *Query[T]) Aggregate(ctx context.Context, specs ...AggregateSpec) (map[s
 	varName := "e"
 	var patterns []string
 	patterns = append(patterns, fmt.Sprintf("$%s isa %s;", varName, q.mgr.info.TypeName))
-	for _, f := range q.filters {
-		patterns = append(patterns, f.ToPatterns(varName)...)
+	filterPats, err := filterPatterns(q.filters, varName)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate %s: %w", q.mgr.info.TypeName, err)
 	}
+	patterns = append(patterns, filterPats...)
 
 	// Build reduce assignments - one per spec
 	var assignments []string
@@ -515,9 +752,11 @@ func (gq *GroupByQuery[T]) Aggregate(ctx context.Context, specs ...AggregateSpec
 	varName := "e"
 	var patterns []string
 	patterns = append(patterns, fmt.Sprintf("$%s isa %s;", varName, gq.mgr.info.TypeName))
-	for _, f := range gq.filters {
-		patterns = append(patterns, f.ToPatterns(varName)...)
+	filterPats, err := filterPatterns(gq.filters, varName)
+	if err != nil {
+		return nil, fmt.Errorf("groupby %s: %w", gq.mgr.info.TypeName, err)
 	}
+	patterns = append(patterns, filterPats...)
 
 	// Add has clause for the group-by attribute
 	groupVar := sanitizeVar(varName + "__" + gq.groupBy)
@@ -549,7 +788,7 @@ func (gq *GroupByQuery[T]) Aggregate(ctx context.Context, specs ...AggregateSpec
 
 	query := match + fmt.Sprintf("\nreduce %s, group $%s;", strings.Join(reduces, ", "), groupVar)
 
-	rawResults, err := gq.mgr.db.ExecuteRead(ctx, query)
+	rawResults, err := gq.mgr.readQuery(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("groupby %s: %w", gq.mgr.info.TypeName, err)
 	}
@@ -575,6 +814,23 @@ func (m *Manager[T]) Query() *Query[T] {
 	return &Query[T]{mgr: m}
 }
 
+// Info returns the metadata of the queried model type.
+func (q *Query[T]) Info() *ModelInfo {
+	return q.mgr.info
+}
+
+// Manager returns the manager the query runs through.
+func (q *Query[T]) Manager() *Manager[T] {
+	return q.mgr
+}
+
+// QueryPolymorphic returns the instances of T and its subtypes matching
+// filters, each hydrated as its registered concrete type. It is shorthand for
+// m.Query().Filter(filters...).ExecutePolymorphic(ctx).
+func (m *Manager[T]) QueryPolymorphic(ctx context.Context, filters ...Filter) ([]any, error) {
+	return m.Query().Filter(filters...).ExecutePolymorphic(ctx)
+}
+
 // --- Helpers ---
 
 func extractCount(result map[string]any) int64 {
@@ -2,6 +2,9 @@ package gotype
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"math"
 	"strings"
 	"testing"
@@ -78,6 +81,24 @@ func TestQuery_OrFilter(t *testing.T) {
 	assertContains(t, q, "or")
 }
 
+func TestQuery_WithPlayer(t *testing.T) {
+	registerTestTypes(t)
+
+	readTx := &mockTx{responses: [][]map[string]any{nil}}
+	conn := &mockConn{txs: []*mockTx{readTx}}
+	db := NewDatabase(conn, "test_db")
+	mgr := MustNewManager[testEmployment](db)
+
+	_, _ = mgr.Query().
+		WithPlayer("employee", "0xABC").
+		Execute(context.Background())
+
+	q := readTx.queries[0]
+	assertContains(t, q, "$e isa test-employment;")
+	assertContains(t, q, "$e links (employee: $e_employee_0xABC);")
+	assertContains(t, q, "$e_employee_0xABC iid 0xABC;")
+}
+
 func TestQuery_Limit(t *testing.T) {
 	registerTestTypes(t)
 
@@ -219,6 +240,50 @@ func TestQuery_Count(t *testing.T) {
 	assertContains(t, readTx.queries[0], "reduce $count = count($e);")
 }
 
+func TestQuery_Page(t *testing.T) {
+	registerTestTypes(t)
+
+	readTx := &mockTx{
+		responses: [][]map[string]any{
+			{{"count": float64(5)}},
+			{{"_iid": "0x3", "name": "Carol"}, {"_iid": "0x4", "name": "Dan"}},
+		},
+	}
+	// A second transaction would mean the count and the fetch were split.
+	conn := &mockConn{txs: []*mockTx{readTx}}
+	db := NewDatabase(conn, "test_db")
+	mgr := MustNewManager[testPerson](db)
+
+	q := mgr.Query().Filter(Gt("age", 20)).OrderAsc("name")
+	items, total, err := q.Page(context.Background(), 2, 2)
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+	if total != 5 || len(items) != 2 || items[0].Name != "Carol" {
+		t.Fatalf("items = %+v, total = %d", items, total)
+	}
+	if !readTx.closed || len(readTx.queries) != 2 {
+		t.Fatalf("queries = %q, closed = %v", readTx.queries, readTx.closed)
+	}
+	assertContains(t, readTx.queries[0], "reduce $count = count($e);")
+	assertContains(t, readTx.queries[1], "offset 2;\nlimit 2;")
+	if q.limit != 0 || q.offset != 0 {
+		t.Error("Page must not change the query")
+	}
+
+	// A page past the end skips the fetch.
+	readTx = &mockTx{responses: [][]map[string]any{{{"count": float64(5)}}}}
+	db = NewDatabase(&mockConn{txs: []*mockTx{readTx}}, "test_db")
+	items, total, err = MustNewManager[testPerson](db).Query().Page(context.Background(), 4, 2)
+	if err != nil || total != 5 || len(items) != 0 || len(readTx.queries) != 1 {
+		t.Errorf("items = %v, total = %d, err = %v, queries = %d", items, total, err, len(readTx.queries))
+	}
+
+	if _, _, err := mgr.Query().Page(context.Background(), 0, 10); err == nil {
+		t.Error("expected error for page 0")
+	}
+}
+
 func TestParseValueString(t *testing.T) {
 	tests := []struct {
 		name string
@@ -437,6 +502,111 @@ func TestQuery_UpdateWith_NoResults(t *testing.T) {
 	}
 }
 
+func TestQuery_UpdateWith_BoundTx(t *testing.T) {
+	registerTestTypes(t)
+
+	writeTx := &mockTx{
+		responses: [][]map[string]any{
+			{{"_iid": "0x001", "name": "Alice", "email": "old-a@example.com"}},
+			nil,                     // UpdateWith persist
+			{{"count": float64(1)}}, // Update count
+			nil,                     // Update
+			{{"count": float64(1)}}, // Delete count
+		},
+	}
+	conn := &mockConn{txs: []*mockTx{writeTx}}
+	db := NewDatabase(conn, "test_db")
+
+	tc, err := db.Begin(WriteTransaction)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tc.Close()
+	mgr := MustNewManagerWithTx[testPerson](tc)
+	ctx := context.Background()
+
+	if _, err := mgr.Query().UpdateWith(ctx, func(p *testPerson) { p.Email = "new-a@example.com" }); err != nil {
+		t.Fatalf("UpdateWith: %v", err)
+	}
+	if _, err := mgr.Query().Filter(Eq("name", "Alice")).Update(ctx, map[string]any{"age": 31}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := mgr.Query().Filter(Eq("name", "Bob")).Delete(ctx); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Every statement ran in the bound transaction, which is still open; the
+	// mock conn has no second transaction to hand out.
+	if len(writeTx.queries) != 6 {
+		t.Fatalf("expected 6 queries in the bound tx, got %d", len(writeTx.queries))
+	}
+	if writeTx.committed {
+		t.Fatal("bound transaction committed by a query helper")
+	}
+	if err := tc.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if !writeTx.committed {
+		t.Error("expected the bound transaction to commit")
+	}
+}
+
+func TestQuery_Writes_BoundTxLeftToCaller(t *testing.T) {
+	registerTestTypes(t)
+
+	writeTx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{writeTx}}, "test_db")
+	tc, err := db.Begin(WriteTransaction)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	mgr := MustNewManagerWithTx[testPerson](tc)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A failing write neither commits nor closes the bound transaction; the
+	// caller decides whether to roll back.
+	if _, err := mgr.Query().Delete(ctx); err == nil {
+		t.Fatal("Delete: expected context error")
+	}
+	if _, err := mgr.Query().Update(ctx, map[string]any{"age": 31}); err == nil {
+		t.Fatal("Update: expected context error")
+	}
+	if _, err := mgr.Query().UpdateWith(ctx, func(*testPerson) {}); err == nil {
+		t.Fatal("UpdateWith: expected context error")
+	}
+	if writeTx.committed || writeTx.closed {
+		t.Errorf("bound transaction committed=%v closed=%v after failed writes", writeTx.committed, writeTx.closed)
+	}
+}
+
+func TestQuery_Writes_ReadBoundTxRejected(t *testing.T) {
+	registerTestTypes(t)
+
+	readTx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{readTx}}, "test_db")
+	tc, err := db.Begin(ReadTransaction)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tc.Close()
+	mgr := MustNewManagerWithTx[testPerson](tc)
+	ctx := context.Background()
+
+	// The writes fail up front instead of opening a write transaction of
+	// their own next to the bound read transaction.
+	var typeErr *TransactionTypeError
+	if _, err := mgr.Query().Update(ctx, map[string]any{"age": 31}); !errors.As(err, &typeErr) {
+		t.Errorf("Update: got %v, want *TransactionTypeError", err)
+	}
+	if _, err := mgr.Query().UpdateWith(ctx, func(*testPerson) {}); !errors.As(err, &typeErr) {
+		t.Errorf("UpdateWith: got %v, want *TransactionTypeError", err)
+	}
+	if len(readTx.queries) != 0 {
+		t.Errorf("expected no queries, got %v", readTx.queries)
+	}
+}
+
 func TestQuery_Update_BulkMap(t *testing.T) {
 	registerTestTypes(t)
 
@@ -476,6 +646,29 @@ func TestQuery_Update_BulkMap(t *testing.T) {
 	}
 }
 
+func TestQuery_Update_Relation(t *testing.T) {
+	registerTestTypes(t)
+	writeTx := &mockTx{responses: [][]map[string]any{{{"count": float64(1)}}, nil}}
+	mgr := MustNewManager[testEmployment](NewDatabase(&mockConn{txs: []*mockTx{writeTx}}, "test_db"))
+
+	count, err := mgr.Query().Filter(RolePlayer("employee", Eq("name", "Alice"))).
+		Update(context.Background(), map[string]any{"start-date": nil})
+	if err != nil || count != 1 {
+		t.Fatalf("Update = %d, %v", count, err)
+	}
+	q := writeTx.queries[1]
+	assertContains(t, q, "$e isa test-employment")
+	assertContains(t, q, "links (employee:")
+	assertContains(t, q, "try { $e has start-date $old0; };\ndelete\ntry { $old0 of $e; };")
+	if strings.Contains(q, "insert") {
+		t.Errorf("nil value should only delete:\n%s", q)
+	}
+
+	if _, err := mgr.Query().Update(context.Background(), map[string]any{"employee": "x"}); err == nil {
+		t.Error("expected error for a role name")
+	}
+}
+
 func TestQuery_Update_EmptyMap(t *testing.T) {
 	registerTestTypes(t)
 
@@ -547,6 +740,26 @@ func TestQuery_Delete_ReturnsMatchedCount(t *testing.T) {
 	assertContains(t, writeTx.queries[1], "delete $e;")
 }
 
+func TestQuery_Delete_NoMatchesReturnsZero(t *testing.T) {
+	registerTestTypes(t)
+
+	// A count over no matches yields no rows.
+	writeTx := &mockTx{responses: [][]map[string]any{nil, nil}}
+	conn := &mockConn{txs: []*mockTx{writeTx}}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db"))
+
+	count, err := mgr.Query().Filter(Eq("name", "nobody")).Delete(context.Background())
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected delete count 0, got %d", count)
+	}
+	if !writeTx.committed {
+		t.Error("transaction was not committed")
+	}
+}
+
 func TestQuery_Exists_True(t *testing.T) {
 	registerTestTypes(t)
 
@@ -879,3 +1092,247 @@ func TestQuery_Chaining(t *testing.T) {
 	assertContains(t, q, "limit 10;")
 	assertContains(t, q, "fetch")
 }
+
+type testAnimal struct {
+	BaseEntity
+	Name string `typedb:"name,key"`
+}
+
+type testDog struct {
+	BaseEntity
+	Name  string `typedb:"name,key"`
+	Breed string `typedb:"breed"`
+}
+
+func TestManager_QueryPolymorphic(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testAnimal]()
+	MustRegister[testDog]()
+	dogInfo, _ := Lookup("test-dog")
+	dogInfo.Supertype = "test-animal"
+
+	readTx := &mockTx{
+		responses: [][]map[string]any{
+			{
+				{"_iid": "0x1", "_type": "test-dog", "name": "Rex", "breed": "collie"},
+				{"_iid": "0x2", "_type": "test-animal", "name": "Generic"},
+				{"_iid": "0x3", "_type": "test-cat", "name": "Tom"},
+			},
+		},
+	}
+	db := NewDatabase(&mockConn{txs: []*mockTx{readTx}}, "test_db")
+	mgr := MustNewManager[testAnimal](db)
+
+	results, err := mgr.QueryPolymorphic(context.Background(), Eq("name", "Rex"))
+	if err != nil {
+		t.Fatalf("QueryPolymorphic failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	dog, ok := results[0].(*testDog)
+	if !ok || dog.Breed != "collie" || dog.GetIID() != "0x1" {
+		t.Errorf("results[0] = %#v, want *testDog with breed", results[0])
+	}
+	if a, ok := results[1].(*testAnimal); !ok || a.Name != "Generic" {
+		t.Errorf("results[1] = %#v, want *testAnimal", results[1])
+	}
+	if a, ok := results[2].(*testAnimal); !ok || a.Name != "Tom" {
+		t.Errorf("unregistered subtype should fall back to base, got %#v", results[2])
+	}
+
+	q := readTx.queries[0]
+	assertContains(t, q, "$e isa! $t;")
+	assertContains(t, q, "$t sub test-animal;")
+	assertContains(t, q, `$e__name == "Rex";`)
+	assertContains(t, q, `"_type": label($t)`)
+	assertContains(t, q, `"breed": $e.breed`)
+}
+
+func TestQuery_ExecutePolymorphic_SortAndLimit(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testAnimal]()
+
+	readTx := &mockTx{responses: [][]map[string]any{nil}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{readTx}}, "test_db")
+	mgr := MustNewManager[testAnimal](db)
+
+	results, err := mgr.Query().OrderAsc("name").Limit(5).ExecutePolymorphic(context.Background())
+	if err != nil {
+		t.Fatalf("ExecutePolymorphic failed: %v", err)
+	}
+	if results != nil {
+		t.Errorf("expected nil results, got %v", results)
+	}
+	q := readTx.queries[0]
+	assertContains(t, q, "sort $e__name asc;")
+	assertContains(t, q, "limit 5;")
+}
+
+// streamingTx yields rows one by one and records how many were produced.
+type streamingTx struct {
+	mockTx
+	rows     []map[string]any
+	produced int
+}
+
+func (s *streamingTx) QueryStream(ctx context.Context, query string) iter.Seq2[map[string]any, error] {
+	s.queries = append(s.queries, query)
+	return func(yield func(map[string]any, error) bool) {
+		for _, row := range s.rows {
+			s.produced++
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
+
+type streamingConn struct {
+	mockConn
+	tx *streamingTx
+}
+
+func (c *streamingConn) Transaction(dbName string, txType int) (Tx, error) { return c.tx, nil }
+
+func TestQuery_Iter(t *testing.T) {
+	registerTestTypes(t)
+	readTx := &mockTx{
+		responses: [][]map[string]any{{
+			{"_iid": "0x1", "name": "Alice", "email": "a@example.com"},
+			{"_iid": "0x2", "name": "Bob", "email": "b@example.com"},
+		}},
+	}
+	mgr := MustNewManager[testPerson](NewDatabase(&mockConn{txs: []*mockTx{readTx}}, "test_db"))
+
+	var names []string
+	for p, err := range mgr.Query().Iter(context.Background()) {
+		if err != nil {
+			t.Fatalf("Iter: %v", err)
+		}
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "Alice,Bob" {
+		t.Errorf("names = %v", names)
+	}
+	if !readTx.closed {
+		t.Error("read transaction not closed after iteration")
+	}
+}
+
+func TestQuery_Iter_StreamingStopsEarly(t *testing.T) {
+	registerTestTypes(t)
+	stx := &streamingTx{rows: []map[string]any{
+		{"name": "Alice"}, {"name": "Bob"}, {"name": "Carol"},
+	}}
+	mgr := MustNewManager[testPerson](NewDatabase(&streamingConn{tx: stx}, "test_db"))
+
+	for p, err := range mgr.Query().Iter(context.Background()) {
+		if err != nil {
+			t.Fatalf("Iter: %v", err)
+		}
+		if p.Name == "Alice" {
+			break
+		}
+	}
+	if stx.produced != 1 {
+		t.Errorf("expected 1 row pulled from the stream, got %d", stx.produced)
+	}
+	if !stx.closed {
+		t.Error("read transaction not closed after break")
+	}
+}
+
+func TestQuery_Iter_HydrationError(t *testing.T) {
+	registerTestTypes(t)
+	stx := &streamingTx{rows: []map[string]any{{"name": "Alice", "age": "not a number"}}}
+	mgr := MustNewManager[testPerson](NewDatabase(&streamingConn{tx: stx}, "test_db"))
+
+	var errs int
+	for p, err := range mgr.Query().Iter(context.Background()) {
+		if err == nil || p != nil {
+			t.Fatalf("expected hydration error, got %v, %v", p, err)
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Errorf("expected exactly one error, got %d", errs)
+	}
+}
+
+func maxRowsDB(rows int) *Database {
+	result := make([]map[string]any, rows)
+	for i := range result {
+		result[i] = map[string]any{"name": fmt.Sprintf("p%d", i), "email": "x@example.com"}
+	}
+	conn := &mockConn{}
+	for range 4 {
+		conn.txs = append(conn.txs, &mockTx{responses: [][]map[string]any{result}})
+	}
+	return NewDatabase(conn, "test_db")
+}
+
+func TestMaxRows_DatabaseLimit(t *testing.T) {
+	registerTestTypes(t)
+	db := maxRowsDB(3)
+	ctx := context.Background()
+
+	mgr := MustNewManager[testPerson](db.WithMaxRows(2))
+	if _, err := mgr.All(ctx); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("All: expected ErrTooManyRows, got %v", err)
+	}
+	if _, err := mgr.Query().Execute(ctx); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("Execute: expected ErrTooManyRows, got %v", err)
+	}
+
+	mgr = MustNewManager[testPerson](db.WithMaxRows(3))
+	people, err := mgr.All(ctx)
+	if err != nil || len(people) != 3 {
+		t.Fatalf("All at the limit: %d rows, err %v", len(people), err)
+	}
+}
+
+func TestMaxRows_QueryOverride(t *testing.T) {
+	registerTestTypes(t)
+	db := maxRowsDB(3)
+	ctx := context.Background()
+	mgr := MustNewManager[testPerson](db.WithMaxRows(1))
+
+	people, err := mgr.Query().MaxRows(0).Execute(ctx)
+	if err != nil || len(people) != 3 {
+		t.Fatalf("MaxRows(0) should lift the limit: %d rows, err %v", len(people), err)
+	}
+	if _, err := MustNewManager[testPerson](db).Query().MaxRows(2).Execute(ctx); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("expected ErrTooManyRows, got %v", err)
+	}
+}
+
+func TestMaxRows_Iter(t *testing.T) {
+	registerTestTypes(t)
+	db := maxRowsDB(3)
+	mgr := MustNewManager[testPerson](db)
+
+	var seen int
+	var iterErr error
+	for _, err := range mgr.Query().MaxRows(2).Iter(context.Background()) {
+		if err != nil {
+			iterErr = err
+			break
+		}
+		seen++
+	}
+	if seen != 2 || !errors.Is(iterErr, ErrTooManyRows) {
+		t.Fatalf("seen %d rows, err %v; want 2 rows then ErrTooManyRows", seen, iterErr)
+	}
+}
+
+func TestDatabase_WithMaxRows(t *testing.T) {
+	db := NewDatabase(&mockConn{}, "test_db")
+	limited := db.WithMaxRows(10)
+	if db.MaxRows() != 0 || limited.MaxRows() != 10 {
+		t.Errorf("MaxRows = %d/%d, want 0/10", db.MaxRows(), limited.MaxRows())
+	}
+	if limited.WithMaxRows(-1).MaxRows() != 0 {
+		t.Error("negative limit should disable the guard")
+	}
+}
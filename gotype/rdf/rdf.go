@@ -0,0 +1,392 @@
+// Package rdf exports the instances of registered gotype models as RDF, in
+// N-Triples or Turtle, so TypeDB data can feed semantic-web tooling.
+//
+// Every instance becomes a resource typed with each registered type it is an
+// instance of. Attributes become literal-valued properties typed with the XSD
+// datatype of their value type, and every role a relation's player plays
+// becomes a property from the relation to the player. URIs are built from a
+// base:
+//
+//	<base>instance/<iid>          instances
+//	<base>type/<type>             entity and relation types
+//	<base>attribute/<attribute>   attribute types
+//	<base>role/<relation>:<role>  roles
+//
+// For example:
+//
+//	err := rdf.Export(ctx, db, w, rdf.WithBase("https://example.com/hr/"), rdf.WithFormat(rdf.Turtle))
+package rdf
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// Format selects the RDF serialization.
+type Format int
+
+const (
+	// NTriples writes one triple per line with full IRIs.
+	NTriples Format = iota
+	// Turtle groups the triples of each subject and abbreviates IRIs with
+	// prefixes.
+	Turtle
+)
+
+const (
+	rdfType = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	xsd     = "http://www.w3.org/2001/XMLSchema#"
+)
+
+// xsdTypes maps TypeDB value types to XSD datatypes. Strings are written as
+// plain literals.
+var xsdTypes = map[string]string{
+	"boolean":     "boolean",
+	"integer":     "integer",
+	"long":        "integer",
+	"double":      "double",
+	"decimal":     "decimal",
+	"date":        "date",
+	"datetime":    "dateTime",
+	"datetime-tz": "dateTime",
+	"duration":    "duration",
+}
+
+// Option configures Export.
+type Option func(*exporter)
+
+// WithBase sets the base URI of the exported resources (default
+// "urn:typedb:<database>/").
+func WithBase(base string) Option {
+	return func(e *exporter) { e.base = base }
+}
+
+// WithFormat selects the serialization (default NTriples).
+func WithFormat(f Format) Option {
+	return func(e *exporter) { e.format = f }
+}
+
+// WithTypes restricts the export to the named registered types and their
+// instances; relations outside the list are not followed.
+func WithTypes(names ...string) Option {
+	return func(e *exporter) { e.types = names }
+}
+
+type exporter struct {
+	db     *gotype.Database
+	w      *bufio.Writer
+	base   string
+	format Format
+	types  []string
+	// valueTypes maps attribute names to their value types, collected from
+	// the registered models.
+	valueTypes map[string]string
+	// described holds the IIDs whose attributes were written.
+	described map[string]bool
+}
+
+// Export writes the instances of the types registered in db's registry to
+// w. Types are walked in name order.
+func Export(ctx context.Context, db *gotype.Database, w io.Writer, opts ...Option) error {
+	e := &exporter{
+		db:         db,
+		w:          bufio.NewWriter(w),
+		base:       "urn:typedb:" + db.Name() + "/",
+		valueTypes: make(map[string]string),
+		described:  make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	var infos []*gotype.ModelInfo
+	for _, info := range db.Registry().RegisteredTypes() {
+		for _, f := range info.Fields {
+			e.valueTypes[f.Tag.Name] = f.ValueType
+		}
+		if e.types == nil || slices.Contains(e.types, info.TypeName) {
+			infos = append(infos, info)
+		}
+	}
+	slices.SortFunc(infos, func(a, b *gotype.ModelInfo) int { return cmp.Compare(a.TypeName, b.TypeName) })
+	for _, name := range e.types {
+		if !slices.ContainsFunc(infos, func(info *gotype.ModelInfo) bool { return info.TypeName == name }) {
+			return fmt.Errorf("rdf: type %s is not registered", name)
+		}
+	}
+
+	if e.format == Turtle {
+		e.writePrefixes()
+	}
+	for _, info := range infos {
+		if err := e.exportInstances(ctx, info); err != nil {
+			return err
+		}
+	}
+	for _, info := range infos {
+		if info.Kind != gotype.ModelKindRelation {
+			continue
+		}
+		if err := e.exportRoles(ctx, info); err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
+
+func (e *exporter) typeIRI(name string) string { return e.base + "type/" + name }
+
+func (e *exporter) attrIRI(name string) string { return e.base + "attribute/" + name }
+
+func (e *exporter) roleIRI(role string) string { return e.base + "role/" + role }
+
+func (e *exporter) instanceIRI(iid string) string { return e.base + "instance/" + iid }
+
+// exportInstances writes a type triple for each instance of info, plus its
+// attributes the first time the instance is seen.
+func (e *exporter) exportInstances(ctx context.Context, info *gotype.ModelInfo) error {
+	mgr, err := gotype.NewDynamicManager(e.db, info.TypeName)
+	if err != nil {
+		return fmt.Errorf("rdf: %w", err)
+	}
+	rows, err := mgr.Get(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("rdf: %w", err)
+	}
+	for _, row := range rows {
+		iid, _ := row["_iid"].(string)
+		if iid == "" {
+			continue
+		}
+		subject := e.instanceIRI(iid)
+		triples := []triple{{subject, rdfType, iriTerm(e.typeIRI(info.TypeName))}}
+		if !e.described[iid] {
+			e.described[iid] = true
+			for _, name := range slices.Sorted(maps.Keys(row)) {
+				if strings.HasPrefix(name, "_") {
+					continue
+				}
+				values, ok := row[name].([]any)
+				if !ok {
+					values = []any{row[name]}
+				}
+				for _, v := range values {
+					if v == nil {
+						continue
+					}
+					triples = append(triples, triple{subject, e.attrIRI(name), e.literal(name, v)})
+				}
+			}
+		}
+		e.writeSubject(triples)
+	}
+	return nil
+}
+
+// exportRoles writes a triple from each relation of info to each of its role
+// players.
+func (e *exporter) exportRoles(ctx context.Context, info *gotype.ModelInfo) error {
+	query := "match\n$r isa " + info.TypeName + ", links ($role: $p);\nfetch {\n" +
+		"  \"_iid\": iid($r),\n  \"player\": iid($p),\n  \"role\": label($role)\n};"
+	results, err := e.db.ExecuteRead(ctx, query)
+	if err != nil {
+		return fmt.Errorf("rdf: roles of %s: %w", info.TypeName, err)
+	}
+	var triples []triple
+	seen := make(map[triple]bool)
+	for _, result := range results {
+		rel, _ := unwrap(result["_iid"]).(string)
+		player, _ := unwrap(result["player"]).(string)
+		role, _ := unwrap(result["role"]).(string)
+		if rel == "" || player == "" || role == "" {
+			continue
+		}
+		if !strings.Contains(role, ":") {
+			role = info.TypeName + ":" + role
+		}
+		t := triple{e.instanceIRI(rel), e.roleIRI(role), iriTerm(e.instanceIRI(player))}
+		if !seen[t] {
+			seen[t] = true
+			triples = append(triples, t)
+		}
+	}
+	// Group by relation so Turtle writes one block per relation.
+	slices.SortStableFunc(triples, func(a, b triple) int { return cmp.Compare(a.subject, b.subject) })
+	for group := range chunkBySubject(triples) {
+		e.writeSubject(group)
+	}
+	return nil
+}
+
+// triple is a subject IRI, a predicate IRI and an object already rendered as
+// an N-Triples term.
+type triple struct {
+	subject, predicate, object string
+}
+
+// chunkBySubject yields the runs of triples sharing a subject.
+func chunkBySubject(triples []triple) iter.Seq[[]triple] {
+	return func(yield func([]triple) bool) {
+		for start := 0; start < len(triples); {
+			end := start + 1
+			for end < len(triples) && triples[end].subject == triples[start].subject {
+				end++
+			}
+			if !yield(triples[start:end]) {
+				return
+			}
+			start = end
+		}
+	}
+}
+
+func (e *exporter) writePrefixes() {
+	for _, p := range [][2]string{
+		{"i", e.instanceIRI("")},
+		{"t", e.typeIRI("")},
+		{"a", e.attrIRI("")},
+		{"r", e.roleIRI("")},
+		{"xsd", xsd},
+	} {
+		fmt.Fprintf(e.w, "@prefix %s: <%s> .\n", p[0], p[1])
+	}
+	e.w.WriteByte('\n')
+}
+
+// writeSubject writes triples, which share their subject.
+func (e *exporter) writeSubject(triples []triple) {
+	if e.format != Turtle {
+		for _, t := range triples {
+			fmt.Fprintf(e.w, "<%s> <%s> %s .\n", t.subject, t.predicate, t.object)
+		}
+		return
+	}
+	e.w.WriteString(e.abbreviate(triples[0].subject))
+	for i, t := range triples {
+		if i > 0 {
+			e.w.WriteString(" ;")
+		}
+		pred := e.abbreviate(t.predicate)
+		if t.predicate == rdfType {
+			pred = "a"
+		}
+		fmt.Fprintf(e.w, "\n    %s %s", pred, e.abbreviateTerm(t.object))
+	}
+	e.w.WriteString(" .\n\n")
+}
+
+// abbreviate returns iri as a prefixed name when one of the export's
+// prefixes applies.
+func (e *exporter) abbreviate(iri string) string {
+	for _, p := range [][2]string{
+		{"i:", e.instanceIRI("")},
+		{"t:", e.typeIRI("")},
+		{"a:", e.attrIRI("")},
+		{"r:", e.roleIRI("")},
+	} {
+		if local, ok := strings.CutPrefix(iri, p[1]); ok && local != "" {
+			return p[0] + local
+		}
+	}
+	return "<" + iri + ">"
+}
+
+// abbreviateTerm abbreviates IRI terms and XSD datatypes in object.
+func (e *exporter) abbreviateTerm(object string) string {
+	if iri, ok := strings.CutPrefix(object, "<"); ok {
+		return e.abbreviate(strings.TrimSuffix(iri, ">"))
+	}
+	if lit, dt, ok := strings.Cut(object, "^^<"+xsd); ok {
+		return lit + "^^xsd:" + strings.TrimSuffix(dt, ">")
+	}
+	return object
+}
+
+func iriTerm(iri string) string { return "<" + iri + ">" }
+
+// literal renders v as a literal of attribute name's datatype.
+func (e *exporter) literal(name string, v any) string {
+	valueType := e.valueTypes[name]
+	var lex string
+	switch v := v.(type) {
+	case string:
+		lex = v
+	case bool:
+		lex = strconv.FormatBool(v)
+		valueType = "boolean"
+	case time.Time:
+		if valueType == "date" {
+			lex = v.Format(time.DateOnly)
+		} else {
+			lex = v.Format(time.RFC3339Nano)
+		}
+	case float64:
+		if valueType == "integer" || valueType == "long" {
+			lex = strconv.FormatFloat(v, 'f', -1, 64)
+		} else {
+			lex = strconv.FormatFloat(v, 'g', -1, 64)
+			if valueType == "" {
+				valueType = "double"
+			}
+		}
+	case int, int64, int32:
+		lex = fmt.Sprint(v)
+		if valueType == "" {
+			valueType = "integer"
+		}
+	default:
+		lex = fmt.Sprint(v)
+	}
+	term := `"` + escape(lex) + `"`
+	if dt, ok := xsdTypes[valueType]; ok {
+		term += "^^<" + xsd + dt + ">"
+	}
+	return term
+}
+
+// escape escapes s for a quoted N-Triples or Turtle literal.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// unwrap flattens a {"value": v} fetch wrapper.
+func unwrap(v any) any {
+	if m, ok := v.(map[string]any); ok {
+		if inner, ok := m["value"]; ok {
+			return inner
+		}
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package rdf_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+	"github.com/CaliLuke/go-typeql/gotype/rdf"
+)
+
+type person struct {
+	gotype.BaseEntity
+	Name   string     `typedb:"name,key"`
+	Age    *int       `typedb:"age,card=0..1"`
+	Joined *time.Time `typedb:"joined,card=0..1"`
+	Bio    string     `typedb:"bio"`
+}
+
+type company struct {
+	gotype.BaseEntity
+	Title string `typedb:"title,key"`
+}
+
+type employment struct {
+	gotype.BaseRelation
+	Employee *person  `typedb:"role:employee"`
+	Employer *company `typedb:"role:employer"`
+	Active   bool     `typedb:"active"`
+}
+
+// newDB stores Alice working at Acme and returns their IIDs.
+func newDB(t *testing.T) (*gotype.Database, map[string]string) {
+	t.Helper()
+	ctx := context.Background()
+	reg := gotype.NewRegistry()
+	for _, err := range []error{
+		gotype.RegisterIn[person](reg),
+		gotype.RegisterIn[company](reg),
+		gotype.RegisterIn[employment](reg),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := gotype.NewDatabase(gotypetest.NewFakeDB(), "hr").WithRegistry(reg)
+	if err := db.ExecuteSchema(ctx, reg.GenerateSchema()); err != nil {
+		t.Fatal(err)
+	}
+	age, joined := 30, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	alice := &person{Name: "Alice", Age: &age, Joined: &joined, Bio: "says \"hi\"\nthen leaves"}
+	acme := &company{Title: "Acme"}
+	if err := gotype.MustNewManager[person](db).Insert(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := gotype.MustNewManager[company](db).Insert(ctx, acme); err != nil {
+		t.Fatal(err)
+	}
+	job := &employment{Employee: alice, Employer: acme, Active: true}
+	if err := gotype.MustNewManager[employment](db).Insert(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	return db, map[string]string{"alice": alice.GetIID(), "acme": acme.GetIID(), "job": job.GetIID()}
+}
+
+func TestNTriples(t *testing.T) {
+	db, iids := newDB(t)
+	var out strings.Builder
+	if err := rdf.Export(context.Background(), db, &out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	alice := "<urn:typedb:hr/instance/" + iids["alice"] + ">"
+	job := "<urn:typedb:hr/instance/" + iids["job"] + ">"
+	for _, want := range []string{
+		alice + " <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <urn:typedb:hr/type/person> .\n",
+		alice + ` <urn:typedb:hr/attribute/name> "Alice" .` + "\n",
+		alice + ` <urn:typedb:hr/attribute/age> "30"^^<http://www.w3.org/2001/XMLSchema#integer> .` + "\n",
+		alice + ` <urn:typedb:hr/attribute/joined> "2024-01-02T03:04:05`,
+		alice + ` <urn:typedb:hr/attribute/bio> "says \"hi\"\nthen leaves" .` + "\n",
+		job + ` <urn:typedb:hr/attribute/active> "true"^^<http://www.w3.org/2001/XMLSchema#boolean> .` + "\n",
+		job + " <urn:typedb:hr/role/employment:employee> " + alice + " .\n",
+		job + " <urn:typedb:hr/role/employment:employer> <urn:typedb:hr/instance/" + iids["acme"] + "> .\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "\n"); n != 11 {
+		t.Errorf("%d triples, want 11:\n%s", n, got)
+	}
+}
+
+func TestTurtle(t *testing.T) {
+	db, iids := newDB(t)
+	var out strings.Builder
+	err := rdf.Export(context.Background(), db, &out,
+		rdf.WithBase("https://example.com/hr/"), rdf.WithFormat(rdf.Turtle), rdf.WithTypes("company", "employment"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"@prefix i: <https://example.com/hr/instance/> .\n",
+		"@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .\n",
+		"i:" + iids["acme"] + "\n    a t:company ;\n    a:title \"Acme\" .\n",
+		"a:active \"true\"^^xsd:boolean .\n",
+		"i:" + iids["job"] + "\n    r:employment:employee i:" + iids["alice"] + " ;\n    r:employment:employer i:" + iids["acme"] + " .\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "t:person") {
+		t.Errorf("person exported despite WithTypes:\n%s", got)
+	}
+
+	if err := rdf.Export(context.Background(), db, &out, rdf.WithTypes("robot")); err == nil {
+		t.Error("expected error for unregistered type")
+	}
+}
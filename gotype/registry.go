@@ -4,75 +4,222 @@ package gotype
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
+
+	"github.com/CaliLuke/go-typeql/tqlgen"
 )
 
 var (
-	globalRegistry = &Registry{
-		byName:   make(map[string]*ModelInfo),
-		byType:   make(map[reflect.Type]*ModelInfo),
-		byGoName: make(map[string]*ModelInfo),
-	}
+	globalRegistry = NewRegistry()
 )
 
 // Registry maintains a mapping between Go struct types and TypeDB model metadata.
 // It is used to look up schema information during query generation and hydration.
+//
+// The package-level functions (Register, Lookup, ...) operate on a default
+// registry. Create separate registries with NewRegistry to host schemas with
+// conflicting type names in one process, and bind one to a Database with
+// Database.WithRegistry.
 type Registry struct {
+	// gen is bumped on every change so cached cross-type templates (such as
+	// polymorphic fetches) can detect newly registered subtypes.
+	gen      atomic.Uint64
 	mu       sync.RWMutex
 	byName   map[string]*ModelInfo
 	byType   map[reflect.Type]*ModelInfo
 	byGoName map[string]*ModelInfo
+	naming   tqlgen.NamingStrategy
+}
+
+// NewRegistry creates an empty model registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byName:   make(map[string]*ModelInfo),
+		byType:   make(map[reflect.Type]*ModelInfo),
+		byGoName: make(map[string]*ModelInfo),
+	}
+}
+
+// DefaultRegistry returns the package-level registry used by Register,
+// Lookup and the other package-level functions.
+func DefaultRegistry() *Registry {
+	return globalRegistry
+}
+
+// RegisterOption configures model registration.
+type RegisterOption func(*registerConfig)
+
+type registerConfig struct {
+	typeName  string
+	naming    tqlgen.NamingStrategy
+	validate  bool
+	hierarchy *Hierarchy
+}
+
+// WithTypeName overrides the TypeDB type name derived from the Go struct name
+// (or from a type: tag option).
+func WithTypeName(name string) RegisterOption {
+	return func(c *registerConfig) { c.typeName = name }
+}
+
+// WithNamingStrategy derives the model's type name and the names of its
+// unnamed attribute fields with ns instead of the registry's strategy.
+func WithNamingStrategy(ns tqlgen.NamingStrategy) RegisterOption {
+	return func(c *registerConfig) { c.naming = ns }
+}
+
+// SetNamingStrategy sets the naming strategy of the default registry.
+func SetNamingStrategy(ns tqlgen.NamingStrategy) {
+	globalRegistry.SetNamingStrategy(ns)
+}
+
+// SetNamingStrategy sets the strategy that derives TypeDB names for models
+// registered afterwards: the type name when neither a type: tag nor
+// WithTypeName gives one, and the attribute name of fields whose typedb tag
+// leaves it out (`typedb:""`, `typedb:",key"`). nil restores the default,
+// tqlgen.KebabCase. Models already registered keep their names.
+func (r *Registry) SetNamingStrategy(ns tqlgen.NamingStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.naming = ns
+}
+
+func (r *Registry) namingStrategy() tqlgen.NamingStrategy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.naming == nil {
+		return tqlgen.KebabCase{}
+	}
+	return r.naming
+}
+
+// WithValidate checks the model's struct tags at registration time and
+// returns a *SchemaValidationError describing every problem found: typedb
+// tags on unexported fields, duplicate attribute or role names, key fields
+// that are pointers or slices, role fields on entities, and role fields
+// whose player is not a struct.
+func WithValidate() RegisterOption {
+	return func(c *registerConfig) { c.validate = true }
+}
+
+// WithHierarchy marks a relation as hierarchical: parentRole and childRole
+// link a child instance to its parent, as in reports-to or parent-of. The
+// tree helpers Manager.Ancestors, Descendants and Roots follow it.
+func WithHierarchy(parentRole, childRole string) RegisterOption {
+	return func(c *registerConfig) {
+		c.hierarchy = &Hierarchy{ParentRole: parentRole, ChildRole: childRole}
+	}
 }
 
 // Register adds a Go struct type to the global registry as a TypeDB model.
 // The type T must embed either BaseEntity or BaseRelation.
-func Register[T any]() error {
-	var zero T
-	t := reflect.TypeOf(zero)
+func Register[T any](opts ...RegisterOption) error {
+	return RegisterIn[T](globalRegistry, opts...)
+}
+
+// RegisterIn adds a Go struct type to the given registry as a TypeDB model.
+func RegisterIn[T any](r *Registry, opts ...RegisterOption) error {
+	return r.RegisterType(reflect.TypeFor[T](), opts...)
+}
+
+// RegisterType adds the Go struct type t to the registry as a TypeDB model.
+// It is the non-generic form of RegisterIn.
+func (r *Registry) RegisterType(t reflect.Type, opts ...RegisterOption) error {
+	cfg := registerConfig{}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
 
-	info, err := ExtractModelInfo(t)
+	typeName := cfg.typeName
+	if typeName == "" && t.Kind() == reflect.Struct {
+		// Check for type: override in first field's tag
+		for field := range t.Fields() {
+			tagStr := field.Tag.Get("typedb")
+			if tagStr == "" {
+				continue
+			}
+			tag, err := ParseTag(tagStr)
+			if err != nil {
+				continue
+			}
+			if tag.TypeName != "" {
+				typeName = tag.TypeName
+				break
+			}
+		}
+	}
+	naming := cfg.naming
+	if naming == nil {
+		naming = r.namingStrategy()
+	}
+
+	info, err := extractModelInfo(t, naming, typeName)
 	if err != nil {
 		return fmt.Errorf("registering %s: %w", t.Name(), err)
 	}
+	info.registry = r
 
-	// Check for type: override in first field's tag
-	for field := range t.Fields() {
-		tagStr := field.Tag.Get("typedb")
-		if tagStr == "" {
-			continue
-		}
-		tag, err := ParseTag(tagStr)
-		if err != nil {
-			continue
+	if err := validateModelNames(info); err != nil {
+		return err
+	}
+	if cfg.validate {
+		if err := validateModelTags(t, info); err != nil {
+			return err
 		}
-		if tag.TypeName != "" {
-			info.TypeName = tag.TypeName
-			break
+	}
+	if h := cfg.hierarchy; h != nil {
+		if err := validateHierarchy(info, h); err != nil {
+			return fmt.Errorf("registering %s: %w", t.Name(), err)
 		}
+		info.Hierarchy = h
 	}
-
-	if err := validateModelNames(info); err != nil {
-		return err
+	if info.templates, err = buildQueryTemplates(info); err != nil {
+		return fmt.Errorf("registering %s: build query templates: %w", t.Name(), err)
 	}
 
-	globalRegistry.mu.Lock()
-	defer globalRegistry.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	if existing, ok := globalRegistry.byName[info.TypeName]; ok {
+	if existing, ok := r.byName[info.TypeName]; ok {
 		if existing.GoType != t {
 			return fmt.Errorf("type name %q already registered to %s", info.TypeName, existing.GoType.Name())
 		}
 	}
 
-	globalRegistry.byName[info.TypeName] = info
-	globalRegistry.byType[t] = info
-	globalRegistry.byGoName[lowerGoName(t.Name())] = info
+	r.resolvePlayerNames(info)
+	r.byName[info.TypeName] = info
+	r.byType[t] = info
+	r.byGoName[lowerGoName(t.Name())] = info
+	r.gen.Add(1)
 	return nil
 }
 
+// resolvePlayerNames points RoleInfo.PlayerTypeName at the registered name of
+// each player, which differs from the Go-derived name when the player was
+// registered with WithTypeName or a type: tag. It covers the roles of info
+// and, since players may be registered after their relations, the roles of
+// registered relations that info plays. The caller holds r.mu.
+func (r *Registry) resolvePlayerNames(info *ModelInfo) {
+	for i := range info.Roles {
+		if player, ok := r.byType[info.Roles[i].playerType]; ok {
+			info.Roles[i].PlayerTypeName = player.TypeName
+		}
+	}
+	for _, rel := range r.byName {
+		for i := range rel.Roles {
+			if rel.Roles[i].playerType == info.GoType {
+				rel.Roles[i].PlayerTypeName = info.TypeName
+			}
+		}
+	}
+}
+
 func validateModelNames(info *ModelInfo) error {
 	kindStr := "entity"
 	if info.Kind == ModelKindRelation {
@@ -114,36 +261,105 @@ func validateModelNames(info *ModelInfo) error {
 
 // MustRegister is a helper that calls Register and panics if an error occurs.
 // It is intended for use during application initialization.
-func MustRegister[T any]() {
-	if err := Register[T](); err != nil {
+func MustRegister[T any](opts ...RegisterOption) {
+	if err := Register[T](opts...); err != nil {
+		panic(err)
+	}
+}
+
+// validateModelTags implements WithValidate. ExtractModelInfo has already
+// rejected unparsable tags on the fields it maps.
+func validateModelTags(t reflect.Type, info *ModelInfo) error {
+	var problems []string
+
+	for field := range t.Fields() {
+		if !field.IsExported() && !field.Anonymous && field.Tag.Get("typedb") != "" {
+			problems = append(problems, fmt.Sprintf("field %s: typedb tag on unexported field is ignored", field.Name))
+		}
+	}
+
+	seen := make(map[string]string)
+	for _, fi := range info.Fields {
+		if prev, ok := seen[fi.Tag.Name]; ok {
+			problems = append(problems, fmt.Sprintf("field %s: attribute %q already mapped by field %s", fi.FieldName, fi.Tag.Name, prev))
+		} else {
+			seen[fi.Tag.Name] = fi.FieldName
+		}
+		if fi.Tag.Key && fi.IsPointer {
+			problems = append(problems, fmt.Sprintf("field %s: key attribute must not be a pointer", fi.FieldName))
+		}
+		if fi.Tag.Key && fi.IsSlice {
+			problems = append(problems, fmt.Sprintf("field %s: key attribute must not be a slice", fi.FieldName))
+		}
+	}
+
+	roles := make(map[string]string)
+	for _, role := range info.Roles {
+		if info.Kind != ModelKindRelation {
+			problems = append(problems, fmt.Sprintf("field %s: role %q declared on an entity", role.FieldName, role.RoleName))
+		}
+		if prev, ok := roles[role.RoleName]; ok {
+			problems = append(problems, fmt.Sprintf("field %s: role %q already mapped by field %s", role.FieldName, role.RoleName, prev))
+		} else {
+			roles[role.RoleName] = role.FieldName
+		}
+		if role.playerType == nil || role.playerType.Kind() != reflect.Struct {
+			problems = append(problems, fmt.Sprintf("field %s: role player must be a struct or pointer to struct", role.FieldName))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &SchemaValidationError{TypeName: info.TypeName, Message: strings.Join(problems, "; ")}
+}
+
+// MustRegisterIn is a helper that calls RegisterIn and panics if an error occurs.
+func MustRegisterIn[T any](r *Registry, opts ...RegisterOption) {
+	if err := RegisterIn[T](r, opts...); err != nil {
 		panic(err)
 	}
 }
 
 // Lookup retrieves ModelInfo for a given TypeDB type name.
 func Lookup(typeName string) (*ModelInfo, bool) {
-	globalRegistry.mu.RLock()
-	defer globalRegistry.mu.RUnlock()
-	info, ok := globalRegistry.byName[typeName]
+	return globalRegistry.Lookup(typeName)
+}
+
+// Lookup retrieves ModelInfo for a given TypeDB type name.
+func (r *Registry) Lookup(typeName string) (*ModelInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.byName[typeName]
 	return info, ok
 }
 
 // LookupType retrieves ModelInfo for a given Go reflect.Type.
 func LookupType(t reflect.Type) (*ModelInfo, bool) {
+	return globalRegistry.LookupType(t)
+}
+
+// LookupType retrieves ModelInfo for a given Go reflect.Type.
+func (r *Registry) LookupType(t reflect.Type) (*ModelInfo, bool) {
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
-	globalRegistry.mu.RLock()
-	defer globalRegistry.mu.RUnlock()
-	info, ok := globalRegistry.byType[t]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.byType[t]
 	return info, ok
 }
 
 // LookupByGoName retrieves ModelInfo based on the name of the Go struct.
 func LookupByGoName(name string) (*ModelInfo, bool) {
-	globalRegistry.mu.RLock()
-	defer globalRegistry.mu.RUnlock()
-	info, ok := globalRegistry.byGoName[lowerGoName(name)]
+	return globalRegistry.LookupByGoName(name)
+}
+
+// LookupByGoName retrieves ModelInfo based on the name of the Go struct.
+func (r *Registry) LookupByGoName(name string) (*ModelInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.byGoName[lowerGoName(name)]
 	if ok {
 		return info, true
 	}
@@ -152,10 +368,15 @@ func LookupByGoName(name string) (*ModelInfo, bool) {
 
 // RegisteredTypes returns a slice containing ModelInfo for all registered types.
 func RegisteredTypes() []*ModelInfo {
-	globalRegistry.mu.RLock()
-	defer globalRegistry.mu.RUnlock()
-	result := make([]*ModelInfo, 0, len(globalRegistry.byType))
-	for _, info := range globalRegistry.byType {
+	return globalRegistry.RegisteredTypes()
+}
+
+// RegisteredTypes returns a slice containing ModelInfo for all registered types.
+func (r *Registry) RegisteredTypes() []*ModelInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*ModelInfo, 0, len(r.byType))
+	for _, info := range r.byType {
 		result = append(result, info)
 	}
 	return result
@@ -164,10 +385,16 @@ func RegisteredTypes() []*ModelInfo {
 // SubtypesOf returns a slice of registered types that are direct subtypes
 // of the specified parent type.
 func SubtypesOf(typeName string) []*ModelInfo {
-	globalRegistry.mu.RLock()
-	defer globalRegistry.mu.RUnlock()
+	return globalRegistry.SubtypesOf(typeName)
+}
+
+// SubtypesOf returns a slice of registered types that are direct subtypes
+// of the specified parent type.
+func (r *Registry) SubtypesOf(typeName string) []*ModelInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	var result []*ModelInfo
-	for _, info := range globalRegistry.byName {
+	for _, info := range r.byName {
 		if info.Supertype == typeName {
 			result = append(result, info)
 		}
@@ -177,17 +404,79 @@ func SubtypesOf(typeName string) []*ModelInfo {
 
 // ResolveType maps a TypeDB type label to its registered ModelInfo.
 func ResolveType(typeLabel string) (*ModelInfo, bool) {
-	return Lookup(typeLabel)
+	return globalRegistry.Lookup(typeLabel)
+}
+
+// ResolveType maps a TypeDB type label to its registered ModelInfo.
+func (r *Registry) ResolveType(typeLabel string) (*ModelInfo, bool) {
+	return r.Lookup(typeLabel)
+}
+
+// SetRoleCardinality bounds the number of players of role in the registered
+// relation type. A negative max means unbounded. It is meant to be called
+// during initialization, typically with the MinCard and MaxCard of a
+// tqlgen-generated registry:
+//
+//	for rel, roles := range schema.RelationSchema {
+//	    for _, ri := range roles {
+//	        gotype.SetRoleCardinality(rel, ri.RoleName, ri.MinCard, ri.MaxCard)
+//	    }
+//	}
+func SetRoleCardinality(relation, role string, min, max int) error {
+	return globalRegistry.SetRoleCardinality(relation, role, min, max)
+}
+
+// SetRoleCardinality bounds the number of players of role in the registered
+// relation type. A negative max means unbounded.
+func (r *Registry) SetRoleCardinality(relation, role string, min, max int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info, ok := r.byName[relation]
+	if !ok {
+		return &NotRegisteredError{TypeName: relation}
+	}
+	var cardMax *int
+	if max >= 0 {
+		cardMax = new(max)
+	}
+	found := false
+	for i := range info.Roles {
+		if info.Roles[i].RoleName == role {
+			info.Roles[i].CardMin, info.Roles[i].CardMax = new(min), cardMax
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("gotype: relation %s has no role %q", relation, role)
+	}
+	return nil
 }
 
 // ClearRegistry resets the global registry, removing all registered models.
 // This is primarily used for testing purposes.
 func ClearRegistry() {
-	globalRegistry.mu.Lock()
-	defer globalRegistry.mu.Unlock()
-	globalRegistry.byName = make(map[string]*ModelInfo)
-	globalRegistry.byType = make(map[reflect.Type]*ModelInfo)
-	globalRegistry.byGoName = make(map[string]*ModelInfo)
+	globalRegistry.Clear()
+}
+
+// Clear removes all registered models from the registry and restores the
+// default naming strategy.
+func (r *Registry) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.naming = nil
+	r.byName = make(map[string]*ModelInfo)
+	r.byType = make(map[reflect.Type]*ModelInfo)
+	r.byGoName = make(map[string]*ModelInfo)
+	r.gen.Add(1)
+}
+
+// registryOf returns the registry info was registered in, falling back to the
+// default registry for models built directly with ExtractModelInfo.
+func registryOf(info *ModelInfo) *Registry {
+	if info != nil && info.registry != nil {
+		return info.registry
+	}
+	return globalRegistry
 }
 
 func lowerGoName(name string) string {
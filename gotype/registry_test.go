@@ -1,8 +1,13 @@
 package gotype
 
 import (
+	"context"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/CaliLuke/go-typeql/tqlgen"
 )
 
 func TestRegister(t *testing.T) {
@@ -165,3 +170,238 @@ func TestFieldByAttrName(t *testing.T) {
 		t.Errorf("FieldName: got %q, want %q", f.FieldName, "Email")
 	}
 }
+
+type testBillingAccount struct {
+	BaseEntity
+	Number string `typedb:"number,key,type:account"`
+}
+
+type testAuthAccount struct {
+	BaseEntity
+	Login string `typedb:"login,key,type:account"`
+}
+
+func TestRegistry_Isolated(t *testing.T) {
+	ClearRegistry()
+	billing := NewRegistry()
+	auth := NewRegistry()
+
+	MustRegisterIn[testBillingAccount](billing)
+	MustRegisterIn[testAuthAccount](auth)
+
+	// Both registries own a type named "account" without conflict.
+	b, ok := billing.Lookup("account")
+	if !ok || b.GoType != reflect.TypeOf(testBillingAccount{}) {
+		t.Fatalf("billing account = %v, %v", b, ok)
+	}
+	a, ok := auth.Lookup("account")
+	if !ok || a.GoType != reflect.TypeOf(testAuthAccount{}) {
+		t.Fatalf("auth account = %v, %v", a, ok)
+	}
+	if _, ok := Lookup("account"); ok {
+		t.Error("default registry should be untouched")
+	}
+
+	// The same registry still rejects a conflicting name.
+	if err := RegisterIn[testAuthAccount](billing); err == nil {
+		t.Error("expected type name conflict in one registry")
+	}
+
+	billing.Clear()
+	if len(billing.RegisteredTypes()) != 0 {
+		t.Error("Clear should empty the registry")
+	}
+	if len(auth.RegisteredTypes()) != 1 {
+		t.Error("Clear must not affect other registries")
+	}
+}
+
+func TestRegistry_DatabaseBinding(t *testing.T) {
+	ClearRegistry()
+	reg := NewRegistry()
+	MustRegisterIn[testAuthAccount](reg)
+
+	tx := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1", "login": "root"}}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db")
+
+	if _, err := NewManager[testAuthAccount](db); err == nil {
+		t.Fatal("expected not-registered error with the default registry")
+	}
+
+	scoped := db.WithRegistry(reg)
+	if scoped.Registry() != reg || db.Registry() != DefaultRegistry() {
+		t.Fatal("WithRegistry must not modify the original handle")
+	}
+	mgr, err := NewManager[testAuthAccount](scoped)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	got, err := mgr.All(context.Background())
+	if err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Login != "root" {
+		t.Errorf("got %+v", got)
+	}
+	assertContains(t, tx.queries[0], "$e isa account")
+
+	if !strings.Contains(reg.GenerateSchema(), "entity account") {
+		t.Errorf("registry schema missing account:\n%s", reg.GenerateSchema())
+	}
+}
+
+type testUserAccount struct {
+	BaseEntity
+	Login string `typedb:"login,key"`
+}
+
+type testBadTags struct {
+	BaseEntity
+	ID     *string     `typedb:"id,key"`
+	Name   string      `typedb:"name"`
+	Alias  string      `typedb:"name"`
+	Player *testPerson `typedb:"role:owner"`
+	hidden string      `typedb:"hidden"`
+}
+
+func TestRegister_WithTypeName(t *testing.T) {
+	ClearRegistry()
+	if err := Register[testUserAccount](WithTypeName("user_account")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := Lookup("user_account"); !ok {
+		t.Error("expected model under overridden name")
+	}
+	if _, ok := Lookup("test-user-account"); ok {
+		t.Error("derived name should not be registered")
+	}
+
+	if err := Register[testUserAccount](WithTypeName("match")); err == nil {
+		t.Error("expected reserved word error for overridden name")
+	}
+}
+
+func TestRegister_WithTypeNamePlayer(t *testing.T) {
+	playerName := func(reg *Registry, role string) string {
+		info, _ := reg.Lookup("test-employment")
+		for _, r := range info.Roles {
+			if r.RoleName == role {
+				return r.PlayerTypeName
+			}
+		}
+		return ""
+	}
+
+	// Player registered before the relation.
+	reg := NewRegistry()
+	MustRegisterIn[TestPerson](reg, WithTypeName("employee-person"))
+	MustRegisterIn[TestEmployment](reg)
+	if got := playerName(reg, "employee"); got != "employee-person" {
+		t.Errorf("player first: PlayerTypeName = %q, want employee-person", got)
+	}
+	if got := playerName(reg, "employer"); got != "test-company" {
+		t.Errorf("unregistered player: PlayerTypeName = %q, want test-company", got)
+	}
+
+	// Player registered after the relation.
+	reg = NewRegistry()
+	MustRegisterIn[TestEmployment](reg)
+	MustRegisterIn[TestPerson](reg, WithTypeName("employee-person"))
+	if got := playerName(reg, "employee"); got != "employee-person" {
+		t.Errorf("relation first: PlayerTypeName = %q, want employee-person", got)
+	}
+}
+
+type testUserProfile struct {
+	BaseEntity
+	UserID      string `typedb:",key"`
+	DisplayName string `typedb:""`
+	Email       string `typedb:"contact"`
+	Notes       string
+}
+
+type testProfileLink struct {
+	BaseRelation
+	Owner *testUserProfile `typedb:"role:owner"`
+}
+
+func TestRegister_NamingStrategy(t *testing.T) {
+	reg := NewRegistry()
+	MustRegisterIn[testUserProfile](reg)
+	info, ok := reg.Lookup("test-user-profile")
+	if !ok {
+		t.Fatal("expected kebab-case type name by default")
+	}
+	var attrs []string
+	for _, f := range info.Fields {
+		attrs = append(attrs, f.Tag.Name)
+	}
+	if !reflect.DeepEqual(attrs, []string{"user-id", "display-name", "contact"}) {
+		t.Errorf("attributes = %v", attrs)
+	}
+	if len(info.KeyFields) != 1 || info.KeyFields[0].Tag.Name != "user-id" {
+		t.Errorf("key fields = %+v", info.KeyFields)
+	}
+
+	reg = NewRegistry()
+	reg.SetNamingStrategy(tqlgen.SnakeCase{PrefixAttributes: true})
+	MustRegisterIn[testUserProfile](reg)
+	MustRegisterIn[testProfileLink](reg)
+	info, ok = reg.Lookup("test_user_profile")
+	if !ok {
+		t.Fatal("expected snake_case type name")
+	}
+	if _, ok := info.FieldByAttrName("test_user_profile_display_name"); !ok {
+		t.Errorf("fields = %+v", info.Fields)
+	}
+	if _, ok := info.FieldByAttrName("contact"); !ok {
+		t.Error("explicit attribute names must not change")
+	}
+	link, _ := reg.Lookup("test_profile_link")
+	if link == nil || link.Roles[0].PlayerTypeName != "test_user_profile" {
+		t.Errorf("link = %+v", link)
+	}
+
+	// A per-model strategy and explicit type names take precedence.
+	MustRegisterIn[testUserAccount](reg, WithNamingStrategy(tqlgen.KebabCase{}))
+	MustRegisterIn[testCompany](reg, WithTypeName("firm"))
+	if _, ok := reg.Lookup("test-user-account"); !ok {
+		t.Error("WithNamingStrategy not applied")
+	}
+	if _, ok := reg.Lookup("firm"); !ok {
+		t.Error("WithTypeName not applied")
+	}
+}
+
+func TestRegister_WithValidate(t *testing.T) {
+	ClearRegistry()
+
+	// Without validation the problems go unnoticed.
+	if err := Register[testBadTags](); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ClearRegistry()
+	err := Register[testBadTags](WithValidate())
+	var verr *SchemaValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *SchemaValidationError, got %v", err)
+	}
+	for _, want := range []string{
+		"field hidden: typedb tag on unexported field",
+		`field Alias: attribute "name" already mapped by field Name`,
+		"field ID: key attribute must not be a pointer",
+		`field Player: role "owner" declared on an entity`,
+	} {
+		if !strings.Contains(verr.Message, want) {
+			t.Errorf("message missing %q:\n%s", want, verr.Message)
+		}
+	}
+	if _, ok := Lookup("test-bad-tags"); ok {
+		t.Error("invalid model must not be registered")
+	}
+
+	if err := Register[TestPerson](WithValidate()); err != nil {
+		t.Errorf("valid model rejected: %v", err)
+	}
+}
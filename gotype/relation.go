@@ -1,6 +1,11 @@
 // Package gotype provides reflection-based TypeDB data mapping.
 package gotype
 
+import (
+	"reflect"
+	"slices"
+)
+
 // Relation is the marker interface for TypeDB relation types.
 // Structs that represent TypeDB relations must satisfy this interface,
 // typically by embedding the BaseRelation type.
@@ -48,9 +53,65 @@ type RoleInfo struct {
 	// FieldName is the name of the Go struct field representing the player.
 	FieldName string
 
-	// FieldIndex is the 0-based index of the field in the Go struct.
+	// FieldIndex is the 0-based index of the field in the Go struct. For
+	// fields hoisted from an embedded struct it is the index of the embedding
+	// field.
 	FieldIndex int
 
-	// PlayerTypeName is the TypeDB type label of the expected role player.
+	// PlayerTypeName is the TypeDB type label of the expected role player:
+	// its registered type name once the player type is registered in the
+	// same registry, and the name derived from its Go type until then.
 	PlayerTypeName string
+
+	// CardMin and CardMax bound the number of players of the role, counted
+	// over all fields mapping it. They come from the field's card= tag option
+	// or Registry.SetRoleCardinality; nil means unconstrained.
+	CardMin *int
+	CardMax *int
+
+	// index is the reflect index path of the field, including embedded structs.
+	index []int
+	// playerType is the Go struct type of the role player field.
+	playerType reflect.Type
+}
+
+// checkRoleCardinality counts the players of each role of v, an instance of
+// the relation info, and reports the first role outside its cardinality.
+func checkRoleCardinality(info *ModelInfo, v reflect.Value) error {
+	counts := make(map[string]int)
+	var order []string
+	for _, role := range info.Roles {
+		if !slices.Contains(order, role.RoleName) {
+			order = append(order, role.RoleName)
+		}
+		field := role.fieldValue(v)
+		switch field.Kind() {
+		case reflect.Pointer:
+			if !field.IsNil() {
+				counts[role.RoleName]++
+			}
+		case reflect.Slice:
+			counts[role.RoleName] += field.Len()
+		default:
+			counts[role.RoleName]++
+		}
+	}
+	for _, name := range order {
+		min, max := 0, -1
+		for _, role := range info.Roles {
+			if role.RoleName != name {
+				continue
+			}
+			if role.CardMin != nil {
+				min = *role.CardMin
+			}
+			if role.CardMax != nil {
+				max = *role.CardMax
+			}
+		}
+		if n := counts[name]; n < min || (max >= 0 && n > max) {
+			return &RoleCardinalityError{Relation: info.TypeName, Role: name, Count: n, Min: min, Max: max}
+		}
+	}
+	return nil
 }
@@ -83,6 +83,22 @@ func ValidateIdentifier(name, context string) error {
 	return nil
 }
 
+// IsIID reports whether s is a hexadecimal IID such as
+// 0x1e00000000000000000001. Strings that pass are safe to place in an iid
+// constraint; callers accepting IIDs from outside should check them first.
+func IsIID(s string) bool {
+	hex, ok := strings.CutPrefix(s, "0x")
+	if !ok || hex == "" {
+		return false
+	}
+	for _, c := range hex {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
 // InvalidIdentifierError is returned when a name contains characters
 // not allowed in TypeQL identifiers.
 type InvalidIdentifierError struct {
@@ -104,6 +104,21 @@ func TestValidateIdentifier_Invalid(t *testing.T) {
 	}
 }
 
+// --- IsIID ---
+
+func TestIsIID(t *testing.T) {
+	for _, s := range []string{"0x1", "0x1e00000000000000000001", "0xABCdef09"} {
+		if !IsIID(s) {
+			t.Errorf("expected %q to be an IID", s)
+		}
+	}
+	for _, s := range []string{"", "0x", "1e00", "0X1f", "0x1g", "0x1 ", "0x1; delete $x"} {
+		if IsIID(s) {
+			t.Errorf("expected %q not to be an IID", s)
+		}
+	}
+}
+
 func TestReservedWordError_Message(t *testing.T) {
 	err := &ReservedWordError{Word: "label", Context: "attribute"}
 	msg := err.Error()
@@ -0,0 +1,84 @@
+package gotype
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// WriteSummary reports the effect of a write query as counted by the driver.
+type WriteSummary struct {
+	// Inserted is the number of entity and relation instances created.
+	Inserted int64
+	// Deleted is the number of entity and relation instances removed by the
+	// query's delete stage.
+	Deleted int64
+}
+
+// Result is the outcome of a query: the rows it returned and, when the
+// driver reports one, a summary of the writes it made.
+type Result struct {
+	Rows []map[string]any
+	// Summary is nil when the driver does not report write counts for the
+	// query.
+	Summary *WriteSummary
+}
+
+// ResultTx is implemented by transactions whose driver reports the effect of
+// write queries. Delete and Unlink rely on the summary of their delete query
+// instead of issuing a separate count query, and fail if it is missing.
+//
+// The TypeDB 3 driver returns answer rows but no counts; the transactions of
+// the Conn adapters in cmd/gotypeql and gotypetest implement ResultTx with
+// RowSummary.
+type ResultTx interface {
+	Tx
+	// QueryResult executes query and returns its rows with the write summary.
+	QueryResult(ctx context.Context, query string) (Result, error)
+}
+
+// queryResult executes query on tx, returning the write summary when tx
+// reports one and the rows alone otherwise.
+func queryResult(ctx context.Context, tx Tx, query string) (Result, error) {
+	if rtx, ok := tx.(ResultTx); ok {
+		return rtx.QueryResult(ctx, query)
+	}
+	rows, err := tx.QueryWithContext(ctx, query)
+	return Result{Rows: rows}, err
+}
+
+// reportsSummary reports whether tx, after unwrapping the transaction
+// wrappers added by Database and ConnPool, is a ResultTx.
+func reportsSummary(tx Tx) bool {
+	if _, ok := tx.(ResultTx); !ok {
+		return false
+	}
+	for {
+		w, ok := tx.(interface{ Unwrap() Tx })
+		if !ok {
+			break
+		}
+		tx = w.Unwrap()
+	}
+	_, ok := tx.(ResultTx)
+	return ok
+}
+
+// deleteStage matches a query whose last stage deletes whole instances, e.g.
+// "delete $e;" or "delete $a, $b;", capturing the variables.
+var deleteStage = regexp.MustCompile(`(?:^|[;\n])\s*delete\s+(\$[\w-]+(?:\s*,\s*\$[\w-]+)*)\s*;\s*$`)
+
+// RowSummary derives the write summary of query from the answer rows the
+// driver returned for it, for drivers that return rows but no counts. A
+// final stage deleting instances, such as "delete $e;", deletes one instance
+// per variable and answer; like "reduce $n = count($e);", an instance in
+// several answers is counted once per answer. Other queries have no summary
+// and RowSummary returns nil.
+func RowSummary(query string, rows []map[string]any) *WriteSummary {
+	m := deleteStage.FindStringSubmatch(query)
+	if m == nil {
+		return nil
+	}
+	vars := int64(strings.Count(m[1], "$"))
+	return &WriteSummary{Deleted: vars * int64(len(rows))}
+}
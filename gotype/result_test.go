@@ -0,0 +1,160 @@
+package gotype
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// summaryTx is a mockTx whose driver reports a write summary per query, or
+// none when missing is set.
+type summaryTx struct {
+	*mockTx
+	summaries []WriteSummary
+	missing   bool
+}
+
+func (s *summaryTx) QueryResult(ctx context.Context, query string) (Result, error) {
+	i := len(s.queries)
+	rows, err := s.QueryWithContext(ctx, query)
+	if err != nil {
+		return Result{}, err
+	}
+	if s.missing {
+		return Result{Rows: rows}, nil
+	}
+	var summary WriteSummary
+	if i < len(s.summaries) {
+		summary = s.summaries[i]
+	}
+	return Result{Rows: rows, Summary: &summary}, nil
+}
+
+// summaryConn hands out the mock transactions of mockConn wrapped as
+// summaryTx.
+type summaryConn struct {
+	*mockConn
+	summaries [][]WriteSummary
+	missing   bool
+}
+
+func (c *summaryConn) Transaction(dbName string, txType int) (Tx, error) {
+	i := c.idx
+	tx, err := c.mockConn.Transaction(dbName, txType)
+	if err != nil {
+		return nil, err
+	}
+	var summaries []WriteSummary
+	if i < len(c.summaries) {
+		summaries = c.summaries[i]
+	}
+	return &summaryTx{mockTx: tx.(*mockTx), summaries: summaries, missing: c.missing}, nil
+}
+
+func TestQuery_Delete_UsesWriteSummary(t *testing.T) {
+	registerTestTypes(t)
+
+	for _, tc := range []struct {
+		name string
+		opts []DatabaseOption
+	}{
+		{"plain", nil},
+		{"observed", []DatabaseOption{WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			writeTx := &mockTx{}
+			conn := &summaryConn{
+				mockConn:  &mockConn{txs: []*mockTx{writeTx}},
+				summaries: [][]WriteSummary{{{Deleted: 2}}},
+			}
+			db := NewDatabase(conn, "test_db", tc.opts...)
+			mgr := MustNewManager[testPerson](db)
+
+			count, err := mgr.Query().Filter(Eq("name", "Alice")).Delete(context.Background())
+			if err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if count != 2 {
+				t.Fatalf("expected delete count 2 from the summary, got %d", count)
+			}
+			if len(writeTx.queries) != 1 {
+				t.Fatalf("expected only the delete query, got %d: %v", len(writeTx.queries), writeTx.queries)
+			}
+			assertContains(t, writeTx.queries[0], "delete $e;")
+			if !writeTx.committed {
+				t.Fatal("expected commit")
+			}
+		})
+	}
+}
+
+func TestDelete_MissingWriteSummary(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	deleteTx, unlinkTx := &mockTx{}, &mockTx{}
+	conn := &summaryConn{mockConn: &mockConn{txs: []*mockTx{deleteTx, unlinkTx}}, missing: true}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db"))
+
+	if _, err := mgr.Query().Filter(Eq("name", "Alice")).Delete(ctx); err == nil || !strings.Contains(err.Error(), "no write summary") {
+		t.Errorf("Delete: err = %v", err)
+	}
+	if deleteTx.committed {
+		t.Error("Delete committed without a count")
+	}
+
+	alice := &testPerson{Name: "Alice"}
+	alice.SetIID("0x1")
+	if _, err := mgr.Unlink(ctx, alice, "friendship", "friend", "0x2", "friend"); err == nil || !strings.Contains(err.Error(), "no write summary") {
+		t.Errorf("Unlink: err = %v", err)
+	}
+	if unlinkTx.committed {
+		t.Error("Unlink committed without a count")
+	}
+}
+
+func TestRowSummary(t *testing.T) {
+	rows := []map[string]any{{}, {}, {}}
+	for _, tc := range []struct {
+		query string
+		want  int64 // -1 for no summary
+	}{
+		{"match\n$e isa person;\ndelete $e;", 3},
+		{"match $r isa friendship, links (friend: $a);\ndelete $r;\n", 3},
+		{"match\n$a isa person;\n$b isa person;\ndelete $a, $b;", 6},
+		{"match\n$e isa person, has name $n;\ndelete has $n of $e;", -1},
+		{"insert $e isa person;", -1},
+		{"match $e isa person;\nfetch { \"n\": $e.name };", -1},
+	} {
+		got := RowSummary(tc.query, rows)
+		switch {
+		case tc.want < 0 && got != nil:
+			t.Errorf("%q: expected no summary, got %+v", tc.query, got)
+		case tc.want >= 0 && (got == nil || got.Deleted != tc.want):
+			t.Errorf("%q: expected %d deleted, got %+v", tc.query, tc.want, got)
+		}
+	}
+}
+
+func TestReportsSummary(t *testing.T) {
+	plain := &mockTx{}
+	summarized := &summaryTx{mockTx: &mockTx{}}
+	db := &Database{}
+	cases := []struct {
+		name string
+		tx   Tx
+		want bool
+	}{
+		{"plain", plain, false},
+		{"summarized", summarized, true},
+		{"observed plain", &observedTx{Tx: plain, db: db}, false},
+		{"observed summarized", &observedTx{Tx: summarized, db: db}, true},
+		{"pooled summarized", &pooledTx{tx: summarized}, true},
+	}
+	for _, tc := range cases {
+		if got := reportsSummary(tc.tx); got != tc.want {
+			t.Errorf("%s: reportsSummary = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -10,7 +10,13 @@ import (
 // GenerateSchema produces a complete TypeQL `define` query string for all
 // models currently registered in the global registry.
 func GenerateSchema() string {
-	types := RegisteredTypes()
+	return globalRegistry.GenerateSchema()
+}
+
+// GenerateSchema produces a complete TypeQL `define` query string for all
+// models registered in r.
+func (r *Registry) GenerateSchema() string {
+	types := r.RegisteredTypes()
 	if len(types) == 0 {
 		return ""
 	}
@@ -108,7 +114,11 @@ func generateTypeDef(info *ModelInfo, playsMap map[string][]string) string {
 
 	// Roles (relation only)
 	for _, role := range info.Roles {
-		lines = append(lines, fmt.Sprintf("    relates %s", role.RoleName))
+		line := fmt.Sprintf("    relates %s", role.RoleName)
+		if card := formatCardAnnotation(role.CardMin, role.CardMax); card != "" {
+			line += " " + card
+		}
+		lines = append(lines, line)
 	}
 
 	// Plays clauses (entity only, TypeDB 3.x)
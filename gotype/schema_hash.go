@@ -0,0 +1,83 @@
+package gotype
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// schemaVersionSchemaTQL defines the entity EnsureSchema uses to remember the
+// hash of the last schema it applied.
+const schemaVersionSchemaTQL = `define
+attribute schema-version-hash, value string;
+attribute schema-version-applied-at, value datetime;
+entity schema-version,
+    owns schema-version-hash,
+    owns schema-version-applied-at;`
+
+// SchemaHash returns the SHA-256 hex digest of schemaText, the default guard
+// value used by EnsureSchema.
+func SchemaHash(schemaText string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(schemaText)))
+}
+
+// EnsureSchema applies schemaText unless the database already records
+// schemaHash as the last applied schema, and reports whether it applied it.
+// If schemaHash is empty, SchemaHash(schemaText) is used.
+//
+// The hash is kept in a single schema-version entity. The schema and the new
+// hash are written in the same schema transaction, so a failed apply leaves
+// the previous hash in place and the next call retries.
+func EnsureSchema(ctx context.Context, db *Database, schemaText, schemaHash string) (bool, error) {
+	if schemaHash == "" {
+		schemaHash = SchemaHash(schemaText)
+	}
+	if err := db.ExecuteSchema(ctx, schemaVersionSchemaTQL); err != nil {
+		return false, fmt.Errorf("ensure schema: define version tracking: %w", err)
+	}
+
+	stored, err := storedSchemaHash(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	if stored == schemaHash {
+		return false, nil
+	}
+
+	tx, err := db.openTransaction(ctx, SchemaTransaction)
+	if err != nil {
+		return false, fmt.Errorf("ensure schema: open schema transaction: %w", err)
+	}
+	defer tx.Close()
+
+	queries := []string{
+		schemaText,
+		"match\n$v isa schema-version;\ndelete $v;",
+		fmt.Sprintf("insert\n$v isa schema-version,\nhas schema-version-hash \"%s\",\nhas schema-version-applied-at %s;",
+			escapeTQL(schemaHash), FormatValue(time.Now().UTC())),
+	}
+	for _, q := range queries {
+		if _, err := tx.QueryWithContext(ctx, q); err != nil {
+			return false, fmt.Errorf("ensure schema: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("ensure schema: commit: %w", err)
+	}
+	return true, nil
+}
+
+// storedSchemaHash returns the hash recorded by the last EnsureSchema, or "".
+func storedSchemaHash(ctx context.Context, db *Database) (string, error) {
+	results, err := db.ExecuteRead(ctx, "match\n$v isa schema-version;\nfetch {\n  \"hash\": $v.schema-version-hash\n};")
+	if err != nil {
+		return "", fmt.Errorf("ensure schema: read stored hash: %w", err)
+	}
+	for _, row := range results {
+		if h, ok := unwrapResult(row)["hash"].(string); ok {
+			return h, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,68 @@
+package gotype
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const testEnsureSchemaText = "define\nattribute name, value string;"
+
+func TestEnsureSchema_AppliesWhenHashDiffers(t *testing.T) {
+	defineTx := &mockTx{}
+	readTx := &mockTx{responses: [][]map[string]any{{{"hash": "old"}}}}
+	applyTx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{defineTx, readTx, applyTx}}, "test_db")
+
+	applied, err := EnsureSchema(context.Background(), db, testEnsureSchemaText, "v2")
+	if err != nil {
+		t.Fatalf("EnsureSchema failed: %v", err)
+	}
+	if !applied {
+		t.Fatal("expected schema to be applied")
+	}
+
+	assertContains(t, defineTx.queries[0], "entity schema-version")
+	if len(applyTx.queries) != 3 {
+		t.Fatalf("expected 3 queries in apply tx, got %d", len(applyTx.queries))
+	}
+	if applyTx.queries[0] != testEnsureSchemaText {
+		t.Errorf("first query should be the schema, got %q", applyTx.queries[0])
+	}
+	assertContains(t, applyTx.queries[1], "delete $v;")
+	assertContains(t, applyTx.queries[2], `has schema-version-hash "v2"`)
+	if !applyTx.committed {
+		t.Error("apply transaction was not committed")
+	}
+}
+
+func TestEnsureSchema_SkipsWhenHashMatches(t *testing.T) {
+	defineTx := &mockTx{}
+	readTx := &mockTx{responses: [][]map[string]any{{{"hash": map[string]any{"value": "v2"}}}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{defineTx, readTx}}, "test_db")
+
+	applied, err := EnsureSchema(context.Background(), db, testEnsureSchemaText, "v2")
+	if err != nil {
+		t.Fatalf("EnsureSchema failed: %v", err)
+	}
+	if applied {
+		t.Error("expected schema apply to be skipped")
+	}
+}
+
+func TestEnsureSchema_DefaultHash(t *testing.T) {
+	readTx := &mockTx{responses: [][]map[string]any{nil}}
+	applyTx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{{}, readTx, applyTx}}, "test_db")
+
+	if _, err := EnsureSchema(context.Background(), db, testEnsureSchemaText, ""); err != nil {
+		t.Fatalf("EnsureSchema failed: %v", err)
+	}
+	want := SchemaHash(testEnsureSchemaText)
+	if len(want) != 64 {
+		t.Fatalf("SchemaHash length = %d, want 64", len(want))
+	}
+	if !strings.Contains(applyTx.queries[2], want) {
+		t.Errorf("expected computed hash %s in %q", want, applyTx.queries[2])
+	}
+}
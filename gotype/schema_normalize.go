@@ -0,0 +1,252 @@
+package gotype
+
+import (
+	"slices"
+	"strings"
+)
+
+// schemaKindOrder is the order in which NormalizeSchema emits definitions.
+var schemaKindOrder = map[string]int{"attribute": 0, "struct": 1, "entity": 2, "relation": 3, "fun": 4}
+
+// NormalizeSchema rewrites a TypeQL define query, such as the schema returned
+// by Database.Schema, into a canonical form so that it can be committed and
+// diffed: definitions are grouped by kind (attributes, structs, entities,
+// relations, functions) and sorted by label, "sub" is moved next to the label,
+// the other constraints of each type are sorted, statements that extend an
+// already defined type are merged into it, and whitespace is normalized. Comments are dropped. Several
+// define blocks are merged into one.
+//
+// The result is a valid define query accepted by tqlgen.ParseSchema, and
+// normalizing it again returns it unchanged.
+func NormalizeSchema(schema string) string {
+	var types []*schemaDef
+	byLabel := make(map[string]*schemaDef)
+	var fn *schemaDef
+	for _, stmt := range splitSchemaStatements(schema) {
+		parts := splitTopLevel(stmt, ',')
+		head := strings.Fields(parts[0])
+		kind := head[0]
+		_, isKind := schemaKindOrder[kind]
+		if fn != nil && !isKind && !isTypeExtension(head) {
+			// A statement of the preceding function's body.
+			fn.body = append(fn.body, stmt)
+			continue
+		}
+		fn = nil
+		if kind == "fun" {
+			fn = &schemaDef{kind: kind, label: schemaFunName(stmt), head: stmt}
+			if i := strings.Index(stmt, "->"); i >= 0 {
+				if j := strings.IndexByte(stmt[i:], ':'); j >= 0 {
+					fn.head = stmt[:i+j+1]
+					fn.body = append(fn.body, strings.TrimSpace(stmt[i+j+1:]))
+				}
+			}
+			types = append(types, fn)
+			continue
+		}
+
+		var label string
+		if isKind && len(head) > 1 {
+			label = head[1]
+			if isTypeExtension(head[1:]) && head[2] != "sub" {
+				// "attribute name value string": split off the constraint.
+				rest := strings.TrimSpace(strings.TrimPrefix(parts[0], kind+" "+label))
+				parts = append([]string{kind + " " + label, rest}, parts[1:]...)
+			}
+		} else {
+			// "person owns name": the label is followed by a constraint.
+			label, kind = head[0], ""
+			parts[0] = strings.TrimSpace(strings.TrimPrefix(parts[0], label))
+		}
+		def, ok := byLabel[label]
+		if !ok {
+			def = &schemaDef{label: label, head: label}
+			byLabel[label] = def
+			types = append(types, def)
+		}
+		if kind != "" {
+			// Keep the most annotated head of repeated definitions.
+			if def.kind == "" || len(parts[0]) > len(def.head) {
+				def.kind, def.head = kind, parts[0]
+			}
+			parts = parts[1:]
+		}
+		def.parts = append(def.parts, parts...)
+	}
+
+	slices.SortStableFunc(types, func(a, b *schemaDef) int {
+		if c := kindRank(a.kind) - kindRank(b.kind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.label, b.label)
+	})
+
+	var b strings.Builder
+	b.WriteString("define")
+	for i, def := range types {
+		if i == 0 || def.kind != types[i-1].kind {
+			b.WriteByte('\n')
+		}
+		b.WriteString("\n  ")
+		if def.kind == "fun" {
+			b.WriteString(def.head)
+			b.WriteString("\n    ")
+			b.WriteString(strings.Join(def.body, ";\n    "))
+			b.WriteByte(';')
+			continue
+		}
+		parts := def.sortedParts()
+		head := def.head
+		if len(parts) > 0 && strings.HasPrefix(parts[0], "sub ") {
+			// tqlgen expects "entity employee sub person @abstract".
+			kindLabel := def.label
+			if def.kind != "" {
+				kindLabel = def.kind + " " + def.label
+			}
+			head = kindLabel + " " + parts[0] + strings.TrimPrefix(head, kindLabel)
+			parts = parts[1:]
+		}
+		b.WriteString(head)
+		if head == def.label && len(parts) > 0 {
+			// A type only extended here cannot be followed by a comma.
+			b.WriteByte(' ')
+			b.WriteString(parts[0])
+			parts = parts[1:]
+		}
+		for _, part := range parts {
+			b.WriteString(",\n    ")
+			b.WriteString(part)
+		}
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+type schemaDef struct {
+	kind  string // "" for a type only extended, never defined
+	label string
+	head  string   // "entity person @abstract", or the function signature
+	parts []string // constraints after the head
+	body  []string // function body statements
+}
+
+// sortedParts returns the constraints without duplicates, "sub" first.
+func (d *schemaDef) sortedParts() []string {
+	parts := slices.DeleteFunc(slices.Clone(d.parts), func(p string) bool { return p == "" })
+	slices.SortFunc(parts, func(a, b string) int {
+		aSub, bSub := strings.HasPrefix(a, "sub "), strings.HasPrefix(b, "sub ")
+		if aSub != bSub {
+			if aSub {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	return slices.Compact(parts)
+}
+
+// isTypeExtension reports whether head starts a statement like
+// "person owns name" that adds constraints to a type defined elsewhere.
+func isTypeExtension(head []string) bool {
+	if len(head) < 2 || strings.HasPrefix(head[0], "$") {
+		return false
+	}
+	switch head[1] {
+	case "owns", "plays", "relates", "sub", "value", "alias":
+		return true
+	}
+	return false
+}
+
+func kindRank(kind string) int {
+	if r, ok := schemaKindOrder[kind]; ok {
+		return r
+	}
+	return len(schemaKindOrder)
+}
+
+// schemaFunName returns the name of the function declared by stmt.
+func schemaFunName(stmt string) string {
+	name := strings.TrimSpace(strings.TrimPrefix(stmt, "fun"))
+	if i := strings.IndexAny(name, "( "); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// splitSchemaStatements returns the statements of the define blocks in
+// schema with comments removed, whitespace collapsed and the define keywords
+// and terminating semicolons stripped.
+func splitSchemaStatements(schema string) []string {
+	var stmts []string
+	for _, stmt := range splitTopLevel(collapseTQLSpace(schema), ';') {
+		for {
+			rest, ok := strings.CutPrefix(stmt, "define")
+			if !ok || (rest != "" && rest[0] != ' ') {
+				break
+			}
+			stmt = strings.TrimSpace(rest)
+		}
+		if stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// collapseTQLSpace drops comments and replaces each run of whitespace outside
+// string literals with a single space.
+func collapseTQLSpace(query string) string {
+	var b strings.Builder
+	space := false
+	for _, tok := range tokenizeTQL(query) {
+		switch {
+		case tok == "":
+			space = true
+		case tok[0] == '#':
+			space = true
+		default:
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteString(tok)
+		}
+	}
+	return b.String()
+}
+
+// splitTopLevel splits s at sep outside string literals, parentheses,
+// brackets and braces, trimming the pieces and dropping empty ones.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth = max(depth-1, 0)
+		case c == sep && depth == 0:
+			if p := strings.TrimSpace(s[start:i]); p != "" {
+				parts = append(parts, p)
+			}
+			start = i + 1
+		}
+	}
+	if p := strings.TrimSpace(s[start:]); p != "" {
+		parts = append(parts, p)
+	}
+	return parts
+}
@@ -0,0 +1,96 @@
+package gotype
+
+import "testing"
+
+const unsortedSchema = `define
+# people
+entity person @abstract, owns name @key, plays employment:employee, owns email;
+attribute name, value string;
+relation employment, relates employee, relates employer, owns start-date;
+attribute email value string @regex("^.*;  x$");
+entity company,
+  owns name;
+attribute start-date, value datetime;
+entity employee, sub person, owns badge;
+person owns age;
+attribute age, value integer;
+fun adults() -> { person }:
+  match $p isa person, has age $a; $a >= 18;
+  return { $p };
+define
+attribute badge, value string;
+`
+
+const normalizedSchema = `define
+
+  attribute age,
+    value integer;
+  attribute badge,
+    value string;
+  attribute email,
+    value string @regex("^.*;  x$");
+  attribute name,
+    value string;
+  attribute start-date,
+    value datetime;
+
+  entity company,
+    owns name;
+  entity employee sub person,
+    owns badge;
+  entity person @abstract,
+    owns age,
+    owns email,
+    owns name @key,
+    plays employment:employee;
+
+  relation employment,
+    owns start-date,
+    relates employee,
+    relates employer;
+
+  fun adults() -> { person }:
+    match $p isa person, has age $a;
+    $a >= 18;
+    return { $p };`
+
+func TestNormalizeSchema(t *testing.T) {
+	got := NormalizeSchema(unsortedSchema)
+	if got != normalizedSchema {
+		t.Errorf("NormalizeSchema:\n%s\nwant:\n%s", got, normalizedSchema)
+	}
+}
+
+func TestNormalizeSchema_Idempotent(t *testing.T) {
+	once := NormalizeSchema(unsortedSchema)
+	if twice := NormalizeSchema(once); twice != once {
+		t.Errorf("second pass changed the schema:\n%s", twice)
+	}
+}
+
+func TestNormalizeSchema_Parses(t *testing.T) {
+	schema, err := IntrospectSchemaFromString(NormalizeSchema(unsortedSchema))
+	if err != nil {
+		t.Fatalf("parse normalized schema: %v", err)
+	}
+	if len(schema.Entities) != 3 || len(schema.Relations) != 1 || len(schema.Attributes) != 5 {
+		t.Errorf("got %d entities, %d relations, %d attributes",
+			len(schema.Entities), len(schema.Relations), len(schema.Attributes))
+	}
+}
+
+func TestNormalizeSchema_MergesDuplicates(t *testing.T) {
+	got := NormalizeSchema("define entity a, owns x; entity a @abstract, owns x, owns y; a plays r:p;")
+	want := "define\n\n  entity a @abstract,\n    owns x,\n    owns y,\n    plays r:p;"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNormalizeSchema_ExtensionOnly(t *testing.T) {
+	got := NormalizeSchema("define person owns name; person plays r:p;")
+	want := "define\n\n  person owns name,\n    plays r:p;"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
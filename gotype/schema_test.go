@@ -177,6 +177,28 @@ func TestGenerateSchema_Multiple(t *testing.T) {
 	}
 }
 
+func TestGenerateSchema_PlaysUsesRegisteredPlayerName(t *testing.T) {
+	reg := NewRegistry()
+	if err := RegisterIn[TestPerson](reg, WithTypeName("employee-person")); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterIn[TestCompany](reg); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterIn[TestEmployment](reg); err != nil {
+		t.Fatal(err)
+	}
+
+	schema := reg.GenerateSchema()
+	want := "entity employee-person,\n    plays test-employment:employee,"
+	if !strings.Contains(schema, want) {
+		t.Errorf("schema missing plays clause on the renamed player:\n%s", schema)
+	}
+	if strings.Contains(schema, "test-person") {
+		t.Errorf("schema still refers to the Go-derived name:\n%s", schema)
+	}
+}
+
 func TestFormatCardAnnotation(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,90 @@
+package gotype
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// TQLMigrationSeparator separates queries within a migration file read by
+// LoadTQLMigrations. It must stand alone on its line.
+const TQLMigrationSeparator = "---"
+
+// LoadTQLMigrations builds sequential migrations from the .tql files in dir
+// of fsys, such as an embed.FS or os.DirFS. Each migration is a pair of files
+// named <name>.up.tql and, optionally, <name>.down.tql, where <name> is the
+// migration name (e.g. "20240101_create_users"). Other files are ignored.
+//
+// A file holds one TypeQL query, or several separated by lines containing
+// only "---". Each query runs in its own schema or write transaction, as with
+// TQLMigration, and the checksums match those of an equivalent TQLMigration.
+func LoadTQLMigrations(fsys fs.FS, dir string) ([]SequentialMigration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+
+	up := make(map[string][]string)
+	down := make(map[string][]string)
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		file := e.Name()
+		var name string
+		var dst map[string][]string
+		switch {
+		case strings.HasSuffix(file, ".up.tql"):
+			name, dst = strings.TrimSuffix(file, ".up.tql"), up
+			names = append(names, name)
+		case strings.HasSuffix(file, ".down.tql"):
+			name, dst = strings.TrimSuffix(file, ".down.tql"), down
+		default:
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("load migrations: %w", err)
+		}
+		queries := splitTQLMigration(string(data))
+		if len(queries) == 0 {
+			return nil, fmt.Errorf("load migrations: %s holds no query", file)
+		}
+		dst[name] = queries
+	}
+	for name := range down {
+		if _, ok := up[name]; !ok {
+			return nil, fmt.Errorf("load migrations: %s.down.tql has no matching %s.up.tql", name, name)
+		}
+	}
+
+	migrations := make([]SequentialMigration, len(names))
+	for i, name := range names {
+		migrations[i] = TQLMigration(name, up[name], down[name])
+	}
+	return migrations, nil
+}
+
+// splitTQLMigration splits a migration file at separator lines, dropping
+// blank queries.
+func splitTQLMigration(text string) []string {
+	var queries []string
+	var cur []string
+	flush := func() {
+		if q := strings.TrimSpace(strings.Join(cur, "\n")); q != "" {
+			queries = append(queries, q)
+		}
+		cur = cur[:0]
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		if strings.TrimSpace(line) == TQLMigrationSeparator {
+			flush()
+			continue
+		}
+		cur = append(cur, line)
+	}
+	flush()
+	return queries
+}
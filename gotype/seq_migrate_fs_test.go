@@ -0,0 +1,74 @@
+package gotype
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadTQLMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/002_seed.up.tql":        {Data: []byte("insert $p isa person, has name \"root\";\n")},
+		"migrations/001_people.up.tql":      {Data: []byte("define\nentity person, owns name;\nattribute name, value string;\n---\n\ninsert $p isa person, has name \"a\";\n---\n")},
+		"migrations/001_people.down.tql":    {Data: []byte("undefine person;\n")},
+		"migrations/README.md":              {Data: []byte("not a migration")},
+		"migrations/nested/003_skip.up.tql": {Data: []byte("define entity x;")},
+		"other/999_elsewhere.up.tql":        {Data: []byte("define entity y;")},
+	}
+
+	migrations, err := LoadTQLMigrations(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("LoadTQLMigrations: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("got %d migrations, want 2", len(migrations))
+	}
+
+	byName := map[string]SequentialMigration{}
+	for _, m := range migrations {
+		byName[m.Name] = m
+	}
+	people := byName["001_people"]
+	wantUp := []string{
+		"define\nentity person, owns name;\nattribute name, value string;",
+		"insert $p isa person, has name \"a\";",
+	}
+	if people.Statements == nil || !reflect.DeepEqual(people.Statements.Up, wantUp) {
+		t.Errorf("001_people up = %#v", people.Statements)
+	}
+	if people.Down == nil || !reflect.DeepEqual(people.Statements.Down, []string{"undefine person;"}) {
+		t.Errorf("001_people down = %#v", people.Statements.Down)
+	}
+	if seed := byName["002_seed"]; seed.Up == nil || seed.Down != nil {
+		t.Errorf("002_seed = %+v", seed)
+	}
+
+	// Checksums match the equivalent TQLMigration.
+	if got, want := MigrationChecksum(people), MigrationChecksum(TQLMigration("001_people", wantUp, []string{"undefine person;"})); got != want {
+		t.Errorf("checksum = %s, want %s", got, want)
+	}
+	if issues := ValidateSequentialMigrations(migrations); hasValidationErrors(issues) {
+		t.Errorf("validation issues: %v", issues)
+	}
+}
+
+func TestLoadTQLMigrations_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"orphan down", fstest.MapFS{"m/001_x.down.tql": {Data: []byte("undefine x;")}}, "001_x.down.tql has no matching 001_x.up.tql"},
+		{"empty file", fstest.MapFS{"m/001_x.up.tql": {Data: []byte("\n---\n")}}, "001_x.up.tql holds no query"},
+		{"missing dir", fstest.MapFS{}, "load migrations:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTQLMigrations(tt.fsys, "m")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -6,13 +6,18 @@ package gotype
 import (
 	"context"
 	"fmt"
+	"iter"
 	"log"
+	"log/slog"
 	"runtime"
 	"sync"
 	"sync/atomic"
 )
 
 // TransactionType represents the intended mode of operation for a TypeDB transaction.
+// Each type allows the operations of the ones before it: reads run in any
+// transaction, data writes in write and schema transactions, and schema
+// changes only in schema transactions.
 type TransactionType int
 
 const (
@@ -40,6 +45,37 @@ type Tx interface {
 	IsOpen() bool
 }
 
+// StreamingTx is implemented by transactions that can deliver result rows as
+// the server produces them instead of as one materialized slice. Query.Iter
+// uses it when available so only one row is held in memory at a time.
+type StreamingTx interface {
+	Tx
+	// QueryStream executes query and yields its rows in order. Iteration stops
+	// at the first error, which is yielded with a nil row.
+	QueryStream(ctx context.Context, query string) iter.Seq2[map[string]any, error]
+}
+
+// queryRows yields the rows of query, streaming them when tx supports it and
+// otherwise ranging over the materialized result.
+func queryRows(ctx context.Context, tx Tx, query string) iter.Seq2[map[string]any, error] {
+	if stx, ok := tx.(StreamingTx); ok {
+		return stx.QueryStream(ctx, query)
+	}
+	return func(yield func(map[string]any, error) bool) {
+		rows, err := tx.QueryWithContext(ctx, query)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for i, row := range rows {
+			rows[i] = nil // release rows already handed out
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
+
 // Conn is the interface for a TypeDB connection.
 type Conn interface {
 	// Transaction opens a new transaction on the specified database.
@@ -110,14 +146,62 @@ func EnsureDatabase(ctx context.Context, conn Conn, name string) (bool, error) {
 // Database represents a high-level handle to a specific TypeDB database,
 // providing convenient methods for transaction management and query execution.
 type Database struct {
-	conn    Conn
-	dbName  string
-	ownConn bool
+	conn      Conn
+	dbName    string
+	ownConn   bool
+	registry  *Registry
+	maxRows   int
+	stats     *dbStats
+	debug     *debugLogger
+	logger    *slog.Logger
+	ctxFields ContextFieldsFunc
+	cache     Cache
 }
 
 // NewDatabase creates a new Database handle bound to a specific database name.
-func NewDatabase(conn Conn, dbName string) *Database {
-	return &Database{conn: conn, dbName: dbName}
+func NewDatabase(conn Conn, dbName string, opts ...DatabaseOption) *Database {
+	db := &Database{conn: conn, dbName: dbName, stats: &dbStats{}}
+	for _, o := range opts {
+		o(db)
+	}
+	return db
+}
+
+// WithRegistry returns a copy of the Database handle that resolves models in
+// r instead of the default registry. The copy shares the connection but does
+// not own it, so closing it leaves the connection open.
+func (db *Database) WithRegistry(r *Registry) *Database {
+	cp := *db
+	cp.ownConn = false
+	cp.registry = r
+	return &cp
+}
+
+// WithMaxRows returns a copy of the Database handle whose managers refuse to
+// hydrate reads returning more than n rows, failing with ErrTooManyRows
+// instead. It guards services against accidental unbounded All() calls on
+// large types. n <= 0 removes the limit. Query.MaxRows overrides it per query.
+// Like WithRegistry, the copy shares the connection but does not own it.
+func (db *Database) WithMaxRows(n int) *Database {
+	cp := *db
+	cp.ownConn = false
+	cp.maxRows = max(n, 0)
+	return &cp
+}
+
+// MaxRows returns the row limit set with WithMaxRows, or 0 if reads are
+// unbounded.
+func (db *Database) MaxRows() int {
+	return db.maxRows
+}
+
+// Registry returns the model registry used by managers and migrations built
+// on this Database.
+func (db *Database) Registry() *Registry {
+	if db.registry != nil {
+		return db.registry
+	}
+	return globalRegistry
 }
 
 // Close closes the underlying connection if it is owned by this Database handle.
@@ -147,7 +231,7 @@ func (db *Database) Schema(ctx context.Context) (string, error) {
 
 // Transaction opens a new transaction of the specified type.
 func (db *Database) Transaction(txType TransactionType) (Tx, error) {
-	return db.conn.Transaction(db.dbName, int(txType))
+	return db.openTransaction(context.Background(), txType)
 }
 
 // TransactionContext opens a new transaction of the specified type and lets
@@ -160,10 +244,17 @@ func (db *Database) TransactionContext(ctx context.Context, txType TransactionTy
 }
 
 func (db *Database) openTransaction(ctx context.Context, txType TransactionType) (Tx, error) {
+	var tx Tx
+	var err error
 	if connWithContext, ok := db.conn.(contextTransactionConn); ok {
-		return connWithContext.TransactionContext(ctx, db.dbName, int(txType))
+		tx, err = connWithContext.TransactionContext(ctx, db.dbName, int(txType))
+	} else {
+		tx, err = db.conn.Transaction(db.dbName, int(txType))
 	}
-	return db.conn.Transaction(db.dbName, int(txType))
+	if err != nil {
+		return nil, err
+	}
+	return db.instrumentTx(ctx, tx, txType), nil
 }
 
 // ExecuteWrite executes a query in a new write transaction and commits it.
@@ -262,6 +353,11 @@ func (tc *TransactionContext) Tx() Tx {
 	return tc.tx
 }
 
+// Type returns the type the transaction was opened with.
+func (tc *TransactionContext) Type() TransactionType {
+	return tc.txType
+}
+
 func (tc *TransactionContext) markDone() {
 	tc.done.Do(func() {
 		tc.closed.Store(true)
@@ -0,0 +1,218 @@
+package gotype
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// CacheKey identifies an entry of a shared Cache. IID is set for GetByIID
+// results and QueryHash for other reads; exactly one of them is non-empty.
+type CacheKey struct {
+	TypeName  string
+	IID       string
+	QueryHash string
+}
+
+// Cache is a second-level cache shared by every Manager of a Database (see
+// WithSharedCache). It stores raw result rows rather than hydrated
+// instances, so an implementation backed by Redis or memcached only has to
+// serialize []map[string]any, for example as JSON.
+//
+// Implementations must be safe for concurrent use. Errors are the
+// implementation's to handle: a failed Get is a miss and a failed Delete
+// should expire the entry some other way.
+type Cache interface {
+	// Get returns the rows cached under key.
+	Get(ctx context.Context, key CacheKey) ([]map[string]any, bool)
+	// Set caches rows under key. Callers must not modify rows afterwards.
+	Set(ctx context.Context, key CacheKey, rows []map[string]any)
+	// Delete drops the entry under key.
+	Delete(ctx context.Context, key CacheKey)
+	// DeleteType drops every entry whose key has typeName.
+	DeleteType(ctx context.Context, typeName string)
+	// DeleteQueries drops every entry keyed by QueryHash, of any type.
+	DeleteQueries(ctx context.Context)
+}
+
+// WithSharedCache makes the Database's managers serve reads from c and
+// invalidate it on writes. GetByIID is cached by IID; Get, All,
+// GetWithRoles, Query.Execute and Query.Count are cached by a hash of their
+// query. Managers bound to a transaction neither read nor fill the cache.
+//
+// Any write through a Manager drops all query entries, since a filter or
+// role-player fetch on one type can depend on instances of another. Writes
+// to known instances drop their IID entries, and Put and Query-level writes
+// drop the IID entries of the whole type. Either way the entries of the
+// registered supertypes and subtypes go too, since an instance is cached
+// under the type of each manager that read it. Writes made
+// with ExecuteWrite, through a TransactionContext before it commits, or by
+// other processes are not seen; invalidate those through Database.Cache, or
+// use a cache with a TTL. Use one Cache per database.
+func WithSharedCache(c Cache) DatabaseOption {
+	return func(db *Database) { db.cache = c }
+}
+
+// Cache returns the cache set with WithSharedCache, or nil.
+func (db *Database) Cache() Cache {
+	return db.cache
+}
+
+// withoutCache returns a copy of db that bypasses the shared cache, for
+// readers that must see the database itself, such as Watch.
+func (db *Database) withoutCache() *Database {
+	if db.cache == nil {
+		return db
+	}
+	cp := *db
+	cp.ownConn = false
+	cp.cache = nil
+	return &cp
+}
+
+// QueryHash returns the QueryHash under which the result of query is cached.
+func QueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// sharedCache returns the cache reads of m may use, or nil.
+func (m *Manager[T]) sharedCache() Cache {
+	if m.readTransaction() != nil {
+		return nil
+	}
+	return m.db.cache
+}
+
+// cachedRead runs query with read, going through the shared cache when m
+// may use it.
+func (m *Manager[T]) cachedRead(ctx context.Context, query string, read func(context.Context, string) ([]map[string]any, error)) ([]map[string]any, error) {
+	c := m.sharedCache()
+	if c == nil {
+		return read(ctx, query)
+	}
+	key := CacheKey{TypeName: m.info.TypeName, QueryHash: QueryHash(query)}
+	if rows, ok := c.Get(ctx, key); ok {
+		return rows, nil
+	}
+	rows, err := read(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(ctx, key, rows)
+	return rows, nil
+}
+
+// byIIDCache returns the cache GetByIID may use: the manager's own cache
+// (WithCache) or else the shared one, or nil.
+func (m *Manager[T]) byIIDCache() Cache {
+	if m.ownFieldsOnly || m.readTransaction() != nil {
+		return nil
+	}
+	if m.cache != nil {
+		return m.cache
+	}
+	return m.db.cache
+}
+
+// caches returns the caches writes through m invalidate.
+func (m *Manager[T]) caches() []Cache {
+	var cs []Cache
+	for _, c := range []Cache{m.cache, m.db.cache} {
+		if c != nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// invalidateCache drops the query entries and the IID entries of iids.
+// An instance is cached under the type of each manager that read it, so the
+// entries of T's registered supertypes and subtypes are dropped too.
+// It runs after the write, so it ignores ctx's cancellation.
+func (m *Manager[T]) invalidateCache(ctx context.Context, iids ...string) {
+	cs := m.caches()
+	if len(cs) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	var names []string
+	for _, iid := range iids {
+		if iid != "" {
+			names = append(m.supertypeNames(), m.subtypeNames()...)
+			break
+		}
+	}
+	for _, c := range cs {
+		for _, iid := range iids {
+			if iid == "" {
+				continue
+			}
+			for _, name := range names {
+				c.Delete(ctx, CacheKey{TypeName: name, IID: iid})
+			}
+		}
+		c.DeleteQueries(ctx)
+	}
+}
+
+// invalidateCacheType drops the query entries and every entry of T and its
+// registered supertypes and subtypes, for writes whose IIDs are not known up
+// front.
+func (m *Manager[T]) invalidateCacheType(ctx context.Context) {
+	cs := m.caches()
+	if len(cs) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	names := append(m.supertypeNames(), m.subtypeNames()...)
+	for _, c := range cs {
+		for _, name := range names {
+			c.DeleteType(ctx, name)
+		}
+		c.DeleteQueries(ctx)
+	}
+}
+
+// subtypeNames returns the name of T followed by those of its registered
+// subtypes, transitively.
+func (m *Manager[T]) subtypeNames() []string {
+	names := []string{m.info.TypeName}
+	for i := 0; i < len(names); i++ {
+		for _, sub := range m.db.Registry().SubtypesOf(names[i]) {
+			names = append(names, sub.TypeName)
+		}
+	}
+	return names
+}
+
+// supertypeNames returns the names of T's registered supertypes, nearest
+// first.
+func (m *Manager[T]) supertypeNames() []string {
+	var names []string
+	seen := map[string]bool{m.info.TypeName: true}
+	for super := m.info.Supertype; super != "" && !seen[super]; {
+		seen[super] = true
+		info, ok := m.db.Registry().Lookup(super)
+		if !ok {
+			break
+		}
+		names = append(names, super)
+		super = info.Supertype
+	}
+	return names
+}
+
+// cachedIIDs returns the IIDs of instances, for invalidateCache.
+func (m *Manager[T]) cachedIIDs(instances []*T) []string {
+	if m.cache == nil && m.db.cache == nil {
+		return nil
+	}
+	iids := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		if inst != nil {
+			iids = append(iids, getIIDOfInfo(inst, m.info))
+		}
+	}
+	return iids
+}
@@ -0,0 +1,187 @@
+package gotype
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	rows := []map[string]any{{"name": "a"}}
+	byIID := CacheKey{TypeName: "person", IID: "0x1"}
+	byQuery := CacheKey{TypeName: "person", QueryHash: QueryHash("match $p isa person;")}
+	other := CacheKey{TypeName: "company", IID: "0x2"}
+	for _, k := range []CacheKey{byIID, byQuery, other} {
+		c.Set(ctx, k, rows)
+	}
+	if got, ok := c.Get(ctx, byQuery); !ok || got[0]["name"] != "a" {
+		t.Fatalf("Get = %v, %v", got, ok)
+	}
+
+	c.DeleteQueries(ctx)
+	if _, ok := c.Get(ctx, byQuery); ok {
+		t.Error("query entry survived DeleteQueries")
+	}
+	if _, ok := c.Get(ctx, byIID); !ok {
+		t.Error("IID entry dropped by DeleteQueries")
+	}
+	c.DeleteType(ctx, "person")
+	if _, ok := c.Get(ctx, byIID); ok {
+		t.Error("person entry survived DeleteType")
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len = %d, want only the company entry", c.Len())
+	}
+	c.Delete(ctx, other)
+	if c.Len() != 0 {
+		t.Errorf("Len = %d after Delete", c.Len())
+	}
+}
+
+func TestMemoryCache_TTL(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fixTimeNow(t, now)
+	ctx := context.Background()
+	c := NewMemoryCache(WithCacheTTL(time.Minute))
+	key := CacheKey{TypeName: "person", IID: "0x1"}
+	c.Set(ctx, key, nil)
+
+	timeNow = func() time.Time { return now.Add(2 * time.Minute) }
+	if _, ok := c.Get(ctx, key); ok {
+		t.Error("expired entry served")
+	}
+}
+
+func TestSharedCache_ReadsAndInvalidation(t *testing.T) {
+	registerTestTypes(t)
+	conn := &mockConn{txs: []*mockTx{
+		{responses: personRow("0x1", "alice")}, // GetByIID
+		{responses: personRow("0x1", "alice")}, // Query.Execute
+		{},                                     // Update
+		{responses: personRow("0x1", "alicia")},
+		{responses: personRow("0x1", "alicia")},
+	}}
+	cache := NewMemoryCache()
+	db := NewDatabase(conn, "test_db", WithSharedCache(cache))
+	ctx := context.Background()
+
+	// Two managers share the cache.
+	readA, readB := MustNewManager[testPerson](db), MustNewManager[testPerson](db)
+	for _, mgr := range []*Manager[testPerson]{readA, readB} {
+		if p, err := mgr.GetByIID(ctx, "0x1"); err != nil || p.Name != "alice" {
+			t.Fatalf("GetByIID = %+v, %v", p, err)
+		}
+		if ps, err := mgr.Query().Filter(Eq("name", "alice")).Execute(ctx); err != nil || len(ps) != 1 {
+			t.Fatalf("Execute = %v, %v", ps, err)
+		}
+	}
+	if conn.idx != 2 {
+		t.Fatalf("expected 2 read transactions, got %d", conn.idx)
+	}
+
+	p, _ := readA.GetByIID(ctx, "0x1")
+	if err := readB.Update(ctx, p); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Update left %d entries", cache.Len())
+	}
+	if p, _ := readA.GetByIID(ctx, "0x1"); p.Name != "alicia" {
+		t.Errorf("GetByIID after Update = %q", p.Name)
+	}
+	if ps, _ := readA.Query().Filter(Eq("name", "alice")).Execute(ctx); len(ps) != 1 || ps[0].Name != "alicia" {
+		t.Errorf("Execute after Update = %+v", ps)
+	}
+	if conn.idx != 5 {
+		t.Errorf("expected 5 transactions, got %d", conn.idx)
+	}
+}
+
+func TestSharedCache_QueryWriteDropsType(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	cache := NewMemoryCache()
+	conn := &mockConn{txs: []*mockTx{{responses: [][]map[string]any{{{"count": 1}}}}}}
+	mgr := MustNewManager[testPerson](NewDatabase(conn, "test_db", WithSharedCache(cache)))
+	person := CacheKey{TypeName: mgr.Info().TypeName, IID: "0x1"}
+	company := CacheKey{TypeName: "company", IID: "0x2"}
+	cache.Set(ctx, person, nil)
+	cache.Set(ctx, company, nil)
+
+	if _, err := mgr.Query().Filter(Eq("name", "alice")).Delete(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get(ctx, person); ok {
+		t.Error("person entry survived Query.Delete")
+	}
+	if _, ok := cache.Get(ctx, company); !ok {
+		t.Error("Query.Delete dropped another type's IID entry")
+	}
+}
+
+func TestSharedCache_SubtypeWriteDropsSupertypeEntries(t *testing.T) {
+	reg := NewRegistry()
+	MustRegisterIn[testAnimal](reg)
+	MustRegisterIn[testDog](reg)
+	dogInfo, _ := reg.Lookup("test-dog")
+	dogInfo.Supertype = "test-animal"
+
+	ctx := context.Background()
+	cache := NewMemoryCache()
+	conn := &mockConn{txs: []*mockTx{
+		{responses: [][]map[string]any{{{"_iid": "0x1", "name": "Rex"}}}},
+		{},
+		{},
+		{responses: [][]map[string]any{{{"count": 1}}}},
+	}}
+	db := NewDatabase(conn, "test_db", WithSharedCache(cache)).WithRegistry(reg)
+	animals := MustNewManager[testAnimal](db)
+	dogs := MustNewManager[testDog](db)
+
+	// Read through the supertype, delete through the subtype.
+	if a, err := animals.GetByIID(ctx, "0x1"); err != nil || a == nil {
+		t.Fatalf("GetByIID = %+v, %v", a, err)
+	}
+	rex := &testDog{Name: "Rex"}
+	rex.SetIID("0x1")
+	if err := dogs.Delete(ctx, rex); err != nil {
+		t.Fatal(err)
+	}
+	if a, err := animals.GetByIID(ctx, "0x1"); err != nil || a != nil {
+		t.Errorf("GetByIID after the subtype delete = %+v, %v", a, err)
+	}
+	if conn.idx != 3 {
+		t.Errorf("expected 3 transactions, got %d", conn.idx)
+	}
+
+	animal := CacheKey{TypeName: "test-animal", IID: "0x2"}
+	cache.Set(ctx, animal, nil)
+	if _, err := dogs.Query().Delete(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get(ctx, animal); ok {
+		t.Error("supertype entry survived a subtype Query.Delete")
+	}
+}
+
+func TestSharedCache_BypassedInTransaction(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	cache := NewMemoryCache()
+	tx := &mockTx{responses: personRow("0x1", "alice")}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db", WithSharedCache(cache))
+	tc, err := db.Begin(ReadTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	if _, err := MustNewManagerWithTx[testPerson](tc).GetByIID(ctx, "0x1"); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("transaction read filled the cache")
+	}
+}
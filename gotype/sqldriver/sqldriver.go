@@ -0,0 +1,314 @@
+// Package sqldriver exposes a gotype Database through database/sql, so
+// reporting and BI tools that expect a *sql.DB can read from TypeDB.
+//
+// The driver is read-only. Every query runs in its own read transaction and
+// must return rows, typically a fetch or a reduce:
+//
+//	sqlDB := sqldriver.OpenDB(db)
+//	rows, err := sqlDB.QueryContext(ctx, `match $p isa person, has age $a; $a > ?;
+//	fetch { "name": $p.name, "age": $a };`, 30)
+//
+// Each top-level key of the fetched documents is a column; columns are
+// sorted by name and a row lacking a key reads as NULL. Values keep the type
+// the driver fetched them as, so datetimes usually arrive as text; nested
+// documents and lists are returned as JSON text. A ? outside string literals
+// and comments is replaced by the next argument formatted as a TypeQL literal.
+//
+// Tools that only accept a driver name and a DSN can use sql.Open("gotype",
+// dsn) after RegisterDatabase(dsn, db).
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+)
+
+// DriverName is the name the driver is registered under with database/sql.
+const DriverName = "gotype"
+
+// ErrReadOnly is returned for Exec calls and non-read-only transactions.
+var ErrReadOnly = errors.New("sqldriver: connection is read-only")
+
+var (
+	databasesMu sync.RWMutex
+	databases   = map[string]*gotype.Database{}
+)
+
+func init() {
+	sql.Register(DriverName, Driver{})
+}
+
+// RegisterDatabase makes db available to sql.Open(DriverName, dsn).
+func RegisterDatabase(dsn string, db *gotype.Database) {
+	databasesMu.Lock()
+	defer databasesMu.Unlock()
+	databases[dsn] = db
+}
+
+// Driver opens connections to databases added with RegisterDatabase.
+type Driver struct{}
+
+// Open returns a connection to the database registered under dsn.
+func (Driver) Open(dsn string) (driver.Conn, error) {
+	c, err := Driver{}.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(context.Background())
+}
+
+// OpenConnector returns a Connector for the database registered under dsn.
+func (Driver) OpenConnector(dsn string) (driver.Connector, error) {
+	databasesMu.RLock()
+	db, ok := databases[dsn]
+	databasesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("sqldriver: no database registered for %q", dsn)
+	}
+	return NewConnector(db), nil
+}
+
+// NewConnector returns a Connector reading from db, for sql.OpenDB.
+func NewConnector(db *gotype.Database) driver.Connector {
+	return &connector{db: db}
+}
+
+// OpenDB is shorthand for sql.OpenDB(NewConnector(db)).
+func OpenDB(db *gotype.Database) *sql.DB {
+	return sql.OpenDB(NewConnector(db))
+}
+
+type connector struct {
+	db *gotype.Database
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+func (c *connector) Driver() driver.Driver { return Driver{} }
+
+// conn is a database/sql connection. It holds no server resources: the
+// Database owns the underlying connection and each query opens its own
+// transaction.
+type conn struct {
+	db *gotype.Database
+}
+
+var (
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{ReadOnly: true})
+}
+
+// BeginTx accepts read-only transactions only. Queries in the transaction
+// still run in separate TypeDB read transactions.
+func (c *conn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if !opts.ReadOnly {
+		return nil, ErrReadOnly
+	}
+	return tx{}, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !c.db.GetConn().IsOpen() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+// CheckNamedValue accepts any positional argument; FormatValue decides how
+// it is written.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nv.Name != "" {
+		return fmt.Errorf("sqldriver: named argument %q is not supported", nv.Name)
+	}
+	return nil
+}
+
+func (c *conn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return nil, ErrReadOnly
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query, err := bind(query, args)
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.db.ExecuteRead(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(results), nil
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return s.QueryContext(context.Background(), named)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+// bind replaces each ? outside string literals and comments with the next
+// argument.
+func bind(query string, args []driver.NamedValue) (string, error) {
+	if len(args) == 0 {
+		return query, nil
+	}
+	var b strings.Builder
+	next := 0
+	var quote byte
+	comment := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case comment:
+			comment = c != '\n'
+		case quote != 0:
+			if c == '\\' && i+1 < len(query) {
+				b.WriteByte(c)
+				i++
+				c = query[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			comment = true
+		case c == '?':
+			if next == len(args) {
+				return "", fmt.Errorf("sqldriver: query has more placeholders than the %d arguments", len(args))
+			}
+			b.WriteString(gotype.FormatValue(args[next].Value))
+			next++
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if next != len(args) {
+		return "", fmt.Errorf("sqldriver: %d arguments for %d placeholders", len(args), next)
+	}
+	return b.String(), nil
+}
+
+type rows struct {
+	columns []string
+	results []map[string]any
+	pos     int
+}
+
+func newRows(results []map[string]any) *rows {
+	var columns []string
+	for _, row := range results {
+		for key := range row {
+			if !slices.Contains(columns, key) {
+				columns = append(columns, key)
+			}
+		}
+	}
+	slices.Sort(columns)
+	return &rows{columns: columns, results: results}
+}
+
+func (r *rows) Columns() []string { return r.columns }
+
+func (r *rows) Close() error {
+	r.pos = len(r.results)
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.results) {
+		return io.EOF
+	}
+	row := r.results[r.pos]
+	r.pos++
+	for i, col := range r.columns {
+		v, err := columnValue(row[col])
+		if err != nil {
+			return fmt.Errorf("sqldriver: column %s: %w", col, err)
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// columnValue converts a fetched value to a driver.Value. Values wrapped as
+// {"value": v} are unwrapped; other documents and lists become JSON.
+func columnValue(v any) (driver.Value, error) {
+	if m, ok := v.(map[string]any); ok {
+		if inner, ok := m["value"]; ok {
+			v = inner
+		}
+	}
+	switch v := v.(type) {
+	case nil, int64, float64, bool, string, []byte, time.Time:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case float32:
+		return float64(v), nil
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(raw), nil
+	}
+}
@@ -0,0 +1,191 @@
+package sqldriver_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CaliLuke/go-typeql/gotype"
+	"github.com/CaliLuke/go-typeql/gotype/gotypetest"
+	"github.com/CaliLuke/go-typeql/gotype/sqldriver"
+)
+
+type person struct {
+	gotype.BaseEntity
+	Name     string    `typedb:"name,key"`
+	Age      *int      `typedb:"age,card=0..1"`
+	JoinedAt time.Time `typedb:"joined-at"`
+	Tags     []string  `typedb:"tag,card=0.."`
+}
+
+func newTestDB(t *testing.T) *gotype.Database {
+	t.Helper()
+	ctx := context.Background()
+	reg := gotype.NewRegistry()
+	if err := gotype.RegisterIn[person](reg); err != nil {
+		t.Fatal(err)
+	}
+	db := gotype.NewDatabase(gotypetest.NewFakeDB(), "test").WithRegistry(reg)
+	if err := db.ExecuteSchema(ctx, reg.GenerateSchema()); err != nil {
+		t.Fatalf("ExecuteSchema: %v", err)
+	}
+	mgr := gotype.MustNewManager[person](db)
+	for i, name := range []string{"Alice", "Bob", "Carol"} {
+		p := &person{Name: name, JoinedAt: time.Date(2020+i, 1, 1, 0, 0, 0, 0, time.UTC), Tags: []string{"x"}}
+		if name != "Carol" {
+			age := 30 + 10*i
+			p.Age = &age
+		}
+		if err := mgr.Insert(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestQuery(t *testing.T) {
+	sqlDB := sqldriver.OpenDB(newTestDB(t))
+	defer sqlDB.Close()
+
+	rows, err := sqlDB.Query(`match $p isa person, has name $n; sort $n;
+fetch { "name": $n, "age": $p.age, "joined": $p.joined-at, "tags": [ $p.tag ] };`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(cols, ",") != "age,joined,name,tags" {
+		t.Fatalf("columns = %v", cols)
+	}
+	var got []string
+	for rows.Next() {
+		var (
+			age    sql.NullInt64
+			joined string
+			name   string
+			tags   string
+		)
+		if err := rows.Scan(&age, &joined, &name, &tags); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, name)
+		if name == "Carol" && age.Valid {
+			t.Errorf("Carol has age %d, want NULL", age.Int64)
+		}
+		if name == "Bob" && (age.Int64 != 40 || !strings.HasPrefix(joined, "2021-01-01")) {
+			t.Errorf("Bob = %d, %v", age.Int64, joined)
+		}
+		if tags != `["x"]` {
+			t.Errorf("tags = %q, want JSON list", tags)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "Alice,Bob,Carol" {
+		t.Errorf("names = %v", got)
+	}
+}
+
+func TestQueryArgs(t *testing.T) {
+	sqlDB := sqldriver.OpenDB(newTestDB(t))
+	defer sqlDB.Close()
+
+	// The ? inside the string literal and the comment are not placeholders.
+	const query = `match $p isa person, has age $a, has name $n; # age?
+$a >= ?; not { $n == "who?"; }; $n != ?;
+fetch { "name": $n };`
+	var name string
+	if err := sqlDB.QueryRow(query, 35, "Alice").Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "Bob" {
+		t.Errorf("name = %q, want Bob", name)
+	}
+
+	if _, err := sqlDB.Query(query, 35); err == nil {
+		t.Error("expected error for missing argument")
+	}
+	if _, err := sqlDB.Query(query, 35, "Alice", "extra"); err == nil {
+		t.Error("expected error for extra argument")
+	}
+	if _, err := sqlDB.Query(query, sql.Named("age", 35), "Alice"); err == nil {
+		t.Error("expected error for named argument")
+	}
+
+	stmt, err := sqlDB.Prepare(`match $p isa person, has name $n; $n == ?; fetch { "name": $n };`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	for _, want := range []string{"Alice", "Carol"} {
+		if err := stmt.QueryRow(want).Scan(&name); err != nil || name != want {
+			t.Errorf("prepared %s = %q, %v", want, name, err)
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	sqlDB := sqldriver.OpenDB(newTestDB(t))
+	defer sqlDB.Close()
+
+	var count int64
+	if err := sqlDB.QueryRow(`match $p isa person, has age $a; reduce $count = count($a);`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	sqlDB := sqldriver.OpenDB(newTestDB(t))
+	defer sqlDB.Close()
+	ctx := context.Background()
+
+	if _, err := sqlDB.Exec(`insert $p isa person, has name "Dave";`); !errors.Is(err, sqldriver.ErrReadOnly) {
+		t.Errorf("Exec err = %v, want ErrReadOnly", err)
+	}
+	if _, err := sqlDB.BeginTx(ctx, nil); !errors.Is(err, sqldriver.ErrReadOnly) {
+		t.Errorf("BeginTx err = %v, want ErrReadOnly", err)
+	}
+	tx, err := sqlDB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int64
+	if err := tx.QueryRow(`match $p isa person; reduce $n = count($p);`).Scan(&n); err != nil || n != 3 {
+		t.Errorf("count in tx = %d, %v", n, err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Error(err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+}
+
+func TestRegisterDatabase(t *testing.T) {
+	sqldriver.RegisterDatabase("reports", newTestDB(t))
+
+	sqlDB, err := sql.Open(sqldriver.DriverName, "reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	var n int64
+	if err := sqlDB.QueryRow(`match $p isa person; reduce $n = count($p);`).Scan(&n); err != nil || n != 3 {
+		t.Errorf("count = %d, %v", n, err)
+	}
+
+	if _, err := sql.Open(sqldriver.DriverName, "nowhere"); err == nil {
+		t.Error("expected error for unregistered dsn")
+	}
+}
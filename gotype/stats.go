@@ -0,0 +1,207 @@
+package gotype
+
+import (
+	"context"
+	"iter"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the runtime counters of a Database, as returned by
+// Database.Stats. Counters cover every transaction opened through the
+// Database, including those used by managers and TransactionContexts.
+type Stats struct {
+	// ReadQueries, WriteQueries and SchemaQueries count queries executed in
+	// read, write and schema transactions.
+	ReadQueries   int64
+	WriteQueries  int64
+	SchemaQueries int64
+	// QueryErrors counts queries that returned an error.
+	QueryErrors int64
+	// TransactionsOpened counts transactions opened successfully.
+	TransactionsOpened int64
+	// TransactionsCommitted counts successful commits.
+	TransactionsCommitted int64
+	// TransactionsRolledBack counts explicit rollbacks and write or schema
+	// transactions closed without a successful commit.
+	TransactionsRolledBack int64
+	// HydrationErrors counts result rows managers failed to hydrate.
+	HydrationErrors int64
+	// QueryTime is the total time spent executing queries.
+	QueryTime time.Duration
+}
+
+// Queries returns the number of queries executed across all transaction types.
+func (s Stats) Queries() int64 {
+	return s.ReadQueries + s.WriteQueries + s.SchemaQueries
+}
+
+// AvgLatency returns the mean query execution time, or 0 if no query ran.
+func (s Stats) AvgLatency() time.Duration {
+	n := s.Queries()
+	if n == 0 {
+		return 0
+	}
+	return s.QueryTime / time.Duration(n)
+}
+
+// dbStats holds the live counters. Database copies made with WithRegistry or
+// WithMaxRows share it with the original handle.
+type dbStats struct {
+	queries         [3]atomic.Int64 // indexed by TransactionType
+	queryErrors     atomic.Int64
+	queryNanos      atomic.Int64
+	txOpened        atomic.Int64
+	txCommitted     atomic.Int64
+	txRolledBack    atomic.Int64
+	hydrationErrors atomic.Int64
+}
+
+// Stats returns a snapshot of the runtime counters of db.
+func (db *Database) Stats() Stats {
+	s := db.stats
+	if s == nil {
+		return Stats{}
+	}
+	return Stats{
+		ReadQueries:            s.queries[ReadTransaction].Load(),
+		WriteQueries:           s.queries[WriteTransaction].Load(),
+		SchemaQueries:          s.queries[SchemaTransaction].Load(),
+		QueryErrors:            s.queryErrors.Load(),
+		TransactionsOpened:     s.txOpened.Load(),
+		TransactionsCommitted:  s.txCommitted.Load(),
+		TransactionsRolledBack: s.txRolledBack.Load(),
+		HydrationErrors:        s.hydrationErrors.Load(),
+		QueryTime:              time.Duration(s.queryNanos.Load()),
+	}
+}
+
+// ResetStats sets every counter reported by Stats back to zero, e.g. after
+// exporting them to a metrics system.
+func (db *Database) ResetStats() {
+	s := db.stats
+	if s == nil {
+		return
+	}
+	for i := range s.queries {
+		s.queries[i].Store(0)
+	}
+	s.queryErrors.Store(0)
+	s.queryNanos.Store(0)
+	s.txOpened.Store(0)
+	s.txCommitted.Store(0)
+	s.txRolledBack.Store(0)
+	s.hydrationErrors.Store(0)
+}
+
+// recordHydrationError counts a failed hydration; s may be nil.
+func (s *dbStats) recordHydrationError() {
+	if s != nil {
+		s.hydrationErrors.Add(1)
+	}
+}
+
+// instrumentTx wraps a freshly opened tx so its queries and outcome are
+// counted in the stats and reported to the query logger and debug dump, if
+// enabled. ctx is the context the transaction was opened with.
+func (db *Database) instrumentTx(ctx context.Context, tx Tx, txType TransactionType) Tx {
+	if txType < ReadTransaction || txType > SchemaTransaction ||
+		(db.stats == nil && db.debug == nil && db.logger == nil) {
+		return tx
+	}
+	if db.stats != nil {
+		db.stats.txOpened.Add(1)
+	}
+	return &observedTx{Tx: tx, db: db, ctx: ctx, stats: db.stats, txType: txType}
+}
+
+// observedTx counts queries, commits and rollbacks of the wrapped transaction
+// and reports its queries to the Database's loggers. stats may be nil.
+type observedTx struct {
+	Tx
+	db     *Database
+	ctx    context.Context // used for queries that carry no context
+	stats  *dbStats
+	txType TransactionType
+	ended  atomic.Bool // committed, rolled back or closed
+}
+
+// Unwrap returns the transaction opened by the Conn.
+func (t *observedTx) Unwrap() Tx {
+	return t.Tx
+}
+
+func (t *observedTx) record(ctx context.Context, start time.Time, query string, rows []map[string]any, count int, err error) {
+	dur := time.Since(start)
+	if s := t.stats; s != nil {
+		s.queries[t.txType].Add(1)
+		s.queryNanos.Add(int64(dur))
+		if err != nil {
+			s.queryErrors.Add(1)
+		}
+	}
+	t.db.logQuery(ctx, t.txType, query, rows, count, dur, err)
+}
+
+func (t *observedTx) Query(query string) ([]map[string]any, error) {
+	start := time.Now()
+	rows, err := t.Tx.Query(query)
+	t.record(t.ctx, start, query, rows, len(rows), err)
+	return rows, err
+}
+
+func (t *observedTx) QueryWithContext(ctx context.Context, query string) ([]map[string]any, error) {
+	start := time.Now()
+	rows, err := t.Tx.QueryWithContext(ctx, query)
+	t.record(ctx, start, query, rows, len(rows), err)
+	return rows, err
+}
+
+func (t *observedTx) QueryResult(ctx context.Context, query string) (Result, error) {
+	start := time.Now()
+	res, err := queryResult(ctx, t.Tx, query)
+	t.record(ctx, start, query, res.Rows, len(res.Rows), err)
+	return res, err
+}
+
+func (t *observedTx) QueryStream(ctx context.Context, query string) iter.Seq2[map[string]any, error] {
+	return func(yield func(map[string]any, error) bool) {
+		start := time.Now()
+		var count int
+		var streamErr error
+		defer func() { t.record(ctx, start, query, nil, count, streamErr) }()
+		for row, err := range queryRows(ctx, t.Tx, query) {
+			if err != nil {
+				streamErr = err
+			} else {
+				count++
+			}
+			if !yield(row, err) {
+				return
+			}
+		}
+	}
+}
+
+func (t *observedTx) Commit() error {
+	err := t.Tx.Commit()
+	if err == nil && t.ended.CompareAndSwap(false, true) && t.stats != nil {
+		t.stats.txCommitted.Add(1)
+	}
+	return err
+}
+
+func (t *observedTx) Rollback() error {
+	err := t.Tx.Rollback()
+	if err == nil && t.ended.CompareAndSwap(false, true) && t.stats != nil {
+		t.stats.txRolledBack.Add(1)
+	}
+	return err
+}
+
+func (t *observedTx) Close() {
+	t.Tx.Close()
+	if t.ended.CompareAndSwap(false, true) && t.txType != ReadTransaction && t.stats != nil {
+		t.stats.txRolledBack.Add(1)
+	}
+}
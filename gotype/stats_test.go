@@ -0,0 +1,87 @@
+package gotype
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type failingQueryTx struct{ mockTx }
+
+func (f *failingQueryTx) QueryWithContext(ctx context.Context, query string) ([]map[string]any, error) {
+	return nil, errors.New("boom")
+}
+
+type failingQueryConn struct{ mockConn }
+
+func (c *failingQueryConn) Transaction(dbName string, txType int) (Tx, error) {
+	return &failingQueryTx{}, nil
+}
+
+func TestStats_CountsQueriesAndTransactions(t *testing.T) {
+	registerTestTypes(t)
+	conn := &mockConn{txs: []*mockTx{
+		{responses: [][]map[string]any{{{"_iid": "0x1"}}}}, // insert
+		{responses: [][]map[string]any{{{"name": "a"}, {"name": "b"}}}},
+		{}, // explicit rollback
+	}}
+	db := NewDatabase(conn, "test_db")
+	mgr := MustNewManager[testPerson](db)
+	ctx := context.Background()
+
+	if err := mgr.Insert(ctx, &testPerson{Name: "a", Email: "a@x"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if _, err := mgr.All(ctx); err != nil {
+		t.Fatalf("All: %v", err)
+	}
+	tc, err := db.Begin(WriteTransaction)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err := tc.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	tc.Close()
+
+	s := db.Stats()
+	if s.ReadQueries != 1 || s.WriteQueries != 1 || s.SchemaQueries != 0 || s.Queries() != 2 {
+		t.Errorf("query counts = %+v", s)
+	}
+	if s.TransactionsOpened != 3 || s.TransactionsCommitted != 1 || s.TransactionsRolledBack != 1 {
+		t.Errorf("transaction counts = %+v", s)
+	}
+	if s.AvgLatency() != s.QueryTime/2 {
+		t.Errorf("AvgLatency = %v, QueryTime = %v", s.AvgLatency(), s.QueryTime)
+	}
+
+	// Copies share the counters with the original handle.
+	if db.WithMaxRows(10).Stats() != s {
+		t.Error("derived Database should report the same stats")
+	}
+	db.ResetStats()
+	if db.Stats() != (Stats{}) {
+		t.Errorf("stats after reset = %+v", db.Stats())
+	}
+}
+
+func TestStats_CountsErrors(t *testing.T) {
+	registerTestTypes(t)
+	db := NewDatabase(&failingQueryConn{}, "test_db")
+	if _, err := db.ExecuteWrite(context.Background(), "insert $x isa thing;"); err == nil {
+		t.Fatal("expected query error")
+	}
+	s := db.Stats()
+	if s.QueryErrors != 1 || s.TransactionsRolledBack != 1 {
+		t.Errorf("stats = %+v, want 1 query error and 1 rollback", s)
+	}
+
+	readTx := &mockTx{responses: [][]map[string]any{{{"name": "a", "age": "not a number"}}}}
+	db = NewDatabase(&mockConn{txs: []*mockTx{readTx}}, "test_db")
+	if _, err := MustNewManager[testPerson](db).All(context.Background()); err == nil {
+		t.Fatal("expected hydration error")
+	}
+	if got := db.Stats().HydrationErrors; got != 1 {
+		t.Errorf("HydrationErrors = %d, want 1", got)
+	}
+}
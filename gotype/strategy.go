@@ -4,6 +4,7 @@ package gotype
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/CaliLuke/go-typeql/ast"
@@ -35,7 +36,9 @@ type FetchBuilder interface {
 	BuildFetchAll(info *ModelInfo, varName string) (string, error)
 	// BuildFetchAllWithType generates a fetch clause that includes the type label.
 	BuildFetchAllWithType(info *ModelInfo, varName string) (string, error)
-	// BuildFetchWithRoles generates a fetch clause including role player data for relations.
+	// BuildFetchWithRoles generates a fetch clause including role player data
+	// for relations. matchAdditions holds patterns to append to the match
+	// clause, if any.
 	BuildFetchWithRoles(info *ModelInfo, varName string) (matchAdditions string, fetchClause string, err error)
 }
 
@@ -57,6 +60,9 @@ func strategyFor(kind ModelKind) ModelStrategy {
 }
 
 func appendIIDFetch(query, varName string) (string, error) {
+	if varName == templateVar {
+		return query + "\n" + iidFetchTemplate, nil
+	}
 	fetch := ast.Fetch(ast.FetchFunc("_iid", "iid", "$"+varName))
 	fetchStr, err := compileNode(fetch)
 	if err != nil {
@@ -83,17 +89,29 @@ func (s *entityStrategy) BuildPutQuery(info *ModelInfo, instance any, varName st
 
 func (s *entityStrategy) buildInsertOrPut(info *ModelInfo, instance any, varName string, keyword string) (string, error) {
 	v := reflectValue(instance)
+	subject := "$" + varName
 
 	// Build AST statements
-	statements := []ast.Statement{
-		ast.IsaStmt("$"+varName, info.TypeName),
-	}
+	statements := make([]ast.Statement, 1, 1+len(info.Fields))
+	statements[0] = ast.IsaStmt(subject, info.TypeName)
 
 	for _, fi := range info.Fields {
-		visitFieldValues(v, fi, func(val any) {
+		err := visitFieldValues(v, fi, func(val any) error {
 			statements = append(statements,
-				ast.HasStmt("$"+varName, fi.Tag.Name, ast.ValueFromGo(val)))
+				ast.HasStmt(subject, fi.Tag.Name, ast.ValueFromGo(val)))
+			return nil
 		})
+		if err != nil {
+			return "", err
+		}
+	}
+	err := visitExtraValues(v, info, func(name string, val any) error {
+		statements = append(statements,
+			ast.HasStmt(subject, name, ast.ValueFromGo(val)))
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
 	// Build clause based on keyword
@@ -109,11 +127,19 @@ func (s *entityStrategy) buildInsertOrPut(info *ModelInfo, instance any, varName
 
 func (s *entityStrategy) BuildMatchByKey(info *ModelInfo, instance any, varName string) (string, error) {
 	v := reflectValue(instance)
+	if varName == templateVar && info.templates != nil {
+		if query, ok, err := precompiledMatchByKey(info, v); ok || err != nil {
+			return query, err
+		}
+	}
 
 	// Build has constraints for key fields
 	var constraints []ast.Constraint
 	for _, fi := range info.KeyFields {
-		val := extractSingleFieldValue(v, fi)
+		val, err := extractSingleFieldValue(v, fi)
+		if err != nil {
+			return "", err
+		}
 		if val != nil {
 			constraints = append(constraints, ast.Has(fi.Tag.Name, ast.ValueFromGo(val)))
 		}
@@ -135,6 +161,19 @@ func (s *entityStrategy) BuildMatchByIID(iid string, varName string) (string, er
 }
 
 func (s *entityStrategy) BuildMatchAll(info *ModelInfo, varName string) (string, error) {
+	return buildMatchAll(info, varName)
+}
+
+// buildMatchAll returns the match clause for all instances of info, using the
+// registration-time template when possible. Shared by both strategies.
+func buildMatchAll(info *ModelInfo, varName string) (string, error) {
+	if varName == templateVar && info.templates != nil {
+		return info.templates.matchAll, nil
+	}
+	return compileMatchAll(info, varName)
+}
+
+func compileMatchAll(info *ModelInfo, varName string) (string, error) {
 	match := ast.Match(
 		ast.Entity("$"+varName, info.TypeName),
 	)
@@ -145,21 +184,32 @@ func (s *entityStrategy) BuildFetchAll(info *ModelInfo, varName string) (string,
 	return buildFetchAll(info, varName)
 }
 
-// buildFetchAll compiles a fetch for every owned attribute plus the synthetic
-// _iid field. Shared by entity and relation strategies.
+// buildFetchAll returns a fetch for every owned attribute plus the synthetic
+// _iid field, using the registration-time template when possible. Shared by
+// entity and relation strategies.
 func buildFetchAll(info *ModelInfo, varName string) (string, error) {
-	items := []ast.FetchItem{ast.FetchFunc("_iid", "iid", "$"+varName)}
+	if varName == templateVar && info.templates != nil {
+		return info.templates.fetchAll, nil
+	}
+	return compileFetchAll(info, varName)
+}
+
+func compileFetchAll(info *ModelInfo, varName string) (string, error) {
+	subject := "$" + varName
+	items := make([]ast.FetchItem, 1, len(info.Fields)+2)
+	items[0] = ast.FetchFunc("_iid", "iid", subject)
 	for _, fi := range info.Fields {
 		if fi.IsSlice {
 			items = append(items, ast.FetchAttributeList{
 				Key:      fi.Tag.Name,
-				Var:      "$" + varName,
+				Var:      subject,
 				AttrName: fi.Tag.Name,
 			})
 		} else {
-			items = append(items, ast.FetchAttr(fi.Tag.Name, "$"+varName, fi.Tag.Name))
+			items = append(items, ast.FetchAttr(fi.Tag.Name, subject, fi.Tag.Name))
 		}
 	}
+	items = appendExtraFetch(items, info, varName)
 	return compileNode(ast.Fetch(items...))
 }
 
@@ -198,12 +248,15 @@ func (s *relationStrategy) BuildPutQuery(info *ModelInfo, instance any, varName
 
 func (s *relationStrategy) buildInsertOrPut(info *ModelInfo, instance any, varName string, keyword string) (string, error) {
 	v := reflectValue(instance)
+	if err := checkRoleCardinality(info, v); err != nil {
+		return "", err
+	}
 
 	var matchPatterns []ast.Pattern
 	var roleParts []string
 
 	for _, role := range info.Roles {
-		field := v.Field(role.FieldIndex)
+		field := role.fieldValue(v)
 		if field.Kind() == reflect.Pointer && field.IsNil() {
 			continue
 		}
@@ -215,26 +268,15 @@ func (s *relationStrategy) buildInsertOrPut(info *ModelInfo, instance any, varNa
 		roleVar := role.RoleName
 
 		// Look up player model info for key matching
-		playerInfo, ok := LookupType(playerVal.Type())
+		playerInfo, ok := registryOf(info).LookupType(playerVal.Type())
 		if !ok {
 			continue
 		}
-
-		// Prefer IID, fall back to key attributes
-		playerIID := getIIDFromValueInfo(playerVal, playerInfo)
-
-		if playerIID != "" {
-			matchPatterns = append(matchPatterns, ast.Entity("$"+roleVar, playerInfo.TypeName, ast.Iid(playerIID)))
-		} else {
-			var constraints []ast.Constraint
-			for _, kf := range playerInfo.KeyFields {
-				kVal := extractSingleFieldValue(playerVal, kf)
-				if kVal != nil {
-					constraints = append(constraints, ast.Has(kf.Tag.Name, ast.ValueFromGo(kVal)))
-				}
-			}
-			matchPatterns = append(matchPatterns, ast.Entity("$"+roleVar, playerInfo.TypeName, constraints...))
+		pattern, err := playerPattern(roleVar, playerVal, playerInfo)
+		if err != nil {
+			return "", err
 		}
+		matchPatterns = append(matchPatterns, pattern)
 
 		roleParts = append(roleParts, fmt.Sprintf("%s: $%s", role.RoleName, roleVar))
 	}
@@ -245,9 +287,22 @@ func (s *relationStrategy) buildInsertOrPut(info *ModelInfo, instance any, varNa
 		varName, info.TypeName, strings.Join(roleParts, ", ")))
 
 	for _, fi := range info.Fields {
-		visitFieldValues(v, fi, func(val any) {
-			insertParts = append(insertParts, fmt.Sprintf("has %s %s", fi.Tag.Name, FormatValue(val)))
+		err := visitFieldValues(v, fi, func(val any) error {
+			has, err := hasClause(fi.Tag.Name, val)
+			insertParts = append(insertParts, has)
+			return err
 		})
+		if err != nil {
+			return "", err
+		}
+	}
+	err := visitExtraValues(v, info, func(name string, val any) error {
+		has, err := hasClause(name, val)
+		insertParts = append(insertParts, has)
+		return err
+	})
+	if err != nil {
+		return "", err
 	}
 
 	// Compile query
@@ -264,6 +319,25 @@ func (s *relationStrategy) buildInsertOrPut(info *ModelInfo, instance any, varNa
 	return query, nil
 }
 
+// playerPattern matches the stored instance of playerVal as $varName, by IID
+// when it is set and by key attributes otherwise.
+func playerPattern(varName string, playerVal reflect.Value, playerInfo *ModelInfo) (ast.Pattern, error) {
+	if playerIID := getIIDFromValueInfo(playerVal, playerInfo); playerIID != "" {
+		return ast.Entity("$"+varName, playerInfo.TypeName, ast.Iid(playerIID)), nil
+	}
+	var constraints []ast.Constraint
+	for _, kf := range playerInfo.KeyFields {
+		kVal, err := extractSingleFieldValue(playerVal, kf)
+		if err != nil {
+			return nil, err
+		}
+		if kVal != nil {
+			constraints = append(constraints, ast.Has(kf.Tag.Name, ast.ValueFromGo(kVal)))
+		}
+	}
+	return ast.Entity("$"+varName, playerInfo.TypeName, constraints...), nil
+}
+
 func (s *relationStrategy) BuildMatchByKey(info *ModelInfo, instance any, varName string) (string, error) {
 	v := reflectValue(instance)
 	iid := getIIDFromValueInfo(v, info)
@@ -288,10 +362,7 @@ func (s *relationStrategy) BuildMatchByIID(iid string, varName string) (string,
 }
 
 func (s *relationStrategy) BuildMatchAll(info *ModelInfo, varName string) (string, error) {
-	match := ast.Match(
-		ast.Entity("$"+varName, info.TypeName),
-	)
-	return compileNode(match)
+	return buildMatchAll(info, varName)
 }
 
 func (s *relationStrategy) BuildFetchAll(info *ModelInfo, varName string) (string, error) {
@@ -320,55 +391,42 @@ func buildFetchAllWithType(info *ModelInfo, varName string) (string, error) {
 	for _, fi := range info.Fields {
 		items = appendFetchField(items, fi, varName)
 	}
+	items = appendExtraFetch(items, info, varName)
 	return compileNode(ast.Fetch(items...))
 }
 
 func (s *relationStrategy) BuildFetchWithRoles(info *ModelInfo, varName string) (string, string, error) {
-	// Build match patterns for role players using AST
-	var matchPatterns []ast.Pattern
 	var items []string
 	items = append(items, fmt.Sprintf(`"_iid": iid($%s)`, varName))
 
 	// Own attributes
 	items = appendFetchProjectionItems(items, info.Fields, varName)
+	if info.extraField != nil {
+		items = append(items, fmt.Sprintf(`"%s": { $%s.* }`, ExtraAttributesKey, varName))
+	}
 
-	// Role players
+	// Role players, fetched as one list subquery per role so that a relation
+	// yields a single row whatever the number of players of each role.
+	var fetched []string
 	for _, role := range info.Roles {
-		roleVar := role.RoleName
-		// Add links pattern to match (using RawPattern for "links" syntax)
-		matchPatterns = append(matchPatterns, ast.RawPattern{
-			Content: fmt.Sprintf("$%s links (%s: $%s)", varName, role.RoleName, roleVar),
-		})
-
-		// Look up player model info to get its attributes
-		playerInfo, ok := Lookup(role.PlayerTypeName)
-		if !ok {
-			// Can't resolve player type — just include IID
-			items = append(items, fmt.Sprintf(`"%s": { "_iid": iid($%s) }`, role.RoleName, roleVar))
+		if slices.Contains(fetched, role.RoleName) {
 			continue
 		}
+		fetched = append(fetched, role.RoleName)
+		roleVar := role.RoleName
 
-		// Build a sub-fetch for the role player
-		var subItems []string
-		subItems = append(subItems, fmt.Sprintf(`"_iid": iid($%s)`, roleVar))
-		subItems = appendFetchProjectionItems(subItems, playerInfo.Fields, roleVar)
-		items = append(items, fmt.Sprintf(`"%s": { %s }`, role.RoleName, strings.Join(subItems, ", ")))
-	}
-
-	matchAdditions := ""
-	if len(matchPatterns) > 0 {
-		// Extract pattern contents (all are RawPattern with "links" syntax)
-		var parts []string
-		for _, p := range matchPatterns {
-			if rp, ok := p.(ast.RawPattern); ok {
-				parts = append(parts, rp.Content)
-			}
+		subItems := []string{fmt.Sprintf(`"_iid": iid($%s)`, roleVar)}
+		// Look up player model info to get its attributes; an unresolved
+		// player type only gets its IID.
+		if playerInfo, ok := role.playerInfo(registryOf(info)); ok {
+			subItems = appendFetchProjectionItems(subItems, playerInfo.Fields, roleVar)
 		}
-		matchAdditions = strings.Join(parts, ";\n") + ";"
+		items = append(items, fmt.Sprintf(`"%s": [ match $%s links (%s: $%s); fetch { %s }; ]`,
+			role.RoleName, varName, role.RoleName, roleVar, strings.Join(subItems, ", ")))
 	}
 
 	fetchClause := "fetch {\n" + strings.Join(items, ",\n") + "\n};"
-	return matchAdditions, fetchClause, nil
+	return "", fetchClause, nil
 }
 
 // --- Helpers ---
@@ -381,36 +439,48 @@ func reflectValue(instance any) reflect.Value {
 	return v
 }
 
-func visitFieldValues(v reflect.Value, fi FieldInfo, fn func(any)) {
-	field := v.Field(fi.FieldIndex)
-	if fi.IsPointer && field.IsNil() {
-		return
+func visitFieldValues(v reflect.Value, fi FieldInfo, fn func(any) error) error {
+	field := fi.fieldValue(v)
+	if fi.IsPointer {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
 	}
 
 	if fi.IsSlice {
 		for i := 0; i < field.Len(); i++ {
-			fn(field.Index(i).Interface())
+			val, err := attrValueOf(field.Index(i))
+			if err != nil {
+				return fmt.Errorf("field %s: %w", fi.FieldName, err)
+			}
+			if err := fn(val); err != nil {
+				return err
+			}
 		}
-		return
+		return nil
 	}
 
-	val := field.Interface()
-	if fi.IsPointer {
-		val = field.Elem().Interface()
+	val, err := attrValueOf(field)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", fi.FieldName, err)
 	}
-	fn(val)
+	return fn(val)
 }
 
-func extractSingleFieldValue(v reflect.Value, fi FieldInfo) any {
-	field := v.Field(fi.FieldIndex)
-	if fi.IsPointer && field.IsNil() {
-		return nil
-	}
-	val := field.Interface()
+func extractSingleFieldValue(v reflect.Value, fi FieldInfo) (any, error) {
+	field := fi.fieldValue(v)
 	if fi.IsPointer {
-		val = field.Elem().Interface()
+		if field.IsNil() {
+			return nil, nil
+		}
+		field = field.Elem()
+	}
+	val, err := attrValueOf(field)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", fi.FieldName, err)
 	}
-	return val
+	return val, nil
 }
 
 func getIIDFromValueInfo(v reflect.Value, info *ModelInfo) string {
@@ -278,8 +278,11 @@ func TestRelationStrategy_BuildFetchWithRoles(t *testing.T) {
 	if err != nil {
 		t.Fatalf("BuildFetchWithRoles: %v", err)
 	}
-	assertContains(t, matchAdd, "$r links (employee: $employee)")
-	assertContains(t, matchAdd, "$r links (employer: $employer)")
+	if matchAdd != "" {
+		t.Errorf("expected role players in fetch subqueries, got match additions %q", matchAdd)
+	}
+	assertContains(t, fetch, `"employee": [ match $r links (employee: $employee); fetch {`)
+	assertContains(t, fetch, `"employer": [ match $r links (employer: $employer); fetch {`)
 	assertContains(t, fetch, `"_iid": iid($r)`)
 	assertContains(t, fetch, `"start-date": $r.start-date`)
 	assertContains(t, fetch, `"employee"`)
@@ -0,0 +1,117 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Graph is the neighbourhood of an instance returned by Subgraph.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is an instance in a Graph.
+type GraphNode struct {
+	// IID is the IID of the instance.
+	IID string `json:"iid"`
+	// Type is the exact type label of the instance.
+	Type string `json:"type"`
+	// Depth is the number of relations between the root and the instance.
+	Depth int `json:"depth"`
+}
+
+// GraphEdge links two instances of a Graph playing roles in the same
+// relation. A relation with more than two players yields one edge per pair.
+type GraphEdge struct {
+	Relation     string `json:"relation"`
+	RelationType string `json:"relation_type"`
+	From         string `json:"from"`
+	FromRole     string `json:"from_role"`
+	To           string `json:"to"`
+	ToRole       string `json:"to_role"`
+}
+
+// Subgraph returns the instances reachable from rootIID across at most depth
+// relations, and the relations linking them, for graph visualisation and
+// impact analysis. It walks one level per query. When types is not empty
+// only instances and relations of those types, or registered subtypes of
+// them, are followed; the root is always included. Relations between two
+// instances at the last level are not fetched. A missing root is a
+// *NotFoundError.
+func Subgraph(ctx context.Context, db *Database, rootIID string, depth int, types []string) (*Graph, error) {
+	if !IsIID(rootIID) {
+		return nil, fmt.Errorf("subgraph: invalid IID %q", rootIID)
+	}
+	if err := checkCtx(ctx, "subgraph", "graph"); err != nil {
+		return nil, err
+	}
+	labels, err := instanceTypes(ctx, db, rootIID)
+	if err != nil {
+		return nil, fmt.Errorf("subgraph: %w", err)
+	}
+	if labels[rootIID] == "" {
+		return nil, fmt.Errorf("subgraph: %w", &NotFoundError{TypeName: rootIID})
+	}
+	allowed := typeAllowList(db.Registry(), types)
+
+	g := &Graph{Nodes: []GraphNode{{IID: rootIID, Type: labels[rootIID]}}}
+	seen := map[string]bool{rootIID: true}
+	edges := make(map[string]bool)
+	frontier := []string{rootIID}
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		found, err := neighbours(ctx, db, frontier, nil)
+		if err != nil {
+			return nil, fmt.Errorf("subgraph: %w", err)
+		}
+		var next []string
+		for _, e := range found {
+			if !allowed(e.RelationType) || !allowed(e.ToType) {
+				continue
+			}
+			if !seen[e.To] {
+				seen[e.To] = true
+				next = append(next, e.To)
+				g.Nodes = append(g.Nodes, GraphNode{IID: e.To, Type: e.ToType, Depth: level})
+			}
+			// Both ends of an edge between two expanded instances report it.
+			key := e.Relation + " " + min(e.From, e.To) + " " + max(e.From, e.To)
+			if edges[key] {
+				continue
+			}
+			edges[key] = true
+			g.Edges = append(g.Edges, GraphEdge{
+				Relation:     e.Relation,
+				RelationType: e.RelationType,
+				From:         e.From,
+				FromRole:     e.FromRole,
+				To:           e.To,
+				ToRole:       e.ToRole,
+			})
+		}
+		frontier = next
+	}
+	return g, nil
+}
+
+// typeAllowList reports whether a type label is in types or is a registered
+// subtype of one of them. An empty list allows every type.
+func typeAllowList(reg *Registry, types []string) func(string) bool {
+	return func(label string) bool {
+		if len(types) == 0 {
+			return true
+		}
+		for label != "" {
+			if slices.Contains(types, label) {
+				return true
+			}
+			info, ok := reg.Lookup(label)
+			if !ok {
+				return false
+			}
+			label = info.Supertype
+		}
+		return false
+	}
+}
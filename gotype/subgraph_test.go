@@ -0,0 +1,45 @@
+package gotype
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSubgraph(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	hop := func(from, rel, relType, to, toType string) map[string]any {
+		return map[string]any{"from": from, "relation": rel, "relation_type": relType,
+			"from_role": "r", "to": to, "to_role": "r", "to_type": toType}
+	}
+	root := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1", "type": "test-person"}}}}
+	level1 := &mockTx{responses: [][]map[string]any{{
+		hop("0x1", "0xa", "test-employment", "0x2", "test-company"),
+		hop("0x1", "0xb", "friendship", "0x3", "test-person"),
+	}}}
+	level2 := &mockTx{responses: [][]map[string]any{{
+		hop("0x2", "0xa", "test-employment", "0x1", "test-person"),
+		hop("0x2", "0xc", "test-employment", "0x4", "test-person"),
+	}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{root, level1, level2}}, "test_db")
+
+	g, err := Subgraph(ctx, db, "0x1", 2, []string{"test-person", "test-company", "test-employment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 3 || g.Nodes[1].IID != "0x2" || g.Nodes[2].IID != "0x4" || g.Nodes[2].Depth != 2 {
+		t.Errorf("nodes = %+v", g.Nodes)
+	}
+	if len(g.Edges) != 2 || g.Edges[0].Relation != "0xa" || g.Edges[1].Relation != "0xc" {
+		t.Errorf("edges = %+v", g.Edges)
+	}
+	assertContains(t, level2.queries[0], "$a iid 0x2;")
+
+	root = &mockTx{responses: [][]map[string]any{{}}}
+	db = NewDatabase(&mockConn{txs: []*mockTx{root}}, "test_db")
+	var nf *NotFoundError
+	if _, err := Subgraph(ctx, db, "0x1", 2, nil); !errors.As(err, &nf) {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -21,12 +21,25 @@ type FieldTag struct {
 	CardMax *int
 	// RoleName is the name of the role for relation player fields.
 	RoleName string
+	// LinkRelation and LinkRole name the relation and the role played by
+	// the field's value for typed role-player fields ("rel:relation.role").
+	LinkRelation string
+	LinkRole     string
 	// Abstract marks the model type as abstract.
 	Abstract bool
 	// TypeName provides an explicit override for the TypeDB type name.
 	TypeName string
 	// Skip indicates the field should be ignored by the ORM.
 	Skip bool
+	// AutoCreate sets a time.Time field to the current time on insert.
+	AutoCreate bool
+	// AutoUpdate sets a time.Time field to the current time on every write.
+	AutoUpdate bool
+	// Default is the value Insert uses when the field is zero or nil.
+	Default *string
+	// Extra marks a map[string]any field that collects attributes not
+	// mapped to other struct fields.
+	Extra bool
 }
 
 // IsRole returns true if the tag identifies the field as a role player in a relation.
@@ -34,9 +47,16 @@ func (ft FieldTag) IsRole() bool {
 	return ft.RoleName != ""
 }
 
+// IsLink returns true if the tag identifies the field as a typed role-player
+// field of an entity.
+func (ft FieldTag) IsLink() bool {
+	return ft.LinkRelation != ""
+}
+
 // ParseTag parses the content of a `typedb` struct tag into a FieldTag structure.
 // It supports options like key, unique, cardinality (card=M..N), roles (role:name),
-// and type name overrides (type:name).
+// typed role players (rel:relation.role), type name overrides (type:name), defaults (default=value) and the attribute
+// bag marker (",extra"). Default values cannot contain commas.
 func ParseTag(tag string) (FieldTag, error) {
 	if tag == "" || tag == "-" {
 		return FieldTag{Skip: tag == "-"}, nil
@@ -64,7 +84,7 @@ func isBareName(part string) bool {
 		return false
 	}
 	switch part {
-	case "key", "unique", "abstract", "-":
+	case "key", "unique", "abstract", "autocreate", "autoupdate", "-":
 		return false
 	}
 	return true
@@ -80,10 +100,25 @@ func applyTagOption(ft *FieldTag, part string, isFirst bool) error {
 		ft.Abstract = true
 	case part == "-":
 		ft.Skip = true
+	case part == "extra" && !isFirst:
+		ft.Extra = true
+	case part == "autocreate":
+		ft.AutoCreate = true
+	case part == "autoupdate":
+		ft.AutoUpdate = true
 	case strings.HasPrefix(part, "role:"):
 		ft.RoleName = strings.TrimPrefix(part, "role:")
+	case strings.HasPrefix(part, "rel:"):
+		rel, role, ok := strings.Cut(strings.TrimPrefix(part, "rel:"), ".")
+		if !ok || rel == "" || role == "" {
+			return fmt.Errorf("invalid link %q: expected rel:relation.role", part)
+		}
+		ft.LinkRelation, ft.LinkRole = rel, role
 	case strings.HasPrefix(part, "type:"):
 		ft.TypeName = strings.TrimPrefix(part, "type:")
+	case strings.HasPrefix(part, "default="):
+		def := strings.TrimPrefix(part, "default=")
+		ft.Default = &def
 	case strings.HasPrefix(part, "card="):
 		cardStr := strings.TrimPrefix(part, "card=")
 		min, max, err := parseCardinality(cardStr)
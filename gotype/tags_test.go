@@ -36,6 +36,16 @@ func TestParseTag(t *testing.T) {
 			tag:  "role:employee",
 			want: FieldTag{RoleName: "employee"},
 		},
+		{
+			name: "typed role player",
+			tag:  "rel:employment.employer",
+			want: FieldTag{LinkRelation: "employment", LinkRole: "employer"},
+		},
+		{
+			name:    "typed role player without role",
+			tag:     "rel:employment",
+			wantErr: true,
+		},
 		{
 			name: "cardinality range",
 			tag:  "tags,card=0..5",
@@ -66,6 +76,31 @@ func TestParseTag(t *testing.T) {
 			tag:  "-",
 			want: FieldTag{Skip: true},
 		},
+		{
+			name: "extra bag",
+			tag:  ",extra",
+			want: FieldTag{Extra: true},
+		},
+		{
+			name: "autocreate",
+			tag:  "created-at,autocreate",
+			want: FieldTag{Name: "created-at", AutoCreate: true},
+		},
+		{
+			name: "autoupdate",
+			tag:  "updated-at,autoupdate",
+			want: FieldTag{Name: "updated-at", AutoUpdate: true},
+		},
+		{
+			name: "default value",
+			tag:  "status,default=proposed",
+			want: FieldTag{Name: "status", Default: new("proposed")},
+		},
+		{
+			name: "attribute named extra",
+			tag:  "extra",
+			want: FieldTag{Name: "extra"},
+		},
 		{
 			name: "empty",
 			tag:  "",
@@ -108,6 +143,9 @@ func TestParseTag(t *testing.T) {
 			if got.RoleName != tt.want.RoleName {
 				t.Errorf("RoleName: got %q, want %q", got.RoleName, tt.want.RoleName)
 			}
+			if got.LinkRelation != tt.want.LinkRelation || got.LinkRole != tt.want.LinkRole {
+				t.Errorf("Link: got %q.%q, want %q.%q", got.LinkRelation, got.LinkRole, tt.want.LinkRelation, tt.want.LinkRole)
+			}
 			if got.Abstract != tt.want.Abstract {
 				t.Errorf("Abstract: got %v, want %v", got.Abstract, tt.want.Abstract)
 			}
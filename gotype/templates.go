@@ -0,0 +1,66 @@
+package gotype
+
+import (
+	"sync/atomic"
+
+	"github.com/CaliLuke/go-typeql/ast"
+)
+
+// templateVar is the variable the cached query templates are built for. It is
+// the variable every single-instance Manager operation uses.
+const templateVar = "e"
+
+// iidFetchTemplate is the compiled `fetch { "_iid": iid($e) };` clause
+// appended to inserts.
+var iidFetchTemplate = mustCompileNode(ast.Fetch(ast.FetchFunc("_iid", "iid", "$"+templateVar)))
+
+// queryTemplates caches query fragments that only depend on the model, so
+// they are compiled once at registration instead of on every operation.
+type queryTemplates struct {
+	matchAll string
+	fetchAll string
+	// count and matchByKey are only set from PrecompiledQueries.
+	count      string
+	matchByKey string
+	// poly caches the polymorphic fetch, which also depends on the subtypes
+	// registered so far; it is rebuilt when the registry generation changes.
+	poly atomic.Pointer[polyFetchTemplate]
+}
+
+type polyFetchTemplate struct {
+	gen   uint64
+	fetch string
+}
+
+// buildQueryTemplates compiles the per-type templates for info, taking them
+// from the model's PrecompiledQueries when it supplies them. It must run after
+// the type name is final.
+func buildQueryTemplates(info *ModelInfo) (*queryTemplates, error) {
+	matchAll, err := compileMatchAll(info, templateVar)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &queryTemplates{matchAll: matchAll}
+	// Generated queries know nothing of a hand-added ",extra" field.
+	if pq, ok := precompiledQueriesFor(info.GoType, info.TypeName); ok && info.extraField == nil {
+		tmpl.fetchAll = pq.FetchAll
+		tmpl.count = pq.Count
+		if info.Kind == ModelKindEntity {
+			tmpl.matchByKey = pq.MatchByKey
+		}
+	}
+	if tmpl.fetchAll == "" {
+		if tmpl.fetchAll, err = compileFetchAll(info, templateVar); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+func mustCompileNode(node ast.QueryNode) string {
+	s, err := compileNode(node)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package gotype
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryTemplates_MatchUncompiled(t *testing.T) {
+	registerTestTypes(t)
+
+	for _, name := range []string{"test-person", "test-employment"} {
+		info, _ := Lookup(name)
+		if info.templates == nil {
+			t.Fatalf("%s: templates not built at registration", name)
+		}
+		fetch, err := compileFetchAll(info, templateVar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.templates.fetchAll != fetch {
+			t.Errorf("%s: cached fetch %q != compiled %q", name, info.templates.fetchAll, fetch)
+		}
+		match, err := compileMatchAll(info, templateVar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.templates.matchAll != match {
+			t.Errorf("%s: cached match %q != compiled %q", name, info.templates.matchAll, match)
+		}
+	}
+
+	got, err := appendIIDFetch("insert $e isa x;", templateVar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := appendIIDFetch("insert $e isa x;", "e2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != strings.ReplaceAll(want, "$e2", "$e") {
+		t.Errorf("cached iid fetch %q does not match compiled %q", got, want)
+	}
+}
+
+func TestQueryTemplates_PolymorphicFetchSeesNewSubtypes(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testAnimal]()
+	base, _ := Lookup("test-animal")
+
+	before, err := buildPolymorphicFetch(base, templateVar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(before, "breed") {
+		t.Fatalf("unexpected subtype field before registration: %s", before)
+	}
+
+	// Registering a subtype later must invalidate the cached fetch.
+	MustRegister[testDog]()
+	dogInfo, _ := Lookup("test-dog")
+	dogInfo.Supertype = "test-animal"
+
+	after, err := buildPolymorphicFetch(base, templateVar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, after, `"breed": $e.breed`)
+}
+
+func TestQueryTemplates_PolymorphicFetchMemoized(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testAnimal]()
+	MustRegister[testDog]()
+	base, _ := Lookup("test-animal")
+
+	first, err := buildPolymorphicFetch(base, templateVar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := base.templates.poly.Load()
+	if cached == nil || cached.fetch != first {
+		t.Fatal("expected the polymorphic fetch to be cached after the first call")
+	}
+	for range 3 {
+		if _, err := buildPolymorphicFetch(base, templateVar); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if base.templates.poly.Load() != cached {
+		t.Error("cached polymorphic fetch was rebuilt without a registry change")
+	}
+	if n := testing.AllocsPerRun(100, func() { _, _ = buildPolymorphicFetch(base, templateVar) }); n != 0 {
+		t.Errorf("memoized polymorphic fetch allocated %.0f times, want 0", n)
+	}
+}
@@ -0,0 +1,59 @@
+package gotype
+
+import (
+	"reflect"
+	"time"
+)
+
+// timeNow is the clock used for autocreate/autoupdate fields; tests replace it.
+var timeNow = time.Now
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// timestampMode selects which automatic timestamp fields a write stamps.
+type timestampMode int
+
+const (
+	// stampInsert sets autocreate and autoupdate fields.
+	stampInsert timestampMode = iota
+	// stampUpdate sets autoupdate fields.
+	stampUpdate
+	// stampPut sets autocreate and autoupdate fields that are still zero, so
+	// repeating a put with the same instance stays idempotent.
+	stampPut
+)
+
+// isTimestampField reports whether t can hold an automatic timestamp.
+func isTimestampField(t reflect.Type) bool {
+	return t == timeType || (t.Kind() == reflect.Pointer && t.Elem() == timeType)
+}
+
+// stampTimestamps writes the current UTC time into the autocreate and
+// autoupdate fields of instance according to mode. autocreate fields that
+// already hold a value are never overwritten.
+func stampTimestamps(info *ModelInfo, instance any, mode timestampMode) {
+	if !info.hasTimestamps {
+		return
+	}
+	now := timeNow().UTC()
+	v := reflectValue(instance)
+	for i := range info.Fields {
+		fi := &info.Fields[i]
+		if !fi.Tag.AutoCreate && !fi.Tag.AutoUpdate {
+			continue
+		}
+		field := fi.fieldValue(v)
+		zero := field.IsZero()
+		switch {
+		case fi.Tag.AutoUpdate && mode != stampPut:
+		case zero && (fi.Tag.AutoUpdate || mode != stampUpdate):
+		default:
+			continue
+		}
+		if fi.IsPointer {
+			field.Set(reflect.ValueOf(&now))
+		} else {
+			field.Set(reflect.ValueOf(now))
+		}
+	}
+}
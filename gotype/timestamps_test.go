@@ -0,0 +1,122 @@
+package gotype
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testStamped struct {
+	BaseEntity
+	Name      string     `typedb:"name,key"`
+	CreatedAt time.Time  `typedb:"created-at,autocreate"`
+	UpdatedAt *time.Time `typedb:"updated-at,autoupdate"`
+}
+
+type testBadStamp struct {
+	BaseEntity
+	Name      string `typedb:"name,key"`
+	CreatedAt string `typedb:"created-at,autocreate"`
+}
+
+func fixTimeNow(t *testing.T, now time.Time) {
+	t.Helper()
+	orig := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = orig })
+}
+
+func TestTimestamps_RequiresTimeField(t *testing.T) {
+	ClearRegistry()
+	err := Register[testBadStamp]()
+	if err == nil || !strings.Contains(err.Error(), "autocreate/autoupdate requires time.Time") {
+		t.Fatalf("expected time.Time error, got %v", err)
+	}
+}
+
+func TestTimestamps_Insert(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testStamped]()
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	fixTimeNow(t, now)
+
+	writeTx := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1"}}}}
+	mgr := MustNewManager[testStamped](NewDatabase(&mockConn{txs: []*mockTx{writeTx}}, "test_db"))
+
+	s := &testStamped{Name: "a"}
+	if err := mgr.Insert(context.Background(), s); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if !s.CreatedAt.Equal(now) || s.UpdatedAt == nil || !s.UpdatedAt.Equal(now) {
+		t.Fatalf("timestamps not set: %v %v", s.CreatedAt, s.UpdatedAt)
+	}
+	assertContains(t, writeTx.queries[0], "has created-at 2026-03-04T05:06:07")
+	assertContains(t, writeTx.queries[0], "has updated-at 2026-03-04T05:06:07")
+}
+
+func TestTimestamps_InsertKeepsExplicitCreatedAt(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testStamped]()
+	fixTimeNow(t, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC))
+
+	writeTx := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1"}}}}
+	mgr := MustNewManager[testStamped](NewDatabase(&mockConn{txs: []*mockTx{writeTx}}, "test_db"))
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &testStamped{Name: "a", CreatedAt: created}
+	if err := mgr.Insert(context.Background(), s); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if !s.CreatedAt.Equal(created) {
+		t.Errorf("CreatedAt overwritten: %v", s.CreatedAt)
+	}
+}
+
+func TestTimestamps_Update(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testStamped]()
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	fixTimeNow(t, now)
+
+	writeTx := &mockTx{responses: [][]map[string]any{nil}}
+	mgr := MustNewManager[testStamped](NewDatabase(&mockConn{txs: []*mockTx{writeTx}}, "test_db"))
+
+	s := &testStamped{Name: "a", UpdatedAt: &old}
+	s.SetIID("0x1")
+	if err := mgr.Update(context.Background(), s); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if !s.UpdatedAt.Equal(now) {
+		t.Errorf("UpdatedAt = %v, want %v", s.UpdatedAt, now)
+	}
+	if !s.CreatedAt.IsZero() {
+		t.Errorf("Update must not set CreatedAt, got %v", s.CreatedAt)
+	}
+	q := writeTx.queries[0]
+	assertContains(t, q, "has updated-at 2026-03-04T05:06:07")
+	assertNotContains(t, q, "created-at")
+}
+
+func TestTimestamps_PutOnlyFillsZero(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testStamped]()
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	fixTimeNow(t, now)
+
+	writeTx := &mockTx{responses: [][]map[string]any{nil, {{"_iid": "0x1"}}}}
+	mgr := MustNewManager[testStamped](NewDatabase(&mockConn{txs: []*mockTx{writeTx}}, "test_db"))
+
+	s := &testStamped{Name: "a", UpdatedAt: &old}
+	if err := mgr.Put(context.Background(), s); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if !s.CreatedAt.Equal(now) {
+		t.Errorf("CreatedAt = %v, want %v", s.CreatedAt, now)
+	}
+	if !s.UpdatedAt.Equal(old) {
+		t.Errorf("Put must keep a set UpdatedAt, got %v", s.UpdatedAt)
+	}
+}
@@ -0,0 +1,232 @@
+package gotype
+
+import "strings"
+
+// tqlBlockStages are the pipeline stages whose statements go on the lines
+// below the keyword, indented one level.
+var tqlBlockStages = map[string]bool{
+	"match": true, "insert": true, "put": true, "update": true, "delete": true,
+	"define": true, "undefine": true, "redefine": true,
+}
+
+// tqlInlineStages are the pipeline stages whose arguments stay on the
+// keyword's line.
+var tqlInlineStages = map[string]bool{
+	"fetch": true, "select": true, "sort": true, "offset": true, "limit": true,
+	"reduce": true, "distinct": true, "require": true,
+}
+
+// FormatTQL reformats a TypeQL query into a canonical layout: each pipeline
+// stage keyword starts a line, statements are indented below it one per line
+// with comma-separated constraints on continuation lines, braces open a new
+// indentation level, whitespace outside string literals is collapsed and
+// redundant semicolons are dropped. A missing final semicolon is added.
+//
+//	match
+//	  $e isa person,
+//	    has name "Alice";
+//	fetch {
+//	  "name": $e.name
+//	};
+//
+// FormatTQL does not validate the query. Its output is stable, so formatting
+// an already formatted query returns it unchanged, which makes it suitable
+// for logs and golden files.
+func FormatTQL(query string) string {
+	f := tqlFormatter{stmtStart: true}
+	toks := tokenizeTQL(query)
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		if tok == "" {
+			f.space = true
+			continue
+		}
+		switch tok[0] {
+		case '#':
+			f.newline()
+			f.write(tok)
+			f.newline()
+		case ',':
+			f.write(tok)
+			if f.parens == 0 && (len(f.braces) > 0 || f.inClause) {
+				f.newline()
+				f.cont = len(f.braces) == 0 || !f.braces[len(f.braces)-1].mapBody
+			}
+		case ';':
+			if f.last == 0 || f.last == ';' {
+				continue
+			}
+			f.write(tok)
+			f.newline()
+			f.cont = false
+			f.stmtStart = true
+		case '(', '[':
+			f.write(tok)
+			f.parens++
+		case ')', ']':
+			f.parens = max(f.parens-1, 0)
+			f.write(tok)
+		case '{':
+			if j := nextTQLToken(toks, i); j > 0 && toks[j] == "}" {
+				f.write("{}")
+				i = j
+				continue
+			}
+			f.write(tok)
+			f.braces = append(f.braces, tqlBrace{indent: f.indent, mapBody: f.stage == "fetch"})
+			f.newline()
+			f.cont = false
+			f.stmtStart = true
+		case '}':
+			f.newline()
+			if n := len(f.braces); n > 0 {
+				f.indent = f.braces[n-1].indent
+				f.braces = f.braces[:n-1]
+				f.fixed = true
+			}
+			f.cont = false
+			f.write(tok)
+		default:
+			if f.stmtStart && len(f.braces) == 0 && f.parens == 0 {
+				if tqlBlockStages[tok] || tqlInlineStages[tok] {
+					f.newline()
+					f.inClause = false
+					f.cont = false
+					f.stage = tok
+					f.write(tok)
+					if tqlBlockStages[tok] {
+						f.newline()
+						f.inClause = true
+						f.stmtStart = true
+					}
+					continue
+				}
+			}
+			f.write(tok)
+		}
+	}
+	if f.last != 0 && f.last != ';' {
+		if f.line.Len() == 0 && len(f.lines) > 0 {
+			f.lines[len(f.lines)-1] += ";"
+		} else {
+			f.write(";")
+		}
+	}
+	f.newline()
+	return strings.Join(f.lines, "\n")
+}
+
+type tqlBrace struct {
+	indent  int  // indentation of the line holding the opening brace
+	mapBody bool // fetch braces hold comma-separated entries, not statements
+}
+
+type tqlFormatter struct {
+	lines     []string
+	line      strings.Builder // current line, without indentation
+	indent    int             // indentation level of the current line
+	fixed     bool            // indent was set explicitly for the next line
+	braces    []tqlBrace
+	parens    int
+	stage     string // current pipeline stage keyword
+	inClause  bool   // statements of a block stage are indented one level
+	cont      bool   // inside a statement continued after a comma
+	space     bool   // whitespace pending before the next token
+	stmtStart bool   // the next token starts a statement
+	last      byte   // last character written outside comments
+}
+
+func (f *tqlFormatter) write(tok string) {
+	if f.line.Len() == 0 {
+		if !f.fixed {
+			f.indent = f.lineIndent()
+		}
+		f.fixed = false
+	} else if tok[0] == '{' || ((f.space || f.last == ',') && !strings.ContainsRune(",;)]", rune(tok[0])) && f.last != '(' && f.last != '[') {
+		f.line.WriteByte(' ')
+	}
+	f.line.WriteString(tok)
+	f.space = false
+	f.stmtStart = false
+	if tok[0] != '#' {
+		f.last = tok[len(tok)-1]
+	}
+}
+
+func (f *tqlFormatter) lineIndent() int {
+	indent := 0
+	if n := len(f.braces); n > 0 {
+		indent = f.braces[n-1].indent + 1
+	} else if f.inClause {
+		indent = 1
+	}
+	if f.cont {
+		indent++
+	}
+	return indent
+}
+
+func (f *tqlFormatter) newline() {
+	if f.line.Len() == 0 {
+		return
+	}
+	f.lines = append(f.lines, strings.Repeat("  ", f.indent)+f.line.String())
+	f.line.Reset()
+	f.space = false
+}
+
+// tokenizeTQL splits query into words, string literals, comments and single
+// punctuation characters. Runs of whitespace become an empty token.
+func tokenizeTQL(query string) []string {
+	var toks []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			for i < len(query) && strings.IndexByte(" \t\n\r", query[i]) >= 0 {
+				i++
+			}
+			toks = append(toks, "")
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(query) && query[j] != c {
+				if query[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(query))
+			toks = append(toks, query[i:j])
+			i = j
+		case c == '#':
+			j := strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				j = len(query) - i
+			}
+			toks = append(toks, strings.TrimRight(query[i:i+j], " \t\r"))
+			i += j
+		case strings.IndexByte("{}()[],;", c) >= 0:
+			toks = append(toks, query[i:i+1])
+			i++
+		default:
+			j := i
+			for j < len(query) && strings.IndexByte(" \t\n\r\"'#{}()[],;", query[j]) < 0 {
+				j++
+			}
+			toks = append(toks, query[i:j])
+			i = j
+		}
+	}
+	return toks
+}
+
+// nextTQLToken returns the index of the first non-whitespace token after i,
+// or -1.
+func nextTQLToken(toks []string, i int) int {
+	for j := i + 1; j < len(toks); j++ {
+		if toks[j] != "" {
+			return j
+		}
+	}
+	return -1
+}
@@ -0,0 +1,98 @@
+package gotype
+
+import "testing"
+
+func TestFormatTQL(t *testing.T) {
+	tests := []struct {
+		name, query, want string
+	}{
+		{
+			name:  "match fetch",
+			query: "match $e isa person, has name \"Alice\";   fetch { \"name\": $e.name, \"all\": { $e.* } };",
+			want: `match
+  $e isa person,
+    has name "Alice";
+fetch {
+  "name": $e.name,
+  "all": {
+    $e.*
+  }
+};`,
+		},
+		{
+			name:  "semicolons normalized",
+			query: "match $e isa person ;; delete $e",
+			want: `match
+  $e isa person;
+delete
+  $e;`,
+		},
+		{
+			name:  "strings and relations kept intact",
+			query: "match $r isa friendship (friend: $a,friend: $b), has note \"a;  b, {c}\";",
+			want: `match
+  $r isa friendship (friend: $a, friend: $b),
+    has note "a;  b, {c}";`,
+		},
+		{
+			name:  "pattern blocks and modifiers",
+			query: "match $a isa person; { $a has age 1; } or { $a has age 2; }; not { $a has name 'x'; }; sort $a desc; offset 5; limit 10;",
+			want: `match
+  $a isa person;
+  {
+    $a has age 1;
+  } or {
+    $a has age 2;
+  };
+  not {
+    $a has name 'x';
+  };
+sort $a desc;
+offset 5;
+limit 10;`,
+		},
+		{
+			name:  "define with comment",
+			query: "define\n# people\nentity person, owns name @key;",
+			want: `define
+  # people
+  entity person,
+    owns name @key;`,
+		},
+		{
+			name:  "empty braces",
+			query: "match $x isa person; fetch { };",
+			want: `match
+  $x isa person;
+fetch {};`,
+		},
+		{name: "empty", query: "  \n ", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatTQL(tt.query)
+			if got != tt.want {
+				t.Errorf("FormatTQL:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if again := FormatTQL(got); again != got {
+				t.Errorf("FormatTQL not idempotent:\n%s\nthen:\n%s", got, again)
+			}
+		})
+	}
+}
+
+func TestFormatTQL_GeneratedQueries(t *testing.T) {
+	registerTestTypes(t)
+	mgr := MustNewManager[testPerson](NewDatabase(&mockConn{}, "test_db"))
+	query, err := mgr.Query().Filter(Eq("name", "Alice")).Limit(5).buildQuery()
+	if err != nil {
+		t.Fatalf("buildQuery: %v", err)
+	}
+
+	got := FormatTQL(query)
+	if FormatTQL(got) != got {
+		t.Errorf("FormatTQL not idempotent for generated query:\n%s", got)
+	}
+	assertContains(t, got, "match\n  $e isa test-person")
+	assertContains(t, got, "\nlimit 5;")
+}
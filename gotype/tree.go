@@ -0,0 +1,165 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Hierarchy describes a relation linking a child instance to its parent,
+// registered with WithHierarchy.
+type Hierarchy struct {
+	// ParentRole is the role played by the parent (e.g. "manager").
+	ParentRole string
+	// ChildRole is the role played by the child (e.g. "report").
+	ChildRole string
+}
+
+// validateHierarchy checks that h names two distinct roles of the relation
+// info.
+func validateHierarchy(info *ModelInfo, h *Hierarchy) error {
+	if info.Kind != ModelKindRelation {
+		return fmt.Errorf("hierarchy on entity %s", info.TypeName)
+	}
+	if h.ParentRole == h.ChildRole {
+		return fmt.Errorf("hierarchy %s: parent and child role must differ", info.TypeName)
+	}
+	for _, role := range []string{h.ParentRole, h.ChildRole} {
+		if !slices.ContainsFunc(info.Roles, func(r RoleInfo) bool { return r.RoleName == role }) {
+			return fmt.Errorf("hierarchy %s: unknown role %q", info.TypeName, role)
+		}
+	}
+	return nil
+}
+
+// Ancestors returns the parents of e, their parents and so on, nearest
+// first, following the hierarchical relation between instances of T. At most
+// maxDepth levels are walked; maxDepth <= 0 walks up to the roots. Each level
+// is one query; cycles are cut at the first repeated instance.
+func (m *Manager[T]) Ancestors(ctx context.Context, e *T, maxDepth int) ([]*T, error) {
+	return m.walkTree(ctx, "ancestors", e, maxDepth, true)
+}
+
+// Descendants returns the children of e, their children and so on, level by
+// level, following the hierarchical relation between instances of T. maxDepth
+// bounds the levels walked as for Ancestors.
+func (m *Manager[T]) Descendants(ctx context.Context, e *T, maxDepth int) ([]*T, error) {
+	return m.walkTree(ctx, "descendants", e, maxDepth, false)
+}
+
+// Roots returns the instances of T without a parent in the hierarchical
+// relation between instances of T.
+func (m *Manager[T]) Roots(ctx context.Context) ([]*T, error) {
+	if err := checkCtx(ctx, "roots", m.info.TypeName); err != nil {
+		return nil, err
+	}
+	rel, err := m.hierarchy()
+	if err != nil {
+		return nil, fmt.Errorf("roots %s: %w", m.info.TypeName, err)
+	}
+	fetch, err := buildFetchAll(m.info, "e")
+	if err != nil {
+		return nil, fmt.Errorf("roots %s: %w", m.info.TypeName, err)
+	}
+	query := fmt.Sprintf("match\n$e isa %s;\nnot { $h isa %s, links (%s: $e); };\n%s",
+		m.info.TypeName, rel.TypeName, rel.Hierarchy.ChildRole, fetch)
+	results, err := m.readQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("roots %s: %w", m.info.TypeName, err)
+	}
+	return m.hydrateResults(results)
+}
+
+// walkTree collects the instances reachable from e one level per query, up
+// to the parents when up is true and down to the children otherwise.
+func (m *Manager[T]) walkTree(ctx context.Context, op string, e *T, maxDepth int, up bool) ([]*T, error) {
+	if e == nil {
+		return nil, fmt.Errorf("%s %s: instance must not be nil", op, m.info.TypeName)
+	}
+	if err := checkCtx(ctx, op, m.info.TypeName); err != nil {
+		return nil, err
+	}
+	iid := getIIDOfInfo(e, m.info)
+	if iid == "" {
+		return nil, fmt.Errorf("%s %s: instance has no IID", op, m.info.TypeName)
+	}
+	rel, err := m.hierarchy()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", op, m.info.TypeName, err)
+	}
+	from, to := rel.Hierarchy.ChildRole, rel.Hierarchy.ParentRole
+	if !up {
+		from, to = to, from
+	}
+	fetch, err := buildFetchAll(m.info, "e")
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", op, m.info.TypeName, err)
+	}
+
+	visited := map[string]bool{iid: true}
+	frontier := []string{iid}
+	var out []*T
+	for depth := 0; len(frontier) > 0 && (maxDepth <= 0 || depth < maxDepth); depth++ {
+		query := fmt.Sprintf("match\n%s\n$h isa %s, links (%s: $f, %s: $e);\n$e isa %s;\n%s",
+			strings.Join(IIDIn(frontier...).ToPatterns("f"), "\n"), rel.TypeName, from, to, m.info.TypeName, fetch)
+		results, err := m.readQuery(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op, m.info.TypeName, err)
+		}
+		level, err := m.hydrateResults(results)
+		if err != nil {
+			return nil, err
+		}
+		frontier = frontier[:0]
+		for _, inst := range level {
+			id := getIIDOfInfo(inst, m.info)
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			frontier = append(frontier, id)
+			out = append(out, inst)
+		}
+	}
+	return out, nil
+}
+
+// hierarchy finds the relation registered WithHierarchy whose parent and
+// child are both played by T or one of its supertypes.
+func (m *Manager[T]) hierarchy() (*ModelInfo, error) {
+	reg := registryOf(m.info)
+	plays := func(rel *ModelInfo, role string) bool {
+		for _, r := range rel.Roles {
+			if r.RoleName != role {
+				continue
+			}
+			player, ok := r.playerInfo(reg)
+			for info := m.info; ok && info != nil; {
+				if info == player {
+					return true
+				}
+				info, _ = reg.Lookup(info.Supertype)
+			}
+		}
+		return false
+	}
+	var found []*ModelInfo
+	for _, info := range reg.RegisteredTypes() {
+		if h := info.Hierarchy; h != nil && plays(info, h.ParentRole) && plays(info, h.ChildRole) {
+			found = append(found, info)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no hierarchical relation between %s instances is registered", m.info.TypeName)
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, len(found))
+	for i, info := range found {
+		names[i] = info.TypeName
+	}
+	slices.Sort(names)
+	return nil, fmt.Errorf("ambiguous hierarchical relations: %s", strings.Join(names, ", "))
+}
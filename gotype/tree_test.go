@@ -0,0 +1,60 @@
+package gotype
+
+import (
+	"context"
+	"testing"
+)
+
+type testReportsTo struct {
+	BaseRelation
+	Boss   *testPerson `typedb:"role:boss"`
+	Report *testPerson `typedb:"role:report"`
+}
+
+func TestTree(t *testing.T) {
+	registerTestTypes(t)
+	if err := Register[testReportsTo](WithHierarchy("boss", "boss")); err == nil {
+		t.Error("expected error for identical roles")
+	}
+	if err := Register[testReportsTo](WithHierarchy("boss", "peer")); err == nil {
+		t.Error("expected error for unknown role")
+	}
+	ctx := context.Background()
+	mgr := MustNewManager[testPerson](NewDatabase(&mockConn{}, "test_db"))
+	intern := &testPerson{Name: "Ivy"}
+	intern.SetIID("0x1")
+	if _, err := mgr.Ancestors(ctx, intern, 0); err == nil {
+		t.Error("expected error without a hierarchical relation")
+	}
+
+	MustRegister[testReportsTo](WithHierarchy("boss", "report"))
+	// The second level repeats Ivy through a cycle and stops there.
+	level1 := &mockTx{responses: [][]map[string]any{{{"_iid": "0x2", "name": "Dev"}}}}
+	level2 := &mockTx{responses: [][]map[string]any{{{"_iid": "0x3", "name": "Ceo"}, {"_iid": "0x1", "name": "Ivy"}}}}
+	level3 := &mockTx{responses: [][]map[string]any{{}}}
+	mgr = MustNewManager[testPerson](NewDatabase(&mockConn{txs: []*mockTx{level1, level2, level3}}, "test_db"))
+	got, err := mgr.Ancestors(ctx, intern, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "Dev" || got[1].Name != "Ceo" {
+		t.Fatalf("ancestors = %+v", got)
+	}
+	assertContains(t, level1.queries[0], "$f iid 0x1;\n$h isa test-reports-to, links (report: $f, boss: $e);\n$e isa test-person;")
+	assertContains(t, level2.queries[0], "$f iid 0x2;")
+	assertContains(t, level3.queries[0], "$f iid 0x3;")
+
+	down := &mockTx{responses: [][]map[string]any{{{"_iid": "0x1", "name": "Ivy"}}}}
+	mgr = MustNewManager[testPerson](NewDatabase(&mockConn{txs: []*mockTx{down}}, "test_db"))
+	if got, err := mgr.Descendants(ctx, &testPerson{BaseEntity: BaseEntity{iid: "0x2"}}, 1); err != nil || len(got) != 1 {
+		t.Fatalf("descendants = %v, %v", got, err)
+	}
+	assertContains(t, down.queries[0], "links (boss: $f, report: $e);")
+
+	roots := &mockTx{responses: [][]map[string]any{{{"_iid": "0x3", "name": "Ceo"}}}}
+	mgr = MustNewManager[testPerson](NewDatabase(&mockConn{txs: []*mockTx{roots}}, "test_db"))
+	if got, err := mgr.Roots(ctx); err != nil || len(got) != 1 || got[0].Name != "Ceo" {
+		t.Fatalf("roots = %v, %v", got, err)
+	}
+	assertContains(t, roots.queries[0], "$e isa test-person;\nnot { $h isa test-reports-to, links (report: $e); };")
+}
@@ -0,0 +1,90 @@
+package gotype
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AttrValuer is implemented by field types that convert themselves into a
+// value TypeDB understands (string, integer, double, boolean or time.Time).
+// It is the write-side counterpart of AttrScanner, e.g. a struct stored as a
+// JSON-encoded string attribute.
+type AttrValuer interface {
+	AttrValue() (any, error)
+}
+
+// AttrScanner is implemented by pointers to field types that populate
+// themselves from a raw attribute value returned by TypeDB.
+type AttrScanner interface {
+	ScanAttr(value any) error
+}
+
+var (
+	attrValuerType  = reflect.TypeFor[AttrValuer]()
+	attrScannerType = reflect.TypeFor[AttrScanner]()
+)
+
+// isAttrCodec reports whether t (or *t) implements AttrValuer or AttrScanner.
+// Such types are treated as a single attribute value regardless of their kind.
+func isAttrCodec(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(attrValuerType) || pt.Implements(attrScannerType)
+}
+
+// isAttrScanner reports whether *t implements AttrScanner.
+func isAttrScanner(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(attrScannerType)
+}
+
+// attrValueOf returns the value to send to TypeDB for rv, delegating to
+// AttrValuer when the type implements it.
+func attrValueOf(rv reflect.Value) (any, error) {
+	if rv.CanAddr() {
+		if v, ok := rv.Addr().Interface().(AttrValuer); ok {
+			return v.AttrValue()
+		}
+	}
+	val := rv.Interface()
+	if v, ok := val.(AttrValuer); ok {
+		return v.AttrValue()
+	}
+	return val, nil
+}
+
+// scanAttrValue populates the addressable target from a raw attribute value.
+func scanAttrValue(target reflect.Value, val any) error {
+	return target.Addr().Interface().(AttrScanner).ScanAttr(val)
+}
+
+// scanFieldValue hydrates a field whose type implements AttrScanner.
+func scanFieldValue(field reflect.Value, fi *FieldInfo, val any) error {
+	if fi.IsSlice {
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Slice {
+			slice := reflect.MakeSlice(fi.FieldType, 1, 1)
+			if err := scanAttrValue(slice.Index(0), val); err != nil {
+				return err
+			}
+			field.Set(slice)
+			return nil
+		}
+		slice := reflect.MakeSlice(fi.FieldType, rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			if err := scanAttrValue(slice.Index(i), rv.Index(i).Interface()); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	if fi.IsPointer {
+		ptr := reflect.New(fi.ElemType)
+		if err := scanAttrValue(ptr.Elem(), val); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	return scanAttrValue(field, val)
+}
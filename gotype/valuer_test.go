@@ -0,0 +1,187 @@
+package gotype
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testPrefs struct {
+	Theme string   `json:"theme"`
+	Tags  []string `json:"tags"`
+}
+
+func (p testPrefs) AttrValue() (any, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (p *testPrefs) ScanAttr(value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("prefs: expected string, got %T", value)
+	}
+	return json.Unmarshal([]byte(s), p)
+}
+
+type testCodecUser struct {
+	BaseEntity
+	Name     string     `typedb:"name,key"`
+	Prefs    testPrefs  `typedb:"prefs"`
+	OldPrefs *testPrefs `typedb:"old-prefs"`
+}
+
+type testFailingValue struct{}
+
+func (testFailingValue) AttrValue() (any, error) { return nil, errors.New("boom") }
+
+type testFailingUser struct {
+	BaseEntity
+	Name  string           `typedb:"name,key"`
+	Value testFailingValue `typedb:"payload"`
+}
+
+func TestAttrCodec_FieldInfo(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testCodecUser]()
+
+	info, _ := Lookup("test-codec-user")
+	for _, fi := range info.Fields {
+		if fi.Tag.Name != "prefs" && fi.Tag.Name != "old-prefs" {
+			continue
+		}
+		if fi.ValueType != "string" {
+			t.Errorf("%s: ValueType = %q, want string", fi.Tag.Name, fi.ValueType)
+		}
+		if fi.IsSlice {
+			t.Errorf("%s: codec field must not be treated as a slice", fi.Tag.Name)
+		}
+		if !fi.scanner {
+			t.Errorf("%s: expected scanner flag", fi.Tag.Name)
+		}
+	}
+}
+
+func TestAttrCodec_Hydrate(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testCodecUser]()
+
+	u, err := HydrateNew[testCodecUser](map[string]any{
+		"name":      "alice",
+		"prefs":     `{"theme":"dark","tags":["a","b"]}`,
+		"old-prefs": `{"theme":"light"}`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u.Prefs.Theme != "dark" || len(u.Prefs.Tags) != 2 {
+		t.Errorf("Prefs = %+v", u.Prefs)
+	}
+	if u.OldPrefs == nil || u.OldPrefs.Theme != "light" {
+		t.Errorf("OldPrefs = %+v", u.OldPrefs)
+	}
+}
+
+func TestAttrCodec_HydrateScanError(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testCodecUser]()
+
+	_, err := HydrateNew[testCodecUser](map[string]any{"name": "alice", "prefs": 42})
+	if err == nil || !strings.Contains(err.Error(), "prefs: expected string") {
+		t.Fatalf("expected scan error, got %v", err)
+	}
+}
+
+func TestAttrCodec_InsertQuery(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testCodecUser]()
+
+	q, err := ToInsertQuery(&testCodecUser{Name: "alice", Prefs: testPrefs{Theme: "dark"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `has prefs "{\"theme\":\"dark\",\"tags\":null}"`
+	if !strings.Contains(q, want) {
+		t.Errorf("query missing %s:\n%s", want, q)
+	}
+	if strings.Contains(q, "old-prefs") {
+		t.Errorf("nil optional codec field should be omitted:\n%s", q)
+	}
+}
+
+func TestAttrCodec_InsertQueryValueError(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testFailingUser]()
+
+	_, err := ToInsertQuery(&testFailingUser{Name: "x"})
+	if err == nil || !strings.Contains(err.Error(), "field Value: boom") {
+		t.Fatalf("expected valuer error, got %v", err)
+	}
+}
+
+func TestAttrCodec_BuilderValueErrors(t *testing.T) {
+	registerTestTypes(t)
+	ctx := context.Background()
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx, tx, tx, tx, tx}}, "test_db")
+	mgr := MustNewManager[testPerson](db)
+	bad := testFailingValue{}
+
+	checks := map[string]func() error{
+		"filter": func() error {
+			_, err := mgr.Query().Filter(Eq("name", bad)).Execute(ctx)
+			return err
+		},
+		"nested in filter": func() error {
+			_, err := mgr.Query().Filter(Or(In("name", []any{"a", bad}))).Count(ctx)
+			return err
+		},
+		"aggregate filter": func() error {
+			_, err := mgr.Query().Filter(Not(Eq("name", bad))).Sum("age").Execute(ctx)
+			return err
+		},
+		"get": func() error {
+			_, err := mgr.Get(ctx, map[string]any{"name": bad})
+			return err
+		},
+		"bulk update": func() error {
+			_, err := mgr.Query().Update(ctx, map[string]any{"email": bad})
+			return err
+		},
+	}
+	for name, run := range checks {
+		if err := run(); err == nil || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("%s: expected valuer error, got %v", name, err)
+		}
+	}
+	if len(tx.queries) != 0 {
+		t.Errorf("no query should run with an unconvertible value, got %v", tx.queries)
+	}
+}
+
+func TestAttrCodec_FormatValue(t *testing.T) {
+	got := FormatValue(testPrefs{Theme: "dark"})
+	want := `"{\"theme\":\"dark\",\"tags\":null}"`
+	if got != want {
+		t.Errorf("FormatValue = %s, want %s", got, want)
+	}
+}
+
+func TestAttrCodec_ToDict(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testCodecUser]()
+
+	d, err := ToDict(&testCodecUser{Name: "alice", Prefs: testPrefs{Theme: "dark"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d["prefs"] != `{"theme":"dark","tags":null}` {
+		t.Errorf("prefs = %v", d["prefs"])
+	}
+}
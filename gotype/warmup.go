@@ -0,0 +1,87 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Warmup prepares db for traffic so the first user-facing request does not
+// absorb cold-start costs. For each model, named by TypeDB type name, it
+// compiles and caches the query skeletons that are built lazily (such as
+// the polymorphic fetch), then opens one read transaction and runs a
+// limit-1 fetch per model so the connection and the server's query
+// planner are warm. With no models it warms every type registered in
+// db.Registry().
+func (db *Database) Warmup(ctx context.Context, models ...string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("warmup: context cancelled: %w", err)
+	}
+	infos, err := db.warmupModels(models)
+	if err != nil {
+		return fmt.Errorf("warmup: %w", err)
+	}
+	if len(infos) == 0 {
+		return nil
+	}
+
+	queries := make([]string, 0, len(infos))
+	for _, info := range infos {
+		query, err := warmupQuery(info)
+		if err != nil {
+			return fmt.Errorf("warmup %s: %w", info.TypeName, err)
+		}
+		queries = append(queries, query)
+	}
+
+	tx, err := db.openTransaction(ctx, ReadTransaction)
+	if err != nil {
+		return fmt.Errorf("warmup: open read transaction: %w", err)
+	}
+	defer tx.Close()
+	for i, query := range queries {
+		if _, err := tx.QueryWithContext(ctx, query); err != nil {
+			return fmt.Errorf("warmup %s: %w", infos[i].TypeName, err)
+		}
+	}
+	return nil
+}
+
+// warmupModels resolves the type names passed to Warmup, defaulting to every
+// registered type in type name order.
+func (db *Database) warmupModels(models []string) ([]*ModelInfo, error) {
+	reg := db.Registry()
+	if len(models) == 0 {
+		infos := reg.RegisteredTypes()
+		slices.SortFunc(infos, func(a, b *ModelInfo) int { return strings.Compare(a.TypeName, b.TypeName) })
+		return infos, nil
+	}
+	infos := make([]*ModelInfo, 0, len(models))
+	for _, name := range models {
+		info, ok := reg.Lookup(name)
+		if !ok {
+			return nil, &NotRegisteredError{TypeName: name}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// warmupQuery caches the lazily built skeletons of info and returns the
+// limit-1 fetch run against it.
+func warmupQuery(info *ModelInfo) (string, error) {
+	if _, err := buildPolymorphicFetch(info, templateVar); err != nil {
+		return "", err
+	}
+	s := strategyFor(info.Kind)
+	match, err := s.BuildMatchAll(info, templateVar)
+	if err != nil {
+		return "", err
+	}
+	fetch, err := s.BuildFetchAll(info, templateVar)
+	if err != nil {
+		return "", err
+	}
+	return match + "\nlimit 1;\n" + fetch, nil
+}
@@ -0,0 +1,51 @@
+package gotype
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDatabase_Warmup(t *testing.T) {
+	reg := NewRegistry()
+	MustRegisterIn[testPerson](reg)
+	MustRegisterIn[testCompany](reg)
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db").WithRegistry(reg)
+
+	if err := db.Warmup(context.Background()); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	if len(tx.queries) != 2 || !tx.closed || tx.committed {
+		t.Fatalf("queries = %q, closed = %v, committed = %v", tx.queries, tx.closed, tx.committed)
+	}
+	for i, typeName := range []string{"test-company", "test-person"} {
+		assertContains(t, tx.queries[i], "$e isa "+typeName+";\nlimit 1;\n")
+		assertContains(t, tx.queries[i], "fetch {")
+	}
+	info, _ := reg.Lookup("test-person")
+	if p := info.templates.poly.Load(); p == nil || p.gen != reg.gen.Load() {
+		t.Error("polymorphic fetch not cached")
+	}
+}
+
+func TestDatabase_WarmupModels(t *testing.T) {
+	reg := NewRegistry()
+	MustRegisterIn[testPerson](reg)
+	MustRegisterIn[testCompany](reg)
+	tx := &mockTx{}
+	db := NewDatabase(&mockConn{txs: []*mockTx{tx}}, "test_db").WithRegistry(reg)
+
+	if err := db.Warmup(context.Background(), "test-person"); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	if len(tx.queries) != 1 || !strings.Contains(tx.queries[0], "isa test-person;") {
+		t.Errorf("queries = %q", tx.queries)
+	}
+
+	var nre *NotRegisteredError
+	if err := db.Warmup(context.Background(), "ghost"); !errors.As(err, &nre) || nre.TypeName != "ghost" {
+		t.Errorf("err = %v", err)
+	}
+}
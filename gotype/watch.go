@@ -0,0 +1,270 @@
+package gotype
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ChangeKind classifies a ChangeEvent.
+type ChangeKind int
+
+const (
+	// ChangeCreated reports an instance that entered the watched set.
+	ChangeCreated ChangeKind = iota
+	// ChangeUpdated reports an instance whose watched version changed.
+	ChangeUpdated
+	// ChangeDeleted reports an instance that left the watched set, because it
+	// was deleted or no longer matches the filters.
+	ChangeDeleted
+)
+
+// String returns "created", "updated" or "deleted".
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeCreated:
+		return "created"
+	case ChangeUpdated:
+		return "updated"
+	case ChangeDeleted:
+		return "deleted"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// ChangeEvent is one change observed by Watch.
+type ChangeEvent[T any] struct {
+	Kind ChangeKind
+	IID  string
+	// Instance is the instance as read by the poll that saw the change. For
+	// ChangeDeleted it is the last version Watch read.
+	Instance *T
+}
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	// Interval is the time between polls (default 1s).
+	Interval time.Duration
+	// UpdatedAt names the attribute compared between polls to detect
+	// updates. It defaults to the model's autoupdate field. Without one,
+	// every poll reads the full instances and compares all their attributes.
+	UpdatedAt string
+	// Filters restricts the watched instances.
+	Filters []Filter
+	// EmitExisting reports the instances present at the first poll as
+	// ChangeCreated. By default they form the baseline silently.
+	EmitExisting bool
+	// Buffer is the capacity of the returned channel.
+	Buffer int
+}
+
+// Watch polls the instances of T and sends a ChangeEvent for each instance
+// created, updated or deleted between polls, for cache invalidation and sync
+// jobs while TypeDB has no change streams. The channel is closed once ctx is
+// done. Poll errors are logged through the Database's logger (WithLogger) and
+// the next poll retries. Watch reads bypass the shared cache (WithSharedCache).
+//
+// When an updated-at attribute is available each poll reads only IIDs and
+// versions, then fetches the changed instances; updates that leave the
+// attribute unchanged are missed. Changes that happen and revert between two
+// polls are never seen.
+func Watch[T any](ctx context.Context, db *Database, opts WatchOptions) (<-chan ChangeEvent[T], error) {
+	mgr, err := NewManager[T](db.withoutCache())
+	if err != nil {
+		return nil, err
+	}
+	info := mgr.info
+	if opts.UpdatedAt == "" {
+		for _, fi := range info.Fields {
+			if fi.Tag.AutoUpdate {
+				opts.UpdatedAt = fi.Tag.Name
+				break
+			}
+		}
+	} else if _, ok := info.FieldByAttrName(opts.UpdatedAt); !ok {
+		return nil, fmt.Errorf("watch %s: no attribute %q", info.TypeName, opts.UpdatedAt)
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+
+	w := &watcher[T]{mgr: mgr, opts: opts, out: make(chan ChangeEvent[T], max(opts.Buffer, 0))}
+	go w.run(ctx)
+	return w.out, nil
+}
+
+type watchEntry[T any] struct {
+	version  string
+	instance *T
+}
+
+type watcher[T any] struct {
+	mgr  *Manager[T]
+	opts WatchOptions
+	out  chan ChangeEvent[T]
+	// seen holds the state of the last successful poll; nil before the
+	// first one.
+	seen map[string]watchEntry[T]
+}
+
+func (w *watcher[T]) run(ctx context.Context) {
+	defer close(w.out)
+	for {
+		if err := w.poll(ctx); err != nil && ctx.Err() == nil && w.mgr.db.logger != nil {
+			w.mgr.db.logger.ErrorContext(ctx, "watch poll failed", "type", w.mgr.info.TypeName, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.opts.Interval):
+		}
+	}
+}
+
+// poll reads the current state, diffs it against seen and sends the
+// events. seen only advances once all events are sent.
+func (w *watcher[T]) poll(ctx context.Context) error {
+	var (
+		current map[string]watchEntry[T]
+		err     error
+	)
+	if w.opts.UpdatedAt == "" || w.seen == nil {
+		current, err = w.readAll(ctx)
+	} else {
+		current, err = w.readVersions(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	var events []ChangeEvent[T]
+	if w.seen == nil {
+		if w.opts.EmitExisting {
+			for iid, e := range current {
+				events = append(events, ChangeEvent[T]{Kind: ChangeCreated, IID: iid, Instance: e.instance})
+			}
+		}
+	} else {
+		var changed []string
+		for iid, e := range current {
+			if old, ok := w.seen[iid]; !ok || old.version != e.version {
+				changed = append(changed, iid)
+			}
+		}
+		if w.opts.UpdatedAt != "" && len(changed) > 0 {
+			if err := w.loadInstances(ctx, current, changed); err != nil {
+				return err
+			}
+		}
+		for _, iid := range changed {
+			kind := ChangeUpdated
+			if _, ok := w.seen[iid]; !ok {
+				kind = ChangeCreated
+			}
+			events = append(events, ChangeEvent[T]{Kind: kind, IID: iid, Instance: current[iid].instance})
+		}
+		for iid, old := range w.seen {
+			if _, ok := current[iid]; !ok {
+				events = append(events, ChangeEvent[T]{Kind: ChangeDeleted, IID: iid, Instance: old.instance})
+			}
+		}
+	}
+
+	for _, ev := range events {
+		select {
+		case w.out <- ev:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	w.seen = current
+	return nil
+}
+
+// readAll hydrates every watched instance. The version is the instance's
+// updated-at attribute if there is one, otherwise all its attributes.
+func (w *watcher[T]) readAll(ctx context.Context) (map[string]watchEntry[T], error) {
+	instances, err := w.mgr.Query().Filter(w.opts.Filters...).Execute(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("watch %s: %w", w.mgr.info.TypeName, err)
+	}
+	current := make(map[string]watchEntry[T], len(instances))
+	for _, inst := range instances {
+		data, err := w.mgr.ToDict(inst)
+		if err != nil {
+			return nil, fmt.Errorf("watch %s: %w", w.mgr.info.TypeName, err)
+		}
+		version := fmt.Sprint(data)
+		if w.opts.UpdatedAt != "" {
+			version = watchVersion(data[w.opts.UpdatedAt])
+		}
+		current[getIIDOfInfo(inst, w.mgr.info)] = watchEntry[T]{version: version, instance: inst}
+	}
+	return current, nil
+}
+
+// readVersions reads the IID and updated-at value of every watched instance.
+// Instances unchanged since the last poll keep their hydrated value.
+func (w *watcher[T]) readVersions(ctx context.Context) (map[string]watchEntry[T], error) {
+	q := w.mgr.Query().Filter(w.opts.Filters...)
+	match, err := q.buildMatchClause()
+	if err != nil {
+		return nil, fmt.Errorf("watch %s: %w", w.mgr.info.TypeName, err)
+	}
+	query := fmt.Sprintf("%s\nfetch {\n  \"_iid\": iid($e),\n  \"version\": $e.%s\n};", match, w.opts.UpdatedAt)
+	results, err := w.mgr.readQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("watch %s: %w", w.mgr.info.TypeName, err)
+	}
+	current := make(map[string]watchEntry[T], len(results))
+	for _, row := range results {
+		iid := extractIID(row)
+		version := watchVersion(unwrapResult(row)["version"])
+		e := watchEntry[T]{version: version}
+		if old, ok := w.seen[iid]; ok && old.version == version {
+			e.instance = old.instance
+		}
+		current[iid] = e
+	}
+	return current, nil
+}
+
+// loadInstances hydrates the instances of iids into current.
+func (w *watcher[T]) loadInstances(ctx context.Context, current map[string]watchEntry[T], iids []string) error {
+	instances, err := w.mgr.Query().Filter(IIDIn(iids...)).Execute(ctx)
+	if err != nil {
+		return fmt.Errorf("watch %s: load changed: %w", w.mgr.info.TypeName, err)
+	}
+	for _, inst := range instances {
+		iid := getIIDOfInfo(inst, w.mgr.info)
+		if e, ok := current[iid]; ok {
+			e.instance = inst
+			current[iid] = e
+		}
+	}
+	return nil
+}
+
+// watchVersion normalizes an updated-at value so that hydrated and fetched
+// values of the same instant compare equal.
+func watchVersion(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return FormatValue(v.UTC())
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return FormatValue(v.UTC())
+	case string:
+		if t, err := ParseAttributeValue("datetime", v); err == nil {
+			return FormatValue(t.(time.Time).UTC())
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,118 @@
+package gotype
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// collectChanges reads n events from ch, then cancels the watch and drains
+// it. Events are sorted by IID since one poll reports them in map order.
+func collectChanges[T any](t *testing.T, cancel context.CancelFunc, ch <-chan ChangeEvent[T], n int) []ChangeEvent[T] {
+	t.Helper()
+	var got []ChangeEvent[T]
+	timeout := time.After(2 * time.Second)
+	for len(got) < n {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				t.Fatalf("channel closed after %d events", len(got))
+			}
+			got = append(got, ev)
+		case <-timeout:
+			t.Fatalf("timed out after %d events", len(got))
+		}
+	}
+	cancel()
+	for range ch {
+	}
+	slices.SortFunc(got, func(a, b ChangeEvent[T]) int { return strings.Compare(a.IID, b.IID) })
+	return got
+}
+
+func TestWatch_UpdatedAt(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testStamped]()
+	baseline := &mockTx{responses: [][]map[string]any{{
+		{"_iid": "0x1", "name": "a", "updated-at": "2025-01-01T00:00:00"},
+		{"_iid": "0x2", "name": "b", "updated-at": "2025-01-01T00:00:00"},
+	}}}
+	versions := &mockTx{responses: [][]map[string]any{{
+		{"_iid": "0x2", "version": map[string]any{"value": "2025-01-02T00:00:00"}},
+		{"_iid": "0x3", "version": "2025-01-02T00:00:00"},
+	}}}
+	load := &mockTx{responses: [][]map[string]any{{
+		{"_iid": "0x2", "name": "b2", "updated-at": "2025-01-02T00:00:00"},
+		{"_iid": "0x3", "name": "c", "updated-at": "2025-01-02T00:00:00"},
+	}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{baseline, versions, load}}, "test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := Watch[testStamped](ctx, db, WatchOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collectChanges(t, cancel, ch, 3)
+
+	want := []struct {
+		kind ChangeKind
+		iid  string
+		name string
+	}{{ChangeDeleted, "0x1", "a"}, {ChangeUpdated, "0x2", "b2"}, {ChangeCreated, "0x3", "c"}}
+	for i, w := range want {
+		ev := got[i]
+		if ev.Kind != w.kind || ev.IID != w.iid || ev.Instance == nil || ev.Instance.Name != w.name {
+			t.Errorf("event %d = %v %s %+v, want %v %s %s", i, ev.Kind, ev.IID, ev.Instance, w.kind, w.iid, w.name)
+		}
+	}
+	assertContains(t, versions.queries[0], `"version": $e.updated-at`)
+	assertContains(t, load.queries[0], "iid 0x2")
+	assertContains(t, load.queries[0], "iid 0x3")
+}
+
+func TestWatch_CompareAttributes(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testPerson]()
+	first := &mockTx{responses: [][]map[string]any{{
+		{"_iid": "0x1", "name": "a", "email": "a@x"},
+		{"_iid": "0x2", "name": "b", "email": "b@x"},
+	}}}
+	second := &mockTx{responses: [][]map[string]any{{
+		{"_iid": "0x1", "name": "a", "email": "a@x"},
+		{"_iid": "0x2", "name": "b", "email": "b@y"},
+	}}}
+	db := NewDatabase(&mockConn{txs: []*mockTx{first, second}}, "test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := Watch[testPerson](ctx, db, WatchOptions{
+		Interval:     time.Millisecond,
+		Filters:      []Filter{HasAttr("email")},
+		EmitExisting: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collectChanges(t, cancel, ch, 3)
+
+	kinds := []ChangeKind{got[0].Kind, got[1].Kind, got[2].Kind}
+	if !slices.Equal(kinds, []ChangeKind{ChangeCreated, ChangeCreated, ChangeUpdated}) {
+		t.Errorf("kinds = %v", kinds)
+	}
+	if got[2].IID != "0x2" || got[2].Instance.Email != "b@y" {
+		t.Errorf("update = %s %+v", got[2].IID, got[2].Instance)
+	}
+	assertContains(t, first.queries[0], "has email")
+}
+
+func TestWatch_UnknownAttribute(t *testing.T) {
+	ClearRegistry()
+	MustRegister[testPerson]()
+	db := NewDatabase(&mockConn{}, "test")
+	if _, err := Watch[testPerson](context.Background(), db, WatchOptions{UpdatedAt: "modified"}); err == nil {
+		t.Error("expected error for unknown attribute")
+	}
+}
@@ -0,0 +1,295 @@
+// Package yaml parses the subset of YAML the module's configuration and
+// fixture files use, so that the module needs no YAML dependency.
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses the block-style YAML subset of tqlgen.yaml and test
+// fixtures: nested mappings and sequences, flow sequences of scalars,
+// quoted and plain scalars, and comments. Anchors, tags, flow mappings and
+// multi-line scalars are rejected. Values are returned as map[string]any,
+// []any, string, int64, float64, bool or nil.
+func Parse(data []byte) (any, error) {
+	var p parser
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", i+1)
+		}
+		indent := len(raw) - len(text)
+		text = stripComment(text)
+		if text == "" || text == "---" {
+			continue
+		}
+		p.lines = append(p.lines, line{num: i + 1, indent: indent, text: text})
+	}
+	if len(p.lines) == 0 {
+		return map[string]any{}, nil
+	}
+	v, err := p.block(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	return v, nil
+}
+
+type line struct {
+	num, indent int
+	text        string
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+// block parses the mapping or sequence starting at the current line.
+func (p *parser) block(indent int) (any, error) {
+	if isItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *parser) mapping(indent int) (any, error) {
+	m := make(map[string]any)
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+		}
+		if isItem(l.text) {
+			return nil, fmt.Errorf("line %d: unexpected list item", l.num)
+		}
+		key, rest, ok := splitKey(l.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: want key: value", l.num)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", l.num, key)
+		}
+		p.pos++
+		v, err := p.value(rest, l, true)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+func (p *parser) sequence(indent int) (any, error) {
+	items := []any{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent || (l.indent == indent && !isItem(l.text)) {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		if _, _, ok := splitKey(rest); ok {
+			// "- key: value" starts a mapping whose keys line up with "key".
+			p.lines[p.pos] = line{num: l.num, indent: l.indent + len(l.text) - len(rest), text: rest}
+			v, err := p.mapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+			continue
+		}
+		p.pos++
+		v, err := p.value(rest, l, false)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// value parses the text after "key:" or "-": a scalar, or when empty the
+// nested block on the following lines. A mapping value may also be a
+// sequence at the key's own indentation.
+func (p *parser) value(text string, l line, inMapping bool) (any, error) {
+	if text != "" {
+		return scalar(text, l.num)
+	}
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > l.indent || (inMapping && next.indent == l.indent && isItem(next.text)) {
+			return p.block(next.indent)
+		}
+	}
+	return nil, nil
+}
+
+func isItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits "key: value" or "key:". Keys may be quoted.
+func splitKey(text string) (key, rest string, ok bool) {
+	var after string
+	switch {
+	case text == "":
+		return "", "", false
+	case text[0] == '"' || text[0] == '\'':
+		end := closingQuote(text)
+		if end < 0 {
+			return "", "", false
+		}
+		k, err := scalar(text[:end+1], 0)
+		if err != nil {
+			return "", "", false
+		}
+		key, after = k.(string), text[end+1:]
+		if !strings.HasPrefix(after, ":") {
+			return "", "", false
+		}
+		after = after[1:]
+	case strings.ContainsRune("[{&*!|>", rune(text[0])):
+		return "", "", false
+	default:
+		i := strings.Index(text, ": ")
+		if i < 0 {
+			if !strings.HasSuffix(text, ":") {
+				return "", "", false
+			}
+			i = len(text) - 1
+		}
+		key, after = strings.TrimRight(text[:i], " "), text[i+1:]
+	}
+	if after != "" && after[0] != ' ' {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(after), true
+}
+
+// closingQuote returns the index of the quote closing the string that
+// starts text, or -1.
+func closingQuote(text string) int {
+	q := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case q == '"' && text[i] == '\\':
+			i++
+		case text[i] == q && q == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes a trailing "# comment" outside quotes.
+func stripComment(text string) string {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			end := closingQuote(text[i:])
+			if end < 0 {
+				return text
+			}
+			i += end
+		case '#':
+			if i == 0 || text[i-1] == ' ' {
+				return strings.TrimRight(text[:i], " ")
+			}
+		}
+	}
+	return text
+}
+
+func scalar(text string, num int) (any, error) {
+	switch text[0] {
+	case '"':
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid string %s", num, text)
+		}
+		return s, nil
+	case '\'':
+		if len(text) < 2 || closingQuote(text) != len(text)-1 {
+			return nil, fmt.Errorf("line %d: invalid string %s", num, text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case '[':
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("line %d: unterminated list %s", num, text)
+		}
+		items := []any{}
+		inner := strings.TrimSpace(text[1 : len(text)-1])
+		if inner == "" {
+			return items, nil
+		}
+		for _, part := range splitFlowItems(inner) {
+			part = strings.TrimSpace(part)
+			if part == "" || strings.ContainsAny(part[:1], "[{") {
+				return nil, fmt.Errorf("line %d: unsupported list item %q", num, part)
+			}
+			v, err := scalar(part, num)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case '{':
+		if text == "{}" {
+			return map[string]any{}, nil
+		}
+		return nil, fmt.Errorf("line %d: flow mappings are not supported", num)
+	case '&', '*', '!', '|', '>':
+		return nil, fmt.Errorf("line %d: unsupported YAML syntax %q", num, text)
+	}
+	switch text {
+	case "null", "~":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n, nil
+	}
+	if c := text[0]; c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.' {
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, nil
+		}
+	}
+	return text, nil
+}
+
+// splitFlowItems splits the inside of a flow sequence at commas outside
+// quotes.
+func splitFlowItems(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			if end := closingQuote(s[i:]); end >= 0 {
+				i += end
+			}
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,67 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	input := `# generation config
+schema: schema.tql   # relative to this file
+package: "models"
+acronyms: false
+version: 3
+ratio: 0.5
+empty:
+tags: [a, 'b c', "d,e"]
+targets:
+  - kind: models
+    out: models_gen.go
+  - kind: dto
+    exclude:
+    - audit-log
+    - 'it''s'
+list:
+- x
+- 2
+`
+	got, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]any{
+		"schema":   "schema.tql",
+		"package":  "models",
+		"acronyms": false,
+		"version":  int64(3),
+		"ratio":    0.5,
+		"empty":    nil,
+		"tags":     []any{"a", "b c", "d,e"},
+		"targets": []any{
+			map[string]any{"kind": "models", "out": "models_gen.go"},
+			map[string]any{"kind": "dto", "exclude": []any{"audit-log", "it's"}},
+		},
+		"list": []any{"x", int64(2)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestParseYAML_Errors(t *testing.T) {
+	for _, tt := range []struct{ input, want string }{
+		{"a: 1\n  b: 2\n", "line 2: unexpected indentation"},
+		{"a: 1\na: 2\n", `line 2: duplicate key "a"`},
+		{"just text\n", "line 1: want key: value"},
+		{"a: {b: 1}\n", "flow mappings are not supported"},
+		{"a: |\n  text\n", "unsupported YAML syntax"},
+		{"a:\n\t- b\n", "tabs are not allowed"},
+		{"a: \"open\n", "invalid string"},
+	} {
+		_, err := Parse([]byte(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: err = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
@@ -33,6 +33,9 @@ func main() {
 	skipRelOut := flag.Bool("skip-relation-out", false, "Skip generating relation Out structs")
 	typedConsts := flag.Bool("typed-constants", false, "Generate typed string constants (EntityType, RelationType)")
 	jsonSchema := flag.Bool("json-schema", false, "Generate JSON schema fragment maps for OpenAPI/LLM use")
+	queries := flag.Bool("queries", false, "Emit precompiled TypeQL constants (fetch-all, count, match-by-key) per type")
+	fieldNames := flag.Bool("field-names", true, "Emit a <Type>Fields variable of attribute names per type")
+	naming := flag.String("naming", "kebab", "Schema naming strategy: kebab, snake, kebab-prefixed or snake-prefixed")
 
 	flag.Parse()
 
@@ -47,6 +50,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	namingStrategy, err := tqlgen.ParseNamingStrategy(*naming)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
 	schema, err := tqlgen.ParseSchemaFile(*schemaFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -114,6 +123,9 @@ func main() {
 			SkipAbstract:  *skipAbstract,
 			SchemaVersion: *versionStr,
 			Enums:         *enums,
+			Queries:       *queries,
+			FieldNames:    *fieldNames,
+			Naming:        namingStrategy,
 		}
 		if err := tqlgen.Render(w, schema, cfg); err != nil {
 			fmt.Fprintf(os.Stderr, "error rendering: %v\n", err)
@@ -0,0 +1,181 @@
+package tqlgen
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// DocsConfig specifies the settings for generating Markdown documentation
+// from a TypeQL schema.
+type DocsConfig struct {
+	// Title is the top-level heading (default "Schema").
+	Title string
+	// SkipAbstract excludes abstract entity and relation types.
+	SkipAbstract bool
+}
+
+// RenderDocs writes a Markdown reference of the schema: a table of attribute
+// types, then one section per entity and relation type listing what it owns,
+// plays and relates, with @doc annotations as descriptions. Types are
+// sorted by name so the output only changes when the schema does.
+func RenderDocs(w io.Writer, schema *ParsedSchema, cfg DocsConfig) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!-- Code generated by tqlgen. DO NOT EDIT. -->\n\n# %s\n", cmp.Or(cfg.Title, "Schema"))
+
+	if len(schema.Attributes) > 0 {
+		b.WriteString("\n## Attributes\n\n| Attribute | Value type | Constraints | Description |\n| --- | --- | --- | --- |\n")
+		attrs := slices.SortedFunc(slices.Values(schema.Attributes), func(a, b AttributeSpec) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		for _, a := range attrs {
+			var constraints []string
+			if len(a.Values) > 0 {
+				constraints = append(constraints, "one of "+strings.Join(quoteAll(a.Values), ", "))
+			}
+			if a.Regex != "" {
+				constraints = append(constraints, "matches `"+a.Regex+"`")
+			}
+			if a.RangeOp != "" {
+				constraints = append(constraints, "range "+a.RangeOp)
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", a.Name, a.ValueType,
+				docsCell(strings.Join(constraints, "; ")), docsCell(a.Doc))
+		}
+	}
+
+	var entities []EntitySpec
+	for _, e := range schema.Entities {
+		if !cfg.SkipAbstract || !e.Abstract {
+			entities = append(entities, e)
+		}
+	}
+	if len(entities) > 0 {
+		b.WriteString("\n## Entities\n")
+		slices.SortFunc(entities, func(a, b EntitySpec) int { return strings.Compare(a.Name, b.Name) })
+		for _, e := range entities {
+			docsTypeHeader(&b, e.Name, e.Parent, e.Abstract, e.Doc)
+			docsOwns(&b, e.Owns)
+			docsPlays(&b, e.Plays)
+		}
+	}
+
+	var relations []RelationSpec
+	for _, r := range schema.Relations {
+		if !cfg.SkipAbstract || !r.Abstract {
+			relations = append(relations, r)
+		}
+	}
+	if len(relations) > 0 {
+		b.WriteString("\n## Relations\n")
+		slices.SortFunc(relations, func(a, b RelationSpec) int { return strings.Compare(a.Name, b.Name) })
+		for _, r := range relations {
+			docsTypeHeader(&b, r.Name, r.Parent, r.Abstract, r.Doc)
+			if len(r.Relates) > 0 {
+				b.WriteString("\nRoles:\n\n")
+				for _, rel := range r.Relates {
+					line := "`" + rel.Role + "`"
+					if rel.AsParent != "" {
+						line += " (as `" + rel.AsParent + "`)"
+					}
+					if rel.Card != "" {
+						line += " @card(" + rel.Card + ")"
+					}
+					docsItem(&b, line, rel.Doc)
+				}
+			}
+			docsOwns(&b, r.Owns)
+			docsPlays(&b, r.Plays)
+		}
+	}
+
+	if len(schema.Functions) > 0 {
+		b.WriteString("\n## Functions\n")
+		funcs := slices.SortedFunc(slices.Values(schema.Functions), func(a, b FunctionSpec) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		for _, f := range funcs {
+			params := make([]string, len(f.Parameters))
+			for i, p := range f.Parameters {
+				params[i] = "$" + p.Name + ": " + p.TypeName
+			}
+			fmt.Fprintf(&b, "\n### %s\n\n`%s(%s) -> %s`\n", f.Name, f.Name, strings.Join(params, ", "), f.ReturnType)
+			if f.Doc != "" {
+				fmt.Fprintf(&b, "\n%s\n", f.Doc)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func docsTypeHeader(b *strings.Builder, name, parent string, abstract bool, doc string) {
+	fmt.Fprintf(b, "\n### %s\n", name)
+	var notes []string
+	if abstract {
+		notes = append(notes, "Abstract.")
+	}
+	if parent != "" {
+		notes = append(notes, "Subtype of `"+parent+"`.")
+	}
+	if len(notes) > 0 {
+		fmt.Fprintf(b, "\n%s\n", strings.Join(notes, " "))
+	}
+	if doc != "" {
+		fmt.Fprintf(b, "\n%s\n", doc)
+	}
+}
+
+func docsOwns(b *strings.Builder, owns []OwnsSpec) {
+	if len(owns) == 0 {
+		return
+	}
+	b.WriteString("\nOwns:\n\n")
+	for _, o := range owns {
+		line := "`" + o.Attribute + "`"
+		if o.Key {
+			line += " @key"
+		}
+		if o.Unique {
+			line += " @unique"
+		}
+		if o.Card != "" {
+			line += " @card(" + o.Card + ")"
+		}
+		docsItem(b, line, o.Doc)
+	}
+}
+
+func docsPlays(b *strings.Builder, plays []PlaysSpec) {
+	if len(plays) == 0 {
+		return
+	}
+	b.WriteString("\nPlays:\n\n")
+	for _, p := range plays {
+		docsItem(b, "`"+p.Relation+":"+p.Role+"`", p.Doc)
+	}
+}
+
+func docsItem(b *strings.Builder, line, doc string) {
+	if doc != "" {
+		line += " — " + doc
+	}
+	fmt.Fprintf(b, "- %s\n", line)
+}
+
+// docsCell escapes text for a Markdown table cell.
+func docsCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
@@ -0,0 +1,262 @@
+package tqlgen
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CaliLuke/go-typeql/internal/yaml"
+)
+
+// GenerateConfig describes every file generated from one schema, as read
+// from tqlgen.yaml:
+//
+//	schema: schema.tql
+//	package: models
+//	targets:
+//	  - kind: models
+//	    out: models/models_gen.go
+//	  - kind: registry
+//	    out: models/registry_gen.go
+//	    typed_constants: true
+//	  - kind: dto
+//	    out: api/dto_gen.go
+//	    package: api
+//	  - kind: docs
+//	    out: docs/schema.md
+//	  - kind: proto
+//	    out: proto/models.proto
+//	    proto_import: example.com/app/gen/pb
+//	  - kind: grpc
+//	    out: server/grpc_gen.go
+//	    package: server
+//	    proto_import: example.com/app/gen/pb
+//	    models_import: example.com/app/models
+//
+// Paths are relative to the directory of the configuration file.
+type GenerateConfig struct {
+	// Schema is the path of the TypeQL schema file (required).
+	Schema string `json:"schema"`
+	// Package is the default Go package name of the generated files.
+	Package string `json:"package"`
+	// Acronyms applies Go acronym naming conventions (default true).
+	Acronyms bool `json:"acronyms"`
+	// SkipAbstract excludes abstract types (default true).
+	SkipAbstract bool `json:"skip_abstract"`
+	// Inherit propagates owns and plays from parent types (default true).
+	Inherit bool `json:"inherit"`
+	// Enums generates string constants from @values constraints (default true).
+	Enums bool `json:"enums"`
+	// FieldNames emits a <Type>Fields variable of attribute names per type
+	// (models, default true).
+	FieldNames bool `json:"field_names"`
+	// Naming is the naming strategy of the schema (models): "kebab"
+	// (default), "snake", "kebab-prefixed" or "snake-prefixed". See
+	// ParseNamingStrategy.
+	Naming string `json:"naming"`
+	// SchemaVersion is embedded in the models and registry output.
+	SchemaVersion string `json:"schema_version"`
+	// Targets lists the files to generate.
+	Targets []GenerateTarget `json:"targets"`
+}
+
+// GenerateTarget is one generated file. Options that do not apply to its
+// kind are ignored.
+type GenerateTarget struct {
+	// Kind is "models", "registry", "dto", "docs", "proto" or "grpc".
+	Kind string `json:"kind"`
+	// Out is the output file path (required).
+	Out string `json:"out"`
+	// Package overrides GenerateConfig.Package.
+	Package string `json:"package"`
+
+	// Queries emits precompiled query constants (models).
+	Queries bool `json:"queries"`
+	// TypedConstants generates typed string constants (registry).
+	TypedConstants bool `json:"typed_constants"`
+	// JSONSchema generates JSON schema fragment maps (registry).
+	JSONSchema bool `json:"json_schema"`
+	// IDField is the ID field name in Out structs (dto, default "ID").
+	IDField string `json:"id_field"`
+	// StrictOut makes required fields non-pointer in Out structs (dto).
+	StrictOut bool `json:"strict_out"`
+	// SkipRelationOut skips relation Out structs (dto).
+	SkipRelationOut bool `json:"skip_relation_out"`
+	// ExcludeEntities lists entities to skip (dto).
+	ExcludeEntities []string `json:"exclude_entities"`
+	// ExcludeRelations lists relations to skip (dto).
+	ExcludeRelations []string `json:"exclude_relations"`
+	// Title is the top-level heading (docs).
+	Title string `json:"title"`
+	// ProtoPackage is the protobuf package (proto, default Package).
+	ProtoPackage string `json:"proto_package"`
+	// ProtoImport is the Go import path of the code protoc generates from
+	// the proto target (proto and grpc, required).
+	ProtoImport string `json:"proto_import"`
+	// ModelsImport is the import path of the models package; empty when
+	// the servers are generated into it (grpc).
+	ModelsImport string `json:"models_import"`
+}
+
+// LoadGenerateConfig reads a generation config from a YAML file, or a JSON
+// file when its name ends in ".json", and resolves its paths against the
+// file's directory. Unknown keys are rejected so that typos are not ignored.
+func LoadGenerateConfig(path string) (*GenerateConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		v, err := yaml.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	cfg := &GenerateConfig{Acronyms: true, SkipAbstract: true, Inherit: true, Enums: true, FieldNames: true}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	cfg.Schema = resolve(cfg.Schema)
+	for i := range cfg.Targets {
+		cfg.Targets[i].Out = resolve(cfg.Targets[i].Out)
+	}
+	return cfg, nil
+}
+
+// Generate parses the schema once and renders every target. Files are only
+// written once all targets rendered, so an error leaves existing output
+// untouched. It returns the paths written, in target order.
+func Generate(cfg *GenerateConfig) ([]string, error) {
+	if cfg.Schema == "" {
+		return nil, fmt.Errorf("generate: no schema file configured")
+	}
+	if len(cfg.Targets) == 0 {
+		return nil, fmt.Errorf("generate: no targets configured")
+	}
+	seen := make(map[string]bool, len(cfg.Targets))
+	for i, t := range cfg.Targets {
+		switch {
+		case t.Out == "":
+			return nil, fmt.Errorf("generate: target %d (%s): no output file", i+1, t.Kind)
+		case seen[filepath.Clean(t.Out)]:
+			return nil, fmt.Errorf("generate: target %d (%s): %s is generated twice", i+1, t.Kind, t.Out)
+		}
+		seen[filepath.Clean(t.Out)] = true
+	}
+
+	text, err := os.ReadFile(cfg.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("generate: %w", err)
+	}
+	schema, err := ParseSchema(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("generate: %s: %w", cfg.Schema, err)
+	}
+	if cfg.Inherit {
+		schema.AccumulateInheritance()
+	}
+
+	outputs := make([][]byte, len(cfg.Targets))
+	for i, t := range cfg.Targets {
+		var buf bytes.Buffer
+		if err := renderTarget(&buf, schema, string(text), cfg, t); err != nil {
+			return nil, fmt.Errorf("generate: target %d (%s): %w", i+1, t.Kind, err)
+		}
+		outputs[i] = buf.Bytes()
+	}
+
+	var written []string
+	for i, t := range cfg.Targets {
+		if err := os.MkdirAll(filepath.Dir(t.Out), 0o755); err != nil {
+			return written, fmt.Errorf("generate: %w", err)
+		}
+		if err := os.WriteFile(t.Out, outputs[i], 0o644); err != nil {
+			return written, fmt.Errorf("generate: %w", err)
+		}
+		written = append(written, t.Out)
+	}
+	return written, nil
+}
+
+func renderTarget(w io.Writer, schema *ParsedSchema, schemaText string, cfg *GenerateConfig, t GenerateTarget) error {
+	pkg := cmp.Or(t.Package, cfg.Package, "models")
+	switch t.Kind {
+	case "models":
+		naming, err := ParseNamingStrategy(cfg.Naming)
+		if err != nil {
+			return err
+		}
+		return Render(w, schema, RenderConfig{
+			PackageName:   pkg,
+			UseAcronyms:   cfg.Acronyms,
+			SkipAbstract:  cfg.SkipAbstract,
+			SchemaVersion: cfg.SchemaVersion,
+			Enums:         cfg.Enums,
+			Queries:       t.Queries,
+			FieldNames:    cfg.FieldNames,
+			Naming:        naming,
+		})
+	case "registry":
+		return RenderRegistry(w, BuildRegistryData(schema, RegistryConfig{
+			PackageName:    pkg,
+			UseAcronyms:    cfg.Acronyms,
+			SkipAbstract:   cfg.SkipAbstract,
+			Enums:          cfg.Enums,
+			SchemaText:     schemaText,
+			SchemaVersion:  cfg.SchemaVersion,
+			TypedConstants: t.TypedConstants,
+			JSONSchema:     t.JSONSchema,
+		}))
+	case "dto":
+		return RenderDTO(w, BuildDTOData(schema, DTOConfig{
+			PackageName:      pkg,
+			UseAcronyms:      cfg.Acronyms,
+			SkipAbstract:     cfg.SkipAbstract,
+			IDFieldName:      cmp.Or(t.IDField, "ID"),
+			StrictOut:        t.StrictOut,
+			SkipRelationOut:  t.SkipRelationOut,
+			ExcludeEntities:  t.ExcludeEntities,
+			ExcludeRelations: t.ExcludeRelations,
+		}))
+	case "docs":
+		return RenderDocs(w, schema, DocsConfig{Title: t.Title, SkipAbstract: cfg.SkipAbstract})
+	case "proto", "grpc":
+		naming, err := ParseNamingStrategy(cfg.Naming)
+		if err != nil {
+			return err
+		}
+		data := BuildGRPCData(schema, GRPCConfig{
+			PackageName:  pkg,
+			ProtoPackage: t.ProtoPackage,
+			ProtoImport:  t.ProtoImport,
+			ModelsImport: t.ModelsImport,
+			UseAcronyms:  cfg.Acronyms,
+			SkipAbstract: cfg.SkipAbstract,
+			Naming:       naming,
+		})
+		if t.Kind == "proto" {
+			return RenderProto(w, data)
+		}
+		return RenderGRPC(w, data)
+	default:
+		return fmt.Errorf("unknown kind %q (want models, registry, dto, docs, proto or grpc)", t.Kind)
+	}
+}
@@ -0,0 +1,143 @@
+package tqlgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const generateSchema = `define
+attribute name, value string @doc("Display name");
+attribute status, value string @values("active", "closed");
+entity party @abstract, owns name @key;
+entity person sub party, plays employment:employee;
+entity company sub party, plays employment:employer;
+relation employment, relates employee @card(1..1), relates employer, owns status;
+`
+
+func writeGenerateFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writeGenerateFiles(t, map[string]string{
+		"schema.tql": generateSchema,
+		"tqlgen.yaml": `schema: schema.tql
+package: models
+targets:
+  - kind: models
+    out: models/models_gen.go
+  - kind: registry
+    out: models/registry_gen.go
+    typed_constants: true
+  - kind: dto
+    out: api/dto_gen.go
+    package: api
+  - kind: docs
+    out: docs/schema.md
+    title: Staffing schema
+`,
+	})
+	cfg, err := LoadGenerateConfig(filepath.Join(dir, "tqlgen.yaml"))
+	if err != nil {
+		t.Fatalf("LoadGenerateConfig: %v", err)
+	}
+	if !cfg.Acronyms || !cfg.SkipAbstract || !cfg.Inherit || !cfg.Enums {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+	written, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(written) != 4 {
+		t.Fatalf("written = %v", written)
+	}
+
+	checks := map[string][]string{
+		"models/models_gen.go":   {"package models", "type Person struct", "Name string `typedb:\"name,key\"`"},
+		"models/registry_gen.go": {"package models", "type EntityType string"},
+		"api/dto_gen.go":         {"package api", "type PersonOut struct"},
+		"docs/schema.md":         {"# Staffing schema", "| `name` | string |  | Display name |", "### person", "Subtype of `party`.", "- `employee` @card(1..1)"},
+	}
+	for file, wants := range checks {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("%s: missing %q in:\n%s", file, want, data)
+			}
+		}
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	dir := writeGenerateFiles(t, map[string]string{
+		"schema.tql":   generateSchema,
+		"typo.yaml":    "schema: schema.tql\ntarget:\n  - kind: models\n",
+		"unknown.yaml": "schema: schema.tql\ntargets:\n  - kind: models\n    out: a.go\n  - kind: openapi\n    out: b.json\n",
+		"twice.json":   `{"schema": "schema.tql", "targets": [{"kind": "models", "out": "a.go"}, {"kind": "dto", "out": "./a.go"}]}`,
+		"naming.yaml":  "schema: schema.tql\nnaming: camel\ntargets:\n  - kind: models\n    out: a.go\n",
+	})
+
+	if _, err := LoadGenerateConfig(filepath.Join(dir, "typo.yaml")); err == nil || !strings.Contains(err.Error(), `unknown field "target"`) {
+		t.Errorf("typo: err = %v", err)
+	}
+
+	cfg, err := LoadGenerateConfig(filepath.Join(dir, "unknown.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(cfg); err == nil || !strings.Contains(err.Error(), `target 2 (openapi): unknown kind "openapi"`) {
+		t.Errorf("unknown kind: err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.go")); !os.IsNotExist(err) {
+		t.Errorf("a.go written despite the failing target: %v", err)
+	}
+
+	cfg, err = LoadGenerateConfig(filepath.Join(dir, "twice.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(cfg); err == nil || !strings.Contains(err.Error(), "generated twice") {
+		t.Errorf("duplicate output: err = %v", err)
+	}
+
+	cfg, err = LoadGenerateConfig(filepath.Join(dir, "naming.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(cfg); err == nil || !strings.Contains(err.Error(), `unknown naming strategy "camel"`) {
+		t.Errorf("unknown naming: err = %v", err)
+	}
+}
+
+func TestRenderDocs_SkipAbstract(t *testing.T) {
+	schema, err := ParseSchema(generateSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := RenderDocs(&b, schema, DocsConfig{SkipAbstract: true}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, "<!-- Code generated by tqlgen. DO NOT EDIT. -->\n\n# Schema\n") {
+		t.Errorf("unexpected header:\n%s", out)
+	}
+	if strings.Contains(out, "### party") {
+		t.Errorf("abstract type documented:\n%s", out)
+	}
+	if !strings.Contains(out, `one of "active", "closed"`) {
+		t.Errorf("missing @values constraint:\n%s", out)
+	}
+}
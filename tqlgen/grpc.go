@@ -0,0 +1,580 @@
+package tqlgen
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/format"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// GRPCConfig specifies the settings for generating a protobuf service
+// definition (RenderProto) and the gRPC servers implementing it over
+// gotype managers (RenderGRPC).
+type GRPCConfig struct {
+	// PackageName is the Go package of the generated servers.
+	PackageName string
+	// ProtoPackage is the protobuf package (default PackageName).
+	ProtoPackage string
+	// ProtoImport is the Go import path of the code protoc generates from
+	// the .proto file. It becomes the go_package option and is imported by
+	// the servers (required).
+	ProtoImport string
+	// ModelsImport is the import path of the models generated by Render,
+	// imported under its last element. Empty means the servers are
+	// generated into the models package.
+	ModelsImport string
+	// UseAcronyms applies Go acronym naming conventions, as for Render.
+	UseAcronyms bool
+	// SkipAbstract excludes abstract types.
+	SkipAbstract bool
+	// Naming is the NamingStrategy given to Render, so that model field
+	// names match.
+	Naming NamingStrategy
+}
+
+// GRPCData holds the schema-derived data for protobuf and gRPC generation.
+type GRPCData struct {
+	PackageName  string
+	ProtoPackage string
+	ProtoImport  string
+	// Models qualifies model type names: "models." or empty.
+	Models string
+	// NeedsTimestamp is set when a datetime attribute is exported.
+	NeedsTimestamp bool
+	// NeedsOptionalTime is set when an optional datetime attribute is
+	// exported, which needs the pointer conversion helpers.
+	NeedsOptionalTime bool
+	Imports           [][]grpcImport // std library, then the rest
+	Services          []grpcServiceCtx
+}
+
+type grpcImport struct {
+	Alias string
+	Path  string
+}
+
+type grpcServiceCtx struct {
+	GoName   string // model and message name, e.g. "Person"
+	TypeName string // TypeDB name
+	Relation bool
+	// Var is the proto field holding the instance in requests, and PBVar
+	// its Go name in the protoc output.
+	Var    string
+	PBVar  string
+	MgrVar string // variable holding the Manager in RegisterServices
+	Fields []grpcFieldCtx
+	Roles  []grpcFieldCtx
+	// UpdatePaths lists, quoted, the update_mask paths an empty mask
+	// stands for: every field but the keys.
+	UpdatePaths []string
+}
+
+type grpcFieldCtx struct {
+	ProtoName     string // proto field name and update_mask path
+	ProtoType     string
+	ProtoOptional bool
+	Number        int
+	PBName        string // Go field name in the protoc output
+	GoName        string // Go field name in the model
+	Key           bool
+	ToProto       string // expression converting m.GoName
+	FromProto     string // expression converting p.PBName
+	Role          string // role name, for role player IIDs
+	PlayerType    string // qualified Go type of the role player
+}
+
+// BuildGRPCData derives the messages and services of the schema's entity
+// and relation types. Each message carries the instance's IID as field 1,
+// then its attributes and, for relations, one "<role>_iid" field per role,
+// numbered in schema order.
+func BuildGRPCData(schema *ParsedSchema, cfg GRPCConfig) *GRPCData {
+	data := &GRPCData{
+		PackageName:  cmp.Or(cfg.PackageName, "models"),
+		ProtoPackage: cmp.Or(cfg.ProtoPackage, cfg.PackageName, "models"),
+		ProtoImport:  cfg.ProtoImport,
+	}
+	if cfg.ModelsImport != "" {
+		data.Models = path.Base(cfg.ModelsImport) + "."
+	}
+	attrTypes := make(map[string]string, len(schema.Attributes))
+	for _, a := range schema.Attributes {
+		attrTypes[a.Name] = a.ValueType
+	}
+	rcfg := RenderConfig{UseAcronyms: cfg.UseAcronyms, Naming: cfg.Naming}
+	needsFmt := false
+
+	for _, e := range schema.Entities {
+		if cfg.SkipAbstract && e.Abstract {
+			continue
+		}
+		data.Services = append(data.Services, buildGRPCService(data, e.Name, e.Owns, attrTypes, rcfg))
+	}
+	for _, r := range schema.Relations {
+		if cfg.SkipAbstract && r.Abstract {
+			continue
+		}
+		svc := buildGRPCService(data, r.Name, r.Owns, attrTypes, rcfg)
+		svc.Relation = true
+		roles := buildRelationCtx(r, schema, attrTypes, rcfg).Roles
+		for i, rel := range r.Relates {
+			name := ToSnakeCase(rel.Role) + "_iid"
+			svc.Roles = append(svc.Roles, grpcFieldCtx{
+				ProtoName:  name,
+				ProtoType:  "string",
+				Number:     len(svc.Fields) + i + 2,
+				PBName:     protoGoName(name),
+				GoName:     roles[i].GoName,
+				Role:       rel.Role,
+				PlayerType: data.Models + roles[i].PlayerType,
+			})
+			svc.UpdatePaths = append(svc.UpdatePaths, strconv.Quote(name))
+		}
+		if len(svc.Roles) > 0 {
+			needsFmt = true
+		}
+		data.Services = append(data.Services, svc)
+	}
+
+	std := []grpcImport{{Path: "context"}, {Path: "errors"}}
+	if needsFmt {
+		std = append(std, grpcImport{Path: "fmt"})
+	}
+	if data.NeedsOptionalTime {
+		std = append(std, grpcImport{Path: "time"})
+	}
+	other := []grpcImport{
+		{Path: "github.com/CaliLuke/go-typeql/gotype"},
+		{Path: "google.golang.org/grpc"},
+		{Path: "google.golang.org/grpc/codes"},
+		{Path: "google.golang.org/grpc/status"},
+		{Path: "google.golang.org/protobuf/types/known/emptypb"},
+		{Alias: "pb", Path: cfg.ProtoImport},
+	}
+	if data.NeedsTimestamp {
+		other = append(other, grpcImport{Path: "google.golang.org/protobuf/types/known/timestamppb"})
+	}
+	if cfg.ModelsImport != "" {
+		other = append(other, grpcImport{Alias: path.Base(cfg.ModelsImport), Path: cfg.ModelsImport})
+	}
+	slices.SortFunc(other, func(a, b grpcImport) int { return strings.Compare(a.Path, b.Path) })
+	data.Imports = [][]grpcImport{std, other}
+	return data
+}
+
+func buildGRPCService(data *GRPCData, typeName string, owns []OwnsSpec, attrTypes map[string]string, cfg RenderConfig) grpcServiceCtx {
+	goName := goTypeName(typeName, cfg)
+	svc := grpcServiceCtx{
+		GoName:   goName,
+		TypeName: typeName,
+		Var:      ToSnakeCase(typeName),
+		PBVar:    protoGoName(ToSnakeCase(typeName)),
+		MgrVar:   strings.ToLower(goName[:1]) + goName[1:] + "Mgr",
+	}
+	for _, o := range owns {
+		field := buildFieldCtx(typeName, o, attrTypes, cfg)
+		f := grpcFieldCtx{
+			ProtoName: ToSnakeCase(o.Attribute),
+			ProtoType: typeDBToProto(attrTypes[o.Attribute]),
+			Number:    len(svc.Fields) + 2,
+			GoName:    field.GoName,
+			Key:       o.Key,
+		}
+		f.PBName = protoGoName(f.ProtoName)
+		optional := strings.HasPrefix(field.GoType, "*")
+		switch {
+		case f.ProtoType == "google.protobuf.Timestamp" && optional:
+			data.NeedsTimestamp, data.NeedsOptionalTime = true, true
+			f.ToProto = "timestampOf(m." + f.GoName + ")"
+			f.FromProto = "timeOf(p.Get" + f.PBName + "())"
+		case f.ProtoType == "google.protobuf.Timestamp":
+			data.NeedsTimestamp = true
+			f.ToProto = "timestamppb.New(m." + f.GoName + ")"
+			f.FromProto = "p.Get" + f.PBName + "().AsTime()"
+		case optional:
+			f.ProtoOptional = true
+			f.ToProto = "m." + f.GoName
+			f.FromProto = "p." + f.PBName
+		default:
+			f.ToProto = "m." + f.GoName
+			f.FromProto = "p.Get" + f.PBName + "()"
+		}
+		svc.Fields = append(svc.Fields, f)
+		if !f.Key {
+			svc.UpdatePaths = append(svc.UpdatePaths, strconv.Quote(f.ProtoName))
+		}
+	}
+	return svc
+}
+
+// typeDBToProto maps a TypeDB value type to a protobuf type, following the
+// Go types the models use.
+func typeDBToProto(vtype string) string {
+	switch typeDBToGo(vtype) {
+	case "int64":
+		return "int64"
+	case "float64":
+		return "double"
+	case "bool":
+		return "bool"
+	case "time.Time":
+		return "google.protobuf.Timestamp"
+	default:
+		return "string"
+	}
+}
+
+// protoGoName returns the Go name protoc-gen-go gives the proto field name:
+// an underscore followed by a lowercase letter is dropped and the letter
+// capitalized, as is the first letter.
+func protoGoName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_' && i == 0:
+			b.WriteByte('X')
+		case c == '_' && i+1 < len(name) && isASCIILower(name[i+1]):
+		case '0' <= c && c <= '9':
+			b.WriteByte(c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b.WriteByte(c)
+			for ; i+1 < len(name) && isASCIILower(name[i+1]); i++ {
+				b.WriteByte(name[i+1])
+			}
+		}
+	}
+	return b.String()
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+// RenderProto writes a proto3 file declaring one message per type and a
+// <Type>Service with Create, Get, List, Update and Delete RPCs. Updates take
+// a google.protobuf.FieldMask naming the fields to write.
+func RenderProto(w io.Writer, data *GRPCData) error {
+	if data.ProtoImport == "" {
+		return fmt.Errorf("proto: no Go import path for the generated code (proto_import)")
+	}
+	return protoTemplate.Execute(w, data)
+}
+
+// RenderGRPC writes a <Type>Server per type implementing the service
+// declared by RenderProto over a gotype.Manager, and RegisterServices,
+// which registers all of them with a grpc.Server. It needs the code protoc
+// generates from that file, imported from data.ProtoImport.
+func RenderGRPC(w io.Writer, data *GRPCData) error {
+	if data.ProtoImport == "" {
+		return fmt.Errorf("grpc: no import path for the protoc output (proto_import)")
+	}
+	var buf bytes.Buffer
+	if err := grpcTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("grpc: format: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+var protoTemplate = template.Must(template.New("proto").Parse(`// Code generated by tqlgen; DO NOT EDIT.
+
+syntax = "proto3";
+
+package {{.ProtoPackage}};
+
+import "google/protobuf/empty.proto";
+import "google/protobuf/field_mask.proto";
+{{- if .NeedsTimestamp}}
+import "google/protobuf/timestamp.proto";
+{{- end}}
+
+option go_package = "{{.ProtoImport}}";
+{{range .Services}}
+// {{.GoName}} is an instance of the {{.TypeName}} {{if .Relation}}relation{{else}}entity{{end}}.
+message {{.GoName}} {
+  string iid = 1;
+{{- range .Fields}}
+  {{if .ProtoOptional}}optional {{end}}{{.ProtoType}} {{.ProtoName}} = {{.Number}};
+{{- end}}
+{{- range .Roles}}
+  {{.ProtoType}} {{.ProtoName}} = {{.Number}};
+{{- end}}
+}
+
+service {{.GoName}}Service {
+  rpc Create{{.GoName}}(Create{{.GoName}}Request) returns ({{.GoName}});
+  rpc Get{{.GoName}}(Get{{.GoName}}Request) returns ({{.GoName}});
+  rpc List{{.GoName}}(List{{.GoName}}Request) returns (List{{.GoName}}Response);
+  rpc Update{{.GoName}}(Update{{.GoName}}Request) returns ({{.GoName}});
+  rpc Delete{{.GoName}}(Delete{{.GoName}}Request) returns (google.protobuf.Empty);
+}
+
+message Create{{.GoName}}Request {
+  {{.GoName}} {{.Var}} = 1;
+}
+
+message Get{{.GoName}}Request {
+  string iid = 1;
+}
+
+message List{{.GoName}}Request {
+  int32 limit = 1;
+  int32 offset = 2;
+}
+
+message List{{.GoName}}Response {
+  repeated {{.GoName}} items = 1;
+}
+
+message Update{{.GoName}}Request {
+  {{.GoName}} {{.Var}} = 1;
+  google.protobuf.FieldMask update_mask = 2;
+}
+
+message Delete{{.GoName}}Request {
+  string iid = 1;
+}
+{{end -}}
+`))
+
+var grpcTemplate = template.Must(template.New("grpc").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`// Code generated by tqlgen; DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+{{- range $i, $group := .Imports}}
+{{- if $i}}
+{{end}}
+{{- range $group}}
+	{{if .Alias}}{{.Alias}} {{end}}"{{.Path}}"
+{{- end}}
+{{- end}}
+)
+
+// RegisterServices registers a server for every type with s, each over a
+// new Manager of db.
+func RegisterServices(s grpc.ServiceRegistrar, db *gotype.Database) error {
+{{- range .Services}}
+	{{.MgrVar}}, err := gotype.NewManager[{{$.Models}}{{.GoName}}](db)
+	if err != nil {
+		return err
+	}
+	pb.Register{{.GoName}}ServiceServer(s, &{{.GoName}}Server{Mgr: {{.MgrVar}}})
+{{- end}}
+	return nil
+}
+
+// grpcError converts a Manager error to a gRPC status: invalid instances are
+// the client's fault, anything else is the server's.
+func grpcError(err error) error {
+	if _, ok := errors.AsType[*gotype.NotFoundError](err); ok {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if gotype.FieldErrorsOf(err) != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, ok := errors.AsType[*gotype.KeyAttributeError](err); ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, ok := errors.AsType[*gotype.RoleCardinalityError](err); ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+{{- if .NeedsOptionalTime}}
+
+func timestampOf(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func timeOf(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+{{- end}}
+{{range .Services}}
+// {{.GoName}}Server implements pb.{{.GoName}}ServiceServer over a
+// gotype.Manager.{{if .Relation}} Get and List return the relation's own
+// attributes, as Manager.GetByIID does; role player IIDs are only read by
+// Create and Update.{{end}}
+type {{.GoName}}Server struct {
+	pb.Unimplemented{{.GoName}}ServiceServer
+	Mgr *gotype.Manager[{{$.Models}}{{.GoName}}]
+}
+
+// Create{{.GoName}} inserts the {{.TypeName}} of the request.
+func (s *{{.GoName}}Server) Create{{.GoName}}(ctx context.Context, req *pb.Create{{.GoName}}Request) (*pb.{{.GoName}}, error) {
+	instance, err := from{{.GoName}}Proto(req.Get{{.PBVar}}())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.Mgr.Insert(ctx, instance); err != nil {
+		return nil, grpcError(err)
+	}
+	return to{{.GoName}}Proto(instance), nil
+}
+
+// Get{{.GoName}} returns the {{.TypeName}} with the IID of the request.
+func (s *{{.GoName}}Server) Get{{.GoName}}(ctx context.Context, req *pb.Get{{.GoName}}Request) (*pb.{{.GoName}}, error) {
+	instance, err := s.get(ctx, req.GetIid())
+	if err != nil {
+		return nil, err
+	}
+	return to{{.GoName}}Proto(instance), nil
+}
+
+// List{{.GoName}} returns a page of {{.TypeName}} instances; a zero limit
+// returns them all.
+func (s *{{.GoName}}Server) List{{.GoName}}(ctx context.Context, req *pb.List{{.GoName}}Request) (*pb.List{{.GoName}}Response, error) {
+	if req.GetLimit() < 0 || req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit and offset must not be negative")
+	}
+	q := s.Mgr.Query()
+	if req.GetLimit() > 0 {
+		q = q.Limit(int(req.GetLimit()))
+	}
+	if req.GetOffset() > 0 {
+		q = q.Offset(int(req.GetOffset()))
+	}
+	instances, err := q.Execute(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &pb.List{{.GoName}}Response{Items: make([]*pb.{{.GoName}}, len(instances))}
+	for i, instance := range instances {
+		resp.Items[i] = to{{.GoName}}Proto(instance)
+	}
+	return resp, nil
+}
+
+// Update{{.GoName}} writes the fields named by the update mask, or all of
+// them when it is empty, from the {{.TypeName}} of the request to the stored
+// one with the same IID. Key attributes cannot be updated.
+func (s *{{.GoName}}Server) Update{{.GoName}}(ctx context.Context, req *pb.Update{{.GoName}}Request) (*pb.{{.GoName}}, error) {
+	p := req.Get{{.PBVar}}()
+	instance, err := s.get(ctx, p.GetIid())
+	if err != nil {
+		return nil, err
+	}
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = []string{ {{- join .UpdatePaths ", " -}} }
+	}
+{{- if .Roles}}
+	players := make(map[string]string)
+{{- end}}
+	for _, path := range paths {
+		switch path {
+{{- range .Fields}}
+		case "{{.ProtoName}}":
+{{- if .Key}}
+			return nil, status.Errorf(codes.InvalidArgument, "update_mask: %s is a key attribute", path)
+{{- else}}
+			instance.{{.GoName}} = {{.FromProto}}
+{{- end}}
+{{- end}}
+{{- range .Roles}}
+		case "{{.ProtoName}}":
+			if !gotype.IsIID(p.Get{{.PBName}}()) {
+				return nil, status.Errorf(codes.InvalidArgument, "{{.ProtoName}}: invalid iid %q", p.Get{{.PBName}}())
+			}
+			players["{{.Role}}"] = p.Get{{.PBName}}()
+{{- end}}
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "update_mask: unknown field %q", path)
+		}
+	}
+	if err := s.Mgr.Update(ctx, instance); err != nil {
+		return nil, grpcError(err)
+	}
+{{- if .Roles}}
+	if err := s.Mgr.UpdatePlayers(ctx, instance, players); err != nil {
+		return nil, grpcError(err)
+	}
+{{- end}}
+	return to{{.GoName}}Proto(instance), nil
+}
+
+// Delete{{.GoName}} deletes the {{.TypeName}} with the IID of the request.
+func (s *{{.GoName}}Server) Delete{{.GoName}}(ctx context.Context, req *pb.Delete{{.GoName}}Request) (*emptypb.Empty, error) {
+	instance, err := s.get(ctx, req.GetIid())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Mgr.Delete(ctx, instance); err != nil {
+		return nil, grpcError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// get loads the {{.TypeName}} with the given IID, failing with a gRPC
+// status when the IID is malformed or names no instance.
+func (s *{{.GoName}}Server) get(ctx context.Context, iid string) (*{{$.Models}}{{.GoName}}, error) {
+	if !gotype.IsIID(iid) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid iid %q", iid)
+	}
+	instance, err := s.Mgr.GetByIID(ctx, iid)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if instance == nil {
+		return nil, status.Errorf(codes.NotFound, "{{.TypeName}} %s not found", iid)
+	}
+	return instance, nil
+}
+
+func to{{.GoName}}Proto(m *{{$.Models}}{{.GoName}}) *pb.{{.GoName}} {
+	p := &pb.{{.GoName}}{
+		Iid: m.GetIID(),
+{{- range .Fields}}
+		{{.PBName}}: {{.ToProto}},
+{{- end}}
+	}
+{{- range .Roles}}
+	if m.{{.GoName}} != nil {
+		p.{{.PBName}} = m.{{.GoName}}.GetIID()
+	}
+{{- end}}
+	return p
+}
+
+func from{{.GoName}}Proto(p *pb.{{.GoName}}) (*{{$.Models}}{{.GoName}}, error) {
+	m := &{{$.Models}}{{.GoName}}{
+{{- range .Fields}}
+		{{.GoName}}: {{.FromProto}},
+{{- end}}
+	}
+{{- range .Roles}}
+	if iid := p.Get{{.PBName}}(); iid != "" {
+		if !gotype.IsIID(iid) {
+			return nil, fmt.Errorf("{{.ProtoName}}: invalid iid %q", iid)
+		}
+		m.{{.GoName}} = &{{.PlayerType}}{}
+		m.{{.GoName}}.SetIID(iid)
+	}
+{{- end}}
+	return m, nil
+}
+{{end -}}
+`))
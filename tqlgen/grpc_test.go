@@ -0,0 +1,206 @@
+package tqlgen
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const grpcSchema = `define
+attribute name, value string;
+attribute email, value string;
+attribute birth-date, value datetime;
+attribute age, value integer;
+attribute title, value string;
+entity person, owns name @key, owns email, owns birth-date @card(1..1), owns age,
+  plays employment:employee;
+entity company, owns name @key, plays employment:employer;
+relation employment, relates employee, relates employer, owns title;
+`
+
+func TestProtoGoName(t *testing.T) {
+	for name, want := range map[string]string{
+		"iid":          "Iid",
+		"birth_date":   "BirthDate",
+		"employee_iid": "EmployeeIid",
+		"address2":     "Address2",
+		"x_1y":         "X_1Y",
+	} {
+		if got := protoGoName(name); got != want {
+			t.Errorf("protoGoName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestBuildGRPCData(t *testing.T) {
+	schema, err := ParseSchema(grpcSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := BuildGRPCData(schema, GRPCConfig{
+		PackageName:  "server",
+		ProtoImport:  "example.com/app/pb",
+		ModelsImport: "example.com/app/models",
+		UseAcronyms:  true,
+	})
+	if data.ProtoPackage != "server" || data.Models != "models." {
+		t.Errorf("ProtoPackage = %q, Models = %q", data.ProtoPackage, data.Models)
+	}
+	if !data.NeedsTimestamp || data.NeedsOptionalTime {
+		t.Errorf("NeedsTimestamp = %v, NeedsOptionalTime = %v", data.NeedsTimestamp, data.NeedsOptionalTime)
+	}
+	if len(data.Services) != 3 {
+		t.Fatalf("expected 3 services, got %d", len(data.Services))
+	}
+
+	person := data.Services[0]
+	var numbers []int
+	for _, f := range person.Fields {
+		numbers = append(numbers, f.Number)
+	}
+	if got := strings.Join(person.UpdatePaths, ","); got != `"email","birth_date","age"` {
+		t.Errorf("person update paths = %s", got)
+	}
+	if !person.Fields[0].Key || person.Fields[0].ProtoOptional || !person.Fields[1].ProtoOptional {
+		t.Errorf("person fields = %+v", person.Fields)
+	}
+	if numbers[0] != 2 || numbers[len(numbers)-1] != 5 {
+		t.Errorf("person field numbers = %v", numbers)
+	}
+
+	employment := data.Services[2]
+	if !employment.Relation || len(employment.Roles) != 2 {
+		t.Fatalf("employment = %+v", employment)
+	}
+	employee := employment.Roles[0]
+	if employee.ProtoName != "employee_iid" || employee.Number != 3 || employee.PlayerType != "models.Person" {
+		t.Errorf("employee role = %+v", employee)
+	}
+}
+
+func TestRenderProto(t *testing.T) {
+	schema, err := ParseSchema(grpcSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := BuildGRPCData(schema, GRPCConfig{PackageName: "server", ProtoPackage: "app.v1", ProtoImport: "example.com/app/pb"})
+	var b strings.Builder
+	if err := RenderProto(&b, data); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"package app.v1;",
+		`import "google/protobuf/timestamp.proto";`,
+		`option go_package = "example.com/app/pb";`,
+		"message Person {\n  string iid = 1;\n  string name = 2;\n  optional string email = 3;\n  google.protobuf.Timestamp birth_date = 4;\n  optional int64 age = 5;\n}",
+		"  string employee_iid = 3;\n  string employer_iid = 4;\n",
+		"rpc UpdateEmployment(UpdateEmploymentRequest) returns (Employment);",
+		"rpc DeleteCompany(DeleteCompanyRequest) returns (google.protobuf.Empty);",
+		"google.protobuf.FieldMask update_mask = 2;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	data.ProtoImport = ""
+	if err := RenderProto(&b, data); err == nil || !strings.Contains(err.Error(), "proto_import") {
+		t.Errorf("missing proto_import: err = %v", err)
+	}
+}
+
+func TestRenderGRPC(t *testing.T) {
+	schema, err := ParseSchema(grpcSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := BuildGRPCData(schema, GRPCConfig{
+		PackageName:  "server",
+		ProtoImport:  "example.com/app/pb",
+		ModelsImport: "example.com/app/models",
+		UseAcronyms:  true,
+	})
+	var b strings.Builder
+	if err := RenderGRPC(&b, data); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	formatted, err := format.Source([]byte(out))
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, out)
+	}
+	if string(formatted) != out {
+		t.Errorf("generated code is not gofmt-formatted:\n%s", out)
+	}
+	for _, want := range []string{
+		"package server",
+		`pb "example.com/app/pb"`,
+		"func RegisterServices(s grpc.ServiceRegistrar, db *gotype.Database) error {",
+		"pb.RegisterPersonServiceServer(s, &PersonServer{Mgr: personMgr})",
+		"Mgr *gotype.Manager[models.Employment]",
+		`return nil, status.Errorf(codes.InvalidArgument, "update_mask: %s is a key attribute", path)`,
+		`players["employee"] = p.GetEmployeeIid()`,
+		"BirthDate: timestamppb.New(m.BirthDate),",
+		"m.Employer = &models.Company{}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "func timeOf") {
+		t.Errorf("optional time helpers emitted without optional datetimes:\n%s", out)
+	}
+}
+
+func TestGenerate_GRPC(t *testing.T) {
+	dir := writeGenerateFiles(t, map[string]string{
+		"schema.tql": grpcSchema,
+		"tqlgen.yaml": `schema: schema.tql
+package: models
+targets:
+  - kind: models
+    out: models/models_gen.go
+  - kind: proto
+    out: proto/models.proto
+    proto_package: app.v1
+    proto_import: example.com/app/pb
+  - kind: grpc
+    out: server/grpc_gen.go
+    package: server
+    proto_import: example.com/app/pb
+    models_import: example.com/app/models
+`,
+		"noimport.yaml": "schema: schema.tql\ntargets:\n  - kind: grpc\n    out: grpc_gen.go\n",
+	})
+	cfg, err := LoadGenerateConfig(filepath.Join(dir, "tqlgen.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(cfg); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	checks := map[string]string{
+		"proto/models.proto": "service PersonService {",
+		"server/grpc_gen.go": "type PersonServer struct {",
+	}
+	for file, want := range checks {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s: missing %q in:\n%s", file, want, data)
+		}
+	}
+
+	cfg, err = LoadGenerateConfig(filepath.Join(dir, "noimport.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(cfg); err == nil || !strings.Contains(err.Error(), "proto_import") {
+		t.Errorf("missing proto_import: err = %v", err)
+	}
+}
@@ -2,6 +2,7 @@
 package tqlgen
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
 )
@@ -71,3 +72,130 @@ func ToPascalCaseAcronyms(name string) string {
 	}
 	return b.String()
 }
+
+// NamingStrategy maps Go names to TypeDB names. gotype uses it at
+// registration for type names and for attribute fields whose typedb tag
+// leaves the name out; tqlgen uses it to name the fields it generates.
+type NamingStrategy interface {
+	// TypeName returns the TypeDB type name of the Go struct goName.
+	TypeName(goName string) string
+	// AttributeName returns the name of the attribute held by the Go field
+	// goField of the TypeDB type typeName.
+	AttributeName(typeName, goField string) string
+	// TrimAttribute returns the part of the attribute attr, owned by
+	// typeName, that names its Go field: attr without any decoration added
+	// by AttributeName.
+	TrimAttribute(typeName, attr string) string
+}
+
+// KebabCase is the default NamingStrategy: UserAccount becomes user-account.
+// Type names split before every capital letter (HTTPServer becomes
+// h-t-t-p-server) so that existing type names do not change, while attribute
+// names keep acronyms whole (UserID becomes user-id). With PrefixAttributes,
+// attribute names start with their owner's type name (person-name).
+type KebabCase struct {
+	PrefixAttributes bool
+}
+
+// TypeName implements NamingStrategy.
+func (KebabCase) TypeName(goName string) string {
+	var b strings.Builder
+	for i, r := range goName {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(byte(r - 'A' + 'a'))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// AttributeName implements NamingStrategy.
+func (k KebabCase) AttributeName(typeName, goField string) string {
+	return prefixAttribute(k.PrefixAttributes, typeName, "-", joinGoName(goField, "-"))
+}
+
+// TrimAttribute implements NamingStrategy.
+func (k KebabCase) TrimAttribute(typeName, attr string) string {
+	return trimAttribute(k.PrefixAttributes, typeName, "-", attr)
+}
+
+// SnakeCase is the NamingStrategy for snake_case schemas: UserAccount
+// becomes user_account and UserID becomes user_id. With PrefixAttributes,
+// attribute names start with their owner's type name (person_name).
+type SnakeCase struct {
+	PrefixAttributes bool
+}
+
+// TypeName implements NamingStrategy.
+func (SnakeCase) TypeName(goName string) string {
+	return joinGoName(goName, "_")
+}
+
+// AttributeName implements NamingStrategy.
+func (s SnakeCase) AttributeName(typeName, goField string) string {
+	return prefixAttribute(s.PrefixAttributes, typeName, "_", joinGoName(goField, "_"))
+}
+
+// TrimAttribute implements NamingStrategy.
+func (s SnakeCase) TrimAttribute(typeName, attr string) string {
+	return trimAttribute(s.PrefixAttributes, typeName, "_", attr)
+}
+
+// ParseNamingStrategy returns the built-in strategy called name: "kebab",
+// "snake", "kebab-prefixed" or "snake-prefixed". The empty name is "kebab".
+func ParseNamingStrategy(name string) (NamingStrategy, error) {
+	switch name {
+	case "", "kebab":
+		return KebabCase{}, nil
+	case "snake":
+		return SnakeCase{}, nil
+	case "kebab-prefixed":
+		return KebabCase{PrefixAttributes: true}, nil
+	case "snake-prefixed":
+		return SnakeCase{PrefixAttributes: true}, nil
+	}
+	return nil, fmt.Errorf("unknown naming strategy %q (want kebab, snake, kebab-prefixed or snake-prefixed)", name)
+}
+
+func prefixAttribute(prefix bool, typeName, sep, name string) string {
+	if !prefix || typeName == "" {
+		return name
+	}
+	return typeName + sep + name
+}
+
+func trimAttribute(prefix bool, typeName, sep, attr string) string {
+	if !prefix {
+		return attr
+	}
+	if rest, ok := strings.CutPrefix(attr, typeName+sep); ok && rest != "" {
+		return rest
+	}
+	return attr
+}
+
+// joinGoName lowercases the words of a Go identifier and joins them with
+// sep. A run of capitals is one word, except that its last capital starts
+// the next word when followed by a lowercase letter: HTTPServer is
+// http, server and UserIDs is user, ids.
+func joinGoName(name, sep string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			// A plural acronym (IDs) keeps its trailing s.
+			plural := i+2 == len(runes) && runes[i+1] == 's'
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !plural
+			if !unicode.IsUpper(prev) || nextLower {
+				b.WriteString(sep)
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -1,6 +1,9 @@
 package tqlgen
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestToPascalCase(t *testing.T) {
 	tests := []struct {
@@ -66,3 +69,57 @@ func TestToSnakeCase(t *testing.T) {
 		})
 	}
 }
+
+func TestNamingStrategies(t *testing.T) {
+	tests := []struct {
+		naming   NamingStrategy
+		goName   string
+		typeName string
+		goField  string
+		attr     string
+	}{
+		{KebabCase{}, "UserAccount", "user-account", "FullName", "full-name"},
+		{KebabCase{}, "HTTPServer", "h-t-t-p-server", "UserID", "user-id"},
+		{KebabCase{PrefixAttributes: true}, "Person", "person", "Name", "person-name"},
+		{SnakeCase{}, "UserAccount", "user_account", "HTTPPort", "http_port"},
+		{SnakeCase{}, "Tag", "tag", "UserIDs", "user_ids"},
+		{SnakeCase{PrefixAttributes: true}, "Person", "person", "BirthDate", "person_birth_date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goName+"."+tt.goField, func(t *testing.T) {
+			if got := tt.naming.TypeName(tt.goName); got != tt.typeName {
+				t.Errorf("TypeName(%q) = %q, want %q", tt.goName, got, tt.typeName)
+			}
+			attr := tt.naming.AttributeName(tt.typeName, tt.goField)
+			if attr != tt.attr {
+				t.Errorf("AttributeName(%q, %q) = %q, want %q", tt.typeName, tt.goField, attr, tt.attr)
+			}
+			if got := ToPascalCaseAcronyms(tt.naming.TrimAttribute(tt.typeName, attr)); got != tt.goField && !strings.EqualFold(got, tt.goField) {
+				t.Errorf("TrimAttribute(%q, %q) does not round-trip to %q: %q", tt.typeName, attr, tt.goField, got)
+			}
+		})
+	}
+
+	// Attributes of other owners keep their prefix.
+	if got := (KebabCase{PrefixAttributes: true}).TrimAttribute("employee", "person-name"); got != "person-name" {
+		t.Errorf("TrimAttribute = %q", got)
+	}
+}
+
+func TestParseNamingStrategy(t *testing.T) {
+	for name, want := range map[string]NamingStrategy{
+		"":               KebabCase{},
+		"kebab":          KebabCase{},
+		"snake":          SnakeCase{},
+		"kebab-prefixed": KebabCase{PrefixAttributes: true},
+		"snake-prefixed": SnakeCase{PrefixAttributes: true},
+	} {
+		got, err := ParseNamingStrategy(name)
+		if err != nil || got != want {
+			t.Errorf("ParseNamingStrategy(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := ParseNamingStrategy("camel"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
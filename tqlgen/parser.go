@@ -38,10 +38,12 @@ type SubClause struct {
 	Annots []Annotation `parser:"@@*"`
 }
 
-// EntityClause is one of: owns or plays.
+// EntityClause is one of: owns, plays, or sub (the TypeQL 3 form
+// "entity name, sub parent").
 type EntityClause struct {
-	Owns  *OwnsDef  `parser:"  @@"`
-	Plays *PlaysDef `parser:"| @@"`
+	Owns  *OwnsDef   `parser:"  @@"`
+	Plays *PlaysDef  `parser:"| @@"`
+	Sub   *SubClause `parser:"| @@"`
 }
 
 // OwnsDef parses: owns attr-name [@key] [@unique] [@card(...)]
@@ -66,11 +68,12 @@ type RelationDef struct {
 	Clauses []RelationClause `parser:"( @@ ( ',' @@ )* )? ';'"`
 }
 
-// RelationClause is one of: relates, owns, or plays.
+// RelationClause is one of: relates, owns, plays, or sub.
 type RelationClause struct {
 	Relates *RelatesDef `parser:"  @@"`
 	Owns    *OwnsDef    `parser:"| @@"`
 	Plays   *PlaysDef   `parser:"| @@"`
+	Sub     *SubClause  `parser:"| @@"`
 }
 
 // RelatesDef parses: relates role-name [as parent-role] [@card(...)]
@@ -413,6 +416,9 @@ func convertEntity(e *EntityDef) EntitySpec {
 		spec.Parent = e.Parent.Parent
 	}
 	for _, c := range e.Clauses {
+		if c.Sub != nil {
+			spec.Parent = c.Sub.Parent
+		}
 		if c.Owns != nil {
 			spec.Owns = append(spec.Owns, convertOwns(c.Owns))
 		}
@@ -438,6 +444,9 @@ func convertRelation(r *RelationDef) RelationSpec {
 		spec.Parent = r.Parent.Parent
 	}
 	for _, c := range r.Clauses {
+		if c.Sub != nil {
+			spec.Parent = c.Sub.Parent
+		}
 		if c.Relates != nil {
 			rs := RelatesSpec{Role: c.Relates.Role}
 			if c.Relates.AsParent != nil {
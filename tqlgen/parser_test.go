@@ -273,6 +273,26 @@ func TestParseSchema_Relations(t *testing.T) {
 	}
 }
 
+func TestParseSchema_SubAsClause(t *testing.T) {
+	schema, err := ParseSchema(`define
+entity artifact @abstract;
+entity person @abstract, sub artifact,
+    owns name;
+relation employment, sub membership,
+    relates employee;
+`)
+	if err != nil {
+		t.Fatalf("ParseSchema failed: %v", err)
+	}
+	person := schema.Entities[1]
+	if person.Parent != "artifact" || !person.Abstract || len(person.Owns) != 1 {
+		t.Errorf("person = %+v", person)
+	}
+	if rel := schema.Relations[0]; rel.Parent != "membership" || len(rel.Relates) != 1 {
+		t.Errorf("employment = %+v", rel)
+	}
+}
+
 func TestParseSchema_StripFunctions(t *testing.T) {
 	schemaWithFuncs := testSchema + `
 fun count_things() -> integer:
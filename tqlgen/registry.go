@@ -46,6 +46,7 @@ type RegistryData struct {
 	EntityAttributes  []KVSliceCtx
 	AttrValueTypes    []KVCtx
 	AttrEnumValues    []KVSliceCtx
+	AttrRegex         []KVCtx
 	RelationSchema    []RelSchemaCtx
 	RelationAttrs     []KVSliceCtx
 	AllEntityTypes    []string
@@ -238,6 +239,9 @@ func fillAttributeData(data *RegistryData, schema *ParsedSchema, cfg RegistryCon
 		if len(a.Values) > 0 {
 			data.AttrEnumValues = append(data.AttrEnumValues, KVSliceCtx{name, a.Values})
 		}
+		if a.Regex != "" {
+			data.AttrRegex = append(data.AttrRegex, KVCtx{name, a.Regex})
+		}
 	}
 
 	if cfg.Enums {
@@ -601,16 +605,35 @@ func cardMin(card string) int {
 	return n
 }
 
+// cardMax parses a cardinality string and returns the maximum cardinality as
+// an int, or -1 when it is unbounded or unspecified.
+// Examples: "1" → 1, "0..1" → 1, "2..5" → 5, "1.." → -1, "" → -1.
+func cardMax(card string) int {
+	if card == "" {
+		return -1
+	}
+	parts := strings.SplitN(card, "..", 2)
+	last := parts[len(parts)-1]
+	n, err := strconv.Atoi(last)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
 var registryFuncMap = template.FuncMap{
 	"goStrSlice":   goStrSlice,
 	"goKVMapSlice": goKVMapSlice,
 	"title":        ToPascalCaseAcronyms,
 	"cardMin":      cardMin,
+	"cardMax":      cardMax,
 }
 
 var registryTemplate = template.Must(template.New("registry").Funcs(registryFuncMap).Parse(`// Code generated by tqlgen; DO NOT EDIT.
 
 package {{.PackageName}}
+
+import "fmt"
 {{- if or .SchemaVersion .SchemaHash}}
 
 // --- Schema metadata ---
@@ -735,6 +758,15 @@ var AttributeEnumValues = map[string][]string{
 {{- end}}
 }
 
+// --- Attribute Regex Constraints ---
+
+// AttributeRegex maps attribute name → @regex pattern.
+var AttributeRegex = map[string]string{
+{{- range .AttrRegex}}
+	"{{.Key}}": {{printf "%q" .Value}},
+{{- end}}
+}
+
 // --- Relation Schema ---
 
 // RoleInfo describes a role in a relation: its name and which entity types can fill it.
@@ -742,6 +774,7 @@ type RoleInfo struct {
 	RoleName    string
 	PlayerTypes []string
 	MinCard     int // minimum cardinality (0 or 1+); from @card annotation
+	MaxCard     int // maximum cardinality; -1 when unbounded or unspecified
 }
 
 // RelationSchema maps relation type → slice of RoleInfo (one per role).
@@ -749,7 +782,7 @@ var RelationSchema = map[string][]RoleInfo{
 {{- range .RelationSchema}}
 	"{{.Name}}": {
 	{{- range .Roles}}
-		{"{{.RoleName}}", []string{{goStrSlice .PlayerTypes}}, {{cardMin .Card}}},
+		{"{{.RoleName}}", []string{{goStrSlice .PlayerTypes}}, {{cardMin .Card}}, {{cardMax .Card}}},
 	{{- end}}
 	},
 {{- end}}
@@ -869,6 +902,24 @@ func GetRoleInfo(relationType, roleName string) *RoleInfo {
 	return nil
 }
 
+// CheckRoleCounts returns an error if counts, the number of players per role
+// of a relation instance, violates the MinCard or MaxCard of a role. Roles
+// missing from counts have no players.
+func CheckRoleCounts(relationType string, counts map[string]int) error {
+	for _, ri := range RelationSchema[relationType] {
+		n := counts[ri.RoleName]
+		if n >= ri.MinCard && (ri.MaxCard < 0 || n <= ri.MaxCard) {
+			continue
+		}
+		want := fmt.Sprintf("%d..", ri.MinCard)
+		if ri.MaxCard >= 0 {
+			want += fmt.Sprint(ri.MaxCard)
+		}
+		return fmt.Errorf("%s: role %q has %d player(s), want %s", relationType, ri.RoleName, n, want)
+	}
+	return nil
+}
+
 // GetEntityAttributes returns the owned attributes for an entity type, or nil if not found.
 func GetEntityAttributes(entityType string) []string {
 	return EntityAttributes[entityType]
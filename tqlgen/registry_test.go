@@ -163,6 +163,26 @@ func TestCardMin(t *testing.T) {
 	}
 }
 
+func TestCardMax(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"", -1},
+		{"1", 1},
+		{"0..1", 1},
+		{"1..", -1},
+		{"2", 2},
+		{"2..5", 5},
+		{"bad", -1},
+	}
+	for _, tc := range tests {
+		if got := cardMax(tc.input); got != tc.want {
+			t.Errorf("cardMax(%q) = %d, want %d", tc.input, got, tc.want)
+		}
+	}
+}
+
 func TestRenderRegistry_MinCard(t *testing.T) {
 	schema := &ParsedSchema{
 		Entities: []EntitySpec{
@@ -181,8 +201,11 @@ func TestRenderRegistry_MinCard(t *testing.T) {
 	}
 	out := buf.String()
 	// The generated code should contain MinCard=1 for the "spouse" role
-	if !strings.Contains(out, `"spouse", []string{"person"}, 1`) {
-		t.Errorf("expected MinCard=1 for spouse role in output, got:\n%s", out)
+	if !strings.Contains(out, `"spouse", []string{"person"}, 1, -1`) {
+		t.Errorf("expected MinCard=1, MaxCard=-1 for spouse role in output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "func CheckRoleCounts(") {
+		t.Error("expected CheckRoleCounts convenience function")
 	}
 }
 
@@ -404,6 +427,7 @@ func TestRenderRegistry(t *testing.T) {
 		Attributes: []AttributeSpec{
 			{Name: "name", ValueType: "string"},
 			{Name: "status", ValueType: "string", Values: []string{"proposed", "accepted"}},
+			{Name: "code", ValueType: "string", Regex: `^[A-Z]{3}-\d+$`},
 		},
 		Entities: []EntitySpec{
 			{Name: "artifact", Abstract: true, Owns: []OwnsSpec{{Attribute: "name"}}},
@@ -438,6 +462,7 @@ func TestRenderRegistry(t *testing.T) {
 		"entity parent":         `"persona": "artifact"`,
 		"entity attributes":     `"persona":`,
 		"attribute enum values": `"status":`,
+		"attribute regex":       `"code": "^[A-Z]{3}-\\d+$"`,
 		"enum constant":         `StatusProposed = "proposed"`,
 		"relation schema":       `"acts":`,
 		"relation attributes":   `RelationAttributes`,
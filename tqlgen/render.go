@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+
+	"github.com/CaliLuke/go-typeql/ast"
 )
 
 // RenderConfig specifies the settings for generating Go code from a TypeQL schema.
@@ -23,6 +25,17 @@ type RenderConfig struct {
 	SchemaVersion string
 	// Enums, if true, generates string constants from @values constraints on attributes.
 	Enums bool
+	// Queries, if true, emits precompiled TypeQL constants (fetch-all, count,
+	// match-by-key) per type and a PrecompiledQueries method the Manager
+	// uses instead of building those queries at runtime.
+	Queries bool
+	// FieldNames, if true, emits a <Type>Fields variable per type holding its
+	// attribute names (e.g. PersonFields.Email), for filters and sorting.
+	FieldNames bool
+	// Naming, if set, is the NamingStrategy of the schema: generated field
+	// names drop the decoration it adds to attribute names, so that with
+	// KebabCase{PrefixAttributes: true} person-name becomes Person.Name.
+	Naming NamingStrategy
 }
 
 // DefaultConfig returns a standard RenderConfig with sensible defaults.
@@ -33,6 +46,7 @@ func DefaultConfig() RenderConfig {
 		UseAcronyms:  true,
 		SkipAbstract: true,
 		Enums:        true,
+		FieldNames:   true,
 	}
 }
 
@@ -56,6 +70,7 @@ func Render(w io.Writer, schema *ParsedSchema, cfg RenderConfig) error {
 		PackageName: cfg.PackageName,
 		ModulePath:  cfg.ModulePath,
 		NeedsTime:   needsTimeImport(schema, attrTypes),
+		FieldNames:  cfg.FieldNames,
 	}
 
 	if cfg.Enums {
@@ -70,14 +85,22 @@ func Render(w io.Writer, schema *ParsedSchema, cfg RenderConfig) error {
 		if cfg.SkipAbstract && e.Abstract {
 			continue
 		}
-		data.Entities = append(data.Entities, buildEntityCtx(e, attrTypes, cfg))
+		ctx := buildEntityCtx(e, attrTypes, cfg)
+		if cfg.Queries {
+			ctx.Queries = buildQueriesCtx(ctx.GoName, e.Name, e.Owns)
+		}
+		data.Entities = append(data.Entities, ctx)
 	}
 
 	for _, r := range schema.Relations {
 		if cfg.SkipAbstract && r.Abstract {
 			continue
 		}
-		data.Relations = append(data.Relations, buildRelationCtx(r, schema, attrTypes, cfg))
+		ctx := buildRelationCtx(r, schema, attrTypes, cfg)
+		if cfg.Queries {
+			ctx.Queries = buildQueriesCtx(ctx.GoName, r.Name, r.Owns)
+		}
+		data.Relations = append(data.Relations, ctx)
 	}
 
 	return renderTemplate.Execute(w, data)
@@ -89,6 +112,7 @@ type renderData struct {
 	PackageName string
 	ModulePath  string
 	NeedsTime   bool
+	FieldNames  bool
 	Enums       []enumCtx
 	Entities    []entityCtx
 	Relations   []relationCtx
@@ -122,6 +146,7 @@ type entityCtx struct {
 	InheritanceComment string
 	SchemaDoc          string
 	Fields             []fieldCtx
+	Queries            *queriesCtx
 }
 
 type relationCtx struct {
@@ -135,10 +160,21 @@ type relationCtx struct {
 	SchemaDoc          string
 	Roles              []roleCtx
 	Fields             []fieldCtx
+	Queries            *queriesCtx
+}
+
+// queriesCtx holds the precompiled queries emitted with RenderConfig.Queries.
+type queriesCtx struct {
+	GoName     string
+	TypeName   string
+	FetchAll   string
+	Count      string
+	MatchByKey string // empty for types without key attributes
 }
 
 type fieldCtx struct {
 	GoName       string
+	Attr         string // TypeDB attribute name
 	GoType       string
 	Tag          string
 	Comment      string
@@ -190,7 +226,7 @@ func buildEntityCtx(e EntitySpec, attrTypes map[string]string, cfg RenderConfig)
 	}
 
 	for _, o := range e.Owns {
-		ctx.Fields = append(ctx.Fields, buildFieldCtx(o, attrTypes, cfg))
+		ctx.Fields = append(ctx.Fields, buildFieldCtx(e.Name, o, attrTypes, cfg))
 	}
 
 	return ctx
@@ -236,15 +272,42 @@ func buildRelationCtx(r RelationSpec, schema *ParsedSchema, attrTypes map[string
 	}
 
 	for _, o := range r.Owns {
-		ctx.Fields = append(ctx.Fields, buildFieldCtx(o, attrTypes, cfg))
+		ctx.Fields = append(ctx.Fields, buildFieldCtx(r.Name, o, attrTypes, cfg))
 	}
 
 	return ctx
 }
 
-func buildFieldCtx(o OwnsSpec, attrTypes map[string]string, cfg RenderConfig) fieldCtx {
+// buildQueriesCtx compiles the precompiled queries for a type with the same
+// AST the gotype Manager builds at runtime, so the text is identical.
+func buildQueriesCtx(goName, typeName string, owns []OwnsSpec) *queriesCtx {
+	c := &ast.Compiler{}
+	items := []ast.FetchItem{ast.FetchFunc("_iid", "iid", "$e")}
+	var keys []ast.Constraint
+	for _, o := range owns {
+		items = append(items, ast.FetchAttr(o.Attribute, "$e", o.Attribute))
+		if o.Key {
+			keys = append(keys, ast.Has(o.Attribute, "%s"))
+		}
+	}
+	q := &queriesCtx{GoName: goName, TypeName: typeName}
+	q.FetchAll, _ = c.Compile(ast.Fetch(items...))
+	matchAll, _ := c.Compile(ast.Match(ast.Entity("$e", typeName)))
+	q.Count = matchAll + "\nreduce $count = count($e);"
+	if len(keys) > 0 {
+		q.MatchByKey, _ = c.Compile(ast.Match(ast.Entity("$e", typeName, keys...)))
+	}
+	return q
+}
+
+func buildFieldCtx(owner string, o OwnsSpec, attrTypes map[string]string, cfg RenderConfig) fieldCtx {
+	name := o.Attribute
+	if cfg.Naming != nil {
+		name = cfg.Naming.TrimAttribute(owner, name)
+	}
 	f := fieldCtx{
-		GoName:       goFieldName(o.Attribute, cfg),
+		GoName:       goFieldName(name, cfg),
+		Attr:         o.Attribute,
 		Comment:      docComment(o.Doc),
 		MetaComments: metaComments(o.Meta),
 	}
@@ -265,6 +328,9 @@ func buildFieldCtx(o OwnsSpec, attrTypes map[string]string, cfg RenderConfig) fi
 	if o.Card != "" {
 		tagParts = append(tagParts, "card="+o.Card)
 	}
+	if def, ok := ownsDefault(o); ok {
+		tagParts = append(tagParts, "default="+def)
+	}
 
 	tag := fmt.Sprintf(`typedb:%s`, strconv.Quote(strings.Join(tagParts, ",")))
 	if o.Doc != "" {
@@ -282,6 +348,18 @@ func buildFieldCtx(o OwnsSpec, attrTypes map[string]string, cfg RenderConfig) fi
 	return f
 }
 
+// ownsDefault returns the value of a @meta("default", "...") annotation on an
+// ownership. Values containing commas cannot be expressed in a typedb tag and
+// are ignored.
+func ownsDefault(o OwnsSpec) (string, bool) {
+	for _, m := range o.Meta {
+		if m.Key == "default" && !strings.Contains(m.Value, ",") {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
 func docComment(doc string) string {
 	return strings.Join(strings.Fields(doc), " ")
 }
@@ -462,6 +540,41 @@ func ({{.GoName}}) SchemaMeta() map[string]string {
 	}
 }
 {{- end}}
+{{- if and $.FieldNames .Fields}}
+
+// {{.GoName}}Fields holds the attribute names of {{.GoName}}, for use in
+// filters and sorting.
+var {{.GoName}}Fields = struct {
+{{- range .Fields}}
+	{{.GoName}} string
+{{- end}}
+}{
+{{- range .Fields}}
+	{{.GoName}}: {{quote .Attr}},
+{{- end}}
+}
+{{- end}}
+{{- with .Queries}}
+
+const (
+	{{.GoName}}FetchAllQuery = {{quote .FetchAll}}
+	{{.GoName}}CountQuery = {{quote .Count}}
+{{- if .MatchByKey}}
+	{{.GoName}}MatchByKeyQuery = {{quote .MatchByKey}}
+{{- end}}
+)
+
+func ({{.GoName}}) PrecompiledQueries() gotype.PrecompiledQueries {
+	return gotype.PrecompiledQueries{
+		TypeName: {{quote .TypeName}},
+		FetchAll: {{.GoName}}FetchAllQuery,
+		Count: {{.GoName}}CountQuery,
+{{- if .MatchByKey}}
+		MatchByKey: {{.GoName}}MatchByKeyQuery,
+{{- end}}
+	}
+}
+{{- end}}
 {{end}}
 {{- range .Relations}}
 {{- if .Comment}}
@@ -508,4 +621,39 @@ func ({{.GoName}}) SchemaMeta() map[string]string {
 	}
 }
 {{- end}}
+{{- if and $.FieldNames .Fields}}
+
+// {{.GoName}}Fields holds the attribute names of {{.GoName}}, for use in
+// filters and sorting.
+var {{.GoName}}Fields = struct {
+{{- range .Fields}}
+	{{.GoName}} string
+{{- end}}
+}{
+{{- range .Fields}}
+	{{.GoName}}: {{quote .Attr}},
+{{- end}}
+}
+{{- end}}
+{{- with .Queries}}
+
+const (
+	{{.GoName}}FetchAllQuery = {{quote .FetchAll}}
+	{{.GoName}}CountQuery = {{quote .Count}}
+{{- if .MatchByKey}}
+	{{.GoName}}MatchByKeyQuery = {{quote .MatchByKey}}
+{{- end}}
+)
+
+func ({{.GoName}}) PrecompiledQueries() gotype.PrecompiledQueries {
+	return gotype.PrecompiledQueries{
+		TypeName: {{quote .TypeName}},
+		FetchAll: {{.GoName}}FetchAllQuery,
+		Count: {{.GoName}}CountQuery,
+{{- if .MatchByKey}}
+		MatchByKey: {{.GoName}}MatchByKeyQuery,
+{{- end}}
+	}
+}
+{{- end}}
 {{end}}`))
@@ -2,6 +2,8 @@ package tqlgen
 
 import (
 	"bytes"
+	"go/parser"
+	"go/token"
 	"strings"
 	"testing"
 )
@@ -374,3 +376,162 @@ func TestBuildEnumCtxAcronyms(t *testing.T) {
 		t.Errorf("Values[0].GoName = %q, want %q", ctx.Values[0].GoName, "DisplayIDAuto")
 	}
 }
+
+func TestRenderOwnsDefaultMetaAsTag(t *testing.T) {
+	schema := &ParsedSchema{
+		Attributes: []AttributeSpec{
+			{Name: "status", ValueType: "string"},
+			{Name: "label", ValueType: "string"},
+		},
+		Entities: []EntitySpec{
+			{
+				Name: "proposal",
+				Owns: []OwnsSpec{
+					{Attribute: "status", Meta: []MetaSpec{{Key: "default", Value: "proposed"}}},
+					{Attribute: "label", Meta: []MetaSpec{{Key: "default", Value: "a,b"}}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, schema, DefaultConfig()); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `typedb:"status,default=proposed"`) {
+		t.Fatalf("missing default tag\n%s", out)
+	}
+	if strings.Contains(out, "label,default") {
+		t.Fatalf("default with comma should be skipped\n%s", out)
+	}
+}
+
+func TestRenderPrecompiledQueries(t *testing.T) {
+	schema := &ParsedSchema{
+		Attributes: []AttributeSpec{
+			{Name: "email", ValueType: "string"},
+			{Name: "age", ValueType: "integer"},
+			{Name: "since", ValueType: "datetime"},
+		},
+		Entities: []EntitySpec{
+			{Name: "person", Owns: []OwnsSpec{{Attribute: "email", Key: true}, {Attribute: "age"}}},
+		},
+		Relations: []RelationSpec{
+			{Name: "friendship", Relates: []RelatesSpec{{Role: "friend"}}, Owns: []OwnsSpec{{Attribute: "since"}}},
+		},
+	}
+
+	cfg := DefaultConfig()
+	cfg.Queries = true
+	var buf bytes.Buffer
+	if err := Render(&buf, schema, cfg); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`PersonFetchAllQuery = "fetch {\n  \"_iid\": iid($e),\n  \"email\": $e.email,\n  \"age\": $e.age\n};"`,
+		`PersonCountQuery = "match\n$e isa person;\nreduce $count = count($e);"`,
+		`PersonMatchByKeyQuery = "match\n$e isa person, has email %s;"`,
+		`func (Person) PrecompiledQueries() gotype.PrecompiledQueries {`,
+		`TypeName: "person",`,
+		`FriendshipCountQuery = "match\n$e isa friendship;\nreduce $count = count($e);"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "FriendshipMatchByKeyQuery") {
+		t.Errorf("relation without keys should have no match-by-key query\n%s", out)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "models.go", out, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, out)
+	}
+
+	buf.Reset()
+	if err := Render(&buf, schema, DefaultConfig()); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(buf.String(), "PrecompiledQueries") {
+		t.Error("queries must only be emitted when enabled")
+	}
+}
+
+func TestRenderFieldNames(t *testing.T) {
+	schema := &ParsedSchema{
+		Attributes: []AttributeSpec{
+			{Name: "email", ValueType: "string"},
+			{Name: "full-name", ValueType: "string"},
+			{Name: "since", ValueType: "datetime"},
+		},
+		Entities: []EntitySpec{
+			{Name: "person", Owns: []OwnsSpec{{Attribute: "email", Key: true}, {Attribute: "full-name"}}},
+			{Name: "tag"},
+		},
+		Relations: []RelationSpec{
+			{Name: "friendship", Relates: []RelatesSpec{{Role: "friend"}}, Owns: []OwnsSpec{{Attribute: "since"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, schema, DefaultConfig()); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"var PersonFields = struct {\n\tEmail string\n\tFullName string\n}{\n\tEmail: \"email\",\n\tFullName: \"full-name\",\n}",
+		"var FriendshipFields = struct {\n\tSince string\n}{\n\tSince: \"since\",\n}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "TagFields") {
+		t.Errorf("type without attributes should have no field names\n%s", out)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "models.go", out, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, out)
+	}
+
+	cfg := DefaultConfig()
+	cfg.FieldNames = false
+	buf.Reset()
+	if err := Render(&buf, schema, cfg); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(buf.String(), "PersonFields") {
+		t.Error("field names must only be emitted when enabled")
+	}
+}
+
+func TestRenderNamingStrategy(t *testing.T) {
+	schema := &ParsedSchema{
+		Attributes: []AttributeSpec{
+			{Name: "person_name", ValueType: "string"},
+			{Name: "person_user_id", ValueType: "string"},
+			{Name: "created_at", ValueType: "datetime"},
+		},
+		Entities: []EntitySpec{
+			{Name: "person", Owns: []OwnsSpec{{Attribute: "person_name", Key: true}, {Attribute: "person_user_id"}, {Attribute: "created_at"}}},
+		},
+	}
+	cfg := DefaultConfig()
+	cfg.Naming = SnakeCase{PrefixAttributes: true}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, schema, cfg); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Name string `typedb:\"person_name,key\"`",
+		"UserID *string `typedb:\"person_user_id\"`",
+		"CreatedAt *time.Time `typedb:\"created_at\"`",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package tqlgen
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+
+	"github.com/CaliLuke/go-typeql/ast"
+)
+
+// TestSchemaRoundTrip checks the define compiler against the schema
+// grammar: random define clauses are compiled, parsed back and compared with
+// the schema they describe. A failure names the seed that reproduces it.
+//
+// Query clauses are not covered: there is no query parser to round-trip
+// them through yet.
+func TestSchemaRoundTrip(t *testing.T) {
+	c := &ast.Compiler{}
+	for seed := range uint64(500) {
+		rng := rand.New(rand.NewPCG(seed, 0x7e1))
+		define, want := randomSchema(rng)
+		query, err := c.Compile(define)
+		if err != nil {
+			t.Fatalf("seed %d: compile: %v", seed, err)
+		}
+		got, err := ParseSchema(query)
+		if err != nil {
+			t.Fatalf("seed %d: %v\n%s", seed, err, query)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("seed %d: parsed schema differs\ngot:  %+v\nwant: %+v\n%s", seed, got, want, query)
+		}
+	}
+}
+
+// schemaStrings are used for @regex and @values, to check that the
+// compiler's escaping matches the parser's unescaping.
+var schemaStrings = []string{
+	"plain", "", `^[a-z]+$`, `say "hi"`, `back\slash`, `\d+\.\d*`, "tab\tand\nnewline\r",
+	`'single'`, `/slashes/`, `\u{1F680}`, "ünïcödé 🚀", "#not-a-comment", `;`, `@key`,
+}
+
+var valueTypes = []string{"string", "integer", "double", "decimal", "boolean", "date", "datetime", "datetime-tz", "duration"}
+
+// randomSchema returns a random define clause and the ParsedSchema it
+// should parse to. Definitions are shuffled; the parser keeps each kind in
+// source order.
+func randomSchema(rng *rand.Rand) (ast.DefineClause, *ParsedSchema) {
+	want := &ParsedSchema{}
+	var defs []ast.Definition
+	pick := func(list []string) string { return list[rng.IntN(len(list))] }
+	card := func() (ast.CardAnnotation, string) {
+		lo := rng.IntN(3)
+		if rng.IntN(3) == 0 {
+			return ast.CardAnnotation{Min: lo}, fmt.Sprintf("%d..", lo)
+		}
+		hi := lo + rng.IntN(3)
+		return ast.CardAnnotation{Min: lo, Max: &hi}, fmt.Sprintf("%d..%d", lo, hi)
+	}
+
+	var attrs []string
+	for i := range 1 + rng.IntN(6) {
+		spec := AttributeSpec{Name: fmt.Sprintf("attr-%d", i), ValueType: pick(valueTypes)}
+		value := ast.ValueTypeCapability{ValueType: spec.ValueType}
+		if spec.ValueType == "string" {
+			switch rng.IntN(3) {
+			case 1:
+				spec.Regex = pick(schemaStrings)
+				value.Annotations = append(value.Annotations, ast.RegexAnnotation{Pattern: spec.Regex})
+			case 2:
+				values := ast.ValuesAnnotation{}
+				for range 1 + rng.IntN(3) {
+					v := pick(schemaStrings)
+					spec.Values = append(spec.Values, v)
+					values.Values = append(values.Values, ast.Str(v))
+				}
+				value.Annotations = append(value.Annotations, values)
+			}
+		}
+		attrs = append(attrs, spec.Name)
+		want.Attributes = append(want.Attributes, spec)
+		defs = append(defs, ast.TypeDefinition{Kind: "attribute", TypeName: spec.Name, Capabilities: []ast.Capability{value}})
+	}
+
+	owns := func() ([]OwnsSpec, []ast.Capability) {
+		var specs []OwnsSpec
+		var caps []ast.Capability
+		for _, attr := range attrs {
+			if rng.IntN(2) == 0 {
+				continue
+			}
+			spec := OwnsSpec{Attribute: attr}
+			capability := ast.OwnsCapability{AttrName: attr}
+			switch rng.IntN(4) {
+			case 1:
+				spec.Key = true
+				capability.Annotations = append(capability.Annotations, ast.KeyAnnotation{})
+			case 2:
+				spec.Unique = true
+				capability.Annotations = append(capability.Annotations, ast.UniqueAnnotation{})
+			}
+			if rng.IntN(3) == 0 {
+				ann, expr := card()
+				spec.Card = expr
+				capability.Annotations = append(capability.Annotations, ann)
+			}
+			specs = append(specs, spec)
+			caps = append(caps, capability)
+		}
+		return specs, caps
+	}
+	typeAnnotations := func(abstract bool) []ast.Annotation {
+		if abstract {
+			return []ast.Annotation{ast.AbstractAnnotation{}}
+		}
+		return nil
+	}
+
+	var roles []PlaysSpec
+	for i := range rng.IntN(4) {
+		spec := RelationSpec{Name: fmt.Sprintf("rel-%d", i), Abstract: rng.IntN(4) == 0}
+		if i > 0 && rng.IntN(3) == 0 {
+			spec.Parent = fmt.Sprintf("rel-%d", rng.IntN(i))
+		}
+		var caps []ast.Capability
+		for j := range 1 + rng.IntN(3) {
+			role := RelatesSpec{Role: fmt.Sprintf("role-%d", j)}
+			capability := ast.RelatesCapability{RoleName: role.Role}
+			if rng.IntN(3) == 0 {
+				ann, expr := card()
+				role.Card = expr
+				capability.Annotations = append(capability.Annotations, ann)
+			}
+			spec.Relates = append(spec.Relates, role)
+			caps = append(caps, capability)
+			roles = append(roles, PlaysSpec{Relation: spec.Name, Role: role.Role})
+		}
+		var ownCaps []ast.Capability
+		spec.Owns, ownCaps = owns()
+		caps = append(caps, ownCaps...)
+		want.Relations = append(want.Relations, spec)
+		defs = append(defs, ast.TypeDefinition{
+			Kind: "relation", TypeName: spec.Name, Supertype: spec.Parent,
+			Annotations: typeAnnotations(spec.Abstract), Capabilities: caps,
+		})
+	}
+
+	for i := range 1 + rng.IntN(4) {
+		spec := EntitySpec{Name: fmt.Sprintf("ent-%d", i), Abstract: rng.IntN(4) == 0}
+		if i > 0 && rng.IntN(3) == 0 {
+			spec.Parent = fmt.Sprintf("ent-%d", rng.IntN(i))
+		}
+		var caps []ast.Capability
+		spec.Owns, caps = owns()
+		for _, role := range roles {
+			if rng.IntN(3) == 0 {
+				spec.Plays = append(spec.Plays, role)
+				caps = append(caps, ast.PlaysCapability{Role: role.Relation + ":" + role.Role})
+			}
+		}
+		want.Entities = append(want.Entities, spec)
+		defs = append(defs, ast.TypeDefinition{
+			Kind: "entity", TypeName: spec.Name, Supertype: spec.Parent,
+			Annotations: typeAnnotations(spec.Abstract), Capabilities: caps,
+		})
+	}
+
+	rng.Shuffle(len(defs), func(i, j int) { defs[i], defs[j] = defs[j], defs[i] })
+	return ast.DefineClause{Definitions: defs}, sortedByKind(defs, want)
+}
+
+// sortedByKind reorders want's type lists to follow the shuffled defs.
+func sortedByKind(defs []ast.Definition, want *ParsedSchema) *ParsedSchema {
+	attrs := make(map[string]AttributeSpec)
+	for _, a := range want.Attributes {
+		attrs[a.Name] = a
+	}
+	entities := make(map[string]EntitySpec)
+	for _, e := range want.Entities {
+		entities[e.Name] = e
+	}
+	relations := make(map[string]RelationSpec)
+	for _, r := range want.Relations {
+		relations[r.Name] = r
+	}
+	out := &ParsedSchema{}
+	for _, def := range defs {
+		d := def.(ast.TypeDefinition)
+		switch d.Kind {
+		case "attribute":
+			out.Attributes = append(out.Attributes, attrs[d.TypeName])
+		case "entity":
+			out.Entities = append(out.Entities, entities[d.TypeName])
+		case "relation":
+			out.Relations = append(out.Relations, relations[d.TypeName])
+		}
+	}
+	return out
+}